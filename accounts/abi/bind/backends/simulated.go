@@ -863,6 +863,11 @@ func (m callMsg) Gas() uint64                  { return m.CallMsg.Gas }
 func (m callMsg) Value() *big.Int              { return m.CallMsg.Value }
 func (m callMsg) Data() []byte                 { return m.CallMsg.Data }
 func (m callMsg) AccessList() types.AccessList { return m.CallMsg.AccessList }
+func (m callMsg) BlobGasFeeCap() *big.Int      { return nil }
+func (m callMsg) BlobHashes() []common.Hash    { return nil }
+
+func (m callMsg) SetCodeAuthorizations() []types.SetCodeAuthorization { return nil }
+func (m callMsg) Type() uint8                                         { return types.LegacyTxType }
 
 // filterBackend implements filters.Backend to support filtering for logs without
 // taking bloom-bits acceleration structures into account.
@@ -32,10 +32,13 @@ package bind
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/format"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
@@ -49,8 +52,71 @@ const (
 	setEnabledFuncKey    = "setEnabled"
 	setNoneFuncKey       = "setNone"
 	readAllowListFuncKey = "readAllowList"
+
+	fallbackGasCostKey = "fallback"
+	receiveGasCostKey  = "receive"
+
+	// natspec tags of the gas costs in a userdoc or devdoc, e.g. "@custom:gas 5000"
+	natspecGasTag        = "custom:gas"
+	natspecGasPerByteTag = "custom:gas-per-byte"
 )
 
+// PrecompileGasCost is the gas cost of a precompile function.
+type PrecompileGasCost struct {
+	Gas     uint64 `json:"gas"`     // Gas charged for every call
+	PerByte uint64 `json:"perByte"` // Gas charged for every byte of the input, excluding the selector of functions
+}
+
+// PrecompileGasCosts maps the function signatures or names (and "fallback" or "receive") of a precompile to their gas costs.
+type PrecompileGasCosts map[string]*PrecompileGasCost
+
+// ParsePrecompileGasCosts parses the gas costs of a precompile from [data]. [data] is either a JSON object of
+// function signatures or names to gas costs, e.g. {"transfer": {"gas": 5000, "perByte": 10}}, or the userdoc
+// or devdoc output of solc, where the gas costs are set with the @custom:gas and @custom:gas-per-byte tags.
+func ParsePrecompileGasCosts(data []byte) (PrecompileGasCosts, error) {
+	var doc struct {
+		Methods map[string]map[string]json.RawMessage `json:"methods"`
+	}
+	if err := json.Unmarshal(data, &doc); err == nil && doc.Methods != nil {
+		gasCosts := make(PrecompileGasCosts)
+		for sig, tags := range doc.Methods {
+			gasCost := &PrecompileGasCost{}
+			found := false
+			for tag, dst := range map[string]*uint64{natspecGasTag: &gasCost.Gas, natspecGasPerByteTag: &gasCost.PerByte} {
+				raw, ok := tags[tag]
+				if !ok {
+					continue
+				}
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return nil, fmt.Errorf("invalid @%s of %s: %w", tag, sig, err)
+				}
+				parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid @%s of %s: %w", tag, sig, err)
+				}
+				*dst = parsed
+				found = true
+			}
+			if found {
+				gasCosts[sig] = gasCost
+			}
+		}
+		return gasCosts, nil
+	}
+
+	var gasCosts PrecompileGasCosts
+	if err := json.Unmarshal(data, &gasCosts); err != nil {
+		return nil, fmt.Errorf("failed to parse gas costs: %w", err)
+	}
+	for key, gasCost := range gasCosts {
+		if gasCost == nil {
+			return nil, fmt.Errorf("missing gas cost of %s", key)
+		}
+	}
+	return gasCosts, nil
+}
+
 // Lang is a target programming language selector to generate bindings for.
 type Lang int
 
@@ -102,6 +168,23 @@ func isKeyWord(arg string) bool {
 // enforces compile time type safety and naming convention opposed to having to
 // manually maintain hard coded strings that break on runtime.
 func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string, isPrecompile bool) (string, error) {
+	return bind(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases, isPrecompile, tmplSourcePrecompileGo, nil)
+}
+
+// BindPrecompile generates the precompile of the contract ABIs like [Bind], emitting the gas costs of
+// [gasCosts] instead of placeholders. If [gasCosts] is not nil, it must cover every function of the precompile.
+func BindPrecompile(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, libs map[string]string, aliases map[string]string, gasCosts PrecompileGasCosts) (string, error) {
+	return bind(types, abis, bytecodes, fsigs, pkg, LangGo, libs, aliases, true, tmplSourcePrecompileGo, gasCosts)
+}
+
+// BindPrecompileTest generates the unit test scaffolding of the precompile generated by [BindPrecompile] for the same
+// arguments. The generated tests belong to the core package and run the precompile with precompile/testutils.
+func BindPrecompileTest(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, libs map[string]string, aliases map[string]string, gasCosts PrecompileGasCosts) (string, error) {
+	return bind(types, abis, bytecodes, fsigs, pkg, LangGo, libs, aliases, true, tmplSourcePrecompileTestGo, gasCosts)
+}
+
+// bind generates the binding of the contract ABIs, using [precompileTemplate] if [isPrecompile] is set.
+func bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string, isPrecompile bool, precompileTemplate string, gasCosts PrecompileGasCosts) (string, error) {
 	var (
 		// contracts is the map of each individual contract requested binding
 		contracts = make(map[string]*tmplContract)
@@ -302,7 +385,12 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		}
 		precompileType := types[0]
 		firstContract := contracts[precompileType]
-		data, templateSource = createPrecompileDataAndTemplate(firstContract, structs)
+		precompileData, err := createPrecompileData(firstContract, structs, gasCosts)
+		if err != nil {
+			return "", err
+		}
+		data = precompileData
+		templateSource = precompileTemplate
 	} else {
 		templateSource = tmplSource[lang]
 		data = &tmplData{
@@ -321,6 +409,8 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
 		"convertToNil":  convertToNil,
+		"bindtestvalue": bindTestValue,
+		"args":          func(values ...interface{}) []interface{} { return values },
 	}
 
 	// render the template
@@ -656,9 +746,12 @@ func decapitalise(input string) string {
 }
 
 // convertToNil converts any type to its proper nil form.
-func convertToNil(input abi.Type) string {
+func convertToNil(input abi.Type, structs map[string]*tmplStruct) string {
 	switch input.T {
 	case abi.IntTy, abi.UintTy:
+		if typ := bindBasicTypeGo(input); typ != "*big.Int" {
+			return typ + "(0)"
+		}
 		return "big.NewInt(0)"
 	case abi.StringTy:
 		return "\"\""
@@ -668,6 +761,9 @@ func convertToNil(input abi.Type) string {
 		return "common.Address{}"
 	case abi.HashTy:
 		return "common.Hash{}"
+	case abi.FixedBytesTy, abi.ArrayTy, abi.TupleTy:
+		// fixed size types are values in Go and have no nil form
+		return bindTypeGo(input, structs) + "{}"
 	default:
 		return "nil"
 	}
@@ -711,7 +807,7 @@ func hasStruct(t abi.Type) bool {
 	}
 }
 
-func createPrecompileDataAndTemplate(contract *tmplContract, structs map[string]*tmplStruct) (interface{}, string) {
+func createPrecompileData(contract *tmplContract, structs map[string]*tmplStruct, gasCosts PrecompileGasCosts) (interface{}, error) {
 	funcs := make(map[string]*tmplMethod)
 
 	for k, v := range contract.Transacts {
@@ -734,13 +830,79 @@ func createPrecompileDataAndTemplate(contract *tmplContract, structs map[string]
 		tmplContract: contract,
 		AllowList:    isAllowList,
 		Funcs:        funcs,
+		GasCosts:     make(map[string]*PrecompileGasCost),
+	}
+	if gasCosts != nil {
+		if err := resolveGasCosts(precompileContract, gasCosts); err != nil {
+			return nil, err
+		}
 	}
 
 	data := &tmplPrecompileData{
 		Contract: precompileContract,
 		Structs:  structs,
 	}
-	return data, tmplSourcePrecompileGo
+	return data, nil
+}
+
+// resolveGasCosts sets the gas costs of the functions, the fallback and the receive function of [contract] from [gasCosts].
+// Functions are looked up by their signature first and then by their name. Missing and unused gas costs
+// are reported as errors, so that a function cannot silently ship with a zero gas cost.
+func resolveGasCosts(contract *tmplPrecompileContract, gasCosts PrecompileGasCosts) error {
+	used := make(map[string]bool)
+	lookup := func(keys ...string) (*PrecompileGasCost, bool) {
+		for _, key := range keys {
+			if gasCost, ok := gasCosts[key]; ok {
+				used[key] = true
+				return gasCost, true
+			}
+		}
+		return nil, false
+	}
+
+	var missing []string
+	for _, method := range contract.Funcs {
+		gasCost, ok := lookup(method.Original.Sig, method.Original.RawName)
+		if !ok {
+			missing = append(missing, method.Original.Sig)
+			continue
+		}
+		contract.GasCosts[method.Normalized.Name] = gasCost
+	}
+	if contract.Fallback != nil {
+		gasCost, ok := lookup(fallbackGasCostKey)
+		if !ok {
+			missing = append(missing, fallbackGasCostKey)
+		} else {
+			contract.FallbackGasCost = gasCost
+		}
+	}
+	if contract.Receive != nil {
+		gasCost, ok := lookup(receiveGasCostKey)
+		if !ok {
+			missing = append(missing, receiveGasCostKey)
+		} else if gasCost.PerByte != 0 {
+			return fmt.Errorf("%s cannot have a per byte gas cost, as it does not take an input", receiveGasCostKey)
+		} else {
+			contract.ReceiveGasCost = gasCost
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing gas costs for %s", strings.Join(missing, ", "))
+	}
+
+	var unused []string
+	for key := range gasCosts {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("gas costs of %s do not match any function", strings.Join(unused, ", "))
+	}
+	return nil
 }
 
 func allowListEnabled(funcs map[string]*tmplMethod) bool {
@@ -752,3 +914,242 @@ func allowListEnabled(funcs map[string]*tmplMethod) bool {
 	}
 	return true
 }
+
+// BindPrecompileSolidity generates the Solidity interface and an example contract calling the
+// precompile of type [typ] described by [abiJSON]. The interface declares the functions with the
+// same signatures as the ABI, so that its selectors match the generated Go precompile.
+func BindPrecompileSolidity(typ string, abiJSON string) (string, string, error) {
+	evmABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return "", "", err
+	}
+	isAllowList := true
+	for _, key := range []string{readAllowListFuncKey, setAdminFuncKey, setEnabledFuncKey, setNoneFuncKey} {
+		if _, ok := evmABI.Methods[key]; !ok {
+			isAllowList = false
+		}
+	}
+
+	structs := make(map[string]*tmplSolStruct)
+	data := &tmplPrecompileSolData{
+		Type:        typ,
+		Instance:    decapitalise(typ),
+		AddressName: solidityConstantName(typ) + "_ADDRESS",
+		AllowList:   isAllowList,
+	}
+	for _, method := range evmABI.Methods {
+		if isAllowList && (method.Name == readAllowListFuncKey || method.Name == setAdminFuncKey || method.Name == setEnabledFuncKey || method.Name == setNoneFuncKey) {
+			continue
+		}
+		params, args, err := solidityParams(method.Inputs, structs, "", true)
+		if err != nil {
+			return "", "", err
+		}
+		returns, _, err := solidityParams(method.Outputs, structs, "", true)
+		if err != nil {
+			return "", "", err
+		}
+		// Structs declared in the interface are qualified by the interface name in the example contract
+		qualifier := "I" + typ + "."
+		qualifiedParams, _, err := solidityParams(method.Inputs, structs, qualifier, true)
+		if err != nil {
+			return "", "", err
+		}
+		qualifiedReturns, _, err := solidityParams(method.Outputs, structs, qualifier, true)
+		if err != nil {
+			return "", "", err
+		}
+		mutability := method.StateMutability
+		if mutability == "nonpayable" {
+			mutability = ""
+		}
+		data.Funcs = append(data.Funcs, &tmplSolMethod{
+			Name:             method.RawName,
+			Sig:              method.Sig,
+			Selector:         fmt.Sprintf("%#x", method.ID),
+			Params:           params,
+			Args:             args,
+			Returns:          returns,
+			QualifiedParams:  qualifiedParams,
+			QualifiedReturns: qualifiedReturns,
+			Mutability:       mutability,
+			Constant:         method.IsConstant(),
+			Payable:          method.IsPayable(),
+		})
+	}
+	if evmABI.HasFallback() {
+		data.Fallback = &tmplSolFallback{Payable: evmABI.Fallback.IsPayable()}
+	}
+	data.Receive = evmABI.HasReceive()
+	for _, event := range evmABI.Events {
+		params, _, err := solidityParams(event.Inputs, structs, "", false)
+		if err != nil {
+			return "", "", err
+		}
+		data.Events = append(data.Events, &tmplSolEvent{Name: event.RawName, Params: params, Anonymous: event.Anonymous})
+	}
+	for _, s := range structs {
+		data.Structs = append(data.Structs, s)
+	}
+	sort.Slice(data.Funcs, func(i, j int) bool { return data.Funcs[i].Name < data.Funcs[j].Name })
+	sort.Slice(data.Events, func(i, j int) bool { return data.Events[i].Name < data.Events[j].Name })
+	sort.Slice(data.Structs, func(i, j int) bool { return data.Structs[i].Name < data.Structs[j].Name })
+
+	iface, err := renderSolidity(tmplSourcePrecompileSolInterface, data)
+	if err != nil {
+		return "", "", err
+	}
+	example, err := renderSolidity(tmplSourcePrecompileSolExample, data)
+	if err != nil {
+		return "", "", err
+	}
+	return iface, example, nil
+}
+
+// renderSolidity renders the Solidity [source] template with [data].
+func renderSolidity(source string, data *tmplPrecompileSolData) (string, error) {
+	buffer := new(bytes.Buffer)
+	tmpl := template.Must(template.New("").Parse(source))
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// solidityParams returns the Solidity parameter list of [args] and the comma separated names of
+// the parameters. Struct types are prefixed with [qualifier]. If [withLocation] is set, the
+// parameter list includes the data location of reference types. Structs used by [args] are
+// added to [structs].
+func solidityParams(args abi.Arguments, structs map[string]*tmplSolStruct, qualifier string, withLocation bool) (string, string, error) {
+	params := make([]string, 0, len(args))
+	names := make([]string, 0, len(args))
+	for i, arg := range args {
+		typ, err := solidityType(arg.Type, structs, qualifier)
+		if err != nil {
+			return "", "", err
+		}
+		param := typ
+		if arg.Indexed {
+			param += " indexed"
+		}
+		if withLocation && isSolidityReferenceType(arg.Type) {
+			param += " memory"
+		}
+		name := arg.Name
+		if name == "" || isKeyWord(name) {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		params = append(params, param+" "+name)
+		names = append(names, name)
+	}
+	return strings.Join(params, ", "), strings.Join(names, ", "), nil
+}
+
+// solidityType returns the Solidity type of [kind] with struct names prefixed by [qualifier]
+// and adds the struct declarations required by it to [structs].
+func solidityType(kind abi.Type, structs map[string]*tmplSolStruct, qualifier string) (string, error) {
+	switch kind.T {
+	case abi.SliceTy:
+		elem, err := solidityType(*kind.Elem, structs, qualifier)
+		return elem + "[]", err
+	case abi.ArrayTy:
+		elem, err := solidityType(*kind.Elem, structs, qualifier)
+		return fmt.Sprintf("%s[%d]", elem, kind.Size), err
+	case abi.TupleTy:
+		if kind.TupleRawName == "" {
+			return "", errors.New("tuple types require an internalType to generate the Solidity interface, re-generate the ABI from a Solidity source file")
+		}
+		if _, ok := structs[kind.TupleRawName]; ok {
+			return qualifier + kind.TupleRawName, nil
+		}
+		s := &tmplSolStruct{Name: kind.TupleRawName}
+		structs[kind.TupleRawName] = s
+		for i, elem := range kind.TupleElems {
+			field, err := solidityType(*elem, structs, "")
+			if err != nil {
+				return "", err
+			}
+			s.Fields = append(s.Fields, field+" "+kind.TupleRawNames[i])
+		}
+		return qualifier + kind.TupleRawName, nil
+	default:
+		return kind.String(), nil
+	}
+}
+
+// isSolidityReferenceType returns whether [kind] requires a data location in Solidity function parameters.
+func isSolidityReferenceType(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
+
+// solidityConstantName converts the CamelCase [name] into UPPER_SNAKE_CASE.
+func solidityConstantName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
+
+// bindTestValue returns a Go expression of a non-zero value of [kind], used as a placeholder
+// in the generated precompile tests. Structs are qualified by the precompile package.
+func bindTestValue(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.IntTy, abi.UintTy:
+		if typ := bindBasicTypeGo(kind); typ != "*big.Int" {
+			return typ + "(1)"
+		}
+		return "big.NewInt(1)"
+	case abi.BoolTy:
+		return "true"
+	case abi.StringTy:
+		return "\"test\""
+	case abi.AddressTy:
+		return "common.Address{1}"
+	case abi.HashTy:
+		return "common.Hash{1}"
+	case abi.BytesTy:
+		return "[]byte{1}"
+	case abi.FixedBytesTy, abi.FunctionTy:
+		return bindBasicTypeGo(kind) + "{1}"
+	case abi.SliceTy:
+		return bindTestType(kind, structs) + "{" + bindTestValue(*kind.Elem, structs) + "}"
+	case abi.ArrayTy:
+		elems := make([]string, kind.Size)
+		for i := range elems {
+			elems[i] = bindTestValue(*kind.Elem, structs)
+		}
+		return bindTestType(kind, structs) + "{" + strings.Join(elems, ", ") + "}"
+	case abi.TupleTy:
+		s := structs[kind.TupleRawName+kind.String()]
+		fields := make([]string, len(s.Fields))
+		for i, field := range s.Fields {
+			fields[i] = field.Name + ": " + bindTestValue(field.SolKind, structs)
+		}
+		return bindTestType(kind, structs) + "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return convertToNil(kind, structs)
+	}
+}
+
+// bindTestType returns the Go type of [kind] with structs qualified by the precompile package.
+func bindTestType(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return "precompile." + structs[kind.TupleRawName+kind.String()].Name
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]", kind.Size) + bindTestType(*kind.Elem, structs)
+	case abi.SliceTy:
+		return "[]" + bindTestType(*kind.Elem, structs)
+	default:
+		return bindBasicTypeGo(kind)
+	}
+}
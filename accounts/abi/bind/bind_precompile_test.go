@@ -27,6 +27,8 @@
 package bind
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -91,6 +93,36 @@ func TestPrecompileBindings(t *testing.T) {
 	golangBindingsFailure(t)
 }
 
+func TestPrecompileBindingsEvents(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[]},
+			{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false}]},
+			{"type":"event","name":"Memo","anonymous":false,"inputs":[{"name":"tag","type":"string","indexed":true},{"name":"text","type":"string","indexed":false}]}
+		]
+	`
+	code, err := Bind([]string{"EventEmitter"}, []string{abi}, []string{``}, nil, "bindtest", LangGo, nil, nil, true)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"type TransferEvent struct",
+		"TransferEventID common.Hash",
+		`TransferEventID = parsed.Events["Transfer"].ID`,
+		"func PackTransferLog(event TransferEvent) ([]common.Hash, []byte, error)",
+		`EventEmitterABI.PackEvent("Transfer", event.From, event.To, event.Amount)`,
+		"func EmitTransfer(accessibleState PrecompileAccessibleState, event TransferEvent) error",
+		"func UnpackTransferLog(topics []common.Hash, data []byte) (TransferEvent, error)",
+		// indexed arguments of dynamic types are hashed into their topic
+		"Tag  common.Hash",
+		"Text string",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+}
+
 func golangBindingsFailure(t *testing.T) {
 	// Generate the test suite for all the contracts
 	for i, tt := range bindFailedTests {
@@ -107,3 +139,370 @@ func golangBindingsFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestPrecompileSolidityBindings(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amounts","type":"uint256[]"}],"outputs":[{"name":"success","type":"bool"}]},
+			{"type":"function","name":"info","stateMutability":"view","inputs":[{"name":"key","type":"string"}],"outputs":[{"name":"value","type":"tuple","internalType":"struct IHelloWorld.Info","components":[{"name":"a","type":"uint64"},{"name":"b","type":"bytes"}]}]},
+			{"type":"function","name":"readAllowList","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"role","type":"uint256"}]},
+			{"type":"function","name":"setAdmin","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setEnabled","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setNone","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256","indexed":false}]}
+		]
+	`
+	iface, example, err := BindPrecompileSolidity("HelloWorld", abi)
+	if err != nil {
+		t.Fatalf("failed to generate Solidity bindings: %v", err)
+	}
+	for _, expected := range []string{
+		"interface IHelloWorld is IAllowList {",
+		"struct IHelloWorldInfo {\n    uint64 a;\n    bytes b;\n  }",
+		"event Transfer(address indexed from, uint256 amount);",
+		"// transfer(address,uint256[]): 0x2b4e4e96",
+		"function transfer(address to, uint256[] memory amounts) external returns (bool success);",
+		"function info(string memory key) external view returns (IHelloWorldInfo memory value);",
+	} {
+		if !strings.Contains(iface, expected) {
+			t.Errorf("generated interface does not contain %q", expected)
+		}
+	}
+	if strings.Contains(iface, "function setAdmin") {
+		t.Error("generated interface redeclares the AllowList functions")
+	}
+	for _, expected := range []string{
+		"contract ExampleHelloWorld is AllowList {",
+		"address constant HELLO_WORLD_ADDRESS = 0x0000000000000000000000000000000000000000;",
+		"constructor() AllowList(HELLO_WORLD_ADDRESS) {}",
+		"function transfer(address to, uint256[] memory amounts) public onlyEnabled returns (bool success) {\n    return helloWorld.transfer(to, amounts);",
+		"function info(string memory key) public view returns (IHelloWorld.IHelloWorldInfo memory value) {",
+	} {
+		if !strings.Contains(example, expected) {
+			t.Errorf("generated example contract does not contain %q", expected)
+		}
+	}
+
+	// Tuples without an internal type cannot be named in Solidity
+	_, _, err = BindPrecompileSolidity("HelloWorld", `[{"type":"function","name":"info","stateMutability":"view","inputs":[],"outputs":[{"name":"value","type":"tuple","components":[{"name":"a","type":"uint64"}]}]}]`)
+	if err == nil {
+		t.Fatal("expected an error for a tuple without an internal type")
+	}
+}
+
+func TestPrecompileTestBindings(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amounts","type":"uint256[]"}],"outputs":[{"name":"success","type":"bool"}]},
+			{"type":"function","name":"count","stateMutability":"view","inputs":[],"outputs":[{"name":"n","type":"uint256"}]},
+			{"type":"function","name":"readAllowList","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"role","type":"uint256"}]},
+			{"type":"function","name":"setAdmin","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setEnabled","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setNone","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]}
+		]
+	`
+	code, err := BindPrecompileTest([]string{"HelloWorld"}, []string{abi}, []string{``}, nil, "bindtest", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate test binding: %v", err)
+	}
+	for _, expected := range []string{
+		"package core",
+		"func TestHelloWorldRun(t *testing.T) {",
+		"func TestHelloWorldPackUnpack(t *testing.T) {",
+		`precompile.PackTransfer(precompile.TransferInput{
+					To:      common.Address{1},
+					Amounts: []*big.Int{big.NewInt(1)},
+				})`,
+		"suppliedGas: insufficientGas(precompile.CountGasCost),",
+		`"readOnly transfer fails": {`,
+		"expectedErr: vmerrs.ErrWriteProtection.Error(),",
+		`"transfer from no role fails": {`,
+		"expectedErr: precompile.ErrCannotTransfer.Error(),",
+		"precompile.SetHelloWorldAllowListStatus(state, enabledAddr, precompile.AllowListEnabled)",
+		"res, err := precompile.PackCountOutput(big.NewInt(1))",
+		`require.Equal(t, precompile.HelloWorldABI.Methods["transfer"].ID, packed[:4])`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated test does not contain %q", expected)
+		}
+	}
+	// Read-only functions are not checked against write protection
+	if strings.Contains(code, `"readOnly count fails"`) {
+		t.Error("generated test checks write protection of a read-only function")
+	}
+}
+
+func TestPrecompileBindingsDynamicTypes(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"store","stateMutability":"nonpayable","inputs":[{"name":"name","type":"string"},{"name":"blob","type":"bytes"},{"name":"tags","type":"string[]"}],"outputs":[{"name":"ok","type":"bool"},{"name":"names","type":"string[]"}]},
+			{"type":"function","name":"getBlob","stateMutability":"view","inputs":[{"name":"key","type":"bytes32"}],"outputs":[{"name":"blob","type":"bytes"}]},
+			{"type":"function","name":"getValues","stateMutability":"view","inputs":[{"name":"small","type":"uint8"}],"outputs":[{"name":"values","type":"uint256[]"}]}
+		]
+	`
+	code, err := Bind([]string{"Storage"}, []string{abi}, []string{``}, nil, "bindtest", LangGo, nil, nil, true)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"return [32]byte{}, err",
+		"return uint8(0), err",
+		"func UnpackStoreOutput(output []byte) (StoreOutput, error) {",
+		`StorageABI.UnpackIntoInterface(&outputStruct, "store", output)`,
+		"func UnpackGetBlobOutput(output []byte) ([]byte, error) {",
+		"func UnpackGetValuesOutput(output []byte) ([]*big.Int, error) {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+
+	code, err = BindPrecompileTest([]string{"Storage"}, []string{abi}, []string{``}, nil, "bindtest", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate test binding: %v", err)
+	}
+	for _, expected := range []string{
+		`ethabi "github.com/ethereum/go-ethereum/accounts/abi"`,
+		"func FuzzStorageInput(f *testing.F) {",
+		"func FuzzStorageOutput(f *testing.F) {",
+		`t.Run("store output", func(t *testing.T) {`,
+		"unpacked, err := precompile.UnpackGetValuesOutput(packed)",
+		"unpacked, err := precompile.UnpackStoreInput(input)",
+		"values, err := method.Inputs.Unpack(input)",
+		"values, err := method.Outputs.Unpack(output)",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated test does not contain %q", expected)
+		}
+	}
+}
+
+func TestParsePrecompileGasCosts(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		expected    PrecompileGasCosts
+		expectedErr string
+	}{
+		"gas costs": {
+			input: `{"transfer": {"gas": 5000, "perByte": 10}, "count()": {"gas": 200}}`,
+			expected: PrecompileGasCosts{
+				"transfer": {Gas: 5000, PerByte: 10},
+				"count()":  {Gas: 200},
+			},
+		},
+		"natspec": {
+			input: `{"kind": "dev", "methods": {"transfer(address,bytes)": {"custom:gas": "5000", "custom:gas-per-byte": " 10"}, "count()": {"custom:gas": "200", "details": "returns the count"}, "noop()": {"details": "no gas"}}, "version": 1}`,
+			expected: PrecompileGasCosts{
+				"transfer(address,bytes)": {Gas: 5000, PerByte: 10},
+				"count()":                 {Gas: 200},
+			},
+		},
+		"invalid natspec gas": {
+			input:       `{"methods": {"count()": {"custom:gas": "lots"}}}`,
+			expectedErr: "invalid @custom:gas of count()",
+		},
+		"null gas cost": {
+			input:       `{"count": null}`,
+			expectedErr: "missing gas cost of count",
+		},
+		"invalid json": {
+			input:       `{"count": 5}`,
+			expectedErr: "failed to parse gas costs",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gasCosts, err := ParsePrecompileGasCosts([]byte(test.input))
+			if len(test.expectedErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse gas costs: %v", err)
+			}
+			if !reflect.DeepEqual(test.expected, gasCosts) {
+				t.Fatalf("gas costs mismatch: have %v, want %v", gasCosts, test.expected)
+			}
+		})
+	}
+}
+
+func TestPrecompileBindingsGasCosts(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"memo","type":"bytes"}],"outputs":[]},
+			{"type":"function","name":"count","stateMutability":"view","inputs":[],"outputs":[{"name":"n","type":"uint256"}]},
+			{"type":"fallback","stateMutability":"nonpayable"}
+		]
+	`
+	bindGasCosts := func(gasCosts PrecompileGasCosts) (string, string, error) {
+		code, err := BindPrecompile([]string{"Ledger"}, []string{abi}, []string{``}, nil, "bindtest", nil, nil, gasCosts)
+		if err != nil {
+			return "", "", err
+		}
+		testCode, err := BindPrecompileTest([]string{"Ledger"}, []string{abi}, []string{``}, nil, "bindtest", nil, nil, gasCosts)
+		return code, testCode, err
+	}
+
+	code, testCode, err := bindGasCosts(PrecompileGasCosts{
+		"transfer(address,bytes)": {Gas: 5000, PerByte: 10},
+		"count":                   {Gas: 200},
+		"fallback":                {Gas: 100},
+	})
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"TransferGasCost        uint64 = 5000",
+		"TransferGasCostPerByte uint64 = 10",
+		"CountGasCost           uint64 = 200",
+		"LedgerFallbackGasCost  uint64 = 100",
+		"func TransferRequiredGas(input []byte) uint64 {",
+		"deductGas(suppliedGas, TransferRequiredGas(input))",
+		"deductGas(suppliedGas, CountGasCost)",
+		// helpers to call the precompile from other precompiles
+		"func CallTransfer(accessibleState PrecompileAccessibleState, caller common.Address, inputStruct TransferInput, gas uint64, readOnly bool) (uint64, error) {",
+		"_, remainingGas, err := accessibleState.CallPrecompile(caller, LedgerAddress, input, gas, readOnly)",
+		"func CallCount(accessibleState PrecompileAccessibleState, caller common.Address, gas uint64) (*big.Int, uint64, error) {",
+		"ret, remainingGas, err := accessibleState.CallPrecompile(caller, LedgerAddress, input, gas, true)",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+	if strings.Contains(code, "SET A GAS COST") {
+		t.Error("generated binding contains a gas cost placeholder")
+	}
+	for _, expected := range []string{
+		"transferInput := func() []byte {",
+		"suppliedGas: precompile.TransferRequiredGas(transferInput()[4:]),",
+		"suppliedGas: insufficientGas(precompile.TransferRequiredGas(transferInput()[4:])),",
+		"suppliedGas: precompile.CountGasCost,",
+	} {
+		if !strings.Contains(testCode, expected) {
+			t.Errorf("generated test does not contain %q", expected)
+		}
+	}
+
+	for name, test := range map[string]struct {
+		gasCosts    PrecompileGasCosts
+		expectedErr string
+	}{
+		"missing": {
+			gasCosts:    PrecompileGasCosts{"transfer": {Gas: 5000}},
+			expectedErr: "missing gas costs for count(), fallback",
+		},
+		"unused": {
+			gasCosts:    PrecompileGasCosts{"transfer": {Gas: 5000}, "count": {Gas: 200}, "fallback": {Gas: 100}, "cout": {Gas: 200}},
+			expectedErr: "gas costs of cout do not match any function",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := bindGasCosts(test.gasCosts); err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestPrecompileBindingsReceiveFallback(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"count","stateMutability":"view","inputs":[],"outputs":[{"name":"n","type":"uint256"}]},
+			{"type":"fallback","stateMutability":"payable"},
+			{"type":"receive","stateMutability":"payable"}
+		]
+	`
+	bindGasCosts := func(gasCosts PrecompileGasCosts) (string, error) {
+		return BindPrecompile([]string{"Forwarder"}, []string{abi}, []string{``}, nil, "bindtest", nil, nil, gasCosts)
+	}
+
+	code, err := bindGasCosts(nil)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"ForwarderFallbackGasCost uint64 = 0 // SET A GAS COST HERE",
+		"ForwarderReceiveGasCost  uint64 = 0 // SET A GAS COST LESS THAN 2300 HERE",
+		"func forwarderFallback(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {",
+		"func forwarderReceive(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, _ []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {",
+		"deductGas(suppliedGas, ForwarderReceiveGasCost)",
+		"contract := newStatefulPrecompileWithFunctionSelectors(forwarderReceive, forwarderFallback, functions)",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+
+	code, err = bindGasCosts(PrecompileGasCosts{"count": {Gas: 200}, "fallback": {Gas: 100, PerByte: 2}, "receive": {Gas: 50}})
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"ForwarderFallbackGasCost        uint64 = 100",
+		"ForwarderFallbackGasCostPerByte uint64 = 2",
+		"ForwarderReceiveGasCost         uint64 = 50",
+		"func ForwarderFallbackRequiredGas(input []byte) uint64 {",
+		"deductGas(suppliedGas, ForwarderFallbackRequiredGas(input))",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+
+	_, err = bindGasCosts(PrecompileGasCosts{"count": {Gas: 200}, "fallback": {Gas: 100}, "receive": {Gas: 50, PerByte: 1}})
+	if err == nil || !strings.Contains(err.Error(), "receive cannot have a per byte gas cost") {
+		t.Fatalf("expected per byte receive gas cost error, got %v", err)
+	}
+
+	iface, _, err := BindPrecompileSolidity("Forwarder", abi)
+	if err != nil {
+		t.Fatalf("failed to generate Solidity bindings: %v", err)
+	}
+	for _, expected := range []string{
+		"  receive() external payable;",
+		"  fallback() external payable;",
+	} {
+		if !strings.Contains(iface, expected) {
+			t.Errorf("generated interface does not contain %q", expected)
+		}
+	}
+}
+
+func TestPrecompileBindingsConfig(t *testing.T) {
+	abi := `
+		[
+			{"type":"function","name":"readAllowList","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"role","type":"uint256"}]},
+			{"type":"function","name":"setAdmin","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setEnabled","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]},
+			{"type":"function","name":"setNone","stateMutability":"nonpayable","inputs":[{"name":"addr","type":"address"}],"outputs":[]}
+		]
+	`
+	code, err := Bind([]string{"HelloWorld"}, []string{abi}, []string{``}, nil, "bindtest", LangGo, nil, nil, true)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, expected := range []string{
+		"type HelloWorldConfig struct {\n\tAllowListConfig\n\tUpgradeableConfig\n}",
+		"func NewHelloWorldConfig(blockTimestamp *big.Int, admins []common.Address, enableds []common.Address) *HelloWorldConfig {",
+		"func NewDisableHelloWorldConfig(blockTimestamp *big.Int) *HelloWorldConfig {",
+		"func (c *HelloWorldConfig) Equal(s StatefulPrecompileConfig) bool {",
+		"func (c *HelloWorldConfig) Verify() error {",
+		"func (c *HelloWorldConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {",
+		"func (c *HelloWorldConfig) Address() common.Address {",
+		"func (c *HelloWorldConfig) Contract() StatefulPrecompiledContract {",
+		// registration of the precompile module
+		"const HelloWorldConfigKey = \"helloWorldConfig\"",
+		"RegisterModule(Module{\n\t\tConfigKey: HelloWorldConfigKey,\n\t\tAddress:   HelloWorldAddress,\n\t\tContract:  HelloWorldPrecompile,",
+		"NewConfig: func() StatefulPrecompileConfig { return new(HelloWorldConfig) },",
+		// typed accessor snippets for the params package
+		"func (c *ChainConfig) GetHelloWorldConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.HelloWorldConfig {",
+		"return c.IsPrecompileEnabled(precompile.HelloWorldAddress, blockNumber, blockTimestamp)",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated binding does not contain %q", expected)
+		}
+	}
+}
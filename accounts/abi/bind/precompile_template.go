@@ -11,8 +11,17 @@ type tmplPrecompileData struct {
 // tmplPrecompileContract contains the data needed to generate an individual contract binding.
 type tmplPrecompileContract struct {
 	*tmplContract
-	AllowList bool                   // Indicator whether the contract uses AllowList precompile
-	Funcs     map[string]*tmplMethod // Contract functions that include both Calls + Transacts in tmplContract
+	AllowList       bool                          // Indicator whether the contract uses AllowList precompile
+	Funcs           map[string]*tmplMethod        // Contract functions that include both Calls + Transacts in tmplContract
+	GasCosts        map[string]*PrecompileGasCost // Gas costs of the functions by normalized name, if annotated
+	FallbackGasCost *PrecompileGasCost            // Gas cost of the fallback function, if annotated
+	ReceiveGasCost  *PrecompileGasCost            // Gas cost of the receive function, if annotated
+}
+
+// HasPerByteGasCost returns whether the gas cost of the function [name] depends on the length of its input.
+func (c *tmplPrecompileContract) HasPerByteGasCost(name string) bool {
+	gasCost, ok := c.GasCosts[name]
+	return ok && gasCost.PerByte != 0
 }
 
 // tmplSourcePrecompileGo is the Go precompiled source template.
@@ -22,29 +31,32 @@ const tmplSourcePrecompileGo = `
 // The file is generated by a template. Please inspect every code and comment in this file before use.
 
 // There are some must-be-done changes waiting in the file. Each area requiring you to add your code is marked with CUSTOM CODE to make them easy to find and modify.
-// Additionally there are other files you need to edit to activate your precompile.
-// These areas are highlighted with comments "ADD YOUR PRECOMPILE HERE".
-// For testing take a look at other precompile tests in core/stateful_precompile_test.go
+// The precompile registers itself in the init function below, so no other files need to be edited to activate it.
+// For testing take a look at precompile/testutils, which runs precompiles in an in-memory state.
+// Unit test scaffolding for this precompile can be generated by running precompilegen with the --test-out flag.
 
 /* General guidelines for precompile development:
-1- Read the comment and set a suitable contract address in precompile/params.go. E.g:
+1- Read the comment in precompile/params.go and set a suitable contract address in this file. E.g:
 	{{.Contract.Type}}Address = common.HexToAddress("ASUITABLEHEXADDRESS")
 2- Set gas costs here
+	Gas costs can be generated by running precompilegen with the --gas flag, so that no function is left with a zero gas cost
 3- It is recommended to only modify code in the highlighted areas marked with "CUSTOM CODE STARTS HERE". Modifying code outside of these areas should be done with caution and with a deep understanding of how these changes may impact the EVM.
 Typically, custom codes are required in only those areas.
-4- Add your upgradable config in params/precompile_config.go
-5- Add your precompile upgrade in params/config.go
-6- Add your solidity interface and test contract to contract-examples/contracts
-7- Write solidity tests for your precompile in contract-examples/test
-8- Create your genesis with your precompile enabled in tests/e2e/genesis/
-9- Create e2e test for your solidity test in tests/e2e/solidity/suites.go
-10- Run your e2e precompile Solidity tests with 'E2E=true ./scripts/run.sh'
+4- Optionally add typed accessors for your config in params/precompile_config.go and params/config.go
+	The snippets are generated in the ACCESSORS comment at the end of this file
+5- Add your solidity interface and test contract to contract-examples/contracts
+	Both can be generated by running precompilegen with the --contracts flag set to contract-examples/contracts
+6- Write solidity tests for your precompile in contract-examples/test
+7- Create your genesis with your precompile enabled in tests/e2e/genesis/
+8- Create e2e test for your solidity test in tests/e2e/solidity/suites.go
+9- Run your e2e precompile Solidity tests with 'E2E=true ./scripts/run.sh'
 
 */
 
 package precompile
 
 import (
+	"encoding/json"
 	"math/big"
 	"errors"
 	"fmt"
@@ -58,11 +70,35 @@ import (
 
 const (
 	{{- range .Contract.Funcs}}
+	{{- $gasCost := index $.Contract.GasCosts .Normalized.Name}}
+	{{- if $gasCost}}
+	{{.Normalized.Name}}GasCost uint64 = {{$gasCost.Gas}}
+	{{- if $gasCost.PerByte}}
+	{{.Normalized.Name}}GasCostPerByte uint64 = {{$gasCost.PerByte}}
+	{{- end}}
+	{{- else}}
 	{{.Normalized.Name}}GasCost uint64 = 0 // SET A GAS COST HERE
 	{{- end}}
+	{{- end}}
 	{{- if .Contract.Fallback}}
+	{{- if .Contract.FallbackGasCost}}
+	{{.Contract.Type}}FallbackGasCost uint64 = {{.Contract.FallbackGasCost.Gas}}
+	{{- if .Contract.FallbackGasCost.PerByte}}
+	{{.Contract.Type}}FallbackGasCostPerByte uint64 = {{.Contract.FallbackGasCost.PerByte}}
+	{{- end}}
+	{{- else if .Contract.Receive}}
+	{{.Contract.Type}}FallbackGasCost uint64 = 0 // SET A GAS COST HERE
+	{{- else}}
 	{{.Contract.Type}}FallbackGasCost uint64 = 0 // SET A GAS COST LESS THAN 2300 HERE
-  {{- end}}
+	{{- end}}
+	{{- end}}
+	{{- if .Contract.Receive}}
+	{{- if .Contract.ReceiveGasCost}}
+	{{.Contract.Type}}ReceiveGasCost uint64 = {{.Contract.ReceiveGasCost.Gas}}
+	{{- else}}
+	{{.Contract.Type}}ReceiveGasCost uint64 = 0 // SET A GAS COST LESS THAN 2300 HERE
+	{{- end}}
+	{{- end}}
 
 	// {{.Contract.Type}}RawABI contains the raw ABI of {{.Contract.Type}} contract.
 	{{.Contract.Type}}RawABI = "{{.Contract.InputABI}}"
@@ -75,6 +111,7 @@ var (
 	_ = big.NewInt
 	_ = strings.NewReader
 	_ = fmt.Printf
+	_ = vmerrs.ErrWriteProtection
 )
 
 {{$contract := .Contract}}
@@ -94,15 +131,26 @@ var (
 	Err{{.Contract.Type}}CannotFallback = errors.New("non-enabled cannot call fallback function")
 	{{- end}}
 
+	{{- if .Contract.Receive | and $contract.AllowList}}
+	Err{{.Contract.Type}}CannotReceive = errors.New("non-enabled cannot call receive function")
+	{{- end}}
+
 	{{.Contract.Type}}ABI abi.ABI // will be initialized by init function
 
 	{{.Contract.Type}}Precompile StatefulPrecompiledContract // will be initialized by init function
+	{{- range .Contract.Events}}
+
+	{{.Normalized.Name}}EventID common.Hash // topic hash of the {{.Original.Name}} event, will be initialized by init function
+	{{- end}}
 
 	// CUSTOM CODE STARTS HERE
-	// THIS SHOULD BE MOVED TO precompile/params.go with a suitable hex address.
+	// Set a suitable hex address from the reserved ranges in precompile/params.go.
 	{{.Contract.Type}}Address = common.HexToAddress("ASUITABLEHEXADDRESS")
 )
 
+// {{.Contract.Type}}ConfigKey is the JSON key of the {{.Contract.Type}} config in the chain config and precompile upgrades.
+const {{.Contract.Type}}ConfigKey = "{{decapitalise .Contract.Type}}Config"
+
 // {{.Contract.Type}}Config implements the StatefulPrecompileConfig
 // interface while adding in the {{.Contract.Type}} specific precompile address.
 type {{.Contract.Type}}Config struct {
@@ -134,21 +182,47 @@ type {{capitalise .Normalized.Name}}Output struct{
 {{- end}}
 {{- end}}
 
+{{- range .Contract.Events}}
+
+// {{.Normalized.Name}}Event represents a {{.Original.Name}} event raised by the {{$contract.Type}} contract.
+// Indexed arguments of dynamic types are represented by the hash of their value.
+type {{.Normalized.Name}}Event struct{
+{{- range .Normalized.Inputs}}
+	{{capitalise .Name}} {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}
+{{- end}}
+}
+{{- end}}
+
 func init() {
 	parsed, err := abi.JSON(strings.NewReader({{.Contract.Type}}RawABI))
 	if err != nil {
 		panic(err)
 	}
 	{{.Contract.Type}}ABI = parsed
+	{{- range .Contract.Events}}
+	{{.Normalized.Name}}EventID = parsed.Events["{{.Original.Name}}"].ID
+	{{- end}}
 
 	{{.Contract.Type}}Precompile = create{{.Contract.Type}}Precompile({{.Contract.Type}}Address)
+
+	RegisterModule(Module{
+		ConfigKey: {{.Contract.Type}}ConfigKey,
+		Address:   {{.Contract.Type}}Address,
+		Contract:  {{.Contract.Type}}Precompile,
+		NewConfig: func() StatefulPrecompileConfig { return new({{.Contract.Type}}Config) },
+	})
 }
 
 // New{{.Contract.Type}}Config returns a config for a network upgrade at [blockTimestamp] that enables
-// {{.Contract.Type}} {{if .Contract.AllowList}} with the given [admins] as members of the allowlist {{end}}.
-func New{{.Contract.Type}}Config(blockTimestamp *big.Int{{if .Contract.AllowList}}, admins []common.Address{{end}}) *{{.Contract.Type}}Config {
+// {{.Contract.Type}}{{if .Contract.AllowList}} with the given [admins] and [enableds] as members of the allowlist{{end}}.
+func New{{.Contract.Type}}Config(blockTimestamp *big.Int{{if .Contract.AllowList}}, admins []common.Address, enableds []common.Address{{end}}) *{{.Contract.Type}}Config {
 	return &{{.Contract.Type}}Config{
-		{{if .Contract.AllowList}}AllowListConfig:   AllowListConfig{AllowListAdmins: admins},{{end}}
+		{{- if .Contract.AllowList}}
+		AllowListConfig: AllowListConfig{
+			AllowListAdmins:  admins,
+			EnabledAddresses: enableds,
+		},
+		{{- end}}
 		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
 	}
 }
@@ -184,8 +258,7 @@ func (c *{{.Contract.Type}}Config) String() string {
 	return string(bytes)
 }
 
-// Address returns the address of the {{.Contract.Type}}. Addresses reside under the precompile/params.go
-// Select a non-conflicting address and set it in the params.go.
+// Address returns the address of the {{.Contract.Type}}.
 func (c *{{.Contract.Type}}Config) Address() common.Address {
 	return {{.Contract.Type}}Address
 }
@@ -232,6 +305,45 @@ func Set{{.Contract.Type}}AllowListStatus(stateDB StateDB, address common.Addres
 }
 {{end}}
 
+{{range .Contract.Events}}
+// Pack{{.Normalized.Name}}Log packs [event] into the topics and data of a {{.Original.Name}} log.
+// The first topic is the event signature, followed by the indexed arguments of [event].
+func Pack{{.Normalized.Name}}Log(event {{.Normalized.Name}}Event) ([]common.Hash, []byte, error) {
+	return {{$contract.Type}}ABI.PackEvent("{{.Original.Name}}", {{range .Normalized.Inputs}} event.{{capitalise .Name}}, {{end}})
+}
+
+// Emit{{.Normalized.Name}} adds a {{.Original.Name}} log for [event] to the StateDB of [accessibleState].
+// The log is emitted from {{$contract.Type}}Address at the current block number.
+func Emit{{.Normalized.Name}}(accessibleState PrecompileAccessibleState, event {{.Normalized.Name}}Event) error {
+	topics, data, err := Pack{{.Normalized.Name}}Log(event)
+	if err != nil {
+		return err
+	}
+	accessibleState.GetStateDB().AddLog({{$contract.Type}}Address, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+	return nil
+}
+
+// Unpack{{.Normalized.Name}}Log attempts to unpack [topics] and [data] of a {{.Original.Name}} log into a {{.Normalized.Name}}Event.
+// This function is mostly used for tests.
+func Unpack{{.Normalized.Name}}Log(topics []common.Hash, data []byte) ({{.Normalized.Name}}Event, error) {
+	event := {{.Normalized.Name}}Event{}
+	if len(topics) == 0 || topics[0] != {{.Normalized.Name}}EventID {
+		return event, errors.New("log is not a {{.Original.Name}} event")
+	}
+	if err := {{$contract.Type}}ABI.UnpackIntoInterface(&event, "{{.Original.Name}}", data); err != nil {
+		return event, err
+	}
+	var indexed abi.Arguments
+	for _, arg := range {{$contract.Type}}ABI.Events["{{.Original.Name}}"].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	err := abi.ParseTopics(&event, indexed, topics[1:])
+	return event, err
+}
+{{end}}
+
 {{range .Contract.Funcs}}
 {{if len .Normalized.Inputs | lt 1}}
 // Unpack{{capitalise .Normalized.Name}}Input attempts to unpack [input] into the arguments for the {{capitalise .Normalized.Name}}Input{}
@@ -255,7 +367,7 @@ func Pack{{.Normalized.Name}}(inputStruct {{capitalise .Normalized.Name}}Input)
 func Unpack{{capitalise .Normalized.Name}}Input(input []byte)({{bindtype $input.Type $structs}}, error) {
 res, err := {{$contract.Type}}ABI.UnpackInput("{{$method.Original.Name}}", input)
 if err != nil {
-	return {{convertToNil $input.Type}}, err
+	return {{convertToNil $input.Type $structs}}, err
 }
 unpacked := *abi.ConvertType(res[0], new({{bindtype $input.Type $structs}})).(*{{bindtype $input.Type $structs}})
 return unpacked, nil
@@ -286,6 +398,15 @@ func Pack{{capitalise .Normalized.Name}}Output (outputStruct {{capitalise .Norma
 	)
 }
 
+// Unpack{{capitalise .Normalized.Name}}Output attempts to unpack [output] into the {{capitalise .Normalized.Name}}Output{}
+// returned by {{.Original.Name}}.
+func Unpack{{capitalise .Normalized.Name}}Output(output []byte) ({{capitalise .Normalized.Name}}Output, error) {
+	outputStruct := {{capitalise .Normalized.Name}}Output{}
+	err := {{$contract.Type}}ABI.UnpackIntoInterface(&outputStruct, "{{.Original.Name}}", output)
+
+	return outputStruct, err
+}
+
 {{else if len .Normalized.Outputs | eq 1 }}
 {{$method := .}}
 {{$output := index $method.Normalized.Outputs 0}}
@@ -294,18 +415,72 @@ func Pack{{capitalise .Normalized.Name}}Output (outputStruct {{capitalise .Norma
 func Pack{{$method.Normalized.Name}}Output ({{decapitalise $output.Name}} {{bindtype $output.Type $structs}}) ([]byte, error) {
 	return {{$contract.Type}}ABI.PackOutput("{{$method.Original.Name}}", {{decapitalise $output.Name}})
 }
+
+// Unpack{{capitalise .Normalized.Name}}Output attempts to unpack [output] into the {{bindtype $output.Type $structs}} type output
+// returned by {{$method.Original.Name}}.
+func Unpack{{capitalise .Normalized.Name}}Output(output []byte) ({{bindtype $output.Type $structs}}, error) {
+	res, err := {{$contract.Type}}ABI.Unpack("{{$method.Original.Name}}", output)
+	if err != nil {
+		return {{convertToNil $output.Type $structs}}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new({{bindtype $output.Type $structs}})).(*{{bindtype $output.Type $structs}})
+	return unpacked, nil
+}
 {{end}}
+{{- $method := .}}
+{{- $zero := ""}}
+{{- if len .Normalized.Outputs | lt 1}}{{$zero = printf "%sOutput{}" (capitalise .Normalized.Name)}}
+{{- else if len .Normalized.Outputs | eq 1}}{{$zero = convertToNil (index .Normalized.Outputs 0).Type $structs}}
+{{- end}}
+// Call{{.Normalized.Name}} calls {{.Original.Name}} of the {{$contract.Type}} precompile on behalf of [caller], so that another
+// precompile can use it without accessing its storage. At most [gas] is forwarded and the remaining gas is returned.
+{{- if .Original.IsConstant}}
+// The call is read-only.
+{{- else}}
+// [readOnly] must be set to the readOnly flag of the calling precompile.
+{{- end}}
+func Call{{.Normalized.Name}}(accessibleState PrecompileAccessibleState, caller common.Address,
+	{{- if len .Normalized.Inputs | lt 1}} inputStruct {{capitalise .Normalized.Name}}Input,
+	{{- else if len .Normalized.Inputs | eq 1}} {{decapitalise (index .Normalized.Inputs 0).Name}} {{bindtype (index .Normalized.Inputs 0).Type $structs}},
+	{{- end}} gas uint64{{if not .Original.IsConstant}}, readOnly bool{{end}}) (
+	{{- if len .Normalized.Outputs | lt 1}}{{capitalise .Normalized.Name}}Output, 
+	{{- else if len .Normalized.Outputs | eq 1}}{{bindtype (index .Normalized.Outputs 0).Type $structs}}, 
+	{{- end}}uint64, error) {
+	input, err := Pack{{.Normalized.Name}}(
+		{{- if len .Normalized.Inputs | lt 1}}inputStruct
+		{{- else if len .Normalized.Inputs | eq 1}}{{decapitalise (index .Normalized.Inputs 0).Name}}
+		{{- end}})
+	if err != nil {
+		return {{if $zero}}{{$zero}}, {{end}}gas, err
+	}
+	{{- if .Normalized.Outputs}}
+	ret, remainingGas, err := accessibleState.CallPrecompile(caller, {{$contract.Type}}Address, input, gas, {{if .Original.IsConstant}}true{{else}}readOnly{{end}})
+	if err != nil {
+		return {{$zero}}, remainingGas, err
+	}
+	output, err := Unpack{{capitalise .Normalized.Name}}Output(ret)
+	return output, remainingGas, err
+	{{- else}}
+	_, remainingGas, err := accessibleState.CallPrecompile(caller, {{$contract.Type}}Address, input, gas, {{if .Original.IsConstant}}true{{else}}readOnly{{end}})
+	return remainingGas, err
+	{{- end}}
+}
 
+{{if $contract.HasPerByteGasCost .Normalized.Name}}
+// {{.Normalized.Name}}RequiredGas returns the gas cost of calling {{.Original.Name}} with [input], which does not include the selector.
+func {{.Normalized.Name}}RequiredGas(input []byte) uint64 {
+	return {{.Normalized.Name}}GasCost + uint64(len(input))*{{.Normalized.Name}}GasCostPerByte
+}
+{{end}}
 func {{decapitalise .Normalized.Name}}(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	{{- if $contract.HasPerByteGasCost .Normalized.Name}}
+	if remainingGas, err = deductGas(suppliedGas, {{.Normalized.Name}}RequiredGas(input)); err != nil {
+	{{- else}}
 	if remainingGas, err = deductGas(suppliedGas, {{.Normalized.Name}}GasCost); err != nil {
+	{{- end}}
 		return nil, 0, err
 	}
 
-	{{- if not .Original.IsConstant}}
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
- 	{{- end}}
 
 	{{- if len .Normalized.Inputs | eq 0}}
 	// no input provided for this function
@@ -335,6 +510,9 @@ func {{decapitalise .Normalized.Name}}(accessibleState PrecompileAccessibleState
 	{{- if len .Normalized.Inputs | ne 0}}
 	_ = inputStruct // CUSTOM CODE OPERATES ON INPUT
 	{{- end}}
+	{{- if not .Original.IsConstant | and (len $contract.Events | lt 0)}}
+	// Logs can be emitted with the generated Emit<Event> helpers, e.g. Emit<Event>(accessibleState, <Event>Event{...})
+	{{- end}}
 
 	{{- if len .Normalized.Outputs | eq 0}}
 	// this function does not return an output, leave this one as is
@@ -359,10 +537,25 @@ func {{decapitalise .Normalized.Name}}(accessibleState PrecompileAccessibleState
 
 {{- if .Contract.Fallback}}
 {{- with .Contract.Fallback}}
+{{- if $contract.FallbackGasCost}}{{if $contract.FallbackGasCost.PerByte}}
+// {{$contract.Type}}FallbackRequiredGas returns the gas cost of calling the fallback function with [input].
+func {{$contract.Type}}FallbackRequiredGas(input []byte) uint64 {
+	return {{$contract.Type}}FallbackGasCost + uint64(len(input))*{{$contract.Type}}FallbackGasCostPerByte
+}
+{{end}}{{end}}
 // {{decapitalise $contract.Type}}Fallback executed if a function identifier does not match any of the available functions in a smart contract.
-// This function cannot take an input or return an output.
-func {{decapitalise $contract.Type}}Fallback (accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, _ []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+{{- if not $contract.Receive}}
+// It is also executed if there is no input data, e.g. for plain transfers, since there is no receive function.
+{{- end}}
+// [input] is the raw input data including the function selector, if any.
+func {{decapitalise $contract.Type}}Fallback (accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	{{- if $contract.FallbackGasCost}}{{if $contract.FallbackGasCost.PerByte}}
+	if remainingGas, err = deductGas(suppliedGas, {{$contract.Type}}FallbackRequiredGas(input)); err != nil {
+	{{- else}}
+	if remainingGas, err = deductGas(suppliedGas, {{$contract.Type}}FallbackGasCost); err != nil {
+	{{- end}}{{else}}
 	if remainingGas, err = deductGas(suppliedGas, {{$contract.Type}}FallbackGasCost); err != nil {
+	{{- end}}
 		return nil, 0, err
 	}
 
@@ -371,7 +564,7 @@ func {{decapitalise $contract.Type}}Fallback (accessibleState PrecompileAccessib
 	}
 
 	{{- if $contract.AllowList}}
-	// Allow list is enabled and {{.Normalized.Name}} is a state-changer function.
+	// Allow list is enabled and the fallback is a state-changer function.
 	// This part of the code restricts the function to be called only by enabled/admin addresses in the allow list.
 	// You can modify/delete this code if you don't want this function to be restricted by the allow list.
 	stateDB := accessibleState.GetStateDB()
@@ -384,6 +577,7 @@ func {{decapitalise $contract.Type}}Fallback (accessibleState PrecompileAccessib
 	{{- end}}
 
 	// CUSTOM CODE STARTS HERE
+	_ = input // CUSTOM CODE OPERATES ON INPUT
 
 	// Fallback can return data in output.
 	// The returned data will not be ABI-encoded.
@@ -395,6 +589,39 @@ func {{decapitalise $contract.Type}}Fallback (accessibleState PrecompileAccessib
 {{- end}}
 {{- end}}
 
+{{- if .Contract.Receive}}
+{{- with .Contract.Receive}}
+// {{decapitalise $contract.Type}}Receive executed if the precompile is called with no input data, e.g. for plain transfers.
+// This function cannot take an input or return an output.
+func {{decapitalise $contract.Type}}Receive (accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, _ []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, {{$contract.Type}}ReceiveGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	{{- if $contract.AllowList}}
+	// Allow list is enabled and the receive function is a state-changer function.
+	// This part of the code restricts the function to be called only by enabled/admin addresses in the allow list.
+	// You can modify/delete this code if you don't want this function to be restricted by the allow list.
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to modify it
+	callerStatus := getAllowListStatus(stateDB, {{$contract.Type}}Address, caller)
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", Err{{$contract.Type}}CannotReceive, caller)
+	}
+	// allow list code ends here.
+	{{- end}}
+
+	// CUSTOM CODE STARTS HERE
+
+	return nil, remainingGas, nil
+}
+{{- end}}
+{{- end}}
+
 // create{{.Contract.Type}}Precompile returns a StatefulPrecompiledContract with getters and setters for the precompile.
 {{if .Contract.AllowList}} // Access to the getters/setters is controlled by an allow list for [precompileAddr].{{end}}
 func create{{.Contract.Type}}Precompile(precompileAddr common.Address) StatefulPrecompiledContract {
@@ -408,16 +635,542 @@ func create{{.Contract.Type}}Precompile(precompileAddr common.Address) StatefulP
 	if !ok{
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(method{{.Normalized.Name}}.ID, {{decapitalise .Normalized.Name}}))
+	functions = append(functions, newStatefulPrecompileFunction(method{{.Normalized.Name}}.Name, method{{.Normalized.Name}}.ID, method{{.Normalized.Name}}.IsConstant(), {{decapitalise .Normalized.Name}}))
 	{{end}}
 
-	{{- if .Contract.Fallback}}
+	{{- if and .Contract.Receive .Contract.Fallback}}
+	// Construct the contract with the receive and fallback functions.
+	contract := newStatefulPrecompileWithFunctionSelectors({{decapitalise $contract.Type}}Receive, {{decapitalise $contract.Type}}Fallback, functions)
+	{{- else if .Contract.Receive}}
+	// Construct the contract with the receive function and no fallback function.
+	contract := newStatefulPrecompileWithFunctionSelectors({{decapitalise $contract.Type}}Receive, nil, functions)
+	{{- else if .Contract.Fallback}}
 	// Construct the contract with the fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors({{decapitalise $contract.Type}}Fallback, functions)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, {{decapitalise $contract.Type}}Fallback, functions)
 	{{- else}}
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, functions)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
 	{{- end}}
 	return contract
 }
+
+/* ACCESSORS
+{{.Contract.Type}} is registered by its init function and is activated through the generic precompile upgrade
+handling in the params package. The snippets below add typed accessors for its config, following the
+precompiles shipped with subnet-evm. Add them if needed and remove this comment afterwards.
+
+params/precompile_config.go:
+
+	// Get{{.Contract.Type}}Config returns the latest forked {{.Contract.Type}}Config
+	// specified by [c] or nil if it was never enabled.
+	func (c *ChainConfig) Get{{.Contract.Type}}Config(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.{{.Contract.Type}}Config {
+		if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.{{.Contract.Type}}ConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+			return val.(*precompile.{{.Contract.Type}}Config)
+		}
+		return nil
+	}
+
+params/config.go:
+
+	// Is{{.Contract.Type}} returns whether the {{.Contract.Type}} precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+	func (c *ChainConfig) Is{{.Contract.Type}}(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+		return c.IsPrecompileEnabled(precompile.{{.Contract.Type}}Address, blockNumber, blockTimestamp)
+	}
+*/
+`
+
+// tmplPrecompileSolData is the data structure required to fill the Solidity templates of a precompile.
+type tmplPrecompileSolData struct {
+	Type        string           // Type name of the precompile
+	Instance    string           // Name of the precompile instance in the example contract
+	AddressName string           // Name of the precompile address constant in the example contract
+	AllowList   bool             // Indicator whether the contract uses AllowList precompile
+	Funcs       []*tmplSolMethod // Contract functions excluding the AllowList functions
+	Events      []*tmplSolEvent  // Contract events
+	Structs     []*tmplSolStruct // Structs used by the functions and events
+	Fallback    *tmplSolFallback // Fallback function of the contract, if any
+	Receive     bool             // Indicator whether the contract has a receive function
+}
+
+// tmplSolFallback contains the Solidity declaration of the fallback function of a precompile.
+type tmplSolFallback struct {
+	Payable bool // Indicator whether the fallback function accepts value
+}
+
+// tmplSolMethod contains the Solidity declarations of a precompile function.
+type tmplSolMethod struct {
+	Name             string // Name of the function
+	Sig              string // Canonical signature of the function
+	Selector         string // Hex encoded 4 byte selector of the function
+	Params           string // Parameter list with data locations
+	Args             string // Comma separated names of the parameters
+	Returns          string // Return list with data locations, empty if the function returns nothing
+	QualifiedParams  string // Parameter list with struct types qualified by the interface name
+	QualifiedReturns string // Return list with struct types qualified by the interface name
+	Mutability       string // State mutability of the function, empty if nonpayable
+	Constant         bool   // Indicator whether the function is read-only
+	Payable          bool   // Indicator whether the function accepts value
+}
+
+// tmplSolEvent contains the Solidity declaration of a precompile event.
+type tmplSolEvent struct {
+	Name      string // Name of the event
+	Params    string // Parameter list including indexed modifiers
+	Anonymous bool   // Indicator whether the event is anonymous
+}
+
+// tmplSolStruct contains the Solidity declaration of a struct used by the precompile.
+type tmplSolStruct struct {
+	Name   string   // Name of the struct
+	Fields []string // Field declarations of the struct
+}
+
+// tmplSourcePrecompileSolInterface is the Solidity interface template of a precompile.
+const tmplSourcePrecompileSolInterface = `//SPDX-License-Identifier: MIT
+// Code generated
+// This file is a generated Solidity interface of the {{.Type}} precompile.
+// The function selectors match the ABI used to generate the Go precompile.
+pragma solidity ^0.8.0;
+{{- if .Structs}}
+pragma experimental ABIEncoderV2;
+{{- end}}
+{{- if .AllowList}}
+import "./IAllowList.sol";
+{{- end}}
+
+interface I{{.Type}}{{if .AllowList}} is IAllowList{{end}} {
+{{- range .Structs}}
+  struct {{.Name}} {
+  {{- range .Fields}}
+    {{.}};
+  {{- end}}
+  }
+{{end}}
+{{- range .Events}}
+  event {{.Name}}({{.Params}}){{if .Anonymous}} anonymous{{end}};
+{{end}}
+{{- range .Funcs}}
+  // {{.Sig}}: {{.Selector}}
+  function {{.Name}}({{.Params}}) external{{if .Mutability}} {{.Mutability}}{{end}}{{if .Returns}} returns ({{.Returns}}){{end}};
+{{end -}}
+{{- if .Receive}}
+  // Executed if the precompile is called with no input data.
+  receive() external payable;
+{{end -}}
+{{- with .Fallback}}
+  // Executed with the raw input data if it does not match any function{{if not $.Receive}} or if there is no input data{{end}}.
+  fallback() external{{if .Payable}} payable{{end}};
+{{end -}}
+}
+`
+
+// tmplSourcePrecompileSolExample is the Solidity example contract template of a precompile.
+const tmplSourcePrecompileSolExample = `//SPDX-License-Identifier: MIT
+// Code generated
+// This file is a generated example contract calling the {{.Type}} precompile.
+// Use it as a starting point for the solidity tests of the precompile in contract-examples/test.
+pragma solidity ^0.8.0;
+{{- if .Structs}}
+pragma experimental ABIEncoderV2;
+{{- end}}
+
+{{if .AllowList}}import "./AllowList.sol";
+{{end -}}
+import "./I{{.Type}}.sol";
+
+// Example{{.Type}} shows how the {{.Type}} precompile can be used in a smart contract.
+{{- if .AllowList}}
+// All methods of [allowList] can be directly called.
+{{- end}}
+contract Example{{.Type}}{{if .AllowList}} is AllowList{{end}} {
+  // Precompiled {{.Type}} Contract Address
+  // CUSTOM CODE STARTS HERE: set this to {{.Type}}Address in the generated Go precompile
+  address constant {{.AddressName}} = 0x0000000000000000000000000000000000000000;
+  I{{.Type}} {{.Instance}} = I{{.Type}}({{.AddressName}});
+{{- if .AllowList}}
+
+  constructor() AllowList({{.AddressName}}) {}
+{{- end}}
+{{range .Funcs}}
+  function {{.Name}}({{.QualifiedParams}}) public{{if .Constant}} view{{else if .Payable}} payable{{end}}{{if not .Constant | and $.AllowList}} onlyEnabled{{end}}{{if .QualifiedReturns}} returns ({{.QualifiedReturns}}){{end}} {
+    {{if .Returns}}return {{end}}{{$.Instance}}.{{.Name}}{{if .Payable}}{value: msg.value}{{end}}({{.Args}});
+  }
+{{end -}}
+}
+`
+
+// tmplSourcePrecompileTestGo is the Go unit test template of a precompile.
+const tmplSourcePrecompileTestGo = `
+// Code generated
+// This file is a generated unit test scaffolding for the {{.Contract.Type}} precompile.
+// The file is generated by a template. Please inspect every code and comment in this file before use.
+
+// Place this file in the core package (e.g. core/{{decapitalise .Contract.Type}}_test.go) or in any other test package.
+// The precompile is run in the in-memory accessible state of precompile/testutils.
+// The expected values are placeholders. Each area requiring you to set an expected value is marked with
+// CUSTOM CODE to make them easy to find and modify.
+// Note that the gas cost cases fail until the gas costs of the precompile are set.
+// The fuzz tests compare the generated Pack/Unpack helpers against go-ethereum's abi package and can be run with
+// go test ./core -run=NONE -fuzz=Fuzz{{.Contract.Type}}Input (or Fuzz{{.Contract.Type}}Output).
+
+package core
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/precompile/testutils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	ethabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Reference imports to suppress errors from unused imports. This code and any unnecessary imports can be removed.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = vmerrs.ErrOutOfGas
+	_ = ethabi.JSON
+)
+
+{{$contract := .Contract}}
+{{$structs := .Structs}}
+func Test{{.Contract.Type}}Run(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := testutils.AdminAddr
+	{{- if .Contract.AllowList}}
+	enabledAddr := testutils.EnabledAddr
+	noRoleAddr := testutils.NoRoleAddr
+	{{- end}}
+	// insufficientGas is evaluated at runtime so that this file compiles before the gas costs are set.
+	insufficientGas := func(gasCost uint64) uint64 { return gasCost - 1 }
+	{{- range .Contract.Funcs}}
+	{{- if $contract.HasPerByteGasCost .Normalized.Name}}
+	// {{decapitalise .Normalized.Name}}Input is the input of {{.Original.Name}}, whose gas cost depends on the length of the input.
+	{{decapitalise .Normalized.Name}}Input := func() []byte {
+		input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args . $structs)}})
+		require.NoError(t, err)
+
+		return input
+	}
+	{{- end}}
+	{{- end}}
+
+	for name, test := range map[string]test{
+		{{- range .Contract.Funcs}}
+		{{- $method := .}}
+		"{{.Original.Name}}": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args $method $structs)}})
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: {{template "gas" (args $method $contract)}},
+			readOnly:    {{.Original.IsConstant}},
+			expectedRes: {{template "output" (args $method $structs)}},
+			{{- if not .Original.IsConstant}}
+			assertState: func(t *testing.T, state *state.StateDB) {
+				// CUSTOM CODE STARTS HERE
+				// assert the state changes of {{.Original.Name}}
+			},
+			{{- end}}
+		},
+		"{{.Original.Name}} insufficient gas": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args $method $structs)}})
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: insufficientGas({{template "gas" (args $method $contract)}}),
+			readOnly:    {{.Original.IsConstant}},
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		{{- if not .Original.IsConstant}}
+		"readOnly {{.Original.Name}} fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args $method $structs)}})
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: {{template "gas" (args $method $contract)}},
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		{{- if $contract.AllowList}}
+		"{{.Original.Name}} from enabled": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args $method $structs)}})
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: {{template "gas" (args $method $contract)}},
+			readOnly:    false,
+			expectedRes: {{template "output" (args $method $structs)}},
+		},
+		"{{.Original.Name}} from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args $method $structs)}})
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: {{template "gas" (args $method $contract)}},
+			readOnly:    false,
+			expectedErr: precompile.ErrCannot{{.Normalized.Name}}.Error(),
+		},
+		{{- end}}
+		{{- end}}
+		{{- end}}
+	} {
+		t.Run(name, func(t *testing.T) {
+			accessibleState := testutils.NewAccessibleState(t)
+			state := accessibleState.StateDB
+			{{- if .Contract.AllowList}}
+
+			// Set up the state so that each address has the expected permissions at the start.
+			precompile.Set{{.Contract.Type}}AllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.Set{{.Contract.Type}}AllowListStatus(state, enabledAddr, precompile.AllowListEnabled)
+			require.Equal(t, precompile.AllowListAdmin, precompile.Get{{.Contract.Type}}AllowListStatus(state, adminAddr))
+			require.Equal(t, precompile.AllowListEnabled, precompile.Get{{.Contract.Type}}AllowListStatus(state, enabledAddr))
+			require.Equal(t, precompile.AllowListNoRole, precompile.Get{{.Contract.Type}}AllowListStatus(state, noRoleAddr))
+			{{- end}}
+
+			ret, remainingGas, err := accessibleState.Run(t, precompile.{{.Contract.Type}}Precompile, test.caller, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func Test{{.Contract.Type}}PackUnpack(t *testing.T) {
+	{{- range .Contract.Funcs}}
+	{{- if len .Normalized.Inputs | lt 0}}
+	t.Run("{{.Original.Name}}", func(t *testing.T) {
+		// CUSTOM CODE STARTS HERE
+		// use representative values for the input of {{.Original.Name}}
+		input := {{template "input" (args . $structs)}}
+		packed, err := precompile.Pack{{.Normalized.Name}}(input)
+		require.NoError(t, err)
+		require.Equal(t, precompile.{{$contract.Type}}ABI.Methods["{{.Original.Name}}"].ID, packed[:4])
+
+		unpacked, err := precompile.Unpack{{capitalise .Normalized.Name}}Input(packed[4:])
+		require.NoError(t, err)
+		require.Equal(t, input, unpacked)
+	})
+	{{- end}}
+	{{- if len .Normalized.Outputs | lt 0}}
+	t.Run("{{.Original.Name}} output", func(t *testing.T) {
+		// CUSTOM CODE STARTS HERE
+		// use representative values for the output of {{.Original.Name}}
+		output := {{template "outputvalue" (args . $structs)}}
+		packed, err := precompile.Pack{{capitalise .Normalized.Name}}Output(output)
+		require.NoError(t, err)
+
+		unpacked, err := precompile.Unpack{{capitalise .Normalized.Name}}Output(packed)
+		require.NoError(t, err)
+		require.Equal(t, output, unpacked)
+	})
+	{{- end}}
+	{{- end}}
+}
+
+{{- $hasInputs := false}}
+{{- $hasOutputs := false}}
+{{- range .Contract.Funcs}}
+{{- if len .Normalized.Inputs | lt 0}}{{$hasInputs = true}}{{end}}
+{{- if len .Normalized.Outputs | lt 0}}{{$hasOutputs = true}}{{end}}
+{{- end}}
+{{if $hasInputs}}
+// Fuzz{{.Contract.Type}}Input checks that the generated input helpers accept only inputs that go-ethereum's abi
+// package accepts, and that they repack them to the same encoding. The first 4 bytes select the method.
+func Fuzz{{.Contract.Type}}Input(f *testing.F) {
+	{{- range .Contract.Funcs}}
+	{{- if len .Normalized.Inputs | lt 0}}
+	{
+		input, err := precompile.Pack{{.Normalized.Name}}({{template "input" (args . $structs)}})
+		require.NoError(f, err)
+		f.Add(input)
+	}
+	{{- end}}
+	{{- end}}
+
+	ethABI, err := ethabi.JSON(strings.NewReader(precompile.{{.Contract.Type}}RawABI))
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 4 {
+			return
+		}
+		method, err := ethABI.MethodById(data[:4])
+		if err != nil {
+			return
+		}
+		input := data[4:]
+
+		var packed []byte
+		switch method.Name {
+		{{- range .Contract.Funcs}}
+		{{- if len .Normalized.Inputs | lt 0}}
+		case "{{.Original.Name}}":
+			unpacked, err := precompile.Unpack{{capitalise .Normalized.Name}}Input(input)
+			if err != nil {
+				return
+			}
+			packed, err = precompile.Pack{{.Normalized.Name}}(unpacked)
+			require.NoError(t, err)
+		{{- end}}
+		{{- end}}
+		default:
+			return
+		}
+
+		values, err := method.Inputs.Unpack(input)
+		require.NoError(t, err)
+		expected, err := method.Inputs.Pack(values...)
+		require.NoError(t, err)
+		require.Equal(t, expected, packed[4:])
+	})
+}
+{{end}}
+{{if $hasOutputs}}
+// Fuzz{{.Contract.Type}}Output checks that the generated output helpers accept only outputs that go-ethereum's abi
+// package accepts, and that they repack them to the same encoding. The first 4 bytes select the method.
+func Fuzz{{.Contract.Type}}Output(f *testing.F) {
+	{{- range .Contract.Funcs}}
+	{{- if len .Normalized.Outputs | lt 0}}
+	{
+		output, err := precompile.Pack{{capitalise .Normalized.Name}}Output({{template "outputvalue" (args . $structs)}})
+		require.NoError(f, err)
+		f.Add(append(common.CopyBytes(precompile.{{$contract.Type}}ABI.Methods["{{.Original.Name}}"].ID), output...))
+	}
+	{{- end}}
+	{{- end}}
+
+	ethABI, err := ethabi.JSON(strings.NewReader(precompile.{{.Contract.Type}}RawABI))
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 4 {
+			return
+		}
+		method, err := ethABI.MethodById(data[:4])
+		if err != nil {
+			return
+		}
+		output := data[4:]
+
+		var packed []byte
+		switch method.Name {
+		{{- range .Contract.Funcs}}
+		{{- if len .Normalized.Outputs | lt 0}}
+		case "{{.Original.Name}}":
+			unpacked, err := precompile.Unpack{{capitalise .Normalized.Name}}Output(output)
+			if err != nil {
+				return
+			}
+			packed, err = precompile.Pack{{capitalise .Normalized.Name}}Output(unpacked)
+			require.NoError(t, err)
+		{{- end}}
+		{{- end}}
+		default:
+			return
+		}
+
+		values, err := method.Outputs.Unpack(output)
+		require.NoError(t, err)
+		expected, err := method.Outputs.Pack(values...)
+		require.NoError(t, err)
+		require.Equal(t, expected, packed)
+	})
+}
+{{end}}
+
+{{define "gas"}}
+{{- $method := index . 0}}
+{{- $contract := index . 1}}
+{{- if $contract.HasPerByteGasCost $method.Normalized.Name -}}
+precompile.{{$method.Normalized.Name}}RequiredGas({{decapitalise $method.Normalized.Name}}Input()[4:])
+{{- else -}}
+precompile.{{$method.Normalized.Name}}GasCost
+{{- end}}
+{{- end}}
+
+{{define "output"}}
+{{- $method := index . 0}}
+{{- $structs := index . 1}}
+{{- if len $method.Normalized.Outputs | eq 0 -}}
+[]byte{}
+{{- else -}}
+func() []byte {
+	// CUSTOM CODE STARTS HERE
+	// set the expected output of {{$method.Original.Name}}
+	res, err := precompile.Pack{{capitalise $method.Normalized.Name}}Output({{template "outputvalue" .}})
+	require.NoError(t, err)
+
+	return res
+}()
+{{- end}}
+{{- end}}
+
+{{define "outputvalue"}}
+{{- $method := index . 0}}
+{{- $structs := index . 1}}
+{{- if len $method.Normalized.Outputs | lt 1 -}}
+precompile.{{capitalise $method.Normalized.Name}}Output{
+	{{- range $method.Normalized.Outputs}}
+	{{capitalise .Name}}: {{bindtestvalue .Type $structs}},
+	{{- end}}
+}
+{{- else -}}
+{{bindtestvalue (index $method.Normalized.Outputs 0).Type $structs}}
+{{- end}}
+{{- end}}
+
+{{define "input"}}
+{{- $method := index . 0}}
+{{- $structs := index . 1}}
+{{- if len $method.Normalized.Inputs | lt 1 -}}
+precompile.{{capitalise $method.Normalized.Name}}Input{
+	{{- range $method.Normalized.Inputs}}
+	{{capitalise .Name}}: {{bindtestvalue .Type $structs}},
+	{{- end}}
+}
+{{- else if len $method.Normalized.Inputs | eq 1 -}}
+{{bindtestvalue (index $method.Normalized.Inputs 0).Type $structs}}
+{{- end}}
+{{- end}}
 `
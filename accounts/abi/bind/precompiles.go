@@ -0,0 +1,211 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrNotPrecompileCall is returned when summarizing a transaction that does not call a precompile
+// declaring an ABI.
+var ErrNotPrecompileCall = errors.New("not a call to a precompile with an ABI")
+
+// AllowListTransactor builds the transactions modifying the allow list of a precompile, such as
+// the TxAllowList, the ContractDeployerAllowList or the allow list of any other allow list precompile.
+type AllowListTransactor struct {
+	contract *BoundContract
+}
+
+// NewAllowListTransactor returns a transactor of the allow list of the precompile at [address].
+func NewAllowListTransactor(address common.Address, transactor ContractTransactor) *AllowListTransactor {
+	return &AllowListTransactor{contract: NewBoundContract(address, precompile.AllowListABI, nil, transactor, nil)}
+}
+
+// SetAdmin grants the admin role to [addr], allowing it to modify the allow list.
+func (t *AllowListTransactor) SetAdmin(opts *TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "setAdmin", addr)
+}
+
+// SetEnabled grants the enabled role to [addr], allowing it to use the precompile.
+func (t *AllowListTransactor) SetEnabled(opts *TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "setEnabled", addr)
+}
+
+// SetNone revokes the role of [addr].
+func (t *AllowListTransactor) SetNone(opts *TransactOpts, addr common.Address) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "setNone", addr)
+}
+
+// NativeMinterTransactor builds the transactions of the NativeMinter precompile.
+type NativeMinterTransactor struct {
+	AllowListTransactor
+	contract *BoundContract
+}
+
+// NewNativeMinterTransactor returns a transactor of the NativeMinter precompile.
+func NewNativeMinterTransactor(transactor ContractTransactor) *NativeMinterTransactor {
+	return &NativeMinterTransactor{
+		AllowListTransactor: *NewAllowListTransactor(precompile.ContractNativeMinterAddress, transactor),
+		contract:            NewBoundContract(precompile.ContractNativeMinterAddress, precompile.ContractNativeMinterABI, nil, transactor, nil),
+	}
+}
+
+// MintNativeCoin mints [amount] wei of the native coin to [addr].
+func (t *NativeMinterTransactor) MintNativeCoin(opts *TransactOpts, addr common.Address, amount *big.Int) (*types.Transaction, error) {
+	return t.contract.Transact(opts, "mintNativeCoin", addr, amount)
+}
+
+// FeeConfigManagerTransactor builds the transactions of the FeeConfigManager precompile.
+type FeeConfigManagerTransactor struct {
+	AllowListTransactor
+	contract *BoundContract
+}
+
+// NewFeeConfigManagerTransactor returns a transactor of the FeeConfigManager precompile.
+func NewFeeConfigManagerTransactor(transactor ContractTransactor) *FeeConfigManagerTransactor {
+	return &FeeConfigManagerTransactor{
+		AllowListTransactor: *NewAllowListTransactor(precompile.FeeConfigManagerAddress, transactor),
+		contract:            NewBoundContract(precompile.FeeConfigManagerAddress, precompile.FeeConfigManagerABI, nil, transactor, nil),
+	}
+}
+
+// SetFeeConfig replaces the fee config of the chain with [feeConfig].
+func (t *FeeConfigManagerTransactor) SetFeeConfig(opts *TransactOpts, feeConfig commontype.FeeConfig) (*types.Transaction, error) {
+	if err := feeConfig.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid fee config: %w", err)
+	}
+	input, err := precompile.PackSetFeeConfig(feeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return t.contract.RawTransact(opts, input)
+}
+
+// CallArgument is a decoded argument of a precompile call, typed as in EIP-712 structured data.
+type CallArgument struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// CallSummary is the human-readable description of a call to a precompile, for signers to review the
+// decoded call (e.g. before confirming it on a hardware wallet that only displays its hash or calldata).
+type CallSummary struct {
+	// Precompile is the config key of the called precompile, such as "contractNativeMinterConfig".
+	Precompile string         `json:"precompile"`
+	Address    common.Address `json:"address"`
+	Method     string         `json:"method"`
+	Arguments  []CallArgument `json:"arguments"`
+	// Value is the amount of wei sent with the call.
+	Value *big.Int `json:"value"`
+}
+
+// String formats the summary as the method signature followed by one argument per line.
+func (s *CallSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s).%s", s.Precompile, s.Address, s.Method)
+	for _, arg := range s.Arguments {
+		fmt.Fprintf(&b, "\n  %s (%s): %s", arg.Name, arg.Type, arg.Value)
+	}
+	if s.Value != nil && s.Value.Sign() != 0 {
+		fmt.Fprintf(&b, "\n  value: %s wei", s.Value)
+	}
+	return b.String()
+}
+
+// SummarizePrecompileCall decodes [data] of a call to the precompile registered at [to].
+// Returns [ErrNotPrecompileCall] if no precompile declaring an ABI is registered at [to].
+func SummarizePrecompileCall(to common.Address, data []byte) (*CallSummary, error) {
+	module, ok := precompile.GetRegisteredModuleByAddress(to)
+	if !ok || module.ABI == nil {
+		return nil, ErrNotPrecompileCall
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("calldata of length %d is shorter than a function selector", len(data))
+	}
+	method, err := module.ABI.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the arguments of %s: %w", method.Name, err)
+	}
+	summary := &CallSummary{
+		Precompile: module.ConfigKey,
+		Address:    to,
+		Method:     method.Name,
+		Arguments:  make([]CallArgument, len(method.Inputs)),
+	}
+	for i, input := range method.Inputs {
+		summary.Arguments[i] = CallArgument{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatArgument(input.Type, values[i]),
+		}
+	}
+	return summary, nil
+}
+
+// SummarizeTransaction decodes the precompile call of [tx].
+// Returns [ErrNotPrecompileCall] if [tx] does not call a precompile declaring an ABI.
+func SummarizeTransaction(tx *types.Transaction) (*CallSummary, error) {
+	if tx.To() == nil {
+		return nil, ErrNotPrecompileCall
+	}
+	summary, err := SummarizePrecompileCall(*tx.To(), tx.Data())
+	if err != nil {
+		return nil, err
+	}
+	summary.Value = tx.Value()
+	return summary, nil
+}
+
+// WithCallSummary returns a copy of [opts] whose signer passes the summary of the precompile calls to
+// [confirm] before signing them, refusing to sign if [confirm] returns an error. Transactions that do
+// not call a precompile are signed without confirmation.
+func WithCallSummary(opts *TransactOpts, confirm func(*CallSummary) error) *TransactOpts {
+	confirmed := *opts
+	signer := opts.Signer
+	confirmed.Signer = func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		summary, err := SummarizeTransaction(tx)
+		switch {
+		case errors.Is(err, ErrNotPrecompileCall):
+		case err != nil:
+			return nil, err
+		default:
+			if err := confirm(summary); err != nil {
+				return nil, err
+			}
+		}
+		return signer(from, tx)
+	}
+	return &confirmed
+}
+
+// formatArgument formats [value] of type [typ] for display. Addresses are checksummed and
+// integers are in base 10.
+func formatArgument(typ abi.Type, value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return fmt.Sprintf("%#x", v)
+	}
+	if typ.T == abi.FixedBytesTy {
+		return fmt.Sprintf("%#x", value)
+	}
+	return fmt.Sprint(value)
+}
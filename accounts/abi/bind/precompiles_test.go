@@ -0,0 +1,121 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package bind_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi/bind"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newPrecompileTransactOpts() *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From:     common.Address{1},
+		Signer:   mockSign,
+		GasLimit: 100_000,
+		NoSend:   true,
+	}
+}
+
+func TestPrecompileTransactors(t *testing.T) {
+	transactor := &mockTransactor{baseFee: big.NewInt(100), gasTipCap: big.NewInt(1)}
+	opts := newPrecompileTransactOpts()
+	addr := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+
+	tx, err := bind.NewAllowListTransactor(precompile.TxAllowListAddress, transactor).SetAdmin(opts, addr)
+	require.NoError(t, err)
+	require.Equal(t, precompile.TxAllowListAddress, *tx.To())
+	expected, err := precompile.PackModifyAllowList(addr, precompile.AllowListAdmin)
+	require.NoError(t, err)
+	require.Equal(t, expected, tx.Data())
+
+	minter := bind.NewNativeMinterTransactor(transactor)
+	tx, err = minter.MintNativeCoin(opts, addr, big.NewInt(params.Ether))
+	require.NoError(t, err)
+	require.Equal(t, precompile.ContractNativeMinterAddress, *tx.To())
+	expected, err = precompile.PackMintInput(addr, big.NewInt(params.Ether))
+	require.NoError(t, err)
+	require.Equal(t, expected, tx.Data())
+	tx, err = minter.SetNone(opts, addr)
+	require.NoError(t, err)
+	require.Equal(t, precompile.ContractNativeMinterAddress, *tx.To())
+
+	feeManager := bind.NewFeeConfigManagerTransactor(transactor)
+	tx, err = feeManager.SetFeeConfig(opts, params.DefaultFeeConfig)
+	require.NoError(t, err)
+	require.Equal(t, precompile.FeeConfigManagerAddress, *tx.To())
+	expected, err = precompile.PackSetFeeConfig(params.DefaultFeeConfig)
+	require.NoError(t, err)
+	require.Equal(t, expected, tx.Data())
+	invalidFeeConfig := params.DefaultFeeConfig
+	invalidFeeConfig.TargetBlockRate = 0
+	_, err = feeManager.SetFeeConfig(opts, invalidFeeConfig)
+	require.ErrorContains(t, err, "invalid fee config")
+}
+
+func TestSummarizePrecompileCall(t *testing.T) {
+	addr := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+	input, err := precompile.PackMintInput(addr, big.NewInt(params.Ether))
+	require.NoError(t, err)
+
+	summary, err := bind.SummarizePrecompileCall(precompile.ContractNativeMinterAddress, input)
+	require.NoError(t, err)
+	require.Equal(t, precompile.ContractNativeMinterConfigKey, summary.Precompile)
+	require.Equal(t, "mintNativeCoin", summary.Method)
+	require.Equal(t, []bind.CallArgument{
+		{Name: "addr", Type: "address", Value: addr.Hex()},
+		{Name: "amount", Type: "uint256", Value: "1000000000000000000"},
+	}, summary.Arguments)
+	require.Equal(t, "contractNativeMinterConfig (0x0200000000000000000000000000000000000001).mintNativeCoin\n"+
+		"  addr (address): 0x71562b71999873DB5b286dF957af199Ec94617F7\n"+
+		"  amount (uint256): 1000000000000000000", summary.String())
+
+	input, err = precompile.PackSetFeeConfig(params.DefaultFeeConfig)
+	require.NoError(t, err)
+	summary, err = bind.SummarizePrecompileCall(precompile.FeeConfigManagerAddress, input)
+	require.NoError(t, err)
+	require.Equal(t, "setFeeConfig", summary.Method)
+	require.Len(t, summary.Arguments, 8)
+	require.Equal(t, bind.CallArgument{Name: "gasLimit", Type: "uint256", Value: params.DefaultFeeConfig.GasLimit.String()}, summary.Arguments[0])
+
+	_, err = bind.SummarizePrecompileCall(common.Address{1}, input)
+	require.ErrorIs(t, err, bind.ErrNotPrecompileCall)
+	_, err = bind.SummarizePrecompileCall(precompile.FeeConfigManagerAddress, input[:10])
+	require.Error(t, err)
+}
+
+func TestWithCallSummary(t *testing.T) {
+	transactor := &mockTransactor{baseFee: big.NewInt(100), gasTipCap: big.NewInt(1)}
+	addr := common.Address{0xaa}
+
+	var summaries []*bind.CallSummary
+	opts := bind.WithCallSummary(newPrecompileTransactOpts(), func(summary *bind.CallSummary) error {
+		summaries = append(summaries, summary)
+		if summary.Method == "setAdmin" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	allowList := bind.NewAllowListTransactor(precompile.ContractDeployerAllowListAddress, transactor)
+	_, err := allowList.SetEnabled(opts, addr)
+	require.NoError(t, err)
+	_, err = allowList.SetAdmin(opts, addr)
+	require.ErrorContains(t, err, "rejected")
+	require.Len(t, summaries, 2)
+	require.Equal(t, "setEnabled", summaries[0].Method)
+	require.Equal(t, addr.Hex(), summaries[0].Arguments[0].Value)
+
+	// Transactions that do not call a precompile are signed without confirmation
+	tx, err := opts.Signer(common.Address{1}, types.NewTx(&types.LegacyTx{To: &addr}))
+	require.NoError(t, err)
+	require.NotNil(t, tx)
+	require.Len(t, summaries, 2)
+}
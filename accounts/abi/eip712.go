@@ -0,0 +1,152 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package abi
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// typedDataDomainType is the EIP-712 type of [TypedDataDomain].
+const typedDataDomainType = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+
+// ErrInvalidTypedDataSignature is returned when a signature of typed data is malformed.
+var ErrInvalidTypedDataSignature = errors.New("invalid typed data signature")
+
+// TypedDataDomain is the EIP-712 domain of the typed structured data signed for a contract,
+// which prevents the signatures from being replayed on other contracts or chains.
+type TypedDataDomain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract common.Address
+}
+
+// Separator returns the EIP-712 domain separator of [d].
+func (d TypedDataDomain) Separator() common.Hash {
+	chainID := d.ChainID
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	return crypto.Keccak256Hash(
+		crypto.Keccak256([]byte(typedDataDomainType)),
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		common.BigToHash(chainID).Bytes(),
+		common.LeftPadBytes(d.VerifyingContract.Bytes(), common.HashLength),
+	)
+}
+
+// HashTypedDataStruct returns the EIP-712 hashStruct of the struct with the encoded type [encodedType]
+// (e.g. "Mail(address to,string contents)") and the member [values] in the order of the type.
+// Members must be atomic types or bytes and strings, which are hashed as required by EIP-712.
+// Arrays and nested structs are not supported.
+func HashTypedDataStruct(encodedType string, values ...interface{}) (common.Hash, error) {
+	members, err := parseTypedDataType(encodedType)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(members) != len(values) {
+		return common.Hash{}, fmt.Errorf("typed data %s has %d members, got %d values", encodedType, len(members), len(values))
+	}
+	encoded := crypto.Keccak256([]byte(encodedType))
+	for i, member := range members {
+		value := values[i]
+		switch member.T {
+		case StringTy, BytesTy:
+			var data []byte
+			switch v := value.(type) {
+			case string:
+				data = []byte(v)
+			case []byte:
+				data = v
+			default:
+				return common.Hash{}, fmt.Errorf("typed data %s: invalid value of type %T for member %d", encodedType, value, i)
+			}
+			encoded = append(encoded, crypto.Keccak256(data)...)
+		default:
+			packed, err := Arguments{{Type: member}}.Pack(value)
+			if err != nil {
+				return common.Hash{}, fmt.Errorf("typed data %s: member %d: %w", encodedType, i, err)
+			}
+			encoded = append(encoded, packed...)
+		}
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// parseTypedDataType returns the types of the members of [encodedType].
+func parseTypedDataType(encodedType string) ([]Type, error) {
+	open := strings.IndexByte(encodedType, '(')
+	if open <= 0 || !strings.HasSuffix(encodedType, ")") {
+		return nil, fmt.Errorf("invalid typed data type %q", encodedType)
+	}
+	fields := encodedType[open+1 : len(encodedType)-1]
+	if fields == "" {
+		return nil, nil
+	}
+	var members []Type
+	for _, field := range strings.Split(fields, ",") {
+		parts := strings.Fields(field)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid member %q of typed data type %q", field, encodedType)
+		}
+		typ, err := NewType(parts[0], "", nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid member %q of typed data type %q: %w", field, encodedType, err)
+		}
+		switch typ.T {
+		case SliceTy, ArrayTy, TupleTy:
+			return nil, fmt.Errorf("unsupported member %q of typed data type %q", field, encodedType)
+		}
+		members = append(members, typ)
+	}
+	return members, nil
+}
+
+// TypedDataHash returns the EIP-712 digest signed for the struct with hash [structHash] in [domain],
+// keccak256("\x19\x01" ‖ domainSeparator ‖ structHash).
+func TypedDataHash(domain TypedDataDomain, structHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Separator().Bytes(), structHash.Bytes())
+}
+
+// SignTypedData signs the struct with hash [structHash] in [domain] with [key]. The signature is
+// returned in the [R || S || V] format with V 27 or 28, as produced by eth_signTypedData.
+func SignTypedData(key *ecdsa.PrivateKey, domain TypedDataDomain, structHash common.Hash) ([]byte, error) {
+	sig, err := crypto.Sign(TypedDataHash(domain, structHash).Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// RecoverTypedDataSigner returns the address that produced [sig] for the struct with hash [structHash]
+// in [domain]. [sig] must be in the format returned by [SignTypedData], with a low S value.
+func RecoverTypedDataSigner(domain TypedDataDomain, structHash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: length %d", ErrInvalidTypedDataSignature, len(sig))
+	}
+	v := sig[crypto.RecoveryIDOffset]
+	if v != 27 && v != 28 {
+		return common.Address{}, fmt.Errorf("%w: v %d", ErrInvalidTypedDataSignature, v)
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(v-27, r, s, true) {
+		return common.Address{}, fmt.Errorf("%w: invalid r, s values", ErrInvalidTypedDataSignature)
+	}
+	recoverable := common.CopyBytes(sig)
+	recoverable[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(TypedDataHash(domain, structHash).Bytes(), recoverable)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidTypedDataSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,129 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package abi
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var testTypedDataDomain = TypedDataDomain{
+	Name:              "Test",
+	Version:           "1",
+	ChainID:           big.NewInt(43114),
+	VerifyingContract: common.HexToAddress("0x0200000000000000000000000000000000000003"),
+}
+
+// Tests that typed data is hashed like eth_signTypedData does.
+func TestTypedDataHash(t *testing.T) {
+	to := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	structHash, err := HashTypedDataStruct("Note(address to,string contents,bytes data,uint256 value,bool flag)",
+		to, "hello", []byte{0x01, 0x02}, big.NewInt(42), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := TypedDataHash(testTypedDataDomain, structHash)
+
+	expected, _, err := apitypes.TypedDataAndHash(apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Note": {
+				{Name: "to", Type: "address"},
+				{Name: "contents", Type: "string"},
+				{Name: "data", Type: "bytes"},
+				{Name: "value", Type: "uint256"},
+				{Name: "flag", Type: "bool"},
+			},
+		},
+		PrimaryType: "Note",
+		Domain: apitypes.TypedDataDomain{
+			Name:              testTypedDataDomain.Name,
+			Version:           testTypedDataDomain.Version,
+			ChainId:           (*math.HexOrDecimal256)(testTypedDataDomain.ChainID),
+			VerifyingContract: testTypedDataDomain.VerifyingContract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":       to.Hex(),
+			"contents": "hello",
+			"data":     []byte{0x01, 0x02},
+			"value":    "42",
+			"flag":     true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(hash.Bytes(), expected) {
+		t.Fatalf("typed data hash mismatch: have %x, want %x", hash, expected)
+	}
+}
+
+func TestHashTypedDataStructInvalid(t *testing.T) {
+	for _, tt := range []struct {
+		encodedType string
+		values      []interface{}
+	}{
+		{"Note", nil},
+		{"Note(address)", []interface{}{common.Address{}}},
+		{"Note(uint256[] values)", []interface{}{[]*big.Int{}}},
+		{"Note(address to)", nil},
+		{"Note(address to)", []interface{}{big.NewInt(1)}},
+		{"Note(string contents)", []interface{}{1}},
+	} {
+		if _, err := HashTypedDataStruct(tt.encodedType, tt.values...); err == nil {
+			t.Fatalf("expected hashing %s with %v to fail", tt.encodedType, tt.values)
+		}
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	structHash, err := HashTypedDataStruct("Note(uint256 value)", big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignTypedData(key, testTypedDataDomain, structHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := RecoverTypedDataSigner(testTypedDataDomain, structHash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("recovered signer %s, expected %s", signer, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	// The signature is bound to the domain.
+	otherDomain := testTypedDataDomain
+	otherDomain.ChainID = big.NewInt(1)
+	if signer, err := RecoverTypedDataSigner(otherDomain, structHash, sig); err == nil && signer == crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("expected signature not to be valid in another domain")
+	}
+
+	// Malformed and malleable signatures are rejected.
+	invalidV := common.CopyBytes(sig)
+	invalidV[crypto.RecoveryIDOffset] -= 27
+	highS := common.CopyBytes(sig)
+	s := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+	copy(highS[32:64], common.BigToHash(s).Bytes())
+	highS[crypto.RecoveryIDOffset] ^= 1
+	for name, sig := range map[string][]byte{"short": sig[:64], "invalid v": invalidV, "high s": highS} {
+		if _, err := RecoverTypedDataSigner(testTypedDataDomain, structHash, sig); !errors.Is(err, ErrInvalidTypedDataSignature) {
+			t.Fatalf("%s: expected %v, got %v", name, ErrInvalidTypedDataSignature, err)
+		}
+	}
+}
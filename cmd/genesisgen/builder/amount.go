@@ -0,0 +1,95 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// units are the multipliers of the units accepted in amounts.
+var units = map[string]*big.Int{
+	"wei":   big.NewInt(params.Wei),
+	"gwei":  big.NewInt(params.GWei),
+	"ether": big.NewInt(params.Ether),
+}
+
+// Amount is an amount of the native token, in wei. It is decoded from an integer, or from a
+// string holding a decimal or hex integer optionally followed by a unit, such as "1000 ether"
+// or "1.5 gwei". Amounts over 2^63 must be given as strings, since YAML decodes larger
+// integers into floats.
+type Amount big.Int
+
+// ParseAmount parses [s] into an amount in wei, as described in [Amount].
+func ParseAmount(s string) (*big.Int, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	multiplier := units["wei"]
+	if len(fields) == 2 {
+		unit, ok := units[strings.ToLower(fields[1])]
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q: unknown unit %q", s, fields[1])
+		}
+		multiplier = unit
+	}
+
+	if strings.HasPrefix(fields[0], "0x") || strings.HasPrefix(fields[0], "0X") {
+		value, ok := math.ParseBig256(fields[0])
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q", s)
+		}
+		return value.Mul(value, multiplier), nil
+	}
+	value, ok := new(big.Rat).SetString(fields[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	value.Mul(value, new(big.Rat).SetInt(multiplier))
+	if !value.IsInt() {
+		return nil, fmt.Errorf("invalid amount %q: not a whole number of wei", s)
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q: negative", s)
+	}
+	return value.Num(), nil
+}
+
+// ToInt returns the amount in wei.
+func (a *Amount) ToInt() *big.Int {
+	return (*big.Int)(a)
+}
+
+// MarshalJSON encodes the amount as a decimal string of wei.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.ToInt().String())
+}
+
+// UnmarshalJSON decodes an amount as described in [Amount].
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		if bytes.ContainsAny(data, ".eE") {
+			return fmt.Errorf("amount %s is not an integer, amounts over 2^63 must be quoted", data)
+		}
+		s = string(data)
+	}
+	value, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = Amount(*value)
+	return nil
+}
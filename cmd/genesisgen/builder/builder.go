@@ -0,0 +1,231 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package builder builds the genesis of a subnet-evm chain from a [Spec], and verifies it
+// with the checks the VM runs on the genesis it is initialized with.
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	errNoChainID          = errors.New("chain ID must be set and positive")
+	errNoBalance          = errors.New("allocation has no balance")
+	errDuplicateAlloc     = errors.New("address allocated more than once")
+	errUnknownPrecompile  = errors.New("unknown precompile")
+	errNoAirdropAmount    = errors.New("airdrop has no amount")
+	errNoAirdropAddresses = errors.New("airdrop has no addresses")
+)
+
+// Spec describes the genesis of a chain. It is decoded from YAML or JSON.
+type Spec struct {
+	ChainID   *big.Int `json:"chainID"`
+	Timestamp uint64   `json:"timestamp,omitempty"`
+	// FeeConfig defaults to [params.DefaultFeeConfig]. The gas limit of the genesis block is
+	// the gas limit of the fee config.
+	FeeConfig          *commontype.FeeConfig `json:"feeConfig,omitempty"`
+	AllowFeeRecipients bool                  `json:"allowFeeRecipients,omitempty"`
+	Alloc              []Allocation          `json:"alloc,omitempty"`
+	Airdrop            *Airdrop              `json:"airdrop,omitempty"`
+	// Precompiles are the configs of the stateful precompiles enabled by the genesis, keyed by
+	// their config key (e.g. "txAllowListConfig"). Configs that set neither a blockTimestamp
+	// nor a blockNumber are activated at the timestamp of the genesis.
+	Precompiles map[string]json.RawMessage `json:"precompiles,omitempty"`
+}
+
+// Allocation is an account of the genesis state.
+type Allocation struct {
+	Address common.Address              `json:"address"`
+	Balance *Amount                     `json:"balance"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+}
+
+// Airdrop funds each of [Addresses], and of the addresses listed in [File], with [Amount].
+// The airdrop data is not part of the genesis, and must be provided to the VM with the
+// "airdrop" config.
+type Airdrop struct {
+	Amount    *Amount          `json:"amount"`
+	Addresses []common.Address `json:"addresses,omitempty"`
+	// File is the path of a JSON airdrop file, in the format read by the VM.
+	File string `json:"file,omitempty"`
+}
+
+// ParseSpec decodes the YAML or JSON [data] into a Spec.
+func ParseSpec(data []byte) (*Spec, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	spec := new(Spec)
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, utils.QualifyJSONError(data, spec, err)
+	}
+	return spec, nil
+}
+
+// LoadSpec reads the Spec at [path]. The path of its airdrop file is relative to [path].
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	if spec.Airdrop != nil && spec.Airdrop.File != "" && !filepath.IsAbs(spec.Airdrop.File) {
+		spec.Airdrop.File = filepath.Join(filepath.Dir(path), spec.Airdrop.File)
+	}
+	return spec, nil
+}
+
+// Build returns the genesis described by [spec], after verifying it as the VM does. If the
+// genesis has an airdrop, its AirdropData holds the airdrop file to provide to the VM.
+func Build(spec *Spec) (*core.Genesis, error) {
+	if spec.ChainID == nil || spec.ChainID.Sign() <= 0 {
+		return nil, errNoChainID
+	}
+	config := *params.SubnetEVMDefaultChainConfig
+	config.ChainID = new(big.Int).Set(spec.ChainID)
+	config.AllowFeeRecipients = spec.AllowFeeRecipients
+	if spec.FeeConfig != nil {
+		config.FeeConfig = *spec.FeeConfig
+	}
+	if err := buildPrecompiles(&config, spec.Precompiles, spec.Timestamp); err != nil {
+		return nil, err
+	}
+	// Verify the fee config before its gas limit is used for the genesis block
+	if err := config.FeeConfig.Verify(); err != nil {
+		return nil, utils.WithFieldPath("feeConfig", err)
+	}
+
+	alloc, err := buildAlloc(spec.Alloc)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &core.Genesis{
+		Config:     &config,
+		Timestamp:  spec.Timestamp,
+		GasLimit:   config.FeeConfig.GasLimit.Uint64(),
+		Difficulty: big.NewInt(0),
+		Alloc:      alloc,
+	}
+	if spec.Airdrop != nil {
+		if err := buildAirdrop(genesis, spec.Airdrop); err != nil {
+			return nil, utils.WithFieldPath("airdrop", err)
+		}
+	}
+
+	if err := genesis.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid genesis: %w", err)
+	}
+	return genesis, nil
+}
+
+// buildPrecompiles sets the precompile [configs] in [config], activating those that do not
+// set their activation at [timestamp].
+func buildPrecompiles(config *params.ChainConfig, configs map[string]json.RawMessage, timestamp uint64) error {
+	if len(configs) == 0 {
+		return nil
+	}
+	activated := make(map[string]json.RawMessage, len(configs))
+	for key, raw := range configs {
+		if _, ok := precompile.GetRegisteredModule(key); !ok {
+			return fmt.Errorf("%w %q", errUnknownPrecompile, key)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return utils.WithFieldPath("precompiles."+key, err)
+		}
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+		_, hasTimestamp := fields["blockTimestamp"]
+		_, hasNumber := fields["blockNumber"]
+		if !hasTimestamp && !hasNumber {
+			fields["blockTimestamp"] = json.RawMessage(fmt.Sprint(timestamp))
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		activated[key] = data
+	}
+	data, err := json.Marshal(activated)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &config.PrecompileUpgrade); err != nil {
+		return utils.WithFieldPath("precompiles", err)
+	}
+	return nil
+}
+
+// buildAlloc returns the genesis state of the [allocations].
+func buildAlloc(allocations []Allocation) (core.GenesisAlloc, error) {
+	alloc := make(core.GenesisAlloc, len(allocations))
+	for i, allocation := range allocations {
+		if allocation.Balance == nil {
+			return nil, utils.WithFieldPath(fmt.Sprintf("alloc[%d]", i), errNoBalance)
+		}
+		if _, ok := alloc[allocation.Address]; ok {
+			return nil, utils.WithFieldPath(fmt.Sprintf("alloc[%d]", i), fmt.Errorf("%w: %s", errDuplicateAlloc, allocation.Address))
+		}
+		alloc[allocation.Address] = core.GenesisAccount{
+			Balance: new(big.Int).Set(allocation.Balance.ToInt()),
+			Code:    allocation.Code,
+			Storage: allocation.Storage,
+			Nonce:   allocation.Nonce,
+		}
+	}
+	return alloc, nil
+}
+
+// buildAirdrop sets the airdrop of [genesis] to the [airdrop] amount for its addresses.
+func buildAirdrop(genesis *core.Genesis, airdrop *Airdrop) error {
+	if airdrop.Amount == nil {
+		return errNoAirdropAmount
+	}
+	var recipients []*core.Airdrop
+	if airdrop.File != "" {
+		data, err := os.ReadFile(airdrop.File)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &recipients); err != nil {
+			return fmt.Errorf("invalid airdrop file %s: %w", airdrop.File, err)
+		}
+	}
+	for _, address := range airdrop.Addresses {
+		recipients = append(recipients, &core.Airdrop{Address: address})
+	}
+	if len(recipients) == 0 {
+		return errNoAirdropAddresses
+	}
+	data, err := json.MarshalIndent(recipients, "", "  ")
+	if err != nil {
+		return err
+	}
+	genesis.AirdropHash = crypto.Keccak256Hash(data)
+	genesis.AirdropAmount = new(big.Int).Set(airdrop.Amount.ToInt())
+	genesis.AirdropData = data
+	return nil
+}
@@ -0,0 +1,150 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package builder
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	admin   = common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	enabled = common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		expected *big.Int
+		err      bool
+	}{
+		{amount: "1000", expected: big.NewInt(1000)},
+		{amount: "0x3e8", expected: big.NewInt(1000)},
+		{amount: "25 gwei", expected: big.NewInt(25_000_000_000)},
+		{amount: "1.5 ether", expected: big.NewInt(1_500_000_000_000_000_000)},
+		{amount: "1000000 Ether", expected: new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))},
+		{amount: "1.5", err: true},
+		{amount: "-1", err: true},
+		{amount: "1 eth", err: true},
+		{amount: "", err: true},
+	}
+	for _, test := range tests {
+		amount, err := ParseAmount(test.amount)
+		if test.err {
+			require.Error(t, err, test.amount)
+			continue
+		}
+		require.NoError(t, err, test.amount)
+		require.Equal(t, test.expected, amount, test.amount)
+	}
+
+	// Amounts decoded by YAML as floats are rejected rather than rounded
+	_, err := ParseSpec([]byte("chainID: 1\nalloc:\n  - address: 0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC\n    balance: 1000000000000000000000\n"))
+	require.ErrorContains(t, err, "must be quoted")
+}
+
+func TestBuildSpec(t *testing.T) {
+	spec, err := LoadSpec("testdata/spec.yaml")
+	require.NoError(t, err)
+	genesis, err := Build(spec)
+	require.NoError(t, err)
+
+	require.Equal(t, big.NewInt(99999), genesis.Config.ChainID)
+	require.EqualValues(t, 15_000_000, genesis.GasLimit)
+	require.Equal(t, genesis.Config.FeeConfig.GasLimit.Uint64(), genesis.GasLimit)
+	require.Len(t, genesis.Alloc, 2)
+	require.Equal(t, new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether)), genesis.Alloc[admin].Balance)
+	require.Equal(t, big.NewInt(params.Ether), genesis.Alloc[enabled].Balance)
+
+	// The precompiles without an activation are activated at genesis
+	deployerConfig := genesis.Config.PrecompileUpgrade.GetConfig(precompile.ContractDeployerAllowListConfigKey)
+	require.NotNil(t, deployerConfig)
+	require.Equal(t, big.NewInt(0), deployerConfig.Timestamp())
+	txAllowListConfig := genesis.Config.PrecompileUpgrade.GetConfig(precompile.TxAllowListConfigKey)
+	require.NotNil(t, txAllowListConfig)
+	require.Equal(t, big.NewInt(1_700_000_000), txAllowListConfig.Timestamp())
+
+	// The airdrop includes the addresses of the airdrop file and of the spec
+	var airdrop []*core.Airdrop
+	require.NoError(t, json.Unmarshal(genesis.AirdropData, &airdrop))
+	require.Len(t, airdrop, 3)
+	require.Equal(t, enabled, airdrop[2].Address)
+	require.Equal(t, crypto.Keccak256Hash(genesis.AirdropData), genesis.AirdropHash)
+	require.Equal(t, new(big.Int).Mul(big.NewInt(10), big.NewInt(params.Ether)), genesis.AirdropAmount)
+
+	// The genesis is decoded and created as the VM does
+	genesisJSON, err := json.Marshal(genesis)
+	require.NoError(t, err)
+	decoded := new(core.Genesis)
+	require.NoError(t, json.Unmarshal(genesisJSON, decoded))
+	decoded.AirdropData = genesis.AirdropData
+	require.NoError(t, decoded.Verify())
+	block := decoded.ToBlock(nil)
+	require.Equal(t, genesis.ToBlock(nil).Hash(), block.Hash())
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{
+			name: "no chain ID",
+			spec: `{}`,
+			err:  errNoChainID.Error(),
+		},
+		{
+			name: "invalid fee config",
+			spec: `{"chainID": 1, "feeConfig": {"gasLimit": 0}}`,
+			err:  "feeConfig",
+		},
+		{
+			name: "unknown precompile",
+			spec: `{"chainID": 1, "precompiles": {"txAllowList": {}}}`,
+			err:  `unknown precompile "txAllowList"`,
+		},
+		{
+			name: "invalid precompile config",
+			spec: `{"chainID": 1, "precompiles": {"txAllowListConfig": {"adminAddresses": "0x01"}}}`,
+			err:  "txAllowListConfig",
+		},
+		{
+			name: "missing balance",
+			spec: `{"chainID": 1, "alloc": [{"address": "0x0100000000000000000000000000000000000000"}]}`,
+			err:  "alloc[0]",
+		},
+		{
+			name: "duplicate allocation",
+			spec: `{"chainID": 1, "alloc": [{"address": "0x0100000000000000000000000000000000000000", "balance": 1}, {"address": "0x0100000000000000000000000000000000000000", "balance": 2}]}`,
+			err:  errDuplicateAlloc.Error(),
+		},
+		{
+			name: "code at precompile address",
+			spec: `{"chainID": 1, "alloc": [{"address": "0x0200000000000000000000000000000000000002", "balance": 0, "code": "0x01"}]}`,
+			err:  "stateful precompile address",
+		},
+		{
+			name: "airdrop without addresses",
+			spec: `{"chainID": 1, "airdrop": {"amount": "1 ether"}}`,
+			err:  errNoAirdropAddresses.Error(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec, err := ParseSpec([]byte(test.spec))
+			require.NoError(t, err)
+			_, err = Build(spec)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
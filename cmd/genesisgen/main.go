@@ -0,0 +1,123 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/subnet-evm/cmd/genesisgen/builder"
+	"github.com/ava-labs/subnet-evm/internal/flags"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+)
+
+var (
+	specFlag = &cli.StringFlag{
+		Name:  "spec",
+		Usage: "Path to the YAML or JSON spec of the genesis (default = interactive)",
+	}
+	outFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "Output file for the genesis (default = STDOUT)",
+	}
+	airdropOutFlag = &cli.StringFlag{
+		Name:  "airdrop-out",
+		Usage: "Output file for the airdrop data, to be set as the \"airdrop\" config of the nodes (required if the genesis has an airdrop)",
+	}
+	specOutFlag = &cli.StringFlag{
+		Name:  "spec-out",
+		Usage: "Output file for the YAML spec of the genesis, to build it again without the prompts (default = none)",
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "subnet-evm genesis generator tool")
+	app.Name = "genesisgen"
+	app.Flags = []cli.Flag{
+		specFlag,
+		outFlag,
+		airdropOutFlag,
+		specOutFlag,
+	}
+	app.Action = genesisgen
+}
+
+func genesisgen(c *cli.Context) error {
+	var (
+		spec *builder.Spec
+		err  error
+	)
+	if c.IsSet(specFlag.Name) {
+		spec, err = builder.LoadSpec(c.String(specFlag.Name))
+	} else {
+		spec, err = newPrompter(bufio.NewReader(os.Stdin), os.Stderr).promptSpec()
+	}
+	if err != nil {
+		utils.Fatalf("Failed to read the genesis spec: %v", err)
+	}
+
+	// Save the spec before building it, so that the answers to the prompts are not lost
+	// if the genesis is invalid
+	if c.IsSet(specOutFlag.Name) {
+		data, err := yaml.Marshal(spec)
+		if err != nil {
+			utils.Fatalf("Failed to encode the genesis spec: %v", err)
+		}
+		if err := os.WriteFile(c.String(specOutFlag.Name), data, 0o600); err != nil {
+			utils.Fatalf("Failed to write the genesis spec: %v", err)
+		}
+	}
+
+	genesis, err := builder.Build(spec)
+	if err != nil {
+		utils.Fatalf("Failed to build the genesis: %v", err)
+	}
+	genesisJSON, err := json.MarshalIndent(genesis, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode the genesis: %v", err)
+	}
+
+	if len(genesis.AirdropData) > 0 {
+		if !c.IsSet(airdropOutFlag.Name) {
+			utils.Fatalf("The genesis has an airdrop, its data must be written to a file (--airdrop-out)")
+		}
+		if err := os.WriteFile(c.String(airdropOutFlag.Name), genesis.AirdropData, 0o600); err != nil {
+			utils.Fatalf("Failed to write the airdrop data: %v", err)
+		}
+		log.Info("Set the airdrop config of the nodes to the airdrop data", "airdrop", c.String(airdropOutFlag.Name), "hash", genesis.AirdropHash)
+	}
+
+	// Either flush it out to a file or display on the standard output
+	if !c.IsSet(outFlag.Name) {
+		fmt.Printf("%s\n", genesisJSON)
+		return nil
+	}
+	if err := os.WriteFile(c.String(outFlag.Name), genesisJSON, 0o600); err != nil {
+		utils.Fatalf("Failed to write the genesis: %v", err)
+	}
+	log.Info("Wrote the genesis", "out", c.String(outFlag.Name), "chainID", genesis.Config.ChainID)
+	return nil
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
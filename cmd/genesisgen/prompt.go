@@ -0,0 +1,267 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/cmd/genesisgen/builder"
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// prompter builds a genesis spec from the answers to prompts.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in *bufio.Reader, out io.Writer) *prompter {
+	return &prompter{in: in, out: out}
+}
+
+// promptSpec returns the spec built from the answers to the prompts.
+func (p *prompter) promptSpec() (*builder.Spec, error) {
+	spec := new(builder.Spec)
+	err := p.ask("Chain ID", "", func(answer string) error {
+		chainID, ok := new(big.Int).SetString(answer, 10)
+		if !ok || chainID.Sign() <= 0 {
+			return errors.New("must be a positive integer")
+		}
+		spec.ChainID = chainID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.FeeConfig, err = p.promptFeeConfig(); err != nil {
+		return nil, err
+	}
+	if spec.AllowFeeRecipients, err = p.confirm("Allow block producers to set the fee recipient", false); err != nil {
+		return nil, err
+	}
+	if spec.Alloc, err = p.promptAlloc(); err != nil {
+		return nil, err
+	}
+	if spec.Precompiles, err = p.promptPrecompiles(); err != nil {
+		return nil, err
+	}
+	if spec.Airdrop, err = p.promptAirdrop(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// promptFeeConfig returns the fee config of the genesis, or nil to use the default fee config.
+func (p *prompter) promptFeeConfig() (*commontype.FeeConfig, error) {
+	useDefault, err := p.confirm("Use the default fee config", true)
+	if err != nil || useDefault {
+		return nil, err
+	}
+
+	feeConfig := params.DefaultFeeConfig
+	fields := []struct {
+		name  string
+		value **big.Int
+	}{
+		{"Gas limit", &feeConfig.GasLimit},
+		{"Min base fee (wei)", &feeConfig.MinBaseFee},
+		{"Target gas", &feeConfig.TargetGas},
+		{"Base fee change denominator", &feeConfig.BaseFeeChangeDenominator},
+		{"Min block gas cost", &feeConfig.MinBlockGasCost},
+		{"Max block gas cost", &feeConfig.MaxBlockGasCost},
+		{"Block gas cost step", &feeConfig.BlockGasCostStep},
+	}
+	for _, field := range fields {
+		field := field
+		err := p.ask(field.name, (*field.value).String(), func(answer string) error {
+			value, err := builder.ParseAmount(answer)
+			if err != nil {
+				return err
+			}
+			*field.value = value
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = p.ask("Target block rate (seconds)", strconv.FormatUint(feeConfig.TargetBlockRate, 10), func(answer string) error {
+		feeConfig.TargetBlockRate, err = strconv.ParseUint(answer, 10, 64)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := feeConfig.Verify(); err != nil {
+		fmt.Fprintf(p.out, "Invalid fee config: %v\n", err)
+		return p.promptFeeConfig()
+	}
+	return &feeConfig, nil
+}
+
+// promptAlloc returns the accounts funded by the genesis.
+func (p *prompter) promptAlloc() ([]builder.Allocation, error) {
+	var alloc []builder.Allocation
+	for {
+		var address *common.Address
+		err := p.ask("Address to fund (empty to finish)", "", func(answer string) error {
+			if answer == "" {
+				return nil
+			}
+			parsed, err := parseAddress(answer)
+			address = &parsed
+			return err
+		})
+		if err != nil || address == nil {
+			return alloc, err
+		}
+		allocation := builder.Allocation{Address: *address}
+		err = p.ask(fmt.Sprintf("Balance of %s (e.g. \"1000 ether\")", address), "", func(answer string) error {
+			balance, err := builder.ParseAmount(answer)
+			allocation.Balance = (*builder.Amount)(balance)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		alloc = append(alloc, allocation)
+	}
+}
+
+// promptPrecompiles returns the configs of the precompiles enabled by the genesis, with the
+// roles of their allow lists.
+func (p *prompter) promptPrecompiles() (map[string]json.RawMessage, error) {
+	precompiles := make(map[string]json.RawMessage)
+	for _, module := range precompile.RegisteredModules() {
+		enabled, err := p.confirm(fmt.Sprintf("Enable %s at genesis", module.ConfigKey), false)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			continue
+		}
+		config := make(map[string][]common.Address)
+		if _, ok := module.Describe().Properties["adminAddresses"]; ok {
+			for _, role := range []struct{ name, field string }{
+				{"Admin", "adminAddresses"},
+				{"Enabled", "enabledAddresses"},
+			} {
+				err := p.ask(fmt.Sprintf("%s addresses of %s (comma separated)", role.name, module.ConfigKey), "", func(answer string) error {
+					addresses, err := parseAddresses(answer)
+					if len(addresses) > 0 {
+						config[role.field] = addresses
+					}
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if precompiles[module.ConfigKey], err = json.Marshal(config); err != nil {
+			return nil, err
+		}
+	}
+	return precompiles, nil
+}
+
+// promptAirdrop returns the airdrop of the genesis, or nil if it has none.
+func (p *prompter) promptAirdrop() (*builder.Airdrop, error) {
+	airdrop := new(builder.Airdrop)
+	err := p.ask("Airdrop addresses (comma separated, empty for none)", "", func(answer string) error {
+		addresses, err := parseAddresses(answer)
+		airdrop.Addresses = addresses
+		return err
+	})
+	if err != nil || len(airdrop.Addresses) == 0 {
+		return nil, err
+	}
+	err = p.ask("Airdrop amount of each address (e.g. \"10 ether\")", "", func(answer string) error {
+		amount, err := builder.ParseAmount(answer)
+		airdrop.Amount = (*builder.Amount)(amount)
+		return err
+	})
+	return airdrop, err
+}
+
+// ask prompts [question] until [parse] accepts the answer, which is [defaultValue] if the
+// answer is empty.
+func (p *prompter) ask(question, defaultValue string, parse func(answer string) error) error {
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, err := p.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return err
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = defaultValue
+		}
+		if err := parse(answer); err != nil {
+			fmt.Fprintf(p.out, "Invalid answer: %v\n", err)
+			continue
+		}
+		return nil
+	}
+}
+
+// confirm prompts the yes or no [question], answered with [defaultValue] if the answer is empty.
+func (p *prompter) confirm(question string, defaultValue bool) (bool, error) {
+	choices := "y/N"
+	if defaultValue {
+		choices = "Y/n"
+	}
+	var confirmed bool
+	err := p.ask(fmt.Sprintf("%s? [%s]", question, choices), "", func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "":
+			confirmed = defaultValue
+		case "y", "yes":
+			confirmed = true
+		case "n", "no":
+			confirmed = false
+		default:
+			return errors.New("answer y or n")
+		}
+		return nil
+	})
+	return confirmed, err
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func parseAddresses(s string) ([]common.Address, error) {
+	var addresses []common.Address
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		address, err := parseAddress(field)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
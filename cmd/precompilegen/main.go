@@ -66,6 +66,18 @@ var (
 		Name:  "out",
 		Usage: "Output file for the generated precompile (default = STDOUT)",
 	}
+	testOutFlag = &cli.StringFlag{
+		Name:  "test-out",
+		Usage: "Output file for the generated precompile unit tests, e.g. core/<type>_test.go (default = none)",
+	}
+	gasFlag = &cli.StringFlag{
+		Name:  "gas",
+		Usage: "Path to the JSON gas costs of the precompile functions, or to the solc userdoc/devdoc with @custom:gas tags (default = none)",
+	}
+	contractsFlag = &cli.StringFlag{
+		Name:  "contracts",
+		Usage: "Directory to generate the Solidity interface and example contract into, e.g. contract-examples/contracts (default = none)",
+	}
 )
 
 func init() {
@@ -76,6 +88,9 @@ func init() {
 		outFlag,
 		pkgFlag,
 		typeFlag,
+		testOutFlag,
+		gasFlag,
+		contractsFlag,
 	}
 	app.Action = precompilegen
 }
@@ -88,7 +103,6 @@ func precompilegen(c *cli.Context) error {
 	if pkg == "" {
 		pkg = "precompile"
 	}
-	lang := bind.LangGo
 	// If the entire solidity code was specified, build and bind based on that
 	var (
 		abis    []string
@@ -125,12 +139,53 @@ func precompilegen(c *cli.Context) error {
 	}
 	types = append(types, kind)
 
+	// Load up the gas costs if specified
+	var gasCosts bind.PrecompileGasCosts
+	if c.IsSet(gasFlag.Name) {
+		gasJSON, err := os.ReadFile(c.String(gasFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to read gas costs: %v", err)
+		}
+		gasCosts, err = bind.ParsePrecompileGasCosts(gasJSON)
+		if err != nil {
+			utils.Fatalf("Failed to parse gas costs: %v", err)
+		}
+	} else {
+		log.Warn("No gas costs specified (--gas), gas costs of the generated precompile must be set manually")
+	}
+
 	// Generate the contract precompile
-	code, err := bind.Bind(types, abis, bins, sigs, pkg, lang, libs, aliases, true)
+	code, err := bind.BindPrecompile(types, abis, bins, sigs, pkg, libs, aliases, gasCosts)
 	if err != nil {
 		utils.Fatalf("Failed to generate ABI precompile: %v", err)
 	}
 
+	// Generate the unit tests of the precompile if requested
+	if c.IsSet(testOutFlag.Name) {
+		testCode, err := bind.BindPrecompileTest(types, abis, bins, sigs, pkg, libs, aliases, gasCosts)
+		if err != nil {
+			utils.Fatalf("Failed to generate precompile tests: %v", err)
+		}
+		if err := os.WriteFile(c.String(testOutFlag.Name), []byte(testCode), 0o600); err != nil {
+			utils.Fatalf("Failed to write precompile tests: %v", err)
+		}
+	}
+
+	// Generate the Solidity interface and example contract if requested
+	if c.IsSet(contractsFlag.Name) {
+		iface, example, err := bind.BindPrecompileSolidity(kind, string(abi))
+		if err != nil {
+			utils.Fatalf("Failed to generate Solidity contracts: %v", err)
+		}
+		dir := c.String(contractsFlag.Name)
+		if err := os.WriteFile(filepath.Join(dir, "I"+kind+".sol"), []byte(iface), 0o600); err != nil {
+			utils.Fatalf("Failed to write Solidity interface: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "Example"+kind+".sol"), []byte(example), 0o600); err != nil {
+			utils.Fatalf("Failed to write Solidity example contract: %v", err)
+		}
+	}
+
 	// Either flush it out to a file or display on the standard output
 	if !c.IsSet(outFlag.Name) {
 		fmt.Printf("%s\n", code)
@@ -0,0 +1,169 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// replay re-executes the blocks of a chain export with this binary, optionally against a modified
+// upgrade config, and reports where the receipts or the state roots diverge from the exported ones.
+// It checks that changes to the VM or proposed upgrades preserve the history of a chain.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/internal/flags"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+)
+
+var (
+	genesisFlag = &cli.StringFlag{
+		Name:     "genesis",
+		Usage:    "Path to the genesis of the chain",
+		Required: true,
+	}
+	chainFlag = &cli.StringFlag{
+		Name:     "chain",
+		Usage:    "Path to the chain export of the replayed blocks (see the exportChain admin API)",
+		Required: true,
+	}
+	stateFlag = &cli.StringFlag{
+		Name:  "state",
+		Usage: "Path to the state export of the parent of the first replayed block (default = replay from the genesis)",
+	}
+	upgradeFlag = &cli.StringFlag{
+		Name:  "upgrade",
+		Usage: "Path to an upgrade config replacing the upgrades of the exporting node (default = replay against the exported chain config)",
+	}
+	outFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "Output file for the report of the replay (default = STDOUT)",
+	}
+)
+
+// report describes the result of a replay. Only the blocks that diverge are listed.
+type report struct {
+	Replayed        int                       `json:"replayed"`
+	FirstDivergence *core.BlockReplayResult   `json:"firstDivergence"`
+	Divergent       []*core.BlockReplayResult `json:"divergent,omitempty"`
+}
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "subnet-evm historical replay tool")
+	app.Name = "replay"
+	app.Flags = []cli.Flag{
+		genesisFlag,
+		chainFlag,
+		stateFlag,
+		upgradeFlag,
+		outFlag,
+	}
+	app.Action = replay
+}
+
+func replay(c *cli.Context) error {
+	genesis := new(core.Genesis)
+	if err := readJSON(c.String(genesisFlag.Name), genesis); err != nil {
+		utils.Fatalf("Failed to read the genesis: %v", err)
+	}
+	var upgradeConfig *params.UpgradeConfig
+	if c.IsSet(upgradeFlag.Name) {
+		upgradeConfig = new(params.UpgradeConfig)
+		if err := readJSON(c.String(upgradeFlag.Name), upgradeConfig); err != nil {
+			utils.Fatalf("Failed to read the upgrade config: %v", err)
+		}
+	}
+
+	// Start from the genesis or from the imported state, which is checked against the root of its block
+	db := rawdb.NewMemoryDatabase()
+	genesisBlock, err := genesis.Commit(db)
+	if err != nil {
+		utils.Fatalf("Failed to commit the genesis: %v", err)
+	}
+	lastAccepted := genesisBlock.Hash()
+	if c.IsSet(stateFlag.Name) {
+		f, err := os.Open(c.String(stateFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to open the state export: %v", err)
+		}
+		block, _, err := core.ImportState(db, f, genesisBlock.Hash())
+		f.Close()
+		if err != nil {
+			utils.Fatalf("Failed to import the state export: %v", err)
+		}
+		lastAccepted = block.Hash()
+	}
+	chain, err := core.NewBlockChain(db, core.DefaultCacheConfig, genesis.Config, dummy.NewFaker(), vm.Config{}, lastAccepted)
+	if err != nil {
+		utils.Fatalf("Failed to open the chain: %v", err)
+	}
+	defer chain.Stop()
+
+	f, err := os.Open(c.String(chainFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to open the chain export: %v", err)
+	}
+	results, err := chain.ReplayChain(f, upgradeConfig)
+	f.Close()
+	if err != nil {
+		utils.Fatalf("Failed to replay the chain: %v", err)
+	}
+
+	result := &report{Replayed: len(results), FirstDivergence: core.FirstDivergence(results)}
+	for _, block := range results {
+		if len(block.Divergences) > 0 || block.Error != "" {
+			result.Divergent = append(result.Divergent, block)
+		}
+	}
+	reportJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode the report: %v", err)
+	}
+	// Either flush it out to a file or display on the standard output
+	if !c.IsSet(outFlag.Name) {
+		fmt.Printf("%s\n", reportJSON)
+	} else if err := os.WriteFile(c.String(outFlag.Name), reportJSON, 0o600); err != nil {
+		utils.Fatalf("Failed to write the report: %v", err)
+	}
+
+	if first := result.FirstDivergence; first != nil {
+		if first.FirstDivergentTx != nil {
+			utils.Fatalf("The replay diverged at block %d (%s), from transaction %s", first.Number, first.Hash, first.FirstDivergentTx)
+		}
+		utils.Fatalf("The replay diverged at block %d (%s)", first.Number, first.Hash)
+	}
+	log.Info("The replay matched the exported chain", "blocks", len(results))
+	return nil
+}
+
+// readJSON decodes the JSON file at [path] into [v].
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
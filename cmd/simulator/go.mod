@@ -3,6 +3,7 @@ module github.com/ava-labs/subnet-evm/cmd/simulator
 go 1.18
 
 require (
+	github.com/ava-labs/avalanchego v1.9.6-rc.0
 	github.com/ava-labs/subnet-evm v0.0.0-00010101000000-000000000000
 	github.com/ethereum/go-ethereum v1.10.26
 	github.com/spf13/cobra v1.5.0
@@ -15,7 +16,6 @@ replace github.com/ava-labs/subnet-evm => ../..
 
 require (
 	github.com/VictoriaMetrics/fastcache v1.10.0 // indirect
-	github.com/ava-labs/avalanchego v1.9.6-rc.0 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/btcsuite/btcd/btcec/v2 v2.2.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.1.3 // indirect
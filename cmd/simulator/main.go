@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -13,7 +14,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/cmd/simulator/key"
 	"github.com/ava-labs/subnet-evm/cmd/simulator/worker"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/plugin/dev"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
 )
@@ -40,6 +47,16 @@ var (
 	concurrency int
 	baseFee     uint64
 	priorityFee uint64
+
+	workloads            map[string]int
+	contractAddress      string
+	contractCallData     string
+	contractCallGas      uint64
+	governancePrecompile string
+	governanceInterval   time.Duration
+
+	inProcess   bool
+	genesisPath string
 )
 
 func newCommand() *cobra.Command {
@@ -57,6 +74,14 @@ func newCommand() *cobra.Command {
 	cmd.PersistentFlags().IntVarP(&concurrency, "concurrency", "c", 10, "Concurrency")
 	cmd.PersistentFlags().Uint64VarP(&baseFee, "base-fee", "f", 25, "Base fee")
 	cmd.PersistentFlags().Uint64VarP(&priorityFee, "priority-fee", "p", 1, "Base fee")
+	cmd.PersistentFlags().StringToIntVarP(&workloads, "workloads", "w", nil, "Relative weights of the transactions sent by the workers, e.g. transfer=8,contract-call=2,restricted=1 (default transfer=1)")
+	cmd.PersistentFlags().StringVar(&contractAddress, "contract-address", "", "Contract called by the contract-call workload (default = a counter contract deployed by the simulator)")
+	cmd.PersistentFlags().StringVar(&contractCallData, "contract-call-data", "", "Hex encoded input of the calls to --contract-address")
+	cmd.PersistentFlags().Uint64Var(&contractCallGas, "contract-call-gas", 0, "Gas limit of the contract-call workload (default 50000)")
+	cmd.PersistentFlags().StringVar(&governancePrecompile, "governance-precompile", "", "Config key of a precompile whose allow list roles are changed by the funding key, e.g. txAllowListConfig")
+	cmd.PersistentFlags().DurationVar(&governanceInterval, "governance-interval", 5*time.Second, "Interval between the allow list changes of --governance-precompile")
+	cmd.PersistentFlags().BoolVar(&inProcess, "in-process", false, "Apply the load to an in-process node instead of --endpoints")
+	cmd.PersistentFlags().StringVar(&genesisPath, "genesis", "", "Genesis JSON of the in-process node (default = a genesis funding the keys and making them admins of the precompiles used by the workloads)")
 
 	return cmd
 }
@@ -66,10 +91,31 @@ func runFunc(cmd *cobra.Command, args []string) {
 		rpcEndpoints, clusterInfoYamlPath, timeout, concurrency, baseFee, priorityFee)
 
 	cfg := &worker.Config{
-		Endpoints:   rpcEndpoints,
-		Concurrency: concurrency,
-		BaseFee:     baseFee,
-		PriorityFee: priorityFee,
+		Endpoints:            rpcEndpoints,
+		Concurrency:          concurrency,
+		BaseFee:              baseFee,
+		PriorityFee:          priorityFee,
+		Workloads:            workloads,
+		ContractCallGas:      contractCallGas,
+		GovernancePrecompile: governancePrecompile,
+		GovernanceInterval:   governanceInterval,
+	}
+	if contractAddress != "" {
+		if !common.IsHexAddress(contractAddress) {
+			log.Fatalf("invalid contract address %q", contractAddress)
+		}
+		address := common.HexToAddress(contractAddress)
+		cfg.ContractAddress = &address
+	}
+	if contractCallData != "" {
+		data, err := hexutil.Decode(contractCallData)
+		if err != nil {
+			log.Fatalf("invalid contract call data %v", err)
+		}
+		cfg.ContractCallData = data
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid config %v", err)
 	}
 
 	if clusterInfoYamlPath != "" {
@@ -101,6 +147,19 @@ func runFunc(cmd *cobra.Command, args []string) {
 		cfg.Endpoints = eps
 	}
 
+	if inProcess {
+		node, err := startLocalNode(cfg)
+		if err != nil {
+			log.Fatalf("failed to start in-process node %v", err)
+		}
+		defer func() {
+			if err := node.Stop(); err != nil {
+				log.Printf("failed to stop in-process node %v", err)
+			}
+		}()
+		cfg.Endpoints = []string{node.Endpoint()}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	errc := make(chan error)
 	go func() {
@@ -113,6 +172,8 @@ func runFunc(cmd *cobra.Command, args []string) {
 	case sig := <-sigs:
 		log.Printf("received OS signal %v; canceling context", sig.String())
 		cancel()
+		// Wait for the worker to report the results of the workloads
+		<-errc
 	case err := <-errc:
 		cancel()
 		if !errors.Is(err, context.DeadlineExceeded) {
@@ -121,6 +182,61 @@ func runFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+// startLocalNode starts an in-process node with the genesis at --genesis, or a genesis funding the keys
+// in --keys, which are generated if there are none.
+func startLocalNode(cfg *worker.Config) (*dev.Node, error) {
+	genesis := new(core.Genesis)
+	if genesisPath != "" {
+		b, err := os.ReadFile(genesisPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, genesis); err != nil {
+			return nil, fmt.Errorf("failed to parse genesis %v", err)
+		}
+		return startNode(genesis)
+	}
+
+	keys, err := key.LoadAll(context.Background(), keysDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		k, err := key.Generate()
+		if err != nil {
+			return nil, err
+		}
+		if err := k.Save(keysDir); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	funded := make([]common.Address, len(keys))
+	for i, k := range keys {
+		funded[i] = k.Address
+	}
+	var precompiles []string
+	if cfg.Workloads[worker.RestrictedWorkload] > 0 {
+		precompiles = append(precompiles, precompile.TxAllowListConfigKey)
+	}
+	if cfg.GovernancePrecompile != "" {
+		precompiles = append(precompiles, cfg.GovernancePrecompile)
+	}
+	if genesis, err = dev.FundedGenesis(funded, precompiles); err != nil {
+		return nil, err
+	}
+	return startNode(genesis)
+}
+
+func startNode(genesis *core.Genesis) (*dev.Node, error) {
+	node, err := dev.Start(genesis, dev.Config{})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("started local node at %s (chainID=%s)", node.Endpoint(), genesis.Config.ChainID)
+	return node, nil
+}
+
 type networkRunnerClusterInfo struct {
 	URIs     []string `json:"uris"`
 	Endpoint string   `json:"endpoint"`
@@ -20,8 +20,8 @@ const (
 )
 
 // Monitor periodically prints metrics related to transaction activity on
-// a given network.
-func Monitor(ctx context.Context, client ethclient.Client) error {
+// a given network, and records the accepted blocks in [report].
+func Monitor(ctx context.Context, client ethclient.Client, report *Report) error {
 	lastBlockNumber, err := client.BlockNumber(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get block number: %w", err)
@@ -60,6 +60,8 @@ func Monitor(ctx context.Context, client ethclient.Client) error {
 
 				log.Printf("[block created] t: %v index: %d base fee: %d block gas cost: %d block txs: %d gas used: %d\n", time.Unix(int64(t), 0), i, block.BaseFee().Div(block.BaseFee(), big.NewInt(params.GWei)), block.BlockGasCost(), txs, gas)
 
+				report.Block(block)
+
 				// Update Tx Count
 				timeTxs[t] += txs
 				totalTxs += txs
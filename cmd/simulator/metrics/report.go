@@ -0,0 +1,154 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+)
+
+// Report collects the results of the transactions sent by the simulator and the blocks accepted
+// while it runs, and summarizes them once it stops.
+type Report struct {
+	lock      sync.Mutex
+	start     time.Time
+	workloads map[string]*workloadResults
+
+	blocks  int
+	txs     int
+	gasUsed uint64
+	// firstBaseFee, lastBaseFee, minBaseFee and maxBaseFee describe how the base fee responds to the load.
+	firstBaseFee, lastBaseFee, minBaseFee, maxBaseFee *big.Int
+	maxBaseFeeTime                                    time.Duration
+}
+
+// workloadResults are the results of the transactions of a workload.
+type workloadResults struct {
+	confirmed int
+	rejected  int
+	// latencies are the durations between the issuance of the confirmed transactions and their acceptance.
+	latencies []time.Duration
+}
+
+// NewReport returns a report of a run starting now.
+func NewReport() *Report {
+	return &Report{
+		start:     time.Now(),
+		workloads: make(map[string]*workloadResults),
+	}
+}
+
+func (r *Report) results(workload string) *workloadResults {
+	results, ok := r.workloads[workload]
+	if !ok {
+		results = &workloadResults{}
+		r.workloads[workload] = results
+	}
+	return results
+}
+
+// Confirmed records a transaction of [workload] accepted [latency] after it was issued.
+// A zero latency is not measured.
+func (r *Report) Confirmed(workload string, latency time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	results := r.results(workload)
+	results.confirmed++
+	if latency > 0 {
+		results.latencies = append(results.latencies, latency)
+	}
+}
+
+// Rejected records a transaction of [workload] rejected when it was issued.
+func (r *Report) Rejected(workload string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.results(workload).rejected++
+}
+
+// Block records the transactions, gas and base fee of an accepted [block].
+func (r *Report) Block(block *types.Block) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.blocks++
+	r.txs += len(block.Transactions())
+	r.gasUsed += block.GasUsed()
+
+	baseFee := block.BaseFee()
+	if baseFee == nil {
+		return
+	}
+	if r.firstBaseFee == nil {
+		r.firstBaseFee, r.minBaseFee, r.maxBaseFee = baseFee, baseFee, baseFee
+	}
+	if baseFee.Cmp(r.minBaseFee) < 0 {
+		r.minBaseFee = baseFee
+	}
+	if baseFee.Cmp(r.maxBaseFee) > 0 {
+		r.maxBaseFee = baseFee
+		r.maxBaseFeeTime = time.Since(r.start)
+	}
+	r.lastBaseFee = baseFee
+}
+
+// Print logs the throughput and the latency percentiles of each workload, and the response of the
+// base fee to the load.
+func (r *Report) Print() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	elapsed := time.Since(r.start)
+	names := make([]string, 0, len(r.workloads))
+	for name := range r.workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	log.Printf("[report] elapsed: %v\n", elapsed.Round(time.Second))
+	for _, name := range names {
+		results := r.workloads[name]
+		latencies := results.latencies
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		log.Printf(
+			"[report] workload: %s confirmed: %d rejected: %d TPS: %.2f latency p50: %v p90: %v p99: %v max: %v\n",
+			name, results.confirmed, results.rejected, float64(results.confirmed)/elapsed.Seconds(),
+			percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99), percentile(latencies, 100),
+		)
+	}
+	log.Printf(
+		"[report] blocks: %d txs: %d TPS: %.2f GPS: %.1f\n",
+		r.blocks, r.txs, float64(r.txs)/elapsed.Seconds(), float64(r.gasUsed)/elapsed.Seconds(),
+	)
+	if r.firstBaseFee != nil {
+		log.Printf(
+			"[report] base fee (gwei) first: %s min: %s max: %s (after %v) last: %s\n",
+			gwei(r.firstBaseFee), gwei(r.minBaseFee), gwei(r.maxBaseFee), r.maxBaseFeeTime.Round(time.Second), gwei(r.lastBaseFee),
+		)
+	}
+}
+
+// percentile returns the [p]th percentile of the sorted [latencies], or 0 if there are none.
+func percentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := (len(latencies)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return latencies[i].Round(time.Millisecond)
+}
+
+func gwei(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.GWei)).Text('f', 2)
+}
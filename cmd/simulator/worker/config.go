@@ -4,10 +4,16 @@
 package worker
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"sigs.k8s.io/yaml"
 )
 
@@ -16,6 +22,52 @@ type Config struct {
 	Concurrency int      `json:"concurrency"`
 	BaseFee     uint64   `json:"base-fee"`
 	PriorityFee uint64   `json:"priority-fee"`
+
+	// Workloads are the relative weights of the kinds of transactions sent by the workers, keyed by
+	// [TransferWorkload], [ContractCallWorkload] or [RestrictedWorkload]. Defaults to transfers only.
+	Workloads map[string]int `json:"workloads,omitempty"`
+	// ContractAddress is the contract called by the contract-call workload with ContractCallData. If nil,
+	// a counter contract is deployed by the funding key and called instead.
+	ContractAddress  *common.Address `json:"contract-address,omitempty"`
+	ContractCallData hexutil.Bytes   `json:"contract-call-data,omitempty"`
+	ContractCallGas  uint64          `json:"contract-call-gas,omitempty"`
+	// GovernancePrecompile is the config key of a precompile with an allow list, whose roles are
+	// changed by the funding key every GovernanceInterval. The funding key must be an admin of it.
+	GovernancePrecompile string        `json:"governance-precompile,omitempty"`
+	GovernanceInterval   time.Duration `json:"governance-interval,omitempty"`
+}
+
+// Validate returns an error if the workloads of the config are invalid.
+func (c *Config) Validate() error {
+	total := 0
+	for name, weight := range c.Workloads {
+		switch name {
+		case TransferWorkload, ContractCallWorkload, RestrictedWorkload:
+		default:
+			return fmt.Errorf("unknown workload %q", name)
+		}
+		if weight < 0 {
+			return fmt.Errorf("workload %q has negative weight %d", name, weight)
+		}
+		total += weight
+	}
+	if len(c.Workloads) > 0 && total == 0 {
+		return errors.New("workloads have no weight")
+	}
+	if c.GovernancePrecompile == "" {
+		return nil
+	}
+	module, ok := precompile.GetRegisteredModule(c.GovernancePrecompile)
+	if !ok {
+		return fmt.Errorf("unknown governance precompile %q", c.GovernancePrecompile)
+	}
+	if _, ok := module.GasCosts["setEnabled"]; !ok {
+		return fmt.Errorf("governance precompile %q has no allow list", c.GovernancePrecompile)
+	}
+	if c.GovernanceInterval <= 0 {
+		return errors.New("governance interval must be positive")
+	}
+	return nil
 }
 
 // LoadConfig parses and validates the [config] in [.simulator]
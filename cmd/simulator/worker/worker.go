@@ -9,6 +9,7 @@ import (
 	"log"
 	"math/big"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/cmd/simulator/key"
@@ -16,6 +17,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethclient"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/sync/errgroup"
 )
@@ -25,6 +27,7 @@ var (
 	transferAmount   = big.NewInt(1)
 	workDelay        = time.Duration(100 * time.Millisecond)
 	retryDelay       = time.Duration(500 * time.Millisecond)
+	confirmDelay     = time.Duration(50 * time.Millisecond)
 
 	chainID     *big.Int
 	signer      types.Signer
@@ -41,9 +44,12 @@ func setupVars(cID *big.Int, bFee uint64, pFee uint64) {
 	signer = types.LatestSignerForChainID(chainID)
 	priorityFee = new(big.Int).SetUint64(pFee * params.GWei)
 	feeCap = new(big.Int).Add(new(big.Int).SetUint64(bFee*params.GWei), priorityFee)
+	setupCosts(transferGasLimit)
+}
 
-	maxTransferCost = new(big.Int).Mul(new(big.Int).SetUint64(transferGasLimit), feeCap)
-	maxTransferCost = new(big.Int).Add(maxTransferCost, transferAmount)
+// setupCosts sets the funding amounts for workers sending transactions of at most [gasLimit].
+func setupCosts(gasLimit uint64) {
+	maxTransferCost = maxTxCost(gasLimit)
 
 	requestAmount = new(big.Int).Mul(maxTransferCost, big.NewInt(100))
 	minFunderBalance = new(big.Int).Add(maxTransferCost, requestAmount)
@@ -109,6 +115,11 @@ type worker struct {
 
 	balance *big.Int
 	nonce   uint64
+
+	// restricted is the funded key without a role on the tx allow list that sends the transactions of
+	// the restricted workload, which are rejected before its nonce is used.
+	restricted *key.Key
+	report     *metrics.Report
 }
 
 func newWorker(k *key.Key, endpoint string, keysDir string) (*worker, error) {
@@ -184,17 +195,20 @@ func (w *worker) waitForBalance(ctx context.Context, stdout bool, minBalance *bi
 	return ctx.Err()
 }
 
-func (w *worker) sendTx(ctx context.Context, recipient common.Address, value *big.Int) error {
+// sendTx sends a transaction of [value] and [data] to [to], or creating a contract if [to] is nil,
+// retrying until it is accepted. Returns the time between the issuance of the transaction and its
+// acceptance.
+func (w *worker) sendTx(ctx context.Context, to *common.Address, value *big.Int, data []byte, gas uint64) (time.Duration, error) {
 	for ctx.Err() == nil {
 		tx := types.NewTx(&types.DynamicFeeTx{
 			ChainID:   chainID,
 			Nonce:     w.nonce,
-			To:        &recipient,
-			Gas:       transferGasLimit,
+			To:        to,
+			Gas:       gas,
 			GasFeeCap: feeCap,
 			GasTipCap: priorityFee,
 			Value:     value,
-			Data:      []byte{},
+			Data:      data,
 		})
 		signedTx, err := types.SignTx(tx, signer, w.k.PrivKey)
 		if err != nil {
@@ -202,6 +216,7 @@ func (w *worker) sendTx(ctx context.Context, recipient common.Address, value *bi
 			time.Sleep(retryDelay)
 			continue
 		}
+		issued := time.Now()
 		if err := w.c.SendTransaction(ctx, signedTx); err != nil {
 			log.Printf("failed to send transaction: %s", err.Error())
 			time.Sleep(retryDelay)
@@ -216,17 +231,49 @@ func (w *worker) sendTx(ctx context.Context, recipient common.Address, value *bi
 		}
 		w.nonce++
 		w.balance = new(big.Int).Sub(w.balance, cost)
-		w.balance = new(big.Int).Sub(w.balance, transferAmount)
+		return time.Since(issued), nil
+	}
+	return 0, ctx.Err()
+}
+
+// sendRestrictedTx sends a transfer to [recipient] from the restricted key of [w], and records that
+// it was rejected. Returns an error if it was accepted, since the tx allow list is then not enforced.
+func (w *worker) sendRestrictedTx(ctx context.Context, recipient common.Address) error {
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     0,
+		To:        &recipient,
+		Gas:       transferGasLimit,
+		GasFeeCap: feeCap,
+		GasTipCap: priorityFee,
+		Value:     transferAmount,
+	})
+	signedTx, err := types.SignTx(tx, signer, w.restricted.PrivKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if err := w.c.SendTransaction(ctx, signedTx); err != nil {
+		if !strings.Contains(err.Error(), precompile.ErrSenderAddressNotAllowListed.Error()) {
+			log.Printf("failed to send restricted transaction: %s", err.Error())
+			time.Sleep(retryDelay)
+			return nil
+		}
+		w.report.Rejected(RestrictedWorkload)
 		return nil
 	}
-	return ctx.Err()
+	w.report.Confirmed(RestrictedWorkload, 0)
+	return fmt.Errorf("transaction %s from restricted sender %s was not rejected", signedTx.Hash().Hex(), w.restricted.Address.Hex())
 }
 
-func (w *worker) work(ctx context.Context, availableWorkers []*worker, fundRequest chan common.Address) error {
+func (w *worker) work(ctx context.Context, availableWorkers []*worker, workloads *workloadMix, call *contractCall, fundRequest chan common.Address) error {
 	for ctx.Err() == nil {
 		if w.balance.Cmp(maxTransferCost) < 0 {
 			log.Printf("%s requesting funds from master\n", w.k.Address.Hex())
-			fundRequest <- w.k.Address
+			select {
+			case fundRequest <- w.k.Address:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			if err := w.waitForBalance(ctx, false, maxTransferCost); err != nil {
 				return fmt.Errorf("could not get balance: %w", err)
 			}
@@ -235,15 +282,39 @@ func (w *worker) work(ctx context.Context, availableWorkers []*worker, fundReque
 		if recipient.k.Address == w.k.Address {
 			continue
 		}
-		if err := w.sendTx(ctx, recipient.k.Address, transferAmount); err != nil {
+		var (
+			workload = workloads.pick()
+			latency  time.Duration
+			err      error
+		)
+		switch workload {
+		case TransferWorkload:
+			latency, err = w.sendTx(ctx, &recipient.k.Address, transferAmount, nil, transferGasLimit)
+		case ContractCallWorkload:
+			latency, err = w.sendTx(ctx, &call.to, common.Big0, call.data, call.gas)
+		case RestrictedWorkload:
+			err = w.sendRestrictedTx(ctx, recipient.k.Address)
+		}
+		if err != nil {
 			return err
 		}
+		if workload != RestrictedWorkload {
+			w.report.Confirmed(workload, latency)
+		}
 		time.Sleep(workDelay)
 	}
 	return ctx.Err()
 }
 
-func (w *worker) fund(ctx context.Context, fundRequest chan common.Address) error {
+// fund sends funds to the workers requesting them, and sends the allow list changes of [g] every
+// [governanceInterval] if [g] is not nil.
+func (w *worker) fund(ctx context.Context, fundRequest chan common.Address, g *governance, governanceInterval time.Duration) error {
+	var governanceTick <-chan time.Time
+	if g != nil {
+		ticker := time.NewTicker(governanceInterval)
+		defer ticker.Stop()
+		governanceTick = ticker.C
+	}
 	for {
 		select {
 		case recipient := <-fundRequest:
@@ -252,9 +323,13 @@ func (w *worker) fund(ctx context.Context, fundRequest chan common.Address) erro
 					return fmt.Errorf("could not get minimum balance: %w", err)
 				}
 			}
-			if err := w.sendTx(ctx, recipient, requestAmount); err != nil {
+			if _, err := w.sendTx(ctx, &recipient, requestAmount, nil, transferGasLimit); err != nil {
 				return fmt.Errorf("unable to send tx: %w", err)
 			}
+		case <-governanceTick:
+			if err := w.churn(ctx, g); err != nil {
+				return err
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -265,7 +340,7 @@ func (w *worker) confirmTransaction(ctx context.Context, tx common.Hash) (*big.I
 	for ctx.Err() == nil {
 		result, pending, _ := w.c.TransactionByHash(ctx, tx)
 		if result == nil || pending {
-			time.Sleep(retryDelay)
+			time.Sleep(confirmDelay)
 			continue
 		}
 		return result.Cost(), nil
@@ -274,8 +349,12 @@ func (w *worker) confirmTransaction(ctx context.Context, tx common.Hash) (*big.I
 }
 
 // Run attempts to apply load to a network specified in .simulator/config.yml
-// and periodically prints metrics about the traffic it generates.
+// and periodically prints metrics about the traffic it generates. The results
+// of the workloads are summarized when the context is done.
 func Run(ctx context.Context, cfg *Config, keysDir string) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
 	rclient, err := ethclient.Dial(cfg.Endpoints[0])
 	if err != nil {
 		return err
@@ -291,19 +370,38 @@ func Run(ctx context.Context, cfg *Config, keysDir string) error {
 		return fmt.Errorf("unable to load available workers: %w", err)
 	}
 
-	g, gctx := errgroup.WithContext(ctx)
-	g.Go(func() error {
-		return metrics.Monitor(gctx, rclient)
+	workloads := newWorkloadMix(cfg.Workloads)
+	call, err := master.prepare(ctx, cfg, workloads, workers)
+	if err != nil {
+		return fmt.Errorf("unable to prepare workloads: %w", err)
+	}
+	setupCosts(maxGasLimit(call))
+
+	report := metrics.NewReport()
+	master.report = report
+	for _, worker := range workers {
+		worker.report = report
+	}
+	var g *governance
+	if cfg.GovernancePrecompile != "" {
+		g = newGovernance(cfg.GovernancePrecompile)
+	}
+
+	group, gctx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		return metrics.Monitor(gctx, rclient, report)
 	})
 	fundRequest := make(chan common.Address)
-	g.Go(func() error {
-		return master.fund(gctx, fundRequest)
+	group.Go(func() error {
+		return master.fund(gctx, fundRequest, g, cfg.GovernanceInterval)
 	})
 	for _, worker := range workers {
 		w := worker
-		g.Go(func() error {
-			return w.work(gctx, workers, fundRequest)
+		group.Go(func() error {
+			return w.work(gctx, workers, workloads, call, fundRequest)
 		})
 	}
-	return g.Wait()
+	err = group.Wait()
+	report.Print()
+	return err
 }
@@ -0,0 +1,231 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/ava-labs/subnet-evm/cmd/simulator/key"
+	"github.com/ava-labs/subnet-evm/interfaces"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// TransferWorkload sends transfers between the workers.
+	TransferWorkload = "transfer"
+	// ContractCallWorkload calls a contract, by default a counter writing a storage slot on each call.
+	ContractCallWorkload = "contract-call"
+	// RestrictedWorkload sends transfers from keys without a role on the tx allow list, which must
+	// be rejected. The funding key must be an admin of the tx allow list, to enable the workers.
+	RestrictedWorkload = "restricted"
+	// GovernanceWorkload changes the roles of the allow list of a precompile. It is sent by the
+	// funding key at a fixed interval rather than picked by the workers.
+	GovernanceWorkload = "governance"
+)
+
+var (
+	// counterInitCode deploys a contract that increments the value of its storage slot 0 on every call:
+	// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+	counterInitCode = hexutil.MustDecode("0x600a600c600039600a6000f360005460010160005500")
+
+	deployGasLimit          = uint64(100_000)
+	defaultContractCallGas  = uint64(50_000)
+	governanceGasLimit      = uint64(100_000)
+	defaultWorkloadsWeights = map[string]int{TransferWorkload: 1}
+)
+
+// workloadMix picks the kind of each transaction sent by the workers according to their weights.
+type workloadMix struct {
+	weights    map[string]int
+	names      []string
+	cumulative []int
+}
+
+func newWorkloadMix(weights map[string]int) *workloadMix {
+	if len(weights) == 0 {
+		weights = defaultWorkloadsWeights
+	}
+	w := &workloadMix{weights: weights}
+	for name := range weights {
+		w.names = append(w.names, name)
+	}
+	// Sort the names so that the same seed picks the same workloads.
+	sort.Strings(w.names)
+	total := 0
+	for _, name := range w.names {
+		total += weights[name]
+		w.cumulative = append(w.cumulative, total)
+	}
+	return w
+}
+
+// pick returns the kind of the next transaction.
+func (w *workloadMix) pick() string {
+	n := rand.Intn(w.cumulative[len(w.cumulative)-1])
+	i := sort.SearchInts(w.cumulative, n+1)
+	return w.names[i]
+}
+
+// has returns true if [name] may be picked.
+func (w *workloadMix) has(name string) bool {
+	return w.weights[name] > 0
+}
+
+// contractCall is the call sent by the contract-call workload.
+type contractCall struct {
+	to   common.Address
+	data []byte
+	gas  uint64
+}
+
+// prepare sets up the chain for the [workloads]: it deploys the contract called by the contract-call
+// workload unless [cfg] sets one, and enables the [workers] on the tx allow list and funds their
+// restricted keys for the restricted workload.
+func (w *worker) prepare(ctx context.Context, cfg *Config, workloads *workloadMix, workers []*worker) (*contractCall, error) {
+	if workloads.has(ContractCallWorkload) && cfg.ContractAddress == nil || workloads.has(RestrictedWorkload) {
+		if err := w.waitForBalance(ctx, true, maxTxCost(deployGasLimit)); err != nil {
+			return nil, fmt.Errorf("could not get balance: %w", err)
+		}
+	}
+
+	var call *contractCall
+	if workloads.has(ContractCallWorkload) {
+		call = &contractCall{data: cfg.ContractCallData, gas: cfg.ContractCallGas}
+		if call.gas == 0 {
+			call.gas = defaultContractCallGas
+		}
+		if cfg.ContractAddress != nil {
+			call.to = *cfg.ContractAddress
+		} else {
+			address, err := w.deployCounter(ctx)
+			if err != nil {
+				return nil, err
+			}
+			call.to = address
+		}
+	}
+
+	if workloads.has(RestrictedWorkload) {
+		role, err := w.allowListRole(ctx, precompile.TxAllowListAddress, w.k.Address)
+		if err != nil {
+			return nil, err
+		}
+		if !role.IsAdmin() {
+			return nil, fmt.Errorf("%s workload requires the funding key %s to be an admin of the tx allow list", RestrictedWorkload, w.k.Address)
+		}
+		for _, worker := range workers {
+			role, err := w.allowListRole(ctx, precompile.TxAllowListAddress, worker.k.Address)
+			if err != nil {
+				return nil, err
+			}
+			if role.IsEnabled() {
+				continue
+			}
+			log.Printf("enabling worker %s on the tx allow list\n", worker.k.Address.Hex())
+			data, err := precompile.PackModifyAllowList(worker.k.Address, precompile.AllowListEnabled)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.sendTx(ctx, &precompile.TxAllowListAddress, common.Big0, data, governanceGasLimit); err != nil {
+				return nil, fmt.Errorf("could not enable worker %s: %w", worker.k.Address, err)
+			}
+		}
+		// The tx pool checks the balance of the sender before the allow list, so the restricted keys
+		// must be able to pay for their transactions to be rejected for their role.
+		for _, worker := range workers {
+			restricted, err := key.Generate()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.sendTx(ctx, &restricted.Address, maxTxCost(transferGasLimit), nil, transferGasLimit); err != nil {
+				return nil, fmt.Errorf("could not fund restricted key %s: %w", restricted.Address, err)
+			}
+			worker.restricted = restricted
+		}
+	}
+	return call, nil
+}
+
+// deployCounter deploys the counter contract and returns its address.
+func (w *worker) deployCounter(ctx context.Context) (common.Address, error) {
+	nonce := w.nonce
+	if _, err := w.sendTx(ctx, nil, common.Big0, counterInitCode, deployGasLimit); err != nil {
+		return common.Address{}, fmt.Errorf("could not deploy counter contract: %w", err)
+	}
+	address := crypto.CreateAddress(w.k.Address, nonce)
+	log.Printf("deployed counter contract at %s\n", address.Hex())
+	return address, nil
+}
+
+// allowListRole returns the role of [address] on the allow list of the precompile at [precompileAddr].
+func (w *worker) allowListRole(ctx context.Context, precompileAddr common.Address, address common.Address) (precompile.AllowListRole, error) {
+	result, err := w.c.CallContract(ctx, interfaces.CallMsg{
+		To:   &precompileAddr,
+		Data: precompile.PackReadAllowList(address),
+	}, nil)
+	if err != nil {
+		return precompile.AllowListNoRole, fmt.Errorf("could not read allow list of %s: %w", precompileAddr, err)
+	}
+	return precompile.AllowListRole(common.BytesToHash(result)), nil
+}
+
+// governance changes the roles of the allow list of a precompile, alternately enabling a new address
+// and removing its role.
+type governance struct {
+	precompileAddr common.Address
+	account        common.Address
+	enabled        bool
+}
+
+func newGovernance(configKey string) *governance {
+	module, _ := precompile.GetRegisteredModule(configKey)
+	return &governance{precompileAddr: module.Address}
+}
+
+// next returns the input of the next allow list change.
+func (g *governance) next() ([]byte, error) {
+	role := precompile.AllowListNoRole
+	if !g.enabled {
+		rand.Read(g.account[:])
+		role = precompile.AllowListEnabled
+	}
+	g.enabled = !g.enabled
+	return precompile.PackModifyAllowList(g.account, role)
+}
+
+// churn sends the next allow list change of [g] and records it in the report.
+func (w *worker) churn(ctx context.Context, g *governance) error {
+	data, err := g.next()
+	if err != nil {
+		return err
+	}
+	latency, err := w.sendTx(ctx, &g.precompileAddr, common.Big0, data, governanceGasLimit)
+	if err != nil {
+		return fmt.Errorf("unable to send governance tx: %w", err)
+	}
+	w.report.Confirmed(GovernanceWorkload, latency)
+	return nil
+}
+
+// maxGasLimit returns the largest gas limit of the transactions sent by the workers.
+func maxGasLimit(call *contractCall) uint64 {
+	if call != nil && call.gas > transferGasLimit {
+		return call.gas
+	}
+	return transferGasLimit
+}
+
+// maxTxCost returns the largest cost of the transactions sent by the workers with [gasLimit].
+func maxTxCost(gasLimit uint64) *big.Int {
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), feeCap)
+	return cost.Add(cost, transferAmount)
+}
@@ -0,0 +1,175 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// statepatch applies a state patch to a state export offline, so that the operators of a
+// subnet can review the changes it makes and agree on its hash before scheduling it in the
+// "statePatches" of the upgrade config of their nodes.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/internal/flags"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+)
+
+var (
+	genesisFlag = &cli.StringFlag{
+		Name:     "genesis",
+		Usage:    "Path to the genesis of the chain",
+		Required: true,
+	}
+	stateFlag = &cli.StringFlag{
+		Name:     "state",
+		Usage:    "Path to the state export of the parent of the patched block (see the exportState admin API)",
+		Required: true,
+	}
+	patchFlag = &cli.StringFlag{
+		Name:     "patch",
+		Usage:    "Path to the JSON state patch",
+		Required: true,
+	}
+	outFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "Output file for the report of the patch (default = STDOUT)",
+	}
+	upgradeOutFlag = &cli.StringFlag{
+		Name:  "upgrade-out",
+		Usage: "Output file for the upgrade config scheduling the patch, guarded by the root of the exported state (default = none)",
+	}
+)
+
+// report describes the result of a state patch, for the operators to compare before scheduling it.
+type report struct {
+	PatchHash   common.Hash `json:"patchHash"`
+	BlockNumber uint64      `json:"blockNumber"`
+	ParentRoot  common.Hash `json:"parentRoot"`
+	// PatchedRoot is the root of the parent state with the patch applied, before the transactions of
+	// the block.
+	PatchedRoot common.Hash               `json:"patchedRoot"`
+	Changes     []params.StatePatchChange `json:"changes"`
+}
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "subnet-evm state patch tool")
+	app.Name = "statepatch"
+	app.Flags = []cli.Flag{
+		genesisFlag,
+		stateFlag,
+		patchFlag,
+		outFlag,
+		upgradeOutFlag,
+	}
+	app.Action = statepatch
+}
+
+func statepatch(c *cli.Context) error {
+	genesis := new(core.Genesis)
+	if err := readJSON(c.String(genesisFlag.Name), genesis); err != nil {
+		utils.Fatalf("Failed to read the genesis: %v", err)
+	}
+	patch := new(params.StatePatch)
+	if err := readJSON(c.String(patchFlag.Name), patch); err != nil {
+		utils.Fatalf("Failed to read the state patch: %v", err)
+	}
+	if err := patch.Verify(); err != nil {
+		utils.Fatalf("Invalid state patch: %v", err)
+	}
+
+	// Import the exported state, which checks it against the root of its block
+	db := rawdb.NewMemoryDatabase()
+	f, err := os.Open(c.String(stateFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to open the state export: %v", err)
+	}
+	parent, _, err := core.ImportState(db, f, genesis.ToBlock(nil).Hash())
+	f.Close()
+	if err != nil {
+		utils.Fatalf("Failed to import the state export: %v", err)
+	}
+	if parent.NumberU64()+1 != patch.BlockNumber.Uint64() {
+		utils.Fatalf("The state patch applies at block %d, so it requires the state of block %d, but the state export is at block %d", patch.BlockNumber, new(big.Int).Sub(patch.BlockNumber, common.Big1), parent.NumberU64())
+	}
+	if patch.ParentRoot != nil && *patch.ParentRoot != parent.Root() {
+		utils.Fatalf("The state patch expects parent root %s, but the state export has root %s", patch.ParentRoot, parent.Root())
+	}
+
+	statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		utils.Fatalf("Failed to open the exported state: %v", err)
+	}
+	changes := patch.Apply(statedb)
+
+	// Schedule the patch guarded by the reviewed state, so that it cannot apply to another one
+	root := parent.Root()
+	patch.ParentRoot = &root
+	result := &report{
+		PatchHash:   patch.Hash(),
+		BlockNumber: patch.BlockNumber.Uint64(),
+		ParentRoot:  root,
+		PatchedRoot: statedb.IntermediateRoot(true),
+		Changes:     changes,
+	}
+	log.Info("Applied the state patch", "number", result.BlockNumber, "hash", result.PatchHash, "changes", len(changes), "root", result.PatchedRoot)
+
+	if c.IsSet(upgradeOutFlag.Name) {
+		upgrade, err := json.MarshalIndent(&params.UpgradeConfig{StatePatches: []params.StatePatch{*patch}}, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode the upgrade config: %v", err)
+		}
+		if err := os.WriteFile(c.String(upgradeOutFlag.Name), upgrade, 0o600); err != nil {
+			utils.Fatalf("Failed to write the upgrade config: %v", err)
+		}
+		log.Info("Wrote the upgrade config, to be merged into the upgrade config of the nodes", "out", c.String(upgradeOutFlag.Name))
+	}
+
+	reportJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode the report: %v", err)
+	}
+	// Either flush it out to a file or display on the standard output
+	if !c.IsSet(outFlag.Name) {
+		fmt.Printf("%s\n", reportJSON)
+		return nil
+	}
+	if err := os.WriteFile(c.String(outFlag.Name), reportJSON, 0o600); err != nil {
+		utils.Fatalf("Failed to write the report: %v", err)
+	}
+	return nil
+}
+
+// readJSON decodes the JSON file at [path] into [v].
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
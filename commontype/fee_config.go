@@ -4,7 +4,6 @@
 package commontype
 
 import (
-	"fmt"
 	"math/big"
 
 	"github.com/ava-labs/subnet-evm/utils"
@@ -63,25 +62,38 @@ var EmptyFeeConfig = FeeConfig{}
 func (f *FeeConfig) Verify() error {
 	switch {
 	case f.GasLimit.Cmp(common.Big0) != 1:
-		return fmt.Errorf("gasLimit = %d cannot be less than or equal to 0", f.GasLimit)
+		return utils.NewFieldError("gasLimit", "= %d cannot be less than or equal to 0", f.GasLimit)
 	case f.TargetBlockRate <= 0:
-		return fmt.Errorf("targetBlockRate = %d cannot be less than or equal to 0", f.TargetBlockRate)
+		return utils.NewFieldError("targetBlockRate", "= %d cannot be less than or equal to 0", f.TargetBlockRate)
 	case f.MinBaseFee.Cmp(common.Big0) == -1:
-		return fmt.Errorf("minBaseFee = %d cannot be less than 0", f.MinBaseFee)
+		return utils.NewFieldError("minBaseFee", "= %d cannot be less than 0", f.MinBaseFee)
 	case f.TargetGas.Cmp(common.Big0) != 1:
-		return fmt.Errorf("targetGas = %d cannot be less than or equal to 0", f.TargetGas)
+		return utils.NewFieldError("targetGas", "= %d cannot be less than or equal to 0", f.TargetGas)
 	case f.BaseFeeChangeDenominator.Cmp(common.Big0) != 1:
-		return fmt.Errorf("baseFeeChangeDenominator = %d cannot be less than or equal to 0", f.BaseFeeChangeDenominator)
+		return utils.NewFieldError("baseFeeChangeDenominator", "= %d cannot be less than or equal to 0", f.BaseFeeChangeDenominator)
 	case f.MinBlockGasCost.Cmp(common.Big0) == -1:
-		return fmt.Errorf("minBlockGasCost = %d cannot be less than 0", f.MinBlockGasCost)
+		return utils.NewFieldError("minBlockGasCost", "= %d cannot be less than 0", f.MinBlockGasCost)
 	case f.MinBlockGasCost.Cmp(f.MaxBlockGasCost) == 1:
-		return fmt.Errorf("minBlockGasCost = %d cannot be greater than maxBlockGasCost = %d", f.MinBlockGasCost, f.MaxBlockGasCost)
+		return utils.NewFieldError("minBlockGasCost", "= %d cannot be greater than maxBlockGasCost = %d", f.MinBlockGasCost, f.MaxBlockGasCost)
 	case f.BlockGasCostStep.Cmp(common.Big0) == -1:
-		return fmt.Errorf("blockGasCostStep = %d cannot be less than 0", f.BlockGasCostStep)
+		return utils.NewFieldError("blockGasCostStep", "= %d cannot be less than 0", f.BlockGasCostStep)
 	}
 	return f.checkByteLens()
 }
 
+// Describe returns the schema of the JSON encoding of the FeeConfig, with the bounds checked by Verify.
+func (f *FeeConfig) Describe() *utils.Schema {
+	schema := utils.Describe(f)
+	for _, positive := range []string{"gasLimit", "targetBlockRate", "targetGas", "baseFeeChangeDenominator"} {
+		schema.Properties[positive].Minimum = nil
+		schema.Properties[positive].ExclusiveMinimum = common.Big0
+	}
+	for _, nonNegative := range []string{"minBaseFee", "minBlockGasCost", "blockGasCostStep"} {
+		schema.Properties[nonNegative].Minimum = common.Big0
+	}
+	return schema
+}
+
 // Equal checks if given [other] is same with this FeeConfig.
 func (f *FeeConfig) Equal(other *FeeConfig) bool {
 	if other == nil {
@@ -101,28 +113,28 @@ func (f *FeeConfig) Equal(other *FeeConfig) bool {
 // checkByteLens checks byte lengths against common.HashLen (32 bytes) and returns error
 func (f *FeeConfig) checkByteLens() error {
 	if isBiggerThanHashLen(f.GasLimit) {
-		return fmt.Errorf("gasLimit exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("gasLimit", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(new(big.Int).SetUint64(f.TargetBlockRate)) {
-		return fmt.Errorf("targetBlockRate exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("targetBlockRate", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.MinBaseFee) {
-		return fmt.Errorf("minBaseFee exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("minBaseFee", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.TargetGas) {
-		return fmt.Errorf("targetGas exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("targetGas", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.BaseFeeChangeDenominator) {
-		return fmt.Errorf("baseFeeChangeDenominator exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("baseFeeChangeDenominator", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.MinBlockGasCost) {
-		return fmt.Errorf("minBlockGasCost exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("minBlockGasCost", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.MaxBlockGasCost) {
-		return fmt.Errorf("maxBlockGasCost exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("maxBlockGasCost", "exceeds %d bytes", common.HashLength)
 	}
 	if isBiggerThanHashLen(f.BlockGasCostStep) {
-		return fmt.Errorf("blockGasCostStep exceeds %d bytes", common.HashLength)
+		return utils.NewFieldError("blockGasCostStep", "exceeds %d bytes", common.HashLength)
 	}
 	return nil
 }
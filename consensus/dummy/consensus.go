@@ -158,8 +158,8 @@ func (self *DummyEngine) verifyHeaderGasFields(config *params.ChainConfig, heade
 	if err != nil {
 		return fmt.Errorf("failed to calculate base fee: %w", err)
 	}
-	if !bytes.Equal(expectedRollupWindowBytes, header.Extra) {
-		return fmt.Errorf("expected rollup window bytes: %x, found %x", expectedRollupWindowBytes, header.Extra)
+	if !bytes.Equal(expectedRollupWindowBytes, header.Extra[:params.ExtraDataSize]) {
+		return fmt.Errorf("expected rollup window bytes: %x, found %x", expectedRollupWindowBytes, header.Extra[:params.ExtraDataSize])
 	}
 	if header.BaseFee == nil {
 		return errors.New("expected baseFee to be non-nil")
@@ -208,10 +208,14 @@ func (self *DummyEngine) verifyHeader(chain consensus.ChainHeaderReader, header
 			return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
 		}
 	} else {
-		expectedExtraDataSize := params.ExtraDataSize
+		expectedExtraDataSize := config.GetExtraDataSize(header.Number, timestamp)
 		if len(header.Extra) != expectedExtraDataSize {
 			return fmt.Errorf("expected extra-data field to be: %d, but found %d", expectedExtraDataSize, len(header.Extra))
 		}
+		// Ensure that the P-chain height used to snapshot validator sets does not decrease
+		if err := verifyPChainHeight(config, header, parent); err != nil {
+			return err
+		}
 	}
 	// Ensure gas-related header fields are correct
 	if err := self.verifyHeaderGasFields(config, header, parent, chain); err != nil {
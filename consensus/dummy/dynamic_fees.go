@@ -22,11 +22,12 @@ import (
 func CalcBaseFee(config *params.ChainConfig, feeConfig commontype.FeeConfig, parent *types.Header, timestamp uint64) ([]byte, *big.Int, error) {
 	// If the current block is the first EIP-1559 block, or it is the genesis block
 	// return the initial slice and initial base fee.
-	isSubnetEVM := config.IsSubnetEVM(new(big.Int).SetUint64(parent.Time))
-	extraDataSize := params.ExtraDataSize
+	parentTimestamp := new(big.Int).SetUint64(parent.Time)
+	isSubnetEVM := config.IsSubnetEVM(parentTimestamp)
+	extraDataSize := config.GetExtraDataSize(parent.Number, parentTimestamp)
 
 	if !isSubnetEVM || parent.Number.Cmp(common.Big0) == 0 {
-		initialSlice := make([]byte, extraDataSize)
+		initialSlice := make([]byte, params.ExtraDataSize)
 		return initialSlice, feeConfig.MinBaseFee, nil
 	}
 	if len(parent.Extra) != extraDataSize {
@@ -40,7 +41,7 @@ func CalcBaseFee(config *params.ChainConfig, feeConfig commontype.FeeConfig, par
 
 	// roll the window over by the difference between the timestamps to generate
 	// the new rollup window.
-	newRollupWindow, err := rollLongWindow(parent.Extra, int(roll))
+	newRollupWindow, err := rollLongWindow(parent.Extra[:params.ExtraDataSize], int(roll))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -118,6 +119,22 @@ func EstimateNextBaseFee(config *params.ChainConfig, feeConfig commontype.FeeCon
 	return CalcBaseFee(config, feeConfig, parent, timestamp)
 }
 
+// CalcGasUtilization returns the gas consumed over the rollup window ending at [header],
+// including the gas used by [header], as a ratio of the target gas of [feeConfig]. This is
+// the utilization the base fee of a child of [header] is adjusted to: it increases above 1
+// and decreases below 1. The utilization is 0 prior to Subnet EVM, which has no target gas.
+func CalcGasUtilization(config *params.ChainConfig, feeConfig commontype.FeeConfig, header *types.Header) (float64, error) {
+	if !config.IsSubnetEVM(new(big.Int).SetUint64(header.Time)) {
+		return 0, nil
+	}
+	window, _, err := CalcBaseFee(config, feeConfig, header, header.Time)
+	if err != nil {
+		return 0, err
+	}
+	totalGas := sumLongWindow(window, int(params.RollupWindow))
+	return float64(totalGas) / float64(feeConfig.TargetGas.Uint64()), nil
+}
+
 // selectBigWithinBounds returns [value] if it is within the bounds:
 // lowerBound <= value <= upperBound or the bound at either end if [value]
 // is outside of the defined boundaries.
@@ -14,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var testMinBaseFee = big.NewInt(75_000_000_000)
@@ -440,3 +441,41 @@ func TestCalcBlockGasCost(t *testing.T) {
 		})
 	}
 }
+
+func TestCalcGasUtilization(t *testing.T) {
+	feeConfig := commontype.FeeConfig{
+		GasLimit:        big.NewInt(8_000_000),
+		TargetBlockRate: 2,
+
+		MinBaseFee:               big.NewInt(25_000_000_000),
+		TargetGas:                big.NewInt(15_000_000),
+		BaseFeeChangeDenominator: big.NewInt(36),
+
+		MinBlockGasCost:  big.NewInt(0),
+		MaxBlockGasCost:  big.NewInt(1_000_000),
+		BlockGasCostStep: big.NewInt(200_000),
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Time: 0}
+	utilization, err := CalcGasUtilization(params.TestChainConfig, feeConfig, genesis)
+	require.NoError(t, err)
+	require.Zero(t, utilization)
+
+	// The window of the child of [header] holds the gas used by [parent] and [header]
+	parent := &types.Header{Number: big.NewInt(1), Time: 2, GasUsed: 3_000_000, BaseFee: feeConfig.MinBaseFee}
+	parent.Extra, _, err = CalcBaseFee(params.TestChainConfig, feeConfig, genesis, parent.Time)
+	require.NoError(t, err)
+	header := &types.Header{Number: big.NewInt(2), Time: 4, GasUsed: 4_500_000, BaseFee: feeConfig.MinBaseFee}
+	header.Extra, _, err = CalcBaseFee(params.TestChainConfig, feeConfig, parent, header.Time)
+	require.NoError(t, err)
+	utilization, err = CalcGasUtilization(params.TestChainConfig, feeConfig, header)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, utilization)
+
+	// The gas used falls out of the window once it is older than the rollup window
+	child := &types.Header{Number: big.NewInt(3), Time: 20, BaseFee: feeConfig.MinBaseFee}
+	child.Extra, _, err = CalcBaseFee(params.TestChainConfig, feeConfig, header, child.Time)
+	require.NoError(t, err)
+	utilization, err = CalcGasUtilization(params.TestChainConfig, feeConfig, child)
+	require.NoError(t, err)
+	require.Zero(t, utilization)
+}
@@ -0,0 +1,61 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dummy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+)
+
+var errPChainHeightDecreased = errors.New("P-chain height less than parent's")
+
+// GetPChainHeight returns the P-chain height recorded in the extra data of [header] and false
+// if the validator info precompile was not enabled in [header].
+func GetPChainHeight(config *params.ChainConfig, header *types.Header) (uint64, bool) {
+	if !config.IsValidatorInfo(header.Number, new(big.Int).SetUint64(header.Time)) {
+		return 0, false
+	}
+	if len(header.Extra) != params.ExtraDataSize+params.PChainHeightExtraDataSize {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(header.Extra[params.ExtraDataSize:]), true
+}
+
+// AppendPChainHeight returns the extra data of a header consisting of [rollupWindow]
+// followed by [pChainHeight].
+func AppendPChainHeight(rollupWindow []byte, pChainHeight uint64) []byte {
+	extra := make([]byte, len(rollupWindow)+params.PChainHeightExtraDataSize)
+	copy(extra, rollupWindow)
+	binary.BigEndian.PutUint64(extra[len(rollupWindow):], pChainHeight)
+	return extra
+}
+
+// CalcPChainHeight returns the P-chain height to record in the child of [parent], given the P-chain
+// height [proposedHeight] provided by the proposervm, if any. The recorded P-chain height never
+// decreases, so a block built without a proposed height keeps the P-chain height of its parent.
+func CalcPChainHeight(config *params.ChainConfig, parent *types.Header, proposedHeight *uint64) uint64 {
+	height, _ := GetPChainHeight(config, parent)
+	if proposedHeight != nil && *proposedHeight > height {
+		height = *proposedHeight
+	}
+	return height
+}
+
+// verifyPChainHeight verifies that the P-chain height recorded in [header] is not less
+// than the P-chain height recorded in [parent].
+func verifyPChainHeight(config *params.ChainConfig, header *types.Header, parent *types.Header) error {
+	height, ok := GetPChainHeight(config, header)
+	if !ok {
+		return nil
+	}
+	if parentHeight, ok := GetPChainHeight(config, parent); ok && height < parentHeight {
+		return fmt.Errorf("%w: have %d, parent %d", errPChainHeightDecreased, height, parentHeight)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dummy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPChainHeight(t *testing.T) {
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewValidatorInfoConfig(big.NewInt(10), 60))
+	rollupWindow := make([]byte, params.ExtraDataSize)
+	newHeader := func(time uint64, pChainHeight uint64) *types.Header {
+		return &types.Header{Time: time, Extra: AppendPChainHeight(rollupWindow, pChainHeight)}
+	}
+	proposed := func(height uint64) *uint64 { return &height }
+
+	tests := map[string]struct {
+		header, parent *types.Header
+		proposedHeight *uint64
+		expectedHeight uint64
+		shouldErr      bool
+	}{
+		"parent before activation": {
+			parent:         &types.Header{Time: 5, Extra: rollupWindow},
+			header:         newHeader(10, 3),
+			proposedHeight: proposed(3),
+			expectedHeight: 3,
+		},
+		"increasing height": {
+			parent:         newHeader(10, 3),
+			header:         newHeader(11, 4),
+			proposedHeight: proposed(4),
+			expectedHeight: 4,
+		},
+		"same height": {
+			parent:         newHeader(10, 3),
+			header:         newHeader(11, 3),
+			expectedHeight: 3,
+		},
+		"decreasing height": {
+			parent:         newHeader(10, 3),
+			header:         newHeader(11, 2),
+			proposedHeight: proposed(2),
+			expectedHeight: 3,
+			shouldErr:      true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expectedHeight, CalcPChainHeight(&config, test.parent, test.proposedHeight))
+			err := verifyPChainHeight(&config, test.header, test.parent)
+			if test.shouldErr {
+				require.ErrorIs(t, err, errPChainHeightDecreased)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IAddressBlocklist is IAllowList {
+  // AddressBlocked is emitted when [account] is added to the blocklist
+  event AddressBlocked(address indexed account);
+
+  // AddressUnblocked is emitted when [account] is removed from the blocklist
+  event AddressUnblocked(address indexed account);
+
+  // blockAddress adds [account] to the blocklist
+  function blockAddress(address account) external;
+
+  // unblockAddress removes [account] from the blocklist
+  function unblockAddress(address account) external;
+
+  // isBlocked returns true if [account] is on the blocklist
+  function isBlocked(address account) external view returns (bool blocked);
+
+  // blockedAddressCount returns the number of blocked addresses
+  function blockedAddressCount() external view returns (uint256 count);
+
+  // blockedAddressAt returns the blocked address at [index]
+  function blockedAddressAt(uint256 index) external view returns (address account);
+}
@@ -2,6 +2,9 @@
 pragma solidity ^0.8.0;
 
 interface IAllowList {
+  // Emitted when [sender] sets the role of [account] to [role], once precompile events are enabled.
+  event RoleSet(uint256 indexed role, address indexed account, address indexed sender);
+
   // Set [addr] to have the admin role over the precompile contract.
   function setAdmin(address addr) external;
 
@@ -0,0 +1,25 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+interface IChainConfigReader {
+  // chainId returns the chainID used for replay protection
+  function chainId() external view returns (uint256 chainId);
+
+  // configHash returns the canonical hash of the chain config and of the upgrades activated at or before the current block
+  function configHash() external view returns (bytes32 hash);
+
+  // subnetEVMTimestamp returns whether the SubnetEVM upgrade is activated and its activation timestamp
+  function subnetEVMTimestamp() external view returns (bool activated, uint256 timestamp);
+
+  // feeConfigSource returns 0 if the fee config is read from genesis and 1 if it is managed by the FeeConfigManager precompile
+  function feeConfigSource() external view returns (uint8 source);
+
+  // allowFeeRecipients returns true if fee recipients are allowed by the genesis chain config
+  function allowFeeRecipients() external view returns (bool allowed);
+
+  // enabledPrecompiles returns the addresses of the enabled precompiles and their activation timestamps
+  function enabledPrecompiles() external view returns (address[] memory precompiles, uint256[] memory timestamps);
+
+  // precompileActivation returns whether the precompile at [precompileAddr] is enabled and its activation timestamp
+  function precompileActivation(address precompileAddr) external view returns (bool enabled, uint256 timestamp);
+}
@@ -0,0 +1,47 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IGasSponsor is IAllowList {
+  // Deposited is emitted when [sponsor] deposits [amount] to pay for gas
+  event Deposited(address indexed sponsor, uint256 amount);
+
+  // Withdrawn is emitted when [sponsor] withdraws [amount] from its deposit
+  event Withdrawn(address indexed sponsor, uint256 amount);
+
+  // SenderSponsored is emitted when [sponsor] starts paying for the gas of transactions issued by [sender]
+  event SenderSponsored(address indexed sponsor, address indexed sender);
+
+  // SenderUnsponsored is emitted when [sponsor] stops paying for the gas of transactions issued by [sender]
+  event SenderUnsponsored(address indexed sponsor, address indexed sender);
+
+  // TargetSponsored is emitted when [sponsor] starts paying for the gas of transactions sent to [target]
+  event TargetSponsored(address indexed sponsor, address indexed target);
+
+  // TargetUnsponsored is emitted when [sponsor] stops paying for the gas of transactions sent to [target]
+  event TargetUnsponsored(address indexed sponsor, address indexed target);
+
+  // deposit moves [amount] from the caller's balance into the caller's deposit
+  function deposit(uint256 amount) external;
+
+  // withdraw moves [amount] from the caller's deposit back to the caller's balance
+  function withdraw(uint256 amount) external;
+
+  // depositOf returns the deposit of [sponsor]
+  function depositOf(address sponsor) external view returns (uint256 amount);
+
+  // sponsorSender pays for the gas of transactions issued by [sender] from the caller's deposit
+  function sponsorSender(address sender) external;
+
+  // unsponsorSender stops paying for the gas of transactions issued by [sender]
+  function unsponsorSender(address sender) external;
+
+  // sponsorTarget pays for the gas of transactions sent to [target] from the caller's deposit
+  function sponsorTarget(address target) external;
+
+  // unsponsorTarget stops paying for the gas of transactions sent to [target]
+  function unsponsorTarget(address target) external;
+
+  // sponsorOf returns the sponsor of a transaction from [sender] to [target], or the zero address if there is none
+  function sponsorOf(address sender, address target) external view returns (address sponsor);
+}
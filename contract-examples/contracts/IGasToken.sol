@@ -0,0 +1,41 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IGasToken is IAllowList {
+  // Transfer is emitted when [value] tokens are moved from [from] to [to], or minted to [to] if [from] is zero
+  event Transfer(address indexed from, address indexed to, uint256 value);
+
+  // Approval is emitted when [owner] allows [spender] to transfer [value] of its tokens
+  event Approval(address indexed owner, address indexed spender, uint256 value);
+
+  // name returns the name of the token
+  function name() external view returns (string memory name);
+
+  // symbol returns the symbol of the token
+  function symbol() external view returns (string memory symbol);
+
+  // decimals returns the number of decimals of the token
+  function decimals() external view returns (uint8 decimals);
+
+  // totalSupply returns the amount of tokens in existence
+  function totalSupply() external view returns (uint256 supply);
+
+  // balanceOf returns the balance of [account]
+  function balanceOf(address account) external view returns (uint256 balance);
+
+  // transfer moves [amount] from the caller's balance to [to]
+  function transfer(address to, uint256 amount) external returns (bool success);
+
+  // allowance returns the amount [spender] may transfer on behalf of [owner]
+  function allowance(address owner, address spender) external view returns (uint256 amount);
+
+  // approve sets the amount [spender] may transfer on behalf of the caller
+  function approve(address spender, uint256 amount) external returns (bool success);
+
+  // transferFrom moves [amount] from the balance of [from] to [to], spending the caller's allowance
+  function transferFrom(address from, address to, uint256 amount) external returns (bool success);
+
+  // mint mints [amount] to [to]. Can only be called by enabled addresses
+  function mint(address to, uint256 amount) external;
+}
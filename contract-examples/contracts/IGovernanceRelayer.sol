@@ -0,0 +1,24 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+interface IGovernanceRelayer {
+  // GovernanceCallRelayed is emitted when the call of [precompileAddr] signed by [signer] with [nonce] is relayed by [relayer]
+  event GovernanceCallRelayed(address indexed signer, address indexed precompileAddr, address indexed relayer, uint256 nonce);
+
+  // relay calls [precompileAddr] with [input] on behalf of [signer], which signed the EIP-712 struct
+  // GovernanceCall(address precompileAddr,bytes input,uint256 nonce,uint256 deadline) with its current nonce.
+  // Only admin operations can be relayed: role changes of allow lists, setFeeConfig and the reward manager settings.
+  function relay(
+    address signer,
+    address precompileAddr,
+    bytes calldata input,
+    uint256 deadline,
+    bytes calldata signature
+  ) external returns (bytes memory output);
+
+  // nonces returns the nonce of the next governance call signed by [signer]
+  function nonces(address signer) external view returns (uint256 nonce);
+
+  // domainSeparator returns the EIP-712 domain separator of the governance calls
+  function domainSeparator() external view returns (bytes32 separator);
+}
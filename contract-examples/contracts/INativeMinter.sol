@@ -3,6 +3,9 @@ pragma solidity ^0.8.0;
 import "./IAllowList.sol";
 
 interface INativeMinter is IAllowList {
+  // Emitted when [sender] mints [amount] native coins to [recipient], once precompile events are enabled.
+  event NativeCoinMinted(address indexed sender, address indexed recipient, uint256 amount);
+
   // Mint [amount] number of native coins and send to [addr]
   function mintNativeCoin(address addr, uint256 amount) external;
 }
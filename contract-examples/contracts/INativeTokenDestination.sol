@@ -0,0 +1,20 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+interface INativeTokenDestination {
+  // TransferReceived is emitted when the transfer of [amount] to [recipient] with [nonce] is delivered by [relayer]
+  event TransferReceived(uint256 indexed nonce, address indexed recipient, address indexed relayer, uint256 amount);
+
+  // receiveTransfer mints the amount of the transfer in [signedMessage], which must be signed by a quorum of the
+  // validators of the source subnet at the P-chain height of the validator snapshot
+  function receiveTransfer(bytes calldata signedMessage) external returns (uint256 nonce);
+
+  // isTransferReceived returns true if the transfer with [nonce] was received
+  function isTransferReceived(uint256 nonce) external view returns (bool received);
+
+  // rateLimitWindow returns the start of the current rate limit window, the amount minted in it and the limit (0 if unlimited)
+  function rateLimitWindow() external view returns (uint256 windowStart, uint256 minted, uint256 limit);
+
+  // sourceChainID returns the ID of the chain the transfers are received from
+  function sourceChainID() external view returns (bytes32 chainID);
+}
@@ -0,0 +1,27 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+interface INativeTokenSource {
+  // TransferSent is emitted when [amount] is locked by [sender] for [recipient] on the destination chain. [message] is the
+  // unsigned Teleporter message with ID [messageID] that relayers deliver to the destination once signed by the validators
+  event TransferSent(
+    uint256 indexed nonce,
+    address indexed sender,
+    address indexed recipient,
+    uint256 amount,
+    bytes32 messageID,
+    bytes message
+  );
+
+  // transferToDestination locks [amount] of the native coin of the caller to be minted to [recipient] on the destination chain
+  function transferToDestination(address recipient, uint256 amount) external returns (bytes32 messageID, uint256 nonce);
+
+  // destinationChainID returns the ID of the chain the transfers are sent to
+  function destinationChainID() external view returns (bytes32 chainID);
+
+  // nextNonce returns the nonce of the next transfer
+  function nextNonce() external view returns (uint256 nonce);
+
+  // isMessageSent returns true if the message with [messageID] was sent
+  function isMessageSent(bytes32 messageID) external view returns (bool sent);
+}
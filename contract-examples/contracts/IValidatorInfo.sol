@@ -0,0 +1,20 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+interface IValidatorInfo {
+  // currentEpoch returns the epoch of the current validator snapshot and the P-chain height it was taken at
+  function currentEpoch() external view returns (uint256 epoch, uint256 pChainHeight);
+
+  // validatorCount returns the number of validators in the current snapshot
+  function validatorCount() external view returns (uint256 count);
+
+  // totalWeight returns the sum of the weights of the validators in the current snapshot
+  function totalWeight() external view returns (uint256 weight);
+
+  // validatorAt returns the validator at [index] of the current snapshot, sorted by nodeID
+  // blsPublicKey is empty if the validator has not registered a BLS public key
+  function validatorAt(uint256 index) external view returns (bytes20 nodeID, uint64 weight, bytes memory blsPublicKey);
+
+  // getValidator returns whether [nodeID] is a validator in the current snapshot, its weight and BLS public key
+  function getValidator(bytes20 nodeID) external view returns (bool isValidator, uint64 weight, bytes memory blsPublicKey);
+}
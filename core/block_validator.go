@@ -75,6 +75,17 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	// Blob transactions are included without their sidecar, and blocks reference a limited number of blobs
+	var blobs uint64
+	for i, tx := range block.Transactions() {
+		if tx.BlobTxSidecar() != nil {
+			return fmt.Errorf("blob transaction %d (%s) includes its sidecar", i, tx.Hash())
+		}
+		blobs += uint64(len(tx.BlobHashes()))
+	}
+	if blobs > params.BlobTxMaxBlobsPerBlock {
+		return fmt.Errorf("too many blobs in block: have %d, max %d", blobs, params.BlobTxMaxBlobsPerBlock)
+	}
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
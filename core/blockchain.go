@@ -40,19 +40,24 @@ import (
 
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/consensus"
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/state/pruner"
 	"github.com/ava-labs/subnet-evm/core/state/snapshot"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/ethdb"
 	"github.com/ava-labs/subnet-evm/metrics"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	lru "github.com/hashicorp/golang-lru"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 var (
@@ -81,6 +86,8 @@ var (
 	acceptorQueueGauge           = metrics.NewRegisteredGauge("chain/acceptor/queue/size", nil)
 	acceptorWorkTimer            = metrics.NewRegisteredCounter("chain/acceptor/work", nil)
 	acceptorWorkCount            = metrics.NewRegisteredCounter("chain/acceptor/work/count", nil)
+	acceptorIndexQueueGauge      = metrics.NewRegisteredGauge("chain/acceptor/index/queue/size", nil)
+	acceptorIndexWorkTimer       = metrics.NewRegisteredCounter("chain/acceptor/index/work", nil)
 	processedBlockGasUsedCounter = metrics.NewRegisteredCounter("chain/block/gas/used/processed", nil)
 	acceptedBlockGasUsedCounter  = metrics.NewRegisteredCounter("chain/block/gas/used/accepted", nil)
 	badBlockCounter              = metrics.NewRegisteredCounter("chain/block/bad/count", nil)
@@ -88,8 +95,14 @@ var (
 	acceptedTxsCounter  = metrics.NewRegisteredCounter("chain/txs/accepted", nil)
 	processedTxsCounter = metrics.NewRegisteredCounter("chain/txs/processed", nil)
 
+	acceptedBaseFeeHistogram      = metrics.NewRegisteredHistogram("chain/fee/basefee", nil, metrics.NewExpDecaySample(1028, 0.015))
+	acceptedBlockGasCostHistogram = metrics.NewRegisteredHistogram("chain/fee/blockgascost", nil, metrics.NewExpDecaySample(1028, 0.015))
+	gasUtilizationGauge           = metrics.NewRegisteredGaugeFloat64("chain/fee/gas/utilization", nil)
+
 	ErrRefuseToCorruptArchiver = errors.New("node has operated with pruning disabled, shutting down to prevent missing tries")
 
+	ErrAncientStoreMissing = errors.New("node has moved blocks into an ancient store, shutting down to prevent missing blocks")
+
 	errFutureBlockUnsupported  = errors.New("future block insertion not supported")
 	errCacheConfigNotSpecified = errors.New("must specify cache config")
 )
@@ -104,6 +117,10 @@ const (
 	badBlockLimit            = 10
 	TriesInMemory            = 128
 
+	// ancientFreezeInterval is the number of accepted blocks between moving old
+	// blocks into the ancient store, so that it is flushed to disk in batches.
+	ancientFreezeInterval = 1024
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
 	// Changelog:
@@ -160,9 +177,10 @@ type CacheConfig struct {
 	TrieCleanRejournal              time.Duration // Time interval to dump clean cache to disk periodically
 	TrieDirtyLimit                  int           // Memory limit (MB) at which to block on insert and force a flush of dirty trie nodes to disk
 	TrieDirtyCommitTarget           int           // Memory limit (MB) to target for the dirties cache before invoking commit
+	TriePrefetcherParallelism       int           // Maximum number of tries loaded concurrently by the trie prefetcher (unbounded if 0)
 	CommitInterval                  uint64        // Commit the trie every [CommitInterval] blocks.
 	Pruning                         bool          // Whether to disable trie write caching and GC altogether (archive node)
-	AcceptorQueueLimit              int           // Blocks to queue before blocking during acceptance
+	AcceptorQueueLimit              int           // Blocks to queue in each stage of the acceptor before blocking during acceptance
 	PopulateMissingTries            *uint64       // If non-nil, sets the starting height for re-generating historical tries.
 	PopulateMissingTriesParallelism int           // Is the number of readers to use when trying to populate missing tries.
 	AllowMissingTries               bool          // Whether to allow an archive node to run with pruning enabled
@@ -173,6 +191,18 @@ type CacheConfig struct {
 	SkipSnapshotRebuild             bool          // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
 	Preimages                       bool          // Whether to store preimage of trie key to the disk
 	AcceptedCacheSize               int           // Depth of accepted headers cache and accepted logs cache at the accepted tip
+	OnlinePruning                   bool          // Whether to prune historical state in the background (requires [Pruning])
+	OnlinePruningRetention          uint64        // Number of recent blocks whose committed state is kept by the online pruner
+	OnlinePruningBloomFilterSize    uint64        // Memory allowance (MB) for the bloom filter used by each online pruning pass
+	AncientStoreThreshold           uint64        // Number of recent accepted blocks kept in the key-value store if the database has an ancient store
+	StateScheme                     string        // Scheme used to store the state trie nodes (hash scheme if empty)
+	StateExportInterval             uint64        // Export the state to [StateExportDirectory] every [StateExportInterval] accepted blocks (disabled if 0)
+	StateExportDirectory            string        // Directory the periodic state exports are written to
+	StateDiffArchive                bool          // Whether to store the accounts and storage slots modified by each block
+	StateDiffRetention              uint64        // Number of recent accepted blocks whose state diff is kept (all if 0)
+	TxLookupLimit                   uint64        // Number of recent accepted blocks whose transactions are indexed (all if 0)
+	BlockPrefetch                   bool          // Whether to execute blocks on a throwaway state in the background to warm up the caches before processing them
+	HistoryRetention                uint64        // Number of recent accepted blocks whose bodies and receipts are kept (all if 0)
 }
 
 var DefaultCacheConfig = &CacheConfig{
@@ -186,6 +216,14 @@ var DefaultCacheConfig = &CacheConfig{
 	AcceptedCacheSize:     32,
 }
 
+// stateScheme returns the scheme used to store the state trie nodes.
+func (c *CacheConfig) stateScheme() string {
+	if c.StateScheme == "" {
+		return rawdb.HashScheme
+	}
+	return c.StateScheme
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -213,6 +251,7 @@ type BlockChain struct {
 	chainFeed         event.Feed
 	chainSideFeed     event.Feed
 	chainHeadFeed     event.Feed
+	chainReorgFeed    event.Feed
 	chainAcceptedFeed event.Feed
 	logsFeed          event.Feed
 	logsAcceptedFeed  event.Feed
@@ -229,6 +268,7 @@ type BlockChain struct {
 
 	stateCache          state.Database // State database to reuse between imports (contains state cache)
 	stateManager        TrieWriter
+	onlinePruner        *pruner.OnlinePruner
 	bodyCache           *lru.Cache // Cache for the most recent block bodies
 	receiptsCache       *lru.Cache // Cache for the most recent receipts per block
 	blockCache          *lru.Cache // Cache for the most recent entire blocks
@@ -256,6 +296,12 @@ type BlockChain struct {
 	// clean shutdown, all items inserted into the [acceptorQueue] will be processed.
 	acceptorQueue chan *types.Block
 
+	// [acceptorIndexQueue] connects the two stages of the Acceptor: blocks whose
+	// state has been accepted are queued for indexing and for the accepted feeds,
+	// so that slow indexing or feed subscribers do not hold up snapshot flattening
+	// and, unless both queues are full, do not hold up [Accept].
+	acceptorIndexQueue chan *types.Block
+
 	// [acceptorClosingLock], and [acceptorClosed] are used
 	// to synchronize the closing of the [acceptorQueue] channel.
 	//
@@ -273,6 +319,24 @@ type BlockChain struct {
 	// This is used during shutdown.
 	rejournalWg sync.WaitGroup
 
+	// [stateExporting] is 1 while a periodic state export is running, and
+	// [stateExportWg] is used to wait for it to complete during shutdown.
+	stateExporting int32
+	stateExportWg  sync.WaitGroup
+
+	// [txIndexUpdate] and [txIndexRebuild] notify the transaction indexer to update
+	// or rebuild the transaction lookup index, and [txIndexWg] is used to wait for
+	// it to complete during shutdown.
+	txIndexUpdate  chan struct{}
+	txIndexRebuild chan struct{}
+	txIndexWg      sync.WaitGroup
+
+	// [historyPruneUpdate] notifies the history pruner that blocks may have fallen
+	// out of the retention window, and [historyPruneWg] is used to wait for it to
+	// complete during shutdown.
+	historyPruneUpdate chan struct{}
+	historyPruneWg     sync.WaitGroup
+
 	// quit channel is used to listen for when the blockchain is shut down to close
 	// async processes.
 	// WaitGroups are used to ensure that async processes have finished during shutdown.
@@ -319,6 +383,7 @@ func NewBlockChain(
 			Journal:     cacheConfig.TrieCleanJournal,
 			Preimages:   cacheConfig.Preimages,
 			StatsPrefix: trieCleanCacheStatsNamespace,
+			Scheme:      cacheConfig.stateScheme(),
 		}),
 		bodyCache:           bodyCache,
 		receiptsCache:       receiptsCache,
@@ -331,13 +396,26 @@ func NewBlockChain(
 		badBlocks:           badBlocks,
 		senderCacher:        newTxSenderCacher(runtime.NumCPU()),
 		acceptorQueue:       make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
+		acceptorIndexQueue:  make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
 		quit:                make(chan struct{}),
+		txIndexUpdate:       make(chan struct{}, 1),
+		txIndexRebuild:      make(chan struct{}, 1),
+		historyPruneUpdate:  make(chan struct{}, 1),
 		acceptedLogsCache:   NewFIFOCache[common.Hash, [][]*types.Log](cacheConfig.AcceptedCacheSize),
 	}
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
+	// Blocks moved into an ancient store, including genesis, are missing
+	// without it
+	if err := bc.protectAncientStore(); err != nil {
+		return nil, err
+	}
+	if err := SetupStateScheme(db, cacheConfig.StateScheme); err != nil {
+		return nil, err
+	}
+
 	var err error
 	bc.hc, err = NewHeaderChain(db, chainConfig, cacheConfig, engine)
 	if err != nil {
@@ -376,6 +454,11 @@ func NewBlockChain(
 		return nil, err
 	}
 
+	// Move any blocks accepted while the ancient store was disabled into it
+	if err := bc.freezeAncients(bc.lastAccepted.NumberU64()); err != nil {
+		return nil, fmt.Errorf("could not move blocks into ancient store: %w", err)
+	}
+
 	// Populate missing tries if required
 	if err := bc.populateMissingTries(); err != nil {
 		return nil, fmt.Errorf("could not populate missing tries: %v", err)
@@ -393,8 +476,22 @@ func NewBlockChain(
 	// Warm up [hc.acceptedNumberCache] and [acceptedLogsCache]
 	bc.warmAcceptedCaches()
 
+	// Start recording trie flushes for online pruning if required
+	if err := bc.startOnlinePruner(); err != nil {
+		return nil, err
+	}
+
+	// Start pruning the block history past the retention window in the background
+	if err := bc.startHistoryPruner(); err != nil {
+		return nil, err
+	}
+
 	// Start processing accepted blocks effects in the background
 	go bc.startAcceptor()
+	go bc.startAcceptorIndexer()
+
+	// Start maintaining the transaction lookup index in the background
+	bc.startTxIndexer()
 
 	// If periodic cache journal is required, spin it up.
 	if bc.cacheConfig.TrieCleanRejournal > 0 && len(bc.cacheConfig.TrieCleanJournal) > 0 {
@@ -490,10 +587,13 @@ func (bc *BlockChain) warmAcceptedCaches() {
 	log.Info("Warmed accepted caches", "start", startIndex, "end", lastAccepted, "t", time.Since(startTime))
 }
 
-// startAcceptor starts processing items on the [acceptorQueue]. If a [nil]
-// object is placed on the [acceptorQueue], the [startAcceptor] will exit.
+// startAcceptor starts processing items on the [acceptorQueue]. This is the
+// first stage of the Acceptor, which accepts the state of each block before
+// passing it to the [acceptorIndexQueue]. Once the [acceptorQueue] is closed,
+// [startAcceptor] closes the [acceptorIndexQueue] and exits.
 func (bc *BlockChain) startAcceptor() {
 	log.Info("Starting Acceptor", "queue length", bc.cacheConfig.AcceptorQueueLimit)
+	defer close(bc.acceptorIndexQueue)
 
 	for next := range bc.acceptorQueue {
 		start := time.Now()
@@ -505,16 +605,68 @@ func (bc *BlockChain) startAcceptor() {
 			log.Crit("unable to flatten snapshot from acceptor", "blockHash", next.Hash(), "err", err)
 		}
 
+		// Prune historical state in the background whenever a new state root is committed
+		if bc.onlinePruner != nil && next.NumberU64()%bc.cacheConfig.CommitInterval == 0 {
+			bc.pruneHistoricalState(next)
+		}
+
+		// Drop the state diffs of the blocks past the retention window
+		if retention := bc.cacheConfig.StateDiffRetention; bc.cacheConfig.StateDiffArchive && retention != 0 && next.NumberU64() > retention {
+			number := next.NumberU64() - retention
+			rawdb.DeleteStateDiff(bc.db, rawdb.ReadCanonicalHash(bc.db, number), number)
+		}
+
+		// Export the state in the background at the configured interval
+		if interval := bc.cacheConfig.StateExportInterval; interval != 0 && next.NumberU64()%interval == 0 {
+			bc.exportStatePeriodically(next)
+		}
+
+		acceptorWorkTimer.Inc(time.Since(start).Milliseconds())
+		acceptorWorkCount.Inc(1)
+
+		// Blocks if the indexing stage is [AcceptorQueueLimit] blocks behind
+		acceptorIndexQueueGauge.Inc(1)
+		bc.acceptorIndexQueue <- next
+	}
+}
+
+// startAcceptorIndexer starts processing items on the [acceptorIndexQueue]. This
+// is the second stage of the Acceptor, which writes the indices of each accepted
+// block and notifies the accepted feeds before moving the [acceptorTip].
+func (bc *BlockChain) startAcceptorIndexer() {
+	for next := range bc.acceptorIndexQueue {
+		start := time.Now()
+		acceptorIndexQueueGauge.Dec(1)
+
 		// Update last processed and transaction lookup index
 		if err := bc.writeBlockAcceptedIndices(next); err != nil {
 			log.Crit("failed to write accepted block effects", "err", err)
 		}
 
+		// Unindex the transactions of the blocks past the transaction lookup limit
+		if bc.cacheConfig.TxLookupLimit != 0 {
+			bc.notifyTxIndexer(bc.txIndexUpdate)
+		}
+
+		// Prune the bodies and receipts of the blocks past the history retention window
+		if bc.cacheConfig.HistoryRetention != 0 {
+			bc.notifyHistoryPruner()
+		}
+
+		// Move old blocks into the ancient store in batches
+		if next.NumberU64()%ancientFreezeInterval == 0 {
+			if err := bc.freezeAncients(next.NumberU64()); err != nil {
+				log.Crit("failed to move blocks into ancient store", "err", err)
+			}
+		}
+
 		// Ensure [hc.acceptedNumberCache] and [acceptedLogsCache] have latest content
 		bc.hc.acceptedNumberCache.Put(next.NumberU64(), next.Header())
 		logs := rawdb.ReadLogs(bc.db, next.Hash(), next.NumberU64())
 		bc.acceptedLogsCache.Put(next.Hash(), logs)
 
+		bc.reportFeeMarket(next.Header())
+
 		// Update accepted feeds
 		flattenedLogs := types.FlattenLogs(logs)
 		bc.chainAcceptedFeed.Send(ChainEvent{Block: next, Hash: next.Hash(), Logs: flattenedLogs})
@@ -530,11 +682,36 @@ func (bc *BlockChain) startAcceptor() {
 		bc.acceptorTipLock.Unlock()
 		bc.acceptorWg.Done()
 
-		acceptorWorkTimer.Inc(time.Since(start).Milliseconds())
-		acceptorWorkCount.Inc(1)
+		acceptorIndexWorkTimer.Inc(time.Since(start).Milliseconds())
 	}
 }
 
+// reportFeeMarket updates the fee market metrics with the accepted block [header]: its base
+// fee and block gas cost, and the gas utilization of the rollup window ending at [header]
+// relative to the target gas of the fee config in effect after it.
+func (bc *BlockChain) reportFeeMarket(header *types.Header) {
+	if !metrics.Enabled {
+		return
+	}
+	if header.BaseFee != nil && header.BaseFee.IsInt64() {
+		acceptedBaseFeeHistogram.Update(header.BaseFee.Int64())
+	}
+	if header.BlockGasCost != nil && header.BlockGasCost.IsInt64() {
+		acceptedBlockGasCostHistogram.Update(header.BlockGasCost.Int64())
+	}
+	feeConfig, _, err := bc.GetFeeConfigAt(header)
+	if err != nil {
+		log.Debug("Failed to get fee config of accepted block", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	utilization, err := dummy.CalcGasUtilization(bc.chainConfig, feeConfig, header)
+	if err != nil {
+		log.Debug("Failed to calculate gas utilization of accepted block", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	gasUtilizationGauge.Update(utilization)
+}
+
 // addAcceptorQueue adds a new *types.Block to the [acceptorQueue]. This will
 // block if there are [AcceptorQueueLimit] items in [acceptorQueue].
 func (bc *BlockChain) addAcceptorQueue(b *types.Block) {
@@ -650,7 +827,12 @@ func (bc *BlockChain) loadLastState(lastAcceptedHash common.Hash) error {
 	// reprocessState is necessary to ensure that the last accepted state is
 	// available. The state may not be available if it was not committed due
 	// to an unclean shutdown.
-	return bc.reprocessState(bc.lastAccepted, 2*bc.cacheConfig.CommitInterval)
+	reexec := 2 * bc.cacheConfig.CommitInterval
+	if bc.cacheConfig.stateScheme() == rawdb.PathScheme {
+		// The state on disk also lags behind the tries kept in memory at tip
+		reexec += tipBufferSize
+	}
+	return bc.reprocessState(bc.lastAccepted, reexec)
 }
 
 func (bc *BlockChain) loadGenesisState() error {
@@ -849,6 +1031,22 @@ func (bc *BlockChain) Stop() {
 	bc.stopAcceptor()
 	log.Info("Acceptor queue drained", "t", time.Since(start))
 
+	if bc.onlinePruner != nil {
+		log.Info("Stopping online pruner")
+		start = time.Now()
+		bc.onlinePruner.Stop()
+		log.Info("Online pruner stopped", "t", time.Since(start))
+	}
+
+	log.Info("Waiting for state export to complete")
+	bc.stateExportWg.Wait()
+
+	log.Info("Waiting for transaction indexer to stop")
+	bc.txIndexWg.Wait()
+
+	log.Info("Waiting for history pruner to stop")
+	bc.historyPruneWg.Wait()
+
 	log.Info("Shutting down state manager")
 	start = time.Now()
 	if err := bc.stateManager.Shutdown(); err != nil {
@@ -995,6 +1193,7 @@ func (bc *BlockChain) Reject(block *types.Block) error {
 	// Remove the block since its data is no longer needed
 	batch := bc.db.NewBatch()
 	rawdb.DeleteBlock(batch, block.Hash(), block.NumberU64())
+	rawdb.DeleteStateDiff(batch, block.Hash(), block.NumberU64())
 	if err := batch.Write(); err != nil {
 		return fmt.Errorf("failed to write delete block batch: %w", err)
 	}
@@ -1080,6 +1279,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return err
 	}
+	if diff := state.StateDiff(); diff != nil {
+		rawdb.WriteStateDiff(bc.db, block.Hash(), block.NumberU64(), diff)
+	}
 
 	// Note: if InsertTrie must be the last step in verification that can return an error.
 	// This allows [stateManager] to assume that if it inserts a trie without returning an
@@ -1129,10 +1331,12 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 		}
 	}
 	// Pre-checks passed, start the full block imports
+	bc.senderCacher.RecoverFromBlocks(bc.chainConfig, chain)
+
 	bc.chainmu.Lock()
 	defer bc.chainmu.Unlock()
 	for n, block := range chain {
-		if err := bc.insertBlock(block, true); err != nil {
+		if err := bc.insertBlock(context.Background(), block, true); err != nil {
 			return n, err
 		}
 	}
@@ -1141,15 +1345,17 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 }
 
 func (bc *BlockChain) InsertBlock(block *types.Block) error {
-	return bc.InsertBlockManual(block, true)
+	return bc.InsertBlockManual(context.Background(), block, true)
 }
 
-func (bc *BlockChain) InsertBlockManual(block *types.Block, writes bool) error {
+// InsertBlockManual verifies and processes [block], writing it and its state if [writes]
+// is set. The spans recording the insertion are children of the span of [ctx], if any.
+func (bc *BlockChain) InsertBlockManual(ctx context.Context, block *types.Block, writes bool) error {
 	bc.blockProcFeed.Send(true)
 	defer bc.blockProcFeed.Send(false)
 
 	bc.chainmu.Lock()
-	err := bc.insertBlock(block, writes)
+	err := bc.insertBlock(ctx, block, writes)
 	bc.chainmu.Unlock()
 
 	return err
@@ -1172,15 +1378,66 @@ func (bc *BlockChain) gatherBlockLogs(hash common.Hash, number uint64, removed b
 	return logs
 }
 
-func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
+// prefetchBlock executes [block] on a throwaway copy of its parent state in the
+// background until [interrupt] is set, so that the accounts, storage slots and
+// trie nodes it touches are cached when it is processed. The fee config and
+// coinbase read from the precompiles at the parent are cached first, as they are
+// needed to verify the header.
+func (bc *BlockChain) prefetchBlock(block *types.Block, interrupt *uint32) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return
+	}
+	go func() {
+		if _, _, err := bc.GetFeeConfigAt(parent); err != nil {
+			return
+		}
+		if _, _, err := bc.GetCoinbaseAt(parent); err != nil {
+			return
+		}
+		if len(block.Transactions()) == 0 || atomic.LoadUint32(interrupt) == 1 {
+			return
+		}
+		throwaway, err := state.New(parent.Root, bc.stateCache, bc.snaps)
+		if err != nil {
+			return
+		}
+		bc.prefetcher.Prefetch(block, throwaway, bc.vmConfig, interrupt)
+	}()
+}
+
+func (bc *BlockChain) insertBlock(ctx context.Context, block *types.Block, writes bool) (err error) {
+	ctx, span := tracing.Start(ctx, "BlockChain.insertBlock",
+		attribute.Int64("number", block.Number().Int64()),
+		attribute.Stringer("hash", block.Hash()),
+		attribute.Int("txs", len(block.Transactions())),
+		attribute.Bool("writes", writes),
+	)
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	start := time.Now()
 	bc.senderCacher.Recover(types.MakeSigner(bc.chainConfig, block.Number(), new(big.Int).SetUint64(block.Time())), block.Transactions())
 
+	// Warm up the caches with the state touched by the block in the background,
+	// while the block is verified and processed.
+	if bc.cacheConfig.BlockPrefetch {
+		var interrupt uint32
+		bc.prefetchBlock(block, &interrupt)
+		defer atomic.StoreUint32(&interrupt, 1)
+	}
+
 	substart := time.Now()
-	err := bc.engine.VerifyHeader(bc, block.Header())
+	_, verifySpan := tracing.Start(ctx, "BlockChain.verifyBlock")
+	err = bc.engine.VerifyHeader(bc, block.Header())
 	if err == nil {
 		err = bc.validator.ValidateBody(block)
 	}
+	verifySpan.End()
 
 	switch {
 	case errors.Is(err, ErrKnownBlock):
@@ -1239,14 +1496,23 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	blockStateInitTimer.Inc(time.Since(substart).Milliseconds())
 
 	// Enable prefetching to pull in trie node paths while processing transactions
-	statedb.StartPrefetcher("chain")
+	statedb.StartPrefetcher("chain", bc.cacheConfig.TriePrefetcherParallelism)
 	activeState = statedb
+	if bc.cacheConfig.StateDiffArchive {
+		statedb.RecordStateDiff()
+	}
 
 	// If we have a followup block, run that against the current state to pre-cache
 	// transactions and probabilistically some of the account/storage trie nodes.
 	// Process block using the parent state as reference point
 	substart = time.Now()
-	receipts, logs, usedGas, err := bc.processor.Process(block, parent, statedb, bc.vmConfig)
+	processCtx, processSpan := tracing.Start(ctx, "BlockChain.processBlock")
+	vmConfig := bc.vmConfig
+	if tracing.Enabled() {
+		vmConfig.TraceContext = processCtx
+	}
+	receipts, logs, usedGas, err := bc.processor.Process(block, parent, statedb, vmConfig)
+	processSpan.End()
 	if serr := statedb.Error(); serr != nil {
 		log.Error("statedb error encountered", "err", serr, "number", block.Number(), "hash", block.Hash())
 	}
@@ -1267,7 +1533,10 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 
 	// Validate the state using the default validator
 	substart = time.Now()
-	if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
+	_, validateSpan := tracing.Start(ctx, "BlockChain.validateState")
+	err = bc.validator.ValidateState(block, statedb, receipts, usedGas)
+	validateSpan.End()
+	if err != nil {
 		bc.reportBlock(block, receipts, err)
 		return err
 	}
@@ -1293,7 +1562,10 @@ func (bc *BlockChain) insertBlock(block *types.Block, writes bool) error {
 	// will be cleaned up in Accept/Reject so we need to ensure an error cannot occur
 	// later in verification, since that would cause the referenced root to never be dereferenced.
 	substart = time.Now()
-	if err := bc.writeBlockAndSetHead(block, receipts, logs, statedb); err != nil {
+	_, writeSpan := tracing.Start(ctx, "BlockChain.writeBlock")
+	err = bc.writeBlockAndSetHead(block, receipts, logs, statedb)
+	writeSpan.End()
+	if err != nil {
 		return err
 	}
 	// Update the metrics touched during block commit
@@ -1471,9 +1743,12 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		bc.logsFeed.Send(mergeLogs(rebirthLogs, false))
 	}
 	if len(oldChain) > 0 {
+		dropped := make([]common.Hash, len(oldChain))
 		for i := len(oldChain) - 1; i >= 0; i-- {
 			bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
+			dropped[i] = oldChain[i].Hash()
 		}
+		bc.chainReorgFeed.Send(ChainReorgEvent{OldHead: oldHead, NewHead: newHead, CommonBlock: commonBlock, Depth: len(oldChain), Dropped: dropped})
 	}
 	return nil
 }
@@ -1603,7 +1878,7 @@ func (bc *BlockChain) reprocessBlock(parent *types.Block, current *types.Block)
 	}
 
 	// Enable prefetching to pull in trie node paths while processing transactions
-	statedb.StartPrefetcher("chain")
+	statedb.StartPrefetcher("chain", bc.cacheConfig.TriePrefetcherParallelism)
 	defer func() {
 		statedb.StopPrefetcher()
 	}()
@@ -1804,6 +2079,51 @@ func (bc *BlockChain) protectTrieIndex() error {
 	return nil
 }
 
+// hasAncientStore returns true if the database has an ancient store to move old
+// blocks into.
+func (bc *BlockChain) hasAncientStore() bool {
+	_, err := bc.db.Ancients()
+	return err == nil
+}
+
+// protectAncientStore refuses to start if blocks have been moved into an ancient
+// store that the database no longer has.
+func (bc *BlockChain) protectAncientStore() error {
+	if bc.hasAncientStore() {
+		return nil
+	}
+	used, err := rawdb.HasAncientStoreUsed(bc.db)
+	if err != nil {
+		return fmt.Errorf("failed to check if the chain has moved blocks into an ancient store: %w", err)
+	}
+	if used {
+		return ErrAncientStoreMissing
+	}
+	return nil
+}
+
+// freezeAncients moves the accepted blocks older than the most recent
+// [AncientStoreThreshold] blocks ending at [head] into the ancient store, if the
+// database has one.
+//
+// The first call after the ancient store is enabled migrates all the existing
+// blocks older than the threshold, which may take a while.
+func (bc *BlockChain) freezeAncients(head uint64) error {
+	if !bc.hasAncientStore() || head < bc.cacheConfig.AncientStoreThreshold {
+		return nil
+	}
+	limit := head - bc.cacheConfig.AncientStoreThreshold
+	start := time.Now()
+	frozen, err := rawdb.FreezeCanonicalBlocks(bc.db, limit)
+	if err != nil {
+		return err
+	}
+	if frozen > 0 {
+		log.Debug("Moved blocks into ancient store", "blocks", frozen, "limit", limit, "t", time.Since(start))
+	}
+	return nil
+}
+
 // populateMissingTries iterates from [bc.cacheConfig.PopulateMissingTries] (defaults to 0)
 // to [LastAcceptedBlock] and persists all tries to disk that are not already on disk. This is
 // used to fill trie index gaps in an "archive" node without resyncing from scratch.
@@ -1987,11 +2307,16 @@ func (bc *BlockChain) ResetToStateSyncedBlock(block *types.Block) error {
 	bc.hc.SetCurrentHeader(block.Header())
 
 	lastAcceptedHash := block.Hash()
+	if bc.onlinePruner != nil {
+		bc.onlinePruner.Stop()
+		bc.onlinePruner = nil
+	}
 	bc.stateCache = state.NewDatabaseWithConfig(bc.db, &trie.Config{
 		Cache:       bc.cacheConfig.TrieCleanLimit,
 		Journal:     bc.cacheConfig.TrieCleanJournal,
 		Preimages:   bc.cacheConfig.Preimages,
 		StatsPrefix: trieCleanCacheStatsNamespace,
+		Scheme:      bc.cacheConfig.stateScheme(),
 	})
 	if err := bc.loadLastState(lastAcceptedHash); err != nil {
 		return err
@@ -2006,5 +2331,71 @@ func (bc *BlockChain) ResetToStateSyncedBlock(block *types.Block) error {
 	}
 
 	bc.initSnapshot(head)
+	return bc.startOnlinePruner()
+}
+
+// startOnlinePruner creates the online pruner for the current trie database if
+// online pruning is enabled.
+func (bc *BlockChain) startOnlinePruner() error {
+	if !bc.cacheConfig.OnlinePruning {
+		return nil
+	}
+	if !bc.cacheConfig.Pruning {
+		return errors.New("cannot run online pruning while pruning is disabled")
+	}
+	onlinePruner, err := pruner.NewOnlinePruner(bc.stateCache.TrieDB(), bc.cacheConfig.OnlinePruningBloomFilterSize)
+	if err != nil {
+		return fmt.Errorf("failed to create online pruner: %w", err)
+	}
+	bc.onlinePruner = onlinePruner
 	return nil
 }
+
+// pruneHistoricalState starts an online pruning pass (unless one is running)
+// keeping the genesis state, the committed states within the retention window
+// ending at [head], and the recent states kept in the trie database at tip.
+//
+// Assumes [head] is the last block processed by the acceptor and its state root
+// was just committed.
+func (bc *BlockChain) pruneHistoricalState(head *types.Block) {
+	// The snapshot generator reads the state trie at the root of the disk layer,
+	// which may not be retained.
+	if bc.snaps != nil {
+		if generating, err := bc.snaps.Generating(); err != nil || generating {
+			log.Debug("Skipping online pruning while snapshot is not ready", "generating", generating, "err", err)
+			return
+		}
+	}
+
+	var (
+		interval = bc.cacheConfig.CommitInterval
+		number   = head.NumberU64()
+		oldest   uint64
+		retained = []common.Hash{bc.genesisBlock.Root()}
+		recent   []common.Hash
+	)
+	if number > bc.cacheConfig.OnlinePruningRetention {
+		oldest = number - bc.cacheConfig.OnlinePruningRetention
+	}
+	// Roots are ordered by height so that each is marked relative to the previous one.
+	for height := (oldest + interval - 1) / interval * interval; height <= number; height += interval {
+		if height == 0 {
+			continue
+		}
+		if header := bc.GetHeaderByNumber(height); header != nil {
+			retained = append(retained, header.Root)
+		}
+	}
+	var first uint64
+	if number >= tipBufferSize {
+		first = number - tipBufferSize + 1
+	}
+	for height := first; height < number; height++ {
+		if header := bc.GetHeaderByNumber(height); header != nil {
+			recent = append(recent, header.Root)
+		}
+	}
+	if !bc.onlinePruner.Prune(retained, recent) {
+		log.Debug("Skipping online pruning while the previous pass is running", "number", number)
+	}
+}
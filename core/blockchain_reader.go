@@ -177,6 +177,16 @@ func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*type
 	return
 }
 
+// GetStateDiff retrieves the accounts and storage slots modified by the block
+// with the given hash, if the state diff archive holds it.
+func (bc *BlockChain) GetStateDiff(hash common.Hash) *types.StateDiff {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil
+	}
+	return rawdb.ReadStateDiff(bc.db, hash, *number)
+}
+
 // GetReceiptsByHash retrieves the receipts for all transactions in a given block.
 func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	if receipts, ok := bc.receiptsCache.Get(hash); ok {
@@ -310,6 +320,11 @@ func (bc *BlockChain) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Su
 	return bc.scope.Track(bc.chainHeadFeed.Subscribe(ch))
 }
 
+// SubscribeChainReorgEvent registers a subscription of ChainReorgEvent.
+func (bc *BlockChain) SubscribeChainReorgEvent(ch chan<- ChainReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.chainReorgFeed.Subscribe(ch))
+}
+
 // SubscribeChainSideEvent registers a subscription of ChainSideEvent.
 func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Subscription {
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
@@ -348,8 +363,8 @@ func (bc *BlockChain) SubscribeAcceptedTransactionEvent(ch chan<- NewTxsEvent) e
 func (bc *BlockChain) GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig, *big.Int, error) {
 	config := bc.Config()
 	bigTime := new(big.Int).SetUint64(parent.Time)
-	if !config.IsFeeConfigManager(bigTime) {
-		return config.FeeConfig, common.Big0, nil
+	if !config.IsFeeConfigManager(parent.Number, bigTime) {
+		return config.FeeConfigAt(bigTime), common.Big0, nil
 	}
 
 	// try to return it from the cache
@@ -392,8 +407,8 @@ func (bc *BlockChain) GetCoinbaseAt(parent *types.Header) (common.Address, bool,
 		return constants.BlackholeAddr, false, nil
 	}
 
-	if !config.IsRewardManager(bigTime) {
-		if bc.chainConfig.AllowFeeRecipients {
+	if !config.IsRewardManager(parent.Number, bigTime) {
+		if config.AllowFeeRecipientsAt(bigTime) {
 			return common.Address{}, true, nil
 		} else {
 			return constants.BlackholeAddr, false, nil
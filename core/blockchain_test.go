@@ -4,6 +4,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"os"
@@ -17,12 +18,18 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/tracing"
+	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/fsnotify/fsnotify"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var (
@@ -383,6 +390,107 @@ func TestBlockChainOfflinePruningUngracefulShutdown(t *testing.T) {
 	}
 }
 
+// requireStateComplete fails if any trie node of the state at [root] is missing from [triedb].
+func requireStateComplete(t *testing.T, triedb *trie.Database, root common.Hash) {
+	t.Helper()
+
+	tr, err := trie.NewStateTrie(common.Hash{}, root, triedb)
+	require.NoError(t, err)
+	it := tr.NodeIterator(nil)
+	for it.Next(true) {
+	}
+	require.NoError(t, it.Error())
+}
+
+func TestBlockChainOnlinePruning(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:               256,
+			TrieDirtyLimit:               256,
+			TrieDirtyCommitTarget:        20,
+			Pruning:                      true,
+			CommitInterval:               4,
+			SnapshotLimit:                256,
+			AcceptorQueueLimit:           64,
+			OnlinePruning:                true,
+			OnlinePruningRetention:       8,
+			OnlinePruningBloomFilterSize: 256,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	// Fund a new account in every block so that every state is distinct.
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 64, 10, func(i int, gen *BlockGen) {
+		to := common.BigToAddress(big.NewInt(int64(i + 1)))
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), to, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// Passes scheduled by the acceptor are skipped while another one is running,
+	// and the nodes flushed since the start of the previous pass are not pruned,
+	// so run two more passes from the last accepted block.
+	for i := 0; i < 2; i++ {
+		blockchain.onlinePruner.Wait()
+		blockchain.pruneHistoricalState(chain[len(chain)-1])
+	}
+	blockchain.onlinePruner.Wait()
+
+	// Read the retained states from disk only, so that nodes cached in memory
+	// cannot hide pruned nodes.
+	diskTrieDB := trie.NewDatabase(chainDB)
+	requireStateComplete(t, diskTrieDB, genesis.Root())
+	lastNumber := chain[len(chain)-1].NumberU64()
+	for _, block := range chain {
+		number := block.NumberU64()
+		switch {
+		case number%config.CommitInterval != 0:
+			continue
+		case number+config.OnlinePruningRetention >= lastNumber:
+			requireStateComplete(t, diskTrieDB, block.Root())
+		case number+tipBufferSize > lastNumber:
+			continue // kept as one of the recent states at tip
+		default:
+			require.False(t, rawdb.HasTrieNode(chainDB, block.Root()), "state of block %d should have been pruned", number)
+		}
+	}
+	// The recent states kept in memory at tip must remain complete.
+	for _, block := range chain[len(chain)-tipBufferSize:] {
+		requireStateComplete(t, blockchain.stateCache.TrieDB(), block.Root())
+	}
+
+	// The node restarts from the last accepted block after pruning.
+	lastAcceptedHash := blockchain.LastConsensusAcceptedBlock().Hash()
+	blockchain.Stop()
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, lastAcceptedHash)
+	require.NoError(t, err)
+	require.True(t, blockchain.HasState(chain[len(chain)-1].Root()))
+}
+
 func testRepopulateMissingTriesParallel(t *testing.T, parallelism int) {
 	var (
 		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -745,3 +853,283 @@ func TestCanonicalHashMarker(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockChainAncientStore(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB    = rawdb.NewMemoryDatabase()
+		kvDB     = memorydb.New()
+		chainDB  = rawdb.NewDatabase(kvDB)
+		ancients = t.TempDir()
+		config   = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			AncientStoreThreshold: 8,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	// Accept blocks before the ancient store is enabled
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 20, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	lastAccepted := chain[len(chain)-1]
+	blockchain.Stop()
+
+	// Enabling the ancient store migrates the blocks older than the threshold
+	chainDB, err = rawdb.NewDatabaseWithFreezer(kvDB, ancients, false)
+	require.NoError(t, err)
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, lastAccepted.Hash())
+	require.NoError(t, err)
+
+	frozen, err := chainDB.Ancients()
+	require.NoError(t, err)
+	require.EqualValues(t, lastAccepted.NumberU64()-config.AncientStoreThreshold+1, frozen)
+	for _, block := range append([]*types.Block{genesis}, chain...) {
+		number := block.NumberU64()
+		inKeyValueStore := rawdb.ReadHeaderRLP(rawdb.NewDatabase(kvDB), block.Hash(), number) != nil
+		require.Equal(t, number >= frozen, inKeyValueStore, "block %d", number)
+
+		require.Equal(t, block.Hash(), blockchain.GetBlockByNumber(number).Hash())
+		receipts := blockchain.GetReceiptsByHash(block.Hash())
+		require.Len(t, receipts, len(block.Transactions()))
+	}
+	blockchain.Stop()
+
+	// Dropping the ancient store would leave the frozen blocks missing
+	_, err = createBlockChain(rawdb.NewDatabase(kvDB), config, gspec.Config, lastAccepted.Hash())
+	require.ErrorIs(t, err, ErrAncientStoreMissing)
+}
+
+func TestBlockChainPathScheme(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			StateScheme:           rawdb.PathScheme,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	require.NoError(t, SetupStateScheme(chainDB, rawdb.PathScheme))
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	lastAccepted := chain[len(chain)-1]
+	require.True(t, blockchain.HasState(lastAccepted.Root()))
+	blockchain.Stop()
+
+	// Only the last accepted state is kept on disk after shutting down
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, lastAccepted.Hash())
+	require.NoError(t, err)
+	require.True(t, blockchain.HasState(lastAccepted.Root()))
+	require.False(t, blockchain.HasState(genesis.Root()))
+	state, err := blockchain.StateAt(lastAccepted.Root())
+	require.NoError(t, err)
+	require.EqualValues(t, len(chain), state.GetNonce(addr1))
+	blockchain.Stop()
+
+	// The state scheme of the database cannot be changed
+	hashConfig := *config
+	hashConfig.StateScheme = rawdb.HashScheme
+	_, err = createBlockChain(chainDB, &hashConfig, gspec.Config, lastAccepted.Hash())
+	require.ErrorIs(t, err, errStateSchemeMismatch)
+}
+
+func TestAcceptorPipelineBackpressure(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    2,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 7, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+
+	// The subscriber does not read the accepted events until all blocks are accepted
+	events := make(chan ChainEvent)
+	sub := blockchain.SubscribeChainAcceptedEvent(events)
+	defer sub.Unsubscribe()
+
+	// Each stage holds one block and queues [AcceptorQueueLimit] more, so the
+	// first 6 blocks are accepted without waiting for the subscriber.
+	accepted := make(chan struct{})
+	go func() {
+		defer close(accepted)
+		for _, block := range chain[:6] {
+			require.NoError(t, blockchain.Accept(block))
+		}
+	}()
+	select {
+	case <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("accepting blocks should not wait for the subscriber")
+	}
+	require.Eventually(t, func() bool {
+		return len(blockchain.acceptorIndexQueue) == config.AcceptorQueueLimit
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once both stages are full, acceptance waits for the subscriber
+	accepted = make(chan struct{})
+	go func() {
+		defer close(accepted)
+		require.NoError(t, blockchain.Accept(chain[6]))
+	}()
+	select {
+	case <-accepted:
+		t.Fatal("accepting a block should wait once both stages are full")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for _, block := range chain {
+		require.Equal(t, block.Hash(), (<-events).Hash)
+	}
+	<-accepted
+	blockchain.DrainAcceptorQueue()
+	require.Equal(t, chain[len(chain)-1].Hash(), blockchain.LastConsensusAcceptedBlock().Hash())
+	for _, block := range chain {
+		require.NotNil(t, blockchain.GetTransactionLookup(block.Transactions()[0].Hash()))
+	}
+}
+
+func TestInsertBlockSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test"))
+	defer tracing.SetTracer(nil)
+
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, DefaultCacheConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 1, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	require.NoError(t, blockchain.InsertBlockManual(ctx, chain[0], true))
+	parent.End()
+
+	// The stages of the insertion are recorded as children of the insertion span,
+	// itself a child of the span of the caller.
+	spans := recorder.Ended()
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	require.Equal(t, []string{
+		"BlockChain.verifyBlock",
+		"BlockChain.processBlock",
+		"BlockChain.validateState",
+		"BlockChain.writeBlock",
+		"BlockChain.insertBlock",
+		"parent",
+	}, names)
+	insert := spans[4]
+	require.Equal(t, parent.SpanContext().SpanID(), insert.Parent().SpanID())
+	for _, span := range spans[:4] {
+		require.Equal(t, insert.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+	require.Contains(t, insert.Attributes(), attribute.Int("txs", 1))
+}
@@ -0,0 +1,380 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// chainExportVersion is the version of the format written by [ExportChain].
+const chainExportVersion = 1
+
+// chainImportBatchSize is the number of blocks inserted at once when replaying a chain export.
+const chainImportBatchSize = 128
+
+var errChainExportMalformed = errors.New("malformed chain export")
+
+// chainExportHeader is the first item of a chain export.
+type chainExportHeader struct {
+	Version uint64
+	Genesis common.Hash
+	// Config and UpgradeConfig are the JSON encodings of the chain config of the exporting node,
+	// and of the upgrades it applied on top of the genesis, at the time of the export.
+	Config        []byte
+	UpgradeConfig []byte
+}
+
+// chainExportEntry is an item of a chain export following its header.
+type chainExportEntry struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+}
+
+// ChainExportStats summarizes a chain export or import.
+type ChainExportStats struct {
+	First  uint64 `json:"first"`
+	Last   uint64 `json:"last"`
+	Blocks uint64 `json:"blocks"`
+	Txs    uint64 `json:"txs"`
+}
+
+// ExportChain writes the accepted blocks from [first] to [last] and their
+// receipts to [w] as a gzip compressed stream of RLP items: a header holding
+// the chain config and the upgrade config of this node, followed by an item
+// per block holding the block and its receipts in their storage encoding.
+//
+// The blocks and receipts must not have been pruned.
+func (bc *BlockChain) ExportChain(w io.Writer, first uint64, last uint64) (*ChainExportStats, error) {
+	if lastAccepted := bc.LastAcceptedBlock().NumberU64(); last > lastAccepted {
+		return nil, fmt.Errorf("cannot export block %d after the last accepted block %d", last, lastAccepted)
+	}
+	config, err := json.Marshal(bc.chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	upgradeConfig, err := json.Marshal(bc.chainConfig.Upgrades())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		start = time.Now()
+		gz    = gzip.NewWriter(w)
+		stats = &ChainExportStats{First: first, Last: last}
+	)
+	header := &chainExportHeader{
+		Version:       chainExportVersion,
+		Genesis:       bc.genesisBlock.Hash(),
+		Config:        config,
+		UpgradeConfig: upgradeConfig,
+	}
+	if err := rlp.Encode(gz, header); err != nil {
+		return nil, err
+	}
+	err = bc.ExportCallback(func(block *types.Block) error {
+		receipts := rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64())
+		if receipts == nil && len(block.Transactions()) != 0 {
+			return fmt.Errorf("export failed on #%d: receipts not found", block.NumberU64())
+		}
+		entry := &chainExportEntry{
+			Block:    block,
+			Receipts: make([]*types.ReceiptForStorage, len(receipts)),
+		}
+		for i, receipt := range receipts {
+			entry.Receipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		stats.Blocks++
+		stats.Txs += uint64(len(block.Transactions()))
+		return rlp.Encode(gz, entry)
+	}, first, last)
+	if err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	log.Info("Exported chain", "first", first, "last", last, "txs", stats.Txs, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// ExportChainToFile exports the accepted blocks from [first] to [last] to the
+// file at [path] as [ExportChain] does. The file is only created once the
+// export completes.
+func (bc *BlockChain) ExportChainToFile(path string, first uint64, last uint64) (*ChainExportStats, error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := bc.ExportChain(f, first, last)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return stats, os.Rename(tmp, path)
+}
+
+// chainExportReader reads the blocks of a chain export, checking that they are
+// linked and that their transactions and receipts match their headers.
+type chainExportReader struct {
+	gz     *gzip.Reader
+	stream *rlp.Stream
+	config *params.ChainConfig
+	// exported is the chain config of the exporting node, with its upgrade config.
+	exported *params.ChainConfig
+	parent   *types.Block
+}
+
+// newChainExportReader reads the header of the chain export in [r], which must
+// belong to the chain with the [genesis] hash. The receipts are derived with
+// [config], the chain config of the importing node.
+func newChainExportReader(r io.Reader, genesis common.Hash, config *params.ChainConfig) (*chainExportReader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		stream = rlp.NewStream(gz, 0)
+		header chainExportHeader
+	)
+	if err := stream.Decode(&header); err != nil {
+		gz.Close()
+		return nil, fmt.Errorf("%w: failed to decode header: %v", errChainExportMalformed, err)
+	}
+	if header.Version != chainExportVersion {
+		gz.Close()
+		return nil, fmt.Errorf("unsupported chain export version %d, expected %d", header.Version, chainExportVersion)
+	}
+	if header.Genesis != genesis {
+		gz.Close()
+		return nil, fmt.Errorf("chain export belongs to the chain with genesis %s, expected %s", header.Genesis, genesis)
+	}
+	exported := new(params.ChainConfig)
+	if err := json.Unmarshal(header.Config, exported); err != nil {
+		gz.Close()
+		return nil, fmt.Errorf("%w: failed to decode chain config: %v", errChainExportMalformed, err)
+	}
+	if err := json.Unmarshal(header.UpgradeConfig, &exported.UpgradeConfig); err != nil {
+		gz.Close()
+		return nil, fmt.Errorf("%w: failed to decode upgrade config: %v", errChainExportMalformed, err)
+	}
+	return &chainExportReader{
+		gz:       gz,
+		stream:   stream,
+		config:   config,
+		exported: exported,
+	}, nil
+}
+
+// next returns the next block of the export and its receipts, or [io.EOF] once
+// all the blocks were read.
+func (c *chainExportReader) next() (*types.Block, types.Receipts, error) {
+	var entry chainExportEntry
+	if err := c.stream.Decode(&entry); err == io.EOF {
+		return nil, nil, io.EOF
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", errChainExportMalformed, err)
+	}
+	block := entry.Block
+	if block == nil {
+		return nil, nil, fmt.Errorf("%w: missing block", errChainExportMalformed)
+	}
+	if c.parent != nil && (block.NumberU64() != c.parent.NumberU64()+1 || block.ParentHash() != c.parent.Hash()) {
+		return nil, nil, fmt.Errorf("%w: block %d (%s) is not the child of block %d (%s)", errChainExportMalformed,
+			block.NumberU64(), block.Hash(), c.parent.NumberU64(), c.parent.Hash())
+	}
+	c.parent = block
+
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != block.TxHash() {
+		return nil, nil, fmt.Errorf("%w: transaction root mismatch for block %d: have %s, want %s", errChainExportMalformed, block.NumberU64(), hash, block.TxHash())
+	}
+	receipts := make(types.Receipts, len(entry.Receipts))
+	for i, receipt := range entry.Receipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	if err := receipts.DeriveFields(c.config, block.Hash(), block.NumberU64(), block.Time(), block.Transactions()); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to derive receipts of block %d: %v", errChainExportMalformed, block.NumberU64(), err)
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != block.ReceiptHash() {
+		return nil, nil, fmt.Errorf("%w: receipt root mismatch for block %d: have %s, want %s", errChainExportMalformed, block.NumberU64(), hash, block.ReceiptHash())
+	}
+	return block, receipts, nil
+}
+
+// checkCompatible returns an error if the upgrades activated by [block] are not
+// the same for the exporting node and for the importing node, since the block
+// would then be processed differently.
+func (c *chainExportReader) checkCompatible(block *types.Block) error {
+	if err := c.exported.CheckCompatible(c.config, block.NumberU64(), block.Time()); err != nil {
+		return fmt.Errorf("chain config is incompatible with the exported chain at block %d: %w", block.NumberU64(), err)
+	}
+	return nil
+}
+
+func (c *chainExportReader) close() error {
+	return c.gz.Close()
+}
+
+// ImportChain replays the blocks read from [r] in the format written by
+// [ExportChain] on top of the last accepted block: the blocks are inserted and
+// accepted in order, as if they were decided by consensus. The exported blocks
+// up to the last accepted block are skipped if they are already accepted.
+//
+// The chain config of this node must activate the same upgrades as the one of
+// the exporting node up to the last imported block, but may activate new
+// upgrades afterwards, so that they can be tested on top of an existing
+// history.
+//
+// Assumes the blocks are not concurrently accepted by consensus.
+func (bc *BlockChain) ImportChain(r io.Reader) (*ChainExportStats, error) {
+	reader, err := newChainExportReader(r, bc.genesisBlock.Hash(), bc.chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+
+	var (
+		start = time.Now()
+		stats = &ChainExportStats{}
+		batch = make(types.Blocks, 0, chainImportBatchSize)
+	)
+	insert := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := reader.checkCompatible(batch[len(batch)-1]); err != nil {
+			return err
+		}
+		if n, err := bc.InsertChain(batch); err != nil {
+			return fmt.Errorf("failed to insert block %d (%s): %w", batch[n].NumberU64(), batch[n].Hash(), err)
+		}
+		for _, block := range batch {
+			if err := bc.Accept(block); err != nil {
+				return err
+			}
+			if stats.Blocks == 0 {
+				stats.First = block.NumberU64()
+			}
+			stats.Last = block.NumberU64()
+			stats.Blocks++
+			stats.Txs += uint64(len(block.Transactions()))
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		block, _, err := reader.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if lastAccepted := bc.LastAcceptedBlock(); block.NumberU64() <= lastAccepted.NumberU64() {
+			if hash := bc.GetCanonicalHash(block.NumberU64()); hash != block.Hash() {
+				return nil, fmt.Errorf("exported block %d (%s) conflicts with accepted block %s", block.NumberU64(), block.Hash(), hash)
+			}
+			continue
+		}
+		batch = append(batch, block)
+		if len(batch) == chainImportBatchSize {
+			if err := insert(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := insert(); err != nil {
+		return nil, err
+	}
+	bc.DrainAcceptorQueue()
+	log.Info("Imported chain", "first", stats.First, "last", stats.Last, "blocks", stats.Blocks, "txs", stats.Txs,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// ImportChainHistory writes the blocks and the receipts read from [r] in the
+// format written by [ExportChain] to [db] without processing them, to move the
+// history of a chain to another database. The export must start at block 1 of
+// the chain with the [genesis] hash, whose chain config of the importing node
+// is [config]. The blocks are checked against their headers, but not executed,
+// so the state of the last block must be imported separately (see
+// [ImportState]). Returns the last imported block.
+func ImportChainHistory(db ethdb.Database, r io.Reader, genesis common.Hash, config *params.ChainConfig) (*types.Block, *ChainExportStats, error) {
+	reader, err := newChainExportReader(r, genesis, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.close()
+
+	var (
+		start = time.Now()
+		stats = &ChainExportStats{}
+		batch = db.NewBatch()
+		last  *types.Block
+	)
+	for {
+		block, receipts, err := reader.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if last == nil {
+			if block.NumberU64() == 0 {
+				continue // The genesis block is already written
+			}
+			if block.NumberU64() != 1 || block.ParentHash() != genesis {
+				return nil, nil, fmt.Errorf("chain history must start at block 1, found block %d", block.NumberU64())
+			}
+			stats.First = block.NumberU64()
+		}
+		rawdb.WriteBlock(batch, block)
+		rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+		rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+		rawdb.WriteTxLookupEntriesByBlock(batch, block)
+		last = block
+		stats.Last = block.NumberU64()
+		stats.Blocks++
+		stats.Txs += uint64(len(block.Transactions()))
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := reader.checkCompatible(last); err != nil {
+				return nil, nil, err
+			}
+			if err := batch.Write(); err != nil {
+				return nil, nil, err
+			}
+			batch.Reset()
+		}
+	}
+	if last == nil {
+		return nil, nil, fmt.Errorf("%w: no blocks after the genesis", errChainExportMalformed)
+	}
+	if err := reader.checkCompatible(last); err != nil {
+		return nil, nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, nil, err
+	}
+	log.Info("Imported chain history", "first", stats.First, "last", stats.Last, "txs", stats.Txs,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return last, stats, nil
+}
@@ -0,0 +1,194 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+// newChainExportTest returns a chain of 12 accepted blocks, each with a transfer and a call emitting a log.
+func newChainExportTest(t *testing.T) (*Genesis, *BlockChain, []*types.Block) {
+	var (
+		key1, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1    = crypto.PubkeyToAddress(key1.PublicKey)
+		contract = common.Address{0xcc}
+		genDB    = rawdb.NewMemoryDatabase()
+		chainDB  = rawdb.NewMemoryDatabase()
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int), FeeConfig: params.DefaultFeeConfig},
+		Alloc: GenesisAlloc{
+			addr1: {Balance: big.NewInt(1000000)},
+			// PUSH1 0 PUSH1 0 LOG0
+			contract: {Code: []byte{0x60, 0x00, 0x60, 0x00, 0xa0}},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, DefaultCacheConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	t.Cleanup(blockchain.Stop)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 12, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(gen.TxNonce(addr1), contract, nil, 30000, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	return gspec, blockchain, chain
+}
+
+func TestExportImportChain(t *testing.T) {
+	gspec, blockchain, chain := newChainExportTest(t)
+	genesis := blockchain.Genesis()
+
+	path := filepath.Join(t.TempDir(), "chain.rlp.gz")
+	stats, err := blockchain.ExportChainToFile(path, 0, 12)
+	require.NoError(t, err)
+	require.EqualValues(t, 13, stats.Blocks)
+	require.EqualValues(t, 24, stats.Txs)
+	_, err = blockchain.ExportChain(io.Discard, 0, 13)
+	require.Error(t, err)
+	exported, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// The blocks are replayed on top of the last accepted block of a new node
+	importDB := rawdb.NewMemoryDatabase()
+	_ = gspec.MustCommit(importDB)
+	imported, err := createBlockChain(importDB, DefaultCacheConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer imported.Stop()
+	stats, err = imported.ImportChain(bytes.NewReader(exported))
+	require.NoError(t, err)
+	require.Equal(t, &ChainExportStats{First: 1, Last: 12, Blocks: 12, Txs: 24}, stats)
+	require.Equal(t, chain[11].Hash(), imported.LastAcceptedBlock().Hash())
+	receipts := imported.GetReceiptsByHash(chain[11].Hash())
+	require.Len(t, receipts, 2)
+	require.Len(t, receipts[1].Logs, 1)
+
+	// Importing the same blocks again is a no-op
+	stats, err = imported.ImportChain(bytes.NewReader(exported))
+	require.NoError(t, err)
+	require.Zero(t, stats.Blocks)
+
+	// The export cannot be imported into another chain
+	otherDB := rawdb.NewMemoryDatabase()
+	other := &Genesis{Config: gspec.Config, Alloc: GenesisAlloc{common.Address{0x02}: {Balance: big.NewInt(1)}}}
+	_ = other.MustCommit(otherDB)
+	otherChain, err := createBlockChain(otherDB, DefaultCacheConfig, other.Config, common.Hash{})
+	require.NoError(t, err)
+	defer otherChain.Stop()
+	_, err = otherChain.ImportChain(bytes.NewReader(exported))
+	require.ErrorContains(t, err, "belongs to the chain with genesis")
+
+	// The history is written without processing the blocks, and continued from the imported state
+	historyDB := rawdb.NewMemoryDatabase()
+	_ = gspec.MustCommit(historyDB)
+	last, stats, err := ImportChainHistory(historyDB, bytes.NewReader(exported), genesis.Hash(), gspec.Config)
+	require.NoError(t, err)
+	require.Equal(t, chain[11].Hash(), last.Hash())
+	require.EqualValues(t, 12, stats.Blocks)
+	require.Equal(t, chain[4].Hash(), rawdb.ReadCanonicalHash(historyDB, 5))
+	require.Len(t, rawdb.ReadReceipts(historyDB, chain[4].Hash(), 5, gspec.Config), 2)
+	require.NotNil(t, rawdb.ReadTxLookupEntry(historyDB, chain[4].Transactions()[0].Hash()))
+
+	var state bytes.Buffer
+	_, err = blockchain.ExportState(&state, 12)
+	require.NoError(t, err)
+	block, _, err := ImportState(historyDB, &state, genesis.Hash())
+	require.NoError(t, err)
+	migrated, err := createBlockChain(historyDB, DefaultCacheConfig, gspec.Config, block.Hash())
+	require.NoError(t, err)
+	defer migrated.Stop()
+	require.Equal(t, chain[11].Hash(), migrated.LastAcceptedBlock().Hash())
+	require.Len(t, migrated.GetReceiptsByHash(chain[0].Hash())[1].Logs, 1)
+}
+
+func TestImportChainChecksUpgrades(t *testing.T) {
+	gspec, blockchain, _ := newChainExportTest(t)
+
+	var exported bytes.Buffer
+	_, err := blockchain.ExportChain(&exported, 1, 12)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		activation *big.Int
+		err        string
+	}{
+		// The exported blocks have timestamps from 10 to 120
+		"upgrade during the exported blocks": {activation: big.NewInt(50), err: "incompatible with the exported chain"},
+		"upgrade after the exported blocks":  {activation: big.NewInt(1000)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := *gspec.Config
+			config.UpgradeConfig.PrecompileUpgrades = []params.PrecompileUpgrade{
+				params.NewPrecompileUpgrade(precompile.NewContractNativeMinterConfig(test.activation, nil, nil, nil)),
+			}
+			importDB := rawdb.NewMemoryDatabase()
+			_ = gspec.MustCommit(importDB)
+			imported, err := createBlockChain(importDB, DefaultCacheConfig, &config, common.Hash{})
+			require.NoError(t, err)
+			defer imported.Stop()
+
+			_, err = imported.ImportChain(bytes.NewReader(exported.Bytes()))
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				require.Zero(t, imported.LastAcceptedBlock().NumberU64())
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, 12, imported.LastAcceptedBlock().NumberU64())
+		})
+	}
+}
+
+func TestImportChainRejectsTamperedReceipts(t *testing.T) {
+	gspec, blockchain, chain := newChainExportTest(t)
+	genesis := blockchain.Genesis()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	config, err := gspec.Config.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, rlp.Encode(gz, &chainExportHeader{Version: chainExportVersion, Genesis: genesis.Hash(), Config: config, UpgradeConfig: []byte("{}")}))
+	receipts := rawdb.ReadRawReceipts(blockchain.db, chain[0].Hash(), 1)
+	receipts[1].Logs = nil
+	require.NoError(t, rlp.Encode(gz, &chainExportEntry{
+		Block:    chain[0],
+		Receipts: []*types.ReceiptForStorage{(*types.ReceiptForStorage)(receipts[0]), (*types.ReceiptForStorage)(receipts[1])},
+	}))
+	require.NoError(t, gz.Close())
+
+	db := rawdb.NewMemoryDatabase()
+	_ = gspec.MustCommit(db)
+	_, _, err = ImportChainHistory(db, &buf, genesis.Hash(), gspec.Config)
+	require.ErrorIs(t, err, errChainExportMalformed)
+	require.ErrorContains(t, err, "receipt root mismatch")
+}
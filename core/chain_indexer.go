@@ -471,6 +471,44 @@ func (c *ChainIndexer) AddChildIndexer(indexer *ChainIndexer) {
 	}
 }
 
+// Rebuild rolls the indexer back to [section], so that all the following sections
+// are processed again in the background. Sections whose chain data is not
+// available (e.g. before a checkpoint) are kept. It returns the first section
+// being rebuilt.
+func (c *ChainIndexer) Rebuild(section uint64) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if section < c.checkpointSections {
+		section = c.checkpointSections
+	}
+	for section < c.storedSections {
+		// Processing a section requires its first block and the head of the previous section
+		if rawdb.ReadCanonicalHash(c.chainDb, section*c.sectionSize) != (common.Hash{}) &&
+			(section == 0 || c.SectionHead(section-1) != (common.Hash{})) {
+			break
+		}
+		section++
+	}
+	if section >= c.storedSections {
+		return section
+	}
+	c.log.Info("Rebuilding chain index", "section", section, "sections", c.storedSections)
+	c.setValidSections(section)
+
+	if head := section * c.sectionSize; head < c.cascadedHead {
+		c.cascadedHead = head
+		for _, child := range c.children {
+			child.newHead(c.cascadedHead, true)
+		}
+	}
+	select {
+	case c.update <- struct{}{}:
+	default:
+	}
+	return section
+}
+
 // Prune deletes all chain data older than given threshold.
 func (c *ChainIndexer) Prune(threshold uint64) error {
 	return c.backend.Prune(threshold)
@@ -254,3 +254,62 @@ func (b *testChainIndexBackend) Commit() error {
 func (b *testChainIndexBackend) Prune(threshold uint64) error {
 	return nil
 }
+
+// Tests that rebuilding rolls the indexer back and processes the following
+// sections again, skipping the sections whose chain data is not available.
+func TestChainIndexerRebuild(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	defer db.Close()
+
+	backend := &testChainIndexBackend{t: t, processCh: make(chan uint64)}
+	backend.indexer = NewChainIndexer(db, rawdb.NewTable(db, "i"), backend, 10, 0, 0, "indexer")
+	defer backend.indexer.Close()
+
+	for number := uint64(0); number < 50; number++ {
+		header := &types.Header{Number: new(big.Int).SetUint64(number)}
+		if number > 0 {
+			header.ParentHash = rawdb.ReadCanonicalHash(db, number-1)
+		}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), number)
+	}
+	// expectProcessed expects the blocks in [from, 50) to be processed in order
+	expectProcessed := func(from uint64) {
+		for expected := from; expected < 50; expected++ {
+			select {
+			case <-time.After(10 * time.Second):
+				t.Fatalf("Expected processed block #%d, got nothing", expected)
+			case processed := <-backend.processCh:
+				if processed != expected {
+					t.Fatalf("Expected processed block #%d, got #%d", expected, processed)
+				}
+			}
+		}
+		backend.stored = 5
+		backend.assertSections()
+	}
+	backend.indexer.newHead(49, false)
+	expectProcessed(0)
+
+	if section := backend.indexer.Rebuild(2); section != 2 {
+		t.Fatalf("Rebuilt section mismatch: have %d, want %d", section, 2)
+	}
+	expectProcessed(20)
+
+	// The first section cannot be processed without its first block
+	rawdb.DeleteCanonicalHash(db, 0)
+	if section := backend.indexer.Rebuild(0); section != 1 {
+		t.Fatalf("Rebuilt section mismatch: have %d, want %d", section, 1)
+	}
+	expectProcessed(10)
+
+	// Sections past the stored ones are not rebuilt
+	if section := backend.indexer.Rebuild(7); section != 7 {
+		t.Fatalf("Rebuilt section mismatch: have %d, want %d", section, 7)
+	}
+	select {
+	case processed := <-backend.processCh:
+		t.Fatalf("Unexpected processed block #%d", processed)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
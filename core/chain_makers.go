@@ -299,6 +299,9 @@ func makeHeader(chain consensus.ChainReader, config *params.ChainConfig, parent
 		if err != nil {
 			panic(err)
 		}
+		if chain.Config().IsValidatorInfo(header.Number, timestamp) {
+			header.Extra = dummy.AppendPChainHeight(header.Extra, dummy.CalcPChainHeight(chain.Config(), parent.Header(), nil))
+		}
 	} else {
 		header.GasLimit = CalcGasLimit(parent.GasUsed(), parent.GasLimit(), parent.GasLimit(), parent.GasLimit())
 	}
@@ -320,9 +323,9 @@ func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header
 func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
 func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
 func (cr *fakeChainReader) GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig, *big.Int, error) {
-	return cr.config.FeeConfig, nil, nil
+	return cr.config.FeeConfigAt(new(big.Int).SetUint64(parent.Time)), nil, nil
 }
 
 func (cr *fakeChainReader) GetCoinbaseAt(parent *types.Header) (common.Address, bool, error) {
-	return constants.BlackholeAddr, cr.config.AllowFeeRecipients, nil
+	return constants.BlackholeAddr, cr.config.AllowFeeRecipientsAt(new(big.Int).SetUint64(parent.Time)), nil
 }
@@ -72,6 +72,10 @@ var (
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
 
+	// ErrInsufficientGasTokenFunds is returned if gas is paid in the gas token and the
+	// cost of the gas of a transaction is higher than the gas token balance of the user's account.
+	ErrInsufficientGasTokenFunds = errors.New("insufficient gas token funds for gas * price")
+
 	// ErrGasUintOverflow is returned when calculating gas usage.
 	ErrGasUintOverflow = errors.New("gas uint64 overflow")
 
@@ -101,4 +105,21 @@ var (
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderNoEOA = errors.New("sender not an eoa")
+
+	// ErrBlobFeeCapTooLow is returned if the transaction blob fee cap is less than the
+	// base fee of the block, at which blob gas is priced.
+	ErrBlobFeeCapTooLow = errors.New("max fee per blob gas less than block base fee")
+
+	// ErrEmptyAuthList is returned if a set code transaction has an empty auth list.
+	ErrEmptyAuthList = errors.New("EIP-7702 transaction with empty auth list")
+)
+
+// EIP-7702 authorization errors. Invalid authorizations are skipped without
+// invalidating the transaction carrying them.
+var (
+	ErrAuthorizationWrongChainID       = errors.New("EIP-7702 authorization chain ID mismatch")
+	ErrAuthorizationNonceOverflow      = errors.New("EIP-7702 authorization nonce > 64 bit")
+	ErrAuthorizationInvalidSignature   = errors.New("EIP-7702 authorization has invalid signature")
+	ErrAuthorizationDestinationHasCode = errors.New("EIP-7702 authorization destination is a contract")
+	ErrAuthorizationNonceMismatch      = errors.New("EIP-7702 authorization nonce does not match current account nonce")
 )
@@ -41,6 +41,12 @@ type NewTxPoolHeadEvent struct{ Block *types.Block }
 // NewTxPoolReorgEvent is posted when the pool head is updated.
 type NewTxPoolReorgEvent struct{ Head *types.Header }
 
+// PendingBlockEvent is posted when the miner builds a block, before it is issued to consensus.
+type PendingBlockEvent struct {
+	Block    *types.Block
+	Receipts []*types.Receipt
+}
+
 // RemovedLogsEvent is posted when a reorg happens
 type RemovedLogsEvent struct{ Logs []*types.Log }
 
@@ -55,3 +61,14 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ChainReorgEvent is posted when the preferred chain changes to a block that is not a
+// descendant of the previous preference, dropping [Depth] blocks above [CommonBlock].
+// [Dropped] holds the hashes of the dropped blocks, from the old head down.
+type ChainReorgEvent struct {
+	OldHead     *types.Block
+	NewHead     *types.Block
+	CommonBlock *types.Block
+	Depth       int
+	Dropped     []common.Hash
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethdb"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -52,7 +53,12 @@ import (
 //go:generate go run github.com/fjl/gencodec -type Genesis -field-override genesisSpecMarshaling -out gen_genesis.go
 //go:generate go run github.com/fjl/gencodec -type GenesisAccount -field-override genesisAccountMarshaling -out gen_genesis_account.go
 
-var errGenesisNoConfig = errors.New("genesis has no chain configuration")
+var (
+	errGenesisNoConfig          = errors.New("genesis has no chain configuration")
+	errGenesisPrecompileHasCode = errors.New("genesis allocates code at a stateful precompile address")
+	errGenesisAirdropMismatch   = errors.New("airdrop data does not match airdrop hash")
+	errStateSchemeMismatch      = errors.New("state scheme mismatch")
+)
 
 type Airdrop struct {
 	// Address strings are hex-formatted common.Address
@@ -160,6 +166,81 @@ func (e *GenesisMismatchError) Error() string {
 	return fmt.Sprintf("database contains incompatible genesis (have %x, new %x)", e.Stored, e.New)
 }
 
+// Verify checks that [g] is a valid genesis to initialize a chain with: it must have a valid
+// chain config whose fee config gas limit matches the gas limit of the header, must not allocate
+// code at the address of a stateful precompile, and its airdrop data must match its airdrop hash.
+func (g *Genesis) Verify() error {
+	if g.Config == nil {
+		return errGenesisNoConfig
+	}
+	// Make sure genesis gas limit is consistent in SubnetEVM fork
+	gasLimitConfig := g.Config.FeeConfig.GasLimit.Uint64()
+	if gasLimitConfig != g.GasLimit {
+		return fmt.Errorf("gas limit in fee config (%d) does not match gas limit in header (%d)", gasLimitConfig, g.GasLimit)
+	}
+
+	// Verify config
+	if err := g.Config.Verify(); err != nil {
+		return err
+	}
+	if err := g.verifyPrecompileAddresses(); err != nil {
+		return err
+	}
+	return g.verifyAirdrop()
+}
+
+// verifyAirdrop checks that the airdrop data of [g] matches its airdrop hash, if it has one,
+// rather than failing when the genesis block is created.
+func (g *Genesis) verifyAirdrop() error {
+	if g.AirdropHash == (common.Hash{}) {
+		return nil
+	}
+	if hash := common.BytesToHash(crypto.Keccak256(g.AirdropData)); hash != g.AirdropHash {
+		return fmt.Errorf("%w: expected %s but got %s", errGenesisAirdropMismatch, g.AirdropHash, hash)
+	}
+	var airdrop []*Airdrop
+	if err := json.Unmarshal(g.AirdropData, &airdrop); err != nil {
+		return fmt.Errorf("invalid airdrop data: %w", err)
+	}
+	return nil
+}
+
+// verifyPrecompileAddresses checks that the genesis does not allocate code at the address of a registered
+// stateful precompile, since the precompile would silently shadow the code once it is enabled.
+func (g *Genesis) verifyPrecompileAddresses() error {
+	for _, module := range precompile.RegisteredModules() {
+		if account, ok := g.Alloc[module.Address]; ok && len(account.Code) != 0 {
+			return fmt.Errorf("%w: %s is reserved for %s", errGenesisPrecompileHasCode, module.Address, module.ConfigKey)
+		}
+	}
+	return nil
+}
+
+// SetupStateScheme records [scheme] (the hash scheme if empty) as the scheme
+// used to store the state trie nodes of [db], which must be called before the
+// genesis state is written. A database with a genesis block but no recorded
+// scheme uses the hash scheme. The scheme cannot be changed afterwards, as
+// trie nodes written with one scheme cannot be read with the other.
+func SetupStateScheme(db ethdb.Database, scheme string) error {
+	if scheme == "" {
+		scheme = rawdb.HashScheme
+	}
+	if scheme != rawdb.HashScheme && scheme != rawdb.PathScheme {
+		return fmt.Errorf("unknown state scheme %q", scheme)
+	}
+	stored := rawdb.ReadStateScheme(db)
+	if stored == "" && rawdb.ReadCanonicalHash(db, 0) != (common.Hash{}) {
+		stored = rawdb.HashScheme
+	}
+	if stored != "" && stored != scheme {
+		return fmt.Errorf("%w: database uses the %s scheme, configured to use the %s scheme", errStateSchemeMismatch, stored, scheme)
+	}
+	if rawdb.ReadStateScheme(db) == "" {
+		rawdb.WriteStateScheme(db, scheme)
+	}
+	return nil
+}
+
 // SetupGenesisBlock writes or updates the genesis block in db.
 // The block that will be used is:
 //
@@ -181,17 +262,7 @@ func SetupGenesisBlock(
 	if genesis == nil {
 		return nil, ErrNoGenesis
 	}
-	if genesis.Config == nil {
-		return nil, errGenesisNoConfig
-	}
-	// Make sure genesis gas limit is consistent in SubnetEVM fork
-	gasLimitConfig := genesis.Config.FeeConfig.GasLimit.Uint64()
-	if gasLimitConfig != genesis.GasLimit {
-		return nil, fmt.Errorf("gas limit in fee config (%d) does not match gas limit in header (%d)", gasLimitConfig, genesis.GasLimit)
-	}
-
-	// Verify config
-	if err := genesis.Config.Verify(); err != nil {
+	if err := genesis.Verify(); err != nil {
 		return nil, err
 	}
 	// Just commit the new block if there is no stored genesis block.
@@ -41,6 +41,7 @@ import (
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -190,7 +191,7 @@ func TestStatefulPrecompilesConfigure(t *testing.T) {
 		"allow list enabled in genesis": {
 			getConfig: func() *params.ChainConfig {
 				config := *params.TestChainConfig
-				config.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr}, nil)
+				config.PrecompileUpgrade.SetConfig(precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr}, nil))
 				return &config
 			},
 			assertState: func(t *testing.T, sdb *state.StateDB) {
@@ -267,10 +268,10 @@ func TestPrecompileActivationAfterHeaderBlock(t *testing.T) {
 	activatedGenesis := customg
 	contractDeployerConfig := precompile.NewContractDeployerAllowListConfig(big.NewInt(51), nil, nil)
 	activatedGenesis.Config.UpgradeConfig.PrecompileUpgrades = []params.PrecompileUpgrade{
-		{
+		params.NewPrecompileUpgrade(
 			// Enable ContractDeployerAllowList at timestamp 50
-			ContractDeployerAllowListConfig: contractDeployerConfig,
-		},
+			contractDeployerConfig,
+		),
 	}
 
 	// assert block is after the activation block
@@ -285,3 +286,46 @@ func TestPrecompileActivationAfterHeaderBlock(t *testing.T) {
 		t.Errorf("returned %v\nwant     %v", config, activatedGenesis.Config)
 	}
 }
+
+func TestSetupGenesisPrecompileAddressCollision(t *testing.T) {
+	config := *params.TestChainConfig
+	genesis := &Genesis{
+		Config: &config,
+		Alloc: GenesisAlloc{
+			precompile.TxAllowListAddress: {Balance: common.Big0, Code: []byte{0x1}},
+		},
+		GasLimit: config.FeeConfig.GasLimit.Uint64(),
+	}
+	db := rawdb.NewMemoryDatabase()
+	_, err := SetupGenesisBlock(db, genesis, common.Hash{}, false)
+	require.ErrorIs(t, err, errGenesisPrecompileHasCode)
+	require.ErrorContains(t, err, precompile.TxAllowListConfigKey)
+
+	// balances at precompile addresses do not collide with the precompile
+	genesis.Alloc = GenesisAlloc{
+		precompile.TxAllowListAddress: {Balance: common.Big1},
+	}
+	_, err = SetupGenesisBlock(db, genesis, common.Hash{}, false)
+	require.NoError(t, err)
+}
+
+func TestGenesisVerifyAirdrop(t *testing.T) {
+	config := *params.TestChainConfig
+	airdropData := []byte(`[{"address": "0x0100000000000000000000000000000000000000"}]`)
+	genesis := &Genesis{
+		Config:        &config,
+		GasLimit:      config.FeeConfig.GasLimit.Uint64(),
+		AirdropHash:   crypto.Keccak256Hash(airdropData),
+		AirdropAmount: common.Big1,
+		AirdropData:   airdropData,
+	}
+	require.NoError(t, genesis.Verify())
+
+	// the airdrop data is provided separately, so it may not match the hash
+	genesis.AirdropData = []byte(`[]`)
+	require.ErrorIs(t, genesis.Verify(), errGenesisAirdropMismatch)
+
+	genesis.AirdropData = []byte(`{}`)
+	genesis.AirdropHash = crypto.Keccak256Hash(genesis.AirdropData)
+	require.ErrorContains(t, genesis.Verify(), "invalid airdrop data")
+}
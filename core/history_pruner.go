@@ -0,0 +1,79 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"errors"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// startHistoryPruner starts pruning the bodies and receipts of the blocks older
+// than the last [HistoryRetention] accepted blocks in the background. The
+// headers of all blocks are kept. The blocks that fell out of the retention
+// window while the node was not running (or before the option was enabled) are
+// pruned on startup.
+func (bc *BlockChain) startHistoryPruner() error {
+	if bc.cacheConfig.HistoryRetention == 0 {
+		return nil
+	}
+	// Blocks in the ancient store cannot be deleted, and it expects the bodies and
+	// receipts of the blocks moved into it to be available.
+	if bc.hasAncientStore() {
+		return errors.New("cannot prune the block history with an ancient store")
+	}
+	bc.historyPruneWg.Add(1)
+	go func() {
+		defer bc.historyPruneWg.Done()
+		bc.historyPruneLoop()
+	}()
+	bc.notifyHistoryPruner()
+	return nil
+}
+
+// notifyHistoryPruner notifies the history pruner without blocking, as a
+// pending notification covers all the later ones.
+func (bc *BlockChain) notifyHistoryPruner() {
+	select {
+	case bc.historyPruneUpdate <- struct{}{}:
+	default:
+	}
+}
+
+func (bc *BlockChain) historyPruneLoop() {
+	for {
+		select {
+		case <-bc.quit:
+			return
+		case <-bc.historyPruneUpdate:
+		}
+		if err := bc.pruneHistory(); err != nil {
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+			log.Error("Failed to prune block history", "err", err)
+		}
+	}
+}
+
+// pruneHistory moves the history tail to the oldest block within the
+// [HistoryRetention] of the last accepted block. The genesis block is never
+// pruned.
+func (bc *BlockChain) pruneHistory() error {
+	var (
+		head      = bc.LastAcceptedBlock().NumberU64()
+		retention = bc.cacheConfig.HistoryRetention
+		tail      = uint64(1)
+	)
+	if stored := rawdb.ReadHistoryTail(bc.db); stored != nil {
+		tail = *stored
+	}
+	if head+1 < retention+tail {
+		return nil
+	}
+	return rawdb.PruneHistory(bc.db, tail, head+1-retention, bc.quit)
+}
@@ -0,0 +1,100 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryPruning(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		// Pruning is disabled so that no block is reprocessed on restart
+		config = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               false,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			HistoryRetention:      3,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// checkHistory checks the bodies and receipts of the blocks from [tail] on are
+	// kept and the ones of the previous blocks are pruned, once the pruner has
+	// caught up. The headers of all blocks are kept.
+	checkHistory := func(tail uint64) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			stored := rawdb.ReadHistoryTail(chainDB)
+			return stored != nil && *stored == tail
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NotNil(t, rawdb.ReadBody(chainDB, genesis.Hash(), 0))
+		for _, block := range chain {
+			number := block.NumberU64()
+			require.NotNil(t, rawdb.ReadHeader(chainDB, block.Hash(), number), "block %d", number)
+			body := rawdb.ReadBody(chainDB, block.Hash(), number)
+			receipts := rawdb.ReadRawReceipts(chainDB, block.Hash(), number)
+			lookup := rawdb.ReadTxLookupEntry(chainDB, block.Transactions()[0].Hash())
+			if number < tail {
+				require.Nil(t, body, "block %d", number)
+				require.Nil(t, receipts, "block %d", number)
+				require.Nil(t, lookup, "block %d", number)
+			} else {
+				require.NotNil(t, body, "block %d", number)
+				require.Len(t, receipts, 1, "block %d", number)
+				require.NotNil(t, lookup, "block %d", number)
+			}
+		}
+	}
+	checkHistory(8)
+	blockchain.Stop()
+
+	// The history is pruned on startup when the retention is lowered
+	config.HistoryRetention = 2
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, chain[9].Hash())
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	checkHistory(9)
+}
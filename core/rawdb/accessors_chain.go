@@ -201,22 +201,40 @@ func WriteHeadBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// isCanonicalAncient returns true if the canonical block [hash] at [number] has
+// been moved into the ancient store.
+func isCanonicalAncient(db ethdb.AncientReader, hash common.Hash, number uint64) bool {
+	data, _ := db.Ancient(freezerHashTable, number)
+	return len(data) > 0 && common.BytesToHash(data) == hash
+}
+
+// readAncient retrieves the [kind] data of the block [hash] at [number] from the
+// ancient store, returning nil if the block is not a frozen canonical block.
+func readAncient(db ethdb.AncientReader, kind string, hash common.Hash, number uint64) []byte {
+	if !isCanonicalAncient(db, hash, number) {
+		return nil
+	}
+	data, _ := db.Ancient(kind, number)
+	return data
+}
+
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in leveldb, where all recent blocks are kept.
 	data, _ := db.Get(headerKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
-	return nil // Can't find the data anywhere.
+	// Then try to look up the data in the ancient store.
+	return readAncient(db, freezerHeaderTable, hash, number)
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(headerKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isCanonicalAncient(db, hash, number)
 }
 
 // ReadHeader retrieves the block header corresponding to the hash.
@@ -272,12 +290,13 @@ func deleteHeaderWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in leveldb, where all recent blocks are kept.
 	data, _ := db.Get(blockBodyKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
-	return nil // Can't find the data anywhere.
+	// Then try to look up the data in the ancient store.
+	return readAncient(db, freezerBodiesTable, hash, number)
 }
 
 // ReadCanonicalBodyRLP retrieves the block body (transactions and uncles) for the canonical
@@ -288,7 +307,9 @@ func ReadCanonicalBodyRLP(db ethdb.Reader, number uint64) rlp.RawValue {
 	if len(data) > 0 {
 		return data
 	}
-	return nil
+	// Frozen blocks are all canonical, so there is no need to check the hash.
+	data, _ = db.Ancient(freezerBodiesTable, number)
+	return data
 }
 
 // WriteBodyRLP stores an RLP encoded block body into the database.
@@ -300,10 +321,10 @@ func WriteBodyRLP(db ethdb.KeyValueWriter, hash common.Hash, number uint64, rlp
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(blockBodyKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return isCanonicalAncient(db, hash, number)
 }
 
 // ReadBody retrieves the block body corresponding to the hash.
@@ -339,20 +360,21 @@ func DeleteBody(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // HasReceipts verifies the existence of all the transaction receipts belonging
 // to a block.
 func HasReceipts(db ethdb.Reader, hash common.Hash, number uint64) bool {
-	if has, err := db.Has(blockReceiptsKey(number, hash)); !has || err != nil {
-		return false
+	if has, err := db.Has(blockReceiptsKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return len(readAncient(db, freezerReceiptTable, hash, number)) > 0
 }
 
 // ReadReceiptsRLP retrieves all the transaction receipts belonging to a block in RLP encoding.
 func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue {
-	// Then try to look up the data in leveldb.
+	// First try to look up the data in leveldb, where all recent blocks are kept.
 	data, _ := db.Get(blockReceiptsKey(number, hash))
 	if len(data) > 0 {
 		return data
 	}
-	return nil // Can't find the data anywhere.
+	// Then try to look up the data in the ancient store.
+	return readAncient(db, freezerReceiptTable, hash, number)
 }
 
 // ReadRawReceipts retrieves all the transaction receipts belonging to a block.
@@ -430,6 +452,38 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadStateDiff retrieves the state diff of a block.
+func ReadStateDiff(db ethdb.KeyValueReader, hash common.Hash, number uint64) *types.StateDiff {
+	data, _ := db.Get(stateDiffKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	diff := new(types.StateDiff)
+	if err := rlp.DecodeBytes(data, diff); err != nil {
+		log.Error("Invalid state diff RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return diff
+}
+
+// WriteStateDiff stores the state diff of a block.
+func WriteStateDiff(db ethdb.KeyValueWriter, hash common.Hash, number uint64, diff *types.StateDiff) {
+	bytes, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		log.Crit("Failed to encode state diff", "err", err)
+	}
+	if err := db.Put(stateDiffKey(number, hash), bytes); err != nil {
+		log.Crit("Failed to store state diff", "err", err)
+	}
+}
+
+// DeleteStateDiff removes the state diff of a block.
+func DeleteStateDiff(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(stateDiffKey(number, hash)); err != nil {
+		log.Crit("Failed to delete state diff", "err", err)
+	}
+}
+
 // storedReceiptRLP is the storage encoding of a receipt.
 // Re-definition in core/types/receipt.go.
 type storedReceiptRLP struct {
@@ -549,6 +603,25 @@ func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 	DeleteBody(db, hash, number)
 }
 
+// ReadHistoryTail retrieves the number of the oldest block whose body and
+// receipts have not been pruned, or nil if no block has been pruned.
+func ReadHistoryTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(historyTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteHistoryTail stores the number of the oldest block whose body and
+// receipts have not been pruned.
+func WriteHistoryTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(historyTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the history tail", "err", err)
+	}
+}
+
 // FindCommonAncestor returns the last common ancestor of two block headers
 func FindCommonAncestor(db ethdb.Reader, a, b *types.Header) *types.Header {
 	for bn := b.Number.Uint64(); a.Number.Uint64() > bn; {
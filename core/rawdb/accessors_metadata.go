@@ -102,7 +102,7 @@ func WriteChainConfig(db ethdb.KeyValueWriter, hash common.Hash, cfg *params.Cha
 	}
 
 	// Write the upgrade config for this chain config
-	data, err = json.Marshal(cfg.UpgradeConfig)
+	data, err = json.Marshal(cfg.Upgrades())
 	if err != nil {
 		log.Crit("Failed to JSON encode upgrade config", "err", err)
 	}
@@ -276,6 +276,18 @@ func DeletePruningDisabled(db ethdb.KeyValueStore) error {
 	return db.Delete(pruningDisabledKey)
 }
 
+// WriteAncientStoreUsed writes a marker to track whether chain data has ever
+// been moved out of the key-value store into the ancient store.
+func WriteAncientStoreUsed(db ethdb.KeyValueStore) error {
+	return db.Put(ancientStoreKey, nil)
+}
+
+// HasAncientStoreUsed returns true if there is a marker present indicating that
+// chain data has been moved into the ancient store at some point.
+func HasAncientStoreUsed(db ethdb.KeyValueStore) (bool, error) {
+	return db.Has(ancientStoreKey)
+}
+
 // WriteAcceptorTip writes [hash] as the last accepted block that has been fully processed.
 func WriteAcceptorTip(db ethdb.KeyValueWriter, hash common.Hash) error {
 	return db.Put(acceptorTipKey, hash[:])
@@ -0,0 +1,118 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The schemes used to store the state trie nodes on disk.
+const (
+	// HashScheme stores each trie node keyed by its hash, so every version of
+	// the state that has been committed is kept until it is pruned.
+	HashScheme = "hash"
+
+	// PathScheme stores each trie node keyed by its owner and path in the trie,
+	// so only a single version of the state is kept on disk and older versions
+	// are overwritten in place.
+	PathScheme = "path"
+)
+
+// ReadStateScheme retrieves the scheme used to store the state trie nodes of
+// the database, or the empty string if it has not been recorded.
+func ReadStateScheme(db ethdb.KeyValueReader) string {
+	data, _ := db.Get(stateSchemeKey)
+	return string(data)
+}
+
+// WriteStateScheme records the scheme used to store the state trie nodes of
+// the database.
+func WriteStateScheme(db ethdb.KeyValueWriter, scheme string) {
+	if err := db.Put(stateSchemeKey, []byte(scheme)); err != nil {
+		log.Crit("Failed to store the state scheme", "err", err)
+	}
+}
+
+// ReadAccountTrieNode retrieves the account trie node stored at the provided
+// path with the path scheme.
+func ReadAccountTrieNode(db ethdb.KeyValueReader, path []byte) []byte {
+	data, _ := db.Get(accountTrieNodeKey(path))
+	return data
+}
+
+// WriteAccountTrieNode writes the account trie node at the provided path with
+// the path scheme.
+func WriteAccountTrieNode(db ethdb.KeyValueWriter, path []byte, node []byte) {
+	if err := db.Put(accountTrieNodeKey(path), node); err != nil {
+		log.Crit("Failed to store account trie node", "err", err)
+	}
+}
+
+// DeleteAccountTrieNode deletes the account trie node at the provided path
+// with the path scheme.
+func DeleteAccountTrieNode(db ethdb.KeyValueWriter, path []byte) {
+	if err := db.Delete(accountTrieNodeKey(path)); err != nil {
+		log.Crit("Failed to delete account trie node", "err", err)
+	}
+}
+
+// ReadStorageTrieNode retrieves the storage trie node of the account with the
+// provided hash stored at the provided path with the path scheme.
+func ReadStorageTrieNode(db ethdb.KeyValueReader, accountHash common.Hash, path []byte) []byte {
+	data, _ := db.Get(storageTrieNodeKey(accountHash, path))
+	return data
+}
+
+// WriteStorageTrieNode writes the storage trie node of the account with the
+// provided hash at the provided path with the path scheme.
+func WriteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte, node []byte) {
+	if err := db.Put(storageTrieNodeKey(accountHash, path), node); err != nil {
+		log.Crit("Failed to store storage trie node", "err", err)
+	}
+}
+
+// DeleteStorageTrieNode deletes the storage trie node of the account with the
+// provided hash at the provided path with the path scheme.
+func DeleteStorageTrieNode(db ethdb.KeyValueWriter, accountHash common.Hash, path []byte) {
+	if err := db.Delete(storageTrieNodeKey(accountHash, path)); err != nil {
+		log.Crit("Failed to delete storage trie node", "err", err)
+	}
+}
+
+// IsAccountTrieNode reports whether the given key is the key of an account
+// trie node stored with the path scheme.
+func IsAccountTrieNode(key []byte) bool {
+	if !bytes.HasPrefix(key, TrieNodeAccountPrefix) {
+		return false
+	}
+	return isHexPath(key[len(TrieNodeAccountPrefix):])
+}
+
+// IsStorageTrieNode reports whether the given key is the key of a storage trie
+// node stored with the path scheme.
+func IsStorageTrieNode(key []byte) bool {
+	if !bytes.HasPrefix(key, TrieNodeStoragePrefix) || len(key) < len(TrieNodeStoragePrefix)+common.HashLength {
+		return false
+	}
+	return isHexPath(key[len(TrieNodeStoragePrefix)+common.HashLength:])
+}
+
+// isHexPath reports whether [path] is a path in a trie, which is made of
+// nibbles and is never longer than a hashed key, to tell trie node keys apart
+// from other keys sharing their prefix.
+func isHexPath(path []byte) bool {
+	if len(path) > 2*common.HashLength {
+		return false
+	}
+	for _, nibble := range path {
+		if nibble > 0x0f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// freezerBatchLimit is the maximum number of blocks to move into the ancient
+// store before syncing it and deleting the blocks from the key-value store.
+const freezerBatchLimit = 30000
+
+// FreezeCanonicalBlocks moves the headers, bodies and receipts of the canonical
+// blocks up to and including [limit] out of the key-value store of [db] and into
+// its ancient store, returning the number of blocks moved. The canonical hash and
+// hash to number mappings are kept in the key-value store, while non-canonical
+// blocks at the frozen heights are deleted.
+//
+// The first call moves the contiguous range of canonical blocks ending at
+// [limit] that are held in the key-value store (from genesis, unless the node
+// was state synced), which migrates the existing chain data of a node. Later
+// calls continue from the last frozen block, until a block missing from the
+// key-value store is reached.
+//
+// Assumes the canonical blocks up to [limit] are final and that a single
+// goroutine freezes blocks at a time.
+func FreezeCanonicalBlocks(db ethdb.Database, limit uint64) (uint64, error) {
+	frozen, err := db.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	tail, err := db.Tail()
+	if err != nil {
+		return 0, err
+	}
+	if frozen == tail {
+		// The ancient store is empty, so start at the oldest block of the
+		// contiguous range of canonical blocks ending at [limit].
+		if !hasCanonicalBlock(db, limit) {
+			return 0, nil
+		}
+		frozen = limit
+		for frozen > 0 && hasCanonicalBlock(db, frozen-1) {
+			frozen--
+		}
+	}
+	if frozen > limit {
+		return 0, nil
+	}
+	if !hasCanonicalBlock(db, frozen) {
+		// The node state synced past the blocks held in the ancient store, which
+		// cannot skip over the missing blocks.
+		log.Warn("Cannot move blocks into ancient store past missing block", "number", frozen)
+		return 0, nil
+	}
+	if err := WriteAncientStoreUsed(db); err != nil {
+		return 0, err
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		first  = frozen
+		hashes = make([]common.Hash, 0, freezerBatchLimit)
+	)
+	for frozen <= limit {
+		hash := ReadCanonicalHash(db, frozen)
+		if hash == (common.Hash{}) {
+			return frozen - first, fmt.Errorf("canonical hash missing, can't freeze block %d", frozen)
+		}
+		header, _ := db.Get(headerKey(frozen, hash))
+		if len(header) == 0 {
+			return frozen - first, fmt.Errorf("block header missing, can't freeze block %d", frozen)
+		}
+		body, _ := db.Get(blockBodyKey(frozen, hash))
+		if len(body) == 0 {
+			return frozen - first, fmt.Errorf("block body missing, can't freeze block %d", frozen)
+		}
+		// Blocks fetched by state sync are stored without receipts, which are
+		// frozen as an empty blob.
+		receipts, _ := db.Get(blockReceiptsKey(frozen, hash))
+		if err := db.AppendAncient(frozen, hash[:], header, body, receipts); err != nil {
+			return frozen - first, err
+		}
+		hashes = append(hashes, hash)
+		frozen++
+
+		if len(hashes) == freezerBatchLimit || frozen > limit {
+			if err := deleteFrozenBlocks(db, frozen-uint64(len(hashes)), hashes); err != nil {
+				return frozen - first, err
+			}
+			hashes = hashes[:0]
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Moving blocks into ancient store", "number", frozen-1, "limit", limit, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	return frozen - first, nil
+}
+
+// hasCanonicalBlock returns true if the key-value store of [db] holds the
+// header of the canonical block at [number].
+func hasCanonicalBlock(db ethdb.Database, number uint64) bool {
+	hash := ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return false
+	}
+	has, err := db.Has(headerKey(number, hash))
+	return has && err == nil
+}
+
+// deleteFrozenBlocks flushes the ancient store to disk and then deletes the
+// blocks at the heights starting at [first] from the key-value store. [hashes]
+// are the hashes of the frozen canonical blocks, whose hash to number mappings
+// are kept.
+func deleteFrozenBlocks(db ethdb.Database, first uint64, hashes []common.Hash) error {
+	if err := db.Sync(); err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	for i, hash := range hashes {
+		number := first + uint64(i)
+		DeleteBlockWithoutNumber(batch, hash, number)
+
+		// Dangling blocks at a frozen height can never become canonical
+		for _, dangling := range ReadAllHashes(db, number) {
+			if dangling != hash {
+				DeleteBlock(batch, dangling, number)
+			}
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
@@ -0,0 +1,176 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errIterationInterrupted is returned when the (un)indexing of transactions or the
+// pruning of the block history is interrupted.
+var errIterationInterrupted = errors.New("chain iteration interrupted")
+
+// blockTxHashes returns the hashes of the transactions of the canonical block at
+// [number], or false if the block is not available (e.g. it was skipped by state
+// sync).
+func blockTxHashes(db ethdb.Reader, number uint64) ([]common.Hash, bool) {
+	hash := ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return nil, false
+	}
+	body := ReadBody(db, hash, number)
+	if body == nil {
+		return nil, false
+	}
+	hashes := make([]common.Hash, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		hashes[i] = tx.Hash()
+	}
+	return hashes, true
+}
+
+// IndexTransactions writes the transaction lookup entries of the canonical blocks
+// in [from, to), from the newest block to the oldest one, and moves the
+// transaction index tail down to [from]. The tail is updated with each batch
+// written, so an interrupted run can be resumed.
+func IndexTransactions(db ethdb.Database, from, to uint64, interrupt <-chan struct{}) error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		batch   = db.NewBatch()
+		indexed int
+	)
+	for number := to; number > from; {
+		number--
+		if hashes, ok := blockTxHashes(db, number); ok {
+			WriteTxLookupEntries(batch, number, hashes)
+			indexed += len(hashes)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize || number == from {
+			WriteTxIndexTail(batch, number)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			select {
+			case <-interrupt:
+				return errIterationInterrupted
+			default:
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing transactions", "blocks", to-number, "total", to-from, "txs", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if to > from {
+		log.Debug("Indexed transactions", "from", from, "to", to, "txs", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// UnindexTransactions deletes the transaction lookup entries of the canonical
+// blocks in [from, to), from the oldest block to the newest one, and moves the
+// transaction index tail up to [to]. The tail is updated with each batch
+// written, so an interrupted run can be resumed.
+func UnindexTransactions(db ethdb.Database, from, to uint64, interrupt <-chan struct{}) error {
+	var (
+		start     = time.Now()
+		logged    = time.Now()
+		batch     = db.NewBatch()
+		unindexed int
+	)
+	for number := from; number < to; number++ {
+		if hashes, ok := blockTxHashes(db, number); ok {
+			DeleteTxLookupEntries(batch, hashes)
+			unindexed += len(hashes)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize || number == to-1 {
+			WriteTxIndexTail(batch, number+1)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			select {
+			case <-interrupt:
+				return errIterationInterrupted
+			default:
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Unindexing transactions", "blocks", number-from, "total", to-from, "txs", unindexed, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if to > from {
+		log.Debug("Unindexed transactions", "from", from, "to", to, "txs", unindexed, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// PruneHistory deletes the bodies, receipts and transaction lookup entries of
+// the canonical blocks in [from, to), keeping their headers, and moves the
+// history tail up to [to]. The tail is updated with each batch written, so an
+// interrupted run can be resumed.
+func PruneHistory(db ethdb.Database, from, to uint64, interrupt <-chan struct{}) error {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		batch  = db.NewBatch()
+		pruned int
+	)
+	for number := from; number < to; number++ {
+		if hash := ReadCanonicalHash(db, number); hash != (common.Hash{}) {
+			if hashes, ok := blockTxHashes(db, number); ok {
+				DeleteTxLookupEntries(batch, hashes)
+			}
+			DeleteBody(batch, hash, number)
+			DeleteReceipts(batch, hash, number)
+			pruned++
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize || number == to-1 {
+			WriteHistoryTail(batch, number+1)
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			select {
+			case <-interrupt:
+				return errIterationInterrupted
+			default:
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning block history", "blocks", number-from, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if to > from {
+		log.Debug("Pruned block history", "from", from, "to", to, "blocks", pruned, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// CompactIndexes compacts the key ranges of the transaction lookup and bloom
+// bits indexes, reclaiming the disk space of their deleted entries.
+func CompactIndexes(db ethdb.Compacter) error {
+	for _, prefix := range [][]byte{txLookupPrefix, bloomBitsPrefix} {
+		start := time.Now()
+		limit := common.CopyBytes(prefix)
+		limit[len(limit)-1]++
+		if err := db.Compact(prefix, limit); err != nil {
+			return err
+		}
+		log.Info("Compacted index", "prefix", string(prefix), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
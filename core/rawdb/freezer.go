@@ -0,0 +1,251 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// errReadOnly is returned if the freezer is opened in read only mode. All the
+	// mutations are disallowed.
+	errReadOnly = errors.New("read only")
+
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+
+	// errTailMismatch is returned if the tables of the freezer do not start at the
+	// same item.
+	errTailMismatch = errors.New("freezer tables do not start at the same item")
+)
+
+// freezer is an append-only database to store immutable chain data into flat
+// files:
+//
+//   - The append only nature ensures that disk writes are minimized.
+//   - Keeping the data out of the key-value store avoids the compaction overhead
+//     of LevelDB on data that is never modified again.
+type freezer struct {
+	readonly bool
+	tables   map[string]*freezerTable // Data tables for storing everything
+
+	// [frozen] is the number of the next item to be appended and [tail] is the
+	// number of the first item stored. [frozen] is only modified while holding
+	// [writeLock] and read under [lock].
+	frozen uint64
+	tail   uint64
+	lock   sync.RWMutex
+
+	// [writeLock] ensures that items are appended by a single writer at a time.
+	writeLock sync.Mutex
+	closeOnce sync.Once
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers in [datadir].
+func newFreezer(datadir string, readonly bool) (*freezer, error) {
+	freezer := &freezer{
+		readonly: readonly,
+		tables:   make(map[string]*freezerTable),
+	}
+	for name, disableSnappy := range freezerNoSnappy {
+		table, err := newTable(datadir, name, disableSnappy, readonly)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "tail", freezer.tail, "frozen", freezer.frozen, "readonly", readonly)
+	return freezer, nil
+}
+
+// repair truncates all data tables to the same length, discarding the items of
+// a block that was only partially appended before an unclean shutdown.
+func (f *freezer) repair() error {
+	var (
+		head, tail uint64
+		maxHead    uint64
+		empty      bool // Whether any of the tables is empty
+		found      bool // Whether any of the tables holds items
+	)
+	for _, table := range f.tables {
+		if table.head() == table.first() {
+			empty = true
+			continue
+		}
+		if !found {
+			head, tail, maxHead, found = table.head(), table.first(), table.head(), true
+			continue
+		}
+		if table.first() != tail {
+			return fmt.Errorf("%w: %s table starts at %d, expected %d", errTailMismatch, table.name, table.first(), tail)
+		}
+		if table.head() < head {
+			head = table.head()
+		}
+		if table.head() > maxHead {
+			maxHead = table.head()
+		}
+	}
+	if !found {
+		return nil
+	}
+	if empty {
+		// Blocks are appended to all the tables at once, so an empty table next to
+		// non-empty ones can only be the result of the first block being partially
+		// appended.
+		if maxHead > tail+1 {
+			return fmt.Errorf("freezer tables are missing items in [%d, %d)", tail, maxHead)
+		}
+		head = tail
+	}
+	if !f.readonly {
+		for _, table := range f.tables {
+			if err := table.truncate(head); err != nil {
+				return err
+			}
+		}
+	}
+	f.frozen, f.tail = head, tail
+	return nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *freezer) Close() error {
+	var errs []error
+	f.closeOnce.Do(func() {
+		f.writeLock.Lock()
+		defer f.writeLock.Unlock()
+
+		for _, table := range f.tables {
+			if err := table.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the number of the next block to be frozen, i.e. the length
+// of the frozen chain if it starts at genesis.
+func (f *freezer) Ancients() (uint64, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.frozen, nil
+}
+
+// Tail returns the number of the first block stored in the freezer.
+func (f *freezer) Tail() (uint64, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.tail, nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.sizeBytes(), nil
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files. Blocks must be appended in order, starting
+// at any number if the freezer is empty.
+//
+// Note, this method will *not* flush any data to disk so be sure to explicitly
+// fsync before irreversibly deleting data from the database.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts []byte) (err error) {
+	if f.readonly {
+		return errReadOnly
+	}
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	f.lock.RLock()
+	frozen, empty := f.frozen, f.frozen == f.tail
+	f.lock.RUnlock()
+	if !empty && number != frozen {
+		return fmt.Errorf("%w: appending block %d to ancient store holding blocks until %d", errOutOrderInsertion, number, frozen)
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			for _, table := range f.tables {
+				if rerr := table.truncate(number); rerr != nil {
+					log.Error("Failed to truncate ancient store", "table", table.name, "number", number, "err", rerr)
+				}
+			}
+		}
+	}()
+	for kind, blob := range map[string][]byte{
+		freezerHashTable:    hash,
+		freezerHeaderTable:  header,
+		freezerBodiesTable:  body,
+		freezerReceiptTable: receipts,
+	} {
+		if err := f.tables[kind].Append(number, blob); err != nil {
+			log.Error("Failed to append ancient "+kind, "number", number, "err", err)
+			return err
+		}
+	}
+	f.lock.Lock()
+	if empty {
+		f.tail = number
+	}
+	f.frozen = number + 1
+	f.lock.Unlock()
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
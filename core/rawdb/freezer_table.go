@@ -0,0 +1,395 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+)
+
+var (
+	// errClosed is returned if an operation attempts to read from or write to the
+	// freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+// indexEntrySize is the size of a single entry of the index file, which holds
+// the offset at which the data of an item ends in the data file.
+const indexEntrySize = 8
+
+// freezerTable is an append-only table of binary blobs identified by their item
+// number. The blobs are stored back to back in a data file and an index file
+// records where each of them ends.
+//
+// The first entry of the index file holds the number of the first item stored in
+// the table (the tail), so that a table does not have to start at item 0. Each
+// following entry holds the end offset of the next item in the data file, whose
+// start is the end of the previous item (or 0 for the first item).
+type freezerTable struct {
+	noCompression bool // if true, disables snappy compression. Note: does not work retroactively
+	readonly      bool
+
+	name string
+	path string
+
+	index *os.File // File descriptor for the index entries of the table
+	data  *os.File // File descriptor for the data of the table
+
+	tail  uint64 // Number of the first item stored in the table
+	items uint64 // Number of items stored in the table
+	size  uint64 // Number of bytes stored in the data file
+
+	lock sync.RWMutex // Mutex protecting the data file descriptors
+}
+
+// newTable opens the freezer table [name] in [path], creating it if it does not
+// exist yet. Any partially written item left behind by an unclean shutdown is
+// discarded.
+func newTable(path string, name string, noCompression bool, readonly bool) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	var (
+		idxName  = fmt.Sprintf("%s.ridx", name)
+		dataName = fmt.Sprintf("%s.rdat", name)
+		flag     = os.O_RDWR | os.O_CREATE
+	)
+	if !noCompression {
+		idxName = fmt.Sprintf("%s.cidx", name)
+		dataName = fmt.Sprintf("%s.cdat", name)
+	}
+	if readonly {
+		flag = os.O_RDONLY
+	}
+	index, err := os.OpenFile(filepath.Join(path, idxName), flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(path, dataName), flag, 0o644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	t := &freezerTable{
+		noCompression: noCompression,
+		readonly:      readonly,
+		name:          name,
+		path:          path,
+		index:         index,
+		data:          data,
+	}
+	if err := t.repair(); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair cross checks the index and data files, truncating both of them to the
+// last item that has been fully written.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	indexSize := uint64(stat.Size())
+	if indexSize < indexEntrySize {
+		// A missing tail entry means the table is new (or was never written to).
+		if t.readonly {
+			return nil
+		}
+		if err := t.index.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := t.index.WriteAt(make([]byte, indexEntrySize), 0); err != nil {
+			return err
+		}
+		indexSize = indexEntrySize
+	}
+	// Drop any partially written index entry
+	if overflow := indexSize % indexEntrySize; overflow != 0 {
+		indexSize -= overflow
+	}
+	buf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buf, 0); err != nil {
+		return err
+	}
+	t.tail = binary.BigEndian.Uint64(buf)
+	t.items = indexSize/indexEntrySize - 1
+
+	stat, err = t.data.Stat()
+	if err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	// Drop the index entries of items whose data was not fully written
+	for t.items > 0 {
+		end, err := t.itemEnd(t.items - 1)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			t.size = end
+			break
+		}
+		t.items--
+	}
+	if t.items == 0 {
+		t.size = 0
+	}
+	if t.readonly {
+		return nil
+	}
+	if err := truncateFile(t.index, indexEntrySize*(t.items+1)); err != nil {
+		return err
+	}
+	if err := truncateFile(t.data, t.size); err != nil {
+		return err
+	}
+	if dataSize != t.size {
+		log.Warn("Truncated dangling freezer table data", "table", t.name, "size", dataSize-t.size)
+	}
+	return nil
+}
+
+// truncateFile truncates [f] to [size] bytes if it is larger.
+func truncateFile(f *os.File, size uint64) error {
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if uint64(stat.Size()) <= size {
+		return nil
+	}
+	if err := f.Truncate(int64(size)); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// itemEnd returns the offset in the data file at which the item at [position]
+// from the tail ends.
+//
+// Assumes [position] is less than [t.items].
+func (t *freezerTable) itemEnd(position uint64) (uint64, error) {
+	buf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buf, int64((position+1)*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// itemBounds returns the start and end offsets in the data file of the item at
+// [position] from the tail.
+//
+// Assumes [position] is less than [t.items].
+func (t *freezerTable) itemBounds(position uint64) (uint64, uint64, error) {
+	end, err := t.itemEnd(position)
+	if err != nil {
+		return 0, 0, err
+	}
+	if position == 0 {
+		return 0, end, nil
+	}
+	start, err := t.itemEnd(position - 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// Append injects a binary blob at the end of the freezer table. The item number
+// must be the next one in the table, unless the table is empty, in which case
+// the table starts at [item].
+//
+// Note, this method will *not* flush any data to disk so be sure to explicitly
+// fsync before irreversibly deleting data from the database.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if t.readonly {
+		return errReadOnly
+	}
+	if t.items == 0 && item != t.tail {
+		buf := make([]byte, indexEntrySize)
+		binary.BigEndian.PutUint64(buf, item)
+		if _, err := t.index.WriteAt(buf, 0); err != nil {
+			return err
+		}
+		t.tail = item
+	}
+	if item != t.tail+t.items {
+		return fmt.Errorf("%w: appending item %d to %s table with %d items from %d", errOutOrderInsertion, item, t.name, t.items, t.tail)
+	}
+	if !t.noCompression {
+		blob = snappy.Encode(nil, blob)
+	}
+	// Write the data before the index entry, so that a crash in between leaves
+	// dangling data that is truncated when the table is reopened.
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	end := t.size + uint64(len(blob))
+	buf := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(buf, end)
+	if _, err := t.index.WriteAt(buf, int64((t.items+1)*indexEntrySize)); err != nil {
+		return err
+	}
+	t.size = end
+	t.items++
+	return nil
+}
+
+// truncate discards any items with a number greater than or equal to [items].
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if items >= t.tail+t.items {
+		return nil
+	}
+	var kept uint64
+	if items > t.tail {
+		kept = items - t.tail
+	}
+	var size uint64
+	if kept > 0 {
+		end, err := t.itemEnd(kept - 1)
+		if err != nil {
+			return err
+		}
+		size = end
+	}
+	log.Warn("Truncating freezer table", "table", t.name, "items", t.tail+t.items, "limit", items)
+	if err := truncateFile(t.index, indexEntrySize*(kept+1)); err != nil {
+		return err
+	}
+	if err := truncateFile(t.data, size); err != nil {
+		return err
+	}
+	t.items = kept
+	t.size = size
+	return nil
+}
+
+// has returns an indicator whether the item [number] is stored in the table.
+func (t *freezerTable) has(number uint64) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return number >= t.tail && number < t.tail+t.items
+}
+
+// head returns the number of the next item to be appended to the table.
+func (t *freezerTable) head() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.tail + t.items
+}
+
+// first returns the number of the first item stored in the table.
+func (t *freezerTable) first() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.tail
+}
+
+// Retrieve looks up the data offset of an item with the given number and
+// retrieves the raw binary blob from the data file.
+func (t *freezerTable) Retrieve(number uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return nil, errClosed
+	}
+	if number < t.tail || number >= t.tail+t.items {
+		return nil, errOutOfBounds
+	}
+	start, end, err := t.itemBounds(number - t.tail)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if t.noCompression {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// sizeBytes returns the total data size in the freezer table.
+func (t *freezerTable) sizeBytes() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.size + indexEntrySize*(t.items+1)
+}
+
+// Sync pushes any pending data from memory out to disk. This is an expensive
+// operation, so use it with care.
+func (t *freezerTable) Sync() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if t.readonly {
+		return nil
+	}
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	return t.data.Sync()
+}
+
+// Close closes all opened files.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	for _, f := range []*os.File{t.index, t.data} {
+		if f == nil {
+			continue
+		}
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.index, t.data = nil, nil
+	if len(errs) != 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
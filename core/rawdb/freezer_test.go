@@ -0,0 +1,263 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// getChunk returns a chunk of data of length [size] filled with [b].
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// Tests that the items of a freezer table can be read back after reopening it,
+// and that a partially written item is discarded.
+func TestFreezerTableRepair(t *testing.T) {
+	for _, noCompression := range []bool{true, false} {
+		t.Run(fmt.Sprintf("noCompression=%v", noCompression), func(t *testing.T) {
+			dir := t.TempDir()
+			table, err := newTable(dir, "test", noCompression, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := uint64(0); i < 10; i++ {
+				if err := table.Append(i, getChunk(15, int(i))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := table.Append(11, getChunk(15, 11)); !errors.Is(err, errOutOrderInsertion) {
+				t.Fatalf("expected out of order error, got %v", err)
+			}
+			table.Close()
+
+			// Append the data of an item without its index entry, as if the node
+			// crashed in between.
+			dataName := "test.cdat"
+			if noCompression {
+				dataName = "test.rdat"
+			}
+			f, err := os.OpenFile(filepath.Join(dir, dataName), os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Write(getChunk(15, 10)); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			table, err = newTable(dir, "test", noCompression, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer table.Close()
+			if head := table.head(); head != 10 {
+				t.Fatalf("head mismatch: have %d, want %d", head, 10)
+			}
+			for i := uint64(0); i < 10; i++ {
+				blob, err := table.Retrieve(i)
+				if err != nil {
+					t.Fatalf("failed to retrieve item %d: %v", i, err)
+				}
+				if !bytes.Equal(blob, getChunk(15, int(i))) {
+					t.Fatalf("item %d mismatch: have %x", i, blob)
+				}
+			}
+			if _, err := table.Retrieve(10); !errors.Is(err, errOutOfBounds) {
+				t.Fatalf("expected out of bounds error, got %v", err)
+			}
+			// The table can be appended to where the repaired data ends
+			if err := table.Append(10, getChunk(20, 10)); err != nil {
+				t.Fatal(err)
+			}
+			if blob, err := table.Retrieve(10); err != nil || !bytes.Equal(blob, getChunk(20, 10)) {
+				t.Fatalf("item 10 mismatch: have %x, err %v", blob, err)
+			}
+		})
+	}
+}
+
+// Tests that a freezer may start at any block and keeps all of its tables at
+// the same length.
+func TestFreezerTail(t *testing.T) {
+	dir := t.TempDir()
+	f, err := newFreezer(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(100); i < 105; i++ {
+		if err := f.AppendAncient(i, getChunk(32, int(i)), []byte{1}, []byte{2}, []byte{3}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.AppendAncient(106, getChunk(32, 106), nil, nil, nil); !errors.Is(err, errOutOrderInsertion) {
+		t.Fatalf("expected out of order error, got %v", err)
+	}
+	// Leave a partially appended block behind
+	if err := f.tables[freezerHeaderTable].Append(105, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	f, err = newFreezer(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if tail, _ := f.Tail(); tail != 100 {
+		t.Fatalf("tail mismatch: have %d, want %d", tail, 100)
+	}
+	if frozen, _ := f.Ancients(); frozen != 105 {
+		t.Fatalf("frozen mismatch: have %d, want %d", frozen, 105)
+	}
+	for _, number := range []uint64{99, 105} {
+		if has, _ := f.HasAncient(freezerHeaderTable, number); has {
+			t.Fatalf("unexpected ancient header %d", number)
+		}
+	}
+	if hash, err := f.Ancient(freezerHashTable, 104); err != nil || !bytes.Equal(hash, getChunk(32, 104)) {
+		t.Fatalf("hash mismatch: have %x, err %v", hash, err)
+	}
+}
+
+// Tests that canonical blocks are moved into the ancient store, can still be
+// read through the accessors, and are deleted from the key-value store.
+func TestFreezeCanonicalBlocks(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		kvdb   = memorydb.New()
+		blocks []*types.Block
+	)
+	db, err := NewDatabaseWithFreezer(kvdb, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Extra: []byte("test block")}
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		tx := types.NewTransaction(uint64(i), common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+		block := types.NewBlockWithHeader(header).WithBody(types.Transactions{tx}, nil)
+		receipts := types.Receipts{{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(i), Logs: []*types.Log{}}}
+		WriteBlock(db, block)
+		WriteReceipts(db, block.Hash(), block.NumberU64(), receipts)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		blocks = append(blocks, block)
+	}
+	// Write a block at a height to be frozen that is not canonical
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Extra: []byte("side block")})
+	WriteBlock(db, side)
+
+	if moved, err := FreezeCanonicalBlocks(db, 5); err != nil || moved != 6 {
+		t.Fatalf("failed to freeze blocks: moved %d, err %v", moved, err)
+	}
+	if moved, err := FreezeCanonicalBlocks(db, 5); err != nil || moved != 0 {
+		t.Fatalf("unexpected second freeze: moved %d, err %v", moved, err)
+	}
+	if HasHeader(db, side.Hash(), 3) {
+		t.Fatal("side block not deleted")
+	}
+	check := func(db *freezerdb) {
+		for i, block := range blocks {
+			number := block.NumberU64()
+			if has, _ := db.KeyValueStore.Has(headerKey(number, block.Hash())); has != (i > 5) {
+				t.Fatalf("block %d in key-value store: have %v, want %v", i, has, i > 5)
+			}
+			if has, _ := db.KeyValueStore.Has(headerNumberKey(block.Hash())); !has {
+				t.Fatalf("hash to number mapping of block %d deleted", i)
+			}
+			if !HasHeader(db, block.Hash(), number) || !HasBody(db, block.Hash(), number) || !HasReceipts(db, block.Hash(), number) {
+				t.Fatalf("block %d missing", i)
+			}
+			if entry := ReadBlock(db, block.Hash(), number); entry == nil || entry.Hash() != block.Hash() {
+				t.Fatalf("block %d mismatch: have %v", i, entry)
+			}
+			if len(ReadCanonicalBodyRLP(db, number)) == 0 {
+				t.Fatalf("canonical body %d missing", i)
+			}
+			if receipts := ReadReceipts(db, block.Hash(), number, params.TestChainConfig); len(receipts) != 1 || receipts[0].CumulativeGasUsed != uint64(i) {
+				t.Fatalf("receipts %d mismatch: have %v", i, receipts)
+			}
+		}
+		// A frozen block is only returned for its own hash
+		if HasHeader(db, common.Hash{1}, 1) || ReadHeaderRLP(db, common.Hash{1}, 1) != nil {
+			t.Fatal("frozen block returned for a different hash")
+		}
+	}
+	check(db.(*freezerdb))
+
+	// Reopen the ancient store and continue freezing from where it stopped
+	if err := db.(*freezerdb).AncientStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = NewDatabaseWithFreezer(kvdb, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	check(db.(*freezerdb))
+	if moved, err := FreezeCanonicalBlocks(db, 8); err != nil || moved != 3 {
+		t.Fatalf("failed to freeze blocks: moved %d, err %v", moved, err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 9 {
+		t.Fatalf("frozen mismatch: have %d, want %d", frozen, 9)
+	}
+	if !HasHeader(db, blocks[8].Hash(), 8) || ReadBlock(db, blocks[9].Hash(), 9) == nil {
+		t.Fatal("blocks missing after second freeze")
+	}
+	if used, _ := HasAncientStoreUsed(db); !used {
+		t.Fatal("ancient store marker missing")
+	}
+}
+
+// Tests that the ancient store starts at the oldest block of the contiguous
+// range of blocks held in the key-value store, as for a state synced node.
+func TestFreezeCanonicalBlocksAfterGap(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, number := range []uint64{0, 5, 6, 7} {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number)})
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), number)
+	}
+	if moved, err := FreezeCanonicalBlocks(db, 6); err != nil || moved != 2 {
+		t.Fatalf("failed to freeze blocks: moved %d, err %v", moved, err)
+	}
+	if tail, _ := db.Tail(); tail != 5 {
+		t.Fatalf("tail mismatch: have %d, want %d", tail, 5)
+	}
+	// Blocks stored without receipts are frozen without them
+	if HasReceipts(db, ReadCanonicalHash(db, 5), 5) {
+		t.Fatal("unexpected receipts for frozen block")
+	}
+	if ReadBlock(db, ReadCanonicalHash(db, 0), 0) == nil {
+		t.Fatal("genesis block missing")
+	}
+}
+
+// Tests that a database without an ancient store reports it is not supported.
+func TestFreezeCanonicalBlocksNotSupported(t *testing.T) {
+	if _, err := FreezeCanonicalBlocks(NewMemoryDatabase(), 0); !IsAncientStoreNotSupported(err) {
+		t.Fatalf("expected not supported error, got %v", err)
+	}
+}
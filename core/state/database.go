@@ -136,7 +136,18 @@ func NewDatabase(db ethdb.Database) Database {
 // NewDatabaseWithConfig creates a backing store for state. The returned database
 // is safe for concurrent use and retains a lot of collapsed RLP trie nodes in a
 // large memory cache.
+//
+// If the config does not specify a state scheme, the scheme recorded in [db] is
+// used.
 func NewDatabaseWithConfig(db ethdb.Database, config *trie.Config) Database {
+	if config == nil || config.Scheme == "" {
+		var withScheme trie.Config
+		if config != nil {
+			withScheme = *config
+		}
+		withScheme.Scheme = rawdb.ReadStateScheme(db)
+		config = &withScheme
+	}
 	csc, _ := lru.New(codeSizeCacheSize)
 	return &cachingDB{
 		db:            trie.NewDatabaseWithConfig(db, config),
@@ -0,0 +1,375 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// flushBloomSize is the size (MB) of the bloom filters recording the trie
+	// nodes flushed to disk by the trie database.
+	flushBloomSize = 64
+
+	// sweepBatchSize is the number of stale trie nodes to collect before
+	// deleting them from disk.
+	sweepBatchSize = 1024
+)
+
+var errPrunerStopped = errors.New("online pruner stopped")
+
+// staleNode is a trie node on disk that does not belong to the retained state.
+type staleNode struct {
+	key  []byte
+	size int
+}
+
+// OnlinePruner discards the historical state of a running node in the background,
+// as opposed to [Pruner] which requires the node to be stopped. Each pruning pass:
+//
+// - marks the trie nodes of the retained states in a bloom filter
+// - iterates the database, deleting the trie nodes which are neither marked
+//   nor recently flushed to disk by the trie database
+//
+// The trie database may flush a node the pass is about to delete (the node of a
+// new state can be identical to a stale one). To prevent this, the hashes of the
+// flushed nodes are recorded under [lock] before they are written, and stale nodes
+// are only deleted while holding [lock] after checking they were not recorded.
+//
+// Contract code is never pruned, as it is written to disk by the state database
+// directly instead of being flushed by the trie database.
+type OnlinePruner struct {
+	db        ethdb.KeyValueStore
+	triedb    *trie.Database
+	bloomSize uint64
+
+	// [recent] records the trie nodes flushed since the last pass started and
+	// [previous] the ones flushed between the starts of the two last passes.
+	// Together, they protect the nodes of the states that were inserted but not
+	// yet accepted when a pass starts, which are not marked by the pass.
+	lock     sync.Mutex
+	recent   *stateBloom
+	previous *stateBloom
+
+	running int32 // 1 while a pruning pass is running
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewOnlinePruner creates an online pruner for the state in [triedb] and starts
+// recording the trie nodes it flushes to disk. [bloomSize] is the size (MB) of
+// the bloom filter used to mark the retained state in each pass.
+func NewOnlinePruner(triedb *trie.Database, bloomSize uint64) (*OnlinePruner, error) {
+	// Sanitize the bloom filter size if it's too small.
+	if bloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", bloomSize, "updated(MB)", 256)
+		bloomSize = 256
+	}
+	recent, err := newStateBloomWithSize(flushBloomSize)
+	if err != nil {
+		return nil, err
+	}
+	p := &OnlinePruner{
+		db:        triedb.DiskDB(),
+		triedb:    triedb,
+		bloomSize: bloomSize,
+		recent:    recent,
+		quit:      make(chan struct{}),
+	}
+	triedb.SetOnFlush(p.onFlush)
+	return p, nil
+}
+
+// onFlush records the hashes of the trie nodes about to be flushed to disk.
+func (p *OnlinePruner) onFlush(hashes []common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, hash := range hashes {
+		p.recent.Put(hash.Bytes(), nil)
+	}
+}
+
+// flushedRecently reports whether the trie node with [key] may have been flushed
+// to disk since the start of the previous pass. Assumes [lock] is held.
+func (p *OnlinePruner) flushedRecently(key []byte) bool {
+	if ok, _ := p.recent.Contain(key); ok {
+		return true
+	}
+	if p.previous == nil {
+		return false
+	}
+	ok, _ := p.previous.Contain(key)
+	return ok
+}
+
+// Prune starts a pruning pass in the background which deletes the trie nodes on
+// disk that do not belong to the states at [retained] or [recent]. Returns false
+// if a pass is already running.
+//
+// The states at [retained] must be complete on disk (missing roots are skipped).
+// Each state is marked relative to the previous one, so they should be ordered
+// by height. The states at [recent] may be dereferenced from the trie database
+// while the pass runs, in which case they are no longer retained.
+func (p *OnlinePruner) Prune(retained []common.Hash, recent []common.Hash) bool {
+	if !atomic.CompareAndSwapInt32(&p.running, 0, 1) {
+		return false
+	}
+	marked, err := newStateBloomWithSize(p.bloomSize)
+	if err != nil {
+		log.Error("Failed to create bloom filter for online pruning", "err", err)
+		atomic.StoreInt32(&p.running, 0)
+		return false
+	}
+	flushed, err := newStateBloomWithSize(flushBloomSize)
+	if err != nil {
+		log.Error("Failed to create bloom filter for online pruning", "err", err)
+		atomic.StoreInt32(&p.running, 0)
+		return false
+	}
+	p.lock.Lock()
+	p.previous, p.recent = p.recent, flushed
+	p.lock.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer atomic.StoreInt32(&p.running, 0)
+
+		if err := p.prune(marked, retained, recent); err != nil {
+			if errors.Is(err, errPrunerStopped) {
+				log.Info("Online pruning interrupted")
+				return
+			}
+			log.Error("Online pruning failed", "err", err)
+		}
+	}()
+	return true
+}
+
+// Wait blocks until the running pruning pass, if any, has finished.
+func (p *OnlinePruner) Wait() {
+	p.wg.Wait()
+}
+
+// Stop interrupts the running pruning pass, if any, and stops recording the
+// trie nodes flushed to disk.
+func (p *OnlinePruner) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+	p.triedb.SetOnFlush(nil)
+}
+
+func (p *OnlinePruner) prune(marked *stateBloom, retained []common.Hash, recent []common.Hash) error {
+	start := time.Now()
+
+	var base common.Hash
+	for _, root := range retained {
+		if !rawdb.HasTrieNode(p.db, root) {
+			log.Debug("Skipping missing state for online pruning", "root", root)
+			continue
+		}
+		if err := p.markState(marked, base, root); err != nil {
+			return fmt.Errorf("failed to mark state %s: %w", root, err)
+		}
+		base = root
+	}
+	for _, root := range recent {
+		err := p.markState(marked, base, root)
+		var missing *trie.MissingNodeError
+		if errors.As(err, &missing) {
+			log.Debug("Skipping dereferenced state for online pruning", "root", root)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to mark state %s: %w", root, err)
+		}
+	}
+	log.Info("Marked retained state for online pruning", "elapsed", common.PrettyDuration(time.Since(start)))
+
+	count, size, err := p.sweep(marked)
+	if err != nil {
+		return err
+	}
+	log.Info("Online pruning finished", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// markState adds the trie nodes of the state at [root] to [marked], skipping the
+// subtries shared with the state at [base], which must already be marked.
+func (p *OnlinePruner) markState(marked *stateBloom, base, root common.Hash) error {
+	var baseTrie *trie.Trie
+	if base != (common.Hash{}) {
+		var err error
+		if baseTrie, err = trie.New(common.Hash{}, base, p.triedb); err != nil {
+			return err
+		}
+	}
+	return p.markTrie(marked, common.Hash{}, base, root, func(key, blob []byte) error {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return err
+		}
+		if acc.Root == emptyRoot {
+			return nil
+		}
+		var baseStorageRoot common.Hash
+		if baseTrie != nil {
+			baseBlob, err := baseTrie.TryGet(key)
+			if err != nil {
+				return err
+			}
+			if len(baseBlob) > 0 {
+				var baseAcc types.StateAccount
+				if err := rlp.DecodeBytes(baseBlob, &baseAcc); err != nil {
+					return err
+				}
+				if baseAcc.Root == acc.Root {
+					return nil // already marked with [base]
+				}
+				if baseAcc.Root != emptyRoot {
+					baseStorageRoot = baseAcc.Root
+				}
+			}
+		}
+		return p.markTrie(marked, common.BytesToHash(key), baseStorageRoot, acc.Root, nil)
+	})
+}
+
+// markTrie adds the nodes of the trie at [root] that are not in the trie at [base]
+// (if non-empty) to [marked], invoking [onLeaf] (if non-nil) for each of its leaves
+// that are not in [base].
+func (p *OnlinePruner) markTrie(marked *stateBloom, owner, base, root common.Hash, onLeaf func(key, blob []byte) error) error {
+	t, err := trie.New(owner, root, p.triedb)
+	if err != nil {
+		return err
+	}
+	it := t.NodeIterator(nil)
+	if base != (common.Hash{}) {
+		baseTrie, err := trie.New(owner, base, p.triedb)
+		if err != nil {
+			return err
+		}
+		it, _ = trie.NewDifferenceIterator(baseTrie.NodeIterator(nil), it)
+	}
+	for it.Next(true) {
+		select {
+		case <-p.quit:
+			return errPrunerStopped
+		default:
+		}
+		// Embedded nodes don't have hash.
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			marked.Put(hash.Bytes(), nil)
+		}
+		if onLeaf != nil && it.Leaf() {
+			if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// sweep deletes the trie nodes on disk that are neither in [marked] nor recently
+// flushed, returning the number and size of the deleted nodes.
+//
+// Unlike [prune], the database is not compacted afterwards to avoid stalling the
+// node. The deleted entries are removed by the background compactions instead.
+func (p *OnlinePruner) sweep(marked *stateBloom) (int, common.StorageSize, error) {
+	var (
+		count  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		stale  = make([]staleNode, 0, sweepBatchSize)
+		iter   = p.db.NewIterator(nil, nil)
+	)
+	// We wrap iter.Release() in an anonymous function so that the [iter]
+	// value captured is the value of [iter] at the end of the function as opposed
+	// to incorrectly capturing the first iterator immediately.
+	defer func() {
+		iter.Release()
+	}()
+
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if ok, _ := marked.Contain(key); ok {
+			continue
+		}
+		stale = append(stale, staleNode{key: common.CopyBytes(key), size: len(key) + len(iter.Value())})
+		if len(stale) < sweepBatchSize {
+			continue
+		}
+		deleted, deletedSize, err := p.deleteStale(stale)
+		if err != nil {
+			return count, size, err
+		}
+		count += deleted
+		size += deletedSize
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		select {
+		case <-p.quit:
+			return count, size, errPrunerStopped
+		default:
+		}
+		// Recreate the iterator after every batch in order to allow
+		// the underlying compactor to delete the entries.
+		next := stale[len(stale)-1].key
+		stale = stale[:0]
+		iter.Release()
+		iter = p.db.NewIterator(nil, next)
+	}
+	if err := iter.Error(); err != nil {
+		return count, size, fmt.Errorf("failed to iterate db during online pruning: %w", err)
+	}
+	deleted, deletedSize, err := p.deleteStale(stale)
+	return count + deleted, size + deletedSize, err
+}
+
+// deleteStale deletes the nodes in [stale] which were not recently flushed to
+// disk. [lock] is held until the deletions are written, so a node cannot be
+// flushed between the check and its deletion.
+func (p *OnlinePruner) deleteStale(stale []staleNode) (int, common.StorageSize, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var (
+		count int
+		size  common.StorageSize
+		batch = p.db.NewBatch()
+	)
+	for _, node := range stale {
+		if p.flushedRecently(node.key) {
+			continue
+		}
+		if err := batch.Delete(node.key); err != nil {
+			return 0, 0, err
+		}
+		count++
+		size += common.StorageSize(node.size)
+	}
+	if err := batch.Write(); err != nil {
+		return 0, 0, err
+	}
+	return count, size, nil
+}
@@ -136,3 +136,9 @@ func loadSnapshot(diskdb ethdb.KeyValueStore, triedb *trie.Database, cache int,
 func ResetSnapshotGeneration(db ethdb.KeyValueWriter) {
 	journalProgress(db, nil, nil)
 }
+
+// ScheduleSnapshotRegeneration writes a snapshot generator marker to [db] so
+// the snapshot is wiped and regenerated from the state trie when it is loaded.
+func ScheduleSnapshotRegeneration(db ethdb.KeyValueWriter) {
+	journalProgress(db, []byte{}, &generatorStats{wiping: make(chan struct{})})
+}
@@ -0,0 +1,136 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// stateDiffRecorder records the changes written to the tries of a StateDB,
+// see [StateDB.RecordStateDiff].
+type stateDiffRecorder struct {
+	origins  map[common.Address]*types.AccountDiffState // Accounts as loaded from the database, nil if they did not exist
+	accounts map[common.Address]*types.AccountDiffState // Accounts as last written to the trie, nil if deleted
+	storage  map[common.Address]map[common.Hash]*types.StorageDiff
+}
+
+func newStateDiffRecorder() *stateDiffRecorder {
+	return &stateDiffRecorder{
+		origins:  make(map[common.Address]*types.AccountDiffState),
+		accounts: make(map[common.Address]*types.AccountDiffState),
+		storage:  make(map[common.Address]map[common.Hash]*types.StorageDiff),
+	}
+}
+
+// accountDiffState returns the state of [data] in a state diff.
+func accountDiffState(data *types.StateAccount) *types.AccountDiffState {
+	return &types.AccountDiffState{
+		Nonce:    data.Nonce,
+		Balance:  new(big.Int).Set(data.Balance),
+		CodeHash: common.BytesToHash(data.CodeHash),
+	}
+}
+
+// loadAccount records [data] as the original state of the account at [addr],
+// unless the account was loaded before. [data] is nil if the account does not
+// exist.
+func (r *stateDiffRecorder) loadAccount(addr common.Address, data *types.StateAccount) {
+	if _, ok := r.origins[addr]; ok {
+		return
+	}
+	if data == nil {
+		r.origins[addr] = nil
+	} else {
+		r.origins[addr] = accountDiffState(data)
+	}
+}
+
+// updateAccount records [data] as the current state of the account at [addr],
+// or the account as deleted if [data] is nil.
+func (r *stateDiffRecorder) updateAccount(addr common.Address, data *types.StateAccount) {
+	if data == nil {
+		r.accounts[addr] = nil
+		delete(r.storage, addr)
+	} else {
+		r.accounts[addr] = accountDiffState(data)
+	}
+}
+
+// updateStorage records the change of the slot [key] of the account at [addr]
+// from [pre] to [post]. The original value of the slot is kept if it changed
+// before.
+func (r *stateDiffRecorder) updateStorage(addr common.Address, key, pre, post common.Hash) {
+	slots, ok := r.storage[addr]
+	if !ok {
+		slots = make(map[common.Hash]*types.StorageDiff)
+		r.storage[addr] = slots
+	}
+	if slot, ok := slots[key]; ok {
+		slot.Post = post
+	} else {
+		slots[key] = &types.StorageDiff{Key: key, Pre: pre, Post: post}
+	}
+}
+
+func (r *stateDiffRecorder) copy() *stateDiffRecorder {
+	cpy := newStateDiffRecorder()
+	for addr, origin := range r.origins {
+		cpy.origins[addr] = origin
+	}
+	for addr, account := range r.accounts {
+		cpy.accounts[addr] = account
+	}
+	for addr, slots := range r.storage {
+		cpySlots := make(map[common.Hash]*types.StorageDiff, len(slots))
+		for key, slot := range slots {
+			cpySlot := *slot
+			cpySlots[key] = &cpySlot
+		}
+		cpy.storage[addr] = cpySlots
+	}
+	return cpy
+}
+
+// diff returns the accounts and storage slots whose recorded state differs from
+// their original state.
+func (r *stateDiffRecorder) diff() *types.StateDiff {
+	diff := &types.StateDiff{}
+	for addr, post := range r.accounts {
+		pre := r.origins[addr]
+		var storage []*types.StorageDiff
+		for _, slot := range r.storage[addr] {
+			if slot.Pre != slot.Post {
+				storage = append(storage, slot)
+			}
+		}
+		if len(storage) == 0 && equalAccountDiffStates(pre, post) {
+			continue
+		}
+		sort.Slice(storage, func(i, j int) bool {
+			return bytes.Compare(storage[i].Key[:], storage[j].Key[:]) < 0
+		})
+		diff.Accounts = append(diff.Accounts, &types.AccountDiff{
+			Address: addr,
+			Pre:     pre,
+			Post:    post,
+			Storage: storage,
+		})
+	}
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return bytes.Compare(diff.Accounts[i].Address[:], diff.Accounts[j].Address[:]) < 0
+	})
+	return diff
+}
+
+func equalAccountDiffStates(a, b *types.AccountDiffState) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Nonce == b.Nonce && a.Balance.Cmp(b.Balance) == 0 && a.CodeHash == b.CodeHash
+}
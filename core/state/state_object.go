@@ -101,12 +101,20 @@ type stateObject struct {
 	dirtyStorage   Storage // Storage entries that have been modified in the current transaction execution
 	fakeStorage    Storage // Fake storage which constructed by caller for debugging purpose.
 
+	// readCache holds values derived from the storage of the account by its readers
+	// (e.g. the role of an address in a precompile allow list). It is dropped whenever
+	// a storage slot is set, including when a modification is reverted.
+	readCache map[interface{}]interface{}
+
 	// Cache flags.
 	// When an object is marked suicided it will be delete from the trie
 	// during the "update" phase of the state transition.
 	dirtyCode bool // true if the code was updated
 	suicided  bool
 	deleted   bool
+
+	// Flag whether the object was created in the current transaction
+	created bool
 }
 
 // empty returns whether the account is considered empty.
@@ -217,6 +225,7 @@ func (s *stateObject) GetCommittedState(db Database, key common.Hash) common.Has
 		enc []byte
 		err error
 	)
+	s.db.StorageLoaded++
 	if s.db.snap != nil {
 		// If the object was destructed in *this* block (and potentially resurrected),
 		// the storage has been cleared out, and we should *not* consult the previous
@@ -292,12 +301,29 @@ func (s *stateObject) SetStorage(storage map[common.Hash]common.Hash) {
 	for key, value := range storage {
 		s.fakeStorage[key] = value
 	}
+	s.readCache = nil
 	// Don't bother journal since this function should only be used for
 	// debugging and the `fake` storage won't be committed to database.
 }
 
 func (s *stateObject) setState(key, value common.Hash) {
 	s.dirtyStorage[key] = value
+	s.readCache = nil
+}
+
+// getCachedRead returns the value cached under [key] by a reader of the storage,
+// if the storage has not been modified since it was cached.
+func (s *stateObject) getCachedRead(key interface{}) (interface{}, bool) {
+	value, ok := s.readCache[key]
+	return value, ok
+}
+
+// cacheRead caches [value] under [key] until the storage is modified.
+func (s *stateObject) cacheRead(key interface{}, value interface{}) {
+	if s.readCache == nil {
+		s.readCache = make(map[interface{}]interface{})
+	}
+	s.readCache[key] = value
 }
 
 // finalise moves all dirty storage slots into the pending area to be hashed or
@@ -342,6 +368,9 @@ func (s *stateObject) updateTrie(db Database) Trie {
 		if value == s.originStorage[key] {
 			continue
 		}
+		if s.db.diff != nil {
+			s.db.diff.updateStorage(s.address, key, s.originStorage[key], value)
+		}
 		s.originStorage[key] = value
 
 		var v []byte
@@ -457,6 +486,7 @@ func (s *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.suicided = s.suicided
 	stateObject.dirtyCode = s.dirtyCode
 	stateObject.deleted = s.deleted
+	stateObject.created = s.created
 	return stateObject
 }
 
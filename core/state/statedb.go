@@ -86,6 +86,9 @@ type StateDB struct {
 	snapAccounts  map[common.Hash][]byte
 	snapStorage   map[common.Hash]map[common.Hash][]byte
 
+	// diff records the changes written to the tries if enabled by RecordStateDiff
+	diff *stateDiffRecorder
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects        map[common.Address]*stateObject
 	stateObjectsPending map[common.Address]struct{} // State objects finalized but not yet written to the trie
@@ -135,6 +138,10 @@ type StateDB struct {
 	StorageUpdated int
 	AccountDeleted int
 	StorageDeleted int
+
+	// Number of accounts and storage slots loaded from the snapshot or the trie
+	AccountLoaded int
+	StorageLoaded int
 }
 
 // New creates a new state from a given trie.
@@ -182,14 +189,15 @@ func NewWithSnapshot(root common.Hash, db Database, snap snapshot.Snapshot) (*St
 
 // StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
 // state trie concurrently while the state is mutated so that when we reach the
-// commit phase, most of the needed data is already hot.
-func (s *StateDB) StartPrefetcher(namespace string) {
+// commit phase, most of the needed data is already hot. At most [maxConcurrency]
+// tries are loaded concurrently, or any number of them if it is not positive.
+func (s *StateDB) StartPrefetcher(namespace string, maxConcurrency int) {
 	if s.prefetcher != nil {
 		s.prefetcher.close()
 		s.prefetcher = nil
 	}
 	if s.snap != nil {
-		s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, namespace)
+		s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, namespace, maxConcurrency)
 	}
 }
 
@@ -213,6 +221,12 @@ func (s *StateDB) Error() error {
 	return s.dbErr
 }
 
+// StateReads returns the number of accounts and storage slots loaded from the snapshot
+// or the trie, as opposed to those served from the objects already loaded.
+func (s *StateDB) StateReads() (accounts int, storage int) {
+	return s.AccountLoaded, s.StorageLoaded
+}
+
 // AddLog adds a log with the specified parameters to the statedb
 // Note: blockNumber is a required argument because StateDB does not
 // know the current block number.
@@ -350,6 +364,26 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	return common.Hash{}
 }
 
+// GetCachedRead returns the value cached under [key] for the storage of [addr],
+// if the storage has not been modified since it was cached.
+func (s *StateDB) GetCachedRead(addr common.Address, key interface{}) (interface{}, bool) {
+	stateObject := s.getStateObject(addr)
+	if stateObject != nil {
+		return stateObject.getCachedRead(key)
+	}
+	return nil, false
+}
+
+// CacheRead caches [value], derived from the storage of [addr], under [key]. The
+// cached values are dropped whenever the storage of [addr] is modified, or one of
+// its modifications is reverted, and are not carried over by Copy.
+func (s *StateDB) CacheRead(addr common.Address, key interface{}, value interface{}) {
+	stateObject := s.getStateObject(addr)
+	if stateObject != nil {
+		stateObject.cacheRead(key, value)
+	}
+}
+
 // GetProof returns the Merkle proof for a given account.
 func (s *StateDB) GetProof(addr common.Address) ([][]byte, error) {
 	return s.GetProofByHash(crypto.Keccak256Hash(addr.Bytes()))
@@ -399,6 +433,35 @@ func (s *StateDB) StorageTrie(addr common.Address) Trie {
 	return cpy.getTrie(s.db)
 }
 
+// GetStorageRoot returns the root of the storage trie of [addr], including the
+// modifications of the current block, or the empty hash if the account does not exist.
+func (s *StateDB) GetStorageRoot(addr common.Address) common.Hash {
+	trie := s.StorageTrie(addr)
+	if trie == nil {
+		return common.Hash{}
+	}
+	return trie.Hash()
+}
+
+// CheckTransactionConditional returns an error if the storage of the known accounts
+// of [cond] does not match the state.
+func (s *StateDB) CheckTransactionConditional(cond *types.TransactionConditional) error {
+	for addr, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			if root := s.GetStorageRoot(addr); root != *account.StorageRoot {
+				return fmt.Errorf("storage root of %s is %s, expected %s", addr, root, *account.StorageRoot)
+			}
+			continue
+		}
+		for slot, value := range account.StorageSlots {
+			if have := s.GetState(addr, slot); have != value {
+				return fmt.Errorf("storage slot %s of %s is %s, expected %s", slot, addr, have, value)
+			}
+		}
+	}
+	return nil
+}
+
 func (s *StateDB) HasSuicided(addr common.Address) bool {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
@@ -485,6 +548,19 @@ func (s *StateDB) Suicide(addr common.Address) bool {
 	return true
 }
 
+// Selfdestruct6780 marks the given account as suicided only if it was created
+// in the current transaction, as specified by EIP-6780. Accounts that existed
+// before the transaction keep their code and storage.
+func (s *StateDB) Selfdestruct6780(addr common.Address) {
+	stateObject := s.getStateObject(addr)
+	if stateObject == nil {
+		return
+	}
+	if stateObject.created {
+		s.Suicide(addr)
+	}
+}
+
 //
 // Setting, updating & deleting state object methods.
 //
@@ -508,6 +584,9 @@ func (s *StateDB) updateStateObject(obj *stateObject) {
 	if s.snap != nil {
 		s.snapAccounts[obj.addrHash] = snapshot.SlimAccountRLP(obj.data.Nonce, obj.data.Balance, obj.data.Root, obj.data.CodeHash)
 	}
+	if s.diff != nil {
+		s.diff.updateAccount(addr, &obj.data)
+	}
 }
 
 // deleteStateObject removes the given object from the state trie.
@@ -521,6 +600,9 @@ func (s *StateDB) deleteStateObject(obj *stateObject) {
 	if err := s.trie.TryDeleteAccount(addr[:]); err != nil {
 		s.setError(fmt.Errorf("deleteStateObject (%x) error: %v", addr[:], err))
 	}
+	if s.diff != nil {
+		s.diff.updateAccount(addr, nil)
+	}
 }
 
 // getStateObject retrieves a state object given by the address, returning nil if
@@ -543,6 +625,7 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 		return obj
 	}
 	// If no live objects are available, attempt to use snapshots
+	s.AccountLoaded++
 	var data *types.StateAccount
 	if s.snap != nil {
 		start := time.Now()
@@ -584,6 +667,9 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 			return nil
 		}
 	}
+	if s.diff != nil {
+		s.diff.loadAccount(addr, data)
+	}
 	// Insert into the live set
 	obj := newObject(s, addr, *data)
 	s.setStateObject(obj)
@@ -615,7 +701,11 @@ func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject)
 			s.snapDestructs[prev.addrHash] = struct{}{}
 		}
 	}
+	if s.diff != nil && prev == nil {
+		s.diff.loadAccount(addr, nil)
+	}
 	newobj = newObject(s, addr, types.StateAccount{})
+	newobj.created = true
 	if prev == nil {
 		s.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -747,6 +837,9 @@ func (s *StateDB) Copy() *StateDB {
 	if s.prefetcher != nil {
 		state.prefetcher = s.prefetcher.copy()
 	}
+	if s.diff != nil {
+		state.diff = s.diff.copy()
+	}
 	if s.snap != nil {
 		// In order for the miner to be able to use and make additions
 		// to the snapshot tree, we need to copy that aswell.
@@ -803,6 +896,39 @@ func (s *StateDB) GetRefund() uint64 {
 	return s.refund
 }
 
+// RecordStateDiff starts recording the accounts and storage slots modified in
+// the state, which are returned by StateDiff.
+func (s *StateDB) RecordStateDiff() {
+	s.diff = newStateDiffRecorder()
+}
+
+// StateDiff returns the changes to the accounts and storage slots written to
+// the tries by IntermediateRoot or Commit since RecordStateDiff was called, or
+// nil if the changes are not recorded.
+func (s *StateDB) StateDiff() *types.StateDiff {
+	if s.diff == nil {
+		return nil
+	}
+	return s.diff.diff()
+}
+
+// DirtyAccounts returns the accounts modified since the last call to Finalise, along with
+// the keys of their modified storage slots. Slots may have been reverted to their prior value.
+func (s *StateDB) DirtyAccounts() map[common.Address][]common.Hash {
+	dirties := make(map[common.Address][]common.Hash, len(s.journal.dirties))
+	for addr := range s.journal.dirties {
+		var keys []common.Hash
+		if obj, exist := s.stateObjects[addr]; exist {
+			keys = make([]common.Hash, 0, len(obj.dirtyStorage))
+			for key := range obj.dirtyStorage {
+				keys = append(keys, key)
+			}
+		}
+		dirties[addr] = keys
+	}
+	return dirties
+}
+
 // Finalise finalises the state by removing the destructed objects and clears
 // the journal as well as the refunds. Finalise, however, will not push any updates
 // into the tries just yet. Only IntermediateRoot or Commit will do that.
@@ -834,6 +960,7 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		} else {
 			obj.finalise(true) // Prefetch slots in the background
 		}
+		obj.created = false
 		s.stateObjectsPending[addr] = struct{}{}
 		s.stateObjectsDirty[addr] = struct{}{}
 
@@ -1023,14 +1150,8 @@ func (s *StateDB) commit(deleteEmptyObjects bool, snaps *snapshot.Tree, blockHas
 		}
 		s.snap, s.snapDestructs, s.snapAccounts, s.snapStorage = nil, nil, nil, nil
 	}
-	if referenceRoot {
-		if err := s.db.TrieDB().UpdateAndReferenceRoot(nodes, root); err != nil {
-			return common.Hash{}, err
-		}
-	} else {
-		if err := s.db.TrieDB().Update(nodes); err != nil {
-			return common.Hash{}, err
-		}
+	if err := s.db.TrieDB().UpdateLayer(root, s.originalRoot, nodes, referenceRoot); err != nil {
+		return common.Hash{}, err
 	}
 	s.originalRoot = root
 	if metrics.EnabledExpensive {
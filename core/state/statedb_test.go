@@ -311,6 +311,12 @@ func newTestAction(addr common.Address, r *rand.Rand) testAction {
 				s.Suicide(addr)
 			},
 		},
+		{
+			name: "Selfdestruct6780",
+			fn: func(a testAction, s *StateDB) {
+				s.Selfdestruct6780(addr)
+			},
+		},
 		{
 			name: "AddRefund",
 			fn: func(a testAction, s *StateDB) {
@@ -497,6 +503,31 @@ func TestTouchDelete(t *testing.T) {
 	}
 }
 
+// TestStateReads tests that only the accounts and storage slots loaded from the database
+// are counted as state reads, and that copies count their own reads.
+func TestStateReads(t *testing.T) {
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(common.Hash{}, db, nil)
+	addr := common.HexToAddress("aaaa")
+	state.SetBalance(addr, big.NewInt(42))
+	state.SetState(addr, common.HexToHash("01"), common.HexToHash("02"))
+	root, _ := state.Commit(false, false)
+
+	state, _ = New(root, db, nil)
+	for i := 0; i < 2; i++ {
+		state.GetBalance(addr)
+		state.GetState(addr, common.HexToHash("01"))
+		state.GetState(addr, common.HexToHash("03"))
+	}
+	state.GetBalance(common.HexToAddress("bbbb"))
+	if accounts, storage := state.StateReads(); accounts != 2 || storage != 2 {
+		t.Fatalf("wrong state reads: got %d accounts and %d storage slots, want 2 and 2", accounts, storage)
+	}
+	if accounts, storage := state.Copy().StateReads(); accounts != 0 || storage != 0 {
+		t.Fatalf("wrong state reads of copy: got %d accounts and %d storage slots, want 0 and 0", accounts, storage)
+	}
+}
+
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/ethereum/go-ethereum/pull/15225#issuecomment-380191512
 func TestCopyOfCopy(t *testing.T) {
@@ -704,6 +735,54 @@ func TestDeleteCreateRevert(t *testing.T) {
 	}
 }
 
+// TestSelfdestruct6780 tests that EIP-6780 self-destructs only delete accounts
+// created in the same transaction, and that the deletion reaches the snapshot.
+func TestSelfdestruct6780(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	existing := common.BytesToAddress([]byte("existing"))
+	state.SetBalance(existing, big.NewInt(1))
+	state.SetState(existing, common.Hash{1}, common.Hash{1})
+
+	root, _ := state.Commit(false, false)
+	state, _ = NewWithSnapshot(root, state.db, state.snap)
+
+	// An account that existed before the transaction survives
+	state.Selfdestruct6780(existing)
+	if state.HasSuicided(existing) {
+		t.Fatalf("pre-existing account self-destructed")
+	}
+	// An account created in the transaction is destroyed
+	created := common.BytesToAddress([]byte("created"))
+	state.CreateAccount(created)
+	state.SetCode(created, []byte{0x00})
+	state.Selfdestruct6780(created)
+	if !state.HasSuicided(created) {
+		t.Fatalf("account created in the transaction did not self-destruct")
+	}
+	// An account created in a previous transaction of the block survives
+	later := common.BytesToAddress([]byte("later"))
+	state.CreateAccount(later)
+	state.SetCode(later, []byte{0x00})
+	state.Finalise(true)
+	state.Selfdestruct6780(later)
+	if state.HasSuicided(later) {
+		t.Fatalf("account created in a previous transaction self-destructed")
+	}
+
+	root, _ = state.Commit(true, false)
+	state, _ = NewWithSnapshot(root, state.db, state.snap)
+	if state.GetState(existing, common.Hash{1}) != (common.Hash{1}) {
+		t.Fatalf("pre-existing account lost its storage")
+	}
+	if state.Exist(created) {
+		t.Fatalf("self-destructed account came alive")
+	}
+	if !state.Exist(later) {
+		t.Fatalf("account created in a previous transaction was deleted")
+	}
+}
+
 // TestMissingTrieNodes tests that if the StateDB fails to load parts of the trie,
 // the Commit operation fails with an error
 // If we are missing trie nodes, we should not continue writing to the trie
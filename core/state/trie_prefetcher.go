@@ -49,6 +49,7 @@ type triePrefetcher struct {
 	root     common.Hash            // Root hash of the account trie for metrics
 	fetches  map[string]Trie        // Partially or fully fetcher tries
 	fetchers map[string]*subfetcher // Subfetchers for each trie
+	workers  chan struct{}          // Slots bounding the number of subfetchers loading concurrently (unbounded if nil)
 
 	deliveryCopyMissMeter    metrics.Meter
 	deliveryRequestMissMeter metrics.Meter
@@ -64,7 +65,10 @@ type triePrefetcher struct {
 	storageWasteMeter metrics.Meter
 }
 
-func newTriePrefetcher(db Database, root common.Hash, namespace string) *triePrefetcher {
+// newTriePrefetcher creates an active prefetcher of the tries under [root]. At
+// most [maxConcurrency] tries are loaded concurrently, or any number of them if
+// [maxConcurrency] is not positive.
+func newTriePrefetcher(db Database, root common.Hash, namespace string, maxConcurrency int) *triePrefetcher {
 	prefix := triePrefetchMetricsPrefix + namespace
 	p := &triePrefetcher{
 		db:       db,
@@ -84,6 +88,9 @@ func newTriePrefetcher(db Database, root common.Hash, namespace string) *triePre
 		storageSkipMeter:  metrics.GetOrRegisterMeter(prefix+"/storage/skip", nil),
 		storageWasteMeter: metrics.GetOrRegisterMeter(prefix+"/storage/waste", nil),
 	}
+	if maxConcurrency > 0 {
+		p.workers = make(chan struct{}, maxConcurrency)
+	}
 	return p
 }
 
@@ -166,7 +173,7 @@ func (p *triePrefetcher) prefetch(owner common.Hash, root common.Hash, keys [][]
 	id := p.trieID(owner, root)
 	fetcher := p.fetchers[id]
 	if fetcher == nil {
-		fetcher = newSubfetcher(p.db, owner, root)
+		fetcher = newSubfetcher(p.db, owner, root, p.workers)
 		p.fetchers[id] = fetcher
 	}
 	fetcher.schedule(keys)
@@ -226,8 +233,9 @@ type subfetcher struct {
 	root  common.Hash // Root hash of the trie to prefetch
 	trie  Trie        // Trie being populated with nodes
 
-	tasks [][]byte   // Items queued up for retrieval
-	lock  sync.Mutex // Lock protecting the task queue
+	tasks   [][]byte      // Items queued up for retrieval
+	lock    sync.Mutex    // Lock protecting the task queue
+	workers chan struct{} // Slots shared with the other subfetchers of the prefetcher (unbounded if nil)
 
 	wake chan struct{}  // Wake channel if a new task is scheduled
 	stop chan struct{}  // Channel to interrupt processing
@@ -241,16 +249,17 @@ type subfetcher struct {
 
 // newSubfetcher creates a goroutine to prefetch state items belonging to a
 // particular root hash.
-func newSubfetcher(db Database, owner common.Hash, root common.Hash) *subfetcher {
+func newSubfetcher(db Database, owner common.Hash, root common.Hash, workers chan struct{}) *subfetcher {
 	sf := &subfetcher{
-		db:    db,
-		owner: owner,
-		root:  root,
-		wake:  make(chan struct{}, 1),
-		stop:  make(chan struct{}),
-		term:  make(chan struct{}),
-		copy:  make(chan chan Trie),
-		seen:  make(map[string]struct{}),
+		db:      db,
+		owner:   owner,
+		root:    root,
+		workers: workers,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		term:    make(chan struct{}),
+		copy:    make(chan chan Trie),
+		seen:    make(map[string]struct{}),
 	}
 	go sf.loop()
 	return sf
@@ -299,6 +308,35 @@ func (sf *subfetcher) abort() {
 	<-sf.term
 }
 
+// acquire waits for a worker slot to be available to load the scheduled tasks,
+// granting the copies of the trie requested meanwhile. It returns false if the
+// subfetcher is interrupted before a slot is acquired.
+func (sf *subfetcher) acquire() bool {
+	if sf.workers == nil {
+		return true
+	}
+	for {
+		select {
+		case sf.workers <- struct{}{}:
+			return true
+
+		case ch := <-sf.copy:
+			// Somebody wants a copy of the current trie, grant them
+			ch <- sf.db.CopyTrie(sf.trie)
+
+		case <-sf.stop:
+			return false
+		}
+	}
+}
+
+// release frees the worker slot acquired to load the scheduled tasks.
+func (sf *subfetcher) release() {
+	if sf.workers != nil {
+		<-sf.workers
+	}
+}
+
 // loop waits for new tasks to be scheduled and keeps loading them until it runs
 // out of tasks or its underlying trie is retrieved for committing.
 func (sf *subfetcher) loop() {
@@ -326,7 +364,11 @@ func (sf *subfetcher) loop() {
 	for {
 		select {
 		case <-sf.wake:
-			// Subfetcher was woken up, retrieve any tasks to avoid spinning the lock
+			// Subfetcher was woken up, wait for a worker slot to load the tasks
+			if !sf.acquire() {
+				return
+			}
+			// Retrieve any tasks to avoid spinning the lock
 			sf.lock.Lock()
 			tasks := sf.tasks
 			sf.tasks = nil
@@ -340,6 +382,7 @@ func (sf *subfetcher) loop() {
 					sf.lock.Lock()
 					sf.tasks = append(sf.tasks, tasks[i:]...)
 					sf.lock.Unlock()
+					sf.release()
 					return
 
 				case ch := <-sf.copy:
@@ -364,6 +407,7 @@ func (sf *subfetcher) loop() {
 					}
 				}
 			}
+			sf.release()
 
 		case ch := <-sf.copy:
 			// Somebody wants a copy of the current trie, grant them
@@ -55,7 +55,7 @@ func filledStateDB() *StateDB {
 
 func TestCopyAndClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 0)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, [][]byte{skey.Bytes()})
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, [][]byte{skey.Bytes()})
@@ -80,7 +80,7 @@ func TestCopyAndClose(t *testing.T) {
 
 func TestUseAfterClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 0)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, [][]byte{skey.Bytes()})
 	a := prefetcher.trie(common.Hash{}, db.originalRoot)
@@ -96,7 +96,7 @@ func TestUseAfterClose(t *testing.T) {
 
 func TestCopyClose(t *testing.T) {
 	db := filledStateDB()
-	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "")
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot, "", 0)
 	skey := common.HexToHash("aaa")
 	prefetcher.prefetch(common.Hash{}, db.originalRoot, [][]byte{skey.Bytes()})
 	cpy := prefetcher.copy()
@@ -118,3 +118,31 @@ func TestCopyClose(t *testing.T) {
 		t.Fatal("Copy trie should not return nil")
 	}
 }
+
+func TestPrefetcherConcurrencyLimit(t *testing.T) {
+	db := filledStateDB()
+	root, err := db.Commit(false, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	addr := common.HexToAddress("0xaffeaffeaffeaffeaffeaffeaffeaffeaffeaffe")
+	obj := db.getStateObject(addr)
+
+	// A single worker slot is shared by the account and storage trie subfetchers
+	prefetcher := newTriePrefetcher(db.db, root, "", 1)
+	prefetcher.prefetch(common.Hash{}, root, [][]byte{addr.Bytes()})
+	for i := 0; i < 100; i++ {
+		sk := common.BigToHash(big.NewInt(int64(i)))
+		prefetcher.prefetch(obj.addrHash, obj.data.Root, [][]byte{sk.Bytes()})
+	}
+	if tr := prefetcher.trie(common.Hash{}, root); tr == nil || tr.Hash() != root {
+		t.Fatalf("account trie not prefetched")
+	}
+	if tr := prefetcher.trie(obj.addrHash, obj.data.Root); tr == nil || tr.Hash() != obj.data.Root {
+		t.Fatalf("storage trie not prefetched")
+	}
+	prefetcher.close()
+	if n := len(prefetcher.workers); n != 0 {
+		t.Fatalf("worker slots not released: %d", n)
+	}
+}
@@ -0,0 +1,122 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDiffArchive(t *testing.T) {
+	var (
+		key1, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1    = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2    = common.Address{0x02}
+		contract = common.Address{0xcc}
+		// PUSH1 0x2a PUSH1 0x01 SSTORE
+		code = []byte{0x60, 0x2a, 0x60, 0x01, 0x55}
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			StateDiffArchive:      true,
+			StateDiffRetention:    2,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: GenesisAlloc{
+			addr1: {Balance: big.NewInt(1000000)},
+			contract: {
+				Code:    code,
+				Storage: map[common.Hash]common.Hash{common.BigToHash(common.Big1): common.BigToHash(common.Big2)},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	// Block 1 funds a new account and blocks 2 and 3 call the contract, of which only the first
+	// call modifies its storage.
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 3, 10, func(i int, gen *BlockGen) {
+		var tx *types.Transaction
+		if i == 0 {
+			tx = types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil)
+		} else {
+			tx = types.NewTransaction(gen.TxNonce(addr1), contract, common.Big0, 100000, nil, nil)
+		}
+		signed, err := types.SignTx(tx, signer, key1)
+		require.NoError(t, err)
+		gen.AddTx(signed)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+
+	findAccount := func(diff *types.StateDiff, addr common.Address) *types.AccountDiff {
+		require.NotNil(t, diff)
+		for _, account := range diff.Accounts {
+			if account.Address == addr {
+				return account
+			}
+		}
+		return nil
+	}
+
+	// The diffs are available as soon as the blocks are inserted
+	diff := blockchain.GetStateDiff(chain[0].Hash())
+	sender := findAccount(diff, addr1)
+	require.NotNil(t, sender)
+	require.EqualValues(t, 0, sender.Pre.Nonce)
+	require.EqualValues(t, 1, sender.Post.Nonce)
+	require.Equal(t, big.NewInt(1000000), sender.Pre.Balance)
+	require.Equal(t, big.NewInt(990000), sender.Post.Balance)
+	recipient := findAccount(diff, addr2)
+	require.NotNil(t, recipient)
+	require.Nil(t, recipient.Pre)
+	require.Equal(t, big.NewInt(10000), recipient.Post.Balance)
+	require.Nil(t, findAccount(diff, contract))
+
+	diff = blockchain.GetStateDiff(chain[1].Hash())
+	updated := findAccount(diff, contract)
+	require.NotNil(t, updated)
+	require.Equal(t, updated.Pre, updated.Post)
+	require.Equal(t, []*types.StorageDiff{{Key: common.BigToHash(common.Big1), Pre: common.BigToHash(common.Big2), Post: common.BigToHash(big.NewInt(0x2a))}}, updated.Storage)
+
+	// Writing the same value to a slot is not a change
+	diff = blockchain.GetStateDiff(chain[2].Hash())
+	require.Nil(t, findAccount(diff, contract))
+	require.NotNil(t, findAccount(diff, addr1))
+
+	// Only the diffs of the last two accepted blocks are retained
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	require.Nil(t, blockchain.GetStateDiff(chain[0].Hash()))
+	require.NotNil(t, blockchain.GetStateDiff(chain[1].Hash()))
+	require.NotNil(t, blockchain.GetStateDiff(chain[2].Hash()))
+}
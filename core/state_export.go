@@ -0,0 +1,415 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state/snapshot"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// stateExportVersion is the version of the format written by [ExportState].
+const stateExportVersion = 1
+
+// Kinds of the entries following the header of a state export.
+const (
+	stateExportAccount uint8 = iota // Key is the account hash, Value the account as stored in the trie
+	stateExportCode                 // Key is the code hash, Value the code
+	stateExportStorage              // Key is the slot hash, Value the slot as stored in the trie, of the preceding account
+)
+
+var (
+	errStateExportAborted   = errors.New("state export aborted")
+	errStateExportMalformed = errors.New("malformed state export")
+)
+
+// stateExportHeader is the first item of a state export.
+type stateExportHeader struct {
+	Version uint64
+	Genesis common.Hash
+	Block   *types.Block
+}
+
+// stateExportEntry is an item of a state export following its header.
+type stateExportEntry struct {
+	Kind  uint8
+	Key   common.Hash
+	Value []byte
+}
+
+// StateExportStats summarizes a state export.
+type StateExportStats struct {
+	Number   uint64      `json:"number"`
+	Hash     common.Hash `json:"hash"`
+	Root     common.Hash `json:"root"`
+	Accounts uint64      `json:"accounts"`
+	Slots    uint64      `json:"slots"`
+	Codes    uint64      `json:"codes"`
+}
+
+// ExportState writes the full state at the accepted block [number] to [w] as a
+// gzip compressed stream of RLP items: a header holding the block, followed by
+// the accounts in the order of their hashes, each followed by its code (the
+// first time the code is exported) and by its storage slots in the order of
+// their hashes. The state of the stateful precompiles is kept in the storage of
+// their accounts, so it is exported as well.
+//
+// The state must be available in the trie database, and is kept available
+// until the export completes. The export is aborted if the chain is stopped.
+func (bc *BlockChain) ExportState(w io.Writer, number uint64) (*StateExportStats, error) {
+	block, err := bc.referenceExportedState(number)
+	if err != nil {
+		return nil, err
+	}
+	defer bc.stateCache.TrieDB().Dereference(block.Root())
+	return bc.exportState(w, block)
+}
+
+// ExportStateToFile exports the state at the accepted block [number] to the
+// file at [path] as [ExportState] does. The file is only created once the
+// export completes.
+func (bc *BlockChain) ExportStateToFile(path string, number uint64) (*StateExportStats, error) {
+	block, err := bc.referenceExportedState(number)
+	if err != nil {
+		return nil, err
+	}
+	defer bc.stateCache.TrieDB().Dereference(block.Root())
+	return bc.exportStateToFile(path, block)
+}
+
+// referenceExportedState returns the accepted block [number], referencing its
+// state root so that the state is not dropped from memory while it is exported.
+func (bc *BlockChain) referenceExportedState(number uint64) (*types.Block, error) {
+	if lastAccepted := bc.LastAcceptedBlock().NumberU64(); number > lastAccepted {
+		return nil, fmt.Errorf("cannot export the state of block %d after the last accepted block %d", number, lastAccepted)
+	}
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	bc.stateCache.TrieDB().Reference(block.Root(), common.Hash{})
+	return block, nil
+}
+
+// exportState writes the state of [block] to [w]. It is assumed the caller
+// holds a reference to the state root of [block] in the trie database.
+func (bc *BlockChain) exportState(w io.Writer, block *types.Block) (*StateExportStats, error) {
+	var (
+		number = block.NumberU64()
+		root   = block.Root()
+	)
+	if !bc.HasState(root) {
+		return nil, fmt.Errorf("state %s of block %d is not available", root, number)
+	}
+
+	var (
+		start = time.Now()
+		gz    = gzip.NewWriter(w)
+		stats = &StateExportStats{Number: number, Hash: block.Hash(), Root: root}
+		codes = make(map[common.Hash]struct{})
+	)
+	header := &stateExportHeader{
+		Version: stateExportVersion,
+		Genesis: bc.genesisBlock.Hash(),
+		Block:   block,
+	}
+	if err := rlp.Encode(gz, header); err != nil {
+		return nil, err
+	}
+	accTrie, err := bc.stateCache.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(nil))
+	for accIt.Next() {
+		select {
+		case <-bc.quit:
+			return nil, errStateExportAborted
+		default:
+		}
+		var (
+			accHash = common.BytesToHash(accIt.Key)
+			acc     types.StateAccount
+		)
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			return nil, fmt.Errorf("failed to decode account %s: %w", accHash, err)
+		}
+		if err := rlp.Encode(gz, &stateExportEntry{Kind: stateExportAccount, Key: accHash, Value: accIt.Value}); err != nil {
+			return nil, err
+		}
+		stats.Accounts++
+
+		codeHash := common.BytesToHash(acc.CodeHash)
+		if _, ok := codes[codeHash]; !ok && codeHash != emptyCodeHash {
+			code, err := bc.stateCache.ContractCode(accHash, codeHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read code %s of account %s: %w", codeHash, accHash, err)
+			}
+			if err := rlp.Encode(gz, &stateExportEntry{Kind: stateExportCode, Key: codeHash, Value: code}); err != nil {
+				return nil, err
+			}
+			codes[codeHash] = struct{}{}
+			stats.Codes++
+		}
+
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+		storageTrie, err := bc.stateCache.OpenStorageTrie(accHash, acc.Root)
+		if err != nil {
+			return nil, err
+		}
+		storageIt := trie.NewIterator(storageTrie.NodeIterator(nil))
+		for storageIt.Next() {
+			if err := rlp.Encode(gz, &stateExportEntry{Kind: stateExportStorage, Key: common.BytesToHash(storageIt.Key), Value: storageIt.Value}); err != nil {
+				return nil, err
+			}
+			stats.Slots++
+		}
+		if storageIt.Err != nil {
+			return nil, fmt.Errorf("failed to iterate storage of account %s: %w", accHash, storageIt.Err)
+		}
+	}
+	if accIt.Err != nil {
+		return nil, fmt.Errorf("failed to iterate accounts: %w", accIt.Err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	log.Info("Exported state", "number", number, "hash", block.Hash(), "root", root, "accounts", stats.Accounts,
+		"slots", stats.Slots, "codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// exportStatePeriodically starts exporting the state of [block] to a file in
+// the [StateExportDirectory] in the background, unless the previous export is
+// still running.
+//
+// Assumes [block] was just processed by the acceptor, so its state is still
+// available.
+func (bc *BlockChain) exportStatePeriodically(block *types.Block) {
+	if !atomic.CompareAndSwapInt32(&bc.stateExporting, 0, 1) {
+		log.Warn("Skipping periodic state export while the previous export is running", "number", block.NumberU64())
+		return
+	}
+	triedb := bc.stateCache.TrieDB()
+	triedb.Reference(block.Root(), common.Hash{})
+
+	bc.stateExportWg.Add(1)
+	go func() {
+		defer bc.stateExportWg.Done()
+		defer atomic.StoreInt32(&bc.stateExporting, 0)
+		defer triedb.Dereference(block.Root())
+
+		path := filepath.Join(bc.cacheConfig.StateExportDirectory, fmt.Sprintf("state-%d.rlp.gz", block.NumberU64()))
+		if _, err := bc.exportStateToFile(path, block); err != nil {
+			log.Error("Failed to export state", "number", block.NumberU64(), "path", path, "err", err)
+		}
+	}()
+}
+
+// exportStateToFile exports the state of [block] to the file at [path]. The
+// file is only created once the export completes.
+func (bc *BlockChain) exportStateToFile(path string, block *types.Block) (*StateExportStats, error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := bc.exportState(f, block)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return stats, os.Rename(tmp, path)
+}
+
+// ImportState initializes [db] with the block and the state read from [r] in
+// the format written by [ExportState], and marks the block as the last accepted
+// one (similarly to state sync). The tries are rebuilt from the exported items
+// and checked against the roots of the block, so a corrupted or tampered export
+// is rejected. [genesis] is the hash of the genesis block of the chain that the
+// state must belong to.
+//
+// The trie nodes are written with the hash scheme.
+func ImportState(db ethdb.Database, r io.Reader, genesis common.Hash) (*types.Block, *StateExportStats, error) {
+	if scheme := rawdb.ReadStateScheme(db); scheme != "" && scheme != rawdb.HashScheme {
+		return nil, nil, fmt.Errorf("cannot import state into a database using the %s state scheme", scheme)
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gz.Close()
+
+	var (
+		start  = time.Now()
+		stream = rlp.NewStream(gz, 0)
+		header stateExportHeader
+	)
+	if err := stream.Decode(&header); err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to decode header: %v", errStateExportMalformed, err)
+	}
+	if header.Version != stateExportVersion {
+		return nil, nil, fmt.Errorf("unsupported state export version %d, expected %d", header.Version, stateExportVersion)
+	}
+	if header.Genesis != genesis {
+		return nil, nil, fmt.Errorf("state export belongs to the chain with genesis %s, expected %s", header.Genesis, genesis)
+	}
+	block := header.Block
+	if block == nil || block.NumberU64() == 0 {
+		return nil, nil, fmt.Errorf("%w: missing block", errStateExportMalformed)
+	}
+
+	var (
+		batch       = db.NewBatch()
+		stats       = &StateExportStats{Number: block.NumberU64(), Hash: block.Hash(), Root: block.Root()}
+		accTrie     = trie.NewStackTrie(batch)
+		storageTrie *trie.StackTrie
+		codes       = make(map[common.Hash]struct{})
+		lastAccount []byte
+		lastSlot    []byte
+		account     common.Hash
+		acc         types.StateAccount
+	)
+	// finishAccount checks the storage and the code of the last imported account.
+	finishAccount := func() error {
+		if lastAccount == nil {
+			return nil
+		}
+		root := types.EmptyRootHash
+		if storageTrie != nil {
+			var err error
+			if root, err = storageTrie.Commit(); err != nil {
+				return err
+			}
+		}
+		if root != acc.Root {
+			return fmt.Errorf("%w: storage root mismatch for account %s: have %s, want %s", errStateExportMalformed, account, root, acc.Root)
+		}
+		codeHash := common.BytesToHash(acc.CodeHash)
+		if _, ok := codes[codeHash]; !ok && codeHash != emptyCodeHash {
+			return fmt.Errorf("%w: missing code %s of account %s", errStateExportMalformed, codeHash, account)
+		}
+		return nil
+	}
+	for {
+		var entry stateExportEntry
+		if err := stream.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errStateExportMalformed, err)
+		}
+		switch entry.Kind {
+		case stateExportAccount:
+			if err := finishAccount(); err != nil {
+				return nil, nil, err
+			}
+			if lastAccount != nil && bytes.Compare(entry.Key[:], lastAccount) <= 0 {
+				return nil, nil, fmt.Errorf("%w: account %s out of order", errStateExportMalformed, entry.Key)
+			}
+			account, acc = entry.Key, types.StateAccount{}
+			if err := rlp.DecodeBytes(entry.Value, &acc); err != nil {
+				return nil, nil, fmt.Errorf("%w: failed to decode account %s: %v", errStateExportMalformed, account, err)
+			}
+			if err := accTrie.TryUpdate(account[:], entry.Value); err != nil {
+				return nil, nil, err
+			}
+			rawdb.WriteAccountSnapshot(batch, account, snapshot.SlimAccountRLP(acc.Nonce, acc.Balance, acc.Root, acc.CodeHash))
+			lastAccount, lastSlot, storageTrie = common.CopyBytes(account[:]), nil, nil
+			stats.Accounts++
+
+		case stateExportCode:
+			if crypto.Keccak256Hash(entry.Value) != entry.Key {
+				return nil, nil, fmt.Errorf("%w: code hash mismatch for %s", errStateExportMalformed, entry.Key)
+			}
+			rawdb.WriteCode(batch, entry.Key, entry.Value)
+			codes[entry.Key] = struct{}{}
+			stats.Codes++
+
+		case stateExportStorage:
+			if lastAccount == nil {
+				return nil, nil, fmt.Errorf("%w: storage slot %s before any account", errStateExportMalformed, entry.Key)
+			}
+			if lastSlot != nil && bytes.Compare(entry.Key[:], lastSlot) <= 0 {
+				return nil, nil, fmt.Errorf("%w: storage slot %s of account %s out of order", errStateExportMalformed, entry.Key, account)
+			}
+			if storageTrie == nil {
+				storageTrie = trie.NewStackTrie(batch)
+			}
+			if err := storageTrie.TryUpdate(entry.Key[:], entry.Value); err != nil {
+				return nil, nil, err
+			}
+			rawdb.WriteStorageSnapshot(batch, account, entry.Key, entry.Value)
+			lastSlot = common.CopyBytes(entry.Key[:])
+			stats.Slots++
+
+		default:
+			return nil, nil, fmt.Errorf("%w: unknown entry kind %d", errStateExportMalformed, entry.Kind)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, nil, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := finishAccount(); err != nil {
+		return nil, nil, err
+	}
+	root, err := accTrie.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	if root != block.Root() {
+		return nil, nil, fmt.Errorf("%w: state root mismatch: have %s, want %s", errStateExportMalformed, root, block.Root())
+	}
+
+	// Write the block and mark it as the head of the chain, as state sync does
+	rawdb.WriteBlock(batch, block)
+	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+	rawdb.WriteAcceptorTip(batch, block.Hash())
+	rawdb.WriteHeadBlockHash(batch, block.Hash())
+	rawdb.WriteHeadHeaderHash(batch, block.Hash())
+	rawdb.WriteSnapshotBlockHash(batch, block.Hash())
+	rawdb.WriteSnapshotRoot(batch, block.Root())
+	if err := rawdb.WriteSyncPerformed(batch, block.NumberU64()); err != nil {
+		return nil, nil, err
+	}
+	// The snapshot was written along with the state, unless the database held a
+	// snapshot already, whose stale entries must be wiped.
+	if rawdb.ReadSnapshotRoot(db) == (common.Hash{}) {
+		snapshot.ResetSnapshotGeneration(batch)
+	} else {
+		snapshot.ScheduleSnapshotRegeneration(batch)
+	}
+	if rawdb.ReadStateScheme(db) == "" {
+		rawdb.WriteStateScheme(batch, rawdb.HashScheme)
+	}
+	if err := batch.Write(); err != nil {
+		return nil, nil, err
+	}
+	log.Info("Imported state", "number", block.NumberU64(), "hash", block.Hash(), "root", root, "accounts", stats.Accounts,
+		"slots", stats.Slots, "codes", stats.Codes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return block, stats, nil
+}
@@ -0,0 +1,195 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportState(t *testing.T) {
+	var (
+		key1, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1    = crypto.PubkeyToAddress(key1.PublicKey)
+		contract = common.Address{0xcc}
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB     = rawdb.NewMemoryDatabase()
+		chainDB   = rawdb.NewMemoryDatabase()
+		exportDir = t.TempDir()
+		config    = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			StateExportInterval:   10,
+			StateExportDirectory:  exportDir,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: GenesisAlloc{
+			addr1: {Balance: big.NewInt(1000000)},
+			contract: {
+				Code:    []byte{0x60, 0x00},
+				Storage: map[common.Hash]common.Hash{{0x01}: {0x02}, {0x03}: {0x04}},
+			},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 12, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+	blockchain.stateExportWg.Wait()
+
+	var exported bytes.Buffer
+	stats, err := blockchain.ExportState(&exported, 12)
+	require.NoError(t, err)
+	require.Equal(t, chain[11].Root(), stats.Root)
+	require.EqualValues(t, 1, stats.Codes)
+	require.EqualValues(t, 2, stats.Slots)
+
+	_, err = blockchain.ExportState(io.Discard, 13)
+	require.Error(t, err)
+	blockchain.Stop()
+
+	// The state is exported periodically in the background
+	periodic, err := os.Open(filepath.Join(exportDir, "state-10.rlp.gz"))
+	require.NoError(t, err)
+	defer periodic.Close()
+	block, stats, err := ImportState(rawdb.NewMemoryDatabase(), periodic, genesis.Hash())
+	require.NoError(t, err)
+	require.Equal(t, chain[9].Hash(), block.Hash())
+	require.EqualValues(t, 10, stats.Number)
+
+	// A new node initialized from the export continues the chain
+	importDB := rawdb.NewMemoryDatabase()
+	_ = gspec.MustCommit(importDB)
+	block, _, err = ImportState(importDB, bytes.NewReader(exported.Bytes()), genesis.Hash())
+	require.NoError(t, err)
+	require.Equal(t, chain[11].Hash(), block.Hash())
+
+	imported, err := createBlockChain(importDB, config, gspec.Config, block.Hash())
+	require.NoError(t, err)
+	defer imported.Stop()
+	state, err := imported.State()
+	require.NoError(t, err)
+	require.EqualValues(t, 12, state.GetNonce(addr1))
+	require.Equal(t, []byte{0x60, 0x00}, state.GetCode(contract))
+	require.Equal(t, common.Hash{0x04}, state.GetState(contract, common.Hash{0x03}))
+
+	// The export cannot be imported into another chain
+	_, _, err = ImportState(rawdb.NewMemoryDatabase(), bytes.NewReader(exported.Bytes()), common.Hash{0x01})
+	require.Error(t, err)
+}
+
+func TestImportStateRejectsTamperedState(t *testing.T) {
+	var (
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		addr1   = common.Address{0x01}
+		addr2   = common.Address{0x02}
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: GenesisAlloc{
+			addr1: {Balance: big.NewInt(1000000)},
+			addr2: {Balance: big.NewInt(1000000)},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+	blockchain, err := createBlockChain(chainDB, DefaultCacheConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 1, 10, func(int, *BlockGen) {})
+	require.NoError(t, err)
+
+	// Export the genesis state under the next block, whose state root is identical
+	export := func(entries ...*stateExportEntry) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		require.NoError(t, rlp.Encode(gz, &stateExportHeader{Version: stateExportVersion, Genesis: genesis.Hash(), Block: chain[0]}))
+		for _, entry := range entries {
+			require.NoError(t, rlp.Encode(gz, entry))
+		}
+		require.NoError(t, gz.Close())
+		return buf.Bytes()
+	}
+	account := func(balance int64) []byte {
+		acc, err := rlp.EncodeToBytes(&types.StateAccount{Balance: big.NewInt(balance), Root: types.EmptyRootHash, CodeHash: emptyCodeHash[:]})
+		require.NoError(t, err)
+		return acc
+	}
+	hash1, hash2 := crypto.Keccak256Hash(addr1[:]), crypto.Keccak256Hash(addr2[:])
+	if bytes.Compare(hash1[:], hash2[:]) > 0 {
+		hash1, hash2 = hash2, hash1
+	}
+
+	_, _, err = ImportState(rawdb.NewMemoryDatabase(), bytes.NewReader(export(
+		&stateExportEntry{Kind: stateExportAccount, Key: hash1, Value: account(1000000)},
+		&stateExportEntry{Kind: stateExportAccount, Key: hash2, Value: account(1000000)},
+	)), genesis.Hash())
+	require.NoError(t, err)
+
+	tests := map[string][]*stateExportEntry{
+		"modified balance": {
+			{Kind: stateExportAccount, Key: hash1, Value: account(1000000)},
+			{Kind: stateExportAccount, Key: hash2, Value: account(2000000)},
+		},
+		"missing account": {
+			{Kind: stateExportAccount, Key: hash1, Value: account(1000000)},
+		},
+		"accounts out of order": {
+			{Kind: stateExportAccount, Key: hash2, Value: account(1000000)},
+			{Kind: stateExportAccount, Key: hash1, Value: account(1000000)},
+		},
+		"unexpected storage": {
+			{Kind: stateExportAccount, Key: hash1, Value: account(1000000)},
+			{Kind: stateExportStorage, Key: common.Hash{0x01}, Value: []byte{0x01}},
+			{Kind: stateExportAccount, Key: hash2, Value: account(1000000)},
+		},
+		"invalid code": {
+			{Kind: stateExportCode, Key: common.Hash{0x01}, Value: []byte{0x01}},
+		},
+	}
+	for name, entries := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ImportState(rawdb.NewMemoryDatabase(), bytes.NewReader(export(entries...)), genesis.Hash())
+			require.ErrorIs(t, err, errStateExportMalformed)
+		})
+	}
+}
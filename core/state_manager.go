@@ -31,6 +31,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethdb"
 	"github.com/ethereum/go-ethereum/common"
@@ -72,6 +73,15 @@ type TrieDB interface {
 }
 
 func NewTrieWriter(db TrieDB, config *CacheConfig) TrieWriter {
+	if config.stateScheme() == rawdb.PathScheme {
+		pw := &pathTrieWriter{
+			TrieDB:         db,
+			memoryCap:      common.StorageSize(config.TrieDirtyLimit) * 1024 * 1024,
+			commitInterval: config.CommitInterval,
+		}
+		pw.tipBuffer = NewBoundedBuffer(tipBufferSize, pw.evict)
+		return pw
+	}
 	if config.Pruning {
 		cm := &cappedMemoryTrieWriter{
 			TrieDB:           db,
@@ -201,3 +211,62 @@ func (cm *cappedMemoryTrieWriter) Shutdown() error {
 	// re-processing the state on the next startup.
 	return cm.TrieDB.Commit(last, true, nil)
 }
+
+// pathTrieWriter writes the tries of accepted blocks to disk with the path
+// scheme, where only a single version of the state is kept on disk. The tries
+// of the last [tipBufferSize] accepted blocks are kept in memory so they can
+// still be queried, so the state on disk is moved up to the root evicted last
+// from [tipBuffer] at the [commitInterval], or whenever the tries in memory
+// exceed [memoryCap].
+type pathTrieWriter struct {
+	TrieDB
+	memoryCap      common.StorageSize
+	commitInterval uint64
+
+	tipBuffer *BoundedBuffer[common.Hash]
+	flushable common.Hash // Last root evicted from [tipBuffer]
+}
+
+// evict dereferences [root] once it is no longer kept at tip, leaving it to
+// be written to disk.
+func (pw *pathTrieWriter) evict(root common.Hash) {
+	pw.TrieDB.Dereference(root)
+	pw.flushable = root
+}
+
+func (pw *pathTrieWriter) InsertTrie(block *types.Block) error {
+	// The tries of processing blocks cannot be written to disk before they are
+	// accepted, so there is nothing to [Cap] here.
+	return nil
+}
+
+func (pw *pathTrieWriter) AcceptTrie(block *types.Block) error {
+	pw.tipBuffer.Insert(block.Root())
+
+	nodes, _ := pw.TrieDB.Size()
+	if block.NumberU64()%pw.commitInterval != 0 && nodes <= pw.memoryCap {
+		return nil
+	}
+	if pw.flushable == (common.Hash{}) {
+		return nil
+	}
+	if err := pw.TrieDB.Commit(pw.flushable, true, nil); err != nil {
+		return fmt.Errorf("failed to commit trie for block %s: %w", block.Hash().Hex(), err)
+	}
+	return nil
+}
+
+func (pw *pathTrieWriter) RejectTrie(block *types.Block) error {
+	pw.TrieDB.Dereference(block.Root())
+	return nil
+}
+
+func (pw *pathTrieWriter) Shutdown() error {
+	// Commit the last accepted root on shutdown to avoid re-processing the
+	// state on the next startup.
+	last, exists := pw.tipBuffer.Last()
+	if !exists {
+		return nil
+	}
+	return pw.TrieDB.Commit(last, true, nil)
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 )
 
 // statePrefetcher is a basic Prefetcher, which blindly executes a block on top
@@ -66,6 +67,26 @@ func (p *statePrefetcher) Prefetch(block *types.Block, statedb *state.StateDB, c
 		evm          = vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
 		signer       = types.MakeSigner(p.config, header.Number, new(big.Int).SetUint64(header.Time))
 	)
+	// Pre-load the accounts of the senders and recipients, and the precompile storage
+	// slots checked for each sender, as executing the transactions only reaches them
+	// while the previous transactions succeed.
+	deployerAllowList := p.config.IsContractDeployerAllowList(blockContext.BlockNumber, blockContext.Time)
+	for _, tx := range block.Transactions() {
+		if interrupt != nil && atomic.LoadUint32(interrupt) == 1 {
+			return
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return // Also invalid block, bail out
+		}
+		statedb.GetNonce(from)
+		_ = CheckSenderAllowed(p.config, blockContext, statedb, from)
+		if to := tx.To(); to != nil {
+			statedb.GetCodeHash(*to)
+		} else if deployerAllowList {
+			precompile.GetContractDeployerAllowListStatus(statedb, from)
+		}
+	}
 	// Iterate over and process the individual transactions
 	byzantium := p.config.IsByzantium(block.Number())
 	for i, tx := range block.Transactions() {
@@ -0,0 +1,92 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockPrefetch(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		// Pruning is disabled to keep the state of every block available
+		config = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               false,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			BlockPrefetch:         true,
+		}
+	)
+
+	// The senders are checked against the allow lists when processing the blocks
+	chainConfig := &params.ChainConfig{HomesteadBlock: new(big.Int)}
+	chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(
+		precompile.NewTxAllowListConfig(big.NewInt(0), nil, []common.Address{addr1}),
+		precompile.NewContractDeployerAllowListConfig(big.NewInt(0), nil, []common.Address{addr1}),
+	)
+	gspec := &Genesis{
+		Config: chainConfig,
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	// Each block transfers funds and deploys a contract
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 5, 10, func(i int, gen *BlockGen) {
+		transfer, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(transfer)
+		create, _ := types.SignTx(types.NewContractCreation(gen.TxNonce(addr1), common.Big0, 100000, nil, []byte{0x60, 0x00, 0x60, 0x00, 0xf3}), signer, key1)
+		gen.AddTx(create)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	statedb, err := blockchain.State()
+	require.NoError(t, err)
+	require.EqualValues(t, 10, statedb.GetNonce(addr1))
+	require.Equal(t, big.NewInt(50000), statedb.GetBalance(common.Address{1}))
+
+	// Prefetching executes the block on the throwaway state it is given
+	throwaway, err := state.New(chain[3].Root(), blockchain.stateCache, nil)
+	require.NoError(t, err)
+	blockchain.prefetcher.Prefetch(chain[4], throwaway, blockchain.vmConfig, nil)
+	require.EqualValues(t, 10, throwaway.GetNonce(addr1))
+
+	// An interrupted prefetch returns without executing the block
+	var interrupt uint32 = 1
+	throwaway, err = state.New(chain[3].Root(), blockchain.stateCache, nil)
+	require.NoError(t, err)
+	blockchain.prefetcher.Prefetch(chain[4], throwaway, blockchain.vmConfig, &interrupt)
+	require.EqualValues(t, 8, throwaway.GetNonce(addr1))
+}
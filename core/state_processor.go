@@ -77,8 +77,15 @@ func (p *StateProcessor) Process(block *types.Block, parent *types.Header, state
 		timestamp   = new(big.Int).SetUint64(header.Time)
 	)
 
-	// Configure any stateful precompiles that should go into effect during this block.
+	// Configure any stateful precompiles that should go into effect during this block, and apply its state patch.
 	p.config.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time), block, statedb)
+	if err := p.config.ApplyStatePatch(parent.Root, block, statedb); err != nil {
+		return nil, nil, 0, err
+	}
+	// Record the validator set of the subnet if this block starts a new epoch.
+	if err := ApplyValidatorSnapshot(p.config, header, statedb); err != nil {
+		return nil, nil, 0, fmt.Errorf("could not apply validator snapshot: %w", err)
+	}
 
 	blockContext := NewEVMBlockContext(header, p.bc, nil)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
@@ -27,18 +27,24 @@
 package core
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/consensus"
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/crypto/sha3"
 )
@@ -315,9 +321,7 @@ func TestBadTxAllowListBlock(t *testing.T) {
 			NetworkUpgrades: params.NetworkUpgrades{
 				SubnetEVMTimestamp: big.NewInt(0),
 			},
-			PrecompileUpgrade: params.PrecompileUpgrade{
-				TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(0), nil, nil),
-			},
+			PrecompileUpgrade: params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(0), nil, nil)),
 		}
 		signer     = types.LatestSigner(config)
 		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
@@ -372,6 +376,363 @@ func TestBadTxAllowListBlock(t *testing.T) {
 	}
 }
 
+// TestGasSponsoredTransaction tests that the gas of a transaction from a sponsored sender
+// is paid from the deposit of its sponsor instead of the sender's balance.
+func TestGasSponsoredTransaction(t *testing.T) {
+	var (
+		db          = rawdb.NewMemoryDatabase()
+		sponsorAddr = common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+		userKey, _  = crypto.GenerateKey()
+		userAddr    = crypto.PubkeyToAddress(userKey.PublicKey)
+		depositAmt  = big.NewInt(100000000000000000) // 0.1 ether
+		gasFeeCap   = big.NewInt(225000000000)
+
+		config = *params.TestChainConfig
+	)
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewGasSponsorConfig(big.NewInt(0), []common.Address{sponsorAddr}, nil))
+	var (
+		signer = types.LatestSigner(&config)
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: GenesisAlloc{
+				sponsorAddr: GenesisAccount{
+					Balance: big.NewInt(1000000000000000000), // 1 ether
+				},
+			},
+			GasLimit: config.FeeConfig.GasLimit.Uint64(),
+		}
+		genesis = gspec.MustCommit(db)
+	)
+
+	mkSponsorTx := func(nonce uint64, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: gasFeeCap,
+			Gas:       200_000,
+			To:        &precompile.GasSponsorAddress,
+			Data:      data,
+		}), signer, testKey)
+		return tx
+	}
+	depositInput, err := precompile.PackDeposit(depositAmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sponsorInput, err := precompile.PackSponsorSender(userAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userTx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		Nonce:     0,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: gasFeeCap,
+		Gas:       params.TxGas,
+		To:        &common.Address{1},
+	}), signer, userKey)
+
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewCoinbaseFaker(), db, 2, 10, func(i int, b *BlockGen) {
+		switch i {
+		case 0:
+			b.AddTx(mkSponsorTx(0, depositInput))
+			b.AddTx(mkSponsorTx(1, sponsorInput))
+		case 1:
+			b.AddTx(userTx)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockchain, _ := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{})
+	defer blockchain.Stop()
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	statedb, err := blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce := statedb.GetNonce(userAddr); nonce != 1 {
+		t.Fatalf("expected sponsored transaction to be applied, user nonce = %d", nonce)
+	}
+	if balance := statedb.GetBalance(userAddr); balance.Sign() != 0 {
+		t.Fatalf("expected user balance to be untouched, have %d", balance)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(params.TxGas), blocks[1].BaseFee())
+	expectedDeposit := new(big.Int).Sub(depositAmt, fee)
+	if deposit := precompile.GetSponsorDeposit(statedb, sponsorAddr); deposit.Cmp(expectedDeposit) != 0 {
+		t.Fatalf("expected sponsor deposit %d, have %d", expectedDeposit, deposit)
+	}
+	if balance := statedb.GetBalance(precompile.GasSponsorAddress); balance.Cmp(expectedDeposit) != 0 {
+		t.Fatalf("expected gas sponsor balance %d, have %d", expectedDeposit, balance)
+	}
+}
+
+// TestGasTokenTransaction tests that the gas of a transaction is paid in the gas token
+// while the gas token precompile is enabled, and that the fees are paid to the coinbase
+// in the gas token as well.
+func TestGasTokenTransaction(t *testing.T) {
+	var (
+		db          = rawdb.NewMemoryDatabase()
+		userKey, _  = crypto.GenerateKey()
+		userAddr    = crypto.PubkeyToAddress(userKey.PublicKey)
+		userBalance = big.NewInt(100000000000000000) // 0.1 ether
+		gasFeeCap   = big.NewInt(225000000000)
+
+		config = *params.TestChainConfig
+	)
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewGasTokenConfig(big.NewInt(0), nil, nil, "Gas Token", "GAS", 18,
+		map[common.Address]*math.HexOrDecimal256{userAddr: (*math.HexOrDecimal256)(userBalance)}))
+	var (
+		signer = types.LatestSigner(&config)
+		gspec  = &Genesis{
+			Config:   &config,
+			Alloc:    GenesisAlloc{},
+			GasLimit: config.FeeConfig.GasLimit.Uint64(),
+		}
+		genesis = gspec.MustCommit(db)
+	)
+
+	userTx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		Nonce:     0,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: gasFeeCap,
+		Gas:       params.TxGas,
+		To:        &common.Address{1},
+	}), signer, userKey)
+
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewCoinbaseFaker(), db, 1, 10, func(i int, b *BlockGen) {
+		b.AddTx(userTx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockchain, _ := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{})
+	defer blockchain.Stop()
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	statedb, err := blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce := statedb.GetNonce(userAddr); nonce != 1 {
+		t.Fatalf("expected transaction paid in gas token to be applied, user nonce = %d", nonce)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(params.TxGas), blocks[0].BaseFee())
+	expectedBalance := new(big.Int).Sub(userBalance, fee)
+	if balance := precompile.GetGasTokenBalance(statedb, userAddr); balance.Cmp(expectedBalance) != 0 {
+		t.Fatalf("expected user gas token balance %d, have %d", expectedBalance, balance)
+	}
+	if balance := precompile.GetGasTokenBalance(statedb, blocks[0].Coinbase()); balance.Cmp(fee) != 0 {
+		t.Fatalf("expected coinbase gas token balance %d, have %d", fee, balance)
+	}
+	if balance := precompile.GetGasTokenBalance(statedb, precompile.GasTokenAddress); balance.Sign() != 0 {
+		t.Fatalf("expected no gas token to be held by the precompile, have %d", balance)
+	}
+	if supply := precompile.GetGasTokenTotalSupply(statedb); supply.Cmp(userBalance) != 0 {
+		t.Fatalf("expected gas token total supply %d, have %d", userBalance, supply)
+	}
+}
+
+// testTxType is a custom transaction type storing its payload in the first storage slot of its recipient.
+var (
+	testTxType = TxType{
+		ID:   types.MinCustomTxType,
+		Name: "store",
+		Validate: func(_ *params.ChainConfig, _ vm.StateDB, msg Message) error {
+			if len(msg.Data()) == 0 {
+				return errEmptyTestPayload
+			}
+			return nil
+		},
+		Execute: func(evm *vm.EVM, msg Message, gas uint64) ([]byte, uint64, error) {
+			if gas < testTxTypeGas {
+				return nil, 0, vmerrs.ErrOutOfGas
+			}
+			evm.StateDB.SetState(*msg.To(), common.Hash{}, common.BytesToHash(msg.Data()))
+			return nil, gas - testTxTypeGas, nil
+		},
+	}
+	testTxTypeGas       uint64 = 5000
+	errEmptyTestPayload        = errors.New("empty payload")
+)
+
+func init() {
+	RegisterTxType(testTxType)
+}
+
+// customTxTypeConfig is the test chain config with [testTxType] activated at genesis.
+var customTxTypeConfig = func() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.CustomTxTypeTimestamps = map[uint8]*big.Int{testTxType.ID: big.NewInt(0)}
+	return &config
+}()
+
+func mkCustomTx(nonce uint64, to common.Address, gasLimit uint64, gasTipCap, gasFeeCap *big.Int, data []byte, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTx(&types.CustomTx{
+		Type:      testTxType.ID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      data,
+	}), signer, key)
+	return tx
+}
+
+func TestRegisterTxType(t *testing.T) {
+	if err := registerTxType(TxType{ID: testTxType.ID, Name: "duplicate"}); err == nil {
+		t.Fatal("expected registering a duplicate transaction type to fail")
+	}
+	if err := registerTxType(TxType{ID: types.MinCustomTxType + 1}); err == nil {
+		t.Fatal("expected registering a transaction type without a name to fail")
+	}
+	if err := registerTxType(TxType{ID: types.DynamicFeeTxType, Name: "invalid"}); err == nil {
+		t.Fatal("expected registering an Ethereum transaction type to fail")
+	}
+	if _, ok := GetTxType(types.MinCustomTxType + 1); ok {
+		t.Fatal("expected transaction types that failed to register not to be registered")
+	}
+}
+
+// Tests that custom transactions are executed with the hook of their type and pay
+// for gas like dynamic fee transactions.
+func TestCustomTxTypeTransaction(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		addr    = crypto.PubkeyToAddress(testKey.PublicKey)
+		target  = common.Address{1}
+		payload = common.HexToHash("0x42")
+		balance = big.NewInt(params.Ether)
+		gspec   = &Genesis{
+			Config:   customTxTypeConfig,
+			Alloc:    GenesisAlloc{addr: {Balance: balance}, target: {Balance: common.Big1}},
+			GasLimit: params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+		}
+		genesis = gspec.MustCommit(db)
+		tx      = mkCustomTx(0, target, params.TxGas+testTxTypeGas*2, big.NewInt(0), big.NewInt(225000000000), payload.Bytes(), testKey)
+	)
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewCoinbaseFaker(), db, 1, 10, func(i int, b *BlockGen) {
+		b.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockchain, _ := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{})
+	defer blockchain.Stop()
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	receipts := blockchain.GetReceiptsByHash(blocks[0].Hash())
+	if len(receipts) != 1 || receipts[0].Type != testTxType.ID || receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("unexpected receipts %v", receipts)
+	}
+	gasUsed := params.TxGas + uint64(len(payload))*params.TxDataNonZeroGasEIP2028 - 31*(params.TxDataNonZeroGasEIP2028-params.TxDataZeroGas) + testTxTypeGas
+	if receipts[0].GasUsed != gasUsed {
+		t.Fatalf("unexpected gas used %d, expected %d", receipts[0].GasUsed, gasUsed)
+	}
+	statedb, err := blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := statedb.GetState(target, common.Hash{}); value != payload {
+		t.Fatalf("expected payload %s to be stored, have %s", payload, value)
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 1 {
+		t.Fatalf("expected sender nonce 1, have %d", nonce)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), blocks[0].BaseFee())
+	if have, want := statedb.GetBalance(addr), new(big.Int).Sub(balance, fee); have.Cmp(want) != 0 {
+		t.Fatalf("expected sender balance %d, have %d", want, have)
+	}
+}
+
+// Tests that blocks containing custom transactions are rejected until the type activates,
+// even though the type is registered and Subnet EVM is active.
+func TestCustomTxTypeNotActivated(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		addr  = crypto.PubkeyToAddress(testKey.PublicKey)
+		gspec = &Genesis{
+			Config:   params.TestChainConfig,
+			Alloc:    GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+			GasLimit: params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+		}
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{})
+		tx            = mkCustomTx(0, common.Address{1}, params.TxGas+testTxTypeGas*2, big.NewInt(0), big.NewInt(225000000000), []byte{0x42}, testKey)
+	)
+	defer blockchain.Stop()
+
+	block := GenerateBadBlock(genesis, dummy.NewCoinbaseFaker(), types.Transactions{tx}, gspec.Config)
+	if _, err := blockchain.InsertChain(types.Blocks{block}); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatal("expected", ErrTxTypeNotSupported, "got", err)
+	}
+}
+
+func TestStateProcessorStatePatch(t *testing.T) {
+	var (
+		db            = rawdb.NewMemoryDatabase()
+		target        = common.HexToAddress("0x0100000000000000000000000000000000000abc")
+		funded        = common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+		key           = common.Hash{1}
+		value         = common.Hash{2}
+		balance       = big.NewInt(params.Ether)
+		gspec         = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{funded: {Balance: big.NewInt(1)}}}
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewCoinbaseFaker(), vm.Config{}, common.Hash{})
+	)
+	defer blockchain.Stop()
+
+	patch := params.StatePatch{
+		BlockNumber: big.NewInt(1),
+		Operations: []params.StatePatchOperation{
+			{Op: params.SetStorageOp, Address: target, Key: &key, Value: &value},
+			{Op: params.SetBalanceOp, Address: funded, Balance: (*math.HexOrDecimal256)(balance)},
+		},
+	}
+	process := func(patch params.StatePatch) (*state.StateDB, error) {
+		config := *params.TestChainConfig
+		config.StatePatches = []params.StatePatch{patch}
+		block := GenerateBadBlock(genesis, dummy.NewCoinbaseFaker(), nil, &config)
+		statedb, err := blockchain.StateAt(genesis.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, _, err = NewStateProcessor(&config, blockchain, dummy.NewCoinbaseFaker()).Process(block, genesis.Header(), statedb, vm.Config{})
+		return statedb, err
+	}
+
+	statedb, err := process(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := statedb.GetState(target, key); have != value {
+		t.Fatalf("expected patched storage %x, have %x", value, have)
+	}
+	if have := statedb.GetBalance(funded); have.Cmp(balance) != 0 {
+		t.Fatalf("expected patched balance %d, have %d", balance, have)
+	}
+
+	// A patch reviewed against another parent state is not applied
+	otherRoot := common.Hash{0xaa}
+	patch.ParentRoot = &otherRoot
+	if _, err := process(patch); err == nil || !strings.Contains(err.Error(), "expects parent root") {
+		t.Fatalf("expected parent root mismatch, have %v", err)
+	}
+	root := genesis.Root()
+	patch.ParentRoot = &root
+	if _, err := process(patch); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // GenerateBadBlock constructs a "block" which contains the transactions. The transactions are not expected to be
 // valid, and no proper post-state can be made. But from the perspective of the blockchain, the block is sufficiently
 // valid to be considered for import:
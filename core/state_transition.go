@@ -54,8 +54,10 @@ The state transitioning model does all the necessary work to work out a valid ne
 3) Create a new state object if the recipient is \0*32
 4) Value transfer
 == If contract creation ==
-  4a) Attempt to run transaction data
-  4b) If valid, use result as code for the new state object
+
+	4a) Attempt to run transaction data
+	4b) If valid, use result as code for the new state object
+
 == end ==
 5) Run Script section
 6) Derive new state root
@@ -72,6 +74,12 @@ type StateTransition struct {
 	data       []byte
 	state      vm.StateDB
 	evm        *vm.EVM
+
+	// sponsor is the account whose gas sponsor deposit pays for the gas of this message,
+	// or nil if the gas is paid by the sender.
+	sponsor *common.Address
+	// gasToken is true if the gas of this message is paid in the gas token.
+	gasToken bool
 }
 
 // Message represents a message sent to a contract.
@@ -89,6 +97,16 @@ type Message interface {
 	IsFake() bool
 	Data() []byte
 	AccessList() types.AccessList
+
+	// BlobGasFeeCap and BlobHashes return the blob fields of blob transactions, nil otherwise.
+	BlobGasFeeCap() *big.Int
+	BlobHashes() []common.Hash
+
+	// SetCodeAuthorizations returns the authorization list of set code transactions, nil otherwise.
+	SetCodeAuthorizations() []types.SetCodeAuthorization
+
+	// Type returns the type of the transaction of the message, or the legacy type for calls.
+	Type() uint8
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -127,7 +145,7 @@ func (result *ExecutionResult) Revert() []byte {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
+func IntrinsicGas(data []byte, accessList types.AccessList, authList []types.SetCodeAuthorization, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if isContractCreation && isHomestead {
@@ -164,6 +182,9 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
 		gas += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
 	}
+	if authList != nil {
+		gas += uint64(len(authList)) * params.CallNewAccountGas
+	}
 	return gas, nil
 }
 
@@ -202,6 +223,9 @@ func (st *StateTransition) to() common.Address {
 }
 
 func (st *StateTransition) buyGas() error {
+	if st.evm.ChainConfig().IsGasToken(st.evm.Context.BlockNumber, st.evm.Context.Time) {
+		return st.buyGasToken()
+	}
 	mgval := new(big.Int).SetUint64(st.msg.Gas())
 	mgval = mgval.Mul(mgval, st.gasPrice)
 	balanceCheck := mgval
@@ -210,6 +234,28 @@ func (st *StateTransition) buyGas() error {
 		balanceCheck.Mul(balanceCheck, st.gasFeeCap)
 		balanceCheck.Add(balanceCheck, st.value)
 	}
+	// If the gas of this message is sponsored, the sponsor's deposit must cover the gas
+	// and the sender only needs to cover the value.
+	var sponsor *common.Address
+	if st.evm.ChainConfig().IsGasSponsor(st.evm.Context.BlockNumber, st.evm.Context.Time) {
+		if addr, ok := precompile.GetGasSponsor(st.state, st.msg.From(), st.msg.To()); ok {
+			gasCheck := new(big.Int).Sub(balanceCheck, st.value)
+			if st.gasFeeCap == nil {
+				gasCheck = mgval
+			}
+			if precompile.GetSponsorDeposit(st.state, addr).Cmp(gasCheck) >= 0 {
+				sponsor = &addr
+				balanceCheck = st.value
+			}
+		}
+	}
+	// The blob gas is always paid by the sender.
+	blobFee := st.blobFee()
+	if blobGasFeeCap := st.msg.BlobGasFeeCap(); blobGasFeeCap != nil {
+		blobBalanceCheck := new(big.Int).SetUint64(st.blobGas())
+		blobBalanceCheck.Mul(blobBalanceCheck, blobGasFeeCap)
+		balanceCheck = new(big.Int).Add(balanceCheck, blobBalanceCheck)
+	}
 	if have, want := st.state.GetBalance(st.msg.From()), balanceCheck; have.Cmp(want) < 0 {
 		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From().Hex(), have, want)
 	}
@@ -219,10 +265,88 @@ func (st *StateTransition) buyGas() error {
 	st.gas += st.msg.Gas()
 
 	st.initialGas = st.msg.Gas()
+	// Subnets have no blob fee market: the blob gas is priced at the base fee and burned.
+	st.state.SubBalance(st.msg.From(), blobFee)
+	if sponsor != nil {
+		// The deposit was checked against the fee cap above, so charging the gas price cannot fail.
+		precompile.ChargeSponsor(st.state, *sponsor, mgval)
+		st.sponsor = sponsor
+		return nil
+	}
 	st.state.SubBalance(st.msg.From(), mgval)
 	return nil
 }
 
+// buyGasToken buys the gas of the message with the gas token of the sender, which must cover
+// the gas and blob gas at their fee caps. Gas sponsorships do not apply to the gas token.
+// The blob fee is charged together with the gas and is never released, which burns it.
+func (st *StateTransition) buyGasToken() error {
+	mgval := new(big.Int).SetUint64(st.msg.Gas())
+	mgval = mgval.Mul(mgval, st.gasPrice)
+	gasCheck := mgval
+	if st.gasFeeCap != nil {
+		gasCheck = new(big.Int).SetUint64(st.msg.Gas())
+		gasCheck.Mul(gasCheck, st.gasFeeCap)
+	}
+	blobFee := st.blobFee()
+	if blobGasFeeCap := st.msg.BlobGasFeeCap(); blobGasFeeCap != nil {
+		blobGasCheck := new(big.Int).SetUint64(st.blobGas())
+		blobGasCheck.Mul(blobGasCheck, blobGasFeeCap)
+		gasCheck = new(big.Int).Add(gasCheck, blobGasCheck)
+	}
+	if have, want := precompile.GetGasTokenBalance(st.state, st.msg.From()), gasCheck; have.Cmp(want) < 0 {
+		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientGasTokenFunds, st.msg.From().Hex(), have, want)
+	}
+	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
+		return err
+	}
+	st.gas += st.msg.Gas()
+
+	st.initialGas = st.msg.Gas()
+	// The balance was checked against the fee caps above, so charging the gas price cannot fail.
+	precompile.ChargeGasToken(st.state, st.msg.From(), new(big.Int).Add(mgval, blobFee))
+	st.gasToken = true
+	return nil
+}
+
+// blobGas returns the blob gas consumed by the blobs referenced by the message.
+func (st *StateTransition) blobGas() uint64 {
+	return params.BlobTxBlobGasPerBlob * uint64(len(st.msg.BlobHashes()))
+}
+
+// blobFee returns the fee paid for the blob gas of the message, priced at the base fee.
+func (st *StateTransition) blobFee() *big.Int {
+	blobGas := st.blobGas()
+	if blobGas == 0 || st.evm.Context.BaseFee == nil {
+		return new(big.Int)
+	}
+	// Skip the fee if the blob fee cap is zero and the base fee was explicitly disabled (eth_call)
+	if st.evm.Config.NoBaseFee && st.msg.BlobGasFeeCap().BitLen() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(blobGas), st.evm.Context.BaseFee)
+}
+
+// CheckSenderAllowed returns an error if [from] may not send transactions in the block of [blockContext],
+// because it is not on the tx allow list or is on the address blocklist.
+func CheckSenderAllowed(config *params.ChainConfig, blockContext vm.BlockContext, state vm.StateDB, from common.Address) error {
+	// Check that the sender is on the tx allow list if enabled
+	if config.IsTxAllowList(blockContext.BlockNumber, blockContext.Time) {
+		txAllowListRole := precompile.GetTxAllowListStatus(state, from)
+		if !txAllowListRole.IsEnabled() {
+			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressNotAllowListed, from)
+		}
+	}
+
+	// Check that the sender is not on the address blocklist if enabled
+	if config.IsAddressBlocklist(blockContext.BlockNumber, blockContext.Time) {
+		if precompile.IsAddressBlocked(state, from) {
+			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressBlocked, from)
+		}
+	}
+	return nil
+}
+
 func (st *StateTransition) preCheck() error {
 	// Only check transactions that are not fake
 	if !st.msg.IsFake() {
@@ -238,22 +362,21 @@ func (st *StateTransition) preCheck() error {
 			return fmt.Errorf("%w: address %v, nonce: %d", ErrNonceMax,
 				st.msg.From().Hex(), stNonce)
 		}
-		// Make sure the sender is an EOA
+		// Make sure the sender is an EOA, possibly delegating its code (post set code tx)
 		if codeHash := st.state.GetCodeHash(st.msg.From()); codeHash != emptyCodeHash && codeHash != (common.Hash{}) {
-			return fmt.Errorf("%w: address %v, codehash: %s", ErrSenderNoEOA,
-				st.msg.From().Hex(), codeHash)
+			_, delegated := types.ParseDelegation(st.state.GetCode(st.msg.From()))
+			if !delegated || !st.evm.ChainConfig().IsSetCodeTx(st.evm.Context.Time) {
+				return fmt.Errorf("%w: address %v, codehash: %s", ErrSenderNoEOA,
+					st.msg.From().Hex(), codeHash)
+			}
 		}
 		// Make sure the sender is not prohibited
 		if vm.IsProhibited(st.msg.From()) {
 			return fmt.Errorf("%w: address %v", vmerrs.ErrAddrProhibited, st.msg.From())
 		}
 
-		// Check that the sender is on the tx allow list if enabled
-		if st.evm.ChainConfig().IsTxAllowList(st.evm.Context.Time) {
-			txAllowListRole := precompile.GetTxAllowListStatus(st.state, st.msg.From())
-			if !txAllowListRole.IsEnabled() {
-				return fmt.Errorf("%w: %s", precompile.ErrSenderAddressNotAllowListed, st.msg.From())
-			}
+		if err := CheckSenderAllowed(st.evm.ChainConfig(), st.evm.Context, st.state, st.msg.From()); err != nil {
+			return err
 		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
@@ -280,19 +403,57 @@ func (st *StateTransition) preCheck() error {
 			}
 		}
 	}
+	// Make sure that blob transactions reference valid blob hashes and that their blob fee cap
+	// covers the base fee (post blob tx)
+	if blobGasFeeCap := st.msg.BlobGasFeeCap(); blobGasFeeCap != nil {
+		if !st.evm.ChainConfig().IsBlobTx(st.evm.Context.Time) {
+			return fmt.Errorf("%w: blob transactions are not enabled", ErrTxTypeNotSupported)
+		}
+		if err := types.VerifyBlobHashes(st.msg.BlobHashes()); err != nil {
+			return fmt.Errorf("%w: address %v", err, st.msg.From().Hex())
+		}
+		if !st.evm.Config.NoBaseFee || blobGasFeeCap.BitLen() > 0 {
+			if l := blobGasFeeCap.BitLen(); l > 256 {
+				return fmt.Errorf("%w: address %v, maxFeePerBlobGas bit length: %d", ErrFeeCapVeryHigh,
+					st.msg.From().Hex(), l)
+			}
+			if blobGasFeeCap.Cmp(st.evm.Context.BaseFee) < 0 {
+				return fmt.Errorf("%w: address %v, maxFeePerBlobGas: %s baseFee: %s", ErrBlobFeeCapTooLow,
+					st.msg.From().Hex(), blobGasFeeCap, st.evm.Context.BaseFee)
+			}
+		}
+	}
+	// Make sure that set code transactions carry authorizations (post set code tx)
+	if authList := st.msg.SetCodeAuthorizations(); authList != nil {
+		if !st.evm.ChainConfig().IsSetCodeTx(st.evm.Context.Time) {
+			return fmt.Errorf("%w: set code transactions are not enabled", ErrTxTypeNotSupported)
+		}
+		if len(authList) == 0 {
+			return fmt.Errorf("%w: address %v", ErrEmptyAuthList, st.msg.From().Hex())
+		}
+	}
+	// Make sure that custom transactions are valid for their type (post activation of the type)
+	if types.IsCustomTxType(st.msg.Type()) {
+		if !st.evm.ChainConfig().IsCustomTxType(st.msg.Type(), st.evm.Context.Time) {
+			return fmt.Errorf("%w: custom transaction type %#x is not enabled", ErrTxTypeNotSupported, st.msg.Type())
+		}
+		if err := validateTxType(st.evm.ChainConfig(), st.state, st.msg); err != nil {
+			return err
+		}
+	}
 	return st.buyGas()
 }
 
 // TransitionDb will transition the state by applying the current message and
 // returning the evm execution result with following fields.
 //
-// - used gas:
-//      total gas used (including gas being refunded)
-// - returndata:
-//      the returned data from evm
-// - concrete execution error:
-//      various **EVM** error which aborts the execution,
-//      e.g. ErrOutOfGas, ErrExecutionReverted
+//   - used gas:
+//     total gas used (including gas being refunded)
+//   - returndata:
+//     the returned data from evm
+//   - concrete execution error:
+//     various **EVM** error which aborts the execution,
+//     e.g. ErrOutOfGas, ErrExecutionReverted
 //
 // However if any consensus issue encountered, return the error directly with
 // nil evm execution result.
@@ -303,7 +464,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// 1. the nonce of the message caller is correct
 	// 2. caller has enough balance to cover transaction fee(gaslimit * gasprice)
 	// 3. the amount of gas required is available in the block
-	// 4. the message caller is on the tx allow list (if enabled)
+	// 4. the message caller is on the tx allow list and not on the address blocklist (if enabled)
 	// 5. the purchased gas is enough to cover intrinsic usage
 	// 6. there is no overflow when calculating intrinsic gas
 	// 7. caller has enough balance to cover asset transfer for **topmost** call
@@ -327,7 +488,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	)
 
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, rules.IsHomestead, rules.IsIstanbul)
+	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), st.msg.SetCodeAuthorizations(), contractCreation, rules.IsHomestead, rules.IsIstanbul)
 	if err != nil {
 		return nil, err
 	}
@@ -349,15 +510,36 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret   []byte
 		vmerr error // vm errors do not effect consensus and are therefore not assigned to err
 	)
-	if contractCreation {
+	if txType, ok := GetTxType(msg.Type()); ok && txType.Execute != nil {
+		// Increment the nonce for the next transaction
+		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		ret, st.gas, vmerr = st.executeTxType(txType)
+	} else if contractCreation {
 		ret, _, st.gas, vmerr = st.evm.Create(sender, st.data, st.gas, st.value)
 	} else {
 		// Increment the nonce for the next transaction
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		if rules.IsSetCodeTx {
+			// Apply the EIP-7702 authorizations, skipping the invalid ones
+			authList := msg.SetCodeAuthorizations()
+			for i := range authList {
+				st.applyAuthorization(&authList[i])
+			}
+			// Warm the delegation target of the recipient once the delegations are final,
+			// as it is loaded to execute the code of the recipient
+			if target, ok := types.ParseDelegation(st.state.GetCode(st.to())); ok {
+				st.state.AddAddressToAccessList(target)
+			}
+		}
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 	st.refundGas(rules.IsSubnetEVM)
-	st.state.AddBalance(st.evm.Context.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice)
+	if st.gasToken {
+		precompile.ReleaseGasToken(st.state, st.evm.Context.Coinbase, fee)
+	} else {
+		st.state.AddBalance(st.evm.Context.Coinbase, fee)
+	}
 
 	return &ExecutionResult{
 		UsedGas:    st.gasUsed(),
@@ -366,6 +548,63 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}, nil
 }
 
+// validateAuthorization returns the authority of [auth] if it can be applied to the current
+// state, or an error otherwise.
+func (st *StateTransition) validateAuthorization(auth *types.SetCodeAuthorization) (authority common.Address, err error) {
+	// Verify chain ID is null or equal to current chain ID.
+	if auth.ChainID != nil && auth.ChainID.Sign() != 0 && auth.ChainID.Cmp(st.evm.ChainConfig().ChainID) != 0 {
+		return authority, ErrAuthorizationWrongChainID
+	}
+	// Limit nonce to 2^64-1 per EIP-2681.
+	if auth.Nonce+1 < auth.Nonce {
+		return authority, ErrAuthorizationNonceOverflow
+	}
+	// Validate signature values and recover authority.
+	authority, err = auth.Authority()
+	if err != nil {
+		return authority, fmt.Errorf("%w: %v", ErrAuthorizationInvalidSignature, err)
+	}
+	// Check the authority account
+	//  1) doesn't have code or has existing delegation
+	//  2) matches the auth's nonce
+	//
+	// Note it is added to the access list even if the authorization is invalid.
+	st.state.AddAddressToAccessList(authority)
+	code := st.state.GetCode(authority)
+	if _, ok := types.ParseDelegation(code); len(code) != 0 && !ok {
+		return authority, ErrAuthorizationDestinationHasCode
+	}
+	if have := st.state.GetNonce(authority); have != auth.Nonce {
+		return authority, ErrAuthorizationNonceMismatch
+	}
+	return authority, nil
+}
+
+// applyAuthorization installs the delegation of [auth] to the code of its address,
+// or clears the delegation of its authority if the address is zero.
+func (st *StateTransition) applyAuthorization(auth *types.SetCodeAuthorization) error {
+	authority, err := st.validateAuthorization(auth)
+	if err != nil {
+		return err
+	}
+	// If the account already exists in state, credit back the new account cost
+	// charged in the intrinsic calculation. It is not added to the refund counter,
+	// which is ignored once Subnet EVM is activated.
+	if st.state.Exist(authority) {
+		st.gas += params.CallNewAccountGas - params.TxAuthTupleGas
+	}
+	// Update nonce and account code.
+	st.state.SetNonce(authority, auth.Nonce+1)
+	if auth.Address == (common.Address{}) {
+		// Delegation to zero address means clear.
+		st.state.SetCode(authority, nil)
+		return nil
+	}
+	// Otherwise install delegation to auth.Address.
+	st.state.SetCode(authority, types.AddressToDelegation(auth.Address))
+	return nil
+}
+
 func (st *StateTransition) refundGas(subnetEVM bool) {
 	// Inspired by: https://gist.github.com/holiman/460f952716a74eeb9ab358bb1836d821#gistcomment-3642048
 	if !subnetEVM {
@@ -378,7 +617,14 @@ func (st *StateTransition) refundGas(subnetEVM bool) {
 	}
 	// Return ETH for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	switch {
+	case st.gasToken:
+		precompile.ReleaseGasToken(st.state, st.msg.From(), remaining)
+	case st.sponsor != nil:
+		precompile.RefundSponsor(st.state, *st.sponsor, remaining)
+	default:
+		st.state.AddBalance(st.msg.From(), remaining)
+	}
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
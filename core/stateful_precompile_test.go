@@ -4,10 +4,17 @@
 package core
 
 import (
+	"context"
+	"crypto/ecdsa"
 	"math/big"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/teleporter"
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
@@ -17,6 +24,7 @@ import (
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -45,13 +53,18 @@ type mockBlockContext struct {
 	timestamp   uint64
 }
 
-func (mb *mockBlockContext) Number() *big.Int    { return mb.blockNumber }
-func (mb *mockBlockContext) Timestamp() *big.Int { return new(big.Int).SetUint64(mb.timestamp) }
+func (mb *mockBlockContext) Number() *big.Int         { return mb.blockNumber }
+func (mb *mockBlockContext) Timestamp() *big.Int      { return new(big.Int).SetUint64(mb.timestamp) }
+func (mb *mockBlockContext) BaseFee() *big.Int        { return nil }
+func (mb *mockBlockContext) Coinbase() common.Address { return common.Address{} }
+func (mb *mockBlockContext) ParentHash() common.Hash  { return common.Hash{} }
+func (mb *mockBlockContext) GasLimit() uint64         { return 0 }
 
 type mockAccessibleState struct {
 	state        *state.StateDB
 	blockContext *mockBlockContext
 	snowContext  *snow.Context
+	chainConfig  *params.ChainConfig
 }
 
 func (m *mockAccessibleState) GetStateDB() precompile.StateDB { return m.state }
@@ -60,10 +73,30 @@ func (m *mockAccessibleState) GetBlockContext() precompile.BlockContext { return
 
 func (m *mockAccessibleState) GetSnowContext() *snow.Context { return m.snowContext }
 
+func (m *mockAccessibleState) GetChainConfig() precompile.ChainConfig {
+	if m.chainConfig == nil {
+		return params.TestChainConfig
+	}
+	return m.chainConfig
+}
+
 func (m *mockAccessibleState) CallFromPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error) {
 	return nil, 0, nil
 }
 
+func (m *mockAccessibleState) CallPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	module, ok := precompile.GetRegisteredModuleByAddress(addr)
+	if !ok {
+		return nil, gas, precompile.ErrPrecompileNotEnabled
+	}
+	snapshot := m.state.Snapshot()
+	ret, remainingGas, err = module.Contract.Run(m, caller, addr, input, gas, readOnly)
+	if err != nil {
+		m.state.RevertToSnapshot(snapshot)
+	}
+	return ret, remainingGas, err
+}
+
 // This test is added within the core package so that it can import all of the required code
 // without creating any import cycles
 func TestContractDeployerAllowListRun(t *testing.T) {
@@ -1286,3 +1319,2142 @@ func TestRewardManagerRun(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressBlocklistRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+		config      *precompile.AddressBlocklistConfig
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	enabledAddr := common.HexToAddress("0xB2B1B5A6B4A1d8D1F1c7B8c7c1E0d5e6a1f1A2B3")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	blockedAddrs := []common.Address{{1}, {2}, {3}}
+
+	for name, test := range map[string]test{
+		"block address from enabled": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackBlockAddress(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockAddressGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.True(t, precompile.IsAddressBlocked(state, noRoleAddr))
+				require.Equal(t, uint64(1), precompile.GetBlockedAddressCount(state))
+				blocked, ok := precompile.GetBlockedAddressAt(state, 0)
+				require.True(t, ok)
+				require.Equal(t, noRoleAddr, blocked)
+
+				logs := state.Logs()
+				require.Len(t, logs, 1)
+				require.Equal(t, precompile.AddressBlocklistAddress, logs[0].Address)
+				require.Equal(t, []common.Hash{precompile.AddressBlocklistABI.Events["AddressBlocked"].ID, noRoleAddr.Hash()}, logs[0].Topics)
+				require.Empty(t, logs[0].Data)
+				require.Equal(t, testBlockNumber.Uint64(), logs[0].BlockNumber)
+			},
+		},
+		"block address from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackBlockAddress(adminAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockAddressGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotBlockAddress.Error(),
+		},
+		"block address twice fails": {
+			caller: adminAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackBlockAddress(blockedAddrs[0])
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockAddressGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrAddressAlreadyBlocked.Error(),
+		},
+		"block address readOnly": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackBlockAddress(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockAddressGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"block address insufficient gas": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackBlockAddress(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockAddressGasCost - 1,
+			readOnly:    false,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"unblock address moves last entry": {
+			caller: adminAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackUnblockAddress(blockedAddrs[0])
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnblockAddressGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.False(t, precompile.IsAddressBlocked(state, blockedAddrs[0]))
+				require.Equal(t, uint64(2), precompile.GetBlockedAddressCount(state))
+				first, ok := precompile.GetBlockedAddressAt(state, 0)
+				require.True(t, ok)
+				require.Equal(t, blockedAddrs[2], first)
+				second, ok := precompile.GetBlockedAddressAt(state, 1)
+				require.True(t, ok)
+				require.Equal(t, blockedAddrs[1], second)
+				_, ok = precompile.GetBlockedAddressAt(state, 2)
+				require.False(t, ok)
+
+				// The moved entry can still be removed.
+				require.True(t, precompile.UnblockAddress(state, blockedAddrs[2]))
+				first, ok = precompile.GetBlockedAddressAt(state, 0)
+				require.True(t, ok)
+				require.Equal(t, blockedAddrs[1], first)
+			},
+		},
+		"unblock address not blocked fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackUnblockAddress(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnblockAddressGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrAddressNotBlocked.Error(),
+		},
+		"unblock address from no role fails": {
+			caller: noRoleAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackUnblockAddress(blockedAddrs[0])
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnblockAddressGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotUnblockAddress.Error(),
+		},
+		"is blocked": {
+			caller: noRoleAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackIsBlocked(blockedAddrs[1])
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.IsBlockedGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackIsBlockedOutput(true)
+				require.NoError(t, err)
+				return res
+			}(),
+		},
+		"blocked address count": {
+			caller: noRoleAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackBlockedAddressCount()
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockedAddressCountGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackBlockedAddressCountOutput(big.NewInt(3))
+				require.NoError(t, err)
+				return res
+			}(),
+		},
+		"blocked address at": {
+			caller: noRoleAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackBlockedAddressAt(big.NewInt(1))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockedAddressAtGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackBlockedAddressAtOutput(blockedAddrs[1])
+				require.NoError(t, err)
+				return res
+			}(),
+		},
+		"blocked address at out of bounds": {
+			caller: noRoleAddr,
+			config: precompile.NewAddressBlocklistConfig(common.Big0, nil, nil, blockedAddrs),
+			input: func() []byte {
+				input, err := precompile.PackBlockedAddressAt(big.NewInt(3))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.BlockedAddressAtGasCost,
+			readOnly:    true,
+			expectedErr: precompile.ErrBlockedAddressOutOfBounds.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			// Set up the state so that each address has the expected permissions at the start.
+			precompile.SetAddressBlocklistAllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.SetAddressBlocklistAllowListStatus(state, enabledAddr, precompile.AllowListEnabled)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			if test.config != nil {
+				test.config.Configure(params.TestChainConfig, state, blockContext)
+			}
+			ret, remainingGas, err := precompile.AddressBlocklistPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, test.caller, precompile.AddressBlocklistAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestChainConfigReaderRun(t *testing.T) {
+	type test struct {
+		input       func() []byte
+		suppliedGas uint64
+		timestamp   uint64
+
+		expectedRes func() []byte
+		expectedErr string
+	}
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.ChainID = big.NewInt(43214)
+	chainConfig.AllowFeeRecipients = true
+	chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewChainConfigReaderConfig(common.Big0))
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewFeeManagerConfig(big.NewInt(10), nil, nil, nil)),
+			params.NewPrecompileUpgrade(precompile.NewDisableFeeManagerConfig(big.NewInt(20))),
+		},
+	}
+
+	for name, test := range map[string]test{
+		"chain id": {
+			input:       func() []byte { input, _ := precompile.PackChainId(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("chainId", big.NewInt(43214))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"config hash": {
+			input:       func() []byte { input, _ := precompile.PackConfigHash(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   15,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("configHash", chainConfig.HashAt(testBlockNumber, big.NewInt(15)))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"subnet evm timestamp": {
+			input:       func() []byte { input, _ := precompile.PackSubnetEVMTimestamp(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("subnetEVMTimestamp", true, common.Big0)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"allow fee recipients": {
+			input:       func() []byte { input, _ := precompile.PackChainConfigAllowFeeRecipients(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("allowFeeRecipients", true)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"fee config source before fee manager": {
+			input:       func() []byte { input, _ := precompile.PackFeeConfigSource(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   5,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("feeConfigSource", precompile.FeeConfigSourceGenesis)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"fee config source with fee manager": {
+			input:       func() []byte { input, _ := precompile.PackFeeConfigSource(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   15,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("feeConfigSource", precompile.FeeConfigSourceFeeManager)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"fee config source after fee manager disabled": {
+			input:       func() []byte { input, _ := precompile.PackFeeConfigSource(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   25,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("feeConfigSource", precompile.FeeConfigSourceGenesis)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"enabled precompiles": {
+			input:       func() []byte { input, _ := precompile.PackEnabledPrecompiles(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   15,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("enabledPrecompiles",
+					[]common.Address{precompile.FeeConfigManagerAddress, precompile.ChainConfigReaderAddress},
+					[]*big.Int{big.NewInt(10), common.Big0},
+				)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"precompile activation enabled": {
+			input: func() []byte {
+				input, err := precompile.PackPrecompileActivation(precompile.FeeConfigManagerAddress)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   15,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("precompileActivation", true, big.NewInt(10))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"precompile activation not enabled": {
+			input: func() []byte {
+				input, err := precompile.PackPrecompileActivation(precompile.TxAllowListAddress)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.ReadChainConfigGasCost,
+			timestamp:   15,
+			expectedRes: func() []byte {
+				res, err := precompile.ChainConfigReaderABI.PackOutput("precompileActivation", false, common.Big0)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"insufficient gas": {
+			input:       func() []byte { input, _ := precompile.PackChainId(); return input },
+			suppliedGas: precompile.ReadChainConfigGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber, timestamp: test.timestamp}
+			accessibleState := &mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest(), chainConfig: &chainConfig}
+			ret, remainingGas, err := precompile.ChainConfigReaderPrecompile.Run(accessibleState, common.Address{}, precompile.ChainConfigReaderAddress, test.input(), test.suppliedGas, true)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes(), ret)
+		})
+	}
+}
+
+func TestNativeAssetBalanceRun(t *testing.T) {
+	type test struct {
+		input       func() []byte
+		suppliedGas uint64
+		config      *precompile.NativeAssetCallConfig
+
+		expectedRes []byte
+		expectedErr string
+	}
+
+	holderAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	assetID := common.Hash{1}
+
+	for name, test := range map[string]test{
+		"balance of holder": {
+			input:       func() []byte { return precompile.PackNativeAssetBalanceInput(holderAddr, assetID) },
+			suppliedGas: precompile.NativeAssetBalanceGasCost,
+			config: precompile.NewNativeAssetCallConfig(common.Big0, map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holderAddr: {assetID: math.NewHexOrDecimal256(100)},
+			}),
+			expectedRes: common.BigToHash(big.NewInt(100)).Bytes(),
+		},
+		"balance of unknown asset": {
+			input:       func() []byte { return precompile.PackNativeAssetBalanceInput(holderAddr, common.Hash{2}) },
+			suppliedGas: precompile.NativeAssetBalanceGasCost,
+			config: precompile.NewNativeAssetCallConfig(common.Big0, map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holderAddr: {assetID: math.NewHexOrDecimal256(100)},
+			}),
+			expectedRes: common.Hash{}.Bytes(),
+		},
+		"invalid input": {
+			input:       func() []byte { return holderAddr.Bytes() },
+			suppliedGas: precompile.NativeAssetBalanceGasCost,
+			expectedErr: vmerrs.ErrExecutionReverted.Error(),
+		},
+		"insufficient gas": {
+			input:       func() []byte { return precompile.PackNativeAssetBalanceInput(holderAddr, assetID) },
+			suppliedGas: precompile.NativeAssetBalanceGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			if test.config != nil {
+				test.config.Configure(params.TestChainConfig, state, blockContext)
+			}
+			ret, remainingGas, err := precompile.NativeAssetBalancePrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, common.Address{}, precompile.NativeAssetBalanceAddress, test.input(), test.suppliedGas, true)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes, ret)
+		})
+	}
+}
+
+func TestNativeAssetCallRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	holderAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	recipientAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	assetID := common.Hash{1}
+	config := precompile.NewNativeAssetCallConfig(common.Big0, map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+		holderAddr: {assetID: math.NewHexOrDecimal256(100)},
+	})
+
+	for name, test := range map[string]test{
+		"transfer to new account": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(40), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost + precompile.NativeAssetCallNewAccount,
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.True(t, state.Exist(recipientAddr))
+				require.Equal(t, big.NewInt(60), precompile.GetBalanceMultiCoin(state, holderAddr, assetID))
+				require.Equal(t, big.NewInt(40), precompile.GetBalanceMultiCoin(state, recipientAddr, assetID))
+			},
+		},
+		"transfer entire balance": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(100), []byte{0x01, 0x02})
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost + precompile.NativeAssetCallNewAccount,
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Zero(t, precompile.GetBalanceMultiCoin(state, holderAddr, assetID).Sign())
+				require.Equal(t, big.NewInt(100), precompile.GetBalanceMultiCoin(state, recipientAddr, assetID))
+			},
+		},
+		"insufficient balance": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(101), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost + precompile.NativeAssetCallNewAccount,
+			expectedErr: vmerrs.ErrInsufficientBalance.Error(),
+		},
+		"insufficient balance of other asset": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, common.Hash{2}, big.NewInt(1), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost + precompile.NativeAssetCallNewAccount,
+			expectedErr: vmerrs.ErrInsufficientBalance.Error(),
+		},
+		"invalid input": {
+			caller:      holderAddr,
+			input:       func() []byte { return recipientAddr.Bytes() },
+			suppliedGas: precompile.NativeAssetCallGasCost,
+			expectedErr: vmerrs.ErrExecutionReverted.Error(),
+		},
+		"readOnly transfer fails": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(1), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"insufficient gas for new account": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(1), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"insufficient gas": {
+			caller: holderAddr,
+			input: func() []byte {
+				return precompile.PackNativeAssetCallInput(recipientAddr, assetID, big.NewInt(1), nil)
+			},
+			suppliedGas: precompile.NativeAssetCallGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			config.Configure(params.TestChainConfig, state, blockContext)
+			_, _, err = precompile.NativeAssetCallPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, test.caller, precompile.NativeAssetCallAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestGasSponsorRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	enabledAddr := common.HexToAddress("0xB2B1B5A6B4A1d8D1F1c7B8c7c1E0d5e6a1f1A2B3")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	sponsoredSender := common.Address{1}
+	sponsoredTarget := common.Address{2}
+	initialBalance := big.NewInt(1000)
+	initialDeposit := big.NewInt(500)
+
+	for name, test := range map[string]test{
+		"deposit from enabled": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackDeposit(big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(100), precompile.GetSponsorDeposit(state, enabledAddr))
+				require.Equal(t, big.NewInt(900), state.GetBalance(enabledAddr))
+				require.Equal(t, big.NewInt(600), state.GetBalance(precompile.GasSponsorAddress))
+				logs := state.GetLogs(common.Hash{1}, common.Hash{})
+				require.Len(t, logs, 1)
+				require.Equal(t, []common.Hash{precompile.GasSponsorABI.Events["Deposited"].ID, enabledAddr.Hash()}, logs[0].Topics)
+				require.Equal(t, common.BigToHash(big.NewInt(100)).Bytes(), logs[0].Data)
+			},
+		},
+		"deposit from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackDeposit(big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotDeposit.Error(),
+		},
+		"deposit more than balance fails": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackDeposit(big.NewInt(1001))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositGasCost,
+			readOnly:    false,
+			expectedErr: vmerrs.ErrInsufficientBalance.Error(),
+		},
+		"readOnly deposit fails": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackDeposit(big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"deposit insufficient gas": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackDeposit(big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositGasCost - 1,
+			readOnly:    false,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"withdraw from admin": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackWithdraw(big.NewInt(200))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.WithdrawGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(300), precompile.GetSponsorDeposit(state, adminAddr))
+				require.Equal(t, big.NewInt(1200), state.GetBalance(adminAddr))
+				require.Equal(t, big.NewInt(300), state.GetBalance(precompile.GasSponsorAddress))
+			},
+		},
+		"withdraw more than deposit fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackWithdraw(big.NewInt(501))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.WithdrawGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrInsufficientDeposit.Error(),
+		},
+		"deposit of admin": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackDepositOf(adminAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.DepositOfGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackDepositOfOutput(initialDeposit)
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"sponsor sender from enabled": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorSender(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorAccountGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				sponsor, ok := precompile.GetSenderSponsor(state, noRoleAddr)
+				require.True(t, ok)
+				require.Equal(t, enabledAddr, sponsor)
+			},
+		},
+		"sponsor sender from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorSender(enabledAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorAccountGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotSponsor.Error(),
+		},
+		"sponsor already sponsored sender fails": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorSender(sponsoredSender)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorAccountGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrAlreadySponsored.Error(),
+		},
+		"unsponsor sender from sponsor": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackUnsponsorSender(sponsoredSender)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnsponsorAccountGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				_, ok := precompile.GetSenderSponsor(state, sponsoredSender)
+				require.False(t, ok)
+			},
+		},
+		"unsponsor sender from other sponsor fails": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackUnsponsorSender(sponsoredSender)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnsponsorAccountGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrNotSponsoredByCaller.Error(),
+		},
+		"sponsor target from admin": {
+			caller: adminAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorTarget(noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorAccountGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				sponsor, ok := precompile.GetTargetSponsor(state, noRoleAddr)
+				require.True(t, ok)
+				require.Equal(t, adminAddr, sponsor)
+			},
+		},
+		"unsponsor target from sponsor": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackUnsponsorTarget(sponsoredTarget)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.UnsponsorAccountGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				_, ok := precompile.GetTargetSponsor(state, sponsoredTarget)
+				require.False(t, ok)
+			},
+		},
+		"sponsor of prefers target": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorOf(sponsoredSender, sponsoredTarget)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorOfGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackSponsorOfOutput(enabledAddr)
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"sponsor of falls back to sender": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorOf(sponsoredSender, noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorOfGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackSponsorOfOutput(adminAddr)
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"sponsor of unsponsored": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackSponsorOf(noRoleAddr, noRoleAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.SponsorOfGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.PackSponsorOfOutput(common.Address{})
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			// Set up the state so that each address has the expected permissions, balances and
+			// sponsorships at the start.
+			precompile.SetGasSponsorAllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.SetGasSponsorAllowListStatus(state, enabledAddr, precompile.AllowListEnabled)
+			state.SetBalance(adminAddr, initialBalance)
+			state.SetBalance(enabledAddr, initialBalance)
+			state.SetBalance(noRoleAddr, initialBalance)
+			precompile.RefundSponsor(state, adminAddr, initialDeposit)
+			sponsor := func(caller common.Address, pack func(common.Address) ([]byte, error), account common.Address) {
+				input, err := pack(account)
+				require.NoError(t, err)
+				_, _, err = precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}, snowContext: snow.DefaultContextTest()}, caller, precompile.GasSponsorAddress, input, precompile.SponsorAccountGasCost, false)
+				require.NoError(t, err)
+			}
+			sponsor(adminAddr, precompile.PackSponsorSender, sponsoredSender)
+			sponsor(enabledAddr, precompile.PackSponsorTarget, sponsoredTarget)
+			// Start a new transaction so that only the logs of the tested call are returned.
+			state.Prepare(common.Hash{1}, 0)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			ret, remainingGas, err := precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, test.caller, precompile.GasSponsorAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestGasTokenRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	enabledAddr := common.HexToAddress("0xB2B1B5A6B4A1d8D1F1c7B8c7c1E0d5e6a1f1A2B3")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	recipient := common.Address{1}
+	config := precompile.NewGasTokenConfig(common.Big0, []common.Address{adminAddr}, []common.Address{enabledAddr}, "Gas Token", "GAS", 18,
+		map[common.Address]*math.HexOrDecimal256{
+			adminAddr:  math.NewHexOrDecimal256(1000),
+			noRoleAddr: math.NewHexOrDecimal256(1000),
+		})
+
+	for name, test := range map[string]test{
+		"name": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.GasTokenABI.Pack("name")
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenReadGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("name", "Gas Token")
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"decimals": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.GasTokenABI.Pack("decimals")
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenReadGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("decimals", uint8(18))
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"total supply": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.GasTokenABI.Pack("totalSupply")
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenReadGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("totalSupply", big.NewInt(2000))
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"balance of": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenBalanceOf(adminAddr)
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenReadGasCost,
+			readOnly:    true,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("balanceOf", big.NewInt(1000))
+				require.NoError(t, err)
+
+				return res
+			}(),
+		},
+		"transfer": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransfer(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferGasCost,
+			readOnly:    false,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("transfer", true)
+				require.NoError(t, err)
+
+				return res
+			}(),
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(900), precompile.GetGasTokenBalance(state, noRoleAddr))
+				require.Equal(t, big.NewInt(100), precompile.GetGasTokenBalance(state, recipient))
+				logs := state.GetLogs(common.Hash{1}, common.Hash{})
+				require.Len(t, logs, 1)
+				require.Equal(t, []common.Hash{precompile.GasTokenABI.Events["Transfer"].ID, noRoleAddr.Hash(), recipient.Hash()}, logs[0].Topics)
+				require.Equal(t, common.BigToHash(big.NewInt(100)).Bytes(), logs[0].Data)
+			},
+		},
+		"transfer more than balance fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransfer(recipient, big.NewInt(1001))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrInsufficientGasTokenBalance.Error(),
+		},
+		"readOnly transfer fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransfer(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"transfer insufficient gas": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransfer(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferGasCost - 1,
+			readOnly:    false,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"approve": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenApprove(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenApproveGasCost,
+			readOnly:    false,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("approve", true)
+				require.NoError(t, err)
+
+				return res
+			}(),
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(100), precompile.GetGasTokenAllowance(state, noRoleAddr, recipient))
+				logs := state.GetLogs(common.Hash{1}, common.Hash{})
+				require.Len(t, logs, 1)
+				require.Equal(t, []common.Hash{precompile.GasTokenABI.Events["Approval"].ID, noRoleAddr.Hash(), recipient.Hash()}, logs[0].Topics)
+			},
+		},
+		"transfer from with allowance": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransferFrom(adminAddr, recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferFromGasCost,
+			readOnly:    false,
+			expectedRes: func() []byte {
+				res, err := precompile.GasTokenABI.PackOutput("transferFrom", true)
+				require.NoError(t, err)
+
+				return res
+			}(),
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(900), precompile.GetGasTokenBalance(state, adminAddr))
+				require.Equal(t, big.NewInt(100), precompile.GetGasTokenBalance(state, recipient))
+				require.Equal(t, big.NewInt(50), precompile.GetGasTokenAllowance(state, adminAddr, enabledAddr))
+			},
+		},
+		"transfer from more than allowance fails": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenTransferFrom(adminAddr, recipient, big.NewInt(151))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenTransferFromGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrInsufficientGasTokenAllowance.Error(),
+		},
+		"mint from enabled": {
+			caller: enabledAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenMint(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenMintGasCost,
+			readOnly:    false,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, big.NewInt(100), precompile.GetGasTokenBalance(state, recipient))
+				require.Equal(t, big.NewInt(2100), precompile.GetGasTokenTotalSupply(state))
+				logs := state.GetLogs(common.Hash{1}, common.Hash{})
+				require.Len(t, logs, 1)
+				require.Equal(t, []common.Hash{precompile.GasTokenABI.Events["Transfer"].ID, {}, recipient.Hash()}, logs[0].Topics)
+			},
+		},
+		"mint from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				input, err := precompile.PackGasTokenMint(recipient, big.NewInt(100))
+				require.NoError(t, err)
+
+				return input
+			},
+			suppliedGas: precompile.GasTokenMintGasCost,
+			readOnly:    false,
+			expectedErr: precompile.ErrCannotMintGasToken.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			// Set up the state with the configured metadata, balances and permissions, and an
+			// allowance of [enabledAddr] over the balance of [adminAddr].
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			config.Configure(nil, state, blockContext)
+			input, err := precompile.PackGasTokenApprove(enabledAddr, big.NewInt(150))
+			require.NoError(t, err)
+			_, _, err = precompile.GasTokenPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, adminAddr, precompile.GasTokenAddress, input, precompile.GasTokenApproveGasCost, false)
+			require.NoError(t, err)
+			// Start a new transaction so that only the logs of the tested call are returned.
+			state.Prepare(common.Hash{1}, 0)
+
+			ret, remainingGas, err := precompile.GasTokenPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}, test.caller, precompile.GasTokenAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestGovernanceRelayerRun(t *testing.T) {
+	type test struct {
+		signerKey   *ecdsa.PrivateKey
+		signer      common.Address
+		target      common.Address
+		input       []byte
+		nonce       *big.Int
+		deadline    *big.Int
+		innerGas    uint64
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminKey, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	noRoleKey, _ := crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	adminAddr := crypto.PubkeyToAddress(adminKey.PublicKey)
+	noRoleAddr := crypto.PubkeyToAddress(noRoleKey.PublicKey)
+	relayerAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	enabledAddr := common.Address{1}
+	const timestamp = 100
+	deadline := big.NewInt(timestamp)
+
+	setFeeConfigInput, err := precompile.PackSetFeeConfig(testFeeConfig)
+	require.NoError(t, err)
+	setEnabledInput, err := precompile.PackModifyAllowList(enabledAddr, precompile.AllowListEnabled)
+	require.NoError(t, err)
+	mintInput, err := precompile.PackMintInput(enabledAddr, common.Big1)
+	require.NoError(t, err)
+	// NativeAssetCall takes the recipient, asset ID, amount and call data packed back to back.
+	nativeAssetCallInput := append(append(append(enabledAddr.Bytes(), common.Hash{1}.Bytes()...), common.BigToHash(common.Big1).Bytes()...), 0x00)
+
+	for name, test := range map[string]test{
+		"set fee config signed by admin": {
+			signerKey: adminKey,
+			signer:    adminAddr,
+			target:    precompile.FeeConfigManagerAddress,
+			input:     setFeeConfigInput,
+			nonce:     common.Big0,
+			deadline:  deadline,
+			innerGas:  precompile.SetFeeConfigGasCost,
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, testFeeConfig, precompile.GetStoredFeeConfig(state))
+				require.Equal(t, common.Big1, precompile.GetGovernanceRelayerNonce(state, adminAddr))
+				logs := state.GetLogs(common.Hash{1}, common.Hash{})
+				require.Len(t, logs, 1)
+				require.Equal(t, []common.Hash{precompile.GovernanceRelayerABI.Events["GovernanceCallRelayed"].ID, adminAddr.Hash(), precompile.FeeConfigManagerAddress.Hash(), relayerAddr.Hash()}, logs[0].Topics)
+			},
+		},
+		"set enabled signed by admin": {
+			signerKey: adminKey,
+			signer:    adminAddr,
+			target:    precompile.TxAllowListAddress,
+			input:     setEnabledInput,
+			nonce:     common.Big0,
+			deadline:  deadline,
+			innerGas:  precompile.ModifyAllowListGasCost,
+			assertState: func(t *testing.T, state *state.StateDB) {
+				require.Equal(t, precompile.AllowListEnabled, precompile.GetTxAllowListStatus(state, enabledAddr))
+				require.Equal(t, common.Big1, precompile.GetGovernanceRelayerNonce(state, adminAddr))
+			},
+		},
+		"set fee config signed by no role fails": {
+			signerKey:   noRoleKey,
+			signer:      noRoleAddr,
+			target:      precompile.FeeConfigManagerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			innerGas:    precompile.SetFeeConfigGasCost,
+			expectedErr: precompile.ErrCannotChangeFee.Error(),
+		},
+		"signature of another signer fails": {
+			signerKey:   noRoleKey,
+			signer:      adminAddr,
+			target:      precompile.FeeConfigManagerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			innerGas:    precompile.SetFeeConfigGasCost,
+			expectedErr: precompile.ErrInvalidGovernanceCallSignature.Error(),
+		},
+		"signature with another nonce fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.FeeConfigManagerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big1,
+			deadline:    deadline,
+			innerGas:    precompile.SetFeeConfigGasCost,
+			expectedErr: precompile.ErrInvalidGovernanceCallSignature.Error(),
+		},
+		"expired call fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.FeeConfigManagerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big0,
+			deadline:    big.NewInt(timestamp - 1),
+			innerGas:    precompile.SetFeeConfigGasCost,
+			expectedErr: precompile.ErrGovernanceCallExpired.Error(),
+		},
+		"relay to the relayer fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.GovernanceRelayerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			expectedErr: precompile.ErrGovernanceCallNotAllowed.Error(),
+		},
+		"relay to native asset call fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.NativeAssetCallAddress,
+			input:       nativeAssetCallInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			innerGas:    precompile.NativeAssetCallGasCost,
+			expectedErr: precompile.ErrGovernanceCallNotAllowed.Error(),
+		},
+		"relay of mint fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.ContractNativeMinterAddress,
+			input:       mintInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			innerGas:    precompile.MintGasCost,
+			expectedErr: precompile.ErrGovernanceCallNotAllowed.Error(),
+		},
+		"insufficient gas for the relayed call fails": {
+			signerKey:   adminKey,
+			signer:      adminAddr,
+			target:      precompile.FeeConfigManagerAddress,
+			input:       setFeeConfigInput,
+			nonce:       common.Big0,
+			deadline:    deadline,
+			innerGas:    precompile.SetFeeConfigGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber, timestamp: timestamp}
+			precompile.NewFeeManagerConfig(common.Big0, []common.Address{adminAddr}, nil, nil).Configure(params.TestChainConfig, state, blockContext)
+			precompile.NewTxAllowListConfig(common.Big0, []common.Address{adminAddr}, nil).Configure(params.TestChainConfig, state, blockContext)
+			state.Prepare(common.Hash{1}, 0)
+
+			signature, err := precompile.SignGovernanceCall(test.signerKey, params.TestChainConfig.ChainID, test.target, test.input, test.nonce, test.deadline)
+			require.NoError(t, err)
+			input, err := precompile.PackRelayGovernanceCall(test.signer, test.target, test.input, test.deadline, signature)
+			require.NoError(t, err)
+			suppliedGas := precompile.RelayGovernanceCallGasCost + uint64((len(input)-4+31)/32)*6 + test.innerGas
+
+			accessibleState := &mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}
+			_, remainingGas, err := precompile.GovernanceRelayerPrecompile.Run(accessibleState, relayerAddr, precompile.GovernanceRelayerAddress, input, suppliedGas, false)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+
+			// The signature cannot be replayed.
+			_, _, err = precompile.GovernanceRelayerPrecompile.Run(accessibleState, relayerAddr, precompile.GovernanceRelayerAddress, input, suppliedGas, false)
+			require.ErrorIs(t, err, precompile.ErrInvalidGovernanceCallSignature)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestValidatorInfoRun(t *testing.T) {
+	type test struct {
+		input       func() []byte
+		suppliedGas uint64
+
+		expectedRes func() []byte
+		expectedErr string
+	}
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	withKey := precompile.Validator{NodeID: ids.NodeID{1}, Weight: 100, BLSPublicKey: bls.PublicKeyToBytes(bls.PublicFromSecretKey(sk))}
+	withoutKey := precompile.Validator{NodeID: ids.NodeID{2}, Weight: 50}
+
+	for name, test := range map[string]test{
+		"current epoch": {
+			input:       func() []byte { input, _ := precompile.PackCurrentEpoch(); return input },
+			suppliedGas: precompile.CurrentEpochGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ValidatorInfoABI.PackOutput("currentEpoch", big.NewInt(3), big.NewInt(42))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"validator count": {
+			input:       func() []byte { input, _ := precompile.PackValidatorCount(); return input },
+			suppliedGas: precompile.ValidatorCountGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ValidatorInfoABI.PackOutput("validatorCount", big.NewInt(2))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"total weight": {
+			input:       func() []byte { input, _ := precompile.PackTotalWeight(); return input },
+			suppliedGas: precompile.TotalWeightGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.ValidatorInfoABI.PackOutput("totalWeight", big.NewInt(150))
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"validator at with BLS public key": {
+			input: func() []byte {
+				input, err := precompile.PackValidatorAt(common.Big0)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.ValidatorAtGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.PackValidatorOutput("validatorAt", true, withKey)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"validator at without BLS public key": {
+			input: func() []byte {
+				input, err := precompile.PackValidatorAt(common.Big1)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.ValidatorAtGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.PackValidatorOutput("validatorAt", true, withoutKey)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"validator at out of bounds": {
+			input: func() []byte {
+				input, err := precompile.PackValidatorAt(common.Big2)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.ValidatorAtGasCost,
+			expectedErr: precompile.ErrValidatorOutOfBounds.Error(),
+		},
+		"get validator": {
+			input: func() []byte {
+				input, err := precompile.PackGetValidator(withoutKey.NodeID)
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.GetValidatorGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.PackValidatorOutput("getValidator", true, withoutKey)
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"get non-validator": {
+			input: func() []byte {
+				input, err := precompile.PackGetValidator(ids.NodeID{3})
+				require.NoError(t, err)
+				return input
+			},
+			suppliedGas: precompile.GetValidatorGasCost,
+			expectedRes: func() []byte {
+				res, err := precompile.PackValidatorOutput("getValidator", false, precompile.Validator{})
+				require.NoError(t, err)
+				return res
+			},
+		},
+		"insufficient gas": {
+			input:       func() []byte { input, _ := precompile.PackValidatorCount(); return input },
+			suppliedGas: precompile.ValidatorCountGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+
+			require.NoError(t, precompile.SetValidatorSnapshot(state, 3, 42, []precompile.Validator{withKey, withoutKey}))
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			accessibleState := &mockAccessibleState{state: state, blockContext: blockContext, snowContext: snow.DefaultContextTest()}
+			ret, remainingGas, err := precompile.ValidatorInfoPrecompile.Run(accessibleState, common.Address{}, precompile.ValidatorInfoAddress, test.input(), test.suppliedGas, true)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, test.expectedRes(), ret)
+		})
+	}
+}
+
+var (
+	versionedPrecompileAddress   = common.HexToAddress("0x03000000000000000000000000000000000000f0")
+	versionedPrecompileConfigKey = "versionedPrecompileConfig"
+	versionedValue               = common.HexToHash("0x1234")
+	// versionedBalanceAddress is an account outside of the address space of the versioned precompile,
+	// which it credits on activation and debits when it is disabled.
+	versionedBalanceAddress = common.HexToAddress("0x0100000000000000000000000000000000000000")
+)
+
+// versionedPrecompileConfig is a precompile config with two storage versions, storing
+// [versionedValue] at slot 1 in version 0 and at slot 2 in version 1.
+type versionedPrecompileConfig struct {
+	precompile.UpgradeableConfig
+}
+
+func init() {
+	precompile.RegisterModule(precompile.Module{
+		ConfigKey:      versionedPrecompileConfigKey,
+		Address:        versionedPrecompileAddress,
+		Contract:       precompile.TxAllowListPrecompile,
+		NewConfig:      func() precompile.StatefulPrecompileConfig { return new(versionedPrecompileConfig) },
+		StorageVersion: 1,
+	})
+}
+
+func newVersionedPrecompileConfig(blockTimestamp *big.Int, storageVersion uint64) *versionedPrecompileConfig {
+	return &versionedPrecompileConfig{
+		UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: blockTimestamp, StorageVersion: storageVersion},
+	}
+}
+
+func (c *versionedPrecompileConfig) Address() common.Address { return versionedPrecompileAddress }
+
+func (c *versionedPrecompileConfig) Equal(s precompile.StatefulPrecompileConfig) bool {
+	other, ok := (s).(*versionedPrecompileConfig)
+	return ok && c.UpgradeableConfig.Equal(&other.UpgradeableConfig)
+}
+
+func (c *versionedPrecompileConfig) Configure(_ precompile.ChainConfig, state precompile.StateDB, _ precompile.BlockContext) {
+	state.SetState(versionedPrecompileAddress, common.BigToHash(big.NewInt(int64(c.StorageVersion+1))), versionedValue)
+	state.AddBalance(versionedBalanceAddress, common.Big1)
+}
+
+func (c *versionedPrecompileConfig) Deconfigure(state precompile.StateDB) {
+	state.SubBalance(versionedBalanceAddress, common.Big1)
+}
+
+func (c *versionedPrecompileConfig) Migrate(state precompile.StateDB, fromVersion uint64, toVersion uint64) {
+	if fromVersion == 0 && toVersion == 1 {
+		state.SetState(versionedPrecompileAddress, common.BigToHash(big.NewInt(2)), state.GetState(versionedPrecompileAddress, common.BigToHash(big.NewInt(1))))
+		state.SetState(versionedPrecompileAddress, common.BigToHash(big.NewInt(1)), common.Hash{})
+	}
+}
+
+func (c *versionedPrecompileConfig) Contract() precompile.StatefulPrecompiledContract {
+	return precompile.TxAllowListPrecompile
+}
+
+func (c *versionedPrecompileConfig) Verify() error { return nil }
+
+func (c *versionedPrecompileConfig) String() string { return "versionedPrecompileConfig" }
+
+func TestPrecompileStorageMigration(t *testing.T) {
+	chainConfig := *params.TestChainConfig
+	chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(newVersionedPrecompileConfig(common.Big0, 0))
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(newVersionedPrecompileConfig(big.NewInt(10), 1)),
+		},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	slot1, slot2 := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+
+	// the precompile is configured with the storage layout of version 0 at genesis
+	chainConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0, timestamp: 0}, statedb)
+	require.Equal(t, uint64(0), precompile.GetPrecompileStorageVersion(statedb, versionedPrecompileAddress))
+	require.Equal(t, versionedValue, statedb.GetState(versionedPrecompileAddress, slot1))
+
+	// the storage is migrated to version 1 instead of being configured again
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(5), &mockBlockContext{blockNumber: common.Big1, timestamp: 10}, statedb)
+	require.Equal(t, uint64(1), precompile.GetPrecompileStorageVersion(statedb, versionedPrecompileAddress))
+	require.Equal(t, common.Hash{}, statedb.GetState(versionedPrecompileAddress, slot1))
+	require.Equal(t, versionedValue, statedb.GetState(versionedPrecompileAddress, slot2))
+	require.True(t, chainConfig.IsPrecompileEnabled(versionedPrecompileAddress, common.Big1, big.NewInt(10)))
+
+	// a precompile enabled with a storage version is configured with that version
+	freshConfig := *params.TestChainConfig
+	freshConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(newVersionedPrecompileConfig(common.Big0, 1))
+	statedb, err = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	freshConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0, timestamp: 0}, statedb)
+	require.Equal(t, uint64(1), precompile.GetPrecompileStorageVersion(statedb, versionedPrecompileAddress))
+	require.Equal(t, versionedValue, statedb.GetState(versionedPrecompileAddress, slot2))
+}
+
+func TestFeeConfigManagerStorageMigration(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	chainConfig := *params.TestChainConfig
+	chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewFeeManagerConfig(common.Big0, []common.Address{adminAddr}, nil, nil))
+	migrateConfig := precompile.NewFeeManagerConfig(big.NewInt(10), []common.Address{adminAddr}, nil, nil)
+	migrateConfig.StorageVersion = 1
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{params.NewPrecompileUpgrade(migrateConfig)},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	// version 0 does not store the hash of the fee config
+	chainConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0, timestamp: 0}, statedb)
+	require.NoError(t, precompile.StoreFeeConfig(statedb, testFeeConfig, &mockBlockContext{blockNumber: common.Big1}))
+	require.Equal(t, common.Hash{}, precompile.GetFeeConfigHash(statedb))
+
+	// the migration to version 1 stores the hash of the stored fee config, without changing it
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(5), &mockBlockContext{blockNumber: common.Big2, timestamp: 10}, statedb)
+	require.Equal(t, uint64(1), precompile.GetPrecompileStorageVersion(statedb, precompile.FeeConfigManagerAddress))
+	require.Equal(t, precompile.FeeConfigHash(testFeeConfig), precompile.GetFeeConfigHash(statedb))
+	require.Equal(t, testFeeConfig, precompile.GetStoredFeeConfig(statedb))
+	require.Equal(t, common.Big1, precompile.GetFeeConfigLastChangedAt(statedb))
+	require.Equal(t, precompile.AllowListAdmin, precompile.GetFeeConfigManagerStatus(statedb, adminAddr))
+
+	// the hash is updated with the fee config from then on
+	feeConfig := testFeeConfig
+	feeConfig.MinBaseFee = big.NewInt(50)
+	require.NoError(t, precompile.StoreFeeConfig(statedb, feeConfig, &mockBlockContext{blockNumber: big.NewInt(3)}))
+	require.Equal(t, precompile.FeeConfigHash(feeConfig), precompile.GetFeeConfigHash(statedb))
+	slot, err := precompile.ResolveStorageSlot(precompile.FeeConfigManagerAddress, "feeConfigHash")
+	require.NoError(t, err)
+	require.Equal(t, precompile.FeeConfigHash(feeConfig), statedb.GetState(precompile.FeeConfigManagerAddress, slot))
+
+	// a fee config manager enabled with version 1 stores the hash of its initial fee config
+	freshConfig := *params.TestChainConfig
+	freshConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(&precompile.FeeConfigManagerConfig{
+		UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: common.Big0, StorageVersion: 1},
+		InitialFeeConfig:  &testFeeConfig,
+	})
+	statedb, err = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	freshConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0, timestamp: 0}, statedb)
+	require.Equal(t, precompile.FeeConfigHash(testFeeConfig), precompile.GetFeeConfigHash(statedb))
+}
+
+func TestVerifyPrecompileStorageVersions(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	otherAddr := common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+	for name, test := range map[string]struct {
+		genesis     precompile.StatefulPrecompileConfig
+		upgrades    []precompile.StatefulPrecompileConfig
+		expectedErr string
+	}{
+		"migrate to greater version": {
+			genesis:  newVersionedPrecompileConfig(common.Big0, 0),
+			upgrades: []precompile.StatefulPrecompileConfig{newVersionedPrecompileConfig(big.NewInt(1), 1)},
+		},
+		"enable with the same version": {
+			genesis:     newVersionedPrecompileConfig(common.Big0, 1),
+			upgrades:    []precompile.StatefulPrecompileConfig{newVersionedPrecompileConfig(big.NewInt(1), 1)},
+			expectedErr: "disable should be [true]",
+		},
+		"unsupported version": {
+			genesis:     newVersionedPrecompileConfig(common.Big0, 0),
+			upgrades:    []precompile.StatefulPrecompileConfig{newVersionedPrecompileConfig(big.NewInt(1), 2)},
+			expectedErr: "versionedPrecompileConfig storage version (2) > latest supported storage version (1)",
+		},
+		"migrate with other parameters": {
+			genesis: precompile.NewFeeManagerConfig(common.Big0, []common.Address{adminAddr}, nil, nil),
+			upgrades: []precompile.StatefulPrecompileConfig{&precompile.FeeConfigManagerConfig{
+				AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: []common.Address{otherAddr}},
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1), StorageVersion: 1},
+			}},
+			expectedErr: "feeManagerConfig cannot change the parameters of the precompile when migrating storage",
+		},
+		"migrate with the same parameters": {
+			genesis: precompile.NewFeeManagerConfig(common.Big0, []common.Address{adminAddr}, nil, nil),
+			upgrades: []precompile.StatefulPrecompileConfig{&precompile.FeeConfigManagerConfig{
+				AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: []common.Address{adminAddr}},
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1), StorageVersion: 1},
+			}},
+		},
+		"unversioned precompile": {
+			genesis: &precompile.TxAllowListConfig{
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: common.Big0, StorageVersion: 1},
+			},
+			expectedErr: "txAllowListConfig storage version (1) > latest supported storage version (0)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chainConfig := *params.TestChainConfig
+			chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(test.genesis)
+			chainConfig.UpgradeConfig = params.UpgradeConfig{}
+			for _, upgrade := range test.upgrades {
+				chainConfig.PrecompileUpgrades = append(chainConfig.PrecompileUpgrades, params.NewPrecompileUpgrade(upgrade))
+			}
+			err := chainConfig.Verify()
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPrecompileDeconfigure(t *testing.T) {
+	chainConfig := *params.TestChainConfig
+	chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(newVersionedPrecompileConfig(common.Big0, 0))
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(&versionedPrecompileConfig{
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(10), Disable: true},
+			}),
+		},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	chainConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0, timestamp: 0}, statedb)
+	require.Equal(t, common.Big1, statedb.GetBalance(versionedBalanceAddress))
+	require.Equal(t, versionedValue, statedb.GetState(versionedPrecompileAddress, common.BigToHash(common.Big1)))
+
+	// disabling the precompile calls its Deconfigure hook then wipes its own state
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(5), &mockBlockContext{blockNumber: common.Big1, timestamp: 10}, statedb)
+	require.Zero(t, statedb.GetBalance(versionedBalanceAddress).Sign())
+	require.Equal(t, common.Hash{}, statedb.GetState(versionedPrecompileAddress, common.BigToHash(common.Big1)))
+	require.Zero(t, statedb.GetNonce(versionedPrecompileAddress))
+	require.False(t, chainConfig.IsPrecompileEnabled(versionedPrecompileAddress, common.Big1, big.NewInt(10)))
+}
+
+func TestCheckConfigurePrecompilesByBlockNumber(t *testing.T) {
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	chainConfig := *params.TestChainConfig
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(&precompile.TxAllowListConfig{
+				AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: []common.Address{admin}},
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockNumber: big.NewInt(3)},
+			}),
+		},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	// the timestamp of the block does not matter
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(100), &mockBlockContext{blockNumber: big.NewInt(2), timestamp: 200}, statedb)
+	require.Equal(t, precompile.AllowListNoRole, precompile.GetTxAllowListStatus(statedb, admin))
+
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(200), &mockBlockContext{blockNumber: big.NewInt(3), timestamp: 200}, statedb)
+	require.Equal(t, precompile.AllowListAdmin, precompile.GetTxAllowListStatus(statedb, admin))
+	rules := chainConfig.AvalancheRules(big.NewInt(3), common.Big0)
+	require.True(t, rules.IsPrecompileEnabled(precompile.TxAllowListAddress))
+
+	// the precompile is not configured again by the next block
+	precompile.SetTxAllowListStatus(statedb, admin, precompile.AllowListEnabled)
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(200), &mockBlockContext{blockNumber: big.NewInt(4), timestamp: 300}, statedb)
+	require.Equal(t, precompile.AllowListEnabled, precompile.GetTxAllowListStatus(statedb, admin))
+}
+
+func TestCheckConfigurePrecompilesInitialState(t *testing.T) {
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	slot, value := common.Hash{0xaa}, common.Hash{0xbb}
+	topic := common.Hash{0xcc}
+	chainConfig := *params.TestChainConfig
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(&precompile.TxAllowListConfig{
+				AllowListConfig: precompile.AllowListConfig{AllowListAdmins: []common.Address{admin}},
+				UpgradeableConfig: precompile.UpgradeableConfig{
+					BlockTimestamp: big.NewInt(10),
+					InitialStorage: map[common.Hash]common.Hash{slot: value},
+					InitialEvents:  []precompile.InitialEvent{{Topics: []common.Hash{topic}, Data: []byte{1}}},
+				},
+			}),
+		},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(5), &mockBlockContext{blockNumber: big.NewInt(1), timestamp: 10}, statedb)
+	require.Equal(t, precompile.AllowListAdmin, precompile.GetTxAllowListStatus(statedb, admin))
+	require.Equal(t, value, statedb.GetState(precompile.TxAllowListAddress, slot))
+	logs := statedb.Logs()
+	require.Len(t, logs, 1)
+	require.Equal(t, precompile.TxAllowListAddress, logs[0].Address)
+	require.Equal(t, []common.Hash{topic}, logs[0].Topics)
+	require.Equal(t, []byte{1}, logs[0].Data)
+	require.EqualValues(t, 1, logs[0].BlockNumber)
+}
+
+func TestPrecompileEvents(t *testing.T) {
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	account := common.HexToAddress("0x0300000000000000000000000000000000000043")
+	enabled := true
+	chainConfig := *params.TestChainConfig
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		ParameterUpgrades: []params.ParameterUpgrade{{BlockTimestamp: big.NewInt(10), PrecompileEvents: &enabled}},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	precompile.SetTxAllowListStatus(statedb, admin, precompile.AllowListAdmin)
+	precompile.SetFeeConfigManagerStatus(statedb, admin, precompile.AllowListAdmin)
+	precompile.SetContractNativeMinterStatus(statedb, admin, precompile.AllowListAdmin)
+	require.NoError(t, precompile.StoreFeeConfig(statedb, testFeeConfig, &mockBlockContext{blockNumber: common.Big0}))
+
+	run := func(contract precompile.StatefulPrecompiledContract, addr common.Address, input []byte, timestamp uint64) uint64 {
+		accessibleState := &mockAccessibleState{
+			state:        statedb,
+			blockContext: &mockBlockContext{blockNumber: common.Big1, timestamp: timestamp},
+			snowContext:  snow.DefaultContextTest(),
+			chainConfig:  &chainConfig,
+		}
+		_, remainingGas, err := contract.Run(accessibleState, admin, addr, input, math.MaxUint64, false)
+		require.NoError(t, err)
+		return math.MaxUint64 - remainingGas
+	}
+	unpackLastLog := func(addr common.Address) (string, map[string]interface{}) {
+		logs := statedb.Logs()
+		require.NotEmpty(t, logs)
+		require.Equal(t, addr, logs[len(logs)-1].Address)
+		name, args, err := precompile.UnpackPrecompileEvent(logs[len(logs)-1].Topics, logs[len(logs)-1].Data)
+		require.NoError(t, err)
+		return name, args
+	}
+
+	// Precompiles do not emit events before they are enabled by a parameter upgrade.
+	input, err := precompile.PackModifyAllowList(account, precompile.AllowListEnabled)
+	require.NoError(t, err)
+	require.EqualValues(t, precompile.ModifyAllowListGasCost, run(precompile.TxAllowListPrecompile, precompile.TxAllowListAddress, input, 5))
+	require.Empty(t, statedb.Logs())
+
+	// Changing an allow list role emits RoleSet, paying for the log.
+	require.EqualValues(t, precompile.ModifyAllowListGasCost+4*375+375, run(precompile.TxAllowListPrecompile, precompile.TxAllowListAddress, input, 10))
+	name, args := unpackLastLog(precompile.TxAllowListAddress)
+	require.Equal(t, "RoleSet", name)
+	require.Equal(t, common.Hash(precompile.AllowListEnabled).Big(), args["role"])
+	require.Equal(t, account, args["account"])
+	require.Equal(t, admin, args["sender"])
+
+	// Changing the fee config emits FeeConfigChanged with the new fee config.
+	feeConfig := testFeeConfig
+	feeConfig.MinBaseFee = big.NewInt(42)
+	input, err = precompile.PackSetFeeConfig(feeConfig)
+	require.NoError(t, err)
+	run(precompile.FeeConfigManagerPrecompile, precompile.FeeConfigManagerAddress, input, 10)
+	name, args = unpackLastLog(precompile.FeeConfigManagerAddress)
+	require.Equal(t, "FeeConfigChanged", name)
+	require.Equal(t, admin, args["sender"])
+	require.Equal(t, feeConfig.GasLimit, args["gasLimit"])
+	require.Equal(t, big.NewInt(42), args["minBaseFee"])
+
+	// Minting native coins emits NativeCoinMinted.
+	input, err = precompile.PackMintInput(account, big.NewInt(100))
+	require.NoError(t, err)
+	run(precompile.ContractNativeMinterPrecompile, precompile.ContractNativeMinterAddress, input, 10)
+	name, args = unpackLastLog(precompile.ContractNativeMinterAddress)
+	require.Equal(t, "NativeCoinMinted", name)
+	require.Equal(t, admin, args["sender"])
+	require.Equal(t, account, args["recipient"])
+	require.Equal(t, big.NewInt(100), args["amount"])
+}
+
+func TestCachedPrecompileReads(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	require.NoError(t, err)
+
+	addr := common.HexToAddress("0x0123")
+	precompile.SetAllowListRole(state, precompile.TxAllowListAddress, addr, precompile.AllowListEnabled)
+	require.NoError(t, precompile.StoreFeeConfig(state, testFeeConfig, &mockBlockContext{blockNumber: testBlockNumber}))
+
+	// The first reads populate the cache, the subsequent ones are served from it
+	for i := 0; i < 2; i++ {
+		require.Equal(t, precompile.AllowListEnabled, precompile.GetAllowListRole(state, precompile.TxAllowListAddress, addr))
+		require.Equal(t, testFeeConfig, precompile.GetStoredFeeConfig(state))
+	}
+
+	// Writes invalidate the cached reads
+	snapshot := state.Snapshot()
+	feeConfig := testFeeConfig
+	feeConfig.GasLimit = big.NewInt(10_000_000)
+	precompile.SetAllowListRole(state, precompile.TxAllowListAddress, addr, precompile.AllowListAdmin)
+	require.NoError(t, precompile.StoreFeeConfig(state, feeConfig, &mockBlockContext{blockNumber: testBlockNumber}))
+	require.Equal(t, precompile.AllowListAdmin, precompile.GetAllowListRole(state, precompile.TxAllowListAddress, addr))
+	require.Equal(t, feeConfig, precompile.GetStoredFeeConfig(state))
+
+	// Reverting the writes invalidates the reads cached after them
+	state.RevertToSnapshot(snapshot)
+	require.Equal(t, precompile.AllowListEnabled, precompile.GetAllowListRole(state, precompile.TxAllowListAddress, addr))
+	require.Equal(t, testFeeConfig, precompile.GetStoredFeeConfig(state))
+
+	// The values returned are not affected by modifications of previously returned ones
+	precompile.GetStoredFeeConfig(state).GasLimit.SetUint64(1)
+	require.Equal(t, testFeeConfig, precompile.GetStoredFeeConfig(state))
+}
+
+// Test that the native token bridge precompiles cannot be disabled and enabled again, which would wipe
+// their replay protection and allow the transfers already received to be replayed.
+func TestNativeTokenBridgeDisable(t *testing.T) {
+	chainID := ids.GenerateTestID()
+	for name, test := range map[string]struct {
+		enable  func(*big.Int) precompile.StatefulPrecompileConfig
+		disable precompile.StatefulPrecompileConfig
+	}{
+		"native token source": {
+			enable: func(blockTimestamp *big.Int) precompile.StatefulPrecompileConfig {
+				return precompile.NewNativeTokenSourceConfig(blockTimestamp, chainID)
+			},
+			disable: &precompile.NativeTokenSourceConfig{
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(10), Disable: true},
+			},
+		},
+		"native token destination": {
+			enable: func(blockTimestamp *big.Int) precompile.StatefulPrecompileConfig {
+				return precompile.NewNativeTokenDestinationConfig(blockTimestamp, chainID, nil, 0)
+			},
+			disable: &precompile.NativeTokenDestinationConfig{
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(10), Disable: true},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			chainConfig := *params.TestChainConfig
+			chainConfig.PrecompileUpgrade = params.NewPrecompileUpgrade(test.enable(common.Big0))
+			chainConfig.UpgradeConfig = params.UpgradeConfig{
+				PrecompileUpgrades: []params.PrecompileUpgrade{
+					params.NewPrecompileUpgrade(test.disable),
+					params.NewPrecompileUpgrade(test.enable(big.NewInt(20))),
+				},
+			}
+			require.ErrorIs(t, chainConfig.Verify(), precompile.ErrNativeTokenBridgeDisabled)
+		})
+	}
+}
+
+func TestNativeTokenBridgeRun(t *testing.T) {
+	type test struct {
+		signers         []int // canonical indices of the validators signing the transfer
+		sourceChainID   ids.ID
+		destChainID     ids.ID
+		amount          *big.Int
+		rateLimit       *big.Int
+		skipSnapshot    bool
+		skipMinterRole  bool
+		expectedErr     string
+		expectedBalance *big.Int
+	}
+
+	const (
+		timestamp    = 100
+		pChainHeight = 42
+	)
+	var (
+		sourceChainID = ids.GenerateTestID()
+		sourceSubnet  = ids.GenerateTestID()
+		destChainID   = ids.GenerateTestID()
+		senderAddr    = common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+		recipientAddr = common.HexToAddress("0x0Fa8EA536Be85F32724D57A37758761B86416123")
+		relayerAddr   = common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	)
+
+	// The source subnet has three validators with 50%, 30% and 20% of the weight.
+	keys := make(map[string]*bls.SecretKey)
+	validatorSet := make(map[ids.NodeID]*validators.GetValidatorOutput)
+	for i, weight := range []uint64{50, 30, 20} {
+		sk, err := bls.NewSecretKey()
+		require.NoError(t, err)
+		pk := bls.PublicFromSecretKey(sk)
+		keys[string(bls.PublicKeyToBytes(pk))] = sk
+		nodeID := ids.NodeID{byte(i + 1)}
+		validatorSet[nodeID] = &validators.GetValidatorOutput{NodeID: nodeID, PublicKey: pk, Weight: weight}
+	}
+	validatorState := &validators.TestState{
+		GetSubnetIDF: func(_ context.Context, chainID ids.ID) (ids.ID, error) {
+			require.Equal(t, sourceChainID, chainID)
+			return sourceSubnet, nil
+		},
+		GetValidatorSetF: func(_ context.Context, height uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			require.Equal(t, uint64(pChainHeight), height)
+			require.Equal(t, sourceSubnet, subnetID)
+			return validatorSet, nil
+		},
+	}
+	canonical, _, err := teleporter.GetCanonicalValidatorSet(context.Background(), validatorState, pChainHeight, sourceSubnet)
+	require.NoError(t, err)
+	heaviest := make([]int, 0, len(canonical))
+	for _, weight := range []uint64{50, 30, 20} {
+		for i, vdr := range canonical {
+			if vdr.Weight == weight {
+				heaviest = append(heaviest, i)
+			}
+		}
+	}
+
+	// sign returns the signed [msg] aggregating the signatures of the canonical validators at [indices].
+	sign := func(t *testing.T, msg *teleporter.UnsignedMessage, indices []int) []byte {
+		signers := set.NewBits()
+		signatures := make([]*bls.Signature, 0, len(indices))
+		for _, index := range indices {
+			signers.Add(index)
+			signatures = append(signatures, bls.Sign(keys[string(bls.PublicKeyToBytes(canonical[index].PublicKey))], msg.Bytes()))
+		}
+		aggregate, err := bls.AggregateSignatures(signatures)
+		require.NoError(t, err)
+		signature := &teleporter.BitSetSignature{Signers: signers.Bytes()}
+		copy(signature.Signature[:], bls.SignatureToBytes(aggregate))
+		signed, err := teleporter.NewMessage(msg, signature)
+		require.NoError(t, err)
+		return signed.Bytes()
+	}
+
+	for name, test := range map[string]test{
+		"transfer signed by a quorum": {
+			signers:         heaviest[:2],
+			amount:          big.NewInt(1000),
+			expectedBalance: big.NewInt(1000),
+		},
+		"transfer within the rate limit": {
+			signers:         heaviest[:2],
+			amount:          big.NewInt(1000),
+			rateLimit:       big.NewInt(1000),
+			expectedBalance: big.NewInt(1000),
+		},
+		"transfer exceeding the rate limit fails": {
+			signers:     heaviest[:2],
+			amount:      big.NewInt(1000),
+			rateLimit:   big.NewInt(999),
+			expectedErr: precompile.ErrTransferRateLimitExceeded.Error(),
+		},
+		"transfer signed below the quorum fails": {
+			signers:     heaviest[1:],
+			amount:      big.NewInt(1000),
+			expectedErr: precompile.ErrInvalidTransferSignature.Error(),
+		},
+		"transfer from another chain fails": {
+			signers:       heaviest[:2],
+			sourceChainID: ids.GenerateTestID(),
+			amount:        big.NewInt(1000),
+			expectedErr:   precompile.ErrInvalidTransferMessage.Error(),
+		},
+		"transfer to another chain fails": {
+			signers:     heaviest[:2],
+			destChainID: ids.GenerateTestID(),
+			amount:      big.NewInt(1000),
+			expectedErr: precompile.ErrInvalidTransferMessage.Error(),
+		},
+		"transfer without validator snapshot fails": {
+			signers:      heaviest[:2],
+			amount:       big.NewInt(1000),
+			skipSnapshot: true,
+			expectedErr:  precompile.ErrMissingValidatorSnapshot.Error(),
+		},
+		"transfer without minter role fails": {
+			signers:        heaviest[:2],
+			amount:         big.NewInt(1000),
+			skipMinterRole: true,
+			expectedErr:    precompile.ErrCannotMint.Error(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			require.NoError(t, err)
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber, timestamp: timestamp}
+			state.Prepare(common.Hash{1}, 0)
+
+			// Lock the amount on the source chain.
+			sourceSnowCtx := snow.DefaultContextTest()
+			sourceSnowCtx.ChainID = sourceChainID
+			if test.sourceChainID != ids.Empty {
+				sourceSnowCtx.ChainID = test.sourceChainID
+			}
+			expectedDestChainID := destChainID
+			if test.destChainID != ids.Empty {
+				expectedDestChainID = test.destChainID
+			}
+			precompile.NewNativeTokenSourceConfig(common.Big0, expectedDestChainID).Configure(params.TestChainConfig, state, blockContext)
+			state.AddBalance(senderAddr, test.amount)
+			transferInput, err := precompile.PackTransferToDestination(recipientAddr, test.amount)
+			require.NoError(t, err)
+			accessibleState := &mockAccessibleState{state: state, blockContext: blockContext, snowContext: sourceSnowCtx}
+			_, remainingGas, err := precompile.NativeTokenSourcePrecompile.Run(accessibleState, senderAddr, precompile.NativeTokenSourceAddress, transferInput, precompile.TransferToDestinationGasCost, false)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+			require.Zero(t, state.GetBalance(senderAddr).Sign())
+			require.Equal(t, test.amount, state.GetBalance(precompile.NativeTokenSourceAddress))
+			require.Equal(t, common.Big1, precompile.GetNativeTokenSourceNonce(state))
+
+			logs := state.GetLogs(common.Hash{1}, common.Hash{})
+			require.Len(t, logs, 1)
+			event, err := precompile.NativeTokenSourceABI.Unpack("TransferSent", logs[0].Data)
+			require.NoError(t, err)
+			msg, err := teleporter.ParseUnsignedMessage(event[2].([]byte))
+			require.NoError(t, err)
+			require.True(t, precompile.IsNativeTokenMessageSent(state, precompile.NativeTokenMessageID(msg)))
+
+			// Mint it on the destination chain, which shares the state of the test.
+			destSnowCtx := snow.DefaultContextTest()
+			destSnowCtx.ChainID = destChainID
+			destSnowCtx.ValidatorState = validatorState
+			rateLimitPeriod := uint64(0)
+			if test.rateLimit != nil {
+				rateLimitPeriod = 60
+			}
+			precompile.NewNativeTokenDestinationConfig(common.Big0, sourceChainID, test.rateLimit, rateLimitPeriod).Configure(params.TestChainConfig, state, blockContext)
+			if !test.skipMinterRole {
+				precompile.NewContractNativeMinterConfig(common.Big0, nil, []common.Address{precompile.NativeTokenDestinationAddress}, nil).Configure(params.TestChainConfig, state, blockContext)
+			}
+			if !test.skipSnapshot {
+				require.NoError(t, precompile.SetValidatorSnapshot(state, 1, pChainHeight, nil))
+			}
+			receiveInput, err := precompile.PackReceiveTransfer(sign(t, msg, test.signers))
+			require.NoError(t, err)
+			suppliedGas := precompile.ReceiveTransferGasCost + uint64(len(test.signers))*500 + precompile.MintGasCost
+
+			accessibleState.snowContext = destSnowCtx
+			ret, remainingGas, err := precompile.NativeTokenDestinationPrecompile.Run(accessibleState, relayerAddr, precompile.NativeTokenDestinationAddress, receiveInput, suppliedGas, false)
+			if len(test.expectedErr) != 0 {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), remainingGas)
+			require.Equal(t, common.Hash{}.Bytes(), ret)
+			require.Equal(t, test.expectedBalance, state.GetBalance(recipientAddr))
+			require.True(t, precompile.IsNativeTokenTransferReceived(state, common.Big0))
+
+			logs = state.GetLogs(common.Hash{1}, common.Hash{})
+			require.Len(t, logs, 2)
+			require.Equal(t, []common.Hash{precompile.NativeTokenDestinationABI.Events["TransferReceived"].ID, {}, recipientAddr.Hash(), relayerAddr.Hash()}, logs[1].Topics)
+
+			// The transfer cannot be replayed.
+			_, _, err = precompile.NativeTokenDestinationPrecompile.Run(accessibleState, relayerAddr, precompile.NativeTokenDestinationAddress, receiveInput, suppliedGas, false)
+			require.ErrorIs(t, err, precompile.ErrTransferAlreadyReceived)
+		})
+	}
+}
@@ -27,9 +27,11 @@
 package core
 
 import (
+	"math/big"
 	"sync"
 
 	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
 )
 
 // txSenderCacherRequest is a request for recovering transaction senders with a
@@ -114,6 +116,15 @@ func (cacher *TxSenderCacher) Recover(signer types.Signer, txs []*types.Transact
 	}
 }
 
+// RecoverFromBlocks recovers the senders from the transactions of [blocks] with
+// the signer of each block, so that the senders of the later blocks are
+// recovered while the earlier ones are processed.
+func (cacher *TxSenderCacher) RecoverFromBlocks(config *params.ChainConfig, blocks []*types.Block) {
+	for _, block := range blocks {
+		cacher.Recover(types.MakeSigner(config, block.Number(), new(big.Int).SetUint64(block.Time())), block.Transactions())
+	}
+}
+
 // Shutdown stops the threads started by newTxSenderCacher
 func (cacher *TxSenderCacher) Shutdown() {
 	// Hold the lock on tasksMu to make sure we don't close
@@ -100,6 +100,23 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrNonceGapTooLarge is returned if the nonce of a remote transaction is too
+	// far ahead of the next nonce of its sender. This is not a consensus error
+	// making the transaction invalid, rather a DOS protection.
+	ErrNonceGapTooLarge = errors.New("nonce gap too large")
+
+	// ErrBlobDataRejected is returned if a blob transaction carries its sidecar
+	// while the blob data mode of the chain does not accept blob data.
+	ErrBlobDataRejected = errors.New("blob data rejected")
+
+	// ErrInflightTxLimitReached is returned when an account delegating its code
+	// already has a transaction in the pool.
+	ErrInflightTxLimitReached = errors.New("in-flight transaction limit reached for delegated accounts")
+
+	// ErrAuthorityReserved is returned when a set code transaction authorizes an
+	// account with several transactions in the pool.
+	ErrAuthorityReserved = errors.New("authority already reserved")
 )
 
 var (
@@ -110,17 +127,22 @@ var (
 
 var (
 	// Metrics for the pending pool
-	pendingDiscardMeter   = metrics.NewRegisteredMeter("txpool/pending/discard", nil)
-	pendingReplaceMeter   = metrics.NewRegisteredMeter("txpool/pending/replace", nil)
-	pendingRateLimitMeter = metrics.NewRegisteredMeter("txpool/pending/ratelimit", nil) // Dropped due to rate limiting
-	pendingNofundsMeter   = metrics.NewRegisteredMeter("txpool/pending/nofunds", nil)   // Dropped due to out-of-funds
+	pendingDiscardMeter    = metrics.NewRegisteredMeter("txpool/pending/discard", nil)
+	pendingReplaceMeter    = metrics.NewRegisteredMeter("txpool/pending/replace", nil)
+	pendingRateLimitMeter  = metrics.NewRegisteredMeter("txpool/pending/ratelimit", nil)  // Dropped due to rate limiting
+	pendingNofundsMeter    = metrics.NewRegisteredMeter("txpool/pending/nofunds", nil)    // Dropped due to out-of-funds
+	pendingDisallowedMeter = metrics.NewRegisteredMeter("txpool/pending/disallowed", nil) // Dropped due to allow lists
+	pendingExpiredMeter    = metrics.NewRegisteredMeter("txpool/pending/expired", nil)    // Dropped due to max tx age
 
 	// Metrics for the queued pool
-	queuedDiscardMeter   = metrics.NewRegisteredMeter("txpool/queued/discard", nil)
-	queuedReplaceMeter   = metrics.NewRegisteredMeter("txpool/queued/replace", nil)
-	queuedRateLimitMeter = metrics.NewRegisteredMeter("txpool/queued/ratelimit", nil) // Dropped due to rate limiting
-	queuedNofundsMeter   = metrics.NewRegisteredMeter("txpool/queued/nofunds", nil)   // Dropped due to out-of-funds
-	queuedEvictionMeter  = metrics.NewRegisteredMeter("txpool/queued/eviction", nil)  // Dropped due to lifetime
+	queuedDiscardMeter    = metrics.NewRegisteredMeter("txpool/queued/discard", nil)
+	queuedReplaceMeter    = metrics.NewRegisteredMeter("txpool/queued/replace", nil)
+	queuedRateLimitMeter  = metrics.NewRegisteredMeter("txpool/queued/ratelimit", nil)  // Dropped due to rate limiting
+	queuedNofundsMeter    = metrics.NewRegisteredMeter("txpool/queued/nofunds", nil)    // Dropped due to out-of-funds
+	queuedEvictionMeter   = metrics.NewRegisteredMeter("txpool/queued/eviction", nil)   // Dropped due to lifetime
+	queuedDisallowedMeter = metrics.NewRegisteredMeter("txpool/queued/disallowed", nil) // Dropped due to allow lists
+	queuedExpiredMeter    = metrics.NewRegisteredMeter("txpool/queued/expired", nil)    // Dropped due to max tx age
+	queuedNonceGapMeter   = metrics.NewRegisteredMeter("txpool/queued/noncegap", nil)   // Rejected due to max nonce gap
 
 	// General tx metrics
 	knownTxMeter       = metrics.NewRegisteredMeter("txpool/known", nil)
@@ -142,6 +164,9 @@ var (
 	localGauge   = metrics.NewRegisteredGauge("txpool/local", nil)
 	slotsGauge   = metrics.NewRegisteredGauge("txpool/slots", nil)
 
+	// queuedAccountsGauge is the number of accounts with non-executable transactions
+	queuedAccountsGauge = metrics.NewRegisteredGauge("txpool/queued/accounts", nil)
+
 	reheapTimer = metrics.NewRegisteredTimer("txpool/reheap", nil)
 )
 
@@ -182,6 +207,11 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	MaxTxAge time.Duration // Maximum amount of time a remote transaction is kept in the pool since first seen (0 = unlimited)
+
+	MaxNonceGap uint64 // Maximum distance of the nonce of a remote transaction from the next nonce of its sender (0 = unlimited)
+
+	MinGasPrice uint64 // Operator-level minimum gas fee cap to enforce for remote transactions, independent of the base fee (0 = disabled)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -237,6 +267,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.MaxTxAge < 0 {
+		log.Warn("Sanitizing invalid txpool max tx age", "provided", conf.MaxTxAge, "updated", DefaultTxPoolConfig.MaxTxAge)
+		conf.MaxTxAge = DefaultTxPoolConfig.MaxTxAge
+	}
 	return conf
 }
 
@@ -253,6 +287,7 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	minimumFee  *big.Int
+	minGasPrice *big.Int // Operator-level minimum gas fee cap of remote transactions
 	txFeed      event.Feed
 	headFeed    event.Feed
 	reorgFeed   event.Feed
@@ -263,6 +298,8 @@ type TxPool struct {
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
+	eip4844  bool // Fork indicator whether we are using EIP-4844 blob transactions.
+	eip7702  bool // Fork indicator whether we are using EIP-7702 set code transactions.
 
 	currentHead *types.Header
 	// [currentState] is the state of the blockchain head. It is reset whenever
@@ -328,6 +365,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		initDoneCh:          make(chan struct{}),
 		generalShutdownChan: make(chan struct{}),
 		gasPrice:            new(big.Int).SetUint64(config.PriceLimit),
+		minGasPrice:         new(big.Int).SetUint64(config.MinGasPrice),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
@@ -403,6 +441,7 @@ func (pool *TxPool) loop() {
 		case <-report.C:
 			pool.mu.RLock()
 			pending, queued := pool.stats()
+			queuedAccountsGauge.Update(int64(len(pool.queue)))
 			pool.mu.RUnlock()
 			stales := int(atomic.LoadInt64(&pool.priced.stales))
 
@@ -428,6 +467,9 @@ func (pool *TxPool) loop() {
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			if pool.config.MaxTxAge > 0 {
+				pool.evictExpired(time.Now().Add(-pool.config.MaxTxAge))
+			}
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -443,6 +485,40 @@ func (pool *TxPool) loop() {
 	}
 }
 
+// evictExpired removes the remote transactions first seen before [cutoff], so that stale
+// transactions cannot suddenly execute long after they were submitted, for example after
+// a drop of the base fee.
+//
+// Note, the caller must hold pool.mu.
+func (pool *TxPool) evictExpired(cutoff time.Time) {
+	expired := func(lists map[common.Address]*txList) []common.Hash {
+		var hashes []common.Hash
+		for addr, list := range lists {
+			// Skip local transactions from the eviction mechanism
+			if pool.locals.contains(addr) {
+				continue
+			}
+			for _, tx := range list.Flatten() {
+				if tx.FirstSeen().Before(cutoff) {
+					hashes = append(hashes, tx.Hash())
+				}
+			}
+		}
+		return hashes
+	}
+	// Collect both sets before removing anything, since removing a pending
+	// transaction moves the later transactions of its sender to the queue.
+	pending, queued := expired(pool.pending), expired(pool.queue)
+	for _, hash := range pending {
+		pool.removeTx(hash, true)
+	}
+	for _, hash := range queued {
+		pool.removeTx(hash, true)
+	}
+	pendingExpiredMeter.Mark(int64(len(pending)))
+	queuedExpiredMeter.Mark(int64(len(queued)))
+}
+
 // Stop terminates the transaction pool.
 func (pool *TxPool) Stop() {
 	// Unsubscribe all subscriptions registered from txpool
@@ -506,6 +582,37 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// MinGasPrice returns the operator-level minimum gas fee cap enforced by the
+// transaction pool for remote transactions.
+func (pool *TxPool) MinGasPrice() *big.Int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return new(big.Int).Set(pool.minGasPrice)
+}
+
+// SetMinGasPrice updates the operator-level minimum gas fee cap required by the
+// transaction pool for a new remote transaction, and drops all remote transactions
+// below this threshold. Unlike the minimum fee, this floor is not part of consensus
+// and is not derived from the fee config, so each node can set its own.
+func (pool *TxPool) SetMinGasPrice(price *big.Int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	old := pool.minGasPrice
+	pool.minGasPrice = new(big.Int).Set(price)
+	// if the floor increased, remove remote transactions below the new threshold
+	if price.Cmp(old) > 0 {
+		drop := pool.all.RemotesBelowFeeCap(price)
+		for _, tx := range drop {
+			pool.removeTx(tx.Hash(), false)
+		}
+		pool.priced.Removed(len(drop))
+	}
+
+	log.Info("Transaction pool minimum gas price updated", "price", price)
+}
+
 func (pool *TxPool) SetMinFee(minFee *big.Int) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
@@ -658,6 +765,34 @@ func (pool *TxPool) Locals() []common.Address {
 	return pool.locals.flatten()
 }
 
+// AddLocalAccount marks [addr] as local, so that its transactions are exempt from price-based eviction and
+// prioritized by the miner. The transactions of [addr] already in the pool are migrated to the locals.
+func (pool *TxPool) AddLocalAccount(addr common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.locals.contains(addr) {
+		return
+	}
+	pool.locals.add(addr)
+	pool.priced.Removed(pool.all.RemoteToLocals(pool.locals))
+}
+
+// RemoveLocalAccount stops treating [addr] as local. The transactions of [addr] already in the pool are
+// migrated to the remotes, so that they are subject to price-based eviction again.
+func (pool *TxPool) RemoveLocalAccount(addr common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if !pool.locals.contains(addr) {
+		return
+	}
+	pool.locals.remove(addr)
+	for _, tx := range pool.all.LocalToRemotes(pool.locals) {
+		pool.priced.Put(tx, false)
+	}
+}
+
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -679,10 +814,17 @@ func (pool *TxPool) checkTxState(from common.Address, tx *types.Transaction) err
 	pool.currentStateLock.Lock()
 	defer pool.currentStateLock.Unlock()
 
-	// cost == V + GP * GL
-	if balance, cost := pool.currentState.GetBalance(from), tx.Cost(); balance.Cmp(cost) < 0 {
+	// cost == V + GP * GL, or V if the gas is sponsored or paid in the gas token
+	if balance, cost := pool.currentState.GetBalance(from), pool.senderCost(from, tx); balance.Cmp(cost) < 0 {
 		return fmt.Errorf("%w: address %s have (%d) want (%d)", ErrInsufficientFunds, from.Hex(), balance, cost)
 	}
+	// gas token cost == GP * GL, if the gas is paid in the gas token
+	if pool.chainconfig.IsGasToken(pool.currentHead.Number, big.NewInt(int64(pool.currentHead.Time))) {
+		balance, cost := precompile.GetGasTokenBalance(pool.currentState, from), new(big.Int).Sub(tx.Cost(), tx.Value())
+		if balance.Cmp(cost) < 0 {
+			return fmt.Errorf("%w: address %s have (%d) want (%d)", ErrInsufficientGasTokenFunds, from.Hex(), balance, cost)
+		}
+	}
 
 	txNonce := tx.Nonce()
 	// Ensure the transaction adheres to nonce ordering
@@ -690,18 +832,167 @@ func (pool *TxPool) checkTxState(from common.Address, tx *types.Transaction) err
 		return fmt.Errorf("%w: address %s current nonce (%d) > tx nonce (%d)",
 			ErrNonceTooLow, from.Hex(), currentNonce, txNonce)
 	}
+	// Ensure custom transactions are valid for their type
+	if types.IsCustomTxType(tx.Type()) {
+		msg, err := tx.AsMessage(pool.signer, nil)
+		if err != nil {
+			return err
+		}
+		if err := validateTxType(pool.chainconfig, pool.currentState, msg); err != nil {
+			return err
+		}
+	}
+
+	return pool.checkAllowLists(from, tx)
+}
+
+// validateAuth limits the transactions involving accounts delegating their code, whose nonce
+// and balance may change with the execution of any transaction. Delegated accounts may have a
+// single transaction in the pool, which may be replaced, and set code transactions may not
+// authorize accounts with several transactions in the pool.
+func (pool *TxPool) validateAuth(from common.Address, tx *types.Transaction) error {
+	pool.currentStateLock.Lock()
+	_, delegated := types.ParseDelegation(pool.currentState.GetCode(from))
+	pool.currentStateLock.Unlock()
+
+	if delegated {
+		var (
+			count  int
+			exists bool
+		)
+		if pending := pool.pending[from]; pending != nil {
+			count += pending.Len()
+			exists = pending.Overlaps(tx)
+		}
+		if queue := pool.queue[from]; queue != nil {
+			count += queue.Len()
+			exists = exists || queue.Overlaps(tx)
+		}
+		if count >= 1 && !exists {
+			return fmt.Errorf("%w: address %s", ErrInflightTxLimitReached, from.Hex())
+		}
+	}
+	for _, auth := range tx.SetCodeAuthorities() {
+		var count int
+		if pending := pool.pending[auth]; pending != nil {
+			count += pending.Len()
+		}
+		if queue := pool.queue[auth]; queue != nil {
+			count += queue.Len()
+		}
+		if count > 1 {
+			return fmt.Errorf("%w: address %s", ErrAuthorityReserved, auth.Hex())
+		}
+	}
+	return nil
+}
+
+// allowListsActive returns true if any of the precompiles restricting the senders of transactions
+// is enabled at the current head.
+func (pool *TxPool) allowListsActive() bool {
+	headTimestamp := big.NewInt(int64(pool.currentHead.Time))
+	return pool.chainconfig.IsTxAllowList(pool.currentHead.Number, headTimestamp) ||
+		pool.chainconfig.IsAddressBlocklist(pool.currentHead.Number, headTimestamp) ||
+		pool.chainconfig.IsContractDeployerAllowList(pool.currentHead.Number, headTimestamp)
+}
 
+// checkAllowLists returns an error if the precompiles enabled at the current head prevent [from]
+// from issuing [tx], so that such transactions are rejected or evicted instead of being gossiped
+// until they fail block verification.
+// Assumes that [pool.currentStateLock] is held.
+func (pool *TxPool) checkAllowLists(from common.Address, tx *types.Transaction) error {
 	// If the tx allow list is enabled, return an error if the from address is not allow listed.
 	headTimestamp := big.NewInt(int64(pool.currentHead.Time))
-	if pool.chainconfig.IsTxAllowList(headTimestamp) {
+	if pool.chainconfig.IsTxAllowList(pool.currentHead.Number, headTimestamp) {
 		txAllowListRole := precompile.GetTxAllowListStatus(pool.currentState, from)
 		if !txAllowListRole.IsEnabled() {
 			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressNotAllowListed, from)
 		}
 	}
+	// If the address blocklist is enabled, return an error if the from address is blocked.
+	if pool.chainconfig.IsAddressBlocklist(pool.currentHead.Number, headTimestamp) {
+		if precompile.IsAddressBlocked(pool.currentState, from) {
+			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressBlocked, from)
+		}
+	}
+	// If the contract deployer allow list is enabled, return an error if [tx] deploys a contract
+	// and the from address is not allow listed.
+	if tx.To() == nil && pool.chainconfig.IsContractDeployerAllowList(pool.currentHead.Number, headTimestamp) {
+		deployerRole := precompile.GetContractDeployerAllowListStatus(pool.currentState, from)
+		if !deployerRole.IsEnabled() {
+			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressNotAllowedToDeploy, from)
+		}
+	}
 	return nil
 }
 
+// removeDisallowed removes the transactions of [addr] in [list] that the allow lists no longer permit, for
+// example because the role of [addr] changed since they were added. Returns the removed transactions and
+// the transactions invalidated by their removal (strict mode only), which are kept in the lookup set.
+// Assumes that [pool.currentStateLock] is held.
+func (pool *TxPool) removeDisallowed(addr common.Address, list *txList) (types.Transactions, types.Transactions) {
+	if !pool.allowListsActive() {
+		return nil, nil
+	}
+	disallowed, invalids := list.FilterFunc(func(tx *types.Transaction) bool {
+		return pool.checkAllowLists(addr, tx) != nil
+	})
+	for _, tx := range disallowed {
+		hash := tx.Hash()
+		pool.all.Remove(hash)
+		log.Trace("Removed disallowed transaction", "hash", hash)
+	}
+	return disallowed, invalids
+}
+
+// senderCost returns the portion of the cost of [tx] that must be covered by the balance of [from].
+// If the gas token precompile is enabled, or the gas sponsor precompile is enabled and [tx] has a
+// sponsor whose deposit covers its gas, only the value of [tx] is paid by [from].
+// Assumes that [pool.currentStateLock] is held.
+func (pool *TxPool) senderCost(from common.Address, tx *types.Transaction) *big.Int {
+	if pool.chainconfig.IsGasToken(pool.currentHead.Number, big.NewInt(int64(pool.currentHead.Time))) {
+		return tx.Value()
+	}
+	if !pool.chainconfig.IsGasSponsor(pool.currentHead.Number, big.NewInt(int64(pool.currentHead.Time))) {
+		return tx.Cost()
+	}
+	sponsor, ok := precompile.GetGasSponsor(pool.currentState, from, tx.To())
+	if !ok {
+		return tx.Cost()
+	}
+	gasCost := new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+	if precompile.GetSponsorDeposit(pool.currentState, sponsor).Cmp(gasCost) < 0 {
+		return tx.Cost()
+	}
+	return tx.Value()
+}
+
+// costLimit returns the maximum cost of the transactions in [list] that [addr] is able to pay for.
+// If the gas token precompile is enabled, this includes the gas token balance of [addr], since
+// the cost of the transactions combines their value in native coin and their gas in gas token.
+// If the gas sponsor precompile is enabled, this includes the largest deposit of any sponsor
+// of the transactions in [list], so that sponsored transactions are not dropped as unpayable.
+func (pool *TxPool) costLimit(addr common.Address, list *txList) *big.Int {
+	balance := pool.currentState.GetBalance(addr)
+	if pool.chainconfig.IsGasToken(pool.currentHead.Number, big.NewInt(int64(pool.currentHead.Time))) {
+		return new(big.Int).Add(balance, precompile.GetGasTokenBalance(pool.currentState, addr))
+	}
+	if !pool.chainconfig.IsGasSponsor(pool.currentHead.Number, big.NewInt(int64(pool.currentHead.Time))) {
+		return balance
+	}
+	maxDeposit := new(big.Int)
+	for _, tx := range list.Flatten() {
+		sponsor, ok := precompile.GetGasSponsor(pool.currentState, addr, tx.To())
+		if !ok {
+			continue
+		}
+		if deposit := precompile.GetSponsorDeposit(pool.currentState, sponsor); deposit.Cmp(maxDeposit) > 0 {
+			maxDeposit = deposit
+		}
+	}
+	return maxDeposit.Add(maxDeposit, balance)
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -713,10 +1004,31 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !pool.eip1559 && tx.Type() == types.DynamicFeeTxType {
 		return ErrTxTypeNotSupported
 	}
-	// Reject transactions over defined size to prevent DOS attacks
-	if txSize := uint64(tx.Size()); txSize > txMaxSize {
+	// Reject blob transactions until EIP-4844 activates.
+	if !pool.eip4844 && tx.Type() == types.BlobTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Reject set code transactions until EIP-7702 activates.
+	if !pool.eip7702 && tx.Type() == types.SetCodeTxType {
+		return ErrTxTypeNotSupported
+	}
+	// Reject custom transactions until their type activates.
+	if types.IsCustomTxType(tx.Type()) && !pool.chainconfig.IsCustomTxType(tx.Type(), new(big.Int).SetUint64(pool.currentHead.Time)) {
+		return ErrTxTypeNotSupported
+	}
+	if tx.Type() == types.SetCodeTxType && len(tx.SetCodeAuthorizations()) == 0 {
+		return ErrEmptyAuthList
+	}
+	// Reject transactions over defined size to prevent DOS attacks. The size of the
+	// blob sidecar is bounded by the number of blobs instead.
+	if txSize := uint64(tx.WithoutBlobTxSidecar().Size()); txSize > txMaxSize {
 		return fmt.Errorf("%w tx size %d > max size %d", ErrOversizedData, txSize, txMaxSize)
 	}
+	if tx.Type() == types.BlobTxType {
+		if err := pool.validateBlobTx(tx); err != nil {
+			return err
+		}
+	}
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Value().Sign() < 0 {
@@ -746,19 +1058,41 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if !local && tx.GasTipCapIntCmp(pool.gasPrice) < 0 {
 		return fmt.Errorf("%w: address %s have gas tip cap (%d) < pool gas tip cap (%d)", ErrUnderpriced, from.Hex(), tx.GasTipCap(), pool.gasPrice)
 	}
+	// Drop non-local transactions under the operator's minimum gas price
+	if !local && tx.GasFeeCapIntCmp(pool.minGasPrice) < 0 {
+		return fmt.Errorf("%w: address %s have gas fee cap (%d) < pool minimum gas price (%d)", ErrUnderpriced, from.Hex(), tx.GasFeeCap(), pool.minGasPrice)
+	}
 	// Drop the transaction if the gas fee cap is below the pool's minimum fee
 	if pool.minimumFee != nil && tx.GasFeeCapIntCmp(pool.minimumFee) < 0 {
 		return fmt.Errorf("%w: address %s have gas fee cap (%d) < pool minimum fee cap (%d)", ErrUnderpriced, from.Hex(), tx.GasFeeCap(), pool.minimumFee)
 	}
+	// The blob gas is priced at the base fee, so the blob fee cap must also cover the minimum fee
+	if pool.minimumFee != nil && tx.Type() == types.BlobTxType && tx.BlobGasFeeCap().Cmp(pool.minimumFee) < 0 {
+		return fmt.Errorf("%w: address %s have blob gas fee cap (%d) < pool minimum fee cap (%d)", ErrUnderpriced, from.Hex(), tx.BlobGasFeeCap(), pool.minimumFee)
+	}
 
 	// Ensure the transaction adheres to nonce ordering
 	if err := pool.checkTxState(from, tx); err != nil {
 		return err
 	}
+	if pool.eip7702 {
+		if err := pool.validateAuth(from, tx); err != nil {
+			return err
+		}
+	}
+	// Drop non-local transactions too far ahead of the next nonce of the sender, so
+	// that an account cannot fill the queue with a huge nonce-gapped batch
+	if !local && pool.config.MaxNonceGap > 0 {
+		if next := pool.pendingNonces.get(from); tx.Nonce() > next+pool.config.MaxNonceGap {
+			queuedNonceGapMeter.Mark(1)
+			return fmt.Errorf("%w: address %s tx nonce (%d) > next nonce (%d) + max gap (%d)",
+				ErrNonceGapTooLarge, from.Hex(), tx.Nonce(), next, pool.config.MaxNonceGap)
+		}
+	}
 	// Transactor should have enough funds to cover the costs
 
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
 		return err
 	}
@@ -768,6 +1102,21 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// validateBlobTx checks the blob hashes of a blob transaction, which must not carry its sidecar
+// since the only supported blob data mode is [params.BlobDataReject].
+func (pool *TxPool) validateBlobTx(tx *types.Transaction) error {
+	if err := types.VerifyBlobHashes(tx.BlobHashes()); err != nil {
+		return err
+	}
+	if tx.BlobGasFeeCap().BitLen() > 256 {
+		return ErrFeeCapVeryHigh
+	}
+	if tx.BlobTxSidecar() != nil {
+		return ErrBlobDataRejected
+	}
+	return nil
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -1122,6 +1471,43 @@ func (pool *TxPool) RemoveTx(hash common.Hash) {
 	pool.removeTx(hash, true)
 }
 
+// EvictTx removes the transaction with [hash] from the pool, moving all subsequent
+// transactions of its sender back to the future queue. Returns false if the
+// transaction is not in the pool.
+func (pool *TxPool) EvictTx(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.all.Get(hash) == nil {
+		return false
+	}
+	pool.removeTx(hash, true)
+	return true
+}
+
+// EvictTxsFrom removes all pending and queued transactions of [addr] from the pool and
+// returns the number of transactions removed.
+func (pool *TxPool) EvictTxsFrom(addr common.Address) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var hashes []common.Hash
+	if pending := pool.pending[addr]; pending != nil {
+		for _, tx := range pending.Flatten() {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	if queued := pool.queue[addr]; queued != nil {
+		for _, tx := range queued.Flatten() {
+			hashes = append(hashes, tx.Hash())
+		}
+	}
+	for _, hash := range hashes {
+		pool.removeTx(hash, true)
+	}
+	return len(hashes)
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -1445,7 +1831,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// without requiring FeeConfigManager is enabled.
 	// This is already being set by SetMinFee when gas price updater starts.
 	// However tests are currently failing if we change this check to IsSubnetEVM.
-	if pool.chainconfig.IsFeeConfigManager(new(big.Int).SetUint64(newHead.Time)) {
+	if pool.chainconfig.IsFeeConfigManager(newHead.Number, new(big.Int).SetUint64(newHead.Time)) {
 		feeConfig, _, err := pool.chain.GetFeeConfigAt(newHead)
 		if err != nil {
 			log.Error("Failed to get fee config state", "err", err, "root", newHead.Root)
@@ -1466,6 +1852,8 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	isSubnetEVM := pool.chainconfig.IsSubnetEVM(new(big.Int).SetUint64(newHead.Time))
 	pool.eip2718 = isSubnetEVM
 	pool.eip1559 = isSubnetEVM
+	pool.eip4844 = pool.chainconfig.IsBlobTx(new(big.Int).SetUint64(newHead.Time))
+	pool.eip7702 = pool.chainconfig.IsSetCodeTx(new(big.Int).SetUint64(newHead.Time))
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1492,7 +1880,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
-		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, _ := list.Filter(pool.costLimit(addr, list), pool.currentMaxGas)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1500,6 +1888,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
+		// Drop all transactions that the allow lists no longer permit
+		disallowed, _ := pool.removeDisallowed(addr, list)
+		queuedDisallowedMeter.Mark(int64(len(disallowed)))
+
 		// Gather all executable transactions and promote them
 		readies := list.Ready(pool.pendingNonces.get(addr))
 		for _, tx := range readies {
@@ -1523,10 +1915,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
-		pool.priced.Removed(len(forwards) + len(drops) + len(caps))
-		queuedGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
+		pool.priced.Removed(len(forwards) + len(drops) + len(disallowed) + len(caps))
+		queuedGauge.Dec(int64(len(forwards) + len(drops) + len(disallowed) + len(caps)))
 		if pool.locals.contains(addr) {
-			localGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
+			localGauge.Dec(int64(len(forwards) + len(drops) + len(disallowed) + len(caps)))
 		}
 		// Delete the entire queue entry if it became empty.
 		if list.Empty() {
@@ -1692,7 +2084,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
-		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, invalids := list.Filter(pool.costLimit(addr, list), pool.currentMaxGas)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -1707,9 +2099,20 @@ func (pool *TxPool) demoteUnexecutables() {
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
 		}
-		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
+		// Drop all transactions that the allow lists no longer permit, and queue any invalids back for later
+		disallowed, disallowedInvalids := pool.removeDisallowed(addr, list)
+		pendingDisallowedMeter.Mark(int64(len(disallowed)))
+
+		for _, tx := range disallowedInvalids {
+			hash := tx.Hash()
+			log.Trace("Demoting pending transaction", "hash", hash)
+
+			// Internal shuffle shouldn't touch the lookup set.
+			pool.enqueueTx(hash, tx, false, false)
+		}
+		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids) + len(disallowed) + len(disallowedInvalids)))
 		if pool.locals.contains(addr) {
-			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
+			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids) + len(disallowed) + len(disallowedInvalids)))
 		}
 		// If there's a gap in front, alert (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
@@ -1846,6 +2249,12 @@ func (as *accountSet) add(addr common.Address) {
 	as.cache = nil
 }
 
+// remove deletes an address from the set.
+func (as *accountSet) remove(addr common.Address) {
+	delete(as.accounts, addr)
+	as.cache = nil
+}
+
 // addTx adds the sender of tx into the set.
 func (as *accountSet) addTx(tx *types.Transaction) {
 	if addr, err := types.Sender(as.signer, tx); err == nil {
@@ -2035,6 +2444,23 @@ func (t *txLookup) RemoteToLocals(locals *accountSet) int {
 	return migrated
 }
 
+// LocalToRemotes migrates the transactions that do not belong to the given locals to
+// remotes set, and returns them. The assumption is held the locals set is thread-safe to be used.
+func (t *txLookup) LocalToRemotes(locals *accountSet) types.Transactions {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var migrated types.Transactions
+	for hash, tx := range t.locals {
+		if !locals.containsTx(tx) {
+			t.remotes[hash] = tx
+			delete(t.locals, hash)
+			migrated = append(migrated, tx)
+		}
+	}
+	return migrated
+}
+
 // RemotesBelowTip finds all remote transactions below the given tip threshold.
 func (t *txLookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	found := make(types.Transactions, 0, 128)
@@ -2047,6 +2473,18 @@ func (t *txLookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	return found
 }
 
+// RemotesBelowFeeCap finds all remote transactions below the given fee cap threshold.
+func (t *txLookup) RemotesBelowFeeCap(threshold *big.Int) types.Transactions {
+	found := make(types.Transactions, 0, 128)
+	t.Range(func(hash common.Hash, tx *types.Transaction, local bool) bool {
+		if tx.GasFeeCapIntCmp(threshold) < 0 {
+			found = append(found, tx)
+		}
+		return true
+	}, false, true) // Only iterate remotes
+	return found
+}
+
 // numSlots calculates the number of slots needed for a single transaction.
 func numSlots(tx *types.Transaction) int {
 	return int((tx.Size() + txSlotSize - 1) / txSlotSize)
@@ -43,8 +43,10 @@ import (
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 )
@@ -318,6 +320,162 @@ func testSetNonce(pool *TxPool, addr common.Address, nonce uint64) {
 	pool.mu.Unlock()
 }
 
+// Tests that transactions whose gas is covered by a gas sponsor only require
+// the sender to have enough funds to cover the value.
+func TestSponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewGasSponsorConfig(big.NewInt(0), nil, nil))
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+	testAddBalance(pool, from, tx.Value())
+	if err := pool.addRemoteSync(tx); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("expected", ErrInsufficientFunds, "got", err)
+	}
+
+	// Register a sponsor for [from] whose deposit covers the gas of [tx].
+	sponsor := common.Address{1}
+	pool.mu.Lock()
+	precompile.SetGasSponsorAllowListStatus(pool.currentState, sponsor, precompile.AllowListEnabled)
+	precompile.RefundSponsor(pool.currentState, sponsor, new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()))
+	input, err := precompile.PackSponsorSender(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: pool.currentState, blockContext: &mockBlockContext{blockNumber: common.Big0}}, sponsor, precompile.GasSponsorAddress, input, precompile.SponsorAccountGasCost, false)
+	pool.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatal("expected sponsored transaction to be accepted, got", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that transactions paying for gas in the gas token require the sender to have
+// enough gas token to cover the gas, and enough native coin to cover the value.
+func TestGasTokenTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewGasTokenConfig(big.NewInt(0), nil, nil, "Gas Token", "GAS", 18, nil))
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	tx := pricedTransaction(0, 100000, big.NewInt(1), key)
+	from, _ := deriveSender(tx)
+	testAddBalance(pool, from, new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()))
+	if err := pool.addRemoteSync(tx); !errors.Is(err, ErrInsufficientGasTokenFunds) {
+		t.Fatal("expected", ErrInsufficientGasTokenFunds, "got", err)
+	}
+
+	pool.mu.Lock()
+	precompile.NewGasTokenConfig(big.NewInt(0), nil, nil, "", "", 0, map[common.Address]*math.HexOrDecimal256{
+		from: (*math.HexOrDecimal256)(new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())),
+	}).Configure(nil, pool.currentState, nil)
+	pool.mu.Unlock()
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatal("expected transaction paid in gas token to be accepted, got", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that custom transactions are rejected unless their type has activated and they are valid for it.
+func TestCustomTxTypeTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx := mkCustomTx(0, common.Address{1}, 100000, big.NewInt(1), big.NewInt(1), []byte{0x42}, key)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1_000_000_000))
+	if err := pool.addRemoteSync(tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatal("expected", ErrTxTypeNotSupported, "got", err)
+	}
+
+	pool, key = setupTxPoolWithConfig(customTxTypeConfig)
+	defer pool.Stop()
+
+	tx = mkCustomTx(0, common.Address{1}, 100000, big.NewInt(1), big.NewInt(1), nil, key)
+	from = crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1_000_000_000))
+	if err := pool.addRemoteSync(tx); !errors.Is(err, errEmptyTestPayload) {
+		t.Fatal("expected", errEmptyTestPayload, "got", err)
+	}
+	tx = mkCustomTx(0, common.Address{1}, 100000, big.NewInt(1), big.NewInt(1), []byte{0x42}, key)
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatal("expected valid custom transaction to be accepted, got", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+// Tests that transactions that the allow list precompiles do not permit are rejected
+// when they are added, and evicted once the role of their sender is revoked.
+func TestAllowListTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(
+		precompile.NewTxAllowListConfig(big.NewInt(0), nil, nil),
+		precompile.NewContractDeployerAllowListConfig(big.NewInt(0), nil, nil),
+	)
+	pool, key := setupTxPoolWithConfig(&config)
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+	testAddBalance(pool, from, big.NewInt(1_000_000_000))
+	if err := pool.addRemoteSync(tx); !errors.Is(err, precompile.ErrSenderAddressNotAllowListed) {
+		t.Fatal("expected", precompile.ErrSenderAddressNotAllowListed, "got", err)
+	}
+
+	pool.mu.Lock()
+	precompile.SetTxAllowListStatus(pool.currentState, from, precompile.AllowListEnabled)
+	pool.mu.Unlock()
+	if err := pool.addRemoteSync(tx); err != nil {
+		t.Fatal("expected transaction from allow listed sender to be accepted, got", err)
+	}
+
+	// Contract creations also require the sender to be allowed to deploy contracts.
+	create, _ := types.SignTx(types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.addRemoteSync(create); !errors.Is(err, precompile.ErrSenderAddressNotAllowedToDeploy) {
+		t.Fatal("expected", precompile.ErrSenderAddressNotAllowedToDeploy, "got", err)
+	}
+	// Queue a transaction after a gap, to check that queued transactions are evicted too.
+	if err := pool.addRemoteSync(transaction(3, 100000, key)); err != nil {
+		t.Fatal(err)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("transactions mismatched: have %d pending and %d queued, want 1 and 1", pending, queued)
+	}
+
+	// Revoking the role of the sender evicts its transactions on the next reset.
+	pool.mu.Lock()
+	precompile.SetTxAllowListStatus(pool.currentState, from, precompile.AllowListNoRole)
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("transactions mismatched: have %d pending and %d queued, want 0 and 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestInvalidTransactions(t *testing.T) {
 	t.Parallel()
 
@@ -1682,6 +1840,236 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	validate()
 }
 
+// Tests that accounts marked as local or remote at runtime have their transactions
+// migrated between the local and remote sets of the pool.
+func TestTransactionPoolLocalAccounts(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	// Add a pending and a queued transaction as remotes
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(2, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	validate := func(locals int) {
+		t.Helper()
+		if count := pool.all.LocalCount(); count != locals {
+			t.Fatalf("local transactions mismatched: have %d, want %d", count, locals)
+		}
+		if count := pool.all.RemoteCount(); count != 2-locals {
+			t.Fatalf("remote transactions mismatched: have %d, want %d", count, 2-locals)
+		}
+		if err := validateTxPoolInternals(pool); err != nil {
+			t.Fatalf("pool internal state corrupted: %v", err)
+		}
+	}
+	validate(0)
+
+	pool.AddLocalAccount(addr)
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != addr {
+		t.Fatalf("locals mismatched: have %v, want [%v]", locals, addr)
+	}
+	validate(2)
+
+	// Adding the account again is a no-op
+	pool.AddLocalAccount(addr)
+	validate(2)
+
+	pool.RemoveLocalAccount(addr)
+	if locals := pool.Locals(); len(locals) != 0 {
+		t.Fatalf("locals mismatched: have %v, want []", locals)
+	}
+	validate(0)
+}
+
+// Tests that remote transactions older than the max tx age are evicted, whether
+// executable or not, while local transactions are kept.
+func TestTransactionPoolExpiration(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	local, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+
+	now := time.Now()
+	stale := func(tx *types.Transaction) *types.Transaction {
+		tx.SetFirstSeen(now.Add(-2 * time.Hour))
+		return tx
+	}
+	remotes := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		stale(pricedTransaction(1, 100000, big.NewInt(1), key)),
+		pricedTransaction(2, 100000, big.NewInt(1), key),
+		stale(pricedTransaction(4, 100000, big.NewInt(1), key)),
+	}
+	for _, err := range pool.AddRemotesSync(remotes) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if err := pool.AddLocal(stale(pricedTransaction(0, 100000, big.NewInt(1), local))); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("pool stats mismatched: have %d/%d, want %d/%d", pending, queued, 4, 1)
+	}
+
+	pool.mu.Lock()
+	pool.evictExpired(now.Add(-time.Hour))
+	pool.mu.Unlock()
+
+	// The expired pending transaction moves its successor to the queue
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("pool stats mismatched: have %d/%d, want %d/%d", pending, queued, 2, 1)
+	}
+	if pool.Has(remotes[1].Hash()) || pool.Has(remotes[3].Hash()) {
+		t.Fatalf("expired transactions not evicted")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions too far ahead of the next nonce of their sender
+// are rejected, while local transactions are accepted.
+func TestTransactionPoolMaxNonceGap(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.MaxNonceGap = 2
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction within the max nonce gap: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(3, 100000, key)); !errors.Is(err, ErrNonceGapTooLarge) {
+		t.Fatalf("adding transaction beyond the max nonce gap error mismatch: have %v, want %v", err, ErrNonceGapTooLarge)
+	}
+	// The gap is measured from the next pending nonce
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(3, 100000, key)); err != nil {
+		t.Fatalf("failed to add transaction within the max nonce gap: %v", err)
+	}
+	if err := pool.AddLocal(transaction(10, 100000, key)); err != nil {
+		t.Fatalf("failed to add local transaction beyond the max nonce gap: %v", err)
+	}
+}
+
+// Tests that the operator-level minimum gas price rejects and drops remote
+// transactions below it, while local transactions are kept.
+func TestTransactionPoolMinGasPrice(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	local, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+
+	// Raising the floor drops the remote transactions below it
+	pool.SetMinGasPrice(big.NewInt(2))
+	if pool.MinGasPrice().Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("min gas price mismatched: have %d, want %d", pool.MinGasPrice(), 2)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Fatalf("pool stats mismatched: have %d/%d, want %d/%d", pending, queued, 1, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), key)); !errors.Is(err, ErrUnderpriced) {
+		t.Fatalf("adding remote transaction below the min gas price error mismatch: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(1, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction below the min gas price: %v", err)
+	}
+}
+
+// Tests that transactions can be evicted from the pool by hash or by sender.
+func TestTransactionPoolEviction(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000000))
+
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		pricedTransaction(1, 100000, big.NewInt(1), key),
+		pricedTransaction(2, 100000, big.NewInt(1), key),
+		pricedTransaction(4, 100000, big.NewInt(1), key),
+	}
+	for _, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	validate := func(expPending, expQueued int) {
+		t.Helper()
+		if pending, queued := pool.Stats(); pending != expPending || queued != expQueued {
+			t.Fatalf("pool stats mismatched: have %d/%d, want %d/%d", pending, queued, expPending, expQueued)
+		}
+		if err := validateTxPoolInternals(pool); err != nil {
+			t.Fatalf("pool internal state corrupted: %v", err)
+		}
+	}
+	validate(3, 1)
+
+	// Evicting a pending transaction moves the later ones back to the queue
+	if !pool.EvictTx(txs[1].Hash()) {
+		t.Fatalf("failed to evict transaction")
+	}
+	validate(1, 2)
+	if pool.EvictTx(txs[1].Hash()) {
+		t.Fatalf("evicted transaction not in the pool")
+	}
+
+	if evicted := pool.EvictTxsFrom(addr); evicted != 3 {
+		t.Fatalf("evicted transactions mismatched: have %d, want %d", evicted, 3)
+	}
+	validate(0, 0)
+	if evicted := pool.EvictTxsFrom(addr); evicted != 0 {
+		t.Fatalf("evicted transactions mismatched: have %d, want %d", evicted, 0)
+	}
+}
+
 // Tests that when the pool reaches its global transaction limit, underpriced
 // transactions are gradually shifted out for more expensive ones and any gapped
 // pending transactions are moved into the queue.
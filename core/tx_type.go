@@ -0,0 +1,106 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+)
+
+// TxType is a subnet-specific transaction type, such as an oracle update or a batch settlement,
+// that is introduced without forking the parsing of transactions. Transactions of the type are
+// [types.CustomTx] envelopes: they pay for gas like dynamic fee transactions, and the format of
+// their data is defined by the type.
+// Each type is registered with [RegisterTxType] from an init function, like precompile modules.
+// Transactions of a type are only valid once its timestamp in the CustomTxTypeTimestamps of the
+// network upgrades has passed, which must be scheduled after every node of the network has
+// registered the type, since nodes that have not registered it reject its transactions.
+type TxType struct {
+	// ID is the EIP-2718 envelope type of the transactions, in [types.MinCustomTxType, types.MaxCustomTxType].
+	ID uint8
+	// Name identifies the type in errors.
+	Name string
+	// Validate optionally returns an error if [msg] is invalid for the type against [state]. It is called
+	// when a transaction is added to the txpool and before it is applied to a block, so an invalid
+	// transaction is never included.
+	Validate func(config *params.ChainConfig, state vm.StateDB, msg Message) error
+	// Execute optionally replaces the EVM call or contract creation of [msg]. It is called with the [gas]
+	// left after the intrinsic gas and returns the output, the gas left and the execution error. As for a
+	// call, an error reverts the state changes of the execution and consumes all the gas unless it is
+	// [vmerrs.ErrExecutionReverted]. Transactions of types without [Execute] are executed as usual.
+	Execute func(evm *vm.EVM, msg Message, gas uint64) (ret []byte, leftOverGas uint64, err error)
+}
+
+var (
+	// registeredTxTypes contains the registered transaction types keyed by ID.
+	registeredTxTypes     = make(map[uint8]TxType)
+	registeredTxTypesLock sync.RWMutex
+)
+
+// RegisterTxType registers [txType] so that its transactions can be issued and executed.
+// Panics if [txType] is invalid or conflicts with an already registered type, since
+// types are typically registered during init.
+func RegisterTxType(txType TxType) {
+	if err := registerTxType(txType); err != nil {
+		panic(err)
+	}
+}
+
+func registerTxType(txType TxType) error {
+	if txType.Name == "" {
+		return fmt.Errorf("transaction type %#x has an empty name", txType.ID)
+	}
+	registeredTxTypesLock.Lock()
+	defer registeredTxTypesLock.Unlock()
+
+	if err := types.RegisterCustomTxType(txType.ID, txType.Name); err != nil {
+		return err
+	}
+	registeredTxTypes[txType.ID] = txType
+	return nil
+}
+
+// GetTxType returns the registered transaction type with [id], or false if there is none.
+func GetTxType(id uint8) (TxType, bool) {
+	registeredTxTypesLock.RLock()
+	defer registeredTxTypesLock.RUnlock()
+
+	txType, ok := registeredTxTypes[id]
+	return txType, ok
+}
+
+// validateTxType returns an error if [msg] has a custom transaction type and is invalid for it.
+func validateTxType(config *params.ChainConfig, state vm.StateDB, msg Message) error {
+	txType, ok := GetTxType(msg.Type())
+	if !ok || txType.Validate == nil {
+		return nil
+	}
+	if err := txType.Validate(config, state, msg); err != nil {
+		return fmt.Errorf("invalid %s transaction from %s: %w", txType.Name, msg.From().Hex(), err)
+	}
+	return nil
+}
+
+// executeTxType executes the message with the [Execute] hook of [txType], reverting its state changes
+// if it fails.
+func (st *StateTransition) executeTxType(txType TxType) ([]byte, uint64, error) {
+	snapshot := st.state.Snapshot()
+	ret, leftOverGas, err := txType.Execute(st.evm, st.msg, st.gas)
+	if leftOverGas > st.gas {
+		leftOverGas = st.gas
+	}
+	if err != nil {
+		st.state.RevertToSnapshot(snapshot)
+		if !errors.Is(err, vmerrs.ErrExecutionReverted) {
+			leftOverGas = 0
+		}
+	}
+	return ret, leftOverGas, err
+}
@@ -0,0 +1,93 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// startTxIndexer starts maintaining the transaction lookup index in the
+// background, so it only covers the last [TxLookupLimit] accepted blocks (or all
+// of them if 0). The index is brought in line with the limit on startup, in case
+// the limit was changed since the last run.
+func (bc *BlockChain) startTxIndexer() {
+	bc.txIndexWg.Add(1)
+	go func() {
+		defer bc.txIndexWg.Done()
+		bc.txIndexLoop()
+	}()
+	bc.notifyTxIndexer(bc.txIndexUpdate)
+}
+
+// notifyTxIndexer notifies the transaction indexer through [ch] without
+// blocking, as a pending notification covers all the later ones.
+func (bc *BlockChain) notifyTxIndexer(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// RebuildTxIndex rewrites the transaction lookup entries of all the indexed
+// blocks in the background, repairing missing or corrupted entries.
+func (bc *BlockChain) RebuildTxIndex() {
+	bc.notifyTxIndexer(bc.txIndexRebuild)
+}
+
+func (bc *BlockChain) txIndexLoop() {
+	for {
+		var rebuild bool
+		select {
+		case <-bc.quit:
+			return
+		case <-bc.txIndexUpdate:
+		case <-bc.txIndexRebuild:
+			rebuild = true
+		}
+		if err := bc.updateTxIndex(rebuild); err != nil {
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+			log.Error("Failed to update transaction index", "rebuild", rebuild, "err", err)
+		}
+	}
+}
+
+// updateTxIndex moves the transaction index tail to the oldest block within the
+// [TxLookupLimit] of the last accepted block, unindexing or indexing blocks as
+// required. If [rebuild] is true, the entries of all the blocks within the limit
+// are written again.
+func (bc *BlockChain) updateTxIndex(rebuild bool) error {
+	var (
+		head  = bc.LastAcceptedBlock().NumberU64()
+		limit = bc.cacheConfig.TxLookupLimit
+		want  uint64
+		tail  uint64
+	)
+	if limit != 0 && head+1 > limit {
+		want = head + 1 - limit
+	}
+	if stored := rawdb.ReadTxIndexTail(bc.db); stored != nil {
+		tail = *stored
+	}
+	if tail < want {
+		if err := rawdb.UnindexTransactions(bc.db, tail, want, bc.quit); err != nil {
+			return err
+		}
+		// The cached lookups of the unindexed transactions must not be served anymore
+		bc.txLookupCache.Purge()
+		tail = want
+	}
+	if rebuild {
+		log.Info("Rebuilding transaction index", "from", want, "to", head)
+		tail = head + 1
+	}
+	if tail > want {
+		return rawdb.IndexTransactions(bc.db, want, tail, bc.quit)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxIndexLimit(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+		config  = &CacheConfig{
+			TrieCleanLimit:        256,
+			TrieDirtyLimit:        256,
+			TrieDirtyCommitTarget: 20,
+			Pruning:               true,
+			CommitInterval:        4096,
+			SnapshotLimit:         256,
+			AcceptorQueueLimit:    64,
+			TxLookupLimit:         4,
+		}
+	)
+
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, config, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), common.Address{1}, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// checkIndex checks the transactions of the blocks from [tail] on are indexed and
+	// the ones of the previous blocks are not, once the indexer has caught up.
+	checkIndex := func(blockchain *BlockChain, tail uint64) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			stored := rawdb.ReadTxIndexTail(chainDB)
+			return stored != nil && *stored == tail
+		}, 5*time.Second, 10*time.Millisecond)
+		for _, block := range chain {
+			lookup := blockchain.GetTransactionLookup(block.Transactions()[0].Hash())
+			if block.NumberU64() < tail {
+				require.Nil(t, lookup, "block %d", block.NumberU64())
+			} else {
+				require.NotNil(t, lookup, "block %d", block.NumberU64())
+				require.Equal(t, block.Hash(), lookup.BlockHash)
+			}
+		}
+	}
+	checkIndex(blockchain, 7)
+
+	// A rebuild restores missing entries
+	rawdb.DeleteTxLookupEntry(chainDB, chain[8].Transactions()[0].Hash())
+	blockchain.txLookupCache.Purge()
+	blockchain.RebuildTxIndex()
+	require.Eventually(t, func() bool {
+		return blockchain.GetTransactionLookup(chain[8].Transactions()[0].Hash()) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	checkIndex(blockchain, 7)
+	blockchain.Stop()
+
+	// The index is updated on startup when the limit changes
+	config.TxLookupLimit = 2
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, chain[9].Hash())
+	require.NoError(t, err)
+	checkIndex(blockchain, 9)
+	blockchain.Stop()
+
+	config.TxLookupLimit = 0
+	blockchain, err = createBlockChain(chainDB, config, gspec.Config, chain[9].Hash())
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	checkIndex(blockchain, 0)
+}
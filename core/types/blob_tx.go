@@ -0,0 +1,299 @@
+// (c) 2023, Ava Labs, Inc.
+//
+// This file is a derived work, based on the go-ethereum library whose original
+// notices appear below.
+//
+// It is distributed under a license compatible with the licensing terms of the
+// original code from which it is derived.
+//
+// Much love to the original authors for their work.
+// **********
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// BlobSize is the size of a data blob in bytes.
+const BlobSize = 4096 * 32
+
+var (
+	ErrMissingBlobHashes      = errors.New("blob transaction has no blob hashes")
+	ErrTooManyBlobs           = errors.New("blob transaction references too many blobs")
+	ErrInvalidBlobHashVersion = errors.New("blob hash has an unsupported version")
+	ErrBlobSidecarMismatch    = errors.New("blob sidecar does not match the blob hashes")
+)
+
+// Blob is a data blob of a blob transaction.
+type Blob [BlobSize]byte
+
+// KZGCommitment is the KZG commitment to a data blob.
+type KZGCommitment [48]byte
+
+// KZGProof is the KZG proof that a data blob matches its commitment.
+type KZGProof [48]byte
+
+// MarshalText implements encoding.TextMarshaler.
+func (b Blob) MarshalText() ([]byte, error) { return hexutil.Bytes(b[:]).MarshalText() }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *Blob) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("Blob", input, b[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c KZGCommitment) MarshalText() ([]byte, error) { return hexutil.Bytes(c[:]).MarshalText() }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *KZGCommitment) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("KZGCommitment", input, c[:])
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p KZGProof) MarshalText() ([]byte, error) { return hexutil.Bytes(p[:]).MarshalText() }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *KZGProof) UnmarshalText(input []byte) error {
+	return hexutil.UnmarshalFixedText("KZGProof", input, p[:])
+}
+
+// KZGToVersionedHash returns the versioned hash committing to the blob of [commitment],
+// as referenced by blob transactions and returned by the BLOBHASH opcode.
+func KZGToVersionedHash(commitment KZGCommitment) common.Hash {
+	h := sha256.Sum256(commitment[:])
+	h[0] = params.BlobTxHashVersion
+	return h
+}
+
+// BlobTx represents an EIP-4844 transaction.
+type BlobTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int // a.k.a. maxPriorityFeePerGas
+	GasFeeCap  *big.Int // a.k.a. maxFeePerGas
+	Gas        uint64
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+	BlobFeeCap *big.Int // a.k.a. maxFeePerBlobGas
+	BlobHashes []common.Hash
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+
+	// Sidecar optionally holds the data committed to by BlobHashes. It is not part of
+	// the signed transaction nor of its hash, and is never included in blocks.
+	Sidecar *BlobTxSidecar `rlp:"-"`
+}
+
+// BlobTxSidecar contains the data blobs of a blob transaction, with their commitments and proofs.
+// Blobs may be empty when only the commitments are kept.
+type BlobTxSidecar struct {
+	Blobs       []Blob          `json:"blobs"`
+	Commitments []KZGCommitment `json:"commitments"`
+	Proofs      []KZGProof      `json:"proofs"`
+}
+
+// BlobHashes computes the versioned hashes of the commitments of the sidecar.
+func (sc *BlobTxSidecar) BlobHashes() []common.Hash {
+	hashes := make([]common.Hash, len(sc.Commitments))
+	for i, commitment := range sc.Commitments {
+		hashes[i] = KZGToVersionedHash(commitment)
+	}
+	return hashes
+}
+
+// ValidateBlobHashes returns an error if the commitments of the sidecar do not match [hashes], or
+// if it does not have one proof (and one blob if any) per commitment.
+// Note: this does not verify the KZG proofs, which requires the KZG trusted setup.
+func (sc *BlobTxSidecar) ValidateBlobHashes(hashes []common.Hash) error {
+	if len(sc.Commitments) != len(hashes) {
+		return fmt.Errorf("%w: %d commitments for %d blob hashes", ErrBlobSidecarMismatch, len(sc.Commitments), len(hashes))
+	}
+	if len(sc.Proofs) != len(hashes) {
+		return fmt.Errorf("%w: %d proofs for %d blob hashes", ErrBlobSidecarMismatch, len(sc.Proofs), len(hashes))
+	}
+	if len(sc.Blobs) != 0 && len(sc.Blobs) != len(hashes) {
+		return fmt.Errorf("%w: %d blobs for %d blob hashes", ErrBlobSidecarMismatch, len(sc.Blobs), len(hashes))
+	}
+	for i, hash := range sc.BlobHashes() {
+		if hash != hashes[i] {
+			return fmt.Errorf("%w: commitment %d has hash %s, expected %s", ErrBlobSidecarMismatch, i, hash, hashes[i])
+		}
+	}
+	return nil
+}
+
+// WithoutBlobs returns a copy of the sidecar keeping only the commitments and proofs.
+func (sc *BlobTxSidecar) WithoutBlobs() *BlobTxSidecar {
+	return &BlobTxSidecar{
+		Commitments: append([]KZGCommitment(nil), sc.Commitments...),
+		Proofs:      append([]KZGProof(nil), sc.Proofs...),
+	}
+}
+
+func (sc *BlobTxSidecar) copy() *BlobTxSidecar {
+	cpy := sc.WithoutBlobs()
+	cpy.Blobs = append([]Blob(nil), sc.Blobs...)
+	return cpy
+}
+
+// blobTxWithBlobs is the network representation of a blob transaction carrying its sidecar.
+type blobTxWithBlobs struct {
+	BlobTx      *BlobTx
+	Blobs       []Blob
+	Commitments []KZGCommitment
+	Proofs      []KZGProof
+}
+
+// decodeBlobTx decodes the payload of a blob transaction, either in its canonical encoding or in
+// its network representation carrying the sidecar.
+func decodeBlobTx(b []byte) (TxData, error) {
+	content, _, err := rlp.SplitList(b)
+	if err != nil {
+		return nil, err
+	}
+	kind, _, _, err := rlp.Split(content)
+	if err != nil {
+		return nil, err
+	}
+	if kind != rlp.List {
+		var inner BlobTx
+		err := rlp.DecodeBytes(b, &inner)
+		return &inner, err
+	}
+	var inner blobTxWithBlobs
+	if err := rlp.DecodeBytes(b, &inner); err != nil {
+		return nil, err
+	}
+	inner.BlobTx.Sidecar = &BlobTxSidecar{
+		Blobs:       inner.Blobs,
+		Commitments: inner.Commitments,
+		Proofs:      inner.Proofs,
+	}
+	return inner.BlobTx, nil
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *BlobTx) copy() TxData {
+	cpy := &BlobTx{
+		Nonce: tx.Nonce,
+		To:    tx.To,
+		Data:  common.CopyBytes(tx.Data),
+		Gas:   tx.Gas,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		BlobHashes: make([]common.Hash, len(tx.BlobHashes)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		BlobFeeCap: new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	copy(cpy.BlobHashes, tx.BlobHashes)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasTipCap != nil {
+		cpy.GasTipCap.Set(tx.GasTipCap)
+	}
+	if tx.GasFeeCap != nil {
+		cpy.GasFeeCap.Set(tx.GasFeeCap)
+	}
+	if tx.BlobFeeCap != nil {
+		cpy.BlobFeeCap.Set(tx.BlobFeeCap)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	if tx.Sidecar != nil {
+		cpy.Sidecar = tx.Sidecar.copy()
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *BlobTx) txType() byte           { return BlobTxType }
+func (tx *BlobTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *BlobTx) accessList() AccessList { return tx.AccessList }
+func (tx *BlobTx) data() []byte           { return tx.Data }
+func (tx *BlobTx) gas() uint64            { return tx.Gas }
+func (tx *BlobTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *BlobTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *BlobTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *BlobTx) value() *big.Int        { return tx.Value }
+func (tx *BlobTx) nonce() uint64          { return tx.Nonce }
+func (tx *BlobTx) to() *common.Address    { tmp := tx.To; return &tmp }
+func (tx *BlobTx) blobGas() uint64        { return params.BlobTxBlobGasPerBlob * uint64(len(tx.BlobHashes)) }
+
+func (tx *BlobTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *BlobTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
+
+// withoutSidecar returns a shallow copy of the transaction without its sidecar.
+func (tx *BlobTx) withoutSidecar() *BlobTx {
+	cpy := *tx
+	cpy.Sidecar = nil
+	return &cpy
+}
+
+// VerifyBlobHashes returns an error if [hashes] does not reference between one and
+// [params.BlobTxMaxBlobsPerBlock] blobs, or references them with unsupported versioned hashes.
+func VerifyBlobHashes(hashes []common.Hash) error {
+	if len(hashes) == 0 {
+		return ErrMissingBlobHashes
+	}
+	if uint64(len(hashes)) > params.BlobTxMaxBlobsPerBlock {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyBlobs, len(hashes), params.BlobTxMaxBlobsPerBlock)
+	}
+	for i, hash := range hashes {
+		if hash[0] != params.BlobTxHashVersion {
+			return fmt.Errorf("%w: blob hash %d has version %d", ErrInvalidBlobHashVersion, i, hash[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestBlobSidecar(n int) *BlobTxSidecar {
+	sidecar := &BlobTxSidecar{
+		Blobs:       make([]Blob, n),
+		Commitments: make([]KZGCommitment, n),
+		Proofs:      make([]KZGProof, n),
+	}
+	for i := 0; i < n; i++ {
+		sidecar.Blobs[i][0] = byte(i + 1)
+		sidecar.Commitments[i][0] = byte(i + 1)
+		sidecar.Proofs[i][0] = byte(i + 1)
+	}
+	return sidecar
+}
+
+func newTestBlobTx(t *testing.T, sidecar *BlobTxSidecar) *Transaction {
+	key, _ := crypto.GenerateKey()
+	signer := NewCancunSigner(big.NewInt(1))
+	tx, err := SignNewTx(key, signer, &BlobTx{
+		ChainID:    big.NewInt(1),
+		Nonce:      5,
+		GasTipCap:  big.NewInt(1),
+		GasFeeCap:  big.NewInt(10),
+		Gas:        25000,
+		To:         testAddr,
+		Value:      big.NewInt(10),
+		Data:       common.FromHex("5544"),
+		BlobFeeCap: big.NewInt(3),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestBlobTxEncoding(t *testing.T) {
+	sidecar := newTestBlobSidecar(2)
+	tx := newTestBlobTx(t, sidecar)
+	stripped := tx.WithoutBlobTxSidecar()
+
+	if tx.Hash() != stripped.Hash() {
+		t.Fatalf("hash depends on the sidecar: %s != %s", tx.Hash(), stripped.Hash())
+	}
+	if stripped.BlobTxSidecar() != nil {
+		t.Fatal("sidecar not removed")
+	}
+	if tx.Size() <= stripped.Size() {
+		t.Fatalf("size %v of transaction with sidecar not above size %v without", tx.Size(), stripped.Size())
+	}
+
+	for name, want := range map[string]*Transaction{"with sidecar": tx, "without sidecar": stripped} {
+		enc, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var got Transaction
+		if err := got.UnmarshalBinary(enc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.Hash() != want.Hash() {
+			t.Fatalf("%s: decoded hash %s, expected %s", name, got.Hash(), want.Hash())
+		}
+		if (got.BlobTxSidecar() == nil) != (want.BlobTxSidecar() == nil) {
+			t.Fatalf("%s: decoded sidecar %v, expected %v", name, got.BlobTxSidecar(), want.BlobTxSidecar())
+		}
+		if want.BlobTxSidecar() != nil {
+			if err := got.BlobTxSidecar().ValidateBlobHashes(got.BlobHashes()); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		from, err := Sender(NewCancunSigner(big.NewInt(1)), &got)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if wantFrom, _ := Sender(NewCancunSigner(big.NewInt(1)), want); from != wantFrom {
+			t.Fatalf("%s: decoded sender %s, expected %s", name, from, wantFrom)
+		}
+
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var parsed Transaction
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if parsed.Hash() != want.Hash() {
+			t.Fatalf("%s: JSON decoded hash %s, expected %s", name, parsed.Hash(), want.Hash())
+		}
+		if (parsed.BlobTxSidecar() == nil) != (want.BlobTxSidecar() == nil) {
+			t.Fatalf("%s: JSON decoded sidecar %v, expected %v", name, parsed.BlobTxSidecar(), want.BlobTxSidecar())
+		}
+	}
+
+	wantCost := big.NewInt(25000*10 + 10 + 2*131072*3)
+	if tx.Cost().Cmp(wantCost) != 0 {
+		t.Fatalf("cost %d, expected %d", tx.Cost(), wantCost)
+	}
+}
+
+func TestBlobTxSidecarValidation(t *testing.T) {
+	sidecar := newTestBlobSidecar(2)
+	hashes := sidecar.BlobHashes()
+	for _, hash := range hashes {
+		if hash[0] != 0x01 {
+			t.Fatalf("unexpected versioned hash %s", hash)
+		}
+	}
+	if err := sidecar.ValidateBlobHashes(hashes); err != nil {
+		t.Fatal(err)
+	}
+	if err := sidecar.WithoutBlobs().ValidateBlobHashes(hashes); err != nil {
+		t.Fatal(err)
+	}
+	if err := sidecar.ValidateBlobHashes(hashes[:1]); !errors.Is(err, ErrBlobSidecarMismatch) {
+		t.Fatalf("expected %v, got %v", ErrBlobSidecarMismatch, err)
+	}
+	if err := sidecar.ValidateBlobHashes([]common.Hash{hashes[1], hashes[0]}); !errors.Is(err, ErrBlobSidecarMismatch) {
+		t.Fatalf("expected %v, got %v", ErrBlobSidecarMismatch, err)
+	}
+	missingProof := sidecar.copy()
+	missingProof.Proofs = missingProof.Proofs[:1]
+	if err := missingProof.ValidateBlobHashes(hashes); !errors.Is(err, ErrBlobSidecarMismatch) {
+		t.Fatalf("expected %v, got %v", ErrBlobSidecarMismatch, err)
+	}
+}
+
+func TestVerifyBlobHashes(t *testing.T) {
+	valid := common.Hash{0x01}
+	tests := map[string]struct {
+		hashes []common.Hash
+		want   error
+	}{
+		"valid":           {hashes: []common.Hash{valid, valid}},
+		"no hashes":       {want: ErrMissingBlobHashes},
+		"too many hashes": {hashes: make([]common.Hash, 7), want: ErrTooManyBlobs},
+		"invalid version": {hashes: []common.Hash{valid, {0x02}}, want: ErrInvalidBlobHashVersion},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := VerifyBlobHashes(test.hashes); !errors.Is(err, test.want) {
+				t.Fatalf("expected %v, got %v", test.want, err)
+			}
+		})
+	}
+}
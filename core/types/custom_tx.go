@@ -0,0 +1,144 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Envelope types available to subnet-specific transactions. The range is kept clear of the
+// types of Ethereum transactions, which are allocated upwards from zero.
+const (
+	MinCustomTxType = 0x40
+	MaxCustomTxType = 0x7f
+)
+
+var (
+	// customTxTypes contains the names of the registered custom transaction types keyed by type.
+	customTxTypes     = make(map[uint8]string)
+	customTxTypesLock sync.RWMutex
+)
+
+// RegisterCustomTxType registers [txType] as the envelope type of the subnet-specific transactions
+// named [name], so that transactions of the type are decoded, encoded and signed as [CustomTx].
+// Returns an error if [txType] is not in [MinCustomTxType, MaxCustomTxType] or is already registered.
+// Use core.RegisterTxType to register a transaction type together with its validation and execution.
+func RegisterCustomTxType(txType uint8, name string) error {
+	if txType < MinCustomTxType || txType > MaxCustomTxType {
+		return fmt.Errorf("custom transaction type %#x (%s) is not in the range [%#x, %#x]", txType, name, MinCustomTxType, MaxCustomTxType)
+	}
+	customTxTypesLock.Lock()
+	defer customTxTypesLock.Unlock()
+
+	if existing, ok := customTxTypes[txType]; ok {
+		return fmt.Errorf("custom transaction type %#x (%s) is already registered as %s", txType, name, existing)
+	}
+	customTxTypes[txType] = name
+	return nil
+}
+
+// IsCustomTxType returns whether [txType] is a registered custom transaction type.
+func IsCustomTxType(txType uint8) bool {
+	_, ok := CustomTxTypeName(txType)
+	return ok
+}
+
+// CustomTxTypeName returns the name [txType] was registered with, or false if it is not
+// a registered custom transaction type.
+func CustomTxTypeName(txType uint8) (string, bool) {
+	customTxTypesLock.RLock()
+	defer customTxTypesLock.RUnlock()
+
+	name, ok := customTxTypes[txType]
+	return name, ok
+}
+
+// CustomTx is the envelope of the transaction types registered with [RegisterCustomTxType].
+// It has the fields of a dynamic fee transaction, is signed like one under its own type, and its
+// data holds the payload of the transaction in the format defined by its type.
+type CustomTx struct {
+	Type       uint8 `rlp:"-"` // registered type of the transaction, encoded as its envelope type
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int // a.k.a. maxPriorityFeePerGas
+	GasFeeCap  *big.Int // a.k.a. maxFeePerGas
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+
+	// Signature values
+	V *big.Int `json:"v" gencodec:"required"`
+	R *big.Int `json:"r" gencodec:"required"`
+	S *big.Int `json:"s" gencodec:"required"`
+}
+
+// copy creates a deep copy of the transaction data and initializes all fields.
+func (tx *CustomTx) copy() TxData {
+	cpy := &CustomTx{
+		Type:  tx.Type,
+		Nonce: tx.Nonce,
+		To:    copyAddressPtr(tx.To),
+		Data:  common.CopyBytes(tx.Data),
+		Gas:   tx.Gas,
+		// These are copied below.
+		AccessList: make(AccessList, len(tx.AccessList)),
+		Value:      new(big.Int),
+		ChainID:    new(big.Int),
+		GasTipCap:  new(big.Int),
+		GasFeeCap:  new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	copy(cpy.AccessList, tx.AccessList)
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.ChainID != nil {
+		cpy.ChainID.Set(tx.ChainID)
+	}
+	if tx.GasTipCap != nil {
+		cpy.GasTipCap.Set(tx.GasTipCap)
+	}
+	if tx.GasFeeCap != nil {
+		cpy.GasFeeCap.Set(tx.GasFeeCap)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+// accessors for innerTx.
+func (tx *CustomTx) txType() byte           { return tx.Type }
+func (tx *CustomTx) chainID() *big.Int      { return tx.ChainID }
+func (tx *CustomTx) accessList() AccessList { return tx.AccessList }
+func (tx *CustomTx) data() []byte           { return tx.Data }
+func (tx *CustomTx) gas() uint64            { return tx.Gas }
+func (tx *CustomTx) gasFeeCap() *big.Int    { return tx.GasFeeCap }
+func (tx *CustomTx) gasTipCap() *big.Int    { return tx.GasTipCap }
+func (tx *CustomTx) gasPrice() *big.Int     { return tx.GasFeeCap }
+func (tx *CustomTx) value() *big.Int        { return tx.Value }
+func (tx *CustomTx) nonce() uint64          { return tx.Nonce }
+func (tx *CustomTx) to() *common.Address    { return tx.To }
+
+func (tx *CustomTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *CustomTx) setSignatureValues(chainID, v, r, s *big.Int) {
+	tx.ChainID, tx.V, tx.R, tx.S = chainID, v, r, s
+}
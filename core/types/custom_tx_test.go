@@ -0,0 +1,109 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testCustomTxType = MinCustomTxType
+
+func init() {
+	if err := RegisterCustomTxType(testCustomTxType, "test"); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterCustomTxType(t *testing.T) {
+	if err := RegisterCustomTxType(testCustomTxType, "duplicate"); err == nil {
+		t.Fatal("expected registering a duplicate custom transaction type to fail")
+	}
+	for _, txType := range []uint8{LegacyTxType, DynamicFeeTxType, SetCodeTxType, MinCustomTxType - 1, MaxCustomTxType + 1} {
+		if err := RegisterCustomTxType(txType, "invalid"); err == nil {
+			t.Fatalf("expected registering custom transaction type %#x to fail", txType)
+		}
+	}
+	if name, ok := CustomTxTypeName(testCustomTxType); !ok || name != "test" {
+		t.Fatalf("unexpected name of custom transaction type: have %q (%t), want %q", name, ok, "test")
+	}
+	if IsCustomTxType(testCustomTxType + 1) {
+		t.Fatalf("unregistered type %#x reported as a custom transaction type", testCustomTxType+1)
+	}
+}
+
+func TestCustomTxEncoding(t *testing.T) {
+	var (
+		chainID = big.NewInt(1)
+		signer  = NewLondonSigner(chainID)
+		key, _  = crypto.GenerateKey()
+	)
+	tx, err := SignNewTx(key, signer, &CustomTx{
+		Type:      testCustomTxType,
+		ChainID:   chainID,
+		Nonce:     5,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       50000,
+		To:        &testAddr,
+		Value:     big.NewInt(10),
+		Data:      common.FromHex("5544"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != testCustomTxType {
+		t.Fatalf("unexpected transaction type %#x, expected %#x", tx.Type(), testCustomTxType)
+	}
+
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc[0] != testCustomTxType {
+		t.Fatalf("unexpected envelope type %#x, expected %#x", enc[0], testCustomTxType)
+	}
+	var decoded Transaction
+	if err := decoded.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]*Transaction{"rlp": &decoded, "json": &parsed} {
+		if got.Type() != testCustomTxType {
+			t.Fatalf("%s: decoded type %#x, expected %#x", name, got.Type(), testCustomTxType)
+		}
+		if got.Hash() != tx.Hash() {
+			t.Fatalf("%s: decoded hash %s, expected %s", name, got.Hash(), tx.Hash())
+		}
+		from, err := Sender(signer, got)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if from != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("%s: decoded sender %s, expected %s", name, from, crypto.PubkeyToAddress(key.PublicKey))
+		}
+	}
+
+	// Transactions of unregistered types are rejected.
+	enc[0] = testCustomTxType + 1
+	if err := decoded.UnmarshalBinary(enc); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("expected error %v decoding an unregistered type, got %v", ErrTxTypeNotSupported, err)
+	}
+	// Custom transactions are not supported before London.
+	if _, err := Sender(NewEIP2930Signer(chainID), tx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Fatalf("expected error %v recovering the sender before London, got %v", ErrTxTypeNotSupported, err)
+	}
+}
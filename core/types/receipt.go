@@ -224,17 +224,19 @@ func (r *Receipt) decodeTyped(b []byte) error {
 		return errShortTypedReceipt
 	}
 	switch b[0] {
-	case DynamicFeeTxType, AccessListTxType:
-		var data receiptRLP
-		err := rlp.DecodeBytes(b[1:], &data)
-		if err != nil {
-			return err
-		}
-		r.Type = b[0]
-		return r.setFromRLP(data)
+	case DynamicFeeTxType, AccessListTxType, BlobTxType, SetCodeTxType:
 	default:
-		return ErrTxTypeNotSupported
+		if !IsCustomTxType(b[0]) {
+			return ErrTxTypeNotSupported
+		}
 	}
+	var data receiptRLP
+	err := rlp.DecodeBytes(b[1:], &data)
+	if err != nil {
+		return err
+	}
+	r.Type = b[0]
+	return r.setFromRLP(data)
 }
 
 func (r *Receipt) setFromRLP(data receiptRLP) error {
@@ -397,7 +399,17 @@ func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
 	case DynamicFeeTxType:
 		w.WriteByte(DynamicFeeTxType)
 		rlp.Encode(w, data)
+	case BlobTxType:
+		w.WriteByte(BlobTxType)
+		rlp.Encode(w, data)
+	case SetCodeTxType:
+		w.WriteByte(SetCodeTxType)
+		rlp.Encode(w, data)
 	default:
+		if IsCustomTxType(r.Type) {
+			w.WriteByte(r.Type)
+			rlp.Encode(w, data)
+		}
 		// For unsupported types, write nothing. Since this is for
 		// DeriveSha, the error will be caught matching the derived hash
 		// to the block.
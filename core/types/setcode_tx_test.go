@@ -0,0 +1,104 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	got, ok := ParseDelegation(AddressToDelegation(addr))
+	if !ok || got != addr {
+		t.Fatalf("failed to parse delegation: have %s (%t), want %s", got, ok, addr)
+	}
+	for _, code := range [][]byte{
+		nil,
+		DelegationPrefix,
+		append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		append(AddressToDelegation(addr), 0x00),
+	} {
+		if _, ok := ParseDelegation(code); ok {
+			t.Fatalf("parsed delegation from %x", code)
+		}
+	}
+}
+
+func TestSetCodeTxEncoding(t *testing.T) {
+	var (
+		chainID      = big.NewInt(1)
+		signer       = NewPragueSigner(chainID)
+		key, _       = crypto.GenerateKey()
+		authority, _ = crypto.GenerateKey()
+	)
+	auth, err := SignSetCode(authority, SetCodeAuthorization{
+		ChainID: chainID,
+		Address: testAddr,
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err := auth.Authority(); err != nil || addr != crypto.PubkeyToAddress(authority.PublicKey) {
+		t.Fatalf("authority: have %s (%v), want %s", addr, err, crypto.PubkeyToAddress(authority.PublicKey))
+	}
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:   chainID,
+		Nonce:     5,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       50000,
+		To:        testAddr,
+		Value:     big.NewInt(10),
+		Data:      common.FromHex("5544"),
+		AuthList:  []SetCodeAuthorization{auth, auth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorities := tx.SetCodeAuthorities(); len(authorities) != 1 || authorities[0] != crypto.PubkeyToAddress(authority.PublicKey) {
+		t.Fatalf("unexpected authorities %v", authorities)
+	}
+
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Transaction
+	if err := decoded.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Transaction
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]*Transaction{"rlp": &decoded, "json": &parsed} {
+		if got.Hash() != tx.Hash() {
+			t.Fatalf("%s: decoded hash %s, expected %s", name, got.Hash(), tx.Hash())
+		}
+		from, err := Sender(signer, got)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if from != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("%s: decoded sender %s, expected %s", name, from, crypto.PubkeyToAddress(key.PublicKey))
+		}
+		authList := got.SetCodeAuthorizations()
+		if len(authList) != 2 {
+			t.Fatalf("%s: decoded %d authorizations, expected 2", name, len(authList))
+		}
+		if addr, err := authList[0].Authority(); err != nil || addr != crypto.PubkeyToAddress(authority.PublicKey) {
+			t.Fatalf("%s: decoded authority %s (%v), expected %s", name, addr, err, crypto.PubkeyToAddress(authority.PublicKey))
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StateDiff is the set of accounts and storage slots modified by a block,
+// ordered by address.
+type StateDiff struct {
+	Accounts []*AccountDiff
+}
+
+// AccountDiff is the change made to an account by a block. Pre is nil if the
+// account did not exist before the block, and Post is nil if the account was
+// deleted, in which case its storage was cleared and the slots are not listed.
+// Storage holds the modified storage slots ordered by key.
+type AccountDiff struct {
+	Address common.Address
+	Pre     *AccountDiffState `rlp:"nil"`
+	Post    *AccountDiffState `rlp:"nil"`
+	Storage []*StorageDiff
+}
+
+// AccountDiffState is the state of an account before or after a block, except
+// for its storage.
+type AccountDiffState struct {
+	Nonce    uint64
+	Balance  *big.Int
+	CodeHash common.Hash
+}
+
+// StorageDiff is the change made to a storage slot by a block.
+type StorageDiff struct {
+	Key  common.Hash
+	Pre  common.Hash
+	Post common.Hash
+}
@@ -55,6 +55,8 @@ const (
 	LegacyTxType = iota
 	AccessListTxType
 	DynamicFeeTxType
+	BlobTxType
+	SetCodeTxType
 )
 
 // Transaction is an Ethereum transaction.
@@ -62,6 +64,8 @@ type Transaction struct {
 	inner TxData    // Consensus contents of a transaction
 	time  time.Time // Time first seen locally (spam avoidance)
 
+	conditional *TransactionConditional // Preconditions of the transaction, local to this node
+
 	// caches
 	hash atomic.Value
 	size atomic.Value
@@ -77,7 +81,7 @@ func NewTx(inner TxData) *Transaction {
 
 // TxData is the underlying data of a transaction.
 //
-// This is implemented by DynamicFeeTx, LegacyTx and AccessListTx.
+// This is implemented by CustomTx, SetCodeTx, BlobTx, DynamicFeeTx, LegacyTx and AccessListTx.
 type TxData interface {
 	txType() byte // returns the type ID
 	copy() TxData // creates a deep copy and initializes all fields
@@ -113,9 +117,23 @@ func (tx *Transaction) EncodeRLP(w io.Writer) error {
 }
 
 // encodeTyped writes the canonical encoding of a typed transaction to w.
+// Blob transactions carrying their sidecar are written in their network representation.
 func (tx *Transaction) encodeTyped(w *bytes.Buffer) error {
 	w.WriteByte(tx.Type())
-	return rlp.Encode(w, tx.inner)
+	return rlp.Encode(w, tx.encodedInner())
+}
+
+// encodedInner returns the value RLP encoded as the payload of the transaction.
+func (tx *Transaction) encodedInner() interface{} {
+	if blobtx, ok := tx.inner.(*BlobTx); ok && blobtx.Sidecar != nil {
+		return &blobTxWithBlobs{
+			BlobTx:      blobtx,
+			Blobs:       blobtx.Sidecar.Blobs,
+			Commitments: blobtx.Sidecar.Commitments,
+			Proofs:      blobtx.Sidecar.Proofs,
+		}
+	}
+	return tx.inner
 }
 
 // MarshalBinary returns the canonical encoding of the transaction.
@@ -194,7 +212,18 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		var inner DynamicFeeTx
 		err := rlp.DecodeBytes(b[1:], &inner)
 		return &inner, err
+	case BlobTxType:
+		return decodeBlobTx(b[1:])
+	case SetCodeTxType:
+		var inner SetCodeTx
+		err := rlp.DecodeBytes(b[1:], &inner)
+		return &inner, err
 	default:
+		if IsCustomTxType(b[0]) {
+			inner := CustomTx{Type: b[0]}
+			err := rlp.DecodeBytes(b[1:], &inner)
+			return &inner, err
+		}
 		return nil, ErrTxTypeNotSupported
 	}
 }
@@ -295,9 +324,105 @@ func (tx *Transaction) To() *common.Address {
 	return copyAddressPtr(tx.inner.to())
 }
 
-// Cost returns gas * gasPrice + value.
+// BlobGas returns the blob gas limit of the transaction for blob transactions, 0 otherwise.
+func (tx *Transaction) BlobGas() uint64 {
+	if blobtx, ok := tx.inner.(*BlobTx); ok {
+		return blobtx.blobGas()
+	}
+	return 0
+}
+
+// BlobGasFeeCap returns the blob gas fee cap per blob gas of the transaction for blob transactions, nil otherwise.
+func (tx *Transaction) BlobGasFeeCap() *big.Int {
+	if blobtx, ok := tx.inner.(*BlobTx); ok {
+		return new(big.Int).Set(blobtx.BlobFeeCap)
+	}
+	return nil
+}
+
+// BlobHashes returns the hashes of the blob commitments for blob transactions, nil otherwise.
+func (tx *Transaction) BlobHashes() []common.Hash {
+	if blobtx, ok := tx.inner.(*BlobTx); ok {
+		return blobtx.BlobHashes
+	}
+	return nil
+}
+
+// BlobTxSidecar returns the sidecar of a blob transaction, nil otherwise.
+func (tx *Transaction) BlobTxSidecar() *BlobTxSidecar {
+	if blobtx, ok := tx.inner.(*BlobTx); ok {
+		return blobtx.Sidecar
+	}
+	return nil
+}
+
+// WithBlobTxSidecar returns a copy of the blob transaction with its sidecar replaced by [sidecar],
+// or [tx] itself if it is not a blob transaction.
+func (tx *Transaction) WithBlobTxSidecar(sidecar *BlobTxSidecar) *Transaction {
+	blobtx, ok := tx.inner.(*BlobTx)
+	if !ok {
+		return tx
+	}
+	inner := blobtx.withoutSidecar()
+	inner.Sidecar = sidecar
+	cpy := &Transaction{inner: inner, time: tx.time, conditional: tx.conditional}
+	// Note: tx.size cache not carried over because the sidecar is included in size!
+	if h := tx.hash.Load(); h != nil {
+		cpy.hash.Store(h)
+	}
+	if f := tx.from.Load(); f != nil {
+		cpy.from.Store(f)
+	}
+	return cpy
+}
+
+// WithoutBlobTxSidecar returns a copy of the blob transaction without its sidecar, as included
+// in blocks, or [tx] itself if it is not a blob transaction or has no sidecar.
+func (tx *Transaction) WithoutBlobTxSidecar() *Transaction {
+	if tx.BlobTxSidecar() == nil {
+		return tx
+	}
+	return tx.WithBlobTxSidecar(nil)
+}
+
+// SetCodeAuthorizations returns the authorization list of set code transactions, nil otherwise.
+func (tx *Transaction) SetCodeAuthorizations() []SetCodeAuthorization {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	return setcodetx.AuthList
+}
+
+// SetCodeAuthorities returns the unique accounts whose signature is valid in the
+// authorization list of set code transactions, nil otherwise.
+func (tx *Transaction) SetCodeAuthorities() []common.Address {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	var (
+		auths = make([]common.Address, 0, len(setcodetx.AuthList))
+		seen  = make(map[common.Address]struct{})
+	)
+	for _, auth := range setcodetx.AuthList {
+		if addr, err := auth.Authority(); err == nil {
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			auths = append(auths, addr)
+		}
+	}
+	return auths
+}
+
+// Cost returns (gas * gasPrice) + (blobGas * blobGasFeeCap) + value.
 func (tx *Transaction) Cost() *big.Int {
 	total := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+	if tx.Type() == BlobTxType {
+		total.Add(total, new(big.Int).Mul(tx.BlobGasFeeCap(), new(big.Int).SetUint64(tx.BlobGas())))
+	}
 	total.Add(total, tx.Value())
 	return total
 }
@@ -389,7 +514,7 @@ func (tx *Transaction) Size() common.StorageSize {
 		return size.(common.StorageSize)
 	}
 	c := writeCounter(0)
-	rlp.Encode(&c, &tx.inner)
+	rlp.Encode(&c, tx.encodedInner())
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
@@ -610,6 +735,11 @@ type Message struct {
 	data       []byte
 	accessList AccessList
 	isFake     bool
+
+	blobGasFeeCap *big.Int
+	blobHashes    []common.Hash
+	authList      []SetCodeAuthorization
+	txType        uint8
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice, gasFeeCap, gasTipCap *big.Int, data []byte, accessList AccessList, isFake bool) Message {
@@ -641,6 +771,11 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 		data:       tx.Data(),
 		accessList: tx.AccessList(),
 		isFake:     false,
+
+		blobGasFeeCap: tx.BlobGasFeeCap(),
+		blobHashes:    tx.BlobHashes(),
+		authList:      tx.SetCodeAuthorizations(),
+		txType:        tx.Type(),
 	}
 	// If baseFee provided, set gasPrice to effectiveGasPrice.
 	if baseFee != nil {
@@ -663,6 +798,25 @@ func (m Message) Data() []byte           { return m.data }
 func (m Message) AccessList() AccessList { return m.accessList }
 func (m Message) IsFake() bool           { return m.isFake }
 
+// BlobGasFeeCap returns the blob gas fee cap of blob transactions, nil otherwise.
+func (m Message) BlobGasFeeCap() *big.Int { return m.blobGasFeeCap }
+
+// BlobHashes returns the versioned hashes referenced by blob transactions, nil otherwise.
+func (m Message) BlobHashes() []common.Hash { return m.blobHashes }
+
+// SetCodeAuthorizations returns the authorization list of set code transactions, nil otherwise.
+func (m Message) SetCodeAuthorizations() []SetCodeAuthorization { return m.authList }
+
+// Type returns the type of the transaction of the message, or the legacy type for calls.
+func (m Message) Type() uint8 { return m.txType }
+
+// WithSetCodeAuthorizations returns a copy of the message executed with the authorization
+// list [authList], as set code transactions.
+func (m Message) WithSetCodeAuthorizations(authList []SetCodeAuthorization) Message {
+	m.authList = authList
+	return m
+}
+
 // copyAddressPtr copies an address.
 func copyAddressPtr(a *common.Address) *common.Address {
 	if a == nil {
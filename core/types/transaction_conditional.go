@@ -0,0 +1,147 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// KnownAccount is the storage of an account expected by a conditional transaction, given
+// either as the root of its storage trie or as the values of some of its storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON marshals as either the storage root or an object of storage slots.
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	if a.StorageRoot != nil {
+		return json.Marshal(a.StorageRoot)
+	}
+	return json.Marshal(a.StorageSlots)
+}
+
+// UnmarshalJSON unmarshals from either the storage root or an object of storage slots.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot, a.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or an object of storage slots")
+	}
+	a.StorageRoot, a.StorageSlots = nil, slots
+	return nil
+}
+
+// TransactionConditional holds the preconditions of a transaction submitted through
+// eth_sendRawTransactionConditional. The transaction is only included in a block whose
+// number and timestamp are within the given bounds and whose pre-state matches the
+// storage of the known accounts.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount
+	BlockNumberMin *big.Int
+	BlockNumberMax *big.Int
+	TimestampMin   *uint64
+	TimestampMax   *uint64
+}
+
+// transactionConditionalJSON is the JSON representation of a TransactionConditional.
+type transactionConditionalJSON struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Big                    `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                    `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// MarshalJSON marshals as JSON.
+func (c TransactionConditional) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&transactionConditionalJSON{
+		KnownAccounts:  c.KnownAccounts,
+		BlockNumberMin: (*hexutil.Big)(c.BlockNumberMin),
+		BlockNumberMax: (*hexutil.Big)(c.BlockNumberMax),
+		TimestampMin:   (*hexutil.Uint64)(c.TimestampMin),
+		TimestampMax:   (*hexutil.Uint64)(c.TimestampMax),
+	})
+}
+
+// UnmarshalJSON unmarshals from JSON.
+func (c *TransactionConditional) UnmarshalJSON(input []byte) error {
+	var dec transactionConditionalJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	c.KnownAccounts = dec.KnownAccounts
+	c.BlockNumberMin = (*big.Int)(dec.BlockNumberMin)
+	c.BlockNumberMax = (*big.Int)(dec.BlockNumberMax)
+	c.TimestampMin = (*uint64)(dec.TimestampMin)
+	c.TimestampMax = (*uint64)(dec.TimestampMax)
+	return nil
+}
+
+// Cost returns the number of checks needed to validate the conditional, counting each
+// storage root and storage slot of the known accounts.
+func (c *TransactionConditional) Cost() int {
+	cost := 0
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		} else {
+			cost += len(account.StorageSlots)
+		}
+	}
+	if c.BlockNumberMin != nil || c.BlockNumberMax != nil {
+		cost++
+	}
+	if c.TimestampMin != nil || c.TimestampMax != nil {
+		cost++
+	}
+	return cost
+}
+
+// CheckBlock returns an error if a block with [number] and [timestamp] is outside of the
+// bounds of the conditional.
+func (c *TransactionConditional) CheckBlock(number *big.Int, timestamp uint64) error {
+	if c.BlockNumberMin != nil && number.Cmp(c.BlockNumberMin) < 0 {
+		return fmt.Errorf("block number %v is lower than the minimum %v", number, c.BlockNumberMin)
+	}
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("block number %v is higher than the maximum %v", number, c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && timestamp < *c.TimestampMin {
+		return fmt.Errorf("block timestamp %d is lower than the minimum %d", timestamp, *c.TimestampMin)
+	}
+	if c.TimestampMax != nil && timestamp > *c.TimestampMax {
+		return fmt.Errorf("block timestamp %d is higher than the maximum %d", timestamp, *c.TimestampMax)
+	}
+	return nil
+}
+
+// Expired returns whether a block with [number] and [timestamp] is past the upper bounds
+// of the conditional, such that no later block can satisfy them either.
+func (c *TransactionConditional) Expired(number *big.Int, timestamp uint64) bool {
+	return (c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0) ||
+		(c.TimestampMax != nil && timestamp > *c.TimestampMax)
+}
+
+// Conditional returns the preconditions the transaction was submitted with, if any.
+// The conditional is local to this node: it is not part of the encoding of the
+// transaction and is not gossiped.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	return tx.conditional
+}
+
+// SetConditional sets the preconditions of the transaction.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.conditional = cond
+}
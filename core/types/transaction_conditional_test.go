@@ -0,0 +1,93 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTransactionConditionalJSON(t *testing.T) {
+	input := `{
+		"knownAccounts": {
+			"0x0000000000000000000000000000000000000001": "0x0100000000000000000000000000000000000000000000000000000000000000",
+			"0x0000000000000000000000000000000000000002": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"
+			}
+		},
+		"blockNumberMin": "0x1",
+		"timestampMax": "0x64"
+	}`
+	var cond TransactionConditional
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatal(err)
+	}
+	root := common.Hash{1}
+	timestampMax := uint64(100)
+	want := TransactionConditional{
+		KnownAccounts: map[common.Address]KnownAccount{
+			common.HexToAddress("0x01"): {StorageRoot: &root},
+			common.HexToAddress("0x02"): {StorageSlots: map[common.Hash]common.Hash{
+				common.HexToHash("0x01"): common.HexToHash("0x02"),
+			}},
+		},
+		BlockNumberMin: big.NewInt(1),
+		TimestampMax:   &timestampMax,
+	}
+	if !reflect.DeepEqual(cond, want) {
+		t.Fatalf("unexpected conditional: have %+v, want %+v", cond, want)
+	}
+	if cost := cond.Cost(); cost != 4 {
+		t.Fatalf("unexpected cost: have %d, want 4", cost)
+	}
+	data, err := json.Marshal(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TransactionConditional
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Fatalf("unexpected conditional after round trip: have %+v, want %+v", decoded, want)
+	}
+	if err := json.Unmarshal([]byte(`{"knownAccounts": {"0x0000000000000000000000000000000000000001": 1}}`), &decoded); err == nil {
+		t.Fatal("expected invalid known account to fail")
+	}
+}
+
+func TestTransactionConditionalCheckBlock(t *testing.T) {
+	timestampMin, timestampMax := uint64(10), uint64(20)
+	cond := &TransactionConditional{
+		BlockNumberMin: big.NewInt(5),
+		BlockNumberMax: big.NewInt(6),
+		TimestampMin:   &timestampMin,
+		TimestampMax:   &timestampMax,
+	}
+	for _, test := range []struct {
+		number    int64
+		timestamp uint64
+		valid     bool
+		expired   bool
+	}{
+		{number: 4, timestamp: 15},
+		{number: 5, timestamp: 9},
+		{number: 5, timestamp: 10, valid: true},
+		{number: 6, timestamp: 20, valid: true},
+		{number: 6, timestamp: 21, expired: true},
+		{number: 7, timestamp: 15, expired: true},
+	} {
+		number := big.NewInt(test.number)
+		if err := cond.CheckBlock(number, test.timestamp); (err == nil) != test.valid {
+			t.Errorf("block %d at %d: unexpected check result %v", test.number, test.timestamp, err)
+		}
+		if expired := cond.Expired(number, test.timestamp); expired != test.expired {
+			t.Errorf("block %d at %d: have expired %t, want %t", test.number, test.timestamp, expired, test.expired)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BlockReplayResult reports how a block replayed against a proposed chain config diverged
+// from the block accepted by the chain.
+type BlockReplayResult struct {
+	Number          uint64      `json:"number"`
+	Hash            common.Hash `json:"hash"`
+	GasUsed         uint64      `json:"gasUsed"`
+	ReplayedGasUsed uint64      `json:"replayedGasUsed"`
+	// Divergences describes each difference between the accepted block and its replay.
+	Divergences []string `json:"divergences,omitempty"`
+	// FirstDivergentTx is the hash of the first transaction whose receipt differs from the accepted one, if any.
+	FirstDivergentTx *common.Hash `json:"firstDivergentTx,omitempty"`
+	// Error is set if the block is invalid under the proposed chain config, in which case
+	// the replay stops at this block.
+	Error string `json:"error,omitempty"`
+}
+
+// DryRunChainConfig replays the canonical blocks from [from] to [to] (inclusive) against [config] on top of
+// the state of the parent of [from], and reports how each block diverges from the accepted chain.
+// The blocks are replayed in an isolated copy of the state that is never committed, so that upgrades
+// can be rehearsed against real traffic before they are scheduled: each block is applied to the state
+// produced by the replay of its parent, as in a shadow fork of the chain.
+func (bc *BlockChain) DryRunChainConfig(config *params.ChainConfig, from uint64, to uint64) ([]*BlockReplayResult, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	parent := bc.GetHeaderByNumber(from - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block %d not found", from-1)
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d is not available: %w", from-1, err)
+	}
+	shadow := &shadowChain{BlockChain: bc, config: config, state: statedb}
+
+	results := make([]*BlockReplayResult, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		result := shadow.replay(block, parent, bc.GetReceiptsByHash(block.Hash()), *bc.GetVMConfig())
+		results = append(results, result)
+		if result.Error != "" {
+			break
+		}
+		parent = block.Header()
+	}
+	return results, nil
+}
+
+// ReplayChain re-executes the blocks of the chain export read from [r] that follow the last accepted block,
+// and reports how the receipts and the state root of each block diverge from the exported ones. The blocks
+// are replayed against the chain config of the exporting node, with its upgrades replaced by [upgradeConfig]
+// if it is not nil, so that changes to the binary or proposed upgrades can be checked against the history
+// of a chain. As in [DryRunChainConfig], each block is replayed on top of the replay of its parent in an
+// isolated copy of the state, and the replay stops at the first block that is invalid.
+func (bc *BlockChain) ReplayChain(r io.Reader, upgradeConfig *params.UpgradeConfig) ([]*BlockReplayResult, error) {
+	reader, err := newChainExportReader(r, bc.genesisBlock.Hash(), bc.chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+
+	config := *reader.exported
+	if upgradeConfig != nil {
+		config.UpgradeConfig = *upgradeConfig
+		if err := config.Verify(); err != nil {
+			return nil, fmt.Errorf("invalid upgrade config: %w", err)
+		}
+	}
+	parent := bc.LastAcceptedBlock().Header()
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d is not available: %w", parent.Number, err)
+	}
+	shadow := &shadowChain{BlockChain: bc, config: &config, state: statedb, headers: make(map[common.Hash]*types.Header)}
+
+	var results []*BlockReplayResult
+	for {
+		block, receipts, err := reader.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if block.NumberU64() <= parent.Number.Uint64() {
+			continue
+		}
+		if block.ParentHash() != parent.Hash() {
+			return nil, fmt.Errorf("exported block %d (%s) does not follow block %d (%s)", block.NumberU64(), block.Hash(), parent.Number, parent.Hash())
+		}
+		// The replayed blocks are not stored, so their headers are kept for the BLOCKHASH opcode.
+		shadow.addHeader(block.Header())
+		result := shadow.replay(block, parent, receipts, *bc.GetVMConfig())
+		results = append(results, result)
+		if result.Error != "" {
+			break
+		}
+		parent = block.Header()
+	}
+	return results, nil
+}
+
+// FirstDivergence returns the first of [results] that diverges or is invalid, or nil if the replay
+// matched the accepted chain.
+func FirstDivergence(results []*BlockReplayResult) *BlockReplayResult {
+	for _, result := range results {
+		if len(result.Divergences) > 0 || result.Error != "" {
+			return result
+		}
+	}
+	return nil
+}
+
+// shadowChain is a chain reader for replaying blocks against [config], where the fee config and coinbase
+// are read from the replayed [state] instead of the state of the accepted chain.
+type shadowChain struct {
+	*BlockChain
+	config *params.ChainConfig
+	state  *state.StateDB
+	// headers are the headers of the last replayed blocks that are not stored by the chain, by hash.
+	headers map[common.Hash]*types.Header
+
+	// fee config and coinbase at the parent of the block being replayed,
+	// read before the block modifies [state].
+	feeConfig          commontype.FeeConfig
+	feeLastChangedAt   *big.Int
+	coinbase           common.Address
+	allowFeeRecipients bool
+}
+
+func (s *shadowChain) Config() *params.ChainConfig { return s.config }
+
+func (s *shadowChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := s.headers[hash]; ok {
+		return header
+	}
+	return s.BlockChain.GetHeader(hash, number)
+}
+
+// addHeader records the [header] of a replayed block that is not stored by the chain, and forgets the
+// headers that can no longer be read by the BLOCKHASH opcode.
+func (s *shadowChain) addHeader(header *types.Header) {
+	s.headers[header.Hash()] = header
+	if len(s.headers) <= 257 {
+		return
+	}
+	for hash, h := range s.headers {
+		if h.Number.Uint64()+257 <= header.Number.Uint64() {
+			delete(s.headers, hash)
+		}
+	}
+}
+
+func (s *shadowChain) GetFeeConfigAt(*types.Header) (commontype.FeeConfig, *big.Int, error) {
+	return s.feeConfig, s.feeLastChangedAt, nil
+}
+
+func (s *shadowChain) GetCoinbaseAt(*types.Header) (common.Address, bool, error) {
+	return s.coinbase, s.allowFeeRecipients, nil
+}
+
+// readParentConfigs reads the fee config and the coinbase in effect at [parent] from the replayed state,
+// as BlockChain.GetFeeConfigAt and BlockChain.GetCoinbaseAt do from the accepted state.
+func (s *shadowChain) readParentConfigs(parent *types.Header) {
+	parentTime := new(big.Int).SetUint64(parent.Time)
+	if s.config.IsFeeConfigManager(parent.Number, parentTime) {
+		s.feeConfig = precompile.GetStoredFeeConfig(s.state)
+		s.feeLastChangedAt = precompile.GetFeeConfigLastChangedAt(s.state)
+	} else {
+		s.feeConfig = s.config.FeeConfigAt(parentTime)
+		s.feeLastChangedAt = common.Big0
+	}
+
+	switch {
+	case !s.config.IsSubnetEVM(parentTime):
+		s.coinbase, s.allowFeeRecipients = constants.BlackholeAddr, false
+	case s.config.IsRewardManager(parent.Number, parentTime):
+		s.coinbase, s.allowFeeRecipients = precompile.GetStoredRewardAddress(s.state)
+	case s.config.AllowFeeRecipientsAt(parentTime):
+		s.coinbase, s.allowFeeRecipients = common.Address{}, true
+	default:
+		s.coinbase, s.allowFeeRecipients = constants.BlackholeAddr, false
+	}
+}
+
+// replay applies [block] to the replayed state as StateProcessor.Process does, and compares the
+// result with the [acceptedReceipts] and the state root of the accepted block.
+func (s *shadowChain) replay(block *types.Block, parent *types.Header, acceptedReceipts types.Receipts, cfg vm.Config) *BlockReplayResult {
+	var (
+		header    = block.Header()
+		timestamp = new(big.Int).SetUint64(header.Time)
+		gp        = new(GasPool).AddGas(block.GasLimit())
+		usedGas   = new(uint64)
+		receipts  types.Receipts
+		result    = &BlockReplayResult{Number: block.NumberU64(), Hash: block.Hash(), GasUsed: block.GasUsed()}
+	)
+	s.readParentConfigs(parent)
+
+	s.config.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time), block, s.state)
+	if err := s.config.ApplyStatePatch(parent.Root, block, s.state); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := ApplyValidatorSnapshot(s.config, header, s.state); err != nil {
+		result.Error = fmt.Sprintf("could not apply validator snapshot: %s", err)
+		return result
+	}
+
+	vmenv := vm.NewEVM(NewEVMBlockContext(header, s, nil), vm.TxContext{}, s.state, s.config, cfg)
+	for i, tx := range block.Transactions() {
+		var receipt *types.Receipt
+		msg, err := tx.AsMessage(types.MakeSigner(s.config, header.Number, timestamp), header.BaseFee)
+		if err == nil {
+			s.state.Prepare(tx.Hash(), i)
+			receipt, err = applyTransaction(msg, s.config, nil, gp, s.state, header.Number, block.Hash(), tx, usedGas, vmenv)
+		}
+		if err != nil {
+			txHash := tx.Hash()
+			result.Error = fmt.Sprintf("could not apply tx %d [%v]: %s", i, txHash.Hex(), err)
+			result.FirstDivergentTx = &txHash
+			return result
+		}
+		receipts = append(receipts, receipt)
+	}
+	result.ReplayedGasUsed = *usedGas
+	if err := s.engine.Finalize(s, block, parent, s.state, receipts); err != nil {
+		result.Error = fmt.Sprintf("engine finalization check failed: %s", err)
+		return result
+	}
+
+	for i, receipt := range receipts {
+		if i >= len(acceptedReceipts) {
+			break
+		}
+		divergences := diffReceipts(s.config.IsByzantium(header.Number), receipt, acceptedReceipts[i])
+		if len(divergences) == 0 {
+			continue
+		}
+		if result.FirstDivergentTx == nil {
+			txHash := receipt.TxHash
+			result.FirstDivergentTx = &txHash
+		}
+		for _, divergence := range divergences {
+			result.Divergences = append(result.Divergences, fmt.Sprintf("tx %d [%v]: %s", i, receipt.TxHash.Hex(), divergence))
+		}
+	}
+	if root := s.state.IntermediateRoot(s.config.IsEIP158(header.Number)); root != header.Root {
+		result.Divergences = append(result.Divergences, fmt.Sprintf("state root %v, accepted %v", root.Hex(), header.Root.Hex()))
+	}
+	return result
+}
+
+// diffReceipts describes each difference between the [replayed] receipt of a transaction and the
+// [accepted] one. Receipts only have a status as of Byzantium.
+func diffReceipts(byzantium bool, replayed *types.Receipt, accepted *types.Receipt) []string {
+	var divergences []string
+	if byzantium && replayed.Status != accepted.Status {
+		divergences = append(divergences, fmt.Sprintf("status %d, accepted %d", replayed.Status, accepted.Status))
+	}
+	if replayed.GasUsed != accepted.GasUsed {
+		divergences = append(divergences, fmt.Sprintf("gas used %d, accepted %d", replayed.GasUsed, accepted.GasUsed))
+	}
+	if replayed.ContractAddress != accepted.ContractAddress {
+		divergences = append(divergences, fmt.Sprintf("contract address %v, accepted %v", replayed.ContractAddress.Hex(), accepted.ContractAddress.Hex()))
+	}
+	if len(replayed.Logs) != len(accepted.Logs) {
+		return append(divergences, fmt.Sprintf("%d logs, accepted %d", len(replayed.Logs), len(accepted.Logs)))
+	}
+	for i, replayedLog := range replayed.Logs {
+		if !equalLogs(replayedLog, accepted.Logs[i]) {
+			divergences = append(divergences, fmt.Sprintf("log %d differs", i))
+		}
+	}
+	return divergences
+}
+
+func equalLogs(a *types.Log, b *types.Log) bool {
+	if a.Address != b.Address || len(a.Topics) != len(b.Topics) || !bytes.Equal(a.Data, b.Data) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
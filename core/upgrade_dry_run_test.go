@@ -0,0 +1,138 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunChainConfig(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+	config := *params.TestPreSubnetEVMConfig
+	gspec := &Genesis{
+		Config: &config,
+		Alloc:  GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, archiveConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer blockchain.Stop()
+
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 5, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	require.NoError(t, err)
+	_, err = blockchain.InsertChain(chain)
+	require.NoError(t, err)
+	for _, block := range chain {
+		require.NoError(t, blockchain.Accept(block))
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// Replaying against the current chain config does not diverge.
+	results, err := blockchain.DryRunChainConfig(gspec.Config, 1, 5)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	for _, result := range results {
+		require.Empty(t, result.Divergences)
+		require.Empty(t, result.Error)
+		require.Equal(t, result.GasUsed, result.ReplayedGasUsed)
+	}
+
+	// Enabling the tx allow list without [addr1] invalidates its transactions from the activation on.
+	proposed := *gspec.Config
+	proposed.UpgradeConfig = params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(30), []common.Address{addr2}, nil)),
+		},
+	}
+	results, err = blockchain.DryRunChainConfig(&proposed, 2, 5)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.EqualValues(t, 2, results[0].Number)
+	require.Empty(t, results[0].Error)
+	require.EqualValues(t, 3, results[1].Number)
+	require.Contains(t, results[1].Error, "could not apply tx 0")
+
+	// The accepted state is not modified by the replay.
+	state, err := blockchain.State()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(50000), state.GetBalance(addr2))
+
+	_, err = blockchain.DryRunChainConfig(gspec.Config, 0, 5)
+	require.ErrorContains(t, err, "invalid block range")
+}
+
+func TestReplayChain(t *testing.T) {
+	gspec, blockchain, chain := newChainExportTest(t)
+	recipient := chain[0].Transactions()[0].To()
+
+	var exported bytes.Buffer
+	_, err := blockchain.ExportChain(&exported, 1, 12)
+	require.NoError(t, err)
+
+	// The blocks are replayed on top of the last accepted block of a new node
+	replayDB := rawdb.NewMemoryDatabase()
+	_ = gspec.MustCommit(replayDB)
+	replayChain, err := createBlockChain(replayDB, DefaultCacheConfig, gspec.Config, common.Hash{})
+	require.NoError(t, err)
+	defer replayChain.Stop()
+
+	// Replaying against the exported chain config does not diverge.
+	results, err := replayChain.ReplayChain(bytes.NewReader(exported.Bytes()), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 12)
+	require.Nil(t, FirstDivergence(results))
+	require.Zero(t, replayChain.LastAcceptedBlock().NumberU64())
+
+	// A state patch diverges from the state root of the block it applies at.
+	key, value := common.Hash{1}, common.Hash{2}
+	results, err = replayChain.ReplayChain(bytes.NewReader(exported.Bytes()), &params.UpgradeConfig{
+		StatePatches: []params.StatePatch{{
+			BlockNumber: big.NewInt(3),
+			Operations:  []params.StatePatchOperation{{Op: params.SetStorageOp, Address: *recipient, Key: &key, Value: &value}},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 12)
+	divergence := FirstDivergence(results)
+	require.NotNil(t, divergence)
+	require.EqualValues(t, 3, divergence.Number)
+	require.Nil(t, divergence.FirstDivergentTx)
+	require.Contains(t, divergence.Divergences[0], "state root")
+
+	// Enabling the tx allow list invalidates the transactions from the activation on, at timestamp 50.
+	results, err = replayChain.ReplayChain(bytes.NewReader(exported.Bytes()), &params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(50), []common.Address{*recipient}, nil)),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	divergence = FirstDivergence(results)
+	require.EqualValues(t, 5, divergence.Number)
+	require.Contains(t, divergence.Error, "could not apply tx 0")
+	require.Equal(t, chain[4].Transactions()[0].Hash(), *divergence.FirstDivergentTx)
+}
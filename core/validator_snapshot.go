@@ -0,0 +1,44 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+)
+
+// ApplyValidatorSnapshot records the validator set of the subnet into the state of the validator info
+// precompile if [header] is the first block processed in a new epoch. The validator set is fetched at
+// the P-chain height recorded in [header], so that every node records the same snapshot.
+func ApplyValidatorSnapshot(config *params.ChainConfig, header *types.Header, statedb precompile.StateDB) error {
+	timestamp := new(big.Int).SetUint64(header.Time)
+	validatorInfoConfig := config.GetValidatorInfoConfig(header.Number, timestamp)
+	if validatorInfoConfig == nil || validatorInfoConfig.Disable {
+		return nil
+	}
+	epoch := validatorInfoConfig.EpochAt(timestamp)
+	if snapshotEpoch, _, ok := precompile.GetValidatorSnapshotEpoch(statedb); ok && snapshotEpoch == epoch {
+		return nil
+	}
+
+	pChainHeight, ok := dummy.GetPChainHeight(config, header)
+	if !ok {
+		return precompile.ErrMissingPChainHeight
+	}
+	snowCtx := config.AvalancheContext.SnowCtx
+	if snowCtx == nil || snowCtx.ValidatorState == nil {
+		return precompile.ErrValidatorStateNotFound
+	}
+	validatorSet, err := snowCtx.ValidatorState.GetValidatorSet(context.TODO(), pChainHeight, snowCtx.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get validator set at P-chain height %d: %w", pChainHeight, err)
+	}
+	return precompile.SetValidatorSnapshot(statedb, epoch, pChainHeight, precompile.NewValidatorSnapshot(validatorSet))
+}
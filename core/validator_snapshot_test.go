@@ -0,0 +1,90 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyValidatorSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	subnetID := ids.GenerateTestID()
+	requestedHeights := []uint64{}
+	validatorState := &validators.TestState{
+		GetValidatorSetF: func(_ context.Context, height uint64, requestedSubnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			require.Equal(subnetID, requestedSubnetID)
+			requestedHeights = append(requestedHeights, height)
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				{2}: {NodeID: ids.NodeID{2}, Weight: height},
+				{1}: {NodeID: ids.NodeID{1}, Weight: 1},
+			}, nil
+		},
+	}
+	snowCtx := snow.DefaultContextTest()
+	snowCtx.SubnetID = subnetID
+	snowCtx.ValidatorState = validatorState
+
+	config := *params.TestChainConfig
+	config.AvalancheContext = params.AvalancheContext{SnowCtx: snowCtx}
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewValidatorInfoConfig(big.NewInt(10), 60))
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(err)
+
+	newHeader := func(time uint64, pChainHeight uint64) *types.Header {
+		return &types.Header{
+			Time:  time,
+			Extra: dummy.AppendPChainHeight(make([]byte, params.ExtraDataSize), pChainHeight),
+		}
+	}
+
+	// Before activation no snapshot is taken
+	require.NoError(ApplyValidatorSnapshot(&config, &types.Header{Time: 5}, statedb))
+	_, _, ok := precompile.GetValidatorSnapshotEpoch(statedb)
+	require.False(ok)
+
+	// The first block after activation takes the snapshot of epoch 0
+	require.NoError(ApplyValidatorSnapshot(&config, newHeader(10, 5), statedb))
+	epoch, pChainHeight, ok := precompile.GetValidatorSnapshotEpoch(statedb)
+	require.True(ok)
+	require.Equal(uint64(0), epoch)
+	require.Equal(uint64(5), pChainHeight)
+	require.Equal(uint64(2), precompile.GetValidatorCount(statedb))
+	require.Equal(big.NewInt(6), precompile.GetValidatorTotalWeight(statedb))
+	validator, ok := precompile.GetValidatorAt(statedb, 0)
+	require.True(ok)
+	require.Equal(ids.NodeID{1}, validator.NodeID)
+
+	// Blocks within the same epoch keep the snapshot
+	require.NoError(ApplyValidatorSnapshot(&config, newHeader(69, 8), statedb))
+	_, pChainHeight, _ = precompile.GetValidatorSnapshotEpoch(statedb)
+	require.Equal(uint64(5), pChainHeight)
+	require.Equal([]uint64{5}, requestedHeights)
+
+	// The first block of the next epoch takes a new snapshot
+	require.NoError(ApplyValidatorSnapshot(&config, newHeader(70, 9), statedb))
+	epoch, pChainHeight, _ = precompile.GetValidatorSnapshotEpoch(statedb)
+	require.Equal(uint64(1), epoch)
+	require.Equal(uint64(9), pChainHeight)
+	require.Equal(big.NewInt(10), precompile.GetValidatorTotalWeight(statedb))
+
+	// A header without a P-chain height is rejected
+	err = ApplyValidatorSnapshot(&config, &types.Header{Time: 130, Extra: make([]byte, params.ExtraDataSize)}, statedb)
+	require.ErrorIs(err, precompile.ErrMissingPChainHeight)
+}
@@ -0,0 +1,90 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"sync/atomic"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultPrecompileResultCacheSize is the default number of results of the memoized
+// precompiles kept in the precompile result cache.
+const DefaultPrecompileResultCacheSize = 4096
+
+var (
+	precompileResultCacheHitCounter  = metrics.NewRegisteredCounter("vm/precompile/results/hit", nil)
+	precompileResultCacheMissCounter = metrics.NewRegisteredCounter("vm/precompile/results/miss", nil)
+
+	// precompileResults caches the results of the memoized precompiles by contract and
+	// input hash. It is shared by all EVMs, so that the results computed while verifying
+	// blocks are reused by eth_call simulations and vice versa.
+	precompileResults, _      = lru.New(DefaultPrecompileResultCacheSize)
+	precompileResultsDisabled uint32
+)
+
+// SetPrecompileResultCacheSize sets the number of results of the memoized precompiles
+// kept in the precompile result cache, evicting the least recently used ones if needed.
+// The cache is disabled if [size] is not positive.
+func SetPrecompileResultCacheSize(size int) {
+	if size <= 0 {
+		atomic.StoreUint32(&precompileResultsDisabled, 1)
+		precompileResults.Purge()
+		return
+	}
+	precompileResults.Resize(size)
+	atomic.StoreUint32(&precompileResultsDisabled, 0)
+}
+
+// precompileResultKey identifies the result of running a memoized precompile.
+type precompileResultKey struct {
+	contract PrecompiledContract
+	input    common.Hash
+}
+
+// precompileResult is the output of running a memoized precompile.
+type precompileResult struct {
+	output []byte
+	err    error
+}
+
+// memoizedPrecompiledContract wraps a pure precompiled contract that is expensive to run,
+// such as a signature or pairing check, caching its results by input so that the inputs
+// verified repeatedly (e.g. the aggregate signatures of bridge messages, simulated by
+// eth_call and then verified in a block) are only computed once.
+type memoizedPrecompiledContract struct {
+	p PrecompiledContract
+}
+
+// newMemoizedPrecompiledContract returns a version of [p] caching its results. [p] must be
+// deterministic and not depend on anything but its input.
+func newMemoizedPrecompiledContract(p PrecompiledContract) PrecompiledContract {
+	return &memoizedPrecompiledContract{p: p}
+}
+
+// RequiredGas implements PrecompiledContract. The gas used is the same whether the result
+// is cached or not.
+func (m *memoizedPrecompiledContract) RequiredGas(input []byte) uint64 {
+	return m.p.RequiredGas(input)
+}
+
+// Run implements PrecompiledContract.
+func (m *memoizedPrecompiledContract) Run(input []byte) ([]byte, error) {
+	if atomic.LoadUint32(&precompileResultsDisabled) == 1 {
+		return m.p.Run(input)
+	}
+	key := precompileResultKey{contract: m.p, input: crypto.Keccak256Hash(input)}
+	if cached, ok := precompileResults.Get(key); ok {
+		precompileResultCacheHitCounter.Inc(1)
+		result := cached.(precompileResult)
+		return common.CopyBytes(result.output), result.err
+	}
+	precompileResultCacheMissCounter.Inc(1)
+	output, err := m.p.Run(input)
+	precompileResults.Add(key, precompileResult{output: common.CopyBytes(output), err: err})
+	return output, err
+}
@@ -0,0 +1,79 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/stretchr/testify/require"
+)
+
+var errEmptyInput = errors.New("empty input")
+
+// countingPrecompile is a pure precompile returning its input reversed, counting how
+// many times it is run.
+type countingPrecompile struct {
+	runs int
+}
+
+func (c *countingPrecompile) RequiredGas(input []byte) uint64 { return 100 }
+
+func (c *countingPrecompile) Run(input []byte) ([]byte, error) {
+	c.runs++
+	if len(input) == 0 {
+		return nil, errEmptyInput
+	}
+	output := make([]byte, len(input))
+	for i, b := range input {
+		output[len(input)-1-i] = b
+	}
+	return output, nil
+}
+
+func TestMemoizedPrecompiledContract(t *testing.T) {
+	defer SetPrecompileResultCacheSize(DefaultPrecompileResultCacheSize)
+
+	counting := &countingPrecompile{}
+	p := newMemoizedPrecompiledContract(counting)
+
+	// The result is computed once and the gas is charged on every run
+	for i := 0; i < 2; i++ {
+		ret, remainingGas, err := RunPrecompiledContract(p, []byte{1, 2, 3}, 1000)
+		require.NoError(t, err)
+		require.Equal(t, []byte{3, 2, 1}, ret)
+		require.EqualValues(t, 900, remainingGas)
+		require.Equal(t, 1, counting.runs)
+
+		// Modifying the returned bytes does not modify the cached result
+		ret[0] = 0
+	}
+	_, _, err := RunPrecompiledContract(p, []byte{1, 2, 3}, 99)
+	require.ErrorIs(t, err, vmerrs.ErrOutOfGas)
+	require.Equal(t, 1, counting.runs)
+
+	// Errors are cached along with the results
+	for i := 0; i < 2; i++ {
+		_, _, err := RunPrecompiledContract(p, nil, 1000)
+		require.ErrorIs(t, err, errEmptyInput)
+		require.Equal(t, 2, counting.runs)
+	}
+
+	// The results of different contracts are cached separately
+	other := &countingPrecompile{}
+	ret, _, err := RunPrecompiledContract(newMemoizedPrecompiledContract(other), []byte{1, 2, 3}, 1000)
+	require.NoError(t, err)
+	require.Equal(t, []byte{3, 2, 1}, ret)
+	require.Equal(t, 1, other.runs)
+
+	// Nothing is cached while the cache is disabled
+	SetPrecompileResultCacheSize(0)
+	for i := 0; i < 2; i++ {
+		ret, _, err := RunPrecompiledContract(p, []byte{1, 2, 3}, 1000)
+		require.NoError(t, err)
+		require.Equal(t, []byte{3, 2, 1}, ret)
+	}
+	require.Equal(t, 4, counting.runs)
+}
@@ -5,7 +5,10 @@ package vm
 
 import (
 	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // wrappedPrecompiledContract implements StatefulPrecompiledContract by wrapping stateless native precompiled contracts
@@ -29,3 +32,41 @@ func (w *wrappedPrecompiledContract) Run(accessibleState precompile.PrecompileAc
 func RunStatefulPrecompiledContract(precompile precompile.StatefulPrecompiledContract, accessibleState precompile.PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
 	return precompile.Run(accessibleState, caller, addr, input, suppliedGas, readOnly)
 }
+
+// runStatefulPrecompiledContract runs [precompile] with the specified parameters within the call frame of the
+// invocation, notifying the tracer of the EVM if it is a [PrecompileLogger] and [precompile] is a stateful
+// precompile rather than a wrapped stateless precompile.
+func (evm *EVM) runStatefulPrecompiledContract(precompile precompile.StatefulPrecompiledContract, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if _, stateless := precompile.(*wrappedPrecompiledContract); evm.Config.Debug && !stateless {
+		if tracer, ok := evm.Config.Tracer.(PrecompileLogger); ok {
+			tracer.CaptureEnterPrecompile(caller, addr, input, precompileFunctionName(precompile, input), suppliedGas, evm.depth)
+			defer func() {
+				tracer.CaptureExitPrecompile(ret, suppliedGas-remainingGas, err)
+			}()
+		}
+	}
+	if _, stateless := precompile.(*wrappedPrecompiledContract); evm.Config.TraceContext != nil && !stateless && tracing.Enabled() {
+		_, span := tracing.Start(evm.Config.TraceContext, "precompile",
+			attribute.Stringer("address", addr),
+			attribute.String("function", precompileFunctionName(precompile, input)),
+			attribute.Bool("readOnly", readOnly),
+		)
+		defer func() {
+			span.SetAttributes(attribute.Int64("gasUsed", int64(suppliedGas-remainingGas)))
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+	return RunStatefulPrecompiledContract(precompile, evm, caller, addr, input, suppliedGas, readOnly)
+}
+
+// precompileFunctionName returns the name of the function of [contract] executed for [input], or an empty
+// string if [contract] does not name its functions.
+func precompileFunctionName(contract precompile.StatefulPrecompiledContract, input []byte) string {
+	if namer, ok := contract.(precompile.FunctionNamer); ok {
+		return namer.FunctionName(input)
+	}
+	return ""
+}
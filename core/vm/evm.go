@@ -34,6 +34,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/vmerrs"
@@ -44,7 +45,7 @@ import (
 
 var (
 	_ precompile.PrecompileAccessibleState = &EVM{}
-	_ precompile.BlockContext              = &BlockContext{}
+	_ precompile.BlockContext              = &precompileBlockContext{}
 )
 
 // IsProhibited returns true if [addr] is in the prohibited list of addresses which should
@@ -123,12 +124,51 @@ func (b *BlockContext) Timestamp() *big.Int {
 	return b.Time
 }
 
+// precompileBlockContext exposes a [BlockContext] to stateful precompiles.
+// This wrapper is required since the header fields of [BlockContext] collide
+// with the accessors of [precompile.BlockContext].
+type precompileBlockContext struct {
+	ctx *BlockContext
+}
+
+func (b *precompileBlockContext) Number() *big.Int {
+	return b.ctx.BlockNumber
+}
+
+func (b *precompileBlockContext) Timestamp() *big.Int {
+	return b.ctx.Time
+}
+
+func (b *precompileBlockContext) BaseFee() *big.Int {
+	if b.ctx.BaseFee == nil {
+		return nil
+	}
+	return new(big.Int).Set(b.ctx.BaseFee)
+}
+
+func (b *precompileBlockContext) Coinbase() common.Address {
+	return b.ctx.Coinbase
+}
+
+// ParentHash returns the hash of the parent block, or the empty hash for the genesis block.
+func (b *precompileBlockContext) ParentHash() common.Hash {
+	if b.ctx.GetHash == nil || b.ctx.BlockNumber == nil || b.ctx.BlockNumber.Sign() == 0 {
+		return common.Hash{}
+	}
+	return b.ctx.GetHash(b.ctx.BlockNumber.Uint64() - 1)
+}
+
+func (b *precompileBlockContext) GasLimit() uint64 {
+	return b.ctx.GasLimit
+}
+
 // TxContext provides the EVM with information about a transaction.
 // All fields can change between transactions.
 type TxContext struct {
 	// Message information
-	Origin   common.Address // Provides information for ORIGIN
-	GasPrice *big.Int       // Provides information for GASPRICE
+	Origin     common.Address // Provides information for ORIGIN
+	GasPrice   *big.Int       // Provides information for GASPRICE
+	BlobHashes []common.Hash  // Provides information for BLOBHASH
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -206,6 +246,11 @@ func (evm *EVM) GetSnowContext() *snow.Context {
 	return evm.chainConfig.SnowCtx
 }
 
+// GetChainConfig returns the evm's ChainConfig
+func (evm *EVM) GetChainConfig() precompile.ChainConfig {
+	return evm.chainConfig
+}
+
 // GetStateDB returns the evm's StateDB
 func (evm *EVM) GetStateDB() precompile.StateDB {
 	return evm.StateDB
@@ -213,7 +258,7 @@ func (evm *EVM) GetStateDB() precompile.StateDB {
 
 // GetBlockContext returns the evm's BlockContext
 func (evm *EVM) GetBlockContext() precompile.BlockContext {
-	return &evm.Context
+	return &precompileBlockContext{ctx: &evm.Context}
 }
 
 // Interpreter returns the current interpreter
@@ -221,6 +266,31 @@ func (evm *EVM) Interpreter() *EVMInterpreter {
 	return evm.interpreter
 }
 
+// resolveCode returns the code to execute for [addr], following a single level of
+// EIP-7702 code delegation once set code transactions are enabled.
+func (evm *EVM) resolveCode(addr common.Address) []byte {
+	code := evm.StateDB.GetCode(addr)
+	if !evm.chainRules.IsSetCodeTx {
+		return code
+	}
+	if target, ok := types.ParseDelegation(code); ok {
+		// Note we only follow one level of delegation.
+		return evm.StateDB.GetCode(target)
+	}
+	return code
+}
+
+// resolveCodeHash returns the hash of the code returned by resolveCode.
+func (evm *EVM) resolveCodeHash(addr common.Address) common.Hash {
+	if evm.chainRules.IsSetCodeTx {
+		code := evm.StateDB.GetCode(addr)
+		if target, ok := types.ParseDelegation(code); ok {
+			return evm.StateDB.GetCodeHash(target)
+		}
+	}
+	return evm.StateDB.GetCodeHash(addr)
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -237,6 +307,10 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if value.Sign() != 0 && !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 		return nil, gas, vmerrs.ErrInsufficientBalance
 	}
+	// If the address blocklist is enabled, blocked addresses cannot receive value transfers.
+	if value.Sign() != 0 && evm.chainRules.IsPrecompileEnabled(precompile.AddressBlocklistAddress) && precompile.IsAddressBlocked(evm.StateDB, addr) {
+		return nil, gas, fmt.Errorf("%w: %s", precompile.ErrRecipientAddressBlocked, addr)
+	}
 	snapshot := evm.StateDB.Snapshot()
 	p, isPrecompile := evm.precompile(addr)
 
@@ -275,11 +349,11 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 
 	if isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runStatefulPrecompiledContract(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
-		code := evm.StateDB.GetCode(addr)
+		code := evm.resolveCode(addr)
 		if len(code) == 0 {
 			ret, err = nil, nil // gas is unchanged
 		} else {
@@ -287,7 +361,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 			// If the account has no code, we can abort here
 			// The depth-check is already done, and precompiles handled above
 			contract := NewContract(caller, AccountRef(addrCopy), value, gas)
-			contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), code)
+			contract.SetCallCode(&addrCopy, evm.resolveCodeHash(addrCopy), code)
 			ret, err = evm.interpreter.Run(contract, input, false)
 			gas = contract.Gas
 		}
@@ -312,6 +386,41 @@ func (evm *EVM) CallFromPrecompile(caller common.Address, addr common.Address, i
 	return evm.Call(AccountRef(caller), addr, input, gas, value)
 }
 
+// CallPrecompile executes the stateful precompile enabled at [addr] on behalf of [caller].
+// Implements precompile.PrecompileAccessibleState interface.
+func (evm *EVM) CallPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	// Fail if we're trying to execute above the call depth limit
+	if evm.depth > int(params.CallCreateDepth) {
+		return nil, gas, vmerrs.ErrDepth
+	}
+	p, ok := evm.chainRules.Precompiles[addr]
+	if !ok {
+		return nil, gas, fmt.Errorf("%w: %s", precompile.ErrPrecompileNotEnabled, addr)
+	}
+	// Precompiles are executed without a new interpreter frame, so the depth is increased here
+	// to bound the recursion of precompiles calling each other.
+	evm.depth++
+	defer func() { evm.depth-- }()
+
+	// Handle tracer events for entering and exiting a call frame
+	if evm.Config.Debug {
+		evm.Config.Tracer.CaptureEnter(CALL, caller, addr, input, gas, new(big.Int))
+		defer func(startGas uint64) {
+			evm.Config.Tracer.CaptureExit(ret, startGas-remainingGas, err)
+		}(gas)
+	}
+
+	snapshot := evm.StateDB.Snapshot()
+	ret, remainingGas, err = evm.runStatefulPrecompiledContract(p, caller, addr, input, gas, readOnly || evm.interpreter.readOnly)
+	if err != nil {
+		evm.StateDB.RevertToSnapshot(snapshot)
+		if err != vmerrs.ErrExecutionReverted {
+			remainingGas = 0
+		}
+	}
+	return ret, remainingGas, err
+}
+
 // CallCode executes the contract associated with the addr with the given input
 // as parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -346,13 +455,13 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runStatefulPrecompiledContract(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		addrCopy := addr
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(caller.Address()), value, gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		contract.SetCallCode(&addrCopy, evm.resolveCodeHash(addrCopy), evm.resolveCode(addrCopy))
 		ret, err = evm.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
@@ -387,12 +496,12 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 
 	// It is allowed to call precompiles, even via delegatecall
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
+		ret, gas, err = evm.runStatefulPrecompiledContract(p, caller.Address(), addr, input, gas, evm.interpreter.readOnly)
 	} else {
 		addrCopy := addr
 		// Initialise a new contract and make initialise the delegate values
 		contract := NewContract(caller, AccountRef(caller.Address()), nil, gas).AsDelegate()
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		contract.SetCallCode(&addrCopy, evm.resolveCodeHash(addrCopy), evm.resolveCode(addrCopy))
 		ret, err = evm.interpreter.Run(contract, input, false)
 		gas = contract.Gas
 	}
@@ -436,7 +545,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	}
 
 	if p, isPrecompile := evm.precompile(addr); isPrecompile {
-		ret, gas, err = RunStatefulPrecompiledContract(p, evm, caller.Address(), addr, input, gas, true)
+		ret, gas, err = evm.runStatefulPrecompiledContract(p, caller.Address(), addr, input, gas, true)
 	} else {
 		// At this point, we use a copy of address. If we don't, the go compiler will
 		// leak the 'contract' to the outer scope, and make allocation for 'contract'
@@ -445,7 +554,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		// Initialise a new contract and set the code that is to be used by the EVM.
 		// The contract is a scoped environment for this execution context only.
 		contract := NewContract(caller, AccountRef(addrCopy), new(big.Int), gas)
-		contract.SetCallCode(&addrCopy, evm.StateDB.GetCodeHash(addrCopy), evm.StateDB.GetCode(addrCopy))
+		contract.SetCallCode(&addrCopy, evm.resolveCodeHash(addrCopy), evm.resolveCode(addrCopy))
 		// When an error was returned by the EVM or when setting the creation code
 		// above we revert to the snapshot and consume any gas remaining. Additionally
 		// when we're in Homestead this also counts for code storage gas errors.
@@ -507,7 +616,7 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		return nil, common.Address{}, 0, vmerrs.ErrContractAddressCollision
 	}
 	// If the allow list is enabled, check that [evm.TxContext.Origin] has permission to deploy a contract.
-	if evm.chainRules.IsContractDeployerAllowListEnabled {
+	if evm.chainRules.IsPrecompileEnabled(precompile.ContractDeployerAllowListAddress) {
 		allowListRole := precompile.GetContractDeployerAllowListStatus(evm.StateDB, evm.TxContext.Origin)
 		if !allowListRole.IsEnabled() {
 			return nil, common.Address{}, 0, fmt.Errorf("tx.origin %s is not authorized to deploy a contract", evm.TxContext.Origin)
@@ -4,10 +4,23 @@
 package vm
 
 import (
+	"context"
+	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/tracing"
+	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestIsProhibited(t *testing.T) {
@@ -33,3 +46,203 @@ func TestIsProhibited(t *testing.T) {
 	assert.False(t, IsProhibited(common.HexToAddress("0x0200000000000000000000000000000000000100")))
 	assert.False(t, IsProhibited(common.HexToAddress("0x0300000000000000000000000000000000000100")))
 }
+
+func TestCallPrecompile(t *testing.T) {
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	enabled := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(common.Big0, nil, nil))
+	vmctx := BlockContext{
+		BlockNumber: common.Big0,
+		Time:        common.Big0,
+	}
+	newEVM := func(t *testing.T) *EVM {
+		statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		require.NoError(t, err)
+		precompile.SetTxAllowListStatus(statedb, admin, precompile.AllowListAdmin)
+		return NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+	}
+
+	t.Run("modify allow list", func(t *testing.T) {
+		evm := newEVM(t)
+		remainingGas, err := precompile.CallModifyAllowList(evm, admin, precompile.TxAllowListAddress, enabled, precompile.AllowListEnabled, precompile.ModifyAllowListGasCost+1, false)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), remainingGas)
+		require.Equal(t, precompile.AllowListEnabled, precompile.GetTxAllowListStatus(evm.StateDB, enabled))
+		require.Zero(t, evm.depth)
+
+		role, remainingGas, err := precompile.CallReadAllowList(evm, admin, precompile.TxAllowListAddress, enabled, precompile.ReadAllowListGasCost)
+		require.NoError(t, err)
+		require.Zero(t, remainingGas)
+		require.Equal(t, precompile.AllowListEnabled, role)
+	})
+
+	t.Run("non-admin caller", func(t *testing.T) {
+		evm := newEVM(t)
+		_, err := precompile.CallModifyAllowList(evm, enabled, precompile.TxAllowListAddress, enabled, precompile.AllowListAdmin, precompile.ModifyAllowListGasCost, false)
+		require.ErrorIs(t, err, precompile.ErrCannotModifyAllowList)
+		require.Equal(t, precompile.AllowListNoRole, precompile.GetTxAllowListStatus(evm.StateDB, enabled))
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		evm := newEVM(t)
+		_, err := precompile.CallModifyAllowList(evm, admin, precompile.TxAllowListAddress, enabled, precompile.AllowListEnabled, precompile.ModifyAllowListGasCost, true)
+		require.ErrorIs(t, err, vmerrs.ErrWriteProtection)
+
+		// the read-only flag of the calling context is propagated
+		evm.interpreter.readOnly = true
+		_, err = precompile.CallModifyAllowList(evm, admin, precompile.TxAllowListAddress, enabled, precompile.AllowListEnabled, precompile.ModifyAllowListGasCost, false)
+		require.ErrorIs(t, err, vmerrs.ErrWriteProtection)
+		require.Equal(t, precompile.AllowListNoRole, precompile.GetTxAllowListStatus(evm.StateDB, enabled))
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		evm := newEVM(t)
+		_, remainingGas, err := evm.CallPrecompile(admin, precompile.ContractDeployerAllowListAddress, nil, 100, false)
+		require.ErrorIs(t, err, precompile.ErrPrecompileNotEnabled)
+		require.Equal(t, uint64(100), remainingGas)
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		evm := newEVM(t)
+		evm.depth = int(params.CallCreateDepth) + 1
+		_, _, err := evm.CallPrecompile(admin, precompile.TxAllowListAddress, nil, 100, false)
+		require.ErrorIs(t, err, vmerrs.ErrDepth)
+	})
+}
+
+// precompileTracer records the invocations of stateful precompiles reported to a [PrecompileLogger].
+type precompileTracer struct {
+	functions []string
+	depths    []int
+	gasUsed   []uint64
+}
+
+func (t *precompileTracer) CaptureTxStart(uint64) {}
+func (t *precompileTracer) CaptureTxEnd(uint64)   {}
+func (t *precompileTracer) CaptureStart(*EVM, common.Address, common.Address, bool, []byte, uint64, *big.Int) {
+}
+func (t *precompileTracer) CaptureEnd([]byte, uint64, time.Duration, error) {}
+func (t *precompileTracer) CaptureEnter(OpCode, common.Address, common.Address, []byte, uint64, *big.Int) {
+}
+func (t *precompileTracer) CaptureExit([]byte, uint64, error) {}
+func (t *precompileTracer) CaptureState(uint64, OpCode, uint64, uint64, *ScopeContext, []byte, int, error) {
+}
+func (t *precompileTracer) CaptureFault(uint64, OpCode, uint64, uint64, *ScopeContext, int, error) {}
+
+func (t *precompileTracer) CaptureEnterPrecompile(_ common.Address, _ common.Address, _ []byte, function string, _ uint64, depth int) {
+	t.functions = append(t.functions, function)
+	t.depths = append(t.depths, depth)
+}
+
+func (t *precompileTracer) CaptureExitPrecompile(_ []byte, gasUsed uint64, _ error) {
+	t.gasUsed = append(t.gasUsed, gasUsed)
+}
+
+func TestPrecompileTracing(t *testing.T) {
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	enabled := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(common.Big0, nil, nil))
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	precompile.SetTxAllowListStatus(statedb, admin, precompile.AllowListAdmin)
+	tracer := &precompileTracer{}
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: common.Big0,
+		Time:        common.Big0,
+	}
+	evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{Debug: true, Tracer: tracer})
+
+	input, err := precompile.PackModifyAllowList(enabled, precompile.AllowListEnabled)
+	require.NoError(t, err)
+	_, _, err = evm.Call(AccountRef(admin), precompile.TxAllowListAddress, input, precompile.ModifyAllowListGasCost, new(big.Int))
+	require.NoError(t, err)
+	_, _, err = precompile.CallReadAllowList(evm, admin, precompile.TxAllowListAddress, enabled, precompile.ReadAllowListGasCost)
+	require.NoError(t, err)
+	// stateless precompiles are not reported
+	_, _, err = evm.Call(AccountRef(admin), common.BytesToAddress([]byte{4}), []byte{1}, 100, new(big.Int))
+	require.NoError(t, err)
+	// unknown selectors are reported without a function name
+	_, _, err = evm.Call(AccountRef(admin), precompile.TxAllowListAddress, []byte{1, 2, 3, 4}, 100, new(big.Int))
+	require.Error(t, err)
+
+	require.Equal(t, []string{"setEnabled", "readAllowList", ""}, tracer.functions)
+	require.Equal(t, []int{0, 1, 0}, tracer.depths)
+	require.Equal(t, []uint64{precompile.ModifyAllowListGasCost, precompile.ReadAllowListGasCost, 0}, tracer.gasUsed)
+}
+
+func TestPrecompileSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test"))
+	defer tracing.SetTracer(nil)
+
+	admin := common.HexToAddress("0x0300000000000000000000000000000000000042")
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(common.Big0, nil, nil))
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	vmctx := BlockContext{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: common.Big0,
+		Time:        common.Big0,
+	}
+	ctx, parent := tracing.Start(context.Background(), "parent")
+
+	// Stateful precompile calls are recorded as children of the span of the trace context
+	evm := NewEVM(vmctx, TxContext{}, statedb, &config, Config{TraceContext: ctx})
+	_, _, err = precompile.CallReadAllowList(evm, admin, precompile.TxAllowListAddress, admin, precompile.ReadAllowListGasCost)
+	require.NoError(t, err)
+	_, _, err = evm.Call(AccountRef(admin), common.BytesToAddress([]byte{4}), []byte{1}, 100, new(big.Int))
+	require.NoError(t, err)
+	// Nothing is recorded without a trace context
+	evm = NewEVM(vmctx, TxContext{}, statedb, &config, Config{})
+	_, _, err = precompile.CallReadAllowList(evm, admin, precompile.TxAllowListAddress, admin, precompile.ReadAllowListGasCost)
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	require.Equal(t, "precompile", span.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	require.Contains(t, span.Attributes(), attribute.String("function", "readAllowList"))
+	require.Contains(t, span.Attributes(), attribute.Int64("gasUsed", int64(precompile.ReadAllowListGasCost)))
+}
+
+func TestGetBlockContext(t *testing.T) {
+	parentHash := common.HexToHash("0x01")
+	coinbase := common.HexToAddress("0x02")
+	vmctx := BlockContext{
+		GetHash: func(n uint64) common.Hash {
+			require.Equal(t, uint64(6), n)
+			return parentHash
+		},
+		Coinbase:    coinbase,
+		GasLimit:    8_000_000,
+		BlockNumber: big.NewInt(7),
+		Time:        big.NewInt(100),
+		BaseFee:     big.NewInt(25_000_000_000),
+	}
+	evm := NewEVM(vmctx, TxContext{}, nil, params.TestChainConfig, Config{})
+
+	blockContext := evm.GetBlockContext()
+	require.Equal(t, big.NewInt(7), blockContext.Number())
+	require.Equal(t, big.NewInt(100), blockContext.Timestamp())
+	require.Equal(t, big.NewInt(25_000_000_000), blockContext.BaseFee())
+	require.Equal(t, coinbase, blockContext.Coinbase())
+	require.Equal(t, parentHash, blockContext.ParentHash())
+	require.Equal(t, uint64(8_000_000), blockContext.GasLimit())
+
+	// the base fee cannot be modified through the block context
+	blockContext.BaseFee().SetUint64(0)
+	require.Equal(t, big.NewInt(25_000_000_000), vmctx.BaseFee)
+
+	evm.Context.BlockNumber = common.Big0
+	require.Equal(t, common.Hash{}, blockContext.ParentHash())
+}
@@ -27,9 +27,11 @@
 package vm
 
 import (
+	"fmt"
 	"sync/atomic"
 
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/holiman/uint256"
@@ -832,6 +834,10 @@ func opSelfdestruct(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 	}
 	beneficiary := scope.Stack.pop()
 	balance := interpreter.evm.StateDB.GetBalance(scope.Contract.Address())
+	// If the address blocklist is enabled, a blocked beneficiary cannot receive the remaining balance.
+	if balance.Sign() != 0 && interpreter.evm.chainRules.IsPrecompileEnabled(precompile.AddressBlocklistAddress) && precompile.IsAddressBlocked(interpreter.evm.StateDB, beneficiary.Bytes20()) {
+		return nil, fmt.Errorf("%w: %s", precompile.ErrRecipientAddressBlocked, common.Address(beneficiary.Bytes20()))
+	}
 	interpreter.evm.StateDB.AddBalance(beneficiary.Bytes20(), balance)
 	interpreter.evm.StateDB.Suicide(scope.Contract.Address())
 	if interpreter.cfg.Debug {
@@ -841,6 +847,28 @@ func opSelfdestruct(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext
 	return nil, errStopToken
 }
 
+func opSelfdestruct6780(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.readOnly {
+		return nil, vmerrs.ErrWriteProtection
+	}
+	beneficiary := scope.Stack.pop()
+	balance := interpreter.evm.StateDB.GetBalance(scope.Contract.Address())
+	// If the address blocklist is enabled, a blocked beneficiary cannot receive the remaining balance.
+	if balance.Sign() != 0 && interpreter.evm.chainRules.IsPrecompileEnabled(precompile.AddressBlocklistAddress) && precompile.IsAddressBlocked(interpreter.evm.StateDB, beneficiary.Bytes20()) {
+		return nil, fmt.Errorf("%w: %s", precompile.ErrRecipientAddressBlocked, common.Address(beneficiary.Bytes20()))
+	}
+	// The balance is always moved, but the account is only destroyed if it was
+	// created in the same transaction.
+	interpreter.evm.StateDB.SubBalance(scope.Contract.Address(), balance)
+	interpreter.evm.StateDB.AddBalance(beneficiary.Bytes20(), balance)
+	interpreter.evm.StateDB.Selfdestruct6780(scope.Contract.Address())
+	if interpreter.cfg.Debug {
+		interpreter.cfg.Tracer.CaptureEnter(SELFDESTRUCT, scope.Contract.Address(), beneficiary.Bytes20(), []byte{}, 0, balance)
+		interpreter.cfg.Tracer.CaptureExit([]byte{}, 0, nil)
+	}
+	return nil, errStopToken
+}
+
 // following functions are used by the instruction jump  table
 
 // make log instruction function
@@ -59,6 +59,7 @@ type StateDB interface {
 
 	Suicide(common.Address) bool
 	HasSuicided(common.Address) bool
+	Selfdestruct6780(common.Address)
 	Finalise(deleteEmptyObjects bool)
 
 	// Exist reports whether the given account exists in state.
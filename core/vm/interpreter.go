@@ -27,6 +27,7 @@
 package vm
 
 import (
+	"context"
 	"hash"
 
 	"github.com/ava-labs/subnet-evm/vmerrs"
@@ -48,6 +49,10 @@ type Config struct {
 
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
+
+	// TraceContext is the context of the caller of the EVM, used as the parent of the spans
+	// recording the stateful precompile calls (not recorded if nil)
+	TraceContext context.Context
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -83,6 +88,10 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	if cfg.JumpTable == nil {
 		switch {
+		case evm.chainRules.IsSetCodeTx:
+			cfg.JumpTable = &setCodeTxInstructionSet
+		case evm.chainRules.IsBlobTx:
+			cfg.JumpTable = &blobTxInstructionSet
 		case evm.chainRules.IsSubnetEVM:
 			cfg.JumpTable = &subnetEVMInstructionSet
 		case evm.chainRules.IsIstanbul:
@@ -100,6 +109,12 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 		default:
 			cfg.JumpTable = &frontierInstructionSet
 		}
+		// SELFDESTRUCT semantics are toggled independently of the instruction set
+		if evm.chainRules.IsEIP6780 {
+			copy := *cfg.JumpTable
+			enable6780(&copy)
+			cfg.JumpTable = &copy
+		}
 		for i, eip := range cfg.ExtraEips {
 			copy := *cfg.JumpTable
 			if err := EnableEIP(eip, &copy); err != nil {
@@ -109,6 +124,9 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 			}
 			cfg.JumpTable = &copy
 		}
+		if len(evm.chainRules.OpcodeOverrides) != 0 {
+			cfg.JumpTable = applyOpcodeOverrides(cfg.JumpTable, evm.chainRules.OpcodeOverrides)
+		}
 	}
 
 	return &EVMInterpreter{
@@ -63,11 +63,29 @@ var (
 	constantinopleInstructionSet   = newConstantinopleInstructionSet()
 	istanbulInstructionSet         = newIstanbulInstructionSet()
 	subnetEVMInstructionSet        = newSubnetEVMInstructionSet()
+	blobTxInstructionSet           = newBlobTxInstructionSet()
+	setCodeTxInstructionSet        = newSetCodeTxInstructionSet()
 )
 
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// newSetCodeTxInstructionSet returns the blob transaction instructions, with calls
+// charging for the resolution of the code delegated by EIP-7702 accounts.
+func newSetCodeTxInstructionSet() JumpTable {
+	instructionSet := newBlobTxInstructionSet()
+	enable7702(&instructionSet) // Delegated code resolution https://eips.ethereum.org/EIPS/eip-7702
+	return validate(instructionSet)
+}
+
+// newBlobTxInstructionSet returns the subnet-evm instructions and the
+// BLOBHASH opcode of blob transactions.
+func newBlobTxInstructionSet() JumpTable {
+	instructionSet := newSubnetEVMInstructionSet()
+	enable4844(&instructionSet) // BLOBHASH opcode https://eips.ethereum.org/EIPS/eip-4844
+	return validate(instructionSet)
+}
+
 // newSubnetEVMInstructionSet returns the frontier, homestead, byzantium,
 // contantinople, istanbul, petersburg, subnet-evm instructions.
 func newSubnetEVMInstructionSet() JumpTable {
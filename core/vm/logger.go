@@ -52,3 +52,15 @@ type EVMLogger interface {
 	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error)
 	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
 }
+
+// PrecompileLogger is an optional extension of EVMLogger, notified around every invocation of a
+// stateful precompile. The hooks are called within the call frame of the invocation, after
+// CaptureStart or CaptureEnter and before CaptureEnd or CaptureExit, so that tracers can
+// annotate the frame with the precompile function being called.
+type PrecompileLogger interface {
+	// CaptureEnterPrecompile is called before the precompile at [to] is run with [input] by [from].
+	// [function] is the name of the precompile function selected by [input], or empty if it
+	// cannot be decoded. [depth] is the depth of the call frame of the invocation.
+	CaptureEnterPrecompile(from common.Address, to common.Address, input []byte, function string, gas uint64, depth int)
+	CaptureExitPrecompile(output []byte, gasUsed uint64, err error)
+}
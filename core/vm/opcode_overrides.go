@@ -0,0 +1,27 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"github.com/ava-labs/subnet-evm/params"
+)
+
+// applyOpcodeOverrides returns a copy of [jt] where the opcodes of [overrides] are disabled or repriced.
+// The operations of [jt] are not modified, since they are shared with the globally defined jump tables.
+// Assumes the names of [overrides] are valid opcodes, as enforced by ChainConfig.Verify.
+func applyOpcodeOverrides(jt *JumpTable, overrides map[string]params.OpcodeOverride) *JumpTable {
+	copy := *jt
+	for name, override := range overrides {
+		op := StringToOp(name)
+		switch {
+		case override.Disabled:
+			copy[op] = &operation{execute: opUndefined, maxStack: maxStack(0, 0)}
+		case override.ConstantGas != nil:
+			repriced := *copy[op]
+			repriced.constantGas = *override.ConstantGas
+			copy[op] = &repriced
+		}
+	}
+	return &copy
+}
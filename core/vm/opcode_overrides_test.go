@@ -0,0 +1,89 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpcodeOverrides(t *testing.T) {
+	blockhashGas := uint64(5000)
+	chainConfig := *params.TestChainConfig
+	chainConfig.UpgradeConfig = params.UpgradeConfig{
+		ParameterUpgrades: []params.ParameterUpgrade{
+			{BlockTimestamp: big.NewInt(10), OpcodeOverrides: map[string]params.OpcodeOverride{
+				"SELFDESTRUCT": {Disabled: true},
+				"BLOCKHASH":    {ConstantGas: &blockhashGas},
+			}},
+		},
+	}
+
+	address := common.BytesToAddress([]byte("contract"))
+	tests := map[string]struct {
+		// code executed by the contract
+		code      string
+		timestamp uint64
+		// expected gas used by the call, if it succeeds
+		gasUsed     uint64
+		expectedErr string
+	}{
+		"selfdestruct before override": {
+			code:      "33ff", // CALLER SELFDESTRUCT
+			timestamp: 5,
+			gasUsed:   GasQuickStep + params.SelfdestructGasEIP150 + params.ColdAccountAccessCostEIP2929,
+		},
+		"selfdestruct disabled": {
+			code:        "33ff", // CALLER SELFDESTRUCT
+			timestamp:   10,
+			expectedErr: "invalid opcode: SELFDESTRUCT",
+		},
+		"blockhash before override": {
+			code:      "600040", // PUSH1 0 BLOCKHASH
+			timestamp: 5,
+			gasUsed:   GasFastestStep + GasExtStep,
+		},
+		"blockhash repriced": {
+			code:      "600040", // PUSH1 0 BLOCKHASH
+			timestamp: 10,
+			gasUsed:   GasFastestStep + blockhashGas,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			require.NoError(t, err)
+			statedb.CreateAccount(address)
+			statedb.SetCode(address, common.Hex2Bytes(test.code))
+			statedb.Finalise(true)
+
+			vmctx := BlockContext{
+				CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+				Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+				GetHash:     func(uint64) common.Hash { return common.Hash{} },
+				BlockNumber: common.Big1,
+				Time:        new(big.Int).SetUint64(test.timestamp),
+			}
+			evm := NewEVM(vmctx, TxContext{}, statedb, &chainConfig, Config{})
+			suppliedGas := uint64(100_000)
+			_, leftOverGas, err := evm.Call(AccountRef(common.Address{}), address, nil, suppliedGas, new(big.Int))
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.gasUsed, suppliedGas-leftOverGas)
+		})
+	}
+
+	// The shared jump table is not modified by the overrides.
+	require.Equal(t, GasExtStep, subnetEVMInstructionSet[BLOCKHASH].constantGas)
+	require.NotNil(t, subnetEVMInstructionSet[SELFDESTRUCT].dynamicGas)
+}
@@ -57,6 +57,7 @@ type EthAPIBackend struct {
 	extRPCEnabled            bool
 	allowUnprotectedTxs      bool
 	allowUnprotectedTxHashes map[common.Hash]struct{} // Invariant: read-only after creation.
+	disabledPrecompileAPIs   map[string]struct{}      // Invariant: read-only after creation.
 	eth                      *Ethereum
 	gpo                      *gasprice.Oracle
 }
@@ -219,7 +220,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(ctx, header.Root)
 	return stateDb, header, err
 }
 
@@ -238,12 +239,39 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if header == nil {
 			return nil, nil, errors.New("header for hash not found")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(ctx, header.Root)
 		return stateDb, header, err
 	}
 	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
 }
 
+// batchStateKey is the key of the state of a block root in the cache of an RPC batch.
+type batchStateKey common.Hash
+
+// stateAt returns the state at [root]. The calls of a batch of RPC requests (e.g. a burst of
+// eth_call or eth_getBalance from an indexer) share the state opened for each root, and each
+// call is given its own copy so that it can be modified freely.
+func (b *EthAPIBackend) stateAt(ctx context.Context, root common.Hash) (*state.StateDB, error) {
+	batchCache := rpc.BatchCacheFromContext(ctx)
+	if batchCache == nil {
+		stateDb, err := b.eth.BlockChain().StateAt(root)
+		if err != nil {
+			return nil, err
+		}
+		rpc.CallStatsFromContext(ctx).TrackState(stateDb)
+		return stateDb, nil
+	}
+	stateDb, err := batchCache.GetOrCreate(batchStateKey(root), func() (interface{}, error) {
+		return b.eth.BlockChain().StateAt(root)
+	})
+	if err != nil {
+		return nil, err
+	}
+	callState := stateDb.(*state.StateDB).Copy()
+	rpc.CallStatsFromContext(ctx).TrackState(callState)
+	return callState, nil
+}
+
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -427,6 +455,13 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+// PrecompileAPIEnabled returns false if the RPC helpers of the precompile with [configKey]
+// are disabled on this node.
+func (b *EthAPIBackend) PrecompileAPIEnabled(configKey string) bool {
+	_, disabled := b.disabledPrecompileAPIs[configKey]
+	return !disabled
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -451,11 +486,21 @@ func (b *EthAPIBackend) GetMaxBlocksPerRequest() int64 {
 }
 
 func (b *EthAPIBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, checkLive bool, preferDisk bool) (*state.StateDB, error) {
-	return b.eth.StateAtBlock(block, reexec, base, checkLive, preferDisk)
+	stateDb, err := b.eth.StateAtBlock(block, reexec, base, checkLive, preferDisk)
+	if err != nil {
+		return nil, err
+	}
+	rpc.CallStatsFromContext(ctx).TrackState(stateDb)
+	return stateDb, nil
 }
 
 func (b *EthAPIBackend) StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error) {
-	return b.eth.stateAtTransaction(block, txIndex, reexec)
+	msg, blockCtx, stateDb, err := b.eth.stateAtTransaction(block, txIndex, reexec)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, err
+	}
+	rpc.CallStatsFromContext(ctx).TrackState(stateDb)
+	return msg, blockCtx, stateDb, nil
 }
 
 func (b *EthAPIBackend) MinRequiredTip(ctx context.Context, header *types.Header) (*big.Int, error) {
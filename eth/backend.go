@@ -138,6 +138,10 @@ func New(
 		"snapshot clean", common.StorageSize(config.SnapshotCache)*1024*1024,
 	)
 
+	// The genesis state is written with the state scheme of the database
+	if err := core.SetupStateScheme(chainDb, config.StateScheme); err != nil {
+		return nil, err
+	}
 	chainConfig, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis, lastAcceptedHash, config.SkipUpgradeCheck)
 	if genesisErr != nil {
 		return nil, genesisErr
@@ -202,6 +206,7 @@ func New(
 			TrieCleanRejournal:              config.TrieCleanRejournal,
 			TrieDirtyLimit:                  config.TrieDirtyCache,
 			TrieDirtyCommitTarget:           config.TrieDirtyCommitTarget,
+			TriePrefetcherParallelism:       config.TriePrefetcherParallelism,
 			Pruning:                         config.Pruning,
 			AcceptorQueueLimit:              config.AcceptorQueueLimit,
 			CommitInterval:                  config.CommitInterval,
@@ -215,6 +220,18 @@ func New(
 			SkipSnapshotRebuild:             config.SkipSnapshotRebuild,
 			Preimages:                       config.Preimages,
 			AcceptedCacheSize:               config.AcceptedCacheSize,
+			OnlinePruning:                   config.OnlinePruning,
+			OnlinePruningRetention:          config.OnlinePruningRetention,
+			OnlinePruningBloomFilterSize:    config.OnlinePruningBloomFilterSize,
+			AncientStoreThreshold:           config.AncientStoreThreshold,
+			StateScheme:                     config.StateScheme,
+			StateExportInterval:             config.StateExportInterval,
+			StateExportDirectory:            config.StateExportDirectory,
+			StateDiffArchive:                config.StateDiffArchive,
+			StateDiffRetention:              config.StateDiffRetention,
+			TxLookupLimit:                   config.TxLookupLimit,
+			HistoryRetention:                config.HistoryRetention,
+			BlockPrefetch:                   config.BlockPrefetch,
 		}
 	)
 
@@ -244,15 +261,24 @@ func New(
 		allowUnprotectedTxHashes[txHash] = struct{}{}
 	}
 
+	disabledPrecompileAPIs := make(map[string]struct{})
+	for _, key := range config.DisabledPrecompileAPIs {
+		disabledPrecompileAPIs[key] = struct{}{}
+	}
+
 	eth.APIBackend = &EthAPIBackend{
 		extRPCEnabled:            stack.Config().ExtRPCEnabled(),
 		allowUnprotectedTxs:      config.AllowUnprotectedTxs,
 		allowUnprotectedTxHashes: allowUnprotectedTxHashes,
+		disabledPrecompileAPIs:   disabledPrecompileAPIs,
 		eth:                      eth,
 	}
 	if config.AllowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
+	if len(config.DisabledPrecompileAPIs) > 0 {
+		log.Info("Precompile APIs disabled", "precompiles", config.DisabledPrecompileAPIs)
+	}
 	gpoParams := config.GPO
 	eth.APIBackend.gpo, err = gasprice.NewOracle(eth.APIBackend, gpoParams)
 	if err != nil {
@@ -286,6 +312,8 @@ func (s *Ethereum) APIs() []rpc.API {
 		Timeout: 5 * time.Minute,
 	})
 
+	filterAPI := filters.NewFilterAPI(filterSystem, false /* isLightClient */)
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -294,8 +322,24 @@ func (s *Ethereum) APIs() []rpc.API {
 			Name:      "eth",
 		}, {
 			Namespace: "eth",
-			Service:   filters.NewFilterAPI(filterSystem, false /* isLightClient */),
+			Service:   filterAPI,
 			Name:      "eth-filter",
+		}, {
+			Namespace: "subnetevm",
+			Service:   filters.NewPrecompileEventsAPI(filterAPI),
+			Name:      "subnetevm-filter",
+		}, {
+			Namespace: "subnetevm",
+			Service:   NewPendingBlockAPI(s),
+			Name:      "subnetevm-pending",
+		}, {
+			Namespace: "subnetevm",
+			Service:   NewStateDiffAPI(s),
+			Name:      "subnetevm-statediff",
+		}, {
+			Namespace: "subnetevm",
+			Service:   NewFeeMarketAPI(s),
+			Name:      "subnetevm-feemarket",
 		}, {
 			Namespace: "admin",
 			Service:   NewAdminAPI(s),
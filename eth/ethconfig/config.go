@@ -88,6 +88,7 @@ type Config struct {
 	SnapshotAsync                   bool    // Whether to generate the initial snapshot in async mode
 	SnapshotVerify                  bool    // Whether to verify generated snapshots
 	SkipSnapshotRebuild             bool    // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
+	BlockPrefetch                   bool    // Whether to execute blocks in the background to warm up the caches before processing them
 
 	// Database options
 	SkipBcVersionCheck bool `toml:"-"`
@@ -101,6 +102,10 @@ type Config struct {
 	SnapshotCache         int
 	Preimages             bool
 
+	// TriePrefetcherParallelism is the maximum number of tries loaded concurrently
+	// by the trie prefetcher while blocks are processed (unbounded if 0).
+	TriePrefetcherParallelism int
+
 	// AcceptedCacheSize is the depth of accepted headers cache and accepted
 	// logs cache at the accepted tip.
 	AcceptedCacheSize int
@@ -130,6 +135,9 @@ type Config struct {
 	// AllowUnfinalizedQueries allow unfinalized queries
 	AllowUnfinalizedQueries bool
 
+	// DisabledPrecompileAPIs is a list of config keys of precompiles whose RPC helpers are not served.
+	DisabledPrecompileAPIs []string
+
 	// AllowUnprotectedTxs allow unprotected transactions to be locally issued.
 	// Unprotected transactions are transactions that are signed without EIP-155
 	// replay protection.
@@ -144,6 +152,39 @@ type Config struct {
 	OfflinePruningBloomFilterSize uint64
 	OfflinePruningDataDirectory   string
 
+	// OnlinePruning enables pruning historical state in the background while the node
+	// is running. The committed state of the last [OnlinePruningRetention] blocks is kept.
+	OnlinePruning                bool
+	OnlinePruningRetention       uint64
+	OnlinePruningBloomFilterSize uint64
+
+	// AncientStoreThreshold is the number of recent accepted blocks kept in the key-value
+	// store if the chain database has an ancient store. Older blocks are moved into it.
+	AncientStoreThreshold uint64
+
+	// StateScheme is the scheme used to store the state trie nodes on disk, either
+	// rawdb.HashScheme (the default if empty) or rawdb.PathScheme. It cannot be changed
+	// once the genesis state has been written.
+	StateScheme string
+
+	// StateExportInterval is the number of accepted blocks between the exports of the
+	// state written to StateExportDirectory in the background (disabled if 0).
+	StateExportInterval  uint64
+	StateExportDirectory string
+
+	// StateDiffArchive enables storing the accounts and storage slots modified by each
+	// block, of which only the last StateDiffRetention accepted blocks are kept (all if 0).
+	StateDiffArchive   bool
+	StateDiffRetention uint64
+
+	// TxLookupLimit is the number of recent accepted blocks whose transactions are
+	// indexed for lookups by hash (all if 0).
+	TxLookupLimit uint64
+
+	// HistoryRetention is the number of recent accepted blocks whose bodies and
+	// receipts are kept (all if 0). The headers of all blocks are kept.
+	HistoryRetention uint64
+
 	// SkipUpgradeCheck disables checking that upgrades must take place before the last
 	// accepted block. Skipping this check is useful when a node operator does not update
 	// their node before the network upgrade and their node accepts blocks that have
@@ -0,0 +1,284 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxFeeMarketBlocks is the maximum number of blocks the fee market statistics are computed over.
+const maxFeeMarketBlocks = 1024
+
+var (
+	errInvalidFeeMarketBlockCount = errors.New("block count must be positive")
+
+	// defaultFeeMarketPercentiles are the base fee percentiles returned if none are requested.
+	defaultFeeMarketPercentiles = []float64{10, 50, 90}
+
+	// feeMarketPriceBands are the bounds of the price bands the mempool is split into, as
+	// multiples of the next base fee. The first band holds the transactions that cannot pay
+	// the next base fee and the last band has no upper bound.
+	feeMarketPriceBands = []float64{1, 1.1, 1.5, 2, 5}
+)
+
+// FeeMarketAPI offers statistics on the fee market, so that operators can tune the fee config
+// set by the FeeConfigManager.
+type FeeMarketAPI struct {
+	eth *Ethereum
+}
+
+// NewFeeMarketAPI creates a new FeeMarketAPI.
+func NewFeeMarketAPI(eth *Ethereum) *FeeMarketAPI {
+	return &FeeMarketAPI{eth: eth}
+}
+
+// FeeMarketStats are the statistics of the fee market over a range of accepted blocks.
+type FeeMarketStats struct {
+	OldestBlock hexutil.Uint64 `json:"oldestBlock"`
+	LatestBlock hexutil.Uint64 `json:"latestBlock"`
+	// FeeConfig is the fee config in effect after the latest block.
+	FeeConfig   commontype.FeeConfig `json:"feeConfig"`
+	NextBaseFee *hexutil.Big         `json:"nextBaseFee,omitempty"`
+	// BaseFeePercentiles are the base fees at the requested percentiles of the range.
+	BaseFeePercentiles []*hexutil.Big `json:"baseFeePercentiles"`
+	// GasUsedRatio is the ratio of the gas used to the gas limit over the range.
+	GasUsedRatio float64 `json:"gasUsedRatio"`
+	// GasUtilization is the ratio of the gas consumed over the rollup window ending at the
+	// latest block to the target gas, and AverageGasUtilization its average over the range.
+	// The base fee increases while the utilization is above 1 and decreases below 1.
+	GasUtilization        float64            `json:"gasUtilization"`
+	AverageGasUtilization float64            `json:"averageGasUtilization"`
+	BlockGasCost          *BlockGasCostStats `json:"blockGasCost"`
+	// Mempool is the number of transactions in the mempool by price band.
+	Mempool []*PriceBand `json:"mempool,omitempty"`
+}
+
+// BlockGasCostStats are the statistics of the block gas cost over a range of blocks.
+type BlockGasCostStats struct {
+	Latest  *hexutil.Big `json:"latest"`
+	Min     *hexutil.Big `json:"min"`
+	Max     *hexutil.Big `json:"max"`
+	Average *hexutil.Big `json:"average"`
+	// Change is the difference between the average block gas cost of the second and the
+	// first half of the range.
+	Change *hexutil.Big `json:"change"`
+}
+
+// PriceBand is the number of transactions in the mempool with a gas fee cap in
+// [MinGasFeeCap, MaxGasFeeCap). MaxGasFeeCap is null for the last band.
+type PriceBand struct {
+	MinGasFeeCap *hexutil.Big `json:"minGasFeeCap"`
+	MaxGasFeeCap *hexutil.Big `json:"maxGasFeeCap"`
+	Pending      hexutil.Uint `json:"pending"`
+	Queued       hexutil.Uint `json:"queued"`
+}
+
+// FeeMarket returns the statistics of the fee market over the last [blockCount] accepted blocks
+// (at most [maxFeeMarketBlocks]), with the base fees at [percentiles] (10, 50 and 90 if not set),
+// along with the depth of the mempool by price band relative to the next base fee.
+func (api *FeeMarketAPI) FeeMarket(ctx context.Context, blockCount rpc.DecimalOrHex, percentiles []float64) (*FeeMarketStats, error) {
+	if blockCount == 0 {
+		return nil, errInvalidFeeMarketBlockCount
+	}
+	if len(percentiles) == 0 {
+		percentiles = defaultFeeMarketPercentiles
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile: %f", p)
+		}
+		if i > 0 && p < percentiles[i-1] {
+			return nil, fmt.Errorf("invalid percentile: #%d:%f > #%d:%f", i-1, percentiles[i-1], i, p)
+		}
+	}
+
+	var (
+		chain  = api.eth.BlockChain()
+		config = chain.Config()
+		latest = chain.LastAcceptedBlock().Header()
+		count  = uint64(blockCount)
+	)
+	if count > maxFeeMarketBlocks {
+		count = maxFeeMarketBlocks
+	}
+	if count > latest.Number.Uint64()+1 {
+		count = latest.Number.Uint64() + 1
+	}
+	headers := make([]*types.Header, count)
+	utilizations := make([]float64, count)
+	for i := range headers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		number := latest.Number.Uint64() - count + 1 + uint64(i)
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("header %d not found", number)
+		}
+		feeConfig, _, err := chain.GetFeeConfigAt(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fee config after block %d: %w", number, err)
+		}
+		if utilizations[i], err = dummy.CalcGasUtilization(config, feeConfig, header); err != nil {
+			return nil, fmt.Errorf("failed to calculate gas utilization of block %d: %w", number, err)
+		}
+		headers[i] = header
+	}
+
+	stats := newFeeMarketStats(headers, percentiles, utilizations)
+	feeConfig, _, err := chain.GetFeeConfigAt(latest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee config after block %d: %w", latest.Number.Uint64(), err)
+	}
+	stats.FeeConfig = feeConfig
+
+	timestamp := uint64(time.Now().Unix())
+	if !config.IsSubnetEVM(new(big.Int).SetUint64(timestamp)) {
+		return stats, nil
+	}
+	_, nextBaseFee, err := dummy.EstimateNextBaseFee(config, feeConfig, latest, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate the next base fee: %w", err)
+	}
+	stats.NextBaseFee = (*hexutil.Big)(nextBaseFee)
+	pending, queued := api.eth.TxPool().Content()
+	stats.Mempool = newPriceBands(nextBaseFee, pending, queued)
+	return stats, nil
+}
+
+// newFeeMarketStats returns the statistics of the block range [headers], sorted by number, with
+// the base fees at [percentiles] and the gas utilization of each block in [utilizations].
+func newFeeMarketStats(headers []*types.Header, percentiles []float64, utilizations []float64) *FeeMarketStats {
+	var (
+		latest   = headers[len(headers)-1]
+		baseFees = make([]*big.Int, 0, len(headers))
+		costs    = make([]*big.Int, len(headers))
+		gasUsed  uint64
+		gasLimit uint64
+	)
+	stats := &FeeMarketStats{
+		OldestBlock:        hexutil.Uint64(headers[0].Number.Uint64()),
+		LatestBlock:        hexutil.Uint64(latest.Number.Uint64()),
+		BaseFeePercentiles: make([]*hexutil.Big, len(percentiles)),
+		GasUtilization:     utilizations[len(utilizations)-1],
+	}
+	for i, header := range headers {
+		if header.BaseFee != nil {
+			baseFees = append(baseFees, header.BaseFee)
+		}
+		costs[i] = new(big.Int)
+		if header.BlockGasCost != nil {
+			costs[i].Set(header.BlockGasCost)
+		}
+		gasUsed += header.GasUsed
+		gasLimit += header.GasLimit
+		stats.AverageGasUtilization += utilizations[i]
+	}
+	stats.AverageGasUtilization /= float64(len(utilizations))
+	if gasLimit != 0 {
+		stats.GasUsedRatio = float64(gasUsed) / float64(gasLimit)
+	}
+
+	sort.Slice(baseFees, func(i, j int) bool { return baseFees[i].Cmp(baseFees[j]) < 0 })
+	for i, p := range percentiles {
+		if len(baseFees) == 0 {
+			break
+		}
+		// Nearest-rank percentile of the sorted base fees
+		rank := int(math.Ceil(p / 100 * float64(len(baseFees))))
+		if rank > 0 {
+			rank--
+		}
+		stats.BaseFeePercentiles[i] = (*hexutil.Big)(baseFees[rank])
+	}
+
+	stats.BlockGasCost = newBlockGasCostStats(costs)
+	return stats
+}
+
+// newBlockGasCostStats returns the statistics of the block gas costs [costs], sorted by block.
+func newBlockGasCostStats(costs []*big.Int) *BlockGasCostStats {
+	var (
+		min   = costs[0]
+		max   = costs[0]
+		total = new(big.Int)
+		first = new(big.Int)
+		half  = len(costs) / 2
+	)
+	for i, cost := range costs {
+		if cost.Cmp(min) < 0 {
+			min = cost
+		}
+		if cost.Cmp(max) > 0 {
+			max = cost
+		}
+		if i == half {
+			first.Set(total)
+		}
+		total.Add(total, cost)
+	}
+	stats := &BlockGasCostStats{
+		Latest:  (*hexutil.Big)(costs[len(costs)-1]),
+		Min:     (*hexutil.Big)(min),
+		Max:     (*hexutil.Big)(max),
+		Average: (*hexutil.Big)(new(big.Int).Div(total, big.NewInt(int64(len(costs))))),
+		Change:  new(hexutil.Big),
+	}
+	if half > 0 {
+		second := new(big.Int).Sub(total, first)
+		second.Div(second, big.NewInt(int64(len(costs)-half)))
+		first.Div(first, big.NewInt(int64(half)))
+		stats.Change = (*hexutil.Big)(second.Sub(second, first))
+	}
+	return stats
+}
+
+// newPriceBands returns the number of [pending] and [queued] transactions in each of the
+// [feeMarketPriceBands] relative to [baseFee].
+func newPriceBands(baseFee *big.Int, pending, queued map[common.Address]types.Transactions) []*PriceBand {
+	bands := make([]*PriceBand, len(feeMarketPriceBands)+1)
+	bounds := make([]*big.Int, len(feeMarketPriceBands))
+	for i, multiple := range feeMarketPriceBands {
+		bound, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), big.NewFloat(multiple)).Int(nil)
+		bounds[i] = bound
+	}
+	for i := range bands {
+		bands[i] = &PriceBand{MinGasFeeCap: new(hexutil.Big)}
+		if i > 0 {
+			bands[i].MinGasFeeCap = (*hexutil.Big)(bounds[i-1])
+		}
+		if i < len(bounds) {
+			bands[i].MaxGasFeeCap = (*hexutil.Big)(bounds[i])
+		}
+	}
+	// band returns the band of the transactions paying [gasFeeCap]
+	band := func(gasFeeCap *big.Int) *PriceBand {
+		i := sort.Search(len(bounds), func(i int) bool { return gasFeeCap.Cmp(bounds[i]) < 0 })
+		return bands[i]
+	}
+	for _, txs := range pending {
+		for _, tx := range txs {
+			band(tx.GasFeeCap()).Pending++
+		}
+	}
+	for _, txs := range queued {
+		for _, tx := range txs {
+			band(tx.GasFeeCap()).Queued++
+		}
+	}
+	return bands
+}
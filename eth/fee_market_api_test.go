@@ -0,0 +1,86 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFeeMarketStats(t *testing.T) {
+	var headers []*types.Header
+	for i, baseFee := range []int64{50, 10, 40, 20, 30} {
+		headers = append(headers, &types.Header{
+			Number:       big.NewInt(int64(i + 1)),
+			GasLimit:     1000,
+			GasUsed:      uint64(100 * i),
+			BaseFee:      big.NewInt(baseFee),
+			BlockGasCost: big.NewInt(int64(i * 100)),
+		})
+	}
+
+	stats := newFeeMarketStats(headers, []float64{0, 50, 100}, []float64{0.5, 1, 1.5, 1, 1.5})
+	require.EqualValues(t, 1, stats.OldestBlock)
+	require.EqualValues(t, 5, stats.LatestBlock)
+	require.Equal(t, []*hexutil.Big{(*hexutil.Big)(big.NewInt(10)), (*hexutil.Big)(big.NewInt(30)), (*hexutil.Big)(big.NewInt(50))}, stats.BaseFeePercentiles)
+	require.Equal(t, 0.2, stats.GasUsedRatio)
+	require.Equal(t, 1.5, stats.GasUtilization)
+	require.Equal(t, 1.1, stats.AverageGasUtilization)
+
+	require.Equal(t, big.NewInt(400), stats.BlockGasCost.Latest.ToInt())
+	require.Equal(t, big.NewInt(0), stats.BlockGasCost.Min.ToInt())
+	require.Equal(t, big.NewInt(400), stats.BlockGasCost.Max.ToInt())
+	require.Equal(t, big.NewInt(200), stats.BlockGasCost.Average.ToInt())
+	// The first half averages 50 and the second half 300
+	require.Equal(t, big.NewInt(250), stats.BlockGasCost.Change.ToInt())
+
+	// A single block has no trend
+	stats = newFeeMarketStats(headers[4:], []float64{50}, []float64{1})
+	require.Equal(t, big.NewInt(30), stats.BaseFeePercentiles[0].ToInt())
+	require.Zero(t, stats.BlockGasCost.Change.ToInt().Sign())
+}
+
+func TestNewPriceBands(t *testing.T) {
+	newTx := func(nonce uint64, gasFeeCap int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{Nonce: nonce, GasFeeCap: big.NewInt(gasFeeCap), GasTipCap: big.NewInt(0), Gas: 21000})
+	}
+	var (
+		pending = map[common.Address]types.Transactions{
+			{1}: {newTx(0, 50), newTx(1, 100), newTx(2, 109)},
+			{2}: {newTx(0, 150), newTx(1, 1000)},
+		}
+		queued = map[common.Address]types.Transactions{
+			{3}: {newTx(5, 99), newTx(6, 499)},
+		}
+	)
+
+	bands := newPriceBands(big.NewInt(100), pending, queued)
+	require.Len(t, bands, len(feeMarketPriceBands)+1)
+	expected := []struct {
+		min, max        int64
+		pending, queued uint
+	}{
+		{0, 100, 1, 1},
+		{100, 110, 2, 0},
+		{110, 150, 0, 0},
+		{150, 200, 1, 0},
+		{200, 500, 0, 1},
+		{500, -1, 1, 0},
+	}
+	for i, band := range bands {
+		require.Equal(t, big.NewInt(expected[i].min), band.MinGasFeeCap.ToInt(), "band %d", i)
+		if expected[i].max < 0 {
+			require.Nil(t, band.MaxGasFeeCap, "band %d", i)
+		} else {
+			require.Equal(t, big.NewInt(expected[i].max), band.MaxGasFeeCap.ToInt(), "band %d", i)
+		}
+		require.EqualValues(t, expected[i].pending, band.Pending, "band %d", i)
+		require.EqualValues(t, expected[i].queued, band.Queued, "band %d", i)
+	}
+}
@@ -297,20 +297,10 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
-		logsSub     event.Subscription
-		err         error
 	)
-
-	if api.sys.backend.GetVMConfig().AllowUnfinalizedQueries {
-		logsSub, err = api.events.SubscribeLogs(interfaces.FilterQuery(crit), matchedLogs)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		logsSub, err = api.events.SubscribeAcceptedLogs(interfaces.FilterQuery(crit), matchedLogs)
-		if err != nil {
-			return nil, err
-		}
+	logsSub, err := api.subscribeLogs(crit, matchedLogs)
+	if err != nil {
+		return nil, err
 	}
 
 	go func() {
@@ -334,6 +324,15 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	return rpcSub, nil
 }
 
+// subscribeLogs subscribes to the logs matching [crit], including the logs of unfinalized blocks
+// only if the node allows unfinalized queries.
+func (api *FilterAPI) subscribeLogs(crit FilterCriteria, logs chan []*types.Log) (event.Subscription, error) {
+	if api.sys.backend.GetVMConfig().AllowUnfinalizedQueries {
+		return api.events.SubscribeLogs(interfaces.FilterQuery(crit), logs)
+	}
+	return api.events.SubscribeAcceptedLogs(interfaces.FilterQuery(crit), logs)
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as interfaces.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria interfaces.FilterQuery
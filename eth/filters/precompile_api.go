@@ -0,0 +1,85 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package filters
+
+import (
+	"context"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PrecompileEventsAPI offers subscriptions to the decoded events of the stateful precompiles.
+type PrecompileEventsAPI struct {
+	filters *FilterAPI
+}
+
+// NewPrecompileEventsAPI returns a new PrecompileEventsAPI using the event system of [filters].
+func NewPrecompileEventsAPI(filters *FilterAPI) *PrecompileEventsAPI {
+	return &PrecompileEventsAPI{filters: filters}
+}
+
+// PrecompileEvent is the notification of an event emitted by a stateful precompile, such as a change of
+// the fee config, of an allow list role or of the native coin supply.
+type PrecompileEvent struct {
+	*types.Log
+	// Event is the name of the event.
+	Event string `json:"event"`
+	// Args are the decoded arguments of the event keyed by name.
+	Args map[string]interface{} `json:"args"`
+}
+
+// PrecompileEvents creates a subscription that fires for each event emitted by the precompile at [address],
+// or by any registered precompile if [address] is nil.
+func (api *PrecompileEventsAPI) PrecompileEvents(ctx context.Context, address *common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var crit FilterCriteria
+	if address != nil {
+		crit.Addresses = []common.Address{*address}
+	} else {
+		for _, module := range precompile.RegisteredModules() {
+			crit.Addresses = append(crit.Addresses, module.Address)
+		}
+	}
+
+	var (
+		rpcSub      = notifier.CreateSubscription()
+		matchedLogs = make(chan []*types.Log)
+	)
+	logsSub, err := api.filters.subscribeLogs(crit, matchedLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case logs := <-matchedLogs:
+				for _, l := range logs {
+					name, args, err := precompile.UnpackPrecompileEvent(l.Topics, l.Data)
+					if err != nil {
+						log.Debug("Skipping undecodable precompile log", "address", l.Address, "txHash", l.TxHash, "err", err)
+						continue
+					}
+					notifier.Notify(rpcSub.ID, &PrecompileEvent{Log: l, Event: name, Args: args})
+				}
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				logsSub.Unsubscribe()
+				return
+			case <-notifier.Closed(): // connection dropped
+				logsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,153 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PendingBlockAPI offers a subscription to the blocks built by the miner of the node
+// before they are issued to consensus and accepted.
+type PendingBlockAPI struct {
+	eth *Ethereum
+}
+
+// NewPendingBlockAPI creates a new PendingBlockAPI.
+func NewPendingBlockAPI(eth *Ethereum) *PendingBlockAPI {
+	return &PendingBlockAPI{eth: eth}
+}
+
+// PendingBlock is the notification of a block built by the miner. The block is speculative:
+// it may be rejected by consensus or replaced by a block built by another validator.
+type PendingBlock struct {
+	Number     hexutil.Uint64 `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Timestamp  hexutil.Uint64 `json:"timestamp"`
+	GasLimit   hexutil.Uint64 `json:"gasLimit"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	BaseFee    *hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	// EstimatedBaseFee is the base fee of a block built on top of the pending block at the
+	// time of the notification.
+	EstimatedBaseFee *hexutil.Big   `json:"estimatedBaseFee,omitempty"`
+	Transactions     []common.Hash  `json:"transactions"`
+	Inclusions       []*TxInclusion `json:"inclusions"`
+}
+
+// TxInclusion is the inclusion status of a transaction in a pending block. The position and
+// execution result of the transaction are only set if it is included.
+type TxInclusion struct {
+	TxHash   common.Hash     `json:"transactionHash"`
+	Included bool            `json:"included"`
+	Index    *hexutil.Uint64 `json:"transactionIndex,omitempty"`
+	Status   *hexutil.Uint64 `json:"status,omitempty"`
+	GasUsed  *hexutil.Uint64 `json:"gasUsed,omitempty"`
+}
+
+// PendingBlocks creates a subscription that fires for each block built by the miner of the node,
+// reporting whether each of [txHashes] is included in it.
+func (api *PendingBlockAPI) PendingBlocks(ctx context.Context, txHashes []common.Hash) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub  = notifier.CreateSubscription()
+		pending = make(chan core.PendingBlockEvent)
+	)
+	pendingSub := api.eth.Miner().SubscribePendingBlock(pending)
+
+	go func() {
+		defer pendingSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-pending:
+				notifier.Notify(rpcSub.ID, newPendingBlock(ev, txHashes, api.estimateNextBaseFee(ev.Block.Header())))
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// estimateNextBaseFee returns the base fee of a block built on top of [pending] now, or nil if
+// it cannot be estimated. The fee config is read from the parent of [pending], since the state
+// of the pending block is not committed.
+func (api *PendingBlockAPI) estimateNextBaseFee(pending *types.Header) *big.Int {
+	chain := api.eth.BlockChain()
+	config := chain.Config()
+	timestamp := uint64(time.Now().Unix())
+	if timestamp < pending.Time {
+		timestamp = pending.Time
+	}
+	if !config.IsSubnetEVM(new(big.Int).SetUint64(timestamp)) {
+		return nil
+	}
+	parent := chain.GetHeaderByHash(pending.ParentHash)
+	if parent == nil {
+		return nil
+	}
+	feeConfig, _, err := chain.GetFeeConfigAt(parent)
+	if err != nil {
+		log.Debug("Failed to get fee config of pending block", "hash", pending.Hash(), "err", err)
+		return nil
+	}
+	_, baseFee, err := dummy.EstimateNextBaseFee(config, feeConfig, pending, timestamp)
+	if err != nil {
+		log.Debug("Failed to estimate base fee after pending block", "hash", pending.Hash(), "err", err)
+		return nil
+	}
+	return baseFee
+}
+
+// newPendingBlock returns the notification of the pending block of [ev], reporting the inclusion
+// of [txHashes] in it.
+func newPendingBlock(ev core.PendingBlockEvent, txHashes []common.Hash, estimatedBaseFee *big.Int) *PendingBlock {
+	block := ev.Block
+	pending := &PendingBlock{
+		Number:           hexutil.Uint64(block.NumberU64()),
+		Hash:             block.Hash(),
+		ParentHash:       block.ParentHash(),
+		Timestamp:        hexutil.Uint64(block.Time()),
+		GasLimit:         hexutil.Uint64(block.GasLimit()),
+		GasUsed:          hexutil.Uint64(block.GasUsed()),
+		BaseFee:          (*hexutil.Big)(block.BaseFee()),
+		EstimatedBaseFee: (*hexutil.Big)(estimatedBaseFee),
+		Transactions:     make([]common.Hash, len(block.Transactions())),
+		Inclusions:       make([]*TxInclusion, len(txHashes)),
+	}
+	indices := make(map[common.Hash]int, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		pending.Transactions[i] = tx.Hash()
+		indices[tx.Hash()] = i
+	}
+	for i, txHash := range txHashes {
+		inclusion := &TxInclusion{TxHash: txHash}
+		if index, ok := indices[txHash]; ok {
+			txIndex := hexutil.Uint64(index)
+			inclusion.Included, inclusion.Index = true, &txIndex
+			if index < len(ev.Receipts) {
+				status, gasUsed := hexutil.Uint64(ev.Receipts[index].Status), hexutil.Uint64(ev.Receipts[index].GasUsed)
+				inclusion.Status, inclusion.GasUsed = &status, &gasUsed
+			}
+		}
+		pending.Inclusions[i] = inclusion
+	}
+	return pending
+}
@@ -0,0 +1,49 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPendingBlock(t *testing.T) {
+	var (
+		included = types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), nil)
+		failed   = types.NewTransaction(1, common.HexToAddress("0x01"), big.NewInt(1), 30000, big.NewInt(1), []byte{0x01})
+		missing  = types.NewTransaction(2, common.HexToAddress("0x01"), big.NewInt(1), 21000, big.NewInt(1), nil)
+		receipts = []*types.Receipt{
+			{Status: types.ReceiptStatusSuccessful, GasUsed: 21000, CumulativeGasUsed: 21000},
+			{Status: types.ReceiptStatusFailed, GasUsed: 30000, CumulativeGasUsed: 51000},
+		}
+		header = &types.Header{Number: big.NewInt(3), Time: 10, GasLimit: 8_000_000, GasUsed: 51000, BaseFee: big.NewInt(25)}
+		block  = types.NewBlock(header, []*types.Transaction{included, failed}, nil, receipts, trie.NewStackTrie(nil))
+	)
+
+	pending := newPendingBlock(core.PendingBlockEvent{Block: block, Receipts: receipts}, []common.Hash{failed.Hash(), missing.Hash()}, big.NewInt(24))
+	require.Equal(t, block.Hash(), pending.Hash)
+	require.EqualValues(t, 3, pending.Number)
+	require.EqualValues(t, 51000, pending.GasUsed)
+	require.Equal(t, big.NewInt(25), pending.BaseFee.ToInt())
+	require.Equal(t, big.NewInt(24), pending.EstimatedBaseFee.ToInt())
+	require.Equal(t, []common.Hash{included.Hash(), failed.Hash()}, pending.Transactions)
+
+	require.Len(t, pending.Inclusions, 2)
+	require.Equal(t, failed.Hash(), pending.Inclusions[0].TxHash)
+	require.True(t, pending.Inclusions[0].Included)
+	require.EqualValues(t, 1, *pending.Inclusions[0].Index)
+	require.EqualValues(t, types.ReceiptStatusFailed, *pending.Inclusions[0].Status)
+	require.EqualValues(t, 30000, *pending.Inclusions[0].GasUsed)
+
+	require.Equal(t, missing.Hash(), pending.Inclusions[1].TxHash)
+	require.False(t, pending.Inclusions[1].Included)
+	require.Nil(t, pending.Inclusions[1].Index)
+	require.Nil(t, pending.Inclusions[1].Status)
+}
@@ -0,0 +1,167 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errStateDiffArchiveDisabled = errors.New("state diff archive is disabled")
+
+// StateDiffAPI offers access to the archive of the accounts and storage slots modified by each block.
+type StateDiffAPI struct {
+	eth *Ethereum
+}
+
+// NewStateDiffAPI creates a new StateDiffAPI.
+func NewStateDiffAPI(eth *Ethereum) *StateDiffAPI {
+	return &StateDiffAPI{eth: eth}
+}
+
+// RPCStateDiff is the set of accounts and storage slots modified by a block.
+type RPCStateDiff struct {
+	BlockNumber hexutil.Uint64    `json:"blockNumber"`
+	BlockHash   common.Hash       `json:"blockHash"`
+	Accounts    []*RPCAccountDiff `json:"accounts"`
+}
+
+// RPCAccountDiff is the change made to an account by a block. Pre is null if the account did
+// not exist before the block, and Post is null if the account was deleted along with its storage.
+type RPCAccountDiff struct {
+	Address common.Address       `json:"address"`
+	Pre     *RPCAccountDiffState `json:"pre"`
+	Post    *RPCAccountDiffState `json:"post"`
+	Storage []*RPCStorageDiff    `json:"storage"`
+}
+
+// RPCAccountDiffState is the state of an account before or after a block, except for its storage.
+type RPCAccountDiffState struct {
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	Balance  *hexutil.Big   `json:"balance"`
+	CodeHash common.Hash    `json:"codeHash"`
+}
+
+// RPCStorageDiff is the change made to a storage slot by a block.
+type RPCStorageDiff struct {
+	Key  common.Hash `json:"key"`
+	Pre  common.Hash `json:"pre"`
+	Post common.Hash `json:"post"`
+}
+
+// GetStateDiff returns the accounts and storage slots modified by the block [blockNrOrHash].
+func (api *StateDiffAPI) GetStateDiff(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*RPCStateDiff, error) {
+	if !api.eth.config.StateDiffArchive {
+		return nil, errStateDiffArchiveDisabled
+	}
+	header, err := api.eth.APIBackend.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %s not found", blockNrOrHash.String())
+	}
+	diff := api.eth.BlockChain().GetStateDiff(header.Hash())
+	if diff == nil {
+		return nil, fmt.Errorf("state diff of block %d not available", header.Number.Uint64())
+	}
+	return newRPCStateDiff(header, diff, nil), nil
+}
+
+// StateDiffs creates a subscription that fires with the state diff of each accepted block. If
+// [addresses] is set, only the changes to these accounts are notified, and blocks that do not
+// modify any of them are skipped.
+func (api *StateDiffAPI) StateDiffs(ctx context.Context, addresses *[]common.Address) (*rpc.Subscription, error) {
+	if !api.eth.config.StateDiffArchive {
+		return &rpc.Subscription{}, errStateDiffArchiveDisabled
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var filter map[common.Address]struct{}
+	if addresses != nil {
+		filter = make(map[common.Address]struct{}, len(*addresses))
+		for _, addr := range *addresses {
+			filter[addr] = struct{}{}
+		}
+	}
+
+	var (
+		rpcSub   = notifier.CreateSubscription()
+		accepted = make(chan core.ChainEvent)
+	)
+	acceptedSub := api.eth.BlockChain().SubscribeChainAcceptedEvent(accepted)
+
+	go func() {
+		defer acceptedSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-accepted:
+				diff := api.eth.BlockChain().GetStateDiff(ev.Hash)
+				if diff == nil {
+					log.Debug("Missing state diff of accepted block", "number", ev.Block.NumberU64(), "hash", ev.Hash)
+					continue
+				}
+				rpcDiff := newRPCStateDiff(ev.Block.Header(), diff, filter)
+				if filter != nil && len(rpcDiff.Accounts) == 0 {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, rpcDiff)
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// newRPCStateDiff returns the RPC representation of [diff], the state diff of the block with
+// [header], restricted to the accounts in [filter] if it is not nil.
+func newRPCStateDiff(header *types.Header, diff *types.StateDiff, filter map[common.Address]struct{}) *RPCStateDiff {
+	result := &RPCStateDiff{
+		BlockNumber: hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:   header.Hash(),
+		Accounts:    make([]*RPCAccountDiff, 0, len(diff.Accounts)),
+	}
+	for _, account := range diff.Accounts {
+		if _, ok := filter[account.Address]; filter != nil && !ok {
+			continue
+		}
+		rpcAccount := &RPCAccountDiff{
+			Address: account.Address,
+			Pre:     newRPCAccountDiffState(account.Pre),
+			Post:    newRPCAccountDiffState(account.Post),
+			Storage: make([]*RPCStorageDiff, 0, len(account.Storage)),
+		}
+		for _, slot := range account.Storage {
+			rpcAccount.Storage = append(rpcAccount.Storage, &RPCStorageDiff{Key: slot.Key, Pre: slot.Pre, Post: slot.Post})
+		}
+		result.Accounts = append(result.Accounts, rpcAccount)
+	}
+	return result
+}
+
+func newRPCAccountDiffState(state *types.AccountDiffState) *RPCAccountDiffState {
+	if state == nil {
+		return nil
+	}
+	return &RPCAccountDiffState{
+		Nonce:    hexutil.Uint64(state.Nonce),
+		Balance:  (*hexutil.Big)(state.Balance),
+		CodeHash: state.CodeHash,
+	}
+}
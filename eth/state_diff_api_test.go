@@ -0,0 +1,55 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package eth
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRPCStateDiff(t *testing.T) {
+	var (
+		created = common.HexToAddress("0x01")
+		updated = common.HexToAddress("0x02")
+		header  = &types.Header{Number: big.NewInt(3)}
+		diff    = &types.StateDiff{Accounts: []*types.AccountDiff{
+			{
+				Address: created,
+				Post:    &types.AccountDiffState{Balance: big.NewInt(10), CodeHash: types.EmptyCodeHash},
+			},
+			{
+				Address: updated,
+				Pre:     &types.AccountDiffState{Nonce: 1, Balance: big.NewInt(5), CodeHash: common.Hash{0x01}},
+				Post:    &types.AccountDiffState{Nonce: 1, Balance: big.NewInt(5), CodeHash: common.Hash{0x01}},
+				Storage: []*types.StorageDiff{{Key: common.Hash{0x01}, Pre: common.Hash{0x02}, Post: common.Hash{0x03}}},
+			},
+		}}
+	)
+
+	rpcDiff := newRPCStateDiff(header, diff, nil)
+	require.Equal(t, header.Hash(), rpcDiff.BlockHash)
+	require.EqualValues(t, 3, rpcDiff.BlockNumber)
+	require.Len(t, rpcDiff.Accounts, 2)
+
+	require.Equal(t, created, rpcDiff.Accounts[0].Address)
+	require.Nil(t, rpcDiff.Accounts[0].Pre)
+	require.Equal(t, big.NewInt(10), rpcDiff.Accounts[0].Post.Balance.ToInt())
+	require.Empty(t, rpcDiff.Accounts[0].Storage)
+
+	require.Equal(t, updated, rpcDiff.Accounts[1].Address)
+	require.EqualValues(t, 1, rpcDiff.Accounts[1].Pre.Nonce)
+	require.Equal(t, []*RPCStorageDiff{{Key: common.Hash{0x01}, Pre: common.Hash{0x02}, Post: common.Hash{0x03}}}, rpcDiff.Accounts[1].Storage)
+
+	// Only the accounts in the filter are returned
+	rpcDiff = newRPCStateDiff(header, diff, map[common.Address]struct{}{updated: {}})
+	require.Len(t, rpcDiff.Accounts, 1)
+	require.Equal(t, updated, rpcDiff.Accounts[0].Address)
+
+	rpcDiff = newRPCStateDiff(header, diff, map[common.Address]struct{}{})
+	require.Empty(t, rpcDiff.Accounts)
+}
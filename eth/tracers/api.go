@@ -549,6 +549,21 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requested tracer.
 func (api *API) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
+	statedb, err := api.blockPrestate(ctx, block, config)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*txTraceResult, len(block.Transactions()))
+	if err := api.traceBlockTxs(ctx, block, statedb, config, func(index int, result *txTraceResult) {
+		results[index] = result
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// blockPrestate returns the state the transactions of [block] are executed on top of.
+func (api *API) blockPrestate(ctx context.Context, block *types.Block, config *TraceConfig) (*state.StateDB, error) {
 	if block.NumberU64() == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
@@ -560,23 +575,27 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	statedb, err := api.backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
-	if err != nil {
-		return nil, err
-	}
-	// Execute all the transaction contained within the block concurrently
+	return api.backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+}
+
+// traceBlockTxs traces the transactions of [block] executed on top of [statedb] and passes the
+// trace of each transaction to [onResult] as soon as it completes. [onResult] is called concurrently.
+// The transactions are traced by a pool of at most [runtime.NumCPU] workers, each tracing a transaction
+// from its own copy of its prestate. The prestates are generated by re-executing the transactions
+// without tracing, at most one task ahead of each worker, which bounds the state copies held in memory.
+func (api *API) traceBlockTxs(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig, onResult func(index int, result *txTraceResult)) error {
 	var (
 		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number(), new(big.Int).SetUint64(block.Time()))
 		txs     = block.Transactions()
-		results = make([]*txTraceResult, len(txs))
-
-		pend = new(sync.WaitGroup)
-		jobs = make(chan *txTraceTask, len(txs))
+		threads = runtime.NumCPU()
 	)
-	threads := runtime.NumCPU()
 	if threads > len(txs) {
 		threads = len(txs)
 	}
+	var (
+		pend = new(sync.WaitGroup)
+		jobs = make(chan *txTraceTask, threads)
+	)
 	blockHash := block.Hash()
 	for th := 0; th < threads; th++ {
 		pend.Add(1)
@@ -594,19 +613,25 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 				}
 				res, err := api.traceTx(ctx, msg, txctx, blockCtx, task.statedb, config)
 				if err != nil {
-					results[task.index] = &txTraceResult{Error: err.Error()}
+					onResult(task.index, &txTraceResult{Error: err.Error()})
 					continue
 				}
-				results[task.index] = &txTraceResult{Result: res}
+				onResult(task.index, &txTraceResult{Result: res})
 			}
 		}()
 	}
 	// Feed the transactions into the tracers and return
 	var failed error
 	blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+feed:
 	for i, tx := range txs {
-		// Send the trace task over for execution
-		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
+		// Send the trace task over for execution, unless the trace was aborted
+		select {
+		case jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}:
+		case <-ctx.Done():
+			failed = ctx.Err()
+			break feed
+		}
 
 		// Generate the next state snapshot fast without tracing
 		msg, _ := tx.AsMessage(signer, block.BaseFee())
@@ -624,10 +649,66 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	pend.Wait()
 
 	// If execution failed in between, abort
-	if failed != nil {
-		return nil, failed
+	return failed
+}
+
+// txTraceStreamResult is the trace of a single transaction streamed by TraceBlockStream.
+type txTraceStreamResult struct {
+	TxIndex hexutil.Uint64 `json:"txIndex"`
+	TxHash  common.Hash    `json:"txHash"`
+	Result  interface{}    `json:"result,omitempty"` // Trace results produced by the tracer
+	Error   string         `json:"error,omitempty"`  // Trace failure produced by the tracer
+}
+
+// TraceBlockStream traces the transactions of the block [blockNrOrHash] as TraceBlockByNumber does,
+// but streams the trace of each transaction as soon as it completes, in no particular order, so that
+// blocks too large to be traced within a single request can be traced.
+func (api *API) TraceBlockStream(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-	return results, nil
+	var (
+		block *types.Block
+		err   error
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		err = errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.blockPrestate(ctx, block, config)
+	if err != nil {
+		return nil, err
+	}
+	sub := notifier.CreateSubscription()
+
+	// Abort the trace if the subscription is cancelled.
+	localctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-sub.Err():
+		case <-notifier.Closed():
+		case <-localctx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer cancel()
+		txs := block.Transactions()
+		err := api.traceBlockTxs(localctx, block, statedb, config, func(index int, result *txTraceResult) {
+			notifier.Notify(sub.ID, &txTraceStreamResult{TxIndex: hexutil.Uint64(index), TxHash: txs[index].Hash(), Result: result.Result, Error: result.Error})
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Warn("Streaming block trace failed", "block", block.NumberU64(), "hash", block.Hash(), "err", err)
+		}
+	}()
+	return sub, nil
 }
 
 // TraceTransaction returns the structured logs created during the execution of EVM
@@ -778,5 +859,10 @@ func APIs(backend Backend) []rpc.API {
 			Service:   NewAPI(backend),
 			Name:      "debug-tracer",
 		},
+		{
+			Namespace: "trace",
+			Service:   NewParityAPI(backend),
+			Name:      "parity-tracer",
+		},
 	}
 }
@@ -440,6 +440,75 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+func TestTraceBlockStream(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	txCount := 20
+	signer := types.HomesteadSigner{}
+	api := NewAPI(newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[1] in every transaction of the block
+		for nonce := 0; nonce < txCount; nonce++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), accounts[1].addr, big.NewInt(1000), params.TxGas, new(big.Int).Add(b.BaseFee(), big.NewInt(int64(500*params.GWei))), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	}))
+	want, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+
+	server := rpc.NewServer(0)
+	defer server.Stop()
+	if err := server.RegisterName("debug", api); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// Tracing a missing block fails when subscribing.
+	traces := make(chan *txTraceStreamResult, txCount)
+	if _, err := client.Subscribe(context.Background(), "debug", traces, "traceBlockStream", rpc.BlockNumberOrHashWithNumber(2), nil); err == nil {
+		t.Fatal("expected error when streaming the trace of a missing block")
+	}
+
+	sub, err := client.Subscribe(context.Background(), "debug", traces, "traceBlockStream", rpc.BlockNumberOrHashWithNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Every transaction is streamed once, with the same trace as in the full block trace.
+	seen := make(map[uint64]bool)
+	for len(seen) < txCount {
+		select {
+		case trace := <-traces:
+			index := uint64(trace.TxIndex)
+			if seen[index] {
+				t.Fatalf("transaction %d streamed twice", index)
+			}
+			seen[index] = true
+			if trace.Error != want[index].Error {
+				t.Errorf("transaction %d: error mismatch, have %q, want %q", index, trace.Error, want[index].Error)
+			}
+			var expected interface{}
+			encoded, _ := json.Marshal(want[index].Result)
+			if err := json.Unmarshal(encoded, &expected); err != nil {
+				t.Fatalf("failed to decode trace: %v", err)
+			}
+			if !reflect.DeepEqual(trace.Result, expected) {
+				t.Errorf("transaction %d: result mismatch, have\n%v\n, want\n%v\n", index, trace.Result, expected)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		}
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
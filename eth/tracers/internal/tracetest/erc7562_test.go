@@ -0,0 +1,143 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/tests"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type erc7562Trace struct {
+	AccessedSlots struct {
+		Reads  map[common.Hash][]common.Hash `json:"reads"`
+		Writes map[common.Hash]uint64        `json:"writes"`
+	} `json:"accessedSlots"`
+	ExtCodeAccessInfo []common.Address  `json:"extCodeAccessInfo"`
+	UsedOpcodes       map[string]uint64 `json:"usedOpcodes"`
+	ContractSize      map[common.Address]struct {
+		ContractSize int    `json:"contractSize"`
+		Opcode       string `json:"opcode"`
+	} `json:"contractSize"`
+	OutOfGas bool            `json:"outOfGas"`
+	Keccak   []hexutil.Bytes `json:"keccak"`
+	Calls    []erc7562Trace  `json:"calls"`
+}
+
+// TestErc7562Tracer tests that the erc7562Tracer reports the opcodes, storage accesses, code
+// accesses and hashed inputs used by the validation rules of account abstraction bundlers.
+func TestErc7562Tracer(t *testing.T) {
+	var to = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.LatestSigner(params.TestChainConfig)
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		GasPrice: big.NewInt(1),
+		Gas:      100_000,
+		To:       &to,
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(1),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    common.Address{},
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(5),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    8_000_000,
+		BaseFee:     big.NewInt(1),
+	}
+	var code = []byte{
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x0, byte(vm.SSTORE), // write slot 0
+		byte(vm.PUSH1), 0x1, byte(vm.SLOAD), byte(vm.POP), // read slot 1
+		byte(vm.TIMESTAMP), byte(vm.POP),
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0, byte(vm.KECCAK256), byte(vm.POP),
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), // in and outs zero
+		byte(vm.DUP1), byte(vm.PUSH1), 0xff, byte(vm.GAS), byte(vm.CALL), byte(vm.POP), // value=0,address=0xff, gas=GAS
+		byte(vm.PUSH1), 0xff, byte(vm.EXTCODESIZE), byte(vm.POP),
+	}
+	var alloc = core.GenesisAlloc{
+		to: core.GenesisAccount{
+			Nonce:   1,
+			Code:    code,
+			Storage: map[common.Hash]common.Hash{common.HexToHash("0x01"): common.HexToHash("0x07")},
+		},
+		origin: core.GenesisAccount{
+			Nonce:   0,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	tracer, err := tracers.New("erc7562Tracer", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create erc7562 tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	if result.Failed() {
+		t.Fatalf("transaction failed: %v", result.Err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	have := new(erc7562Trace)
+	if err := json.Unmarshal(res, have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+
+	if have.UsedOpcodes["TIMESTAMP"] != 1 || have.UsedOpcodes["SSTORE"] != 1 || have.UsedOpcodes["CALL"] != 1 {
+		t.Errorf("unexpected opcodes: %v", have.UsedOpcodes)
+	}
+	if _, ok := have.UsedOpcodes["GAS"]; ok {
+		t.Errorf("GAS followed by CALL reported: %v", have.UsedOpcodes)
+	}
+	if writes := have.AccessedSlots.Writes; len(writes) != 1 || writes[common.Hash{}] != 1 {
+		t.Errorf("unexpected writes: %v", writes)
+	}
+	if reads := have.AccessedSlots.Reads[common.HexToHash("0x01")]; len(reads) != 1 || reads[0] != common.HexToHash("0x07") {
+		t.Errorf("unexpected reads: %v", have.AccessedSlots.Reads)
+	}
+	if len(have.Keccak) != 1 || len(have.Keccak[0]) != 32 {
+		t.Errorf("unexpected keccak inputs: %v", have.Keccak)
+	}
+	target := common.HexToAddress("0xff")
+	if len(have.ExtCodeAccessInfo) != 1 || have.ExtCodeAccessInfo[0] != target {
+		t.Errorf("unexpected code accesses: %v", have.ExtCodeAccessInfo)
+	}
+	if size, ok := have.ContractSize[target]; !ok || size.ContractSize != 0 || size.Opcode != "CALL" {
+		t.Errorf("unexpected contract sizes: %v", have.ContractSize)
+	}
+	if len(have.Calls) != 1 || have.OutOfGas {
+		t.Errorf("unexpected calls: %s", res)
+	}
+}
@@ -0,0 +1,139 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/tests"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type stateDiffAccount struct {
+	Balance      *hexutil.Big                `json:"balance"`
+	Nonce        uint64                      `json:"nonce"`
+	Storage      map[common.Hash]common.Hash `json:"storage"`
+	StorageNames map[common.Hash]string      `json:"storageNames"`
+}
+
+type stateDiffResult struct {
+	Pre  map[common.Address]*stateDiffAccount `json:"pre"`
+	Post map[common.Address]*stateDiffAccount `json:"post"`
+}
+
+// TestStateDiffTracerPrecompile tests that the stateDiffTracer reports the storage of the
+// fee config manager modified by setFeeConfig, annotated with the names of the slots.
+func TestStateDiffTracerPrecompile(t *testing.T) {
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewFeeManagerConfig(big.NewInt(0), nil, nil, nil))
+
+	feeConfig := params.DefaultFeeConfig
+	input, err := precompile.PackSetFeeConfig(feeConfig)
+	if err != nil {
+		t.Fatalf("failed to pack setFeeConfig: %v", err)
+	}
+	signer := types.LatestSigner(&config)
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		GasPrice: big.NewInt(1),
+		Gas:      500_000,
+		To:       &precompile.FeeConfigManagerAddress,
+		Data:     input,
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(1),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    common.Address{},
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(5),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    8_000_000,
+		BaseFee:     big.NewInt(1),
+	}
+	alloc := core.GenesisAlloc{
+		origin: core.GenesisAccount{Balance: big.NewInt(500000000000000)},
+		// Configured precompiles have a nonce, so that they are not deleted as empty accounts.
+		precompile.FeeConfigManagerAddress: core.GenesisAccount{Nonce: 1, Balance: new(big.Int)},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	precompile.SetFeeConfigManagerStatus(statedb, origin, precompile.AllowListAdmin)
+	statedb.Finalise(true)
+
+	tracer, err := tracers.New("stateDiffTracer", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create state diff tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, &config, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	if result.Failed() {
+		t.Fatalf("transaction failed: %v", result.Err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	have := new(stateDiffResult)
+	if err := json.Unmarshal(res, have); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+
+	sender, ok := have.Post[origin]
+	if !ok || sender.Nonce != 1 || have.Pre[origin].Balance.ToInt().Cmp(big.NewInt(500000000000000)) != 0 {
+		t.Fatalf("unexpected diff of sender: %s", res)
+	}
+	post, ok := have.Post[precompile.FeeConfigManagerAddress]
+	if !ok {
+		t.Fatalf("missing fee config manager in post state: %s", res)
+	}
+	pre := have.Pre[precompile.FeeConfigManagerAddress]
+	for name, value := range map[string]common.Hash{
+		"feeConfig.gasLimit":     common.BigToHash(feeConfig.GasLimit),
+		"feeConfig.minBaseFee":   common.BigToHash(feeConfig.MinBaseFee),
+		"feeConfigLastChangedAt": common.BigToHash(context.BlockNumber),
+	} {
+		slot, err := precompile.ResolveStorageSlot(precompile.FeeConfigManagerAddress, name)
+		if err != nil {
+			t.Fatalf("failed to resolve %s: %v", name, err)
+		}
+		if post.Storage[slot] != value {
+			t.Errorf("post %s: have %s, want %s", name, post.Storage[slot], value)
+		}
+		if pre.Storage[slot] != (common.Hash{}) {
+			t.Errorf("pre %s: have %s, want zero", name, pre.Storage[slot])
+		}
+		if post.StorageNames[slot] != name || pre.StorageNames[slot] != name {
+			t.Errorf("slot %s: have names %q and %q, want %q", slot, pre.StorageNames[slot], post.StorageNames[slot], name)
+		}
+	}
+}
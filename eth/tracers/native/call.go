@@ -30,6 +30,7 @@ import (
 	"encoding/json"
 	"errors"
 	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -37,7 +38,9 @@ import (
 
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 func init() {
@@ -45,16 +48,18 @@ func init() {
 }
 
 type callFrame struct {
-	Type    string      `json:"type"`
-	From    string      `json:"from"`
-	To      string      `json:"to,omitempty"`
-	Value   string      `json:"value,omitempty"`
-	Gas     string      `json:"gas"`
-	GasUsed string      `json:"gasUsed"`
-	Input   string      `json:"input"`
-	Output  string      `json:"output,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Calls   []callFrame `json:"calls,omitempty"`
+	Type     string                 `json:"type"`
+	From     string                 `json:"from"`
+	To       string                 `json:"to,omitempty"`
+	Value    string                 `json:"value,omitempty"`
+	Gas      string                 `json:"gas"`
+	GasUsed  string                 `json:"gasUsed"`
+	Input    string                 `json:"input"`
+	Function string                 `json:"function,omitempty"` // name of the stateful precompile function called, if any
+	Args     map[string]interface{} `json:"args,omitempty"`     // arguments of the stateful precompile function, if decodable
+	Output   string                 `json:"output,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Calls    []callFrame            `json:"calls,omitempty"`
 }
 
 type callTracer struct {
@@ -169,6 +174,46 @@ func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
 }
 
+// CaptureEnterPrecompile implements the PrecompileLogger interface to annotate the call frame
+// invoking a stateful precompile with the name of the function being called and, if the precompile
+// declares an ABI, its decoded arguments.
+func (t *callTracer) CaptureEnterPrecompile(from common.Address, to common.Address, input []byte, function string, gas uint64, depth int) {
+	if t.config.OnlyTopCall && depth > 0 {
+		return
+	}
+	if depth >= len(t.callstack) {
+		return
+	}
+	t.callstack[depth].Function = function
+	if _, args, err := precompile.UnpackFunctionInput(to, input); err == nil && len(args) > 0 {
+		for name, arg := range args {
+			args[name] = formatPrecompileArg(arg)
+		}
+		t.callstack[depth].Args = args
+	}
+}
+
+// formatPrecompileArg returns [arg] decoded from a precompile input in the hex encoding used by the
+// other fields of the call frame.
+func formatPrecompileArg(arg interface{}) interface{} {
+	switch arg := arg.(type) {
+	case *big.Int:
+		return (*hexutil.Big)(arg)
+	case []byte:
+		return hexutil.Bytes(arg)
+	}
+	if v := reflect.ValueOf(arg); v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Bytes(b)
+	}
+	return arg
+}
+
+// CaptureExitPrecompile implements the PrecompileLogger interface. The result of the invocation
+// is recorded by CaptureExit or CaptureEnd.
+func (t *callTracer) CaptureExitPrecompile(output []byte, gasUsed uint64, err error) {}
+
 func (*callTracer) CaptureTxStart(gasLimit uint64) {}
 
 func (*callTracer) CaptureTxEnd(restGas uint64) {}
@@ -0,0 +1,249 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func init() {
+	register("erc7562Tracer", newErc7562Tracer)
+}
+
+// accessedSlots are the storage slots of the executing contract accessed by a call frame.
+// Reads holds the values of the slots read before being written in the frame.
+type accessedSlots struct {
+	Reads  map[common.Hash][]common.Hash `json:"reads"`
+	Writes map[common.Hash]uint64        `json:"writes"`
+}
+
+// contractSizeWithOpcode is the size of the code of an account, with the first opcode
+// that accessed it.
+type contractSizeWithOpcode struct {
+	ContractSize int    `json:"contractSize"`
+	Opcode       string `json:"opcode"`
+}
+
+// erc7562Frame is a call frame annotated with the information needed to enforce the
+// ERC-7562 validation rules of account abstraction bundlers.
+type erc7562Frame struct {
+	Type              string                                     `json:"type"`
+	From              string                                     `json:"from"`
+	To                string                                     `json:"to,omitempty"`
+	Value             string                                     `json:"value,omitempty"`
+	Gas               string                                     `json:"gas"`
+	GasUsed           string                                     `json:"gasUsed"`
+	Input             string                                     `json:"input"`
+	Output            string                                     `json:"output,omitempty"`
+	Error             string                                     `json:"error,omitempty"`
+	AccessedSlots     accessedSlots                              `json:"accessedSlots"`
+	ExtCodeAccessInfo []common.Address                           `json:"extCodeAccessInfo"`
+	UsedOpcodes       map[string]uint64                          `json:"usedOpcodes"`
+	ContractSize      map[common.Address]*contractSizeWithOpcode `json:"contractSize"`
+	OutOfGas          bool                                       `json:"outOfGas"`
+	Keccak            []hexutil.Bytes                            `json:"keccak,omitempty"`
+	Calls             []erc7562Frame                             `json:"calls,omitempty"`
+
+	lastOp vm.OpCode
+}
+
+func newErc7562Frame(typ string, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) erc7562Frame {
+	return erc7562Frame{
+		Type:  typ,
+		From:  addrToHex(from),
+		To:    addrToHex(to),
+		Input: bytesToHex(input),
+		Gas:   uintToHex(gas),
+		Value: bigToHex(value),
+		AccessedSlots: accessedSlots{
+			Reads:  make(map[common.Hash][]common.Hash),
+			Writes: make(map[common.Hash]uint64),
+		},
+		ExtCodeAccessInfo: make([]common.Address, 0),
+		UsedOpcodes:       make(map[string]uint64),
+		ContractSize:      make(map[common.Address]*contractSizeWithOpcode),
+	}
+}
+
+// exit records the result of the frame.
+func (f *erc7562Frame) exit(output []byte, gasUsed uint64, err error) {
+	f.GasUsed = uintToHex(gasUsed)
+	if err == nil {
+		f.Output = bytesToHex(output)
+		return
+	}
+	f.Error = err.Error()
+	if errors.Is(err, vmerrs.ErrOutOfGas) {
+		f.OutOfGas = true
+	}
+	if errors.Is(err, vmerrs.ErrExecutionReverted) && len(output) > 0 {
+		f.Output = bytesToHex(output)
+	}
+}
+
+// erc7562Tracer reports the call frames of a call together with the opcodes they used,
+// the storage slots they accessed, the code they inspected and the inputs they hashed,
+// such that bundlers can check the validation of user operations with debug_traceCall.
+//
+// GAS is not reported when immediately followed by a call, which the rules allow.
+type erc7562Tracer struct {
+	env       *vm.EVM
+	callstack []erc7562Frame
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newErc7562Tracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &erc7562Tracer{callstack: make([]erc7562Frame, 1)}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *erc7562Tracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	typ := "CALL"
+	if create {
+		typ = "CREATE"
+	}
+	t.callstack[0] = newErc7562Frame(typ, from, to, input, gas, value)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *erc7562Tracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.callstack[0].exit(output, gasUsed, err)
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *erc7562Tracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+	frame := &t.callstack[len(t.callstack)-1]
+	if err != nil {
+		if errors.Is(err, vmerrs.ErrOutOfGas) {
+			frame.OutOfGas = true
+		}
+		return
+	}
+	isCall := op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL
+	if isCall && frame.lastOp == vm.GAS {
+		if frame.UsedOpcodes[vm.GAS.String()]--; frame.UsedOpcodes[vm.GAS.String()] == 0 {
+			delete(frame.UsedOpcodes, vm.GAS.String())
+		}
+	}
+	frame.lastOp = op
+	frame.UsedOpcodes[op.String()]++
+
+	stack := scope.Stack.Data()
+	switch {
+	case op == vm.SLOAD && len(stack) >= 1:
+		slot := common.Hash(stack[len(stack)-1].Bytes32())
+		if _, written := frame.AccessedSlots.Writes[slot]; !written {
+			value := t.env.StateDB.GetState(scope.Contract.Address(), slot)
+			frame.AccessedSlots.Reads[slot] = append(frame.AccessedSlots.Reads[slot], value)
+		}
+	case op == vm.SSTORE && len(stack) >= 1:
+		slot := common.Hash(stack[len(stack)-1].Bytes32())
+		frame.AccessedSlots.Writes[slot]++
+	case (op == vm.EXTCODESIZE || op == vm.EXTCODEHASH || op == vm.EXTCODECOPY) && len(stack) >= 1:
+		addr := common.Address(stack[len(stack)-1].Bytes20())
+		frame.ExtCodeAccessInfo = append(frame.ExtCodeAccessInfo, addr)
+		t.recordContractSize(frame, addr, op)
+	case isCall && len(stack) >= 2:
+		t.recordContractSize(frame, common.Address(stack[len(stack)-2].Bytes20()), op)
+	case op == vm.KECCAK256 && len(stack) >= 2:
+		offset, size := stack[len(stack)-1], stack[len(stack)-2]
+		if !offset.IsUint64() || !size.IsUint64() {
+			return
+		}
+		// Tracing happens before the memory expansion, which has been paid for, so the
+		// input is zero-padded
+		data := make([]byte, size.Uint64())
+		if offset.Uint64() < uint64(scope.Memory.Len()) {
+			copy(data, scope.Memory.Data()[offset.Uint64():])
+		}
+		frame.Keccak = append(frame.Keccak, data)
+	}
+}
+
+// recordContractSize records the size of the code of [addr], if it was not yet accessed
+// in the frame.
+func (t *erc7562Tracer) recordContractSize(frame *erc7562Frame, addr common.Address, op vm.OpCode) {
+	if _, ok := frame.ContractSize[addr]; ok {
+		return
+	}
+	frame.ContractSize[addr] = &contractSizeWithOpcode{
+		ContractSize: t.env.StateDB.GetCodeSize(addr),
+		Opcode:       op.String(),
+	}
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *erc7562Tracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+	if errors.Is(err, vmerrs.ErrOutOfGas) {
+		t.callstack[len(t.callstack)-1].OutOfGas = true
+	}
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *erc7562Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+	t.callstack = append(t.callstack, newErc7562Frame(typ.String(), from, to, input, gas, value))
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *erc7562Tracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+	// pop call
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	size -= 1
+
+	call.exit(output, gasUsed, err)
+	if err != nil && (call.Type == "CREATE" || call.Type == "CREATE2") {
+		call.To = ""
+	}
+	t.callstack[size-1].Calls = append(t.callstack[size-1].Calls, call)
+}
+
+func (*erc7562Tracer) CaptureTxStart(gasLimit uint64) {}
+
+func (*erc7562Tracer) CaptureTxEnd(restGas uint64) {}
+
+// GetResult returns the json-encoded nested list of annotated call frames, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *erc7562Tracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	res, err := json.Marshal(t.callstack[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *erc7562Tracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
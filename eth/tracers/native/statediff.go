@@ -0,0 +1,268 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func init() {
+	register("stateDiffTracer", newStateDiffTracer)
+}
+
+// diffAccount is the state of an account before or after a transaction, restricted to the fields
+// modified by the transaction. Storage slots of precompiles are named in StorageNames (e.g.
+// "feeConfig.gasLimit"), see [precompile.StorageSlotName].
+type diffAccount struct {
+	Balance      string                      `json:"balance,omitempty"`
+	Nonce        uint64                      `json:"nonce,omitempty"`
+	Code         string                      `json:"code,omitempty"`
+	Storage      map[common.Hash]common.Hash `json:"storage,omitempty"`
+	StorageNames map[common.Hash]string      `json:"storageNames,omitempty"`
+}
+
+type stateDiff struct {
+	Pre  map[common.Address]*diffAccount `json:"pre"`
+	Post map[common.Address]*diffAccount `json:"post"`
+}
+
+// dirtyStateDB is implemented by the state databases that track the accounts and storage slots
+// modified by the current transaction.
+type dirtyStateDB interface {
+	DirtyAccounts() map[common.Address][]common.Hash
+}
+
+// stateDiffTracer reports the accounts modified by a transaction, with their state before and
+// after it. The state before the transaction is recorded when an account is first touched,
+// including the addresses passed as arguments to stateful precompiles (e.g. the recipient of
+// mintNativeCoin). Accounts modified without being touched are reported in post only.
+type stateDiffTracer struct {
+	env       *vm.EVM
+	pre       map[common.Address]*account
+	diff      stateDiff
+	create    bool
+	to        common.Address
+	gasLimit  uint64 // Amount of gas bought for the whole tx
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newStateDiffTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &stateDiffTracer{
+		pre: make(map[common.Address]*account),
+		diff: stateDiff{
+			Pre:  make(map[common.Address]*diffAccount),
+			Post: make(map[common.Address]*diffAccount),
+		},
+	}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *stateDiffTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.create = create
+	t.to = to
+
+	t.lookupAccount(from)
+	t.lookupAccount(env.Context.Coinbase)
+	if create {
+		// The created contract did not exist before the transaction.
+		t.pre[to] = &account{Balance: bigToHex(new(big.Int))}
+	} else {
+		t.lookupAccount(to)
+		// The recipient balance includes the value transferred.
+		toBal := hexutil.MustDecodeBig(t.pre[to].Balance)
+		t.pre[to].Balance = hexutil.EncodeBig(new(big.Int).Sub(toBal, value))
+	}
+
+	// The sender balance is after reducing: value and gasLimit.
+	// We need to re-add them to get the pre-tx balance.
+	fromBal := hexutil.MustDecodeBig(t.pre[from].Balance)
+	consumedGas := new(big.Int).Mul(env.TxContext.GasPrice, new(big.Int).SetUint64(t.gasLimit))
+	fromBal.Add(fromBal, new(big.Int).Add(value, consumedGas))
+	t.pre[from].Balance = hexutil.EncodeBig(fromBal)
+	t.pre[from].Nonce--
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *stateDiffTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *stateDiffTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	stackData := scope.Stack.Data()
+	stackLen := len(stackData)
+	switch {
+	case stackLen >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.BALANCE || op == vm.SELFDESTRUCT):
+		t.lookupAccount(common.Address(stackData[stackLen-1].Bytes20()))
+	case stackLen >= 5 && (op == vm.DELEGATECALL || op == vm.CALL || op == vm.STATICCALL || op == vm.CALLCODE):
+		t.lookupAccount(common.Address(stackData[stackLen-2].Bytes20()))
+	case op == vm.CREATE:
+		addr := scope.Contract.Address()
+		t.lookupAccount(crypto.CreateAddress(addr, t.env.StateDB.GetNonce(addr)))
+	case stackLen >= 4 && op == vm.CREATE2:
+		offset := stackData[stackLen-2]
+		size := stackData[stackLen-3]
+		init := scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64()))
+		salt := stackData[stackLen-4]
+		t.lookupAccount(crypto.CreateAddress2(scope.Contract.Address(), salt.Bytes32(), crypto.Keccak256(init)))
+	}
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *stateDiffTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *stateDiffTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnterPrecompile implements the PrecompileLogger interface to record the state of the
+// precompile and of the accounts passed as arguments to it before it is run.
+func (t *stateDiffTracer) CaptureEnterPrecompile(from common.Address, to common.Address, input []byte, function string, gas uint64, depth int) {
+	t.lookupAccount(to)
+	_, args, err := precompile.UnpackFunctionInput(to, input)
+	if err != nil {
+		return
+	}
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case common.Address:
+			t.lookupAccount(arg)
+		case []common.Address:
+			for _, addr := range arg {
+				t.lookupAccount(addr)
+			}
+		}
+	}
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *stateDiffTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+func (t *stateDiffTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+// CaptureTxEnd diffs the state of the accounts touched or modified by the transaction, once the
+// gas has been refunded and the fee paid.
+func (t *stateDiffTracer) CaptureTxEnd(restGas uint64) {
+	if t.env == nil {
+		return
+	}
+	var dirties map[common.Address][]common.Hash
+	if db, ok := t.env.StateDB.(dirtyStateDB); ok {
+		dirties = db.DirtyAccounts()
+	}
+	for addr, pre := range t.pre {
+		t.diffAccount(addr, pre, dirties[addr])
+	}
+	for addr, keys := range dirties {
+		if _, ok := t.pre[addr]; !ok {
+			t.diffAccount(addr, nil, keys)
+		}
+	}
+}
+
+// diffAccount adds the fields of [addr] modified by the transaction to the diff, given its state
+// [pre] before the transaction (nil if unknown) and the keys of its modified storage slots.
+func (t *stateDiffTracer) diffAccount(addr common.Address, pre *account, keys []common.Hash) {
+	var (
+		db      = t.env.StateDB
+		preAcc  = &diffAccount{}
+		postAcc = &diffAccount{}
+		changed bool
+	)
+	if db.HasSuicided(addr) {
+		if pre != nil {
+			t.diff.Pre[addr] = &diffAccount{Balance: pre.Balance, Nonce: pre.Nonce, Code: pre.Code}
+		}
+		return
+	}
+	balance, nonce, code := bigToHex(db.GetBalance(addr)), db.GetNonce(addr), bytesToHex(db.GetCode(addr))
+	if pre == nil {
+		postAcc.Balance, postAcc.Nonce, postAcc.Code = balance, nonce, code
+		changed = true
+	} else {
+		if balance != pre.Balance {
+			preAcc.Balance, postAcc.Balance = pre.Balance, balance
+			changed = true
+		}
+		if nonce != pre.Nonce {
+			preAcc.Nonce, postAcc.Nonce = pre.Nonce, nonce
+			changed = true
+		}
+		if code != pre.Code && (pre.Code != "" || code != "0x") {
+			preAcc.Code, postAcc.Code = pre.Code, code
+			changed = true
+		}
+	}
+	for _, key := range keys {
+		preVal, postVal := db.GetCommittedState(addr, key), db.GetState(addr, key)
+		if preVal == postVal {
+			continue
+		}
+		if preAcc.Storage == nil {
+			preAcc.Storage = make(map[common.Hash]common.Hash)
+			postAcc.Storage = make(map[common.Hash]common.Hash)
+		}
+		preAcc.Storage[key], postAcc.Storage[key] = preVal, postVal
+		if name, ok := precompile.StorageSlotName(addr, key); ok {
+			if preAcc.StorageNames == nil {
+				preAcc.StorageNames = make(map[common.Hash]string)
+				postAcc.StorageNames = make(map[common.Hash]string)
+			}
+			preAcc.StorageNames[key], postAcc.StorageNames[key] = name, name
+		}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if pre != nil {
+		t.diff.Pre[addr] = preAcc
+	}
+	t.diff.Post[addr] = postAcc
+}
+
+// GetResult returns the json-encoded state diff, and any error arising from the
+// encoding or forceful termination (via `Stop`).
+func (t *stateDiffTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.diff)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *stateDiffTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// lookupAccount records the state of [addr] before the transaction, if it
+// has not been recorded yet.
+func (t *stateDiffTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.pre[addr]; ok {
+		return
+	}
+	t.pre[addr] = &account{
+		Balance: bigToHex(t.env.StateDB.GetBalance(addr)),
+		Nonce:   t.env.StateDB.GetNonce(addr),
+		Code:    bytesToHex(t.env.StateDB.GetCode(addr)),
+	}
+}
@@ -0,0 +1,351 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// parityTracer is the tracer whose call frames are flattened into parity traces.
+	parityTracer = "callTracer"
+
+	// maxParityFilterBlocks is the maximum number of blocks traced by a single trace_filter request.
+	maxParityFilterBlocks = 1024
+)
+
+var (
+	errParityFilterRange    = errors.New("fromBlock is greater than toBlock")
+	errParityFilterTooLarge = fmt.Errorf("cannot trace more than %d blocks at once", maxParityFilterBlocks)
+)
+
+// ParityAPI is the collection of tracing APIs served in the trace namespace, which returns
+// the call traces of transactions in the flat format of the OpenEthereum (formerly Parity)
+// trace module, so that indexers relying on it can run against subnet-evm.
+// Block reward traces are not returned, since fees are the only reward of the block producer.
+type ParityAPI struct {
+	api *API
+}
+
+// NewParityAPI creates a new API definition for the parity tracing methods of the Ethereum service.
+func NewParityAPI(backend Backend) *ParityAPI {
+	return &ParityAPI{api: NewAPI(backend)}
+}
+
+// ParityTraceAction is the action of a parity trace. Call traces set the call fields,
+// create traces set the create fields and suicide traces set the suicide fields.
+type ParityTraceAction struct {
+	// call and create
+	CallType string          `json:"callType,omitempty"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	Input    *hexutil.Bytes  `json:"input,omitempty"`
+	Init     *hexutil.Bytes  `json:"init,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+
+	// suicide
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// ParityTraceResult is the result of a successful call or create parity trace.
+type ParityTraceResult struct {
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+}
+
+// ParityTrace is a single call, create or suicide of a transaction.
+// [TraceAddress] is the path of the trace in the call tree of the transaction.
+type ParityTrace struct {
+	Action              ParityTraceAction  `json:"action"`
+	BlockHash           common.Hash        `json:"blockHash"`
+	BlockNumber         uint64             `json:"blockNumber"`
+	Error               string             `json:"error,omitempty"`
+	Result              *ParityTraceResult `json:"result"`
+	Subtraces           int                `json:"subtraces"`
+	TraceAddress        []int              `json:"traceAddress"`
+	TransactionHash     common.Hash        `json:"transactionHash"`
+	TransactionPosition uint64             `json:"transactionPosition"`
+	Type                string             `json:"type"`
+}
+
+// ParityFilterArgs are the arguments of trace_filter. Traces match if their sender is in
+// [FromAddress] and their recipient is in [ToAddress], where empty lists match any address.
+// [After] and [Count] paginate the matching traces.
+type ParityFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// parityCallFrame is the JSON encoding of the call frames produced by [parityTracer].
+type parityCallFrame struct {
+	Type    string            `json:"type"`
+	From    common.Address    `json:"from"`
+	To      *common.Address   `json:"to"`
+	Value   *hexutil.Big      `json:"value"`
+	Gas     hexutil.Uint64    `json:"gas"`
+	GasUsed hexutil.Uint64    `json:"gasUsed"`
+	Input   hexutil.Bytes     `json:"input"`
+	Output  hexutil.Bytes     `json:"output"`
+	Error   string            `json:"error"`
+	Calls   []parityCallFrame `json:"calls"`
+}
+
+// parityTxContext identifies the transaction a call frame belongs to.
+type parityTxContext struct {
+	blockHash   common.Hash
+	blockNumber uint64
+	txHash      common.Hash
+	txIndex     uint64
+}
+
+// Block returns the parity traces of all the transactions in the block [number].
+func (api *ParityAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*ParityTrace, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the parity traces of the transaction [hash].
+func (api *ParityAPI) Transaction(ctx context.Context, hash common.Hash) ([]*ParityTrace, error) {
+	_, blockHash, blockNumber, index, err := api.api.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	tracer := parityTracer
+	res, err := api.api.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	txctx := parityTxContext{
+		blockHash:   blockHash,
+		blockNumber: blockNumber,
+		txHash:      hash,
+		txIndex:     index,
+	}
+	return flattenParityTraces(res, txctx)
+}
+
+// Filter returns the parity traces of the blocks in the range of [args] matching its addresses.
+func (api *ParityAPI) Filter(ctx context.Context, args ParityFilterArgs) ([]*ParityTrace, error) {
+	from, err := api.filterBlock(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.filterBlock(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, errParityFilterRange
+	}
+	if to-from >= maxParityFilterBlocks {
+		return nil, errParityFilterTooLarge
+	}
+	var (
+		fromAddresses = addressSet(args.FromAddress)
+		toAddresses   = addressSet(args.ToAddress)
+		skip          uint64
+		traces        []*ParityTrace
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	for number := from; number <= to; number++ {
+		block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		blockTraces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range blockTraces {
+			if !trace.matches(fromAddresses, toAddresses) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			traces = append(traces, trace)
+			if args.Count != nil && uint64(len(traces)) >= *args.Count {
+				return traces, nil
+			}
+		}
+	}
+	return traces, nil
+}
+
+// filterBlock returns the number of the block [number] of a trace_filter request,
+// defaulting to the latest block.
+func (api *ParityAPI) filterBlock(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	blockNumber := rpc.LatestBlockNumber
+	if number != nil {
+		blockNumber = *number
+	}
+	header, err := api.api.backend.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// traceBlock returns the parity traces of all the transactions in [block].
+// The genesis block has no transactions and therefore no traces.
+func (api *ParityAPI) traceBlock(ctx context.Context, block *types.Block) ([]*ParityTrace, error) {
+	if block.NumberU64() == 0 {
+		return []*ParityTrace{}, nil
+	}
+	tracer := parityTracer
+	results, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*ParityTrace, 0, len(results))
+	for i, result := range results {
+		tx := block.Transactions()[i]
+		if result.Error != "" {
+			return nil, fmt.Errorf("failed to trace transaction %s: %s", tx.Hash(), result.Error)
+		}
+		txctx := parityTxContext{
+			blockHash:   block.Hash(),
+			blockNumber: block.NumberU64(),
+			txHash:      tx.Hash(),
+			txIndex:     uint64(i),
+		}
+		txTraces, err := flattenParityTraces(result.Result, txctx)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	return traces, nil
+}
+
+// flattenParityTraces decodes the call frames of [result] produced by [parityTracer] and
+// returns them as parity traces in depth-first order.
+func flattenParityTraces(result interface{}, txctx parityTxContext) ([]*ParityTrace, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T result of %s", result, parityTracer)
+	}
+	var frame parityCallFrame
+	if err := json.Unmarshal(raw, &frame); err != nil {
+		return nil, err
+	}
+	var traces []*ParityTrace
+	frame.flatten(txctx, []int{}, &traces)
+	return traces, nil
+}
+
+// flatten appends the parity trace of [f] at [traceAddress] followed by the traces of its
+// subcalls to [traces].
+func (f *parityCallFrame) flatten(txctx parityTxContext, traceAddress []int, traces *[]*ParityTrace) {
+	trace := &ParityTrace{
+		BlockHash:           txctx.blockHash,
+		BlockNumber:         txctx.blockNumber,
+		Subtraces:           len(f.Calls),
+		TraceAddress:        traceAddress,
+		TransactionHash:     txctx.txHash,
+		TransactionPosition: txctx.txIndex,
+	}
+	from, gas, input, output := f.From, f.Gas, f.Input, f.Output
+	value := f.Value
+	if value == nil {
+		value = (*hexutil.Big)(new(big.Int))
+	}
+	switch f.Type {
+	case "CREATE", "CREATE2":
+		trace.Type = "create"
+		trace.Action = ParityTraceAction{From: &from, Gas: &gas, Init: &input, Value: value}
+		if f.Error == "" {
+			trace.Result = &ParityTraceResult{GasUsed: f.GasUsed, Address: f.To, Code: &output}
+		}
+	case "SELFDESTRUCT":
+		trace.Type = "suicide"
+		trace.Action = ParityTraceAction{Address: &from, RefundAddress: f.To, Balance: value}
+	default:
+		trace.Type = "call"
+		trace.Action = ParityTraceAction{CallType: strings.ToLower(f.Type), From: &from, To: f.To, Gas: &gas, Input: &input, Value: value}
+		if f.Error == "" {
+			trace.Result = &ParityTraceResult{GasUsed: f.GasUsed, Output: &output}
+		}
+	}
+	if f.Error != "" {
+		trace.Error = parityError(f.Error)
+	}
+	*traces = append(*traces, trace)
+
+	for i := range f.Calls {
+		subtraceAddress := make([]int, len(traceAddress), len(traceAddress)+1)
+		copy(subtraceAddress, traceAddress)
+		f.Calls[i].flatten(txctx, append(subtraceAddress, i), traces)
+	}
+}
+
+// parityError returns the parity representation of the EVM error [err].
+func parityError(err string) string {
+	if err == "execution reverted" {
+		return "Reverted"
+	}
+	return err
+}
+
+// matches returns true if the sender of [t] is in [from] and its recipient is in [to],
+// where nil sets match any address.
+func (t *ParityTrace) matches(from, to map[common.Address]struct{}) bool {
+	sender, recipient := t.Action.From, t.Action.To
+	if t.Type == "suicide" {
+		sender, recipient = t.Action.Address, t.Action.RefundAddress
+	} else if t.Type == "create" && t.Result != nil {
+		recipient = t.Result.Address
+	}
+	return addressMatches(from, sender) && addressMatches(to, recipient)
+}
+
+func addressMatches(set map[common.Address]struct{}, addr *common.Address) bool {
+	if set == nil {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	_, ok := set[*addr]
+	return ok
+}
+
+func addressSet(addrs []common.Address) map[common.Address]struct{} {
+	if len(addrs) == 0 {
+		return nil
+	}
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
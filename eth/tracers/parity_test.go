@@ -0,0 +1,106 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenParityTraces(t *testing.T) {
+	var (
+		sender      = common.HexToAddress("0x01")
+		contract    = common.HexToAddress("0x02")
+		created     = common.HexToAddress("0x03")
+		beneficiary = common.HexToAddress("0x04")
+		txctx       = parityTxContext{
+			blockHash:   common.HexToHash("0xaa"),
+			blockNumber: 5,
+			txHash:      common.HexToHash("0xbb"),
+			txIndex:     1,
+		}
+	)
+	// Call frames in the encoding of the callTracer.
+	result := json.RawMessage(`{
+		"type": "CALL", "from": "0x0000000000000000000000000000000000000001", "to": "0x0000000000000000000000000000000000000002",
+		"value": "0x1", "gas": "0x10000", "gasUsed": "0x5000", "input": "0x1234", "output": "0x",
+		"calls": [
+			{
+				"type": "CREATE2", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000003",
+				"value": "0x0", "gas": "0x8000", "gasUsed": "0x1000", "input": "0x6000", "output": "0x00",
+				"calls": [
+					{"type": "SELFDESTRUCT", "from": "0x0000000000000000000000000000000000000003", "to": "0x0000000000000000000000000000000000000004", "value": "0x0", "gas": "0x0", "gasUsed": "0x0", "input": "0x"}
+				]
+			},
+			{
+				"type": "STATICCALL", "from": "0x0000000000000000000000000000000000000002", "to": "0x0000000000000000000000000000000000000003",
+				"gas": "0x100", "gasUsed": "0x100", "input": "0x", "error": "execution reverted"
+			}
+		]
+	}`)
+
+	traces, err := flattenParityTraces(result, txctx)
+	require.NoError(t, err)
+	require.Len(t, traces, 4)
+	for _, trace := range traces {
+		require.Equal(t, txctx.blockHash, trace.BlockHash)
+		require.EqualValues(t, 5, trace.BlockNumber)
+		require.Equal(t, txctx.txHash, trace.TransactionHash)
+		require.EqualValues(t, 1, trace.TransactionPosition)
+	}
+
+	call := traces[0]
+	require.Equal(t, "call", call.Type)
+	require.Equal(t, "call", call.Action.CallType)
+	require.Equal(t, sender, *call.Action.From)
+	require.Equal(t, contract, *call.Action.To)
+	require.Equal(t, big.NewInt(1), call.Action.Value.ToInt())
+	require.Equal(t, hexutil.Bytes{0x12, 0x34}, *call.Action.Input)
+	require.EqualValues(t, 0x5000, call.Result.GasUsed)
+	require.Equal(t, 2, call.Subtraces)
+	require.Equal(t, []int{}, call.TraceAddress)
+
+	create := traces[1]
+	require.Equal(t, "create", create.Type)
+	require.Equal(t, hexutil.Bytes{0x60, 0x00}, *create.Action.Init)
+	require.Nil(t, create.Action.Input)
+	require.Equal(t, created, *create.Result.Address)
+	require.Equal(t, hexutil.Bytes{0x00}, *create.Result.Code)
+	require.Equal(t, []int{0}, create.TraceAddress)
+
+	suicide := traces[2]
+	require.Equal(t, "suicide", suicide.Type)
+	require.Equal(t, created, *suicide.Action.Address)
+	require.Equal(t, beneficiary, *suicide.Action.RefundAddress)
+	require.Nil(t, suicide.Result)
+	require.Equal(t, []int{0, 0}, suicide.TraceAddress)
+
+	reverted := traces[3]
+	require.Equal(t, "staticcall", reverted.Action.CallType)
+	require.Equal(t, "Reverted", reverted.Error)
+	require.Nil(t, reverted.Result)
+	require.Equal(t, big.NewInt(0), reverted.Action.Value.ToInt())
+	require.Equal(t, []int{1}, reverted.TraceAddress)
+
+	// Traces match their sender and recipient, where creates match the created contract
+	// and suicides match the beneficiary.
+	match := func(from, to []common.Address) []*ParityTrace {
+		var matched []*ParityTrace
+		for _, trace := range traces {
+			if trace.matches(addressSet(from), addressSet(to)) {
+				matched = append(matched, trace)
+			}
+		}
+		return matched
+	}
+	require.Equal(t, traces, match(nil, nil))
+	require.Equal(t, []*ParityTrace{call}, match([]common.Address{sender}, nil))
+	require.Equal(t, []*ParityTrace{create, reverted}, match([]common.Address{contract}, []common.Address{created}))
+	require.Equal(t, []*ParityTrace{suicide}, match(nil, []common.Address{beneficiary}))
+}
@@ -77,9 +77,49 @@ type KeyValueStore interface {
 	io.Closer
 }
 
-// Reader contains the methods required to read data from key-value storage.
+// AncientReader contains the methods required to read from immutable ancient data.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified data exists in the
+	// ancient store.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient retrieves an ancient binary blob from the append-only immutable files.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the ancient item numbers in the ancient store, i.e. the
+	// number of the next item to be appended.
+	Ancients() (uint64, error)
+
+	// Tail returns the number of the first item stored in the ancient store.
+	Tail() (uint64, error)
+
+	// AncientSize returns the ancient size of the specified category.
+	AncientSize(kind string) (uint64, error)
+}
+
+// AncientWriter contains the methods required to write to immutable ancient data.
+type AncientWriter interface {
+	// AppendAncient injects all binary blobs belong to block at the end of the
+	// append-only immutable table files.
+	AppendAncient(number uint64, hash, header, body, receipts []byte) error
+
+	// Sync flushes all in-memory ancient store data to disk.
+	Sync() error
+}
+
+// AncientStore contains all the methods required to allow handling different
+// ancient data stores backing immutable chain data store.
+type AncientStore interface {
+	AncientReader
+	AncientWriter
+	io.Closer
+}
+
+// Reader contains the methods required to read data from both key-value as well as
+// immutable ancient data.
 type Reader interface {
 	KeyValueReader
+	AncientReader
 }
 
 // Writer contains the methods required to write data to key-value storage.
@@ -92,6 +132,7 @@ type Writer interface {
 type Database interface {
 	Reader
 	Writer
+	AncientWriter
 	Batcher
 	Iteratee
 	Stater
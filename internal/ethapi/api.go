@@ -27,10 +27,14 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/accounts"
@@ -44,7 +48,9 @@ import (
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth/tracers/logger"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
@@ -129,6 +135,75 @@ func (s *EthereumAPI) FeeHistory(ctx context.Context, blockCount rpc.DecimalOrHe
 	return results, nil
 }
 
+// feeConfigRange is the fee config in effect for the blocks from FromBlock to ToBlock, inclusive.
+type feeConfigRange struct {
+	FromBlock *hexutil.Big         `json:"fromBlock"`
+	ToBlock   *hexutil.Big         `json:"toBlock"`
+	FeeConfig commontype.FeeConfig `json:"feeConfig"`
+}
+
+type subnetFeeHistoryResult struct {
+	feeHistoryResult
+	BlockGasCost []*hexutil.Big   `json:"blockGasCost"`
+	FeeConfigs   []feeConfigRange `json:"feeConfigs"`
+}
+
+// SubnetFeeHistory returns the fee market history like FeeHistory, along with the block gas cost of each
+// block and the fee configs in effect over the range. Fee estimates based on FeeHistory alone are off
+// when the fee config is changed by the FeeConfigManager in the middle of the range.
+func (s *EthereumAPI) SubnetFeeHistory(ctx context.Context, blockCount rpc.DecimalOrHex, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*subnetFeeHistoryResult, error) {
+	history, err := s.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	results := &subnetFeeHistoryResult{
+		feeHistoryResult: *history,
+		BlockGasCost:     make([]*hexutil.Big, 0, len(history.GasUsedRatio)),
+	}
+	if len(history.GasUsedRatio) == 0 {
+		return results, nil
+	}
+
+	oldest := history.OldestBlock.ToInt().Uint64()
+	// The fee config of a block is the one stored in the state of its parent.
+	var parent *types.Header
+	if oldest > 0 {
+		if parent, err = s.b.HeaderByNumber(ctx, rpc.BlockNumber(oldest-1)); err != nil {
+			return nil, err
+		}
+	}
+	for i := range history.GasUsedRatio {
+		number := oldest + uint64(i)
+		header, err := s.b.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("header %d not found", number)
+		}
+		if parent == nil {
+			parent = header
+		}
+		feeConfig, _, err := s.b.GetFeeConfigAt(parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fee config of block %d: %w", number, err)
+		}
+		parent = header
+
+		results.BlockGasCost = append(results.BlockGasCost, (*hexutil.Big)(header.BlockGasCost))
+		if n := len(results.FeeConfigs); n > 0 && results.FeeConfigs[n-1].FeeConfig.Equal(&feeConfig) {
+			results.FeeConfigs[n-1].ToBlock = (*hexutil.Big)(header.Number)
+			continue
+		}
+		results.FeeConfigs = append(results.FeeConfigs, feeConfigRange{
+			FromBlock: (*hexutil.Big)(header.Number),
+			ToBlock:   (*hexutil.Big)(header.Number),
+			FeeConfig: feeConfig,
+		})
+	}
+	return results, nil
+}
+
 // Syncing allows the caller to determine whether the chain is syncing or not.
 // In geth, the response is either a map representing an ethereum.SyncProgress
 // struct or "false" (indicating the chain is not syncing).
@@ -147,7 +222,7 @@ func (s *BlockChainAPI) GetChainConfig(ctx context.Context) GetChainConfigRespon
 	config := s.b.ChainConfig()
 	resp := GetChainConfigResponse{
 		ChainConfig:   config,
-		UpgradeConfig: config.UpgradeConfig,
+		UpgradeConfig: *config.Upgrades(),
 	}
 	return resp
 }
@@ -625,12 +700,48 @@ func (api *BlockChainAPI) ChainId() *hexutil.Big {
 	return (*hexutil.Big)(api.b.ChainConfig().ChainID)
 }
 
-func (s *BlockChainAPI) GetActivePrecompilesAt(ctx context.Context, blockTimestamp *big.Int) params.PrecompileUpgrade {
+// GetActivePrecompilesAt returns the configs of the precompiles enabled at [blockTimestamp] and the optional
+// [blockNumber]. Each of them defaults to the timestamp or number of the current head if it is not provided.
+func (s *BlockChainAPI) GetActivePrecompilesAt(ctx context.Context, blockTimestamp *big.Int, blockNumber *big.Int) params.PrecompileUpgrade {
+	head := s.b.CurrentHeader()
 	if blockTimestamp == nil {
-		blockTimestampInt := s.b.CurrentHeader().Time
-		blockTimestamp = new(big.Int).SetUint64(blockTimestampInt)
+		blockTimestamp = new(big.Int).SetUint64(head.Time)
+	}
+	if blockNumber == nil {
+		blockNumber = new(big.Int).Set(head.Number)
 	}
-	return s.b.ChainConfig().GetActivePrecompiles(blockTimestamp)
+	return s.b.ChainConfig().GetActivePrecompiles(blockNumber, blockTimestamp)
+}
+
+type ChainConfigHashResult struct {
+	// Hash commits to the chain config and to every scheduled upgrade.
+	Hash common.Hash `json:"hash"`
+	// ActiveHash commits to the chain config and to the upgrades activated at the requested block.
+	ActiveHash common.Hash `json:"activeHash"`
+}
+
+// ChainConfigHash returns the canonical hashes of the chain config of the node, so that operators can check
+// that the validators of the chain have the same upgrade config. The active hash is computed at the block
+// [blockNrOrHash], which defaults to the current head if it is not provided.
+func (s *BlockChainAPI) ChainConfigHash(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*ChainConfigHashResult, error) {
+	var (
+		header *types.Header
+		err    error
+	)
+	if blockNrOrHash == nil {
+		header = s.b.CurrentHeader()
+	} else {
+		header, err = s.b.HeaderByNumberOrHash(ctx, *blockNrOrHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := s.b.ChainConfig()
+	return &ChainConfigHashResult{
+		Hash:       config.Hash(),
+		ActiveHash: config.HashAt(header.Number, new(big.Int).SetUint64(header.Time)),
+	}, nil
 }
 
 type FeeConfigResult struct {
@@ -639,26 +750,75 @@ type FeeConfigResult struct {
 }
 
 func (s *BlockChainAPI) FeeConfig(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*FeeConfigResult, error) {
+	return feeConfigAt(ctx, s.b, blockNrOrHash)
+}
+
+// feeConfigAt returns the fee config at [blockNrOrHash], or at the current head if [blockNrOrHash] is nil.
+func feeConfigAt(ctx context.Context, b Backend, blockNrOrHash *rpc.BlockNumberOrHash) (*FeeConfigResult, error) {
+	if !b.PrecompileAPIEnabled(precompile.FeeConfigManagerConfigKey) {
+		return nil, fmt.Errorf("%s APIs are disabled on this node", precompile.FeeConfigManagerConfigKey)
+	}
 	var (
 		header *types.Header
 		err    error
 	)
 	if blockNrOrHash == nil {
-		header = s.b.CurrentHeader()
+		header = b.CurrentHeader()
 	} else {
-		header, err = s.b.HeaderByNumberOrHash(ctx, *blockNrOrHash)
+		header, err = b.HeaderByNumberOrHash(ctx, *blockNrOrHash)
 		if err != nil {
 			return nil, err
 		}
+		if header == nil {
+			return nil, errors.New("header not found")
+		}
 	}
 
-	feeConfig, lastChangedAt, err := s.b.GetFeeConfigAt(header)
+	feeConfig, lastChangedAt, err := b.GetFeeConfigAt(header)
 	if err != nil {
 		return nil, err
 	}
 	return &FeeConfigResult{FeeConfig: feeConfig, LastChangedAt: lastChangedAt}, nil
 }
 
+// SubnetEVMAPI provides an API to access the subnet-evm specific configuration of the chain,
+// decoded from the precompile storage or the chain config.
+type SubnetEVMAPI struct {
+	b Backend
+}
+
+// NewSubnetEVMAPI creates a new subnet-evm API.
+func NewSubnetEVMAPI(b Backend) *SubnetEVMAPI {
+	return &SubnetEVMAPI{b}
+}
+
+// GetFeeConfig returns the fee config in effect at the current head, as stored by the
+// FeeConfigManager precompile or as set by the chain config if the precompile is not enabled.
+func (s *SubnetEVMAPI) GetFeeConfig(ctx context.Context) (*FeeConfigResult, error) {
+	return feeConfigAt(ctx, s.b, nil)
+}
+
+// GetFeeConfigAt returns the fee config in effect at the given block, as stored by the
+// FeeConfigManager precompile or as set by the chain config if the precompile is not enabled.
+func (s *SubnetEVMAPI) GetFeeConfigAt(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*FeeConfigResult, error) {
+	return feeConfigAt(ctx, s.b, &blockNrOrHash)
+}
+
+// GetPrecompileStorageSlots returns the storage keys of the precompile at [address] referred to by
+// the friendly [names] (e.g. "feeConfig.gasLimit" or "allowList[0x...]"), so that Merkle proofs of the
+// precompile state can be requested with eth_getProof.
+func (s *SubnetEVMAPI) GetPrecompileStorageSlots(address common.Address, names []string) ([]common.Hash, error) {
+	slots := make([]common.Hash, len(names))
+	for i, name := range names {
+		slot, err := precompile.ResolveStorageSlot(address, name)
+		if err != nil {
+			return nil, err
+		}
+		slots[i] = slot
+	}
+	return slots, nil
+}
+
 // BlockNumber returns the block number of the chain head.
 func (s *BlockChainAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
@@ -802,6 +962,29 @@ func (s *BlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fu
 	return nil, err
 }
 
+// GetBlockReceipts returns the receipts of all the transactions of the given block, so that
+// clients do not have to request them one at a time. Returns nil if the block is not found.
+func (s *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("receipts length mismatch: %d vs %d", len(txs), len(receipts))
+	}
+	header := block.Header()
+	result := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		result[i] = marshalReceipt(s.b.ChainConfig(), header, txs[i], receipt, uint64(i))
+	}
+	return result, nil
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block number and index.
 func (s *BlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
 	block, err := s.b.BlockByNumber(ctx, blockNr)
@@ -878,12 +1061,15 @@ func (s *BlockChainAPI) GetStorageAt(ctx context.Context, address common.Address
 // set, message execution will only use the data in the given state. Otherwise
 // if statDiff is set, all diff will be applied first and then execute the call
 // message.
+// PrecompileConfig overrides the config of the stateful precompile at the account
+// (e.g. {"disable": true}), as if an upgrade activated it in the block of the call.
 type OverrideAccount struct {
-	Nonce     *hexutil.Uint64              `json:"nonce"`
-	Code      *hexutil.Bytes               `json:"code"`
-	Balance   **hexutil.Big                `json:"balance"`
-	State     *map[common.Hash]common.Hash `json:"state"`
-	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+	Nonce            *hexutil.Uint64              `json:"nonce"`
+	Code             *hexutil.Bytes               `json:"code"`
+	Balance          **hexutil.Big                `json:"balance"`
+	State            *map[common.Hash]common.Hash `json:"state"`
+	StateDiff        *map[common.Hash]common.Hash `json:"stateDiff"`
+	PrecompileConfig json.RawMessage              `json:"precompileConfig"`
 }
 
 // StateOverride is the collection of overridden accounts.
@@ -924,6 +1110,84 @@ func (diff *StateOverride) Apply(state *state.StateDB) error {
 	return nil
 }
 
+// ApplyPrecompiles returns a copy of [config] in which the precompile configs overridden by [diff]
+// activate in the block of [header], and configures the overridden precompiles in [state] as such an
+// upgrade would. Precompiles active before the block are deconfigured first, so that their state is
+// reset to the overridden config. Returns [config] itself if no precompile config is overridden.
+func (diff *StateOverride) ApplyPrecompiles(config *params.ChainConfig, header *types.Header, state *state.StateDB) (*params.ChainConfig, error) {
+	if diff == nil {
+		return config, nil
+	}
+	addrs := make([]common.Address, 0, len(*diff))
+	for addr, account := range *diff {
+		if len(account.PrecompileConfig) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return config, nil
+	}
+	// Configure the precompiles in a deterministic order.
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	upgrades := *config.Upgrades()
+	upgrades.PrecompileUpgrades = append([]params.PrecompileUpgrade(nil), upgrades.PrecompileUpgrades...)
+	overridden := config.WithUpgradeConfig(upgrades)
+	blockContext := types.NewBlockWithHeader(header)
+	for _, addr := range addrs {
+		module, ok := precompile.GetRegisteredModuleByAddress(addr)
+		if !ok {
+			return nil, fmt.Errorf("account %s has 'precompileConfig' but is not a stateful precompile", addr.Hex())
+		}
+		precompileConfig, err := overridePrecompileConfig(module, (*diff)[addr].PrecompileConfig, header.Time)
+		if err != nil {
+			return nil, fmt.Errorf("account %s has invalid 'precompileConfig': %w", addr.Hex(), err)
+		}
+		if config.IsPrecompileEnabled(addr, header.Number, new(big.Int).SetUint64(header.Time)) {
+			precompile.Deconfigure(precompileConfig, state)
+		}
+		if !precompileConfig.IsDisabled() {
+			precompile.Configure(overridden, blockContext, precompileConfig, state)
+		}
+		overridden.PrecompileUpgrades = append(overridden.PrecompileUpgrades, params.NewPrecompileUpgrade(precompileConfig))
+	}
+	return overridden, nil
+}
+
+// overridePrecompileConfig parses the config [raw] of the precompile [module], activating at [timestamp]
+// regardless of the activation set in [raw].
+func overridePrecompileConfig(module precompile.Module, raw json.RawMessage, timestamp uint64) (precompile.StatefulPrecompileConfig, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "blockNumber")
+	fields["blockTimestamp"] = json.RawMessage(strconv.FormatUint(timestamp, 10))
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	precompileConfig := module.NewConfig()
+	if err := json.Unmarshal(raw, precompileConfig); err != nil {
+		return nil, err
+	}
+	return precompileConfig, nil
+}
+
+// newCallEVM returns an EVM executing [msg] on top of [state] in the block of [header] with the chain
+// config [chainConfig], which may differ from the config of the chain due to precompile overrides.
+func newCallEVM(ctx context.Context, b Backend, msg core.Message, state *state.StateDB, header *types.Header, chainConfig *params.ChainConfig, vmConfig *vm.Config) (*vm.EVM, func() error, error) {
+	if tracing.Enabled() {
+		// Record the stateful precompile calls in the span of the RPC call
+		vmConfig.TraceContext = ctx
+	}
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vmConfig)
+	if err != nil || chainConfig == evm.ChainConfig() {
+		return evm, vmError, err
+	}
+	return vm.NewEVM(evm.Context, evm.TxContext, state, chainConfig, evm.Config), vmError, nil
+}
+
 // BlockOverrides is a set of header fields to override.
 type BlockOverrides struct {
 	Number     *hexutil.Big
@@ -959,15 +1223,15 @@ func (diff *BlockOverrides) Apply(blockCtx *vm.BlockContext) {
 	}
 }
 
-func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
-
+// callStateAndHeader returns the state, header and chain config to execute calls on top of the block
+// [blockNrOrHash], with [overrides] applied to the state and the configs of the precompiles.
+func callStateAndHeader(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (*state.StateDB, *types.Header, *params.ChainConfig, error) {
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	if err := overrides.Apply(state); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	// If the request is for the pending block, override the block timestamp, number, and estimated
 	// base fee, so that the check runs as if it were run on a newly generated block.
@@ -982,10 +1246,24 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 		header.Number = new(big.Int).Add(header.Number, big.NewInt(1))
 		estimatedBaseFee, err := b.EstimateBaseFee(ctx)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 		header.BaseFee = estimatedBaseFee
 	}
+	chainConfig, err := overrides.ApplyPrecompiles(b.ChainConfig(), header, state)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return state, header, chainConfig, nil
+}
+
+func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, chainConfig, err := callStateAndHeader(ctx, b, blockNrOrHash, overrides)
+	if state == nil || err != nil {
+		return nil, err
+	}
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -1004,7 +1282,7 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	if err != nil {
 		return nil, err
 	}
-	evm, vmError, err := b.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true})
+	evm, vmError, err := newCallEVM(ctx, b, msg, state, header, chainConfig, &vm.Config{NoBaseFee: true})
 	if err != nil {
 		return nil, err
 	}
@@ -1113,7 +1391,173 @@ func (s *BlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrO
 	return result.Return(), result.Err
 }
 
-func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (hexutil.Uint64, error) {
+// CallManyResult is the result of a transaction of a bundle executed by CallMany.
+// [Error] is set if the transaction could not be executed or its execution failed,
+// in which case the state changes of the transaction are not applied.
+type CallManyResult struct {
+	UsedGas    hexutil.Uint64                  `json:"gasUsed"`
+	ReturnData hexutil.Bytes                   `json:"returnData"`
+	Error      string                          `json:"error,omitempty"`
+	Logs       []*types.Log                    `json:"logs"`
+	StateDiff  map[common.Address]*AccountDiff `json:"stateDiff"`
+}
+
+// AccountDiff contains the fields of an account changed by a transaction.
+type AccountDiff struct {
+	Balance *BigDiff                 `json:"balance,omitempty"`
+	Nonce   *Uint64Diff              `json:"nonce,omitempty"`
+	Code    *BytesDiff               `json:"code,omitempty"`
+	Storage map[common.Hash]HashDiff `json:"storage,omitempty"`
+}
+
+// BigDiff is the change of a numeric field.
+type BigDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// Uint64Diff is the change of an integer field.
+type Uint64Diff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// BytesDiff is the change of a byte field.
+type BytesDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// HashDiff is the change of a storage slot.
+type HashDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+// CallMany executes the ordered bundle of transactions [txs] on top of the state of the given block,
+// where each transaction observes the state changes of the previous ones. It returns the result,
+// logs and state changes of each transaction.
+//
+// Additionally, the caller can override the state with [overrides] and the header fields of the
+// simulated block with [blockOverrides]. The gas cap of the node applies to the whole bundle.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *BlockChainAPI) CallMany(ctx context.Context, txs []TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*CallManyResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, chainConfig, err := callStateAndHeader(ctx, s.b, blockNrOrHash, overrides)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	timeout := s.b.RPCEVMTimeout()
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	var (
+		gasCap  = s.b.RPCGasCap()
+		results = make([]*CallManyResult, len(txs))
+	)
+	for i, args := range txs {
+		if gasCap != 0 && gasCap <= params.TxGas {
+			return nil, fmt.Errorf("gas cap exhausted by the first %d transactions of the bundle", i)
+		}
+		msg, err := args.ToMessage(gasCap, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		evm, vmError, err := newCallEVM(ctx, s.b, msg, state, header, chainConfig, &vm.Config{NoBaseFee: true})
+		if err != nil {
+			return nil, err
+		}
+		blockOverrides.Apply(&evm.Context)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+
+		// Logs are keyed by the hash of the unsigned transaction, since bundle transactions are not signed.
+		txHash := types.NewTx(&types.LegacyTx{
+			Nonce:    msg.Nonce(),
+			GasPrice: msg.GasPrice(),
+			Gas:      msg.Gas(),
+			To:       msg.To(),
+			Value:    msg.Value(),
+			Data:     msg.Data(),
+		}).Hash()
+		preState, snapshot := state.Copy(), state.Snapshot()
+		state.Prepare(txHash, i)
+		result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		if err != nil {
+			// The transaction could not be executed, so its partial changes (e.g. the purchase of gas) are discarded.
+			state.RevertToSnapshot(snapshot)
+			results[i] = &CallManyResult{Error: err.Error(), Logs: []*types.Log{}, StateDiff: map[common.Address]*AccountDiff{}}
+			continue
+		}
+		res := &CallManyResult{
+			UsedGas:    hexutil.Uint64(result.UsedGas),
+			ReturnData: result.ReturnData,
+			Logs:       state.GetLogs(txHash, header.Hash()),
+			StateDiff:  stateDiff(preState, state),
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		if len(result.Revert()) > 0 {
+			res.Error = newRevertError(result).Error()
+		} else if result.Err != nil {
+			res.Error = result.Err.Error()
+		}
+		results[i] = res
+		state.Finalise(true)
+		if gasCap != 0 {
+			gasCap -= result.UsedGas
+		}
+	}
+	return results, nil
+}
+
+// stateDiff returns the changes of the accounts modified in [post] since the last call to Finalise,
+// relative to [pre].
+func stateDiff(pre, post *state.StateDB) map[common.Address]*AccountDiff {
+	diffs := make(map[common.Address]*AccountDiff)
+	for addr, keys := range post.DirtyAccounts() {
+		diff := new(AccountDiff)
+		if from, to := pre.GetBalance(addr), post.GetBalance(addr); from.Cmp(to) != 0 {
+			diff.Balance = &BigDiff{From: (*hexutil.Big)(from), To: (*hexutil.Big)(to)}
+		}
+		if from, to := pre.GetNonce(addr), post.GetNonce(addr); from != to {
+			diff.Nonce = &Uint64Diff{From: hexutil.Uint64(from), To: hexutil.Uint64(to)}
+		}
+		if pre.GetCodeHash(addr) != post.GetCodeHash(addr) {
+			diff.Code = &BytesDiff{From: pre.GetCode(addr), To: post.GetCode(addr)}
+		}
+		for _, key := range keys {
+			if from, to := pre.GetState(addr, key), post.GetState(addr, key); from != to {
+				if diff.Storage == nil {
+					diff.Storage = make(map[common.Hash]HashDiff)
+				}
+				diff.Storage[key] = HashDiff{From: from, To: to}
+			}
+		}
+		if diff.Balance != nil || diff.Nonce != nil || diff.Code != nil || diff.Storage != nil {
+			diffs[addr] = diff
+		}
+	}
+	return diffs
+}
+
+func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, gasCap uint64) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -1151,10 +1595,13 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 	}
 	// Recap the highest gas limit with account's available balance.
 	if feeCap.BitLen() != 0 {
-		state, _, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+		state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 		if err != nil {
 			return 0, err
 		}
+		if err := overrides.Apply(state); err != nil {
+			return 0, err
+		}
 		balance := state.GetBalance(*args.From) // from can't be nil
 		available := new(big.Int).Set(balance)
 		if args.Value != nil {
@@ -1163,6 +1610,11 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 			}
 			available.Sub(available, args.Value.ToInt())
 		}
+		// If gas is paid in the gas token, the value is still paid in native coin.
+		if b.ChainConfig().IsGasToken(header.Number, new(big.Int).SetUint64(header.Time)) {
+			balance = precompile.GetGasTokenBalance(state, *args.From)
+			available = new(big.Int).Set(balance)
+		}
 		allowance := new(big.Int).Div(available, feeCap)
 
 		// If the allowance is larger than maximum uint64, skip checking
@@ -1187,7 +1639,7 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
 		args.Gas = (*hexutil.Uint64)(&gas)
 
-		result, err := DoCall(ctx, b, args, blockNrOrHash, nil, 0, gasCap)
+		result, err := DoCall(ctx, b, args, blockNrOrHash, overrides, 0, gasCap)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
@@ -1234,13 +1686,14 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *BlockChainAPI) EstimateGas(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+// given transaction against the current pending block, or the given block
+// with [overrides] applied.
+func (s *BlockChainAPI) EstimateGas(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Uint64, error) {
 	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
 	if blockNrOrHash != nil {
 		bNrOrHash = *blockNrOrHash
 	}
-	return DoEstimateGas(ctx, s.b, args, bNrOrHash, s.b.RPCGasCap())
+	return DoEstimateGas(ctx, s.b, args, bNrOrHash, overrides, s.b.RPCGasCap())
 }
 
 // RPCMarshalHeader converts the given header to the RPC output .
@@ -1338,25 +1791,28 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        *common.Hash      `json:"blockHash"`
-	BlockNumber      *hexutil.Big      `json:"blockNumber"`
-	From             common.Address    `json:"from"`
-	Gas              hexutil.Uint64    `json:"gas"`
-	GasPrice         *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	Hash             common.Hash       `json:"hash"`
-	Input            hexutil.Bytes     `json:"input"`
-	Nonce            hexutil.Uint64    `json:"nonce"`
-	To               *common.Address   `json:"to"`
-	TransactionIndex *hexutil.Uint64   `json:"transactionIndex"`
-	Value            *hexutil.Big      `json:"value"`
-	Type             hexutil.Uint64    `json:"type"`
-	Accesses         *types.AccessList `json:"accessList,omitempty"`
-	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
-	V                *hexutil.Big      `json:"v"`
-	R                *hexutil.Big      `json:"r"`
-	S                *hexutil.Big      `json:"s"`
+	BlockHash         *common.Hash                 `json:"blockHash"`
+	BlockNumber       *hexutil.Big                 `json:"blockNumber"`
+	From              common.Address               `json:"from"`
+	Gas               hexutil.Uint64               `json:"gas"`
+	GasPrice          *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap         *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap         *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	Hash              common.Hash                  `json:"hash"`
+	Input             hexutil.Bytes                `json:"input"`
+	Nonce             hexutil.Uint64               `json:"nonce"`
+	To                *common.Address              `json:"to"`
+	TransactionIndex  *hexutil.Uint64              `json:"transactionIndex"`
+	Value             *hexutil.Big                 `json:"value"`
+	Type              hexutil.Uint64               `json:"type"`
+	Accesses          *types.AccessList            `json:"accessList,omitempty"`
+	ChainID           *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobGasFeeCap     *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes        []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                 *hexutil.Big                 `json:"v"`
+	R                 *hexutil.Big                 `json:"r"`
+	S                 *hexutil.Big                 `json:"s"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1394,12 +1850,19 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
-	case types.DynamicFeeTxType:
+	case types.DynamicFeeTxType, types.BlobTxType, types.SetCodeTxType:
 		al := tx.AccessList()
 		result.Accesses = &al
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
 		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		switch tx.Type() {
+		case types.BlobTxType:
+			result.BlobGasFeeCap = (*hexutil.Big)(tx.BlobGasFeeCap())
+			result.BlobHashes = tx.BlobHashes()
+		case types.SetCodeTxType:
+			result.AuthorizationList = tx.SetCodeAuthorizations()
+		}
 		// if the transaction has been mined, compute the effective gas price
 		if baseFee != nil && blockHash != (common.Hash{}) {
 			// price = min(tip, gasFeeCap - baseFee) + baseFee
@@ -1715,7 +2178,7 @@ func (s *TransactionAPI) GetRawTransactionByHash(ctx context.Context, hash commo
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
-	tx, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	tx, blockHash, _, index, err := s.b.GetTransaction(ctx, hash)
 	if err != nil {
 		// When the transaction doesn't exist, the RPC method should return JSON null
 		// as per specification.
@@ -1732,18 +2195,21 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	if len(receipts) <= int(index) {
 		return nil, nil
 	}
-	receipt := receipts[index]
+	return marshalReceipt(s.b.ChainConfig(), header, tx, receipts[index], index), nil
+}
 
+// marshalReceipt returns the JSON encoding of [receipt] of [tx], included at position [index]
+// of the block of [header].
+func marshalReceipt(config *params.ChainConfig, header *types.Header, tx *types.Transaction, receipt *types.Receipt, index uint64) map[string]interface{} {
 	// Derive the sender.
-	bigblock := new(big.Int).SetUint64(blockNumber)
 	timestamp := new(big.Int).SetUint64(header.Time)
-	signer := types.MakeSigner(s.b.ChainConfig(), bigblock, timestamp)
+	signer := types.MakeSigner(config, header.Number, timestamp)
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
-		"blockHash":         blockHash,
-		"blockNumber":       hexutil.Uint64(blockNumber),
-		"transactionHash":   hash,
+		"blockHash":         header.Hash(),
+		"blockNumber":       hexutil.Uint64(header.Number.Uint64()),
+		"transactionHash":   tx.Hash(),
 		"transactionIndex":  hexutil.Uint64(index),
 		"from":              from,
 		"to":                tx.To(),
@@ -1755,7 +2221,7 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 		"type":              hexutil.Uint(tx.Type()),
 	}
 	// Assign the effective gas price paid
-	if !s.b.ChainConfig().IsSubnetEVM(timestamp) {
+	if !config.IsSubnetEVM(timestamp) {
 		fields["effectiveGasPrice"] = hexutil.Uint64(tx.GasPrice().Uint64())
 	} else {
 		gasPrice := new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
@@ -1774,7 +2240,7 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 	if receipt.ContractAddress != (common.Address{}) {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -1880,6 +2346,39 @@ func (s *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil.B
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// maxConditionalCost is the maximum number of checks of the conditional of a transaction
+// submitted through SendRawTransactionConditional, see [types.TransactionConditional.Cost].
+const maxConditionalCost = 1000
+
+// SendRawTransactionConditional will add the signed transaction to the transaction pool,
+// to only be included in a block satisfying the preconditions of [cond]. The conditional
+// is checked against the latest state on submission and again when building blocks, and
+// the transaction is dropped once it can no longer be satisfied.
+//
+// Conditional transactions are not gossiped, since their preconditions are not part of
+// the transaction, and are therefore only included in blocks built by this node.
+func (s *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if cost := cond.Cost(); cost > maxConditionalCost {
+		return common.Hash{}, fmt.Errorf("conditional cost %d exceeds the maximum of %d", cost, maxConditionalCost)
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return common.Hash{}, err
+	}
+	if cond.Expired(new(big.Int).Add(header.Number, common.Big1), header.Time) {
+		return common.Hash{}, errors.New("conditional cannot be satisfied by the next block")
+	}
+	if err := state.CheckTransactionConditional(&cond); err != nil {
+		return common.Hash{}, fmt.Errorf("conditional not satisfied: %w", err)
+	}
+	tx.SetConditional(&cond)
+	return SubmitTransaction(ctx, s.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -2059,6 +2558,9 @@ func (api *DebugAPI) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.Block
 		if err != nil {
 			return nil, err
 		}
+		if block == nil {
+			return nil, fmt.Errorf("block %v not found", blockNrOrHash)
+		}
 		hash = block.Hash()
 	}
 	receipts, err := api.b.GetReceipts(ctx, hash)
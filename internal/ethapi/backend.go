@@ -62,6 +62,7 @@ type Backend interface {
 	RPCEVMTimeout() time.Duration                  // global timeout for eth_call over rpc: DoS protection
 	RPCTxFeeCap() float64                          // global tx fee cap for all transaction related APIs
 	UnprotectedAllowed(tx *types.Transaction) bool // allows only for EIP155 transactions.
+	PrecompileAPIEnabled(configKey string) bool    // allows disabling the RPC helpers of a precompile on this node.
 
 	// Blockchain API
 	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
@@ -121,6 +122,10 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Namespace: "txpool",
 			Service:   NewTxPoolAPI(apiBackend),
 			Name:      "internal-tx-pool",
+		}, {
+			Namespace: "subnetevm",
+			Service:   NewSubnetEVMAPI(apiBackend),
+			Name:      "internal-subnetevm",
 		}, {
 			Namespace: "debug",
 			Service:   NewDebugAPI(apiBackend),
@@ -0,0 +1,389 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks simulated by a single eth_simulateV1 request.
+	maxSimulateBlocks = 256
+
+	// simulateTimestampIncrement is the default difference between the timestamps of consecutive
+	// simulated blocks.
+	simulateTimestampIncrement = 1
+
+	// JSON-RPC error codes of eth_simulateV1 for calls failing in the EVM.
+	simulateErrCodeReverted = 3
+	simulateErrCodeVMError  = -32015
+)
+
+var (
+	errSimulateNoBlocks      = errors.New("empty blockStateCalls")
+	errSimulateTooManyBlocks = fmt.Errorf("cannot simulate more than %d blocks", maxSimulateBlocks)
+)
+
+// SimulateOpts are the options of eth_simulateV1.
+// If [Validation] is set, calls are checked as transactions of a block would be (e.g. their nonces,
+// balances and fees), otherwise they are executed as with eth_call. In both cases the tx allow list
+// and address blocklist are enforced, and precompile upgrades activate at the simulated blocks.
+type SimulateOpts struct {
+	BlockStateCalls        []SimulateBlock `json:"blockStateCalls"`
+	Validation             bool            `json:"validation"`
+	ReturnFullTransactions bool            `json:"returnFullTransactions"`
+}
+
+// SimulateBlock is a block of calls executed by eth_simulateV1 on top of the previous one.
+// [StateOverrides] are applied to the state before the calls are executed.
+type SimulateBlock struct {
+	BlockOverrides *BlockOverrides   `json:"blockOverrides"`
+	StateOverrides *StateOverride    `json:"stateOverrides"`
+	Calls          []TransactionArgs `json:"calls"`
+}
+
+// SimulateCallResult is the result of a call executed by eth_simulateV1.
+type SimulateCallResult struct {
+	ReturnData hexutil.Bytes      `json:"returnData"`
+	Logs       []*types.Log       `json:"logs"`
+	GasUsed    hexutil.Uint64     `json:"gasUsed"`
+	Status     hexutil.Uint64     `json:"status"`
+	Error      *SimulateCallError `json:"error,omitempty"`
+}
+
+// SimulateCallError is the error of a call failing in the EVM.
+type SimulateCallError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// simulator executes the blocks of an eth_simulateV1 request on top of [state].
+type simulator struct {
+	b          Backend
+	config     *params.ChainConfig
+	state      *state.StateDB
+	validation bool
+	fullTx     bool
+	gasCap     uint64
+	calls      int64 // number of calls executed so far, used to bucket their logs
+}
+
+// SimulateV1 executes the blocks of calls in [opts] on top of the state of the given block,
+// where each block is built on top of the previous one, and returns the simulated blocks along
+// with the results and logs of their calls. Calls to stateful precompiles are executed as on-chain.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts SimulateOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM simulation finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, errSimulateNoBlocks
+	}
+	if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, errSimulateTooManyBlocks
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	timeout := s.b.RPCEVMTimeout()
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	sim := &simulator{
+		b:          s.b,
+		config:     s.b.ChainConfig(),
+		state:      state,
+		validation: opts.Validation,
+		fullTx:     opts.ReturnFullTransactions,
+		gasCap:     s.b.RPCGasCap(),
+	}
+	results := make([]map[string]interface{}, len(opts.BlockStateCalls))
+	for i, block := range opts.BlockStateCalls {
+		header, err := sim.header(parent, block.BlockOverrides)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		result, simulated, err := sim.processBlock(ctx, parent, header, block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		results[i] = result
+		parent = simulated.Header()
+	}
+	return results, nil
+}
+
+// header returns the header of the block simulated on top of [parent] with [overrides] applied.
+// The base fee is derived from the fee config of the simulated state, as it would be on-chain.
+func (sim *simulator) header(parent *types.Header, overrides *BlockOverrides) (*types.Header, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase,
+		Difficulty: common.Big1,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + simulateTimestampIncrement,
+	}
+	if overrides != nil {
+		if overrides.Number != nil {
+			header.Number = new(big.Int).Set(overrides.Number.ToInt())
+		}
+		if overrides.Time != nil {
+			header.Time = overrides.Time.ToInt().Uint64()
+		}
+		if overrides.Coinbase != nil {
+			header.Coinbase = *overrides.Coinbase
+		}
+		if overrides.Difficulty != nil {
+			header.Difficulty = new(big.Int).Set(overrides.Difficulty.ToInt())
+		}
+	}
+	if header.Number.Cmp(parent.Number) <= 0 {
+		return nil, fmt.Errorf("block number %d is not greater than parent number %d", header.Number, parent.Number)
+	}
+	if header.Time < parent.Time {
+		return nil, fmt.Errorf("block timestamp %d is lower than parent timestamp %d", header.Time, parent.Time)
+	}
+	if sim.config.IsSubnetEVM(new(big.Int).SetUint64(header.Time)) {
+		feeConfig, err := sim.feeConfigAt(parent)
+		if err != nil {
+			return nil, err
+		}
+		header.GasLimit = feeConfig.GasLimit.Uint64()
+		header.Extra, header.BaseFee, err = dummy.CalcBaseFee(sim.config, feeConfig, parent, header.Time)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if overrides != nil {
+		if overrides.GasLimit != nil {
+			header.GasLimit = uint64(*overrides.GasLimit)
+		}
+		if overrides.BaseFee != nil {
+			header.BaseFee = new(big.Int).Set(overrides.BaseFee.ToInt())
+		}
+	}
+	return header, nil
+}
+
+// feeConfigAt returns the fee config in effect for the children of [parent], read from the
+// simulated state if the FeeConfigManager precompile is enabled.
+func (sim *simulator) feeConfigAt(parent *types.Header) (commontype.FeeConfig, error) {
+	parentTime := new(big.Int).SetUint64(parent.Time)
+	if !sim.config.IsFeeConfigManager(parent.Number, parentTime) {
+		return sim.config.FeeConfigAt(parentTime), nil
+	}
+	feeConfig := precompile.GetStoredFeeConfig(sim.state)
+	if err := feeConfig.Verify(); err != nil {
+		return commontype.EmptyFeeConfig, err
+	}
+	return feeConfig, nil
+}
+
+// processBlock executes the calls of [block] on top of [parent] and returns the RPC representation
+// of the simulated block, including the results of its calls, along with the simulated block.
+func (sim *simulator) processBlock(ctx context.Context, parent *types.Header, header *types.Header, block SimulateBlock) (map[string]interface{}, *types.Block, error) {
+	// Configure the precompiles activated by the block before applying the overrides, so that the
+	// overrides can modify the state of the activated precompiles.
+	sim.config.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time), types.NewBlockWithHeader(header), sim.state)
+	if err := sim.config.ApplyStatePatch(parent.Root, types.NewBlockWithHeader(header), sim.state); err != nil {
+		return nil, nil, err
+	}
+	if err := core.ApplyValidatorSnapshot(sim.config, header, sim.state); err != nil {
+		return nil, nil, err
+	}
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return nil, nil, err
+	}
+	// Precompile config overrides persist in the following blocks, as an upgrade would.
+	config, err := block.StateOverrides.ApplyPrecompiles(sim.config, header, sim.state)
+	if err != nil {
+		return nil, nil, err
+	}
+	sim.config = config
+
+	var (
+		gasUsed  uint64
+		txs      = make(types.Transactions, len(block.Calls))
+		senders  = make([]common.Address, len(block.Calls))
+		receipts = make(types.Receipts, len(block.Calls))
+		results  = make([]SimulateCallResult, len(block.Calls))
+	)
+	for i, args := range block.Calls {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		tx, msg, err := sim.message(args, header, gasUsed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		evm, vmError, err := newCallEVM(ctx, sim.b, msg, sim.state, header, sim.config, &vm.Config{NoBaseFee: !sim.validation})
+		if err != nil {
+			return nil, nil, err
+		}
+		go func() {
+			<-ctx.Done()
+			evm.Cancel()
+		}()
+		if err := core.CheckSenderAllowed(sim.config, evm.Context, sim.state, msg.From()); err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		// Logs are bucketed by the position of the call in the simulation, since the hashes of
+		// unsigned transactions from different senders may collide.
+		logKey := common.BigToHash(big.NewInt(sim.calls))
+		sim.calls++
+		sim.state.Prepare(logKey, i)
+		result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		if err := vmError(); err != nil {
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		sim.state.Finalise(true)
+		gasUsed += result.UsedGas
+		if sim.gasCap != 0 {
+			sim.gasCap -= result.UsedGas
+		}
+
+		logs := sim.state.GetLogs(logKey, common.Hash{})
+		if logs == nil {
+			logs = []*types.Log{}
+		}
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: gasUsed,
+			Logs:              logs,
+			TxHash:            tx.Hash(),
+			GasUsed:           result.UsedGas,
+			TransactionIndex:  uint(i),
+		}
+		callResult := SimulateCallResult{
+			ReturnData: result.Return(),
+			Logs:       logs,
+			GasUsed:    hexutil.Uint64(result.UsedGas),
+			Status:     hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if result.Failed() {
+			receipt.Status = types.ReceiptStatusFailed
+			callResult.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if len(result.Revert()) > 0 {
+				revertErr := newRevertError(result)
+				callResult.ReturnData = result.Revert()
+				callResult.Error = &SimulateCallError{Code: simulateErrCodeReverted, Message: revertErr.Error(), Data: revertErr.reason}
+			} else {
+				callResult.Error = &SimulateCallError{Code: simulateErrCodeVMError, Message: result.Err.Error()}
+			}
+		}
+		if msg.To() == nil && !result.Failed() {
+			receipt.ContractAddress = crypto.CreateAddress(msg.From(), msg.Nonce())
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		txs[i], senders[i], receipts[i], results[i] = tx, msg.From(), receipt, callResult
+	}
+
+	header.GasUsed = gasUsed
+	header.Root = sim.state.IntermediateRoot(sim.config.IsEIP158(header.Number))
+	simulated := types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	blockHash := simulated.Hash()
+	for i, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			log.BlockHash = blockHash
+			log.BlockNumber = header.Number.Uint64()
+			log.TxHash = txs[i].Hash()
+		}
+	}
+
+	fields, err := RPCMarshalBlock(simulated, true, sim.fullTx, sim.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sim.fullTx {
+		// Simulated transactions are not signed, so their senders cannot be recovered.
+		for i, tx := range fields["transactions"].([]interface{}) {
+			if rpcTx, ok := tx.(*RPCTransaction); ok {
+				rpcTx.From = senders[i]
+			}
+		}
+	}
+	fields["calls"] = results
+	return fields, simulated, nil
+}
+
+// message returns the unsigned transaction and message of the call [args] in the block of [header],
+// where [gasUsed] has been used by the previous calls of the block. The nonce and gas of the call
+// default to the nonce of the sender and the gas remaining in the block.
+func (sim *simulator) message(args TransactionArgs, header *types.Header, gasUsed uint64) (*types.Transaction, types.Message, error) {
+	if args.From == nil {
+		args.From = new(common.Address)
+	}
+	if args.Nonce == nil {
+		nonce := hexutil.Uint64(sim.state.GetNonce(*args.From))
+		args.Nonce = &nonce
+	}
+	if gasUsed >= header.GasLimit {
+		return nil, types.Message{}, core.ErrGasLimitReached
+	}
+	if args.Gas == nil {
+		gas := hexutil.Uint64(header.GasLimit - gasUsed)
+		args.Gas = &gas
+	} else if uint64(*args.Gas) > header.GasLimit-gasUsed {
+		return nil, types.Message{}, core.ErrGasLimitReached
+	}
+	if sim.gasCap != 0 && uint64(*args.Gas) > sim.gasCap {
+		return nil, types.Message{}, fmt.Errorf("gas cap of the simulation exceeded: %d > %d", *args.Gas, sim.gasCap)
+	}
+	args.ChainID = (*hexutil.Big)(sim.config.ChainID)
+	msg, err := args.ToMessage(0, header.BaseFee)
+	if err != nil {
+		return nil, types.Message{}, err
+	}
+	if sim.validation {
+		// Check the nonce, balance and fees of the call as for a transaction of the block.
+		msg = types.NewMessage(msg.From(), msg.To(), uint64(*args.Nonce), msg.Value(), msg.Gas(), msg.GasPrice(), msg.GasFeeCap(), msg.GasTipCap(), msg.Data(), msg.AccessList(), false).
+			WithSetCodeAuthorizations(msg.SetCodeAuthorizations())
+	}
+	if args.GasPrice == nil && args.MaxFeePerGas == nil {
+		args.GasPrice = (*hexutil.Big)(msg.GasPrice())
+	}
+	if args.MaxFeePerGas != nil && args.MaxPriorityFeePerGas == nil {
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(msg.GasTipCap())
+	}
+	return args.toTransaction(), msg, nil
+}
@@ -0,0 +1,92 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// governancePrecompiles are the precompiles whose admins may use the gas reserved for governance
+// transactions, since calling them is how a chain remediates fee spikes and spam attacks.
+var governancePrecompiles = map[common.Address]struct{}{
+	precompile.FeeConfigManagerAddress:          {},
+	precompile.TxAllowListAddress:               {},
+	precompile.ContractDeployerAllowListAddress: {},
+	precompile.AddressBlocklistAddress:          {},
+	precompile.RewardManagerAddress:             {},
+}
+
+// isGovernanceTx returns true if [tx] from [sender] calls an enabled governance precompile of
+// which [sender] is an admin.
+func isGovernanceTx(config *params.ChainConfig, state precompile.StateDB, header *types.Header, sender common.Address, tx *types.Transaction) bool {
+	to := tx.To()
+	if to == nil {
+		return false
+	}
+	if _, ok := governancePrecompiles[*to]; !ok {
+		return false
+	}
+	if !config.IsPrecompileEnabled(*to, header.Number, new(big.Int).SetUint64(header.Time)) {
+		return false
+	}
+	return precompile.GetAllowListRole(state, *to, sender).IsAdmin()
+}
+
+// splitGovernanceTxs removes the governance transactions from [pending] and returns them.
+// For each account, only the leading governance transactions are removed, so that the
+// transactions of an account are still committed in nonce order.
+func splitGovernanceTxs(config *params.ChainConfig, state precompile.StateDB, header *types.Header, pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	governanceTxs := make(map[common.Address]types.Transactions)
+	for addr, txs := range pending {
+		n := 0
+		for n < len(txs) && isGovernanceTx(config, state, header, addr, txs[n]) {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		governanceTxs[addr] = txs[:n]
+		if n == len(txs) {
+			delete(pending, addr)
+		} else {
+			pending[addr] = txs[n:]
+		}
+	}
+	return governanceTxs
+}
+
+// restoreGovernanceTxs returns the transactions of [governanceTxs] that were not committed within the gas
+// reserved for them to [pending], ahead of the other transactions of their account, so that transactions
+// that did not fit in the reservation (e.g. with a gas limit above it) compete for the rest of the block.
+func restoreGovernanceTxs(state precompile.StateDB, governanceTxs map[common.Address]types.Transactions, pending map[common.Address]types.Transactions) {
+	for addr, txs := range governanceTxs {
+		nonce := state.GetNonce(addr)
+		n := 0
+		for n < len(txs) && txs[n].Nonce() < nonce {
+			n++
+		}
+		if n == len(txs) {
+			continue
+		}
+		pending[addr] = append(txs[n:len(txs):len(txs)], pending[addr]...)
+	}
+}
+
+// commitReservedTransactions commits [txs] using at most [reserved] gas of the block.
+func (w *worker) commitReservedTransactions(env *environment, txs TransactionSet, coinbase common.Address, reserved uint64) {
+	available := env.gasPool.Gas()
+	if reserved >= available {
+		w.commitTransactions(env, txs, coinbase)
+		return
+	}
+	env.gasPool = new(core.GasPool).AddGas(reserved)
+	w.commitTransactions(env, txs, coinbase)
+	env.gasPool.AddGas(available - reserved)
+}
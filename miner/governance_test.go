@@ -0,0 +1,84 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitGovernanceTxs(t *testing.T) {
+	admin := common.HexToAddress("0x01")
+	other := common.HexToAddress("0x02")
+	config := *params.TestChainConfig
+	config.PrecompileUpgrade = params.NewPrecompileUpgrade(precompile.NewFeeManagerConfig(big.NewInt(0), []common.Address{admin}, nil, nil))
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	precompile.SetAllowListRole(statedb, precompile.FeeConfigManagerAddress, admin, precompile.AllowListAdmin)
+	header := &types.Header{Number: big.NewInt(1), Time: 1}
+
+	newTx := func(nonce uint64, to common.Address) *types.Transaction {
+		return types.NewTransaction(nonce, to, common.Big0, 100000, common.Big1, nil)
+	}
+	adminTxs := types.Transactions{
+		newTx(0, precompile.FeeConfigManagerAddress),
+		newTx(1, precompile.FeeConfigManagerAddress),
+		newTx(2, other),
+		newTx(3, precompile.FeeConfigManagerAddress),
+	}
+	otherTxs := types.Transactions{
+		newTx(0, precompile.FeeConfigManagerAddress),
+	}
+	pending := map[common.Address]types.Transactions{
+		admin: adminTxs,
+		other: otherTxs,
+	}
+
+	// Only the leading governance transactions of admins are split off.
+	governanceTxs := splitGovernanceTxs(&config, statedb, header, pending)
+	require.Equal(t, map[common.Address]types.Transactions{admin: adminTxs[:2]}, governanceTxs)
+	require.Equal(t, map[common.Address]types.Transactions{admin: adminTxs[2:], other: otherTxs}, pending)
+
+	// Transactions to a disabled precompile are not governance transactions.
+	config.PrecompileUpgrade = params.PrecompileUpgrade{}
+	pending = map[common.Address]types.Transactions{admin: adminTxs}
+	require.Empty(t, splitGovernanceTxs(&config, statedb, header, pending))
+	require.Equal(t, map[common.Address]types.Transactions{admin: adminTxs}, pending)
+}
+
+func TestRestoreGovernanceTxs(t *testing.T) {
+	admin := common.HexToAddress("0x01")
+	other := common.HexToAddress("0x02")
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	newTx := func(nonce uint64, to common.Address) *types.Transaction {
+		return types.NewTransaction(nonce, to, common.Big0, 100000, common.Big1, nil)
+	}
+	adminTxs := types.Transactions{
+		newTx(0, precompile.FeeConfigManagerAddress),
+		newTx(1, precompile.FeeConfigManagerAddress),
+		newTx(2, other),
+	}
+	otherTxs := types.Transactions{
+		newTx(0, precompile.TxAllowListAddress),
+	}
+	pending := map[common.Address]types.Transactions{admin: adminTxs[2:]}
+	governanceTxs := map[common.Address]types.Transactions{admin: adminTxs[:2], other: otherTxs}
+
+	// The first governance transaction of admin was committed within the reservation, while the second
+	// one and the governance transaction of other did not fit in it.
+	statedb.SetNonce(admin, 1)
+	restoreGovernanceTxs(statedb, governanceTxs, pending)
+	require.Equal(t, map[common.Address]types.Transactions{admin: adminTxs[1:], other: otherTxs}, pending)
+}
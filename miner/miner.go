@@ -28,6 +28,9 @@
 package miner
 
 import (
+	"context"
+	"time"
+
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 	"github.com/ava-labs/subnet-evm/consensus"
 	"github.com/ava-labs/subnet-evm/core"
@@ -46,6 +49,11 @@ type Backend interface {
 // Config is the configuration parameters of mining.
 type Config struct {
 	Etherbase common.Address `toml:",omitempty"` // Public address for block mining rewards (default = first account)
+
+	GovernanceGasReservation uint64 `toml:",omitempty"` // Gas of each block reserved for governance transactions (0 = disabled)
+
+	MaxBuildDuration time.Duration `toml:",omitempty"` // Duration after which no more transactions are committed to the block being built (0 = unbounded)
+	TxOrderer        TxOrderer     `toml:"-"`          // Ordering of the pending transactions committed to the blocks built (by price if nil)
 }
 
 type Miner struct {
@@ -62,8 +70,14 @@ func (miner *Miner) SetEtherbase(addr common.Address) {
 	miner.worker.setEtherbase(addr)
 }
 
-func (miner *Miner) GenerateBlock() (*types.Block, error) {
-	return miner.worker.commitNewWork()
+func (miner *Miner) GenerateBlock(ctx context.Context) (*types.Block, error) {
+	return miner.worker.commitNewWork(ctx, nil)
+}
+
+// GenerateBlockWithPChainHeight generates a block recording [pChainHeight] as the P-chain height
+// provided by the proposervm.
+func (miner *Miner) GenerateBlockWithPChainHeight(ctx context.Context, pChainHeight uint64) (*types.Block, error) {
+	return miner.worker.commitNewWork(ctx, &pChainHeight)
 }
 
 // SubscribePendingLogs starts delivering logs from pending transactions
@@ -71,3 +85,9 @@ func (miner *Miner) GenerateBlock() (*types.Block, error) {
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
 	return miner.worker.pendingLogsFeed.Subscribe(ch)
 }
+
+// SubscribePendingBlock starts delivering the blocks built by the miner, before
+// they are issued to consensus, to the given channel.
+func (miner *Miner) SubscribePendingBlock(ch chan<- core.PendingBlockEvent) event.Subscription {
+	return miner.worker.pendingBlockFeed.Subscribe(ch)
+}
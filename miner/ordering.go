@@ -0,0 +1,245 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// PriceTxOrdering commits the transactions paying the highest tip first.
+	PriceTxOrdering = "price"
+	// FIFOTxOrdering commits the transactions in the order they were first seen.
+	FIFOTxOrdering = "fifo"
+	// RoundRobinTxOrdering commits one transaction of each account in turn, starting
+	// with the accounts whose next transaction was seen first.
+	RoundRobinTxOrdering = "round-robin"
+)
+
+var (
+	_ TransactionSet = &types.TransactionsByPriceAndNonce{}
+	_ TransactionSet = &fifoTransactions{}
+	_ TransactionSet = &roundRobinTransactions{}
+
+	txOrderers = map[string]TxOrderer{
+		PriceTxOrdering: TxOrdererFunc(func(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet {
+			return types.NewTransactionsByPriceAndNonce(signer, txs, baseFee)
+		}),
+		FIFOTxOrdering: TxOrdererFunc(func(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet {
+			return newFIFOTransactions(signer, txs, baseFee)
+		}),
+		RoundRobinTxOrdering: TxOrdererFunc(func(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet {
+			return newRoundRobinTransactions(signer, txs, baseFee)
+		}),
+	}
+	txOrderersLock sync.RWMutex
+)
+
+// TransactionSet is a set of pending transactions, committed to a block in the order
+// it returns them. The transactions of each account must be returned in nonce order.
+type TransactionSet interface {
+	// Peek returns the next transaction to commit, or nil if the set is exhausted.
+	Peek() *types.Transaction
+	// Shift replaces the current transaction with the next transaction of the same account.
+	Shift()
+	// Pop removes the current transaction along with the remaining transactions of the same
+	// account, since they cannot be committed without it.
+	Pop()
+}
+
+// TxOrderer is a policy ordering the pending transactions committed to the blocks built by
+// the miner. Subnets can plug their own policy with [RegisterTxOrderer].
+type TxOrderer interface {
+	// NewTransactionSet returns the set of [txs], which are sorted by nonce for each account,
+	// to commit to a block with [baseFee]. The set may take ownership of [txs].
+	NewTransactionSet(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet
+}
+
+// TxOrdererFunc is a function implementing [TxOrderer].
+type TxOrdererFunc func(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet
+
+// NewTransactionSet implements [TxOrderer].
+func (f TxOrdererFunc) NewTransactionSet(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet {
+	return f(signer, txs, baseFee)
+}
+
+// RegisterTxOrderer registers [orderer] under [name], so that it can be selected as the
+// transaction ordering of the miner. Panics if [name] is already registered, since
+// orderers are typically registered during init.
+func RegisterTxOrderer(name string, orderer TxOrderer) {
+	txOrderersLock.Lock()
+	defer txOrderersLock.Unlock()
+
+	if _, ok := txOrderers[name]; ok {
+		panic(fmt.Sprintf("tx orderer %q is already registered", name))
+	}
+	txOrderers[name] = orderer
+}
+
+// GetTxOrderer returns the orderer registered under [name], if any.
+func GetTxOrderer(name string) (TxOrderer, bool) {
+	txOrderersLock.RLock()
+	defer txOrderersLock.RUnlock()
+
+	orderer, ok := txOrderers[name]
+	return orderer, ok
+}
+
+// payable returns true if [tx] pays at least [baseFee].
+func payable(tx *types.Transaction, baseFee *big.Int) bool {
+	_, err := types.NewTxWithMinerFee(tx, baseFee)
+	return err == nil
+}
+
+// firstSeenBefore returns true if [a] was seen before [b], breaking ties by hash so that
+// the order is deterministic.
+func firstSeenBefore(a, b *types.Transaction) bool {
+	if !a.FirstSeen().Equal(b.FirstSeen()) {
+		return a.FirstSeen().Before(b.FirstSeen())
+	}
+	aHash, bHash := a.Hash(), b.Hash()
+	return bytes.Compare(aHash[:], bHash[:]) < 0
+}
+
+// txsByFirstSeen implements the heap interface, sorting transactions by the time they
+// were first seen.
+type txsByFirstSeen []*types.Transaction
+
+func (s txsByFirstSeen) Len() int           { return len(s) }
+func (s txsByFirstSeen) Less(i, j int) bool { return firstSeenBefore(s[i], s[j]) }
+func (s txsByFirstSeen) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (s *txsByFirstSeen) Push(x interface{}) {
+	*s = append(*s, x.(*types.Transaction))
+}
+
+func (s *txsByFirstSeen) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
+
+// fifoTransactions is a [TransactionSet] returning the transactions in the order they were
+// first seen, among the next transactions of each account.
+type fifoTransactions struct {
+	txs     map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads   txsByFirstSeen                        // Next transaction for each unique account
+	signer  types.Signer
+	baseFee *big.Int
+}
+
+func newFIFOTransactions(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) *fifoTransactions {
+	heads := make(txsByFirstSeen, 0, len(txs))
+	for from, accTxs := range txs {
+		// Remove the account if the sender doesn't match or it cannot pay the base fee
+		if acc, _ := types.Sender(signer, accTxs[0]); acc != from || !payable(accTxs[0], baseFee) {
+			delete(txs, from)
+			continue
+		}
+		heads = append(heads, accTxs[0])
+		txs[from] = accTxs[1:]
+	}
+	heap.Init(&heads)
+	return &fifoTransactions{
+		txs:     txs,
+		heads:   heads,
+		signer:  signer,
+		baseFee: baseFee,
+	}
+}
+
+// Peek implements [TransactionSet].
+func (t *fifoTransactions) Peek() *types.Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift implements [TransactionSet].
+func (t *fifoTransactions) Shift() {
+	acc, _ := types.Sender(t.signer, t.heads[0])
+	if txs := t.txs[acc]; len(txs) > 0 && payable(txs[0], t.baseFee) {
+		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+		heap.Fix(&t.heads, 0)
+		return
+	}
+	heap.Pop(&t.heads)
+}
+
+// Pop implements [TransactionSet].
+func (t *fifoTransactions) Pop() {
+	heap.Pop(&t.heads)
+}
+
+// roundRobinTransactions is a [TransactionSet] returning one transaction of each account in
+// turn, so that no account can fill a block while others wait.
+type roundRobinTransactions struct {
+	txs     map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads   map[common.Address]*types.Transaction // Next transaction for each unique account
+	turns   []common.Address                      // Accounts in the order of their next turn
+	baseFee *big.Int
+}
+
+func newRoundRobinTransactions(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) *roundRobinTransactions {
+	heads := make(map[common.Address]*types.Transaction, len(txs))
+	turns := make([]common.Address, 0, len(txs))
+	for from, accTxs := range txs {
+		// Remove the account if the sender doesn't match or it cannot pay the base fee
+		if acc, _ := types.Sender(signer, accTxs[0]); acc != from || !payable(accTxs[0], baseFee) {
+			delete(txs, from)
+			continue
+		}
+		heads[from] = accTxs[0]
+		txs[from] = accTxs[1:]
+		turns = append(turns, from)
+	}
+	// The first round follows the order in which the transactions were seen
+	sort.Slice(turns, func(i, j int) bool {
+		return firstSeenBefore(heads[turns[i]], heads[turns[j]])
+	})
+	return &roundRobinTransactions{
+		txs:     txs,
+		heads:   heads,
+		turns:   turns,
+		baseFee: baseFee,
+	}
+}
+
+// Peek implements [TransactionSet].
+func (t *roundRobinTransactions) Peek() *types.Transaction {
+	if len(t.turns) == 0 {
+		return nil
+	}
+	return t.heads[t.turns[0]]
+}
+
+// Shift implements [TransactionSet]. The account of the current transaction takes its
+// next turn after all the other accounts.
+func (t *roundRobinTransactions) Shift() {
+	acc := t.turns[0]
+	t.turns = t.turns[1:]
+	if txs := t.txs[acc]; len(txs) > 0 && payable(txs[0], t.baseFee) {
+		t.heads[acc], t.txs[acc] = txs[0], txs[1:]
+		t.turns = append(t.turns, acc)
+		return
+	}
+	delete(t.heads, acc)
+}
+
+// Pop implements [TransactionSet].
+func (t *roundRobinTransactions) Pop() {
+	delete(t.heads, t.turns[0])
+	t.turns = t.turns[1:]
+}
@@ -0,0 +1,114 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxOrderings(t *testing.T) {
+	var (
+		signer  = types.LatestSignerForChainID(big.NewInt(1))
+		baseFee = big.NewInt(10)
+		start   = time.Unix(1000, 0)
+		keys    = make([]*ecdsa.PrivateKey, 3)
+		addrs   = make([]common.Address, 3)
+	)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i], addrs[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	// newTx returns a transaction of the [account]th key first seen after [seen] seconds
+	newTx := func(account int, nonce uint64, tip int64, seen int) *types.Transaction {
+		tx := types.MustSignNewTx(keys[account], signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(tip),
+			GasFeeCap: big.NewInt(100),
+			Gas:       21000,
+		})
+		tx.SetFirstSeen(start.Add(time.Duration(seen) * time.Second))
+		return tx
+	}
+	// The first account sends the most transactions, with the highest tips, but last
+	var (
+		a0 = newTx(0, 0, 30, 3)
+		a1 = newTx(0, 1, 30, 4)
+		a2 = newTx(0, 2, 30, 5)
+		b0 = newTx(1, 0, 20, 1)
+		b1 = newTx(1, 1, 20, 6)
+		c0 = newTx(2, 0, 10, 2)
+	)
+	pending := func() map[common.Address]types.Transactions {
+		return map[common.Address]types.Transactions{
+			addrs[0]: {a0, a1, a2},
+			addrs[1]: {b0, b1},
+			addrs[2]: {c0},
+		}
+	}
+	// commitAll returns the transactions of [set] in the order they are committed
+	commitAll := func(set TransactionSet) []*types.Transaction {
+		var committed []*types.Transaction
+		for tx := set.Peek(); tx != nil; tx = set.Peek() {
+			committed = append(committed, tx)
+			set.Shift()
+		}
+		return committed
+	}
+
+	tests := map[string][]*types.Transaction{
+		PriceTxOrdering:      {a0, a1, a2, b0, b1, c0},
+		FIFOTxOrdering:       {b0, c0, a0, a1, a2, b1},
+		RoundRobinTxOrdering: {b0, c0, a0, b1, a1, a2},
+	}
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			orderer, ok := GetTxOrderer(name)
+			require.True(t, ok)
+			require.Equal(t, expected, commitAll(orderer.NewTransactionSet(signer, pending(), baseFee)))
+
+			// Popping a transaction skips the remaining transactions of its account
+			set := orderer.NewTransactionSet(signer, pending(), baseFee)
+			var committed []*types.Transaction
+			for tx := set.Peek(); tx != nil; tx = set.Peek() {
+				committed = append(committed, tx)
+				if tx == a1 {
+					set.Pop()
+				} else {
+					set.Shift()
+				}
+			}
+			require.NotContains(t, committed, a2)
+			require.Len(t, committed, len(expected)-1)
+
+			// Accounts that cannot pay the base fee are skipped
+			set = orderer.NewTransactionSet(signer, pending(), big.NewInt(1000))
+			require.Nil(t, set.Peek())
+		})
+	}
+}
+
+func TestRegisterTxOrderer(t *testing.T) {
+	orderer := TxOrdererFunc(func(signer types.Signer, txs map[common.Address]types.Transactions, baseFee *big.Int) TransactionSet {
+		return types.NewTransactionsByPriceAndNonce(signer, txs, baseFee)
+	})
+	name := "test-register-tx-orderer"
+	_, ok := GetTxOrderer(name)
+	require.False(t, ok)
+
+	RegisterTxOrderer(name, orderer)
+	_, ok = GetTxOrderer(name)
+	require.True(t, ok)
+	require.Panics(t, func() { RegisterTxOrderer(name, orderer) })
+	require.Panics(t, func() { RegisterTxOrderer(PriceTxOrdering, orderer) })
+}
@@ -30,6 +30,7 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -44,9 +45,11 @@ import (
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -66,8 +69,12 @@ type environment struct {
 	txs      []*types.Transaction
 	receipts []*types.Receipt
 	size     common.StorageSize
+	blobs    uint64 // number of blobs referenced by the blob transactions of the block
 
-	start time.Time // Time that block building began
+	start    time.Time // Time that block building began
+	deadline time.Time // Time after which no more transactions are committed (none if zero)
+
+	ctx context.Context // Context holding the span of the block building
 }
 
 // worker is the main object which takes care of submitting new work to consensus engine
@@ -81,7 +88,8 @@ type worker struct {
 
 	// Feeds
 	// TODO remove since this will never be written to
-	pendingLogsFeed event.Feed
+	pendingLogsFeed  event.Feed
+	pendingBlockFeed event.Feed
 
 	// Subscriptions
 	mux      *event.TypeMux // TODO replace
@@ -112,10 +120,14 @@ func (w *worker) setEtherbase(addr common.Address) {
 }
 
 // commitNewWork generates several new sealing tasks based on the parent block.
-func (w *worker) commitNewWork() (*types.Block, error) {
+// [pChainHeight] is the P-chain height provided by the proposervm, if any.
+func (w *worker) commitNewWork(ctx context.Context, pChainHeight *uint64) (*types.Block, error) {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
+	ctx, span := tracing.Start(ctx, "worker.commitNewWork")
+	defer span.End()
+
 	tstart := w.clock.Time()
 	timestamp := uint64(tstart.Unix())
 	parent := w.chain.CurrentBlock()
@@ -158,6 +170,9 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 			return nil, fmt.Errorf("failed to calculate new base fee: %w", err)
 		}
 	}
+	if w.chainConfig.IsValidatorInfo(header.Number, bigTimestamp) {
+		header.Extra = dummy.AppendPChainHeight(header.Extra, dummy.CalcPChainHeight(w.chainConfig, parent.Header(), pChainHeight))
+	}
 
 	if w.coinbase == (common.Address{}) {
 		return nil, errors.New("cannot mine without etherbase")
@@ -185,11 +200,42 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new current environment: %w", err)
 	}
-	// Configure any stateful precompiles that should go into effect during this block.
+	env.ctx = ctx
+	// Configure any stateful precompiles that should go into effect during this block, and apply its state patch.
 	w.chainConfig.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time()), types.NewBlockWithHeader(header), env.state)
+	if err := w.chainConfig.ApplyStatePatch(parent.Root(), types.NewBlockWithHeader(header), env.state); err != nil {
+		return nil, fmt.Errorf("failed to apply state patch: %w", err)
+	}
+	if err := core.ApplyValidatorSnapshot(w.chainConfig, header, env.state); err != nil {
+		return nil, fmt.Errorf("failed to apply validator snapshot: %w", err)
+	}
+
+	if w.config.MaxBuildDuration > 0 {
+		env.deadline = time.Now().Add(w.config.MaxBuildDuration)
+	}
 
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(true)
+	orderer := w.config.TxOrderer
+	if orderer == nil {
+		orderer = txOrderers[PriceTxOrdering]
+	}
+
+	// Commit the governance transactions first, within the gas reserved for them, so that
+	// remediation transactions cannot be crowded out by fee spikes or spam. The ones that
+	// do not fit in the reservation are committed along with the other transactions.
+	if w.config.GovernanceGasReservation > 0 {
+		if governanceTxs := splitGovernanceTxs(w.chainConfig, env.state, header, pending); len(governanceTxs) > 0 {
+			// Transaction sets consume the map they are built from, so keep [governanceTxs] to restore the leftovers.
+			reserved := make(map[common.Address]types.Transactions, len(governanceTxs))
+			for addr, txs := range governanceTxs {
+				reserved[addr] = txs
+			}
+			txs := orderer.NewTransactionSet(env.signer, reserved, header.BaseFee)
+			w.commitReservedTransactions(env, txs, header.Coinbase, w.config.GovernanceGasReservation)
+			restoreGovernanceTxs(env.state, governanceTxs, pending)
+		}
+	}
 
 	// Split the pending transactions into locals and remotes
 	localTxs := make(map[common.Address]types.Transactions)
@@ -201,14 +247,15 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, header.BaseFee)
+		txs := orderer.NewTransactionSet(env.signer, localTxs, header.BaseFee)
 		w.commitTransactions(env, txs, header.Coinbase)
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, header.BaseFee)
+		txs := orderer.NewTransactionSet(env.signer, remoteTxs, header.BaseFee)
 		w.commitTransactions(env, txs, header.Coinbase)
 	}
 
+	span.SetAttributes(attribute.Int("txs", env.tcount), attribute.Int64("gasUsed", int64(env.header.GasUsed)))
 	return w.commit(env)
 }
 
@@ -231,7 +278,11 @@ func (w *worker) createCurrentEnvironment(parent *types.Block, header *types.Hea
 func (w *worker) commitTransaction(env *environment, tx *types.Transaction, coinbase common.Address) ([]*types.Log, error) {
 	snap := env.state.Snapshot()
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, *w.chain.GetVMConfig())
+	vmConfig := *w.chain.GetVMConfig()
+	if tracing.Enabled() {
+		vmConfig.TraceContext = env.ctx
+	}
+	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed, vmConfig)
 	if err != nil {
 		env.state.RevertToSnapshot(snap)
 		return nil, err
@@ -239,12 +290,18 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction, coin
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
 	env.size += tx.Size()
+	env.blobs += uint64(len(tx.BlobHashes()))
 
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, coinbase common.Address) {
+func (w *worker) commitTransactions(env *environment, txs TransactionSet, coinbase common.Address) {
 	for {
+		// If the block building deadline has passed, build the block with the transactions committed so far
+		if !env.deadline.IsZero() && time.Now().After(env.deadline) {
+			log.Debug("Block building deadline reached", "txs", env.tcount, "gas", env.header.GasUsed)
+			break
+		}
 		// If we don't have enough gas for any further transactions then we're done
 		if env.gasPool.Gas() < params.TxGas {
 			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
@@ -255,6 +312,33 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		if tx == nil {
 			break
 		}
+		// Skip conditional transactions whose preconditions are not satisfied by the block,
+		// dropping them from the pool once they can no longer be satisfied
+		if cond := tx.Conditional(); cond != nil {
+			if err := cond.CheckBlock(env.header.Number, env.header.Time); err != nil {
+				log.Trace("Skipping conditional transaction outside of its block bounds", "hash", tx.Hash(), "err", err)
+				if cond.Expired(env.header.Number, env.header.Time) {
+					w.eth.TxPool().RemoveTx(tx.Hash())
+				}
+				txs.Pop()
+				continue
+			}
+			if err := env.state.CheckTransactionConditional(cond); err != nil {
+				log.Trace("Dropping conditional transaction with unsatisfied known accounts", "hash", tx.Hash(), "err", err)
+				w.eth.TxPool().RemoveTx(tx.Hash())
+				txs.Pop()
+				continue
+			}
+		}
+		// Blocks include blob transactions without their sidecar
+		tx = tx.WithoutBlobTxSidecar()
+		// Skip blob transactions referencing more blobs than the block has room left for
+		if blobs := uint64(len(tx.BlobHashes())); blobs > 0 && env.blobs+blobs > params.BlobTxMaxBlobsPerBlock {
+			log.Trace("Skipping blob transaction that would exceed the blobs per block", "hash", tx.Hash(), "blobs", blobs, "blockBlobs", env.blobs)
+
+			txs.Pop()
+			continue
+		}
 		// Abort transaction if it won't fit in the block and continue to search for a smaller
 		// transction that will fit.
 		if totalTxsSize := env.size + tx.Size(); totalTxsSize > targetTxsSize {
@@ -366,6 +450,7 @@ func (w *worker) handleResult(env *environment, block *types.Block, createdAt ti
 	// Note: the miner no longer emits a NewMinedBlock event. Instead the caller
 	// is responsible for running any additional verification and then inserting
 	// the block with InsertChain, which will also emit a new head event.
+	w.pendingBlockFeed.Send(core.PendingBlockEvent{Block: block, Receipts: receipts})
 	return block, nil
 }
 
@@ -0,0 +1,37 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import "fmt"
+
+// BlobDataMode selects how the nodes of a subnet handle the data attached to EIP-4844 blob
+// transactions. Subnets have no data availability layer: blocks only include the transactions
+// without their sidecars, so whichever the mode, blob transactions execute against the versioned
+// hashes of their blobs alone.
+type BlobDataMode string
+
+const (
+	// BlobDataReject accepts blob transactions without their sidecar only. Rollups post their data
+	// elsewhere and only use the versioned hashes on chain.
+	BlobDataReject BlobDataMode = "reject"
+)
+
+// unsupportedBlobDataModes are the modes retaining the sidecars of blob transactions, which are not
+// supported until the KZG proofs of the sidecars are verified against their blobs.
+var unsupportedBlobDataModes = map[BlobDataMode]struct{}{
+	"local": {},
+	"kzg":   {},
+}
+
+// Verify returns an error if [m] is not a supported mode. The empty mode defaults to [BlobDataReject].
+func (m BlobDataMode) Verify() error {
+	switch m {
+	case "", BlobDataReject:
+		return nil
+	}
+	if _, ok := unsupportedBlobDataModes[m]; ok {
+		return fmt.Errorf("blob data mode %q is not supported, since the KZG proofs of blob sidecars are not verified", string(m))
+	}
+	return fmt.Errorf("unknown blob data mode %q, expected %q", string(m), BlobDataReject)
+}
@@ -31,6 +31,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/subnet-evm/commontype"
@@ -52,6 +53,10 @@ var (
 	ExtraDataSize        = 80
 	RollupWindow  uint64 = 10
 
+	// PChainHeightExtraDataSize is the size of the P-chain height appended to the extra data
+	// of headers while the validator info precompile is enabled.
+	PChainHeightExtraDataSize = 8
+
 	DefaultFeeConfig = commontype.FeeConfig{
 		GasLimit:        big.NewInt(8_000_000),
 		TargetBlockRate: 2, // in seconds
@@ -104,7 +109,7 @@ var (
 		PetersburgBlock:     big.NewInt(0),
 		IstanbulBlock:       big.NewInt(0),
 		MuirGlacierBlock:    big.NewInt(0),
-		NetworkUpgrades:     NetworkUpgrades{big.NewInt(0)},
+		NetworkUpgrades:     NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0)},
 		PrecompileUpgrade:   PrecompileUpgrade{},
 		UpgradeConfig:       UpgradeConfig{},
 	}
@@ -141,6 +146,7 @@ type ChainConfig struct {
 	ChainID            *big.Int             `json:"chainId"`                      // chainId identifies the current chain and is used for replay protection
 	FeeConfig          commontype.FeeConfig `json:"feeConfig"`                    // Set the configuration for the dynamic fee algorithm
 	AllowFeeRecipients bool                 `json:"allowFeeRecipients,omitempty"` // Allows fees to be collected by block builders.
+	BlobDataMode       BlobDataMode         `json:"blobDataMode,omitempty"`       // Handling of the data attached to blob transactions once BlobTxTimestamp activates (default: reject)
 
 	HomesteadBlock *big.Int `json:"homesteadBlock,omitempty"` // Homestead switch block (nil = no fork, 0 = already homestead)
 
@@ -157,14 +163,59 @@ type ChainConfig struct {
 	IstanbulBlock       *big.Int `json:"istanbulBlock,omitempty"`       // Istanbul switch block (nil = no fork, 0 = already on istanbul)
 	MuirGlacierBlock    *big.Int `json:"muirGlacierBlock,omitempty"`    // Eip-2384 (bomb delay) switch block (nil = no fork, 0 = already activated)
 
-	NetworkUpgrades              // Config for timestamps that enable avalanche network upgrades
-	PrecompileUpgrade            // Config for enabling precompiles from genesis
-	UpgradeConfig     `json:"-"` // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
+	NetworkUpgrades                     // Config for timestamps that enable avalanche network upgrades
+	PrecompileUpgrade PrecompileUpgrade `json:"-"` // Config for enabling precompiles from genesis. Encoded inline by MarshalJSON and UnmarshalJSON.
+	UpgradeConfig     `json:"-"`        // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
+
+	upgrades *atomic.Value // *UpgradeConfig replacing [UpgradeConfig] while the chain is running, see SetUpgradeConfig
+}
+
+// chainConfigJSON is ChainConfig without its JSON methods, used to encode and decode the fields
+// other than the precompile configs enabled from genesis.
+type chainConfigJSON ChainConfig
+
+// MarshalJSON returns the JSON encoding of [c], where the precompile configs enabled from
+// genesis are set at their config keys alongside the other fields.
+func (c ChainConfig) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(chainConfigJSON(c))
+	if err != nil || len(c.PrecompileUpgrade.configs) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, config := range c.PrecompileUpgrade.configs {
+		if fields[key], err = json.Marshal(config); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON parses [data] into [c], including the configs of the registered precompiles.
+// Errors in the value of a field are returned as a [utils.FieldError] qualified by the path of the field.
+func (c *ChainConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*chainConfigJSON)(c)); err != nil {
+		return utils.QualifyJSONError(data, (*chainConfigJSON)(c), err)
+	}
+	return json.Unmarshal(data, &c.PrecompileUpgrade)
+}
+
+// Describe returns the schema of the JSON encoding of the chain config, including the configs
+// of the registered precompiles that can be enabled from genesis.
+func (c *ChainConfig) Describe() *utils.Schema {
+	schema := utils.Describe(chainConfigJSON{})
+	for key, precompileSchema := range (&PrecompileUpgrade{}).Describe().Properties {
+		schema.Properties[key] = precompileSchema
+	}
+	return schema
 }
 
 // UpgradeConfig includes the following configs that may be specified in upgradeBytes:
 // - Timestamps that enable avalanche network upgrades,
-// - Enabling or disabling precompiles as network upgrades.
+// - Enabling or disabling precompiles as network upgrades,
+// - Changing chain parameters that are not part of a precompile (e.g. the fee config).
 type UpgradeConfig struct {
 	// Config for blocks/timestamps that enable network upgrades.
 	// Note: if NetworkUpgrades is specified in the JSON all previously activated
@@ -173,6 +224,12 @@ type UpgradeConfig struct {
 
 	// Config for enabling and disabling precompiles as network upgrades.
 	PrecompileUpgrades []PrecompileUpgrade `json:"precompileUpgrades,omitempty"`
+
+	// Config for changing chain parameters (outside of precompiles) at a timestamp.
+	ParameterUpgrades []ParameterUpgrade `json:"parameterUpgrades,omitempty"`
+
+	// Reviewed changes to the state applied at a block number, to recover from bugs.
+	StatePatches []StatePatch `json:"statePatches,omitempty"`
 }
 
 // AvalancheContext provides Avalanche specific context directly into the EVM.
@@ -195,7 +252,7 @@ func (c *ChainConfig) String() string {
 	if err != nil {
 		precompileUpgradeBytes = []byte("cannot marshal PrecompileUpgrade")
 	}
-	upgradeConfigBytes, err := json.Marshal(c.UpgradeConfig)
+	upgradeConfigBytes, err := json.Marshal(c.Upgrades())
 	if err != nil {
 		upgradeConfigBytes = []byte("cannot marshal UpgradeConfig")
 	}
@@ -272,45 +329,117 @@ func (c *ChainConfig) IsSubnetEVM(blockTimestamp *big.Int) bool {
 	return utils.IsForked(c.getNetworkUpgrades().SubnetEVMTimestamp, blockTimestamp)
 }
 
+// IsBlobTx returns whether [blockTimestamp] is either equal to the BlobTx fork block timestamp or greater.
+func (c *ChainConfig) IsBlobTx(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().BlobTxTimestamp, blockTimestamp)
+}
+
+// IsSetCodeTx returns whether [blockTimestamp] is either equal to the SetCodeTx fork block timestamp or greater.
+func (c *ChainConfig) IsSetCodeTx(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().SetCodeTxTimestamp, blockTimestamp)
+}
+
+// IsEIP6780 returns whether [blockTimestamp] is either equal to the EIP6780 fork block timestamp or greater.
+func (c *ChainConfig) IsEIP6780(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().EIP6780Timestamp, blockTimestamp)
+}
+
+// IsCustomTxType returns whether the transactions of the custom transaction type [txType] are enabled at [blockTimestamp],
+// which requires both Subnet EVM and the activation of the type.
+func (c *ChainConfig) IsCustomTxType(txType uint8, blockTimestamp *big.Int) bool {
+	return c.IsSubnetEVM(blockTimestamp) && utils.IsForked(c.getNetworkUpgrades().CustomTxTypeTimestamps[txType], blockTimestamp)
+}
+
 // PRECOMPILE UPGRADES START HERE
 
-// IsContractDeployerAllowList returns whether [blockTimestamp] is either equal to the ContractDeployerAllowList fork block timestamp or greater.
-func (c *ChainConfig) IsContractDeployerAllowList(blockTimestamp *big.Int) bool {
-	config := c.GetContractDeployerAllowListConfig(blockTimestamp)
+// IsContractDeployerAllowList returns whether the ContractDeployerAllowList precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsContractDeployerAllowList(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetContractDeployerAllowListConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// IsContractNativeMinter returns whether the NativeMinter precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsContractNativeMinter(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetContractNativeMinterConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// IsTxAllowList returns whether the TxAllowList precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsTxAllowList(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetTxAllowListConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// IsFeeConfigManager returns whether the FeeConfigManager precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsFeeConfigManager(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetFeeConfigManagerConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// IsRewardManager returns whether the RewardManager precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsRewardManager(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetRewardManagerConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
 
-// IsContractNativeMinter returns whether [blockTimestamp] is either equal to the NativeMinter fork block timestamp or greater.
-func (c *ChainConfig) IsContractNativeMinter(blockTimestamp *big.Int) bool {
-	config := c.GetContractNativeMinterConfig(blockTimestamp)
+// IsAddressBlocklist returns whether the AddressBlocklist precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsAddressBlocklist(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetAddressBlocklistConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
 
-// IsTxAllowList returns whether [blockTimestamp] is either equal to the TxAllowList fork block timestamp or greater.
-func (c *ChainConfig) IsTxAllowList(blockTimestamp *big.Int) bool {
-	config := c.GetTxAllowListConfig(blockTimestamp)
+// IsChainConfigReader returns whether the ChainConfigReader precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsChainConfigReader(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetChainConfigReaderConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
 
-// IsFeeConfigManager returns whether [blockTimestamp] is either equal to the FeeConfigManager fork block timestamp or greater.
-func (c *ChainConfig) IsFeeConfigManager(blockTimestamp *big.Int) bool {
-	config := c.GetFeeConfigManagerConfig(blockTimestamp)
+// IsNativeAssetBalance returns whether the NativeAssetBalance precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsNativeAssetBalance(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetNativeAssetBalanceConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
 
-// IsRewardManager returns whether [blockTimestamp] is either equal to the RewardManager fork block timestamp or greater.
-func (c *ChainConfig) IsRewardManager(blockTimestamp *big.Int) bool {
-	config := c.GetRewardManagerConfig(blockTimestamp)
+// IsNativeAssetCall returns whether the NativeAssetCall precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsNativeAssetCall(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetNativeAssetCallConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
 
-// ADD YOUR PRECOMPILE HERE
-/*
-func (c *ChainConfig) Is{YourPrecompile}(blockTimestamp *big.Int) bool {
-	config := c.Get{YourPrecompile}Config(blockTimestamp)
+// IsGasSponsor returns whether the GasSponsor precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsGasSponsor(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetGasSponsorConfig(blockNumber, blockTimestamp)
 	return config != nil && !config.Disable
 }
-*/
+
+// IsGasToken returns whether the GasToken precompile is enabled in the block with [blockNumber] and [blockTimestamp],
+// in which case gas is paid in the gas token rather than in the native coin.
+func (c *ChainConfig) IsGasToken(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetGasTokenConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// IsValidatorInfo returns whether the ValidatorInfo precompile is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsValidatorInfo(blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetValidatorInfoConfig(blockNumber, blockTimestamp)
+	return config != nil && !config.Disable
+}
+
+// GetExtraDataSize returns the expected size of the extra data of a header with [blockNumber] and
+// [blockTimestamp] after SubnetEVM. While the validator info precompile is enabled, the P-chain height used to
+// snapshot the validator set is appended to the rollup window.
+func (c *ChainConfig) GetExtraDataSize(blockNumber *big.Int, blockTimestamp *big.Int) int {
+	if c.IsValidatorInfo(blockNumber, blockTimestamp) {
+		return ExtraDataSize + PChainHeightExtraDataSize
+	}
+	return ExtraDataSize
+}
+
+// IsPrecompileEnabled returns whether the precompile at [address] is enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) IsPrecompileEnabled(address common.Address, blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	config := c.GetActivePrecompileConfig(address, blockNumber, blockTimestamp)
+	return config != nil && !config.IsDisabled()
+}
 
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
@@ -334,7 +463,10 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, timest
 // Verify verifies chain config and returns error
 func (c *ChainConfig) Verify() error {
 	if err := c.FeeConfig.Verify(); err != nil {
-		return err
+		return utils.WithFieldPath("feeConfig", err)
+	}
+	if err := c.BlobDataMode.Verify(); err != nil {
+		return utils.WithFieldPath("blobDataMode", err)
 	}
 
 	// Verify the precompile upgrades are internally consistent given the existing chainConfig.
@@ -342,6 +474,16 @@ func (c *ChainConfig) Verify() error {
 		return err
 	}
 
+	// Verify the scheduled parameter upgrades are valid and well ordered.
+	if err := c.verifyParameterUpgrades(); err != nil {
+		return err
+	}
+
+	// Verify the scheduled state patches are valid and well ordered.
+	if err := c.verifyStatePatches(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -398,6 +540,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	lastFork = fork{}
 	for _, cur := range []fork{
 		{name: "subnetEVMTimestamp", block: c.SubnetEVMTimestamp},
+		{name: "blobTxTimestamp", block: c.BlobTxTimestamp}, // required by setCodeTxTimestamp, as Cancun by Prague
+		{name: "setCodeTxTimestamp", block: c.SetCodeTxTimestamp, optional: true},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -417,6 +561,16 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// EIP-6780 is independent of the other upgrades apart from SubnetEVM, so it is
+	// checked separately rather than imposing an order on the upgrades above.
+	if c.EIP6780Timestamp != nil {
+		if c.SubnetEVMTimestamp == nil {
+			return fmt.Errorf("unsupported fork ordering: subnetEVMTimestamp not enabled, but eip6780Timestamp enabled at %v", c.EIP6780Timestamp)
+		}
+		if c.SubnetEVMTimestamp.Cmp(c.EIP6780Timestamp) > 0 {
+			return fmt.Errorf("unsupported fork ordering: subnetEVMTimestamp enabled at %v, but eip6780Timestamp enabled at %v", c.SubnetEVMTimestamp, c.EIP6780Timestamp)
+		}
+	}
 	return nil
 }
 
@@ -461,7 +615,7 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, lastHeight *big.Int,
 
 	// Check subnet-evm specific activations
 	newNetworkUpgrades := newcfg.getNetworkUpgrades()
-	if c.UpgradeConfig.NetworkUpgrades != nil && newcfg.UpgradeConfig.NetworkUpgrades == nil {
+	if c.Upgrades().NetworkUpgrades != nil && newcfg.Upgrades().NetworkUpgrades == nil {
 		// Note: if the current NetworkUpgrades are set via UpgradeConfig, then a new config
 		// without NetworkUpgrades will be treated as having specified an empty set of network
 		// upgrades (ie., treated as the user intends to cancel scheduled forks)
@@ -472,7 +626,17 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, lastHeight *big.Int,
 	}
 
 	// Check that the precompiles on the new config are compatible with the existing precompile config.
-	if err := c.CheckPrecompilesCompatible(newcfg.PrecompileUpgrades, lastTimestamp); err != nil {
+	if err := c.CheckPrecompilesCompatible(newcfg.Upgrades().PrecompileUpgrades, lastHeight, lastTimestamp); err != nil {
+		return err
+	}
+
+	// Check that the parameter upgrades that have already activated are unchanged.
+	if err := c.checkParameterUpgradesCompatible(newcfg.Upgrades().ParameterUpgrades, lastTimestamp); err != nil {
+		return err
+	}
+
+	// Check that the state patches that have already been applied are unchanged.
+	if err := c.checkStatePatchesCompatible(newcfg.Upgrades().StatePatches, lastHeight); err != nil {
 		return err
 	}
 
@@ -483,7 +647,7 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, lastHeight *big.Int,
 // getNetworkUpgrades returns NetworkUpgrades from upgrade config if set there,
 // otherwise it falls back to the genesis chain config.
 func (c *ChainConfig) getNetworkUpgrades() *NetworkUpgrades {
-	if upgradeConfigOverride := c.UpgradeConfig.NetworkUpgrades; upgradeConfigOverride != nil {
+	if upgradeConfigOverride := c.Upgrades().NetworkUpgrades; upgradeConfigOverride != nil {
 		return upgradeConfigOverride
 	}
 	return &c.NetworkUpgrades
@@ -538,15 +702,13 @@ type Rules struct {
 
 	// Rules for Avalanche releases
 	IsSubnetEVM bool
+	IsBlobTx    bool
+	IsSetCodeTx bool
+	IsEIP6780   bool
 
-	// Optional stateful precompile rules
-	IsContractDeployerAllowListEnabled bool
-	IsContractNativeMinterEnabled      bool
-	IsTxAllowListEnabled               bool
-	IsFeeConfigManagerEnabled          bool
-	IsRewardManagerEnabled             bool
-	// ADD YOUR PRECOMPILE HERE
-	// Is{YourPrecompile}Enabled         bool
+	// OpcodeOverrides maps the names of the EVM opcodes overridden by the chain config
+	// to their overrides for this rule set.
+	OpcodeOverrides map[string]OpcodeOverride
 
 	// Precompiles maps addresses to stateful precompiled contracts that are enabled
 	// for this rule set.
@@ -580,30 +742,26 @@ func (c *ChainConfig) AvalancheRules(blockNum, blockTimestamp *big.Int) Rules {
 	rules := c.rules(blockNum)
 
 	rules.IsSubnetEVM = c.IsSubnetEVM(blockTimestamp)
-	rules.IsContractDeployerAllowListEnabled = c.IsContractDeployerAllowList(blockTimestamp)
-	rules.IsContractNativeMinterEnabled = c.IsContractNativeMinter(blockTimestamp)
-	rules.IsTxAllowListEnabled = c.IsTxAllowList(blockTimestamp)
-	rules.IsFeeConfigManagerEnabled = c.IsFeeConfigManager(blockTimestamp)
-	rules.IsRewardManagerEnabled = c.IsRewardManager(blockTimestamp)
-	// ADD YOUR PRECOMPILE HERE
-	// rules.Is{YourPrecompile}Enabled = c.{IsYourPrecompile}(blockTimestamp)
-
-	// Initialize the stateful precompiles that should be enabled at [blockTimestamp].
+	rules.IsBlobTx = c.IsBlobTx(blockTimestamp)
+	rules.IsSetCodeTx = c.IsSetCodeTx(blockTimestamp)
+	rules.IsEIP6780 = c.IsEIP6780(blockTimestamp)
+	rules.OpcodeOverrides = c.OpcodeOverridesAt(blockTimestamp)
+
+	// Initialize the stateful precompiles that should be enabled at [blockNum] and [blockTimestamp].
 	rules.Precompiles = make(map[common.Address]precompile.StatefulPrecompiledContract)
-	for _, config := range c.EnabledStatefulPrecompiles(blockTimestamp) {
-		if config.IsDisabled() {
-			continue
+	for _, module := range precompile.RegisteredModules() {
+		if c.IsPrecompileEnabled(module.Address, blockNum, blockTimestamp) {
+			rules.Precompiles[module.Address] = module.Contract
 		}
-		rules.Precompiles[config.Address()] = config.Contract()
 	}
 
 	return rules
 }
 
-// GetFeeConfig returns the original FeeConfig contained in the genesis ChainConfig.
-// Implements precompile.ChainConfig interface.
-func (c *ChainConfig) GetFeeConfig() commontype.FeeConfig {
-	return c.FeeConfig
+// IsPrecompileEnabled returns whether the precompile at [address] is enabled for this rule set.
+func (r *Rules) IsPrecompileEnabled(address common.Address) bool {
+	_, ok := r.Precompiles[address]
+	return ok
 }
 
 // AllowedFeeRecipients returns the original AllowedFeeRecipients parameter contained in the genesis ChainConfig.
@@ -611,3 +769,16 @@ func (c *ChainConfig) GetFeeConfig() commontype.FeeConfig {
 func (c *ChainConfig) AllowedFeeRecipients() bool {
 	return c.AllowFeeRecipients
 }
+
+// GetChainID returns the chainID contained in the ChainConfig.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) GetChainID() *big.Int {
+	return c.ChainID
+}
+
+// GetSubnetEVMTimestamp returns the timestamp of the SubnetEVM network upgrade, taking
+// upgrade config overrides into account.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) GetSubnetEVMTimestamp() *big.Int {
+	return c.getNetworkUpgrades().SubnetEVMTimestamp
+}
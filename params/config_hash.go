@@ -0,0 +1,126 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// chainConfigCommitment is the canonical encoding of a chain config committed to by its hash.
+type chainConfigCommitment struct {
+	ChainConfig   *ChainConfig  `json:"chainConfig"`
+	UpgradeConfig UpgradeConfig `json:"upgradeConfig"`
+}
+
+// precompileConfigCommitment is the canonical encoding of the precompile configs committed to by
+// PrecompileConfigHashAt.
+type precompileConfigCommitment struct {
+	PrecompileUpgrade  PrecompileUpgrade   `json:"precompileUpgrade"`
+	PrecompileUpgrades []PrecompileUpgrade `json:"precompileUpgrades"`
+}
+
+// Hash returns the canonical hash of [c], which commits to the genesis chain config and to every upgrade
+// of its UpgradeConfig, including the upgrades that have not activated yet. Nodes started with the same
+// genesis and upgrade bytes have the same hash, so that validators with mismatched upgrade configs can be
+// detected before the upgrades activate.
+func (c *ChainConfig) Hash() common.Hash {
+	return hashChainConfig(c, *c.Upgrades())
+}
+
+// HashAt returns the canonical hash of [c] as of the block with [blockNumber] and [blockTimestamp], which
+// commits to the genesis chain config and to the upgrades activated at or before that block. Unlike Hash,
+// it does not depend on upgrades scheduled after the block, so it is the same on every validator accepting
+// the block.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) HashAt(blockNumber *big.Int, blockTimestamp *big.Int) common.Hash {
+	active := *c
+	active.NetworkUpgrades = *c.getNetworkUpgrades()
+	if !utils.IsForked(active.SubnetEVMTimestamp, blockTimestamp) {
+		active.SubnetEVMTimestamp = nil
+	}
+	if !utils.IsForked(active.BlobTxTimestamp, blockTimestamp) {
+		active.BlobTxTimestamp = nil
+	}
+	if !utils.IsForked(active.SetCodeTxTimestamp, blockTimestamp) {
+		active.SetCodeTxTimestamp = nil
+	}
+	if !utils.IsForked(active.EIP6780Timestamp, blockTimestamp) {
+		active.EIP6780Timestamp = nil
+	}
+	active.CustomTxTypeTimestamps = nil
+	for txType, timestamp := range c.getNetworkUpgrades().CustomTxTypeTimestamps {
+		if utils.IsForked(timestamp, blockTimestamp) {
+			if active.CustomTxTypeTimestamps == nil {
+				active.CustomTxTypeTimestamps = make(map[uint8]*big.Int)
+			}
+			active.CustomTxTypeTimestamps[txType] = timestamp
+		}
+	}
+
+	upgrades := c.Upgrades()
+	upgradeConfig := UpgradeConfig{
+		ParameterUpgrades: activatedParameterUpgrades(upgrades.ParameterUpgrades, blockTimestamp),
+		StatePatches:      appliedStatePatches(upgrades.StatePatches, blockNumber),
+	}
+	for _, upgrade := range upgrades.PrecompileUpgrades {
+		if isPrecompileUpgradeForked(upgrade, blockNumber, blockTimestamp) {
+			upgradeConfig.PrecompileUpgrades = append(upgradeConfig.PrecompileUpgrades, upgrade)
+		}
+	}
+	return hashChainConfig(&active, upgradeConfig)
+}
+
+// PrecompileConfigHashAt returns the canonical hash of the precompile configs of [c] activated at or
+// before the block with [blockNumber] and [blockTimestamp], both from genesis and from the upgrade config.
+// Nodes reporting different hashes at the same block disagree on the precompiles executing it.
+func (c *ChainConfig) PrecompileConfigHashAt(blockNumber *big.Int, blockTimestamp *big.Int) common.Hash {
+	commitment := precompileConfigCommitment{PrecompileUpgrade: PrecompileUpgrade{}}
+	for _, config := range c.PrecompileUpgrade.configs {
+		if isPrecompileForked(config, blockNumber, blockTimestamp) {
+			commitment.PrecompileUpgrade.SetConfig(config)
+		}
+	}
+	for _, upgrade := range c.Upgrades().PrecompileUpgrades {
+		if isPrecompileUpgradeForked(upgrade, blockNumber, blockTimestamp) {
+			commitment.PrecompileUpgrades = append(commitment.PrecompileUpgrades, upgrade)
+		}
+	}
+	data, err := json.Marshal(commitment)
+	if err != nil {
+		log.Error("failed to encode precompile configs for hashing", "err", err)
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// isPrecompileUpgradeForked returns true if every config of [upgrade] has activated at or before the block
+// with [blockNumber] and [blockTimestamp].
+func isPrecompileUpgradeForked(upgrade PrecompileUpgrade, blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	for _, config := range upgrade.configs {
+		if !isPrecompileForked(config, blockNumber, blockTimestamp) {
+			return false
+		}
+	}
+	return true
+}
+
+// hashChainConfig returns the keccak256 hash of the JSON encoding of [config] and [upgradeConfig].
+// The encoding is canonical since encoding/json encodes struct fields in order of declaration and
+// map entries in order of their keys.
+// Returns the empty hash if the config cannot be encoded, which may only happen for configs of precompile
+// plugins.
+func hashChainConfig(config *ChainConfig, upgradeConfig UpgradeConfig) common.Hash {
+	data, err := json.Marshal(chainConfigCommitment{ChainConfig: config, UpgradeConfig: upgradeConfig})
+	if err != nil {
+		log.Error("failed to encode chain config for hashing", "err", err)
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(data)
+}
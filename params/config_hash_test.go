@@ -0,0 +1,83 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainConfigHash(t *testing.T) {
+	config := *TestChainConfig
+	config.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(10), nil, nil)),
+			NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(20))),
+		},
+	}
+
+	// The hash does not depend on how the config was obtained.
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	var decoded ChainConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	decoded.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(10), nil, nil)),
+			NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(20))),
+		},
+	}
+	require.Equal(t, config.Hash(), decoded.Hash())
+
+	// The hash commits to upgrades that have not activated yet.
+	other := config
+	other.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: config.PrecompileUpgrades[:1],
+	}
+	require.NotEqual(t, config.Hash(), other.Hash())
+
+	// The hash at a block only commits to the upgrades activated at or before it.
+	require.Equal(t, config.HashAt(big.NewInt(1), big.NewInt(15)), other.HashAt(big.NewInt(1), big.NewInt(15)))
+	require.NotEqual(t, config.HashAt(big.NewInt(1), big.NewInt(20)), other.HashAt(big.NewInt(1), big.NewInt(20)))
+	require.NotEqual(t, config.HashAt(big.NewInt(1), big.NewInt(5)), config.HashAt(big.NewInt(1), big.NewInt(15)))
+
+	// The hash at a block does not depend on network upgrades scheduled after it.
+	scheduled := config
+	scheduled.NetworkUpgrades = NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(100)}
+	unscheduled := config
+	unscheduled.NetworkUpgrades = NetworkUpgrades{}
+	require.Equal(t, scheduled.HashAt(big.NewInt(1), big.NewInt(15)), unscheduled.HashAt(big.NewInt(1), big.NewInt(15)))
+	require.NotEqual(t, scheduled.Hash(), unscheduled.Hash())
+}
+
+func TestPrecompileConfigHashAt(t *testing.T) {
+	config := *TestChainConfig
+	config.PrecompileUpgrade = NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(5), nil, nil))
+	config.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(10), nil, nil)),
+		},
+	}
+	hashes := make(map[common.Hash]struct{})
+	for _, timestamp := range []int64{0, 5, 10} {
+		hashes[config.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(timestamp))] = struct{}{}
+	}
+	require.Len(t, hashes, 3)
+
+	// The hash does not depend on the rest of the chain config
+	other := config
+	other.NetworkUpgrades = NetworkUpgrades{}
+	other.FeeConfig.MinBaseFee = big.NewInt(1)
+	require.Equal(t, config.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(10)), other.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(10)))
+
+	// The hash does not depend on upgrades scheduled after the block
+	other.UpgradeConfig = UpgradeConfig{}
+	require.Equal(t, config.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(5)), other.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(5)))
+	require.NotEqual(t, config.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(10)), other.PrecompileConfigHashAt(big.NewInt(1), big.NewInt(10)))
+}
@@ -0,0 +1,102 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainConfigFieldErrors(t *testing.T) {
+	tests := map[string]struct {
+		data         string
+		upgradeBytes bool
+		expectedPath string
+	}{
+		"invalid fee config field": {
+			data:         `{"chainId":1,"feeConfig":{"gasLimit":"abc"}}`,
+			expectedPath: "feeConfig.gasLimit",
+		},
+		"invalid network upgrade": {
+			data:         `{"chainId":1,"subnetEVMTimestamp":true}`,
+			expectedPath: "subnetEVMTimestamp",
+		},
+		"invalid precompile config field": {
+			data:         `{"chainId":1,"txAllowListConfig":{"blockTimestamp":0,"adminAddresses":["0x12"]}}`,
+			expectedPath: "txAllowListConfig.adminAddresses[0]",
+		},
+		"invalid precompile upgrade field": {
+			data:         `{"precompileUpgrades":[{"txAllowListConfig":{"blockTimestamp":1}},{"feeManagerConfig":{"blockTimestamp":"x"}}]}`,
+			upgradeBytes: true,
+			expectedPath: "precompileUpgrades[1].feeManagerConfig.blockTimestamp",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			if tt.upgradeBytes {
+				var upgradeConfig UpgradeConfig
+				err = json.Unmarshal([]byte(tt.data), &upgradeConfig)
+				err = utils.QualifyJSONError([]byte(tt.data), &upgradeConfig, err)
+			} else {
+				var config ChainConfig
+				err = json.Unmarshal([]byte(tt.data), &config)
+			}
+			var fieldErr *utils.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, tt.expectedPath, fieldErr.Path)
+		})
+	}
+}
+
+func TestChainConfigVerifyFieldErrors(t *testing.T) {
+	config := *TestChainConfig
+	config.FeeConfig.BaseFeeChangeDenominator = big.NewInt(0)
+	require.EqualError(t, config.Verify(), "feeConfig.baseFeeChangeDenominator = 0 cannot be less than or equal to 0")
+
+	invalidFeeConfig := DefaultFeeConfig
+	invalidFeeConfig.GasLimit = big.NewInt(0)
+	config = *TestChainConfig
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		NewPrecompileUpgrade(precompile.NewFeeManagerConfig(big.NewInt(1), nil, nil, &invalidFeeConfig)),
+	}
+	require.EqualError(t, config.Verify(), "precompileUpgrades[0].feeManagerConfig.initialFeeConfig.gasLimit = 0 cannot be less than or equal to 0")
+
+	config = *TestChainConfig
+	config.BlobDataMode = "all"
+	require.EqualError(t, config.Verify(), `blobDataMode is invalid: unknown blob data mode "all", expected "reject"`)
+
+	config.BlobDataMode = "local"
+	require.EqualError(t, config.Verify(), `blobDataMode is invalid: blob data mode "local" is not supported, since the KZG proofs of blob sidecars are not verified`)
+}
+
+func TestChainConfigDescribe(t *testing.T) {
+	schema := (&ChainConfig{}).Describe()
+	require.Equal(t, "object", schema.Type)
+	require.Equal(t, "integer", schema.Properties["chainId"].Type)
+	require.Equal(t, "integer", schema.Properties["subnetEVMTimestamp"].Type)
+	require.NotContains(t, schema.Properties, "precompileUpgrades")
+
+	feeConfig := schema.Properties["feeConfig"]
+	require.Equal(t, "object", feeConfig.Type)
+	require.Equal(t, big.NewInt(0), feeConfig.Properties["baseFeeChangeDenominator"].ExclusiveMinimum)
+
+	// every registered precompile config is described at its config key
+	for _, module := range precompile.RegisteredModules() {
+		precompileSchema, ok := schema.Properties[module.ConfigKey]
+		require.True(t, ok, module.ConfigKey)
+		require.Equal(t, "object", precompileSchema.Type)
+		require.Equal(t, "integer", precompileSchema.Properties["blockTimestamp"].Type)
+	}
+	require.Equal(t, "array", schema.Properties["txAllowListConfig"].Properties["adminAddresses"].Type)
+
+	upgradeSchema := utils.Describe(UpgradeConfig{})
+	require.Equal(t, "array", upgradeSchema.Properties["precompileUpgrades"].Type)
+	require.Contains(t, upgradeSchema.Properties["precompileUpgrades"].Items.Properties, "txAllowListConfig")
+}
@@ -127,6 +127,25 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     0,
 			},
 		},
+		{
+			stored:         &ChainConfig{NetworkUpgrades: NetworkUpgrades{CustomTxTypeTimestamps: map[uint8]*big.Int{0x40: big.NewInt(200)}}},
+			new:            &ChainConfig{NetworkUpgrades: NetworkUpgrades{CustomTxTypeTimestamps: map[uint8]*big.Int{0x40: big.NewInt(300)}}},
+			blockHeight:    10,
+			blockTimestamp: 100,
+			wantErr:        nil,
+		},
+		{
+			stored:         &ChainConfig{},
+			new:            &ChainConfig{NetworkUpgrades: NetworkUpgrades{CustomTxTypeTimestamps: map[uint8]*big.Int{0x40: big.NewInt(50)}}},
+			blockHeight:    10,
+			blockTimestamp: 100,
+			wantErr: &ConfigCompatError{
+				What:         "custom transaction type 0x40 fork block timestamp",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(50),
+				RewindTo:     49,
+			},
+		},
 	}
 
 	for _, test := range tests {
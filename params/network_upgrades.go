@@ -4,12 +4,20 @@
 package params
 
 import (
+	"fmt"
 	"math/big"
 )
 
 // NetworkUpgrades contains timestamps that enable avalanche network upgrades.
 type NetworkUpgrades struct {
 	SubnetEVMTimestamp *big.Int `json:"subnetEVMTimestamp,omitempty"` // A placeholder for the latest avalanche forks (nil = no fork, 0 = already activated)
+	BlobTxTimestamp    *big.Int `json:"blobTxTimestamp,omitempty"`    // Enables EIP-4844 blob transactions and the BLOBHASH opcode (nil = no fork, 0 = already activated)
+	SetCodeTxTimestamp *big.Int `json:"setCodeTxTimestamp,omitempty"` // Enables EIP-7702 set code transactions and code delegation (nil = no fork, 0 = already activated)
+	EIP6780Timestamp   *big.Int `json:"eip6780Timestamp,omitempty"`   // Restricts SELFDESTRUCT to contracts created in the same transaction (nil = no fork, 0 = already activated)
+
+	// CustomTxTypeTimestamps enables the transactions of each registered custom transaction type, keyed by
+	// its envelope type, so that every node of a network registers the type before it activates.
+	CustomTxTypeTimestamps map[uint8]*big.Int `json:"customTxTypeTimestamps,omitempty"`
 }
 
 func (n *NetworkUpgrades) CheckCompatible(newcfg *NetworkUpgrades, headTimestamp *big.Int) *ConfigCompatError {
@@ -17,6 +25,25 @@ func (n *NetworkUpgrades) CheckCompatible(newcfg *NetworkUpgrades, headTimestamp
 	if isForkIncompatible(n.SubnetEVMTimestamp, newcfg.SubnetEVMTimestamp, headTimestamp) {
 		return newCompatError("SubnetEVM fork block timestamp", n.SubnetEVMTimestamp, newcfg.SubnetEVMTimestamp)
 	}
+	if isForkIncompatible(n.BlobTxTimestamp, newcfg.BlobTxTimestamp, headTimestamp) {
+		return newCompatError("BlobTx fork block timestamp", n.BlobTxTimestamp, newcfg.BlobTxTimestamp)
+	}
+	if isForkIncompatible(n.SetCodeTxTimestamp, newcfg.SetCodeTxTimestamp, headTimestamp) {
+		return newCompatError("SetCodeTx fork block timestamp", n.SetCodeTxTimestamp, newcfg.SetCodeTxTimestamp)
+	}
+	if isForkIncompatible(n.EIP6780Timestamp, newcfg.EIP6780Timestamp, headTimestamp) {
+		return newCompatError("EIP6780 fork block timestamp", n.EIP6780Timestamp, newcfg.EIP6780Timestamp)
+	}
+	for txType, timestamp := range n.CustomTxTypeTimestamps {
+		if isForkIncompatible(timestamp, newcfg.CustomTxTypeTimestamps[txType], headTimestamp) {
+			return newCompatError(fmt.Sprintf("custom transaction type %#x fork block timestamp", txType), timestamp, newcfg.CustomTxTypeTimestamps[txType])
+		}
+	}
+	for txType, timestamp := range newcfg.CustomTxTypeTimestamps {
+		if isForkIncompatible(n.CustomTxTypeTimestamps[txType], timestamp, headTimestamp) {
+			return newCompatError(fmt.Sprintf("custom transaction type %#x fork block timestamp", txType), n.CustomTxTypeTimestamps[txType], timestamp)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,110 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ava-labs/subnet-evm/utils"
+)
+
+// OpcodeOverride changes the behavior of an EVM opcode, for example to disable SELFDESTRUCT or to reprice
+// BLOCKHASH ahead of an upstream hard fork. An override without any field set restores the default behavior
+// of the opcode, so that a later ParameterUpgrade can revert an earlier override.
+type OpcodeOverride struct {
+	// Disabled makes the opcode invalid, as if it were undefined.
+	Disabled bool `json:"disabled,omitempty"`
+	// ConstantGas replaces the constant gas cost of the opcode. The dynamic part of the cost, such as
+	// the surcharge for accessing cold storage, is still charged on top of it.
+	ConstantGas *uint64 `json:"constantGas,omitempty"`
+}
+
+// isDefault returns true if [o] restores the default behavior of the opcode.
+func (o OpcodeOverride) isDefault() bool {
+	return !o.Disabled && o.ConstantGas == nil
+}
+
+// Equal returns true if [other] overrides the opcode in the same way.
+func (o OpcodeOverride) Equal(other OpcodeOverride) bool {
+	if o.Disabled != other.Disabled || (o.ConstantGas == nil) != (other.ConstantGas == nil) {
+		return false
+	}
+	return o.ConstantGas == nil || *o.ConstantGas == *other.ConstantGas
+}
+
+// opcodeOverridePolicy lists the overrides allowed for an opcode.
+type opcodeOverridePolicy struct {
+	disable bool
+	reprice bool
+}
+
+// overridableOpcodes is the set of opcodes that can be overridden, keyed by their name. Only opcodes
+// that contracts can do without (when disabled) or whose cost is dominated by their constant gas (when
+// repriced) are included, so that overrides cannot break the assumptions of the EVM.
+// The opcodes accessing storage and other accounts, such as SLOAD, SSTORE, BALANCE and EXTCODE*, cannot
+// be repriced since EIP-2929 charges most of their cost as dynamic gas, which overrides do not replace.
+var overridableOpcodes = map[string]opcodeOverridePolicy{
+	"SELFDESTRUCT": {disable: true, reprice: true},
+	"CALLCODE":     {disable: true},
+	"BLOCKHASH":    {reprice: true},
+}
+
+// verifyOpcodeOverrides returns an error if any of [overrides] is not allowed by the policy of its opcode.
+func verifyOpcodeOverrides(overrides map[string]OpcodeOverride) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		override := overrides[name]
+		policy, ok := overridableOpcodes[name]
+		switch {
+		case !ok:
+			return utils.NewFieldError(name, "cannot be overridden")
+		case override.Disabled && override.ConstantGas != nil:
+			return utils.NewFieldError(name, "cannot be both disabled and repriced")
+		case override.Disabled && !policy.disable:
+			return utils.NewFieldError(name, "cannot be disabled")
+		case override.ConstantGas != nil && !policy.reprice:
+			return utils.NewFieldError(name, "cannot be repriced")
+		}
+	}
+	return nil
+}
+
+// opcodeOverridesEqual returns true if [a] and [b] override the same opcodes in the same way.
+func opcodeOverridesEqual(a, b map[string]OpcodeOverride) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, override := range a {
+		other, ok := b[name]
+		if !ok || !override.Equal(other) {
+			return false
+		}
+	}
+	return true
+}
+
+// OpcodeOverridesAt returns the opcode overrides in effect at [blockTimestamp], as set by the
+// activated ParameterUpgrades. Returns nil if no opcode is overridden.
+func (c *ChainConfig) OpcodeOverridesAt(blockTimestamp *big.Int) map[string]OpcodeOverride {
+	var overrides map[string]OpcodeOverride
+	for _, upgrade := range activatedParameterUpgrades(c.Upgrades().ParameterUpgrades, blockTimestamp) {
+		for name, override := range upgrade.OpcodeOverrides {
+			if override.isDefault() {
+				delete(overrides, name)
+				continue
+			}
+			if overrides == nil {
+				overrides = make(map[string]OpcodeOverride)
+			}
+			overrides[name] = override
+		}
+	}
+	return overrides
+}
@@ -0,0 +1,188 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/utils"
+)
+
+var (
+	errNoParameterUpgradeTimestamp = errors.New("parameter upgrade must specify blockTimestamp")
+	errEmptyParameterUpgrade       = errors.New("parameter upgrade must change at least one parameter")
+)
+
+// ParameterUpgrade is a helper struct embedded in UpgradeConfig, representing
+// a scheduled change to the chain parameters that are not part of a precompile.
+// Each parameter that is set replaces the value in the genesis ChainConfig (or in
+// an earlier ParameterUpgrade) for all blocks at or after [BlockTimestamp].
+type ParameterUpgrade struct {
+	BlockTimestamp     *big.Int              `json:"blockTimestamp"`
+	FeeConfig          *commontype.FeeConfig `json:"feeConfig,omitempty"`
+	AllowFeeRecipients *bool                 `json:"allowFeeRecipients,omitempty"`
+	// OpcodeOverrides changes the behavior of the listed EVM opcodes, keyed by opcode name.
+	// Unlike the other parameters, overrides are merged per opcode with those of earlier upgrades.
+	OpcodeOverrides map[string]OpcodeOverride `json:"opcodeOverrides,omitempty"`
+	// PrecompileEvents makes the allow list, fee config manager and native minter precompiles emit
+	// logs when their state changes.
+	PrecompileEvents *bool `json:"precompileEvents,omitempty"`
+}
+
+// Timestamp returns the timestamp this parameter upgrade activates at.
+func (p *ParameterUpgrade) Timestamp() *big.Int {
+	return p.BlockTimestamp
+}
+
+// Verify returns an error if the parameter upgrade is invalid.
+func (p *ParameterUpgrade) Verify() error {
+	if p.BlockTimestamp == nil {
+		return errNoParameterUpgradeTimestamp
+	}
+	if p.FeeConfig == nil && p.AllowFeeRecipients == nil && len(p.OpcodeOverrides) == 0 && p.PrecompileEvents == nil {
+		return errEmptyParameterUpgrade
+	}
+	if p.FeeConfig != nil {
+		if err := p.FeeConfig.Verify(); err != nil {
+			return utils.WithFieldPath("feeConfig", err)
+		}
+	}
+	if err := verifyOpcodeOverrides(p.OpcodeOverrides); err != nil {
+		return utils.WithFieldPath("opcodeOverrides", err)
+	}
+	return nil
+}
+
+// Equal returns true if [other] schedules the same parameters at the same timestamp.
+func (p *ParameterUpgrade) Equal(other *ParameterUpgrade) bool {
+	if other == nil {
+		return false
+	}
+	if !utils.BigNumEqual(p.BlockTimestamp, other.BlockTimestamp) {
+		return false
+	}
+	if (p.FeeConfig == nil) != (other.FeeConfig == nil) {
+		return false
+	}
+	if p.FeeConfig != nil && !p.FeeConfig.Equal(other.FeeConfig) {
+		return false
+	}
+	if (p.AllowFeeRecipients == nil) != (other.AllowFeeRecipients == nil) {
+		return false
+	}
+	if !opcodeOverridesEqual(p.OpcodeOverrides, other.OpcodeOverrides) {
+		return false
+	}
+	if (p.PrecompileEvents == nil) != (other.PrecompileEvents == nil) {
+		return false
+	}
+	if p.PrecompileEvents != nil && *p.PrecompileEvents != *other.PrecompileEvents {
+		return false
+	}
+	return p.AllowFeeRecipients == nil || *p.AllowFeeRecipients == *other.AllowFeeRecipients
+}
+
+// verifyParameterUpgrades checks that each parameter upgrade is valid and that
+// the upgrades are listed in strictly increasing order of activation.
+func (c *ChainConfig) verifyParameterUpgrades() error {
+	var lastTimestamp *big.Int
+	upgrades := c.Upgrades().ParameterUpgrades
+	for i := range upgrades {
+		upgrade := &upgrades[i]
+		if err := upgrade.Verify(); err != nil {
+			return utils.WithFieldPath(fmt.Sprintf("parameterUpgrades[%d]", i), err)
+		}
+		if lastTimestamp != nil && upgrade.BlockTimestamp.Cmp(lastTimestamp) <= 0 {
+			return fmt.Errorf("ParameterUpgrades[%d]: config timestamp (%v) <= previous timestamp (%v)", i, upgrade.BlockTimestamp, lastTimestamp)
+		}
+		lastTimestamp = upgrade.BlockTimestamp
+	}
+	return nil
+}
+
+// checkParameterUpgradesCompatible verifies that [parameterUpgrades] is compatible with the parameter
+// upgrades of [c] at [lastTimestamp]. Upgrades that have already gone into effect cannot be modified
+// or absent from [parameterUpgrades], and new upgrades cannot be scheduled retroactively.
+func (c *ChainConfig) checkParameterUpgradesCompatible(parameterUpgrades []ParameterUpgrade, lastTimestamp *big.Int) *ConfigCompatError {
+	activeUpgrades := activatedParameterUpgrades(c.Upgrades().ParameterUpgrades, lastTimestamp)
+	newUpgrades := activatedParameterUpgrades(parameterUpgrades, lastTimestamp)
+
+	for i := range activeUpgrades {
+		if len(newUpgrades) <= i {
+			return newCompatError(
+				fmt.Sprintf("missing ParameterUpgrade[%d]", i),
+				activeUpgrades[i].BlockTimestamp,
+				nil,
+			)
+		}
+		if !activeUpgrades[i].Equal(&newUpgrades[i]) {
+			return newCompatError(
+				fmt.Sprintf("ParameterUpgrade[%d]", i),
+				activeUpgrades[i].BlockTimestamp,
+				newUpgrades[i].BlockTimestamp,
+			)
+		}
+	}
+	if len(newUpgrades) > len(activeUpgrades) {
+		return newCompatError(
+			fmt.Sprintf("cannot retroactively enable ParameterUpgrade[%d]", len(activeUpgrades)),
+			nil,
+			newUpgrades[len(activeUpgrades)].BlockTimestamp,
+		)
+	}
+	return nil
+}
+
+// activatedParameterUpgrades returns the prefix of [upgrades] that has activated at or before [blockTimestamp].
+// Assumes [upgrades] is sorted by timestamp, as enforced by verifyParameterUpgrades.
+func activatedParameterUpgrades(upgrades []ParameterUpgrade, blockTimestamp *big.Int) []ParameterUpgrade {
+	for i, upgrade := range upgrades {
+		if !utils.IsForked(upgrade.BlockTimestamp, blockTimestamp) {
+			return upgrades[:i]
+		}
+	}
+	return upgrades
+}
+
+// FeeConfigAt returns the FeeConfig in effect at [blockTimestamp] when it is not managed by the fee
+// config manager precompile: the FeeConfig of the latest activated ParameterUpgrade setting one,
+// or the genesis FeeConfig otherwise.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) FeeConfigAt(blockTimestamp *big.Int) commontype.FeeConfig {
+	feeConfig := c.FeeConfig
+	for _, upgrade := range activatedParameterUpgrades(c.Upgrades().ParameterUpgrades, blockTimestamp) {
+		if upgrade.FeeConfig != nil {
+			feeConfig = *upgrade.FeeConfig
+		}
+	}
+	return feeConfig
+}
+
+// AllowFeeRecipientsAt returns whether fee recipients are allowed at [blockTimestamp] when it is not
+// managed by the reward manager precompile, taking activated ParameterUpgrades into account.
+func (c *ChainConfig) AllowFeeRecipientsAt(blockTimestamp *big.Int) bool {
+	allowFeeRecipients := c.AllowFeeRecipients
+	for _, upgrade := range activatedParameterUpgrades(c.Upgrades().ParameterUpgrades, blockTimestamp) {
+		if upgrade.AllowFeeRecipients != nil {
+			allowFeeRecipients = *upgrade.AllowFeeRecipients
+		}
+	}
+	return allowFeeRecipients
+}
+
+// PrecompileEventsAt returns whether the precompiles that predate events emit them at [blockTimestamp],
+// as set by the latest activated ParameterUpgrade setting it. Events are disabled otherwise.
+// Implements precompile.ChainConfig interface.
+func (c *ChainConfig) PrecompileEventsAt(blockTimestamp *big.Int) bool {
+	precompileEvents := false
+	for _, upgrade := range activatedParameterUpgrades(c.Upgrades().ParameterUpgrades, blockTimestamp) {
+		if upgrade.PrecompileEvents != nil {
+			precompileEvents = *upgrade.PrecompileEvents
+		}
+	}
+	return precompileEvents
+}
@@ -0,0 +1,212 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyParameterUpgrades(t *testing.T) {
+	validFeeConfig := DefaultFeeConfig
+	validFeeConfig.MinBaseFee = big.NewInt(50_000_000_000)
+	invalidFeeConfig := DefaultFeeConfig
+	invalidFeeConfig.MinBaseFee = big.NewInt(-1)
+	allow := true
+	blockhashGas := uint64(5000)
+
+	tests := map[string]struct {
+		upgrades      []ParameterUpgrade
+		expectedError string
+	}{
+		"valid upgrades": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), FeeConfig: &validFeeConfig},
+				{BlockTimestamp: big.NewInt(2), AllowFeeRecipients: &allow},
+			},
+		},
+		"missing timestamp": {
+			upgrades:      []ParameterUpgrade{{FeeConfig: &validFeeConfig}},
+			expectedError: "must specify blockTimestamp",
+		},
+		"empty upgrade": {
+			upgrades:      []ParameterUpgrade{{BlockTimestamp: big.NewInt(1)}},
+			expectedError: "must change at least one parameter",
+		},
+		"invalid fee config": {
+			upgrades:      []ParameterUpgrade{{BlockTimestamp: big.NewInt(1), FeeConfig: &invalidFeeConfig}},
+			expectedError: "parameterUpgrades[0].feeConfig.minBaseFee = -1 cannot be less than 0",
+		},
+		"opcode overrides": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{
+					"SELFDESTRUCT": {Disabled: true},
+					"BLOCKHASH":    {ConstantGas: &blockhashGas},
+				}},
+			},
+		},
+		"override unlisted opcode": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{"JUMP": {Disabled: true}}},
+			},
+			expectedError: "parameterUpgrades[0].opcodeOverrides.JUMP cannot be overridden",
+		},
+		"disable repriceable opcode": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{"BLOCKHASH": {Disabled: true}}},
+			},
+			expectedError: "parameterUpgrades[0].opcodeOverrides.BLOCKHASH cannot be disabled",
+		},
+		"reprice storage access": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{"SLOAD": {ConstantGas: &blockhashGas}}},
+			},
+			expectedError: "parameterUpgrades[0].opcodeOverrides.SLOAD cannot be overridden",
+		},
+		"reprice disableable opcode": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{"CALLCODE": {ConstantGas: &blockhashGas}}},
+			},
+			expectedError: "parameterUpgrades[0].opcodeOverrides.CALLCODE cannot be repriced",
+		},
+		"disable and reprice opcode": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(1), OpcodeOverrides: map[string]OpcodeOverride{"SELFDESTRUCT": {Disabled: true, ConstantGas: &blockhashGas}}},
+			},
+			expectedError: "parameterUpgrades[0].opcodeOverrides.SELFDESTRUCT cannot be both disabled and repriced",
+		},
+		"non-increasing timestamps": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(2), FeeConfig: &validFeeConfig},
+				{BlockTimestamp: big.NewInt(2), AllowFeeRecipients: &allow},
+			},
+			expectedError: "config timestamp (2) <= previous timestamp (2)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			chainConfig := *TestChainConfig
+			chainConfig.ParameterUpgrades = tt.upgrades
+			err := chainConfig.Verify()
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestParameterUpgradesAt(t *testing.T) {
+	firstFeeConfig := DefaultFeeConfig
+	firstFeeConfig.MinBaseFee = big.NewInt(50_000_000_000)
+	secondFeeConfig := DefaultFeeConfig
+	secondFeeConfig.TargetBlockRate = 5
+	allow := true
+
+	chainConfig := *TestChainConfig
+	chainConfig.ParameterUpgrades = []ParameterUpgrade{
+		{BlockTimestamp: big.NewInt(10), FeeConfig: &firstFeeConfig},
+		{BlockTimestamp: big.NewInt(20), AllowFeeRecipients: &allow, PrecompileEvents: &allow},
+		{BlockTimestamp: big.NewInt(30), FeeConfig: &secondFeeConfig},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	assert.Equal(t, chainConfig.FeeConfig, chainConfig.FeeConfigAt(big.NewInt(9)))
+	assert.Equal(t, firstFeeConfig, chainConfig.FeeConfigAt(big.NewInt(10)))
+	assert.Equal(t, firstFeeConfig, chainConfig.FeeConfigAt(big.NewInt(29)))
+	assert.Equal(t, secondFeeConfig, chainConfig.FeeConfigAt(big.NewInt(30)))
+
+	assert.False(t, chainConfig.AllowFeeRecipientsAt(big.NewInt(19)))
+	assert.True(t, chainConfig.AllowFeeRecipientsAt(big.NewInt(20)))
+	assert.True(t, chainConfig.AllowFeeRecipientsAt(big.NewInt(30)))
+
+	assert.False(t, chainConfig.PrecompileEventsAt(big.NewInt(19)))
+	assert.True(t, chainConfig.PrecompileEventsAt(big.NewInt(20)))
+	assert.True(t, chainConfig.PrecompileEventsAt(big.NewInt(30)))
+}
+
+func TestOpcodeOverridesAt(t *testing.T) {
+	blockhashGas := uint64(5000)
+
+	chainConfig := *TestChainConfig
+	chainConfig.ParameterUpgrades = []ParameterUpgrade{
+		{BlockTimestamp: big.NewInt(10), OpcodeOverrides: map[string]OpcodeOverride{"SELFDESTRUCT": {Disabled: true}}},
+		{BlockTimestamp: big.NewInt(20), OpcodeOverrides: map[string]OpcodeOverride{"BLOCKHASH": {ConstantGas: &blockhashGas}}},
+		{BlockTimestamp: big.NewInt(30), OpcodeOverrides: map[string]OpcodeOverride{"SELFDESTRUCT": {}}},
+	}
+	require.NoError(t, chainConfig.Verify())
+
+	assert.Nil(t, chainConfig.OpcodeOverridesAt(big.NewInt(9)))
+	assert.Equal(t, map[string]OpcodeOverride{"SELFDESTRUCT": {Disabled: true}}, chainConfig.OpcodeOverridesAt(big.NewInt(10)))
+	assert.Equal(t, map[string]OpcodeOverride{
+		"SELFDESTRUCT": {Disabled: true},
+		"BLOCKHASH":    {ConstantGas: &blockhashGas},
+	}, chainConfig.OpcodeOverridesAt(big.NewInt(20)))
+	assert.Equal(t, map[string]OpcodeOverride{"BLOCKHASH": {ConstantGas: &blockhashGas}}, chainConfig.OpcodeOverridesAt(big.NewInt(30)))
+
+	assert.Equal(t, chainConfig.OpcodeOverridesAt(big.NewInt(20)), chainConfig.AvalancheRules(common.Big0, big.NewInt(20)).OpcodeOverrides)
+}
+
+func TestCheckParameterUpgradesCompatible(t *testing.T) {
+	feeConfig := DefaultFeeConfig
+	feeConfig.MinBaseFee = big.NewInt(50_000_000_000)
+	otherFeeConfig := DefaultFeeConfig
+	otherFeeConfig.MinBaseFee = big.NewInt(40_000_000_000)
+
+	chainConfig := *TestChainConfig
+	chainConfig.ParameterUpgrades = []ParameterUpgrade{
+		{BlockTimestamp: big.NewInt(10), FeeConfig: &feeConfig},
+		{BlockTimestamp: big.NewInt(30), FeeConfig: &otherFeeConfig},
+	}
+
+	tests := map[string]struct {
+		upgrades      []ParameterUpgrade
+		expectedError string
+	}{
+		"unchanged": {
+			upgrades: chainConfig.ParameterUpgrades,
+		},
+		"change upgrade that has not activated": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(10), FeeConfig: &feeConfig},
+				{BlockTimestamp: big.NewInt(40), FeeConfig: &feeConfig},
+			},
+		},
+		"remove activated upgrade": {
+			upgrades:      []ParameterUpgrade{},
+			expectedError: "missing ParameterUpgrade[0]",
+		},
+		"modify activated upgrade": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(10), FeeConfig: &otherFeeConfig},
+			},
+			expectedError: "mismatching ParameterUpgrade[0]",
+		},
+		"retroactive upgrade": {
+			upgrades: []ParameterUpgrade{
+				{BlockTimestamp: big.NewInt(10), FeeConfig: &feeConfig},
+				{BlockTimestamp: big.NewInt(15), FeeConfig: &otherFeeConfig},
+			},
+			expectedError: "cannot retroactively enable ParameterUpgrade[1]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			newConfig := chainConfig
+			newConfig.ParameterUpgrades = tt.upgrades
+			err := chainConfig.CheckCompatible(&newConfig, 20, 20)
+			if tt.expectedError == "" {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
@@ -4,118 +4,186 @@
 package params
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// precompileKey is a helper type used to reference each of the
-// possible stateful precompile types that can be activated
-// as a network upgrade.
-type precompileKey int
-
-const (
-	contractDeployerAllowListKey precompileKey = iota + 1
-	contractNativeMinterKey
-	txAllowListKey
-	feeManagerKey
-	rewardManagerKey
-	// ADD YOUR PRECOMPILE HERE
-	// {yourPrecompile}Key
-)
-
-// TODO: Move this to the interface or PrecompileConfig struct
-func (k precompileKey) String() string {
-	switch k {
-	case contractDeployerAllowListKey:
-		return "contractDeployerAllowList"
-	case contractNativeMinterKey:
-		return "contractNativeMinter"
-	case txAllowListKey:
-		return "txAllowList"
-	case feeManagerKey:
-		return "feeManager"
-	case rewardManagerKey:
-		return "rewardManager"
-		// ADD YOUR PRECOMPILE HERE
-		/*
-			case {yourPrecompile}Key:
-				return "{yourPrecompile}"
-		*/
-	}
-	return "unknown"
-}
-
-// ADD YOUR PRECOMPILE HERE
-var precompileKeys = []precompileKey{contractDeployerAllowListKey, contractNativeMinterKey, txAllowListKey, feeManagerKey, rewardManagerKey /* {yourPrecompile}Key */}
-
 // PrecompileUpgrade is a helper struct embedded in UpgradeConfig, representing
 // each of the possible stateful precompile types that can be activated
-// as a network upgrade.
+// as a network upgrade. The configs are keyed by the config key of the
+// precompile modules registered in the precompile package, which are also
+// their JSON keys.
 type PrecompileUpgrade struct {
-	ContractDeployerAllowListConfig *precompile.ContractDeployerAllowListConfig `json:"contractDeployerAllowListConfig,omitempty"` // Config for the contract deployer allow list precompile
-	ContractNativeMinterConfig      *precompile.ContractNativeMinterConfig      `json:"contractNativeMinterConfig,omitempty"`      // Config for the native minter precompile
-	TxAllowListConfig               *precompile.TxAllowListConfig               `json:"txAllowListConfig,omitempty"`               // Config for the tx allow list precompile
-	FeeManagerConfig                *precompile.FeeConfigManagerConfig          `json:"feeManagerConfig,omitempty"`                // Config for the fee manager precompile
-	RewardManagerConfig             *precompile.RewardManagerConfig             `json:"rewardManagerConfig,omitempty"`             // Config for the reward manager precompile
-	// ADD YOUR PRECOMPILE HERE
-	// {YourPrecompile}Config  *precompile.{YourPrecompile}Config `json:"{yourPrecompile}Config,omitempty"`
-}
-
-func (p *PrecompileUpgrade) getByKey(key precompileKey) (precompile.StatefulPrecompileConfig, bool) {
-	switch key {
-	case contractDeployerAllowListKey:
-		return p.ContractDeployerAllowListConfig, p.ContractDeployerAllowListConfig != nil
-	case contractNativeMinterKey:
-		return p.ContractNativeMinterConfig, p.ContractNativeMinterConfig != nil
-	case txAllowListKey:
-		return p.TxAllowListConfig, p.TxAllowListConfig != nil
-	case feeManagerKey:
-		return p.FeeManagerConfig, p.FeeManagerConfig != nil
-	case rewardManagerKey:
-		return p.RewardManagerConfig, p.RewardManagerConfig != nil
-	// ADD YOUR PRECOMPILE HERE
-	/*
-		case {yourPrecompile}Key:
-		return p.{YourPrecompile}Config , p.{YourPrecompile}Config  != nil
-	*/
-	default:
-		panic(fmt.Sprintf("unknown upgrade key: %v", key))
+	configs map[string]precompile.StatefulPrecompileConfig
+}
+
+// NewPrecompileUpgrade returns a PrecompileUpgrade containing [configs].
+// Panics if the precompile of any of [configs] is not registered.
+func NewPrecompileUpgrade(configs ...precompile.StatefulPrecompileConfig) PrecompileUpgrade {
+	p := PrecompileUpgrade{}
+	for _, config := range configs {
+		p.SetConfig(config)
+	}
+	return p
+}
+
+// SetConfig sets [config] as the config of its precompile in [p], replacing any previous config.
+// Panics if the precompile of [config] is not registered.
+func (p *PrecompileUpgrade) SetConfig(config precompile.StatefulPrecompileConfig) {
+	module, ok := precompile.GetRegisteredModuleByAddress(config.Address())
+	if !ok {
+		panic(fmt.Sprintf("no precompile registered at %s", config.Address()))
+	}
+	if p.configs == nil {
+		p.configs = make(map[string]precompile.StatefulPrecompileConfig)
+	}
+	p.configs[module.ConfigKey] = config
+}
+
+// GetConfig returns the config of the precompile registered with [key] in [p], or nil if there is none.
+func (p *PrecompileUpgrade) GetConfig(key string) precompile.StatefulPrecompileConfig {
+	config, _ := p.getByKey(key)
+	return config
+}
+
+func (p *PrecompileUpgrade) getByKey(key string) (precompile.StatefulPrecompileConfig, bool) {
+	config, ok := p.configs[key]
+	return config, ok
+}
+
+// MarshalJSON returns the JSON encoding of [p], where each config is set at its config key.
+func (p PrecompileUpgrade) MarshalJSON() ([]byte, error) {
+	if p.configs == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p.configs)
+}
+
+// UnmarshalJSON parses the configs of the registered precompiles from [data] into [p].
+// Keys of [data] that do not belong to a registered precompile are ignored.
+func (p *PrecompileUpgrade) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.configs = nil
+	for _, module := range precompile.RegisteredModules() {
+		value, ok := raw[module.ConfigKey]
+		if !ok || string(value) == "null" {
+			continue
+		}
+		config := module.NewConfig()
+		if err := json.Unmarshal(value, config); err != nil {
+			return utils.WithFieldPath(module.ConfigKey, utils.QualifyJSONError(value, config, err))
+		}
+		p.SetConfig(config)
 	}
+	return nil
+}
+
+// Describe returns the schema of the JSON encoding of a PrecompileUpgrade, where the config of each
+// registered precompile is described at its config key.
+func (p *PrecompileUpgrade) Describe() *utils.Schema {
+	schema := &utils.Schema{Type: "object", Properties: make(map[string]*utils.Schema)}
+	for _, module := range precompile.RegisteredModules() {
+		schema.Properties[module.ConfigKey] = module.Describe()
+	}
+	return schema
+}
+
+// precompileActivation returns the block timestamp or number at which [config] activates,
+// along with the name of the field that is used for [config].
+// Returns nil if [config] sets neither of them.
+func precompileActivation(config precompile.StatefulPrecompileConfig) (*big.Int, string) {
+	if timestamp := config.Timestamp(); timestamp != nil {
+		return timestamp, "timestamp"
+	}
+	if number := config.Number(); number != nil {
+		return number, "block number"
+	}
+	return nil, ""
+}
+
+// activationOf returns the block timestamp or number at which [config] activates.
+func activationOf(config precompile.StatefulPrecompileConfig) *big.Int {
+	activation, _ := precompileActivation(config)
+	return activation
+}
+
+// isPrecompileForked returns true if [config] has activated at or before the block with
+// [blockNumber] and [blockTimestamp].
+func isPrecompileForked(config precompile.StatefulPrecompileConfig, blockNumber *big.Int, blockTimestamp *big.Int) bool {
+	if timestamp := config.Timestamp(); timestamp != nil {
+		return utils.IsForked(timestamp, blockTimestamp)
+	}
+	return utils.IsForked(config.Number(), blockNumber)
+}
+
+// isPrecompileForkTransition returns true if [config] activates during the transition from the block with
+// [parentNumber] and [parentTimestamp] to the block with [blockNumber] and [blockTimestamp].
+func isPrecompileForkTransition(config precompile.StatefulPrecompileConfig, parentNumber, parentTimestamp, blockNumber, blockTimestamp *big.Int) bool {
+	return !isPrecompileForked(config, parentNumber, parentTimestamp) && isPrecompileForked(config, blockNumber, blockTimestamp)
+}
+
+// verifyPrecompileActivation checks that [config] does not set both a block timestamp and a block number.
+func verifyPrecompileActivation(config precompile.StatefulPrecompileConfig) error {
+	if config.Timestamp() != nil && config.Number() != nil {
+		return fmt.Errorf("config cannot set both a timestamp (%v) and a block number (%v)", config.Timestamp(), config.Number())
+	}
+	return nil
 }
 
 // verifyPrecompileUpgrades checks [c.PrecompileUpgrades] is well formed:
 // - [upgrades] must specify exactly one key per PrecompileUpgrade
-// - the specified blockTimestamps must monotonically increase
-// - the specified blockTimestamps must be compatible with those
+// - each upgrade must specify exactly one of a blockTimestamp and a blockNumber
+// - the specified blockTimestamps and blockNumbers must monotonically increase
+// - the specified blockTimestamps and blockNumbers must be compatible with those
 //   specified in the chainConfig by genesis.
-// - check a precompile is disabled before it is re-enabled
+// - all upgrades of a precompile must be activated by the same kind of field
+//   (blockTimestamp or blockNumber), so that they can be ordered
+// - check a precompile is disabled before it is re-enabled, unless the upgrade migrates
+//   its storage to a greater storage version without changing its other parameters
+// - the storage versions must be supported by the registered precompiles
 func (c *ChainConfig) verifyPrecompileUpgrades() error {
-	var lastBlockTimestamp *big.Int
-	for i, upgrade := range c.PrecompileUpgrades {
+	var lastBlockTimestamp, lastBlockNumber *big.Int
+	for i, upgrade := range c.Upgrades().PrecompileUpgrades {
 		hasKey := false // used to verify if there is only one key per Upgrade
 
-		for _, key := range precompileKeys {
-			config, ok := upgrade.getByKey(key)
+		for _, module := range precompile.RegisteredModules() {
+			config, ok := upgrade.getByKey(module.ConfigKey)
 			if !ok {
 				continue
 			}
 			if hasKey {
 				return fmt.Errorf("PrecompileUpgrades[%d] has more than one key set", i)
 			}
-			configTimestamp := config.Timestamp()
-			if configTimestamp == nil {
-				return fmt.Errorf("PrecompileUpgrades[%d] cannot have a nil timestamp", i)
+			if err := verifyPrecompileActivation(config); err != nil {
+				return fmt.Errorf("PrecompileUpgrades[%d] %w", i, err)
 			}
-			// Verify specified timestamps are monotonically increasing across all precompile keys.
-			// Note: It is OK for multiple configs of different keys to specify the same timestamp.
-			if lastBlockTimestamp != nil && configTimestamp.Cmp(lastBlockTimestamp) < 0 {
-				return fmt.Errorf("PrecompileUpgrades[%d] config timestamp (%v) < previous timestamp (%v)", i, configTimestamp, lastBlockTimestamp)
+			// Verify specified timestamps and block numbers are monotonically increasing across all precompile keys.
+			// Note: It is OK for multiple configs of different keys to specify the same timestamp or block number.
+			switch {
+			case config.Timestamp() != nil:
+				configTimestamp := config.Timestamp()
+				if lastBlockTimestamp != nil && configTimestamp.Cmp(lastBlockTimestamp) < 0 {
+					return fmt.Errorf("PrecompileUpgrades[%d] config timestamp (%v) < previous timestamp (%v)", i, configTimestamp, lastBlockTimestamp)
+				}
+				lastBlockTimestamp = configTimestamp
+			case config.Number() != nil:
+				configNumber := config.Number()
+				if lastBlockNumber != nil && configNumber.Cmp(lastBlockNumber) < 0 {
+					return fmt.Errorf("PrecompileUpgrades[%d] config block number (%v) < previous block number (%v)", i, configNumber, lastBlockNumber)
+				}
+				lastBlockNumber = configNumber
+			default:
+				return fmt.Errorf("PrecompileUpgrades[%d] cannot have a nil timestamp and a nil block number", i)
 			}
-			lastBlockTimestamp = configTimestamp
 			hasKey = true
 		}
 		if !hasKey {
@@ -123,66 +191,111 @@ func (c *ChainConfig) verifyPrecompileUpgrades() error {
 		}
 	}
 
-	for _, key := range precompileKeys {
+	for _, module := range precompile.RegisteredModules() {
+		key := module.ConfigKey
 		var (
 			lastUpgraded *big.Int
+			lastKind     string
+			lastConfig   precompile.StatefulPrecompileConfig
 			disabled     bool
 		)
 		// check the genesis chain config for any enabled upgrade
 		if config, ok := c.PrecompileUpgrade.getByKey(key); ok {
+			if err := verifyPrecompileActivation(config); err != nil {
+				return fmt.Errorf("%s %w", key, err)
+			}
 			if err := config.Verify(); err != nil {
+				return utils.WithFieldPath(key, err)
+			}
+			if err := verifyStorageVersion(module, config); err != nil {
 				return err
 			}
+			if err := precompile.VerifyInitialState(config); err != nil {
+				return fmt.Errorf("%s %w", key, err)
+			}
 			disabled = false
-			lastUpgraded = config.Timestamp()
+			lastUpgraded, lastKind = precompileActivation(config)
+			lastConfig = config
 		} else {
 			disabled = true
 		}
-		// next range over upgrades to verify correct use of disabled and blockTimestamps.
-		for i, upgrade := range c.PrecompileUpgrades {
+		// next range over upgrades to verify correct use of disabled and activations.
+		for i, upgrade := range c.Upgrades().PrecompileUpgrades {
 			config, ok := upgrade.getByKey(key)
 			// Skip the upgrade if it's not relevant to [key].
 			if !ok {
 				continue
 			}
 
-			if disabled == config.IsDisabled() {
+			// An enabled precompile can only be enabled again to migrate its storage.
+			migrates := !disabled && !config.IsDisabled() && config.GetStorageVersion() > lastConfig.GetStorageVersion()
+			if disabled == config.IsDisabled() && !migrates {
 				return fmt.Errorf("PrecompileUpgrades[%d] disable should be [%v]", i, !disabled)
 			}
-			if lastUpgraded != nil && (config.Timestamp().Cmp(lastUpgraded) <= 0) {
-				return fmt.Errorf("PrecompileUpgrades[%d] config timestamp (%v) <= previous timestamp (%v)", i, config.Timestamp(), lastUpgraded)
+			activation, kind := precompileActivation(config)
+			if lastUpgraded != nil {
+				if kind != lastKind {
+					return fmt.Errorf("PrecompileUpgrades[%d] config activates by %s but previous %s upgrade activates by %s", i, kind, key, lastKind)
+				}
+				if activation.Cmp(lastUpgraded) <= 0 {
+					return fmt.Errorf("PrecompileUpgrades[%d] config %s (%v) <= previous %s (%v)", i, kind, activation, kind, lastUpgraded)
+				}
 			}
 
 			if err := config.Verify(); err != nil {
+				return utils.WithFieldPath(fmt.Sprintf("precompileUpgrades[%d].%s", i, key), err)
+			}
+			if err := verifyStorageVersion(module, config); err != nil {
 				return err
 			}
+			if err := precompile.VerifyInitialState(config); err != nil {
+				return fmt.Errorf("PrecompileUpgrades[%d] %s %w", i, key, err)
+			}
+			// The initial state is only applied when the precompile is configured, not when its storage is migrated.
+			if migrates && (len(config.GetInitialStorage()) != 0 || len(config.GetInitialEvents()) != 0) {
+				return fmt.Errorf("PrecompileUpgrades[%d] %s cannot seed initial state when migrating storage", i, key)
+			}
+			// The other parameters are not applied either, so they must stay the same.
+			if migrates && !precompile.SameSettings(lastConfig, config) {
+				return fmt.Errorf("PrecompileUpgrades[%d] %s cannot change the parameters of the precompile when migrating storage", i, key)
+			}
 
 			disabled = config.IsDisabled()
-			lastUpgraded = config.Timestamp()
+			lastUpgraded, lastKind = activation, kind
+			lastConfig = config
 		}
 	}
 
 	return nil
 }
 
-// getActivePrecompileConfig returns the most recent precompile config corresponding to [key].
+// verifyStorageVersion checks that the storage version of [config] is supported by [module].
+func verifyStorageVersion(module precompile.Module, config precompile.StatefulPrecompileConfig) error {
+	if version := config.GetStorageVersion(); version > module.StorageVersion {
+		return fmt.Errorf("%s storage version (%d) > latest supported storage version (%d)", module.ConfigKey, version, module.StorageVersion)
+	}
+	return nil
+}
+
+// getActivePrecompileConfig returns the most recent precompile config corresponding to [key]
+// activated at or before the block with [blockNumber] and [blockTimestamp].
 // If none have occurred, returns nil.
-func (c *ChainConfig) getActivePrecompileConfig(blockTimestamp *big.Int, key precompileKey, upgrades []PrecompileUpgrade) precompile.StatefulPrecompileConfig {
-	configs := c.getActivatingPrecompileConfigs(nil, blockTimestamp, key, upgrades)
+func (c *ChainConfig) getActivePrecompileConfig(blockNumber *big.Int, blockTimestamp *big.Int, key string, upgrades []PrecompileUpgrade) precompile.StatefulPrecompileConfig {
+	configs := c.getActivatingPrecompileConfigs(nil, nil, blockNumber, blockTimestamp, key, upgrades)
 	if len(configs) == 0 {
 		return nil
 	}
 	return configs[len(configs)-1] // return the most recent config
 }
 
-// getActivatingPrecompileConfigs returns all forks configured to activate during the state transition from a block with timestamp [from]
-// to a block with timestamp [to].
-func (c *ChainConfig) getActivatingPrecompileConfigs(from *big.Int, to *big.Int, key precompileKey, upgrades []PrecompileUpgrade) []precompile.StatefulPrecompileConfig {
+// getActivatingPrecompileConfigs returns all forks configured to activate during the state transition from a block with
+// number [fromNumber] and timestamp [fromTimestamp] to a block with number [toNumber] and timestamp [toTimestamp].
+func (c *ChainConfig) getActivatingPrecompileConfigs(fromNumber, fromTimestamp, toNumber, toTimestamp *big.Int, key string, upgrades []PrecompileUpgrade) []precompile.StatefulPrecompileConfig {
 	configs := make([]precompile.StatefulPrecompileConfig, 0)
 	// First check the embedded [upgrade] for precompiles configured
 	// in the genesis chain config.
 	if config, ok := c.PrecompileUpgrade.getByKey(key); ok {
-		if utils.IsForkTransition(config.Timestamp(), from, to) {
+		if isPrecompileForkTransition(config, fromNumber, fromTimestamp, toNumber, toTimestamp) {
 			configs = append(configs, config)
 		}
 	}
@@ -190,7 +303,7 @@ func (c *ChainConfig) getActivatingPrecompileConfigs(from *big.Int, to *big.Int,
 	for _, upgrade := range upgrades {
 		if config, ok := upgrade.getByKey(key); ok {
 			// Check if the precompile activates in the specified range.
-			if utils.IsForkTransition(config.Timestamp(), from, to) {
+			if isPrecompileForkTransition(config, fromNumber, fromTimestamp, toNumber, toTimestamp) {
 				configs = append(configs, config)
 			}
 		}
@@ -200,8 +313,8 @@ func (c *ChainConfig) getActivatingPrecompileConfigs(from *big.Int, to *big.Int,
 
 // GetContractDeployerAllowListConfig returns the latest forked ContractDeployerAllowListConfig
 // specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetContractDeployerAllowListConfig(blockTimestamp *big.Int) *precompile.ContractDeployerAllowListConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, contractDeployerAllowListKey, c.PrecompileUpgrades); val != nil {
+func (c *ChainConfig) GetContractDeployerAllowListConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.ContractDeployerAllowListConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.ContractDeployerAllowListConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
 		return val.(*precompile.ContractDeployerAllowListConfig)
 	}
 	return nil
@@ -209,8 +322,8 @@ func (c *ChainConfig) GetContractDeployerAllowListConfig(blockTimestamp *big.Int
 
 // GetContractNativeMinterConfig returns the latest forked ContractNativeMinterConfig
 // specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetContractNativeMinterConfig(blockTimestamp *big.Int) *precompile.ContractNativeMinterConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, contractNativeMinterKey, c.PrecompileUpgrades); val != nil {
+func (c *ChainConfig) GetContractNativeMinterConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.ContractNativeMinterConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.ContractNativeMinterConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
 		return val.(*precompile.ContractNativeMinterConfig)
 	}
 	return nil
@@ -218,8 +331,8 @@ func (c *ChainConfig) GetContractNativeMinterConfig(blockTimestamp *big.Int) *pr
 
 // GetTxAllowListConfig returns the latest forked TxAllowListConfig
 // specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetTxAllowListConfig(blockTimestamp *big.Int) *precompile.TxAllowListConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, txAllowListKey, c.PrecompileUpgrades); val != nil {
+func (c *ChainConfig) GetTxAllowListConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.TxAllowListConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.TxAllowListConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
 		return val.(*precompile.TxAllowListConfig)
 	}
 	return nil
@@ -227,8 +340,8 @@ func (c *ChainConfig) GetTxAllowListConfig(blockTimestamp *big.Int) *precompile.
 
 // GetFeeConfigManagerConfig returns the latest forked FeeManagerConfig
 // specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetFeeConfigManagerConfig(blockTimestamp *big.Int) *precompile.FeeConfigManagerConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, feeManagerKey, c.PrecompileUpgrades); val != nil {
+func (c *ChainConfig) GetFeeConfigManagerConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.FeeConfigManagerConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.FeeConfigManagerConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
 		return val.(*precompile.FeeConfigManagerConfig)
 	}
 	return nil
@@ -236,56 +349,106 @@ func (c *ChainConfig) GetFeeConfigManagerConfig(blockTimestamp *big.Int) *precom
 
 // GetRewardManagerConfig returns the latest forked RewardManagerConfig
 // specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetRewardManagerConfig(blockTimestamp *big.Int) *precompile.RewardManagerConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, rewardManagerKey, c.PrecompileUpgrades); val != nil {
+func (c *ChainConfig) GetRewardManagerConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.RewardManagerConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.RewardManagerConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
 		return val.(*precompile.RewardManagerConfig)
 	}
 	return nil
 }
 
-/* ADD YOUR PRECOMPILE HERE
-func (c *ChainConfig) Get{YourPrecompile}Config(blockTimestamp *big.Int) *precompile.{YourPrecompile}Config {
-	if val := c.getActivePrecompileConfig(blockTimestamp, {yourPrecompile}Key, c.PrecompileUpgrades); val != nil {
-		return val.(*precompile.{YourPrecompile}Config)
+// GetAddressBlocklistConfig returns the latest forked AddressBlocklistConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetAddressBlocklistConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.AddressBlocklistConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.AddressBlocklistConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.AddressBlocklistConfig)
 	}
 	return nil
 }
-*/
 
-func (c *ChainConfig) GetActivePrecompiles(blockTimestamp *big.Int) PrecompileUpgrade {
-	pu := PrecompileUpgrade{}
-	if config := c.GetContractDeployerAllowListConfig(blockTimestamp); config != nil && !config.Disable {
-		pu.ContractDeployerAllowListConfig = config
+// GetChainConfigReaderConfig returns the latest forked ChainConfigReaderConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetChainConfigReaderConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.ChainConfigReaderConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.ChainConfigReaderConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.ChainConfigReaderConfig)
+	}
+	return nil
+}
+
+// GetNativeAssetBalanceConfig returns the latest forked NativeAssetBalanceConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetNativeAssetBalanceConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.NativeAssetBalanceConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.NativeAssetBalanceConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.NativeAssetBalanceConfig)
+	}
+	return nil
+}
+
+// GetNativeAssetCallConfig returns the latest forked NativeAssetCallConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetNativeAssetCallConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.NativeAssetCallConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.NativeAssetCallConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.NativeAssetCallConfig)
 	}
-	if config := c.GetContractNativeMinterConfig(blockTimestamp); config != nil && !config.Disable {
-		pu.ContractNativeMinterConfig = config
+	return nil
+}
+
+// GetGasSponsorConfig returns the latest forked GasSponsorConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetGasSponsorConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.GasSponsorConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.GasSponsorConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.GasSponsorConfig)
 	}
-	if config := c.GetTxAllowListConfig(blockTimestamp); config != nil && !config.Disable {
-		pu.TxAllowListConfig = config
+	return nil
+}
+
+// GetValidatorInfoConfig returns the latest forked ValidatorInfoConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetValidatorInfoConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.ValidatorInfoConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.ValidatorInfoConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.ValidatorInfoConfig)
 	}
-	if config := c.GetFeeConfigManagerConfig(blockTimestamp); config != nil && !config.Disable {
-		pu.FeeManagerConfig = config
+	return nil
+}
+
+// GetGasTokenConfig returns the latest forked GasTokenConfig
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetGasTokenConfig(blockNumber *big.Int, blockTimestamp *big.Int) *precompile.GasTokenConfig {
+	if val := c.getActivePrecompileConfig(blockNumber, blockTimestamp, precompile.GasTokenConfigKey, c.Upgrades().PrecompileUpgrades); val != nil {
+		return val.(*precompile.GasTokenConfig)
 	}
-	if config := c.GetRewardManagerConfig(blockTimestamp); config != nil && !config.Disable {
-		pu.RewardManagerConfig = config
+	return nil
+}
+
+// GetActivePrecompileConfig returns the latest forked config of the precompile at [address]
+// specified by [c] or nil if it was never enabled.
+func (c *ChainConfig) GetActivePrecompileConfig(address common.Address, blockNumber *big.Int, blockTimestamp *big.Int) precompile.StatefulPrecompileConfig {
+	module, ok := precompile.GetRegisteredModuleByAddress(address)
+	if !ok {
+		return nil
 	}
-	// ADD YOUR PRECOMPILE HERE
-	// if config := c.{YourPrecompile}Config(blockTimestamp); config != nil && !config.Disable {
-	// 	pu.{YourPrecompile}Config = config
-	// }
+	return c.getActivePrecompileConfig(blockNumber, blockTimestamp, module.ConfigKey, c.Upgrades().PrecompileUpgrades)
+}
 
+// GetActivePrecompiles returns the configs of the precompiles enabled in the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) GetActivePrecompiles(blockNumber *big.Int, blockTimestamp *big.Int) PrecompileUpgrade {
+	pu := PrecompileUpgrade{}
+	for _, config := range c.EnabledStatefulPrecompiles(blockNumber, blockTimestamp) {
+		if !config.IsDisabled() {
+			pu.SetConfig(config)
+		}
+	}
 	return pu
 }
 
-// CheckPrecompilesCompatible checks if [precompileUpgrades] are compatible with [c] at [headTimestamp].
-// Returns a ConfigCompatError if upgrades already forked at [headTimestamp] are missing from
+// CheckPrecompilesCompatible checks if [precompileUpgrades] are compatible with [c] at [lastHeight] and [lastTimestamp].
+// Returns a ConfigCompatError if upgrades already forked at [lastHeight] and [lastTimestamp] are missing from
 // [precompileUpgrades]. Upgrades not already forked may be modified or absent from [precompileUpgrades].
 // Returns nil if [precompileUpgrades] is compatible with [c].
-// Assumes given timestamp is the last accepted block timestamp.
+// Assumes given height and timestamp are those of the last accepted block.
 // This ensures that as long as the node has not accepted a block with a different rule set it will allow a new upgrade to be applied as long as it activates after the last accepted block.
-func (c *ChainConfig) CheckPrecompilesCompatible(precompileUpgrades []PrecompileUpgrade, lastTimestamp *big.Int) *ConfigCompatError {
-	for _, key := range precompileKeys {
-		if err := c.checkPrecompileCompatible(key, precompileUpgrades, lastTimestamp); err != nil {
+func (c *ChainConfig) CheckPrecompilesCompatible(precompileUpgrades []PrecompileUpgrade, lastHeight *big.Int, lastTimestamp *big.Int) *ConfigCompatError {
+	for _, module := range precompile.RegisteredModules() {
+		if err := c.checkPrecompileCompatible(module.ConfigKey, precompileUpgrades, lastHeight, lastTimestamp); err != nil {
 			return err
 		}
 	}
@@ -293,13 +456,14 @@ func (c *ChainConfig) CheckPrecompilesCompatible(precompileUpgrades []Precompile
 	return nil
 }
 
-// checkPrecompileCompatible verifies that the precompile specified by [key] is compatible between [c] and [precompileUpgrades] at [headTimestamp].
-// Returns an error if upgrades already forked at [headTimestamp] are missing from [precompileUpgrades].
+// checkPrecompileCompatible verifies that the precompile specified by [key] is compatible between [c] and [precompileUpgrades]
+// at [lastHeight] and [lastTimestamp].
+// Returns an error if upgrades already forked at [lastHeight] and [lastTimestamp] are missing from [precompileUpgrades].
 // Upgrades that have already gone into effect cannot be modified or absent from [precompileUpgrades].
-func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpgrades []PrecompileUpgrade, lastTimestamp *big.Int) *ConfigCompatError {
+func (c *ChainConfig) checkPrecompileCompatible(key string, precompileUpgrades []PrecompileUpgrade, lastHeight *big.Int, lastTimestamp *big.Int) *ConfigCompatError {
 	// all active upgrades must match
-	activeUpgrades := c.getActivatingPrecompileConfigs(nil, lastTimestamp, key, c.PrecompileUpgrades)
-	newUpgrades := c.getActivatingPrecompileConfigs(nil, lastTimestamp, key, precompileUpgrades)
+	activeUpgrades := c.getActivatingPrecompileConfigs(nil, nil, lastHeight, lastTimestamp, key, c.Upgrades().PrecompileUpgrades)
+	newUpgrades := c.getActivatingPrecompileConfigs(nil, nil, lastHeight, lastTimestamp, key, precompileUpgrades)
 
 	// first, check existing upgrades are there
 	for i, upgrade := range activeUpgrades {
@@ -307,7 +471,7 @@ func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpg
 			// missing upgrade
 			return newCompatError(
 				fmt.Sprintf("missing PrecompileUpgrade[%d]", i),
-				upgrade.Timestamp(),
+				activationOf(upgrade),
 				nil,
 			)
 		}
@@ -315,8 +479,8 @@ func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpg
 		if !upgrade.Equal(newUpgrades[i]) {
 			return newCompatError(
 				fmt.Sprintf("PrecompileUpgrade[%d]", i),
-				upgrade.Timestamp(),
-				newUpgrades[i].Timestamp(),
+				activationOf(upgrade),
+				activationOf(newUpgrades[i]),
 			)
 		}
 	}
@@ -326,7 +490,7 @@ func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpg
 		return newCompatError(
 			fmt.Sprintf("cannot retroactively enable PrecompileUpgrade[%d]", len(activeUpgrades)),
 			nil,
-			newUpgrades[len(activeUpgrades)].Timestamp(), // this indexes to the first element in newUpgrades after the end of activeUpgrades
+			activationOf(newUpgrades[len(activeUpgrades)]), // this indexes to the first element in newUpgrades after the end of activeUpgrades
 		)
 	}
 
@@ -334,11 +498,11 @@ func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpg
 }
 
 // EnabledStatefulPrecompiles returns a slice of stateful precompile configs that
-// have been activated through an upgrade.
-func (c *ChainConfig) EnabledStatefulPrecompiles(blockTimestamp *big.Int) []precompile.StatefulPrecompileConfig {
+// have been activated through an upgrade at or before the block with [blockNumber] and [blockTimestamp].
+func (c *ChainConfig) EnabledStatefulPrecompiles(blockNumber *big.Int, blockTimestamp *big.Int) []precompile.StatefulPrecompileConfig {
 	statefulPrecompileConfigs := make([]precompile.StatefulPrecompileConfig, 0)
-	for _, key := range precompileKeys {
-		if config := c.getActivePrecompileConfig(blockTimestamp, key, c.PrecompileUpgrades); config != nil {
+	for _, module := range precompile.RegisteredModules() {
+		if config := c.getActivePrecompileConfig(blockNumber, blockTimestamp, module.ConfigKey, c.Upgrades().PrecompileUpgrades); config != nil {
 			statefulPrecompileConfigs = append(statefulPrecompileConfigs, config)
 		}
 	}
@@ -347,28 +511,37 @@ func (c *ChainConfig) EnabledStatefulPrecompiles(blockTimestamp *big.Int) []prec
 }
 
 // CheckConfigurePrecompiles checks if any of the precompiles specified by the chain config are enabled or disabled by the block
-// transition from [parentTimestamp] to the timestamp set in [blockContext]. If this is the case, it calls [Configure]
-// or [Deconfigure] to apply the necessary state transitions for the upgrade.
+// transition from the parent block with [parentTimestamp] to the block set in [blockContext]. [parentTimestamp] is nil if
+// [blockContext] is the genesis block. If this is the case, it calls [Configure]
+// or [Deconfigure] to apply the necessary state transitions for the upgrade, or [Migrate] if the upgrade enables an
+// already active precompile with a greater storage version.
 // This function is called:
 // - within genesis setup to configure the starting state for precompiles enabled at genesis,
 // - during block processing to update the state before processing the given block.
 func (c *ChainConfig) CheckConfigurePrecompiles(parentTimestamp *big.Int, blockContext precompile.BlockContext, statedb precompile.StateDB) {
-	blockTimestamp := blockContext.Timestamp()
-	for _, key := range precompileKeys { // Note: configure precompiles in a deterministic order.
-		for _, config := range c.getActivatingPrecompileConfigs(parentTimestamp, blockTimestamp, key, c.PrecompileUpgrades) {
+	blockNumber, blockTimestamp := blockContext.Number(), blockContext.Timestamp()
+	var parentNumber *big.Int
+	if parentTimestamp != nil {
+		parentNumber = new(big.Int).Sub(blockNumber, common.Big1)
+	}
+	for _, module := range precompile.RegisteredModules() { // Note: configure precompiles in a deterministic order.
+		key := module.ConfigKey
+		active := parentTimestamp != nil && c.IsPrecompileEnabled(module.Address, parentNumber, parentTimestamp)
+		for _, config := range c.getActivatingPrecompileConfigs(parentNumber, parentTimestamp, blockNumber, blockTimestamp, key, c.Upgrades().PrecompileUpgrades) {
 			// If this transition activates the upgrade, configure the stateful precompile.
-			// (or deconfigure it if it is being disabled.)
-			if config.IsDisabled() {
+			// (or deconfigure it if it is being disabled, or migrate its storage if it is already active.)
+			switch {
+			case config.IsDisabled():
 				log.Info("Disabling precompile", "name", key)
-				statedb.Suicide(config.Address())
-				// Calling Finalise here effectively commits Suicide call and wipes the contract state.
-				// This enables re-configuration of the same contract state in the same block.
-				// Without an immediate Finalise call after the Suicide, a reconfigured precompiled state can be wiped out
-				// since Suicide will be committed after the reconfiguration.
-				statedb.Finalise(true)
-			} else {
+				precompile.Deconfigure(config, statedb)
+				active = false
+			case active:
+				log.Info("Migrating precompile storage", "name", key, "storageVersion", config.GetStorageVersion())
+				precompile.Migrate(config, statedb)
+			default:
 				log.Info("Activating new precompile", "name", key, "config", config)
 				precompile.Configure(c, blockContext, config, statedb)
+				active = true
 			}
 		}
 	}
@@ -4,6 +4,7 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -18,18 +19,16 @@ func TestVerifyWithChainConfig(t *testing.T) {
 	admins := []common.Address{{1}}
 	baseConfig := *SubnetEVMDefaultChainConfig
 	config := &baseConfig
-	config.PrecompileUpgrade = PrecompileUpgrade{
-		TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(2), nil, nil),
-	}
+	config.PrecompileUpgrade = NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(2), nil, nil))
 	config.PrecompileUpgrades = []PrecompileUpgrade{
-		{
+		NewPrecompileUpgrade(
 			// disable TxAllowList at timestamp 4
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(4)),
-		},
-		{
+			precompile.NewDisableTxAllowListConfig(big.NewInt(4)),
+		),
+		NewPrecompileUpgrade(
 			// re-enable TxAllowList at timestamp 5
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil),
-		},
+			precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil),
+		),
 	}
 
 	// check this config is valid
@@ -40,9 +39,7 @@ func TestVerifyWithChainConfig(t *testing.T) {
 	badConfig := *config
 	badConfig.PrecompileUpgrades = append(
 		badConfig.PrecompileUpgrades,
-		PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(5)),
-		},
+		NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(5))),
 	)
 	err = badConfig.Verify()
 	assert.ErrorContains(t, err, "config timestamp (5) <= previous timestamp (5)")
@@ -51,9 +48,7 @@ func TestVerifyWithChainConfig(t *testing.T) {
 	badConfig = *config
 	badConfig.PrecompileUpgrades = append(
 		badConfig.PrecompileUpgrades,
-		PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil),
-		},
+		NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
 	)
 	err = badConfig.Verify()
 	assert.ErrorContains(t, err, "disable should be [true]")
@@ -69,54 +64,71 @@ func TestVerifyPrecompileUpgrades(t *testing.T) {
 		{
 			name: "enable and disable tx allow list",
 			upgrades: []PrecompileUpgrade{
-				{
-					TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil),
-				},
-				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(2)),
-				},
+				NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil)),
+				NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(2))),
 			},
 			expectedError: "",
 		},
 		{
 			name: "invalid allow list config in tx allowlist",
 			upgrades: []PrecompileUpgrade{
-				{
-					TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil),
-				},
-				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(2)),
-				},
-				{
-					TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(3), admins, admins),
-				},
+				NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil)),
+				NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(2))),
+				NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(3), admins, admins)),
 			},
 			expectedError: "cannot set address",
 		},
 		{
 			name: "invalid initial fee manager config",
 			upgrades: []PrecompileUpgrade{
-				{
-					FeeManagerConfig: precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
+				NewPrecompileUpgrade(
+					precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
 						&commontype.FeeConfig{
 							GasLimit: big.NewInt(-1),
 						}),
-				},
+				),
 			},
 			expectedError: "gasLimit = -1 cannot be less than or equal to 0",
 		},
 		{
 			name: "invalid initial fee manager config gas limit 0",
 			upgrades: []PrecompileUpgrade{
-				{
-					FeeManagerConfig: precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
+				NewPrecompileUpgrade(
+					precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
 						&commontype.FeeConfig{
 							GasLimit: big.NewInt(0),
 						}),
-				},
+				),
 			},
 			expectedError: "gasLimit = 0 cannot be less than or equal to 0",
 		},
+		{
+			name: "initial state seeded when disabling tx allow list",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil)),
+				NewPrecompileUpgrade(&precompile.TxAllowListConfig{
+					UpgradeableConfig: precompile.UpgradeableConfig{
+						BlockTimestamp: big.NewInt(2),
+						Disable:        true,
+						InitialStorage: map[common.Hash]common.Hash{{1}: {2}},
+					},
+				}),
+			},
+			expectedError: "cannot seed initial state when disabling a precompile",
+		},
+		{
+			name: "initial event with too many topics",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(&precompile.TxAllowListConfig{
+					AllowListConfig: precompile.AllowListConfig{AllowListAdmins: admins},
+					UpgradeableConfig: precompile.UpgradeableConfig{
+						BlockTimestamp: big.NewInt(1),
+						InitialEvents:  []precompile.InitialEvent{{Topics: make([]common.Hash, 5)}},
+					},
+				}),
+			},
+			expectedError: "initial event cannot have more than 4 topics",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -143,20 +155,18 @@ func TestVerifyPrecompiles(t *testing.T) {
 		expectedError string
 	}{
 		{
-			name: "invalid allow list config in tx allowlist",
-			upgrade: PrecompileUpgrade{
-				TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(3), admins, admins),
-			},
+			name:          "invalid allow list config in tx allowlist",
+			upgrade:       NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(3), admins, admins)),
 			expectedError: "cannot set address",
 		},
 		{
 			name: "invalid initial fee manager config",
-			upgrade: PrecompileUpgrade{
-				FeeManagerConfig: precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
+			upgrade: NewPrecompileUpgrade(
+				precompile.NewFeeManagerConfig(big.NewInt(3), admins, nil,
 					&commontype.FeeConfig{
 						GasLimit: big.NewInt(-1),
 					}),
-			},
+			),
 			expectedError: "gasLimit = -1 cannot be less than or equal to 0",
 		},
 	}
@@ -182,12 +192,8 @@ func TestVerifyRequiresSortedTimestamps(t *testing.T) {
 	baseConfig := *SubnetEVMDefaultChainConfig
 	config := &baseConfig
 	config.PrecompileUpgrades = []PrecompileUpgrade{
-		{
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(2), admins, nil),
-		},
-		{
-			ContractDeployerAllowListConfig: precompile.NewContractDeployerAllowListConfig(big.NewInt(1), admins, nil),
-		},
+		NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(2), admins, nil)),
+		NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(1), admins, nil)),
 	}
 
 	// block timestamps must be monotonically increasing, so this config is invalid
@@ -199,19 +205,141 @@ func TestGetPrecompileConfig(t *testing.T) {
 	assert := assert.New(t)
 	baseConfig := *SubnetEVMDefaultChainConfig
 	config := &baseConfig
-	config.PrecompileUpgrade = PrecompileUpgrade{
-		ContractDeployerAllowListConfig: precompile.NewContractDeployerAllowListConfig(big.NewInt(10), nil, nil),
-	}
+	config.PrecompileUpgrade = NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(10), nil, nil))
 
-	deployerConfig := config.GetContractDeployerAllowListConfig(big.NewInt(0))
+	deployerConfig := config.GetContractDeployerAllowListConfig(nil, big.NewInt(0))
 	assert.Nil(deployerConfig)
 
-	deployerConfig = config.GetContractDeployerAllowListConfig(big.NewInt(10))
+	deployerConfig = config.GetContractDeployerAllowListConfig(nil, big.NewInt(10))
 	assert.NotNil(deployerConfig)
 
-	deployerConfig = config.GetContractDeployerAllowListConfig(big.NewInt(11))
+	deployerConfig = config.GetContractDeployerAllowListConfig(nil, big.NewInt(11))
 	assert.NotNil(deployerConfig)
 
-	txAllowListConfig := config.GetTxAllowListConfig(big.NewInt(0))
+	txAllowListConfig := config.GetTxAllowListConfig(nil, big.NewInt(0))
 	assert.Nil(txAllowListConfig)
 }
+
+func TestPrecompileUpgradeJSON(t *testing.T) {
+	admins := []common.Address{{1}}
+	config := *SubnetEVMDefaultChainConfig
+	config.PrecompileUpgrade = NewPrecompileUpgrade(
+		precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil),
+		precompile.NewRewardManagerConfig(big.NewInt(2), admins, nil, nil),
+	)
+
+	b, err := json.Marshal(&config)
+	require.NoError(t, err)
+
+	// precompile configs are encoded at the top level of the chain config
+	var raw map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &raw))
+	require.Contains(t, raw, precompile.TxAllowListConfigKey)
+	require.Contains(t, raw, precompile.RewardManagerConfigKey)
+	require.NotContains(t, raw, precompile.FeeConfigManagerConfigKey)
+
+	var decoded ChainConfig
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	for _, key := range []string{precompile.TxAllowListConfigKey, precompile.RewardManagerConfigKey} {
+		require.True(t, config.PrecompileUpgrade.GetConfig(key).Equal(decoded.PrecompileUpgrade.GetConfig(key)), key)
+	}
+	require.Nil(t, decoded.PrecompileUpgrade.GetConfig(precompile.FeeConfigManagerConfigKey))
+
+	// precompile upgrades are encoded as a list of objects with a single key
+	upgrades := UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(3))),
+		},
+	}
+	b, err = json.Marshal(&upgrades)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"precompileUpgrades":[{"txAllowListConfig":{"blockTimestamp":3,"disable":true,"adminAddresses":null,"enabledAddresses":null}}]}`, string(b))
+
+	var decodedUpgrades UpgradeConfig
+	require.NoError(t, json.Unmarshal(b, &decodedUpgrades))
+	require.Len(t, decodedUpgrades.PrecompileUpgrades, 1)
+	require.True(t, decodedUpgrades.PrecompileUpgrades[0].GetConfig(precompile.TxAllowListConfigKey).Equal(precompile.NewDisableTxAllowListConfig(big.NewInt(3))))
+}
+
+func TestPrecompileActivationByBlockNumber(t *testing.T) {
+	admins := []common.Address{{1}}
+	baseConfig := *SubnetEVMDefaultChainConfig
+	config := &baseConfig
+	byNumber := func(number int64, disable bool) precompile.StatefulPrecompileConfig {
+		c := &precompile.TxAllowListConfig{
+			UpgradeableConfig: precompile.UpgradeableConfig{BlockNumber: big.NewInt(number), Disable: disable},
+		}
+		if !disable {
+			c.AllowListConfig.AllowListAdmins = admins
+		}
+		return c
+	}
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		NewPrecompileUpgrade(byNumber(5, false)),
+		NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(100), admins, nil)),
+		NewPrecompileUpgrade(byNumber(10, true)),
+	}
+	require.NoError(t, config.Verify())
+
+	// activation by block number ignores the block timestamp
+	require.False(t, config.IsTxAllowList(big.NewInt(4), big.NewInt(1000)))
+	require.True(t, config.IsTxAllowList(big.NewInt(5), big.NewInt(0)))
+	require.True(t, config.IsTxAllowList(big.NewInt(9), big.NewInt(1000)))
+	require.False(t, config.IsTxAllowList(big.NewInt(10), big.NewInt(1000)))
+	require.False(t, config.IsContractDeployerAllowList(big.NewInt(1000), big.NewInt(99)))
+	require.True(t, config.IsContractDeployerAllowList(big.NewInt(0), big.NewInt(100)))
+
+	tests := []struct {
+		name          string
+		upgrades      []PrecompileUpgrade
+		expectedError string
+	}{
+		{
+			name: "both timestamp and block number",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(&precompile.TxAllowListConfig{
+					UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1), BlockNumber: big.NewInt(1)},
+				}),
+			},
+			expectedError: "cannot set both a timestamp (1) and a block number (1)",
+		},
+		{
+			name: "neither timestamp nor block number",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(&precompile.TxAllowListConfig{}),
+			},
+			expectedError: "cannot have a nil timestamp and a nil block number",
+		},
+		{
+			name: "unsorted block numbers",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(byNumber(5, false)),
+				NewPrecompileUpgrade(byNumber(4, true)),
+			},
+			expectedError: "config block number (4) < previous block number (5)",
+		},
+		{
+			name: "mixed activation of the same precompile",
+			upgrades: []PrecompileUpgrade{
+				NewPrecompileUpgrade(byNumber(5, false)),
+				NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(10))),
+			},
+			expectedError: "config activates by timestamp but previous txAllowListConfig upgrade activates by block number",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			badConfig := baseConfig
+			badConfig.PrecompileUpgrades = tt.upgrades
+			require.ErrorContains(t, badConfig.Verify(), tt.expectedError)
+		})
+	}
+
+	// upgrades activated by block number are checked for compatibility against the last accepted height
+	newConfig := *config
+	newConfig.PrecompileUpgrades = config.PrecompileUpgrades[1:]
+	require.Nil(t, config.CheckPrecompilesCompatible(newConfig.PrecompileUpgrades, big.NewInt(4), big.NewInt(1000)))
+	err := config.CheckPrecompilesCompatible(newConfig.PrecompileUpgrades, big.NewInt(5), big.NewInt(0))
+	require.NotNil(t, err)
+	require.Equal(t, uint64(4), err.RewindTo)
+}
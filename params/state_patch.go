@@ -0,0 +1,256 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Operations of a state patch.
+const (
+	SetStorageOp       = "setStorage"
+	SetBalanceOp       = "setBalance"
+	SetAllowListRoleOp = "setAllowListRole"
+)
+
+var (
+	errNoStatePatchBlockNumber = errors.New("state patch must specify a blockNumber after the genesis")
+	errEmptyStatePatch         = errors.New("state patch must have at least one operation")
+)
+
+// allowListRoles are the names of the allow list roles set by the setAllowListRole operation.
+var allowListRoles = map[string]precompile.AllowListRole{
+	"none":    precompile.AllowListNoRole,
+	"enabled": precompile.AllowListEnabled,
+	"admin":   precompile.AllowListAdmin,
+}
+
+// StatePatch is a helper struct embedded in UpgradeConfig, representing a reviewed change to the
+// state applied before the transactions of the block at [BlockNumber], to recover from a bug without
+// editing the databases of the nodes. Since the patch is part of the upgrade config, every validator
+// applies it identically and the state root of the block commits to the patched state.
+type StatePatch struct {
+	BlockNumber *big.Int `json:"blockNumber"`
+	// ParentRoot is the state root of the parent of the block the patch was reviewed against, if set.
+	// The block cannot be built or verified on top of another state, so that a patch is never applied
+	// to a state it was not reviewed for.
+	ParentRoot *common.Hash          `json:"parentRoot,omitempty"`
+	Operations []StatePatchOperation `json:"operations"`
+}
+
+// StatePatchOperation is an operation of a state patch:
+//   - setStorage sets the storage slot [Key] of [Address] to [Value],
+//   - setBalance sets the balance of [Address] to [Balance],
+//   - setAllowListRole sets the role of [Address] on the allow list of the precompile with the config
+//     key [Precompile] to [Role] ("none", "enabled" or "admin").
+type StatePatchOperation struct {
+	Op         string                `json:"op"`
+	Address    common.Address        `json:"address"`
+	Key        *common.Hash          `json:"key,omitempty"`
+	Value      *common.Hash          `json:"value,omitempty"`
+	Balance    *math.HexOrDecimal256 `json:"balance,omitempty"`
+	Precompile string                `json:"precompile,omitempty"`
+	Role       string                `json:"role,omitempty"`
+}
+
+// StatePatchChange is the change of a storage slot or of a balance made by an operation of a state patch.
+type StatePatchChange struct {
+	Address common.Address `json:"address"`
+	// Key is the storage slot that changed, or nil if the balance changed.
+	Key    *common.Hash `json:"key,omitempty"`
+	Before string       `json:"before"`
+	After  string       `json:"after"`
+}
+
+// Hash returns the hash of the canonical encoding of [p], which the validators compare to check that
+// they apply the same patch.
+func (p *StatePatch) Hash() common.Hash {
+	data, err := json.Marshal(p)
+	if err != nil {
+		log.Error("failed to encode state patch for hashing", "err", err)
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+// Verify returns an error if the state patch is invalid.
+func (p *StatePatch) Verify() error {
+	if p.BlockNumber == nil || p.BlockNumber.Sign() <= 0 {
+		return errNoStatePatchBlockNumber
+	}
+	if len(p.Operations) == 0 {
+		return errEmptyStatePatch
+	}
+	for i := range p.Operations {
+		if err := p.Operations[i].verify(); err != nil {
+			return utils.WithFieldPath(fmt.Sprintf("operations[%d]", i), err)
+		}
+	}
+	return nil
+}
+
+func (o *StatePatchOperation) verify() error {
+	switch o.Op {
+	case SetStorageOp:
+		if o.Key == nil || o.Value == nil {
+			return fmt.Errorf("%s must specify a key and a value", o.Op)
+		}
+	case SetBalanceOp:
+		if o.Balance == nil || (*big.Int)(o.Balance).Sign() < 0 {
+			return fmt.Errorf("%s must specify a non-negative balance", o.Op)
+		}
+	case SetAllowListRoleOp:
+		module, ok := precompile.GetRegisteredModule(o.Precompile)
+		if !ok {
+			return fmt.Errorf("%s: unknown precompile %q", o.Op, o.Precompile)
+		}
+		if _, ok := module.Describe().Properties["adminAddresses"]; !ok {
+			return fmt.Errorf("%s: precompile %q has no allow list", o.Op, o.Precompile)
+		}
+		if _, ok := allowListRoles[o.Role]; !ok {
+			return fmt.Errorf("%s: unknown role %q, expected none, enabled or admin", o.Op, o.Role)
+		}
+	default:
+		return fmt.Errorf("unknown operation %q, expected %s, %s or %s", o.Op, SetStorageOp, SetBalanceOp, SetAllowListRoleOp)
+	}
+	return nil
+}
+
+// Apply applies the operations of [p] to [statedb] in order, and returns the changes they made.
+// Assumes [p] has been verified.
+func (p *StatePatch) Apply(statedb precompile.StateDB) []StatePatchChange {
+	changes := make([]StatePatchChange, 0, len(p.Operations))
+	for _, o := range p.Operations {
+		address, key, value := o.Address, common.Hash{}, common.Hash{}
+		switch o.Op {
+		case SetBalanceOp:
+			before, after := statedb.GetBalance(address), (*big.Int)(o.Balance)
+			if !statedb.Exist(address) {
+				statedb.CreateAccount(address)
+			}
+			statedb.SubBalance(address, before)
+			statedb.AddBalance(address, after)
+			changes = append(changes, StatePatchChange{Address: address, Before: before.String(), After: after.String()})
+			continue
+		case SetStorageOp:
+			key, value = *o.Key, *o.Value
+		case SetAllowListRoleOp:
+			module, _ := precompile.GetRegisteredModule(o.Precompile)
+			address, key, value = module.Address, o.Address.Hash(), common.Hash(allowListRoles[o.Role])
+		}
+		before := statedb.GetState(address, key)
+		if !statedb.Exist(address) {
+			// Make sure the account is not empty, so that its storage is not deleted at the end of
+			// the block, as for the precompiles.
+			statedb.CreateAccount(address)
+			statedb.SetNonce(address, 1)
+		}
+		statedb.SetState(address, key, value)
+		slot := key
+		changes = append(changes, StatePatchChange{Address: address, Key: &slot, Before: before.Hex(), After: value.Hex()})
+	}
+	return changes
+}
+
+// StatePatchAt returns the state patch applied before the transactions of the block with [blockNumber],
+// or nil if there is none.
+func (c *ChainConfig) StatePatchAt(blockNumber *big.Int) *StatePatch {
+	patches := c.Upgrades().StatePatches
+	for i := range patches {
+		if patches[i].BlockNumber.Cmp(blockNumber) == 0 {
+			return &patches[i]
+		}
+	}
+	return nil
+}
+
+// ApplyStatePatch applies the state patch scheduled at the block of [blockContext], if any, to
+// [statedb], which holds the state of the parent block with [parentRoot]. Returns an error if the
+// patch was reviewed against another parent state.
+// This function is called during block processing and building, after the precompiles activated by
+// the block are configured.
+func (c *ChainConfig) ApplyStatePatch(parentRoot common.Hash, blockContext precompile.BlockContext, statedb precompile.StateDB) error {
+	patch := c.StatePatchAt(blockContext.Number())
+	if patch == nil {
+		return nil
+	}
+	if patch.ParentRoot != nil && *patch.ParentRoot != parentRoot {
+		return fmt.Errorf("state patch at block %d expects parent root %s, found %s", blockContext.Number(), patch.ParentRoot, parentRoot)
+	}
+	changes := patch.Apply(statedb)
+	log.Info("Applied state patch", "number", blockContext.Number(), "hash", patch.Hash(), "changes", len(changes))
+	return nil
+}
+
+// verifyStatePatches checks that each state patch is valid and that the patches are listed in
+// strictly increasing order of block number.
+func (c *ChainConfig) verifyStatePatches() error {
+	var lastNumber *big.Int
+	patches := c.Upgrades().StatePatches
+	for i := range patches {
+		patch := &patches[i]
+		if err := patch.Verify(); err != nil {
+			return utils.WithFieldPath(fmt.Sprintf("statePatches[%d]", i), err)
+		}
+		if lastNumber != nil && patch.BlockNumber.Cmp(lastNumber) <= 0 {
+			return fmt.Errorf("StatePatches[%d]: block number (%v) <= previous block number (%v)", i, patch.BlockNumber, lastNumber)
+		}
+		lastNumber = patch.BlockNumber
+	}
+	return nil
+}
+
+// checkStatePatchesCompatible verifies that [statePatches] is compatible with the state patches of [c]
+// at [lastHeight]. Patches that have already been applied cannot be modified or absent from
+// [statePatches], and new patches cannot be scheduled retroactively.
+func (c *ChainConfig) checkStatePatchesCompatible(statePatches []StatePatch, lastHeight *big.Int) *ConfigCompatError {
+	appliedPatches := appliedStatePatches(c.Upgrades().StatePatches, lastHeight)
+	newPatches := appliedStatePatches(statePatches, lastHeight)
+
+	for i := range appliedPatches {
+		if len(newPatches) <= i {
+			return newCompatError(
+				fmt.Sprintf("missing StatePatch[%d]", i),
+				appliedPatches[i].BlockNumber,
+				nil,
+			)
+		}
+		if appliedPatches[i].Hash() != newPatches[i].Hash() {
+			return newCompatError(
+				fmt.Sprintf("StatePatch[%d]", i),
+				appliedPatches[i].BlockNumber,
+				newPatches[i].BlockNumber,
+			)
+		}
+	}
+	if len(newPatches) > len(appliedPatches) {
+		return newCompatError(
+			fmt.Sprintf("cannot retroactively apply StatePatch[%d]", len(appliedPatches)),
+			nil,
+			newPatches[len(appliedPatches)].BlockNumber,
+		)
+	}
+	return nil
+}
+
+// appliedStatePatches returns the prefix of [patches] applied at or before [blockNumber].
+// Assumes [patches] is sorted by block number, as enforced by verifyStatePatches.
+func appliedStatePatches(patches []StatePatch, blockNumber *big.Int) []StatePatch {
+	for i, patch := range patches {
+		if !utils.IsForked(patch.BlockNumber, blockNumber) {
+			return patches[:i]
+		}
+	}
+	return patches
+}
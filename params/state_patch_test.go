@@ -0,0 +1,165 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyStatePatches(t *testing.T) {
+	key, value := common.Hash{1}, common.Hash{2}
+	balance := (*math.HexOrDecimal256)(big.NewInt(100))
+	negative := (*math.HexOrDecimal256)(big.NewInt(-1))
+	setStorage := StatePatchOperation{Op: SetStorageOp, Address: common.Address{1}, Key: &key, Value: &value}
+
+	tests := map[string]struct {
+		patches       []StatePatch
+		expectedError string
+	}{
+		"valid patches": {
+			patches: []StatePatch{
+				{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{setStorage}},
+				{BlockNumber: big.NewInt(10), Operations: []StatePatchOperation{
+					{Op: SetBalanceOp, Address: common.Address{1}, Balance: balance},
+					{Op: SetAllowListRoleOp, Address: common.Address{1}, Precompile: precompile.ContractNativeMinterConfigKey, Role: "admin"},
+				}},
+			},
+		},
+		"missing block number": {
+			patches:       []StatePatch{{Operations: []StatePatchOperation{setStorage}}},
+			expectedError: "must specify a blockNumber",
+		},
+		"patch genesis": {
+			patches:       []StatePatch{{BlockNumber: big.NewInt(0), Operations: []StatePatchOperation{setStorage}}},
+			expectedError: "must specify a blockNumber after the genesis",
+		},
+		"empty patch": {
+			patches:       []StatePatch{{BlockNumber: big.NewInt(5)}},
+			expectedError: "must have at least one operation",
+		},
+		"unknown operation": {
+			patches:       []StatePatch{{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{{Op: "setCode"}}}},
+			expectedError: "statePatches[0].operations[0] is invalid: unknown operation \"setCode\"",
+		},
+		"storage without value": {
+			patches: []StatePatch{{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{
+				{Op: SetStorageOp, Address: common.Address{1}, Key: &key},
+			}}},
+			expectedError: "setStorage must specify a key and a value",
+		},
+		"negative balance": {
+			patches: []StatePatch{{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{
+				{Op: SetBalanceOp, Address: common.Address{1}, Balance: negative},
+			}}},
+			expectedError: "setBalance must specify a non-negative balance",
+		},
+		"precompile without allow list": {
+			patches: []StatePatch{{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{
+				{Op: SetAllowListRoleOp, Address: common.Address{1}, Precompile: precompile.ChainConfigReaderConfigKey, Role: "admin"},
+			}}},
+			expectedError: "has no allow list",
+		},
+		"unknown role": {
+			patches: []StatePatch{{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{
+				{Op: SetAllowListRoleOp, Address: common.Address{1}, Precompile: precompile.ContractNativeMinterConfigKey, Role: "owner"},
+			}}},
+			expectedError: "unknown role \"owner\"",
+		},
+		"non-increasing block numbers": {
+			patches: []StatePatch{
+				{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{setStorage}},
+				{BlockNumber: big.NewInt(5), Operations: []StatePatchOperation{setStorage}},
+			},
+			expectedError: "block number (5) <= previous block number (5)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			chainConfig := *TestChainConfig
+			chainConfig.StatePatches = tt.patches
+			err := chainConfig.Verify()
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestCheckStatePatchesCompatible(t *testing.T) {
+	key, value, otherValue := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	patch := func(number int64, value *common.Hash) StatePatch {
+		return StatePatch{
+			BlockNumber: big.NewInt(number),
+			Operations:  []StatePatchOperation{{Op: SetStorageOp, Address: common.Address{1}, Key: &key, Value: value}},
+		}
+	}
+
+	chainConfig := *TestChainConfig
+	chainConfig.StatePatches = []StatePatch{patch(10, &value), patch(30, &value)}
+
+	tests := map[string]struct {
+		patches       []StatePatch
+		expectedError string
+	}{
+		"unchanged": {
+			patches: chainConfig.StatePatches,
+		},
+		"change patch that has not been applied": {
+			patches: []StatePatch{patch(10, &value), patch(40, &otherValue)},
+		},
+		"remove applied patch": {
+			patches:       []StatePatch{},
+			expectedError: "missing StatePatch[0]",
+		},
+		"modify applied patch": {
+			patches:       []StatePatch{patch(10, &otherValue)},
+			expectedError: "mismatching StatePatch[0]",
+		},
+		"retroactive patch": {
+			patches:       []StatePatch{patch(10, &value), patch(15, &value)},
+			expectedError: "cannot retroactively apply StatePatch[1]",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			newConfig := chainConfig
+			newConfig.StatePatches = tt.patches
+			err := chainConfig.CheckCompatible(&newConfig, 20, 20)
+			if tt.expectedError == "" {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestStatePatchAt(t *testing.T) {
+	key, value := common.Hash{1}, common.Hash{2}
+	chainConfig := *TestChainConfig
+	chainConfig.StatePatches = []StatePatch{{
+		BlockNumber: big.NewInt(10),
+		Operations:  []StatePatchOperation{{Op: SetStorageOp, Address: common.Address{1}, Key: &key, Value: &value}},
+	}}
+	require.NoError(t, chainConfig.Verify())
+
+	require.Nil(t, chainConfig.StatePatchAt(big.NewInt(9)))
+	require.Equal(t, &chainConfig.StatePatches[0], chainConfig.StatePatchAt(big.NewInt(10)))
+	require.Nil(t, chainConfig.StatePatchAt(big.NewInt(11)))
+
+	// The hash commits to the reviewed parent root
+	patched := chainConfig.StatePatches[0]
+	root := common.Hash{0xaa}
+	patched.ParentRoot = &root
+	require.NotEqual(t, chainConfig.StatePatches[0].Hash(), patched.Hash())
+}
@@ -0,0 +1,128 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package params
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+)
+
+// UpgradeConfigErrorKind classifies the errors returned by ValidateUpgradeConfig.
+type UpgradeConfigErrorKind string
+
+const (
+	// InvalidPrecompileConfig is the kind of errors caused by an invalid precompile config,
+	// such as an invalid initial allow list or fee config.
+	InvalidPrecompileConfig UpgradeConfigErrorKind = "invalidPrecompileConfig"
+	// InvalidUpgradeSchedule is the kind of errors caused by upgrades that are not well ordered,
+	// such as activations that are not increasing or overlapping enable and disable upgrades.
+	InvalidUpgradeSchedule UpgradeConfigErrorKind = "invalidUpgradeSchedule"
+	// IncompatibleUpgrade is the kind of errors caused by modifying or removing upgrades that
+	// have already activated on the chain.
+	IncompatibleUpgrade UpgradeConfigErrorKind = "incompatibleUpgrade"
+)
+
+// UpgradeConfigError is an error found by ValidateUpgradeConfig in a proposed upgrade config.
+type UpgradeConfigError struct {
+	Kind    UpgradeConfigErrorKind `json:"kind"`
+	Message string                 `json:"message"`
+	// PrecompileUpgrade is the index of the precompile upgrade causing the error, if it is
+	// caused by a single upgrade.
+	PrecompileUpgrade *int `json:"precompileUpgrade,omitempty"`
+	// RewindTo is the height the chain would have to be rewound to for an [IncompatibleUpgrade]
+	// to be applied.
+	RewindTo *uint64 `json:"rewindTo,omitempty"`
+}
+
+func (e *UpgradeConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+}
+
+// Upgrades returns the upgrade config of [c]: the one last set by SetUpgradeConfig if any, or
+// [c.UpgradeConfig] otherwise. The returned config is shared with the other readers of [c] and
+// must not be modified.
+func (c *ChainConfig) Upgrades() *UpgradeConfig {
+	if c.upgrades != nil {
+		if upgrades, ok := c.upgrades.Load().(*UpgradeConfig); ok {
+			return upgrades
+		}
+	}
+	return &c.UpgradeConfig
+}
+
+// SetUpgradeConfig replaces the upgrade config of [c] with a copy of [upgradeConfig] while the chain is
+// running. The readers of [c] see either the previous or the new upgrade config, never a mix of both.
+// The first call must happen before [c] is shared with other goroutines.
+func (c *ChainConfig) SetUpgradeConfig(upgradeConfig UpgradeConfig) {
+	if c.upgrades == nil {
+		c.upgrades = new(atomic.Value)
+	}
+	c.upgrades.Store(upgradeConfig.copy())
+}
+
+// WithUpgradeConfig returns a copy of [c] with [upgradeConfig] as its upgrade config, so that a proposed
+// upgrade config can be checked or replayed without modifying [c].
+func (c *ChainConfig) WithUpgradeConfig(upgradeConfig UpgradeConfig) *ChainConfig {
+	config := *c
+	config.UpgradeConfig = upgradeConfig
+	config.upgrades = nil
+	return &config
+}
+
+// copy returns a copy of [u] that does not share its lists of upgrades with [u].
+func (u UpgradeConfig) copy() *UpgradeConfig {
+	if u.NetworkUpgrades != nil {
+		networkUpgrades := *u.NetworkUpgrades
+		u.NetworkUpgrades = &networkUpgrades
+	}
+	u.PrecompileUpgrades = append([]PrecompileUpgrade(nil), u.PrecompileUpgrades...)
+	u.ParameterUpgrades = append([]ParameterUpgrade(nil), u.ParameterUpgrades...)
+	u.StatePatches = append([]StatePatch(nil), u.StatePatches...)
+	return &u
+}
+
+// ValidateUpgradeConfig checks whether [proposed] can replace the upgrade config of [current] on a chain whose
+// last block has [height] and [timestamp], so that operators can verify an upgrade config before distributing it
+// to validators. Returns all errors found in [proposed], or nil if it can be applied.
+// Errors of the upgrade schedule are only reported if all the precompile configs are valid.
+func ValidateUpgradeConfig(current *ChainConfig, proposed *UpgradeConfig, height uint64, timestamp uint64) []*UpgradeConfigError {
+	var errs []*UpgradeConfigError
+	for i, upgrade := range proposed.PrecompileUpgrades {
+		for _, module := range precompile.RegisteredModules() {
+			config, ok := upgrade.getByKey(module.ConfigKey)
+			if !ok {
+				continue
+			}
+			if err := config.Verify(); err != nil {
+				index := i
+				errs = append(errs, &UpgradeConfigError{
+					Kind:              InvalidPrecompileConfig,
+					Message:           fmt.Sprintf("PrecompileUpgrades[%d] %s: %s", i, module.ConfigKey, err),
+					PrecompileUpgrade: &index,
+				})
+			}
+		}
+	}
+
+	newConfig := current.WithUpgradeConfig(*proposed)
+	if len(errs) == 0 {
+		if err := newConfig.Verify(); err != nil {
+			errs = append(errs, &UpgradeConfigError{
+				Kind:    InvalidUpgradeSchedule,
+				Message: err.Error(),
+			})
+		}
+	}
+	if err := current.CheckCompatible(newConfig, height, timestamp); err != nil {
+		rewindTo := err.RewindTo
+		errs = append(errs, &UpgradeConfigError{
+			Kind:     IncompatibleUpgrade,
+			Message:  err.Error(),
+			RewindTo: &rewindTo,
+		})
+	}
+	return errs
+}
@@ -15,7 +15,7 @@ import (
 func TestVerifyUpgradeConfig(t *testing.T) {
 	admins := []common.Address{{1}}
 	chainConfig := *TestChainConfig
-	chainConfig.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil)
+	chainConfig.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil))
 
 	type test struct {
 		upgrades            []PrecompileUpgrade
@@ -26,25 +26,19 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 		"upgrade bytes conflicts with genesis (re-enable without disable)": {
 			expectedErrorString: "disable should be [true]",
 			upgrades: []PrecompileUpgrade{
-				{
-					TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(2), admins, nil),
-				},
+				NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(2), admins, nil)),
 			},
 		},
 		"upgrade bytes conflicts with genesis (disable before enable)": {
 			expectedErrorString: "config timestamp (0) <= previous timestamp (1)",
 			upgrades: []PrecompileUpgrade{
-				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(0)),
-				},
+				NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(0))),
 			},
 		},
 		"upgrade bytes conflicts with genesis (disable same time as enable)": {
 			expectedErrorString: "config timestamp (1) <= previous timestamp (1)",
 			upgrades: []PrecompileUpgrade{
-				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(1)),
-				},
+				NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(1))),
 			},
 		},
 	}
@@ -70,8 +64,8 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 	admins := []common.Address{{1}}
 	chainConfig := *TestChainConfig
-	chainConfig.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil)
-	chainConfig.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(10), admins, nil)
+	chainConfig.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(1), admins, nil))
+	chainConfig.PrecompileUpgrade.SetConfig(precompile.NewContractDeployerAllowListConfig(big.NewInt(10), admins, nil))
 
 	type test struct {
 		configs             []*UpgradeConfig
@@ -85,12 +79,8 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 			},
@@ -100,22 +90,14 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(8), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(8), admins, nil)),
 					},
 				},
 			},
@@ -126,22 +108,14 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(8), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(8), admins, nil)),
 					},
 				},
 			},
@@ -151,19 +125,13 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
 					},
 				},
 			},
@@ -174,19 +142,13 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
 					},
 				},
 			},
@@ -197,23 +159,17 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(
 							// uses a different (empty) admin list, not allowed
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), []common.Address{}, nil),
-						},
+							precompile.NewTxAllowListConfig(big.NewInt(7), []common.Address{}, nil),
+						),
 					},
 				},
 			},
@@ -223,22 +179,14 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 			configs: []*UpgradeConfig{
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
-						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
-						},
-						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil),
-						},
+						NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(6))),
+						NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(7), admins, nil)),
 					},
 				},
 			},
@@ -278,3 +226,108 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUpgradeConfig(t *testing.T) {
+	admins := []common.Address{{1}}
+	chainConfig := *TestChainConfig
+	chainConfig.UpgradeConfig = UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
+		},
+	}
+
+	tests := map[string]struct {
+		proposed      UpgradeConfig
+		expectedKinds []UpgradeConfigErrorKind
+		expectedIndex []int
+	}{
+		"add upgrade": {
+			proposed: UpgradeConfig{
+				PrecompileUpgrades: []PrecompileUpgrade{
+					NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
+					NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(20))),
+				},
+			},
+		},
+		"invalid initial configs": {
+			proposed: UpgradeConfig{
+				PrecompileUpgrades: []PrecompileUpgrade{
+					NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
+					NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(20), admins, admins)),
+					NewPrecompileUpgrade(precompile.NewValidatorInfoConfig(big.NewInt(20), 0)),
+				},
+			},
+			expectedKinds: []UpgradeConfigErrorKind{InvalidPrecompileConfig, InvalidPrecompileConfig},
+			expectedIndex: []int{1, 2},
+		},
+		"overlapping enable": {
+			proposed: UpgradeConfig{
+				PrecompileUpgrades: []PrecompileUpgrade{
+					NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
+					NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(20), admins, nil)),
+				},
+			},
+			expectedKinds: []UpgradeConfigErrorKind{InvalidUpgradeSchedule},
+		},
+		"remove activated upgrade": {
+			proposed: UpgradeConfig{
+				PrecompileUpgrades: []PrecompileUpgrade{
+					NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(big.NewInt(20), admins, nil)),
+				},
+			},
+			expectedKinds: []UpgradeConfigErrorKind{IncompatibleUpgrade},
+		},
+		"invalid and incompatible": {
+			proposed: UpgradeConfig{
+				PrecompileUpgrades: []PrecompileUpgrade{
+					NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(6), admins, admins)),
+				},
+			},
+			expectedKinds: []UpgradeConfigErrorKind{InvalidPrecompileConfig, IncompatibleUpgrade},
+			expectedIndex: []int{0},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := ValidateUpgradeConfig(&chainConfig, &tt.proposed, 10, 10)
+			kinds := make([]UpgradeConfigErrorKind, 0, len(errs))
+			indexes := make([]int, 0, len(errs))
+			for _, err := range errs {
+				kinds = append(kinds, err.Kind)
+				if err.PrecompileUpgrade != nil {
+					indexes = append(indexes, *err.PrecompileUpgrade)
+				}
+				if err.Kind == IncompatibleUpgrade {
+					if assert.NotNil(t, err.RewindTo) {
+						assert.Equal(t, uint64(4), *err.RewindTo)
+					}
+				}
+			}
+			assert.ElementsMatch(t, tt.expectedKinds, kinds)
+			assert.ElementsMatch(t, tt.expectedIndex, indexes)
+		})
+	}
+}
+
+func TestSetUpgradeConfig(t *testing.T) {
+	admins := []common.Address{{1}}
+	chainConfig := *TestChainConfig
+	chainConfig.SetUpgradeConfig(UpgradeConfig{})
+	assert.False(t, chainConfig.IsTxAllowList(big.NewInt(0), big.NewInt(10)))
+
+	upgrades := []PrecompileUpgrade{
+		NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(5), admins, nil)),
+	}
+	chainConfig.SetUpgradeConfig(UpgradeConfig{PrecompileUpgrades: upgrades})
+	assert.True(t, chainConfig.IsTxAllowList(big.NewInt(0), big.NewInt(10)))
+	assert.Empty(t, chainConfig.UpgradeConfig.PrecompileUpgrades)
+
+	// the upgrade config is copied, so modifying the upgrades after setting them has no effect
+	upgrades[0] = NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(5)))
+	assert.True(t, chainConfig.IsTxAllowList(big.NewInt(0), big.NewInt(10)))
+
+	// copies with another upgrade config do not affect the chain config
+	proposed := chainConfig.WithUpgradeConfig(UpgradeConfig{})
+	assert.False(t, proposed.IsTxAllowList(big.NewInt(0), big.NewInt(10)))
+	assert.True(t, chainConfig.IsTxAllowList(big.NewInt(0), big.NewInt(10)))
+}
@@ -582,6 +582,12 @@ func (t *testGossipHandler) HandleTxs(nodeID ids.NodeID, msg message.TxsGossip)
 	return nil
 }
 
+func (t *testGossipHandler) HandleChainConfigHash(nodeID ids.NodeID, msg message.ChainConfigHashGossip) error {
+	t.received = true
+	t.nodeID = nodeID
+	return nil
+}
+
 type testRequestHandler struct {
 	message.RequestHandler
 	calls              uint32
@@ -0,0 +1,69 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/plugin/dev"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// runDev runs a development chain until the process is interrupted.
+func runDev() error {
+	v, err := parsedFlags()
+	if err != nil {
+		return err
+	}
+
+	var genesis *core.Genesis
+	if path := v.GetString(devGenesisKey); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		genesis = new(core.Genesis)
+		if err := json.Unmarshal(data, genesis); err != nil {
+			return fmt.Errorf("failed to parse genesis: %w", err)
+		}
+	} else {
+		var precompiles []string
+		if list := v.GetString(devPrecompilesKey); list != "" {
+			precompiles = strings.Split(list, ",")
+		}
+		if genesis, err = dev.Genesis(precompiles); err != nil {
+			return fmt.Errorf("failed to build genesis: %w", err)
+		}
+	}
+
+	vmConfig, err := json.Marshal(map[string]string{"log-level": v.GetString(devLogLevelKey)})
+	if err != nil {
+		return err
+	}
+	node, err := dev.Start(genesis, dev.Config{Address: v.GetString(devAddressKey), VMConfig: string(vmConfig)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Development chain %s serving the eth RPC API at %s\n\n", genesis.Config.ChainID, node.Endpoint())
+	if v.GetString(devGenesisKey) == "" {
+		fmt.Println("Funded accounts (the keys are public, never use them on a live network):")
+		for i, key := range dev.Keys() {
+			fmt.Printf("(%d) %s %s\n", i, crypto.PubkeyToAddress(key.PublicKey), hexutil.Encode(crypto.FromECDSA(key)))
+		}
+		fmt.Println()
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	<-sigs
+	return node.Stop()
+}
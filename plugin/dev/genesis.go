@@ -0,0 +1,125 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dev
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/cmd/genesisgen/builder"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AllPrecompiles selects every registered precompile that can be enabled without a custom config.
+const AllPrecompiles = "all"
+
+// ChainID is the chain ID of the default development genesis.
+var ChainID = big.NewInt(99999)
+
+// defaultBalance is the balance of each funded address of the default genesis.
+var defaultBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+
+// devKeys are the private keys of the accounts funded by the development genesis. They are the keys of
+// the local network of the contract examples, starting with the ewoq key, so that the same wallets and
+// hardhat configs work against a development node.
+var devKeys = []string{
+	"56289e99c94b6912bfc12adc093c9b51124f0dc54ac7a766b2bc5ccf558d8027",
+	"7b4198529994b0dc604278c99d153cfd069d594753d471171a1d102a10438e07",
+	"15614556be13730e9e8d6eacc1603143e7b96987429df8726384c2ec4502ef6e",
+	"31b571bf6894a248831ff937bb49f7754509fe93bbd2517c9c73c4144c0e97dc",
+	"6934bef917e01692b789da754a0eae31a8536eb465e7bff752ea291dad88c675",
+	"e700bdbdbc279b808b1ec45f8c2370e4616d3a02c336e68d85d4668e08f53cff",
+	"bbc2865b76ba28016bc2255c7504d000e046ae01934b04c694592a6276988630",
+	"cdbfd34f687ced8c6968854f8a99ae47712c4f4183b78dcc4a903d1bfe8cbf60",
+	"86f78c5416151fe3546dece84fda4b4b1e36089f2dbc48496faf3a950f16157c",
+	"750839e9dbbd2a0910efe40f50b2f3b2f2f59f5580bb4b83bd8c1201cf9a010a",
+}
+
+// Keys returns the private keys of the accounts funded by [Genesis]. These keys are public and must
+// never hold funds on a live network.
+func Keys() []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, len(devKeys))
+	for i, hex := range devKeys {
+		keys[i] = crypto.ToECDSAUnsafe(common.FromHex(hex))
+	}
+	return keys
+}
+
+// Genesis returns the development genesis, funding the accounts of [Keys] and making them admins of
+// the allow lists of the precompiles with the config keys [precompiles]. [AllPrecompiles] selects each
+// registered precompile whose default config is valid.
+func Genesis(precompiles []string) (*core.Genesis, error) {
+	keys := Keys()
+	funded := make([]common.Address, len(keys))
+	for i, key := range keys {
+		funded[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return FundedGenesis(funded, precompiles)
+}
+
+// FundedGenesis returns a genesis funding each of [funded] and making them admins of the allow lists
+// of the precompiles with the config keys [precompiles]. Precompiles without an allow list are enabled
+// with an empty config.
+func FundedGenesis(funded []common.Address, precompiles []string) (*core.Genesis, error) {
+	spec := &builder.Spec{
+		ChainID:     ChainID,
+		Precompiles: make(map[string]json.RawMessage),
+	}
+	for _, address := range funded {
+		spec.Alloc = append(spec.Alloc, builder.Allocation{
+			Address: address,
+			Balance: (*builder.Amount)(new(big.Int).Set(defaultBalance)),
+		})
+	}
+	for _, configKey := range precompiles {
+		if configKey != AllPrecompiles {
+			module, ok := precompile.GetRegisteredModule(configKey)
+			if !ok {
+				return nil, fmt.Errorf("unknown precompile %q", configKey)
+			}
+			config, err := defaultPrecompileConfig(module, funded)
+			if err != nil {
+				return nil, err
+			}
+			spec.Precompiles[configKey] = config
+			continue
+		}
+		for _, module := range precompile.RegisteredModules() {
+			config, err := defaultPrecompileConfig(module, funded)
+			if err != nil {
+				// Precompiles that require parameters (e.g. an epoch duration) are only enabled
+				// when they are selected explicitly.
+				continue
+			}
+			spec.Precompiles[module.ConfigKey] = config
+		}
+	}
+	return builder.Build(spec)
+}
+
+// defaultPrecompileConfig returns the JSON config of [module] making [admins] the admins of its allow
+// list, if it has one. Returns an error if the config is invalid.
+func defaultPrecompileConfig(module precompile.Module, admins []common.Address) (json.RawMessage, error) {
+	config := json.RawMessage("{}")
+	if _, ok := module.Describe().Properties["adminAddresses"]; ok {
+		var err error
+		if config, err = json.Marshal(precompile.AllowListConfig{AllowListAdmins: admins}); err != nil {
+			return nil, err
+		}
+	}
+	parsed := module.NewConfig()
+	if err := json.Unmarshal(config, parsed); err != nil {
+		return nil, err
+	}
+	if err := parsed.Verify(); err != nil {
+		return nil, fmt.Errorf("%s: %w", module.ConfigKey, err)
+	}
+	return config, nil
+}
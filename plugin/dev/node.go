@@ -0,0 +1,214 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package dev runs an in-process subnet-evm instance without AvalancheGo, for developing against
+// and applying load to a chain locally. The instance accepts a block as soon as the VM signals that
+// transactions are pending, as a single validator would, so that transactions are final as soon as
+// they are included.
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/database/manager"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
+	avalancheConstants "github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/plugin/evm"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// defaultVMConfig is the config of the in-process VM if none is given, which only logs errors
+// so that the output of the embedding program is not drowned.
+const defaultVMConfig = `{"log-level": "error"}`
+
+// Config configures an in-process node.
+type Config struct {
+	// Address is the address the APIs of the VM are served at (default = a free local port).
+	Address string
+	// VMConfig is the JSON config of the VM, in the format of the chain config of a node.
+	VMConfig string
+}
+
+// Node is an in-process subnet-evm instance serving the APIs of the VM over HTTP. The eth RPC API
+// is served at both "/" and "/rpc", and its websocket API at "/ws".
+type Node struct {
+	vm       *evm.VM
+	ctx      *snow.Context
+	toEngine chan commonEng.Message
+	server   *http.Server
+	endpoint string
+
+	shutdownChan chan struct{}
+	shutdownWg   sync.WaitGroup
+}
+
+// Start initializes a VM with [genesis] and starts accepting the blocks it builds.
+func Start(genesis *core.Genesis, config Config) (*Node, error) {
+	genesisBytes, err := json.Marshal(genesis)
+	if err != nil {
+		return nil, err
+	}
+	if config.Address == "" {
+		config.Address = "127.0.0.1:0"
+	}
+	if config.VMConfig == "" {
+		config.VMConfig = defaultVMConfig
+	}
+
+	ctx := snow.DefaultContextTest()
+	ctx.NetworkID = avalancheConstants.LocalID
+	ctx.SubnetID = ids.GenerateTestID()
+	ctx.ChainID = ids.GenerateTestID()
+	ctx.NodeID = ids.GenerateTestNodeID()
+	if err := ctx.BCLookup.(ids.Aliaser).Alias(ctx.ChainID, ctx.ChainID.String()); err != nil {
+		return nil, err
+	}
+	dbManager := manager.NewMemDB(version.CurrentDatabase)
+
+	n := &Node{
+		vm:           &evm.VM{},
+		ctx:          ctx,
+		toEngine:     make(chan commonEng.Message, 1),
+		shutdownChan: make(chan struct{}),
+	}
+	err = n.vm.Initialize(context.Background(), ctx, dbManager, genesisBytes, nil, []byte(config.VMConfig), n.toEngine, nil, noopSender{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize VM: %w", err)
+	}
+	if err := n.vm.SetState(context.Background(), snow.Bootstrapping); err != nil {
+		return nil, err
+	}
+	if err := n.vm.SetState(context.Background(), snow.NormalOp); err != nil {
+		return nil, err
+	}
+
+	handlers, err := n.vm.CreateHandlers(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	for endpoint, handler := range handlers {
+		mux.Handle(endpoint, handler.Handler)
+	}
+	mux.Handle("/", handlers["/rpc"].Handler)
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return nil, err
+	}
+	// Serve cleartext HTTP/2 as well, so clients can multiplex requests over a single connection.
+	n.server = &http.Server{Handler: h2c.NewHandler(mux, &http2.Server{})}
+	n.endpoint = fmt.Sprintf("http://%s", listener.Addr())
+
+	n.shutdownWg.Add(2)
+	go func() {
+		defer n.shutdownWg.Done()
+		if err := n.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Development node stopped serving", "err", err)
+		}
+	}()
+	go func() {
+		defer n.shutdownWg.Done()
+		n.acceptBlocks()
+	}()
+	return n, nil
+}
+
+// Endpoint returns the URL of the eth RPC API of the node.
+func (n *Node) Endpoint() string {
+	return n.endpoint
+}
+
+// acceptBlocks builds, verifies and accepts a block every time the VM signals that transactions are
+// pending, until the node is stopped.
+func (n *Node) acceptBlocks() {
+	for {
+		select {
+		case msg := <-n.toEngine:
+			if msg != commonEng.PendingTxs {
+				continue
+			}
+			n.acceptBlock()
+		case <-n.shutdownChan:
+			return
+		}
+	}
+}
+
+// acceptBlock builds a block and accepts it. A block that cannot be built yet, such as one that does
+// not pay for the block gas cost, is dropped like the consensus engine does: the VM signals again
+// once it may be built.
+func (n *Node) acceptBlock() {
+	n.ctx.Lock.Lock()
+	defer n.ctx.Lock.Unlock()
+
+	ctx := context.Background()
+	blk, err := n.vm.BuildBlock(ctx)
+	if err != nil {
+		return
+	}
+	if err := blk.Verify(ctx); err != nil {
+		log.Error("Development node failed to verify block", "blkID", blk.ID(), "err", err)
+		return
+	}
+	if err := n.vm.SetPreference(ctx, blk.ID()); err != nil {
+		log.Error("Development node failed to set preference", "blkID", blk.ID(), "err", err)
+		return
+	}
+	if err := blk.Accept(ctx); err != nil {
+		log.Error("Development node failed to accept block", "blkID", blk.ID(), "err", err)
+	}
+}
+
+// Stop stops serving the APIs and shuts down the VM.
+func (n *Node) Stop() error {
+	err := n.server.Close()
+	close(n.shutdownChan)
+	n.shutdownWg.Wait()
+
+	n.ctx.Lock.Lock()
+	defer n.ctx.Lock.Unlock()
+	if shutdownErr := n.vm.Shutdown(context.Background()); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// noopSender drops the messages of the VM, since the node has no peers.
+type noopSender struct{}
+
+func (noopSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (noopSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (noopSender) SendAppGossip(context.Context, []byte) error {
+	return nil
+}
+
+func (noopSender) SendAppGossipSpecific(context.Context, set.Set[ids.NodeID], []byte) error {
+	return nil
+}
+
+func (noopSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (noopSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
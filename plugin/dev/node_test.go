@@ -0,0 +1,76 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dev
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethclient"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenesis(t *testing.T) {
+	genesis, err := Genesis([]string{AllPrecompiles})
+	require.NoError(t, err)
+	require.Equal(t, ChainID, genesis.Config.ChainID)
+	for _, key := range Keys() {
+		require.Equal(t, defaultBalance, genesis.Alloc[crypto.PubkeyToAddress(key.PublicKey)].Balance)
+	}
+	require.NotNil(t, genesis.Config.GetActivePrecompileConfig(precompile.TxAllowListAddress, common.Big0, common.Big0))
+	require.NotNil(t, genesis.Config.GetActivePrecompileConfig(precompile.ChainConfigReaderAddress, common.Big0, common.Big0))
+	// Precompiles that cannot be enabled without parameters are skipped
+	require.Nil(t, genesis.Config.GetActivePrecompileConfig(precompile.ValidatorInfoAddress, common.Big0, common.Big0))
+
+	_, err = Genesis([]string{precompile.ValidatorInfoConfigKey})
+	require.ErrorContains(t, err, "epoch duration")
+	_, err = Genesis([]string{"unknownConfig"})
+	require.ErrorContains(t, err, "unknown precompile")
+}
+
+func TestNodeAcceptsTransactions(t *testing.T) {
+	genesis, err := Genesis(nil)
+	require.NoError(t, err)
+	node, err := Start(genesis, Config{})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, node.Stop()) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, node.Endpoint())
+	require.NoError(t, err)
+	defer client.Close()
+
+	key := Keys()[0]
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   ChainID,
+		Nonce:     0,
+		GasTipCap: common.Big0,
+		GasFeeCap: big.NewInt(params.GWei * 100),
+		Gas:       params.TxGas,
+		To:        &common.Address{0xaa},
+		Value:     common.Big1,
+	}), types.LatestSignerForChainID(ChainID), key)
+	require.NoError(t, err)
+	require.NoError(t, client.SendTransaction(ctx, tx))
+
+	// The transaction is accepted without a consensus engine driving the VM
+	var receipt *types.Receipt
+	for receipt == nil {
+		receipt, _ = client.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, ctx.Err())
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	balance, err := client.BalanceAt(ctx, common.Address{0xaa}, nil)
+	require.NoError(t, err)
+	require.Equal(t, common.Big1, balance)
+}
@@ -0,0 +1,69 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/internal/ethapi"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that eth_createAccessList does not list the storage slots consulted by the stateful precompiles,
+// which charge a flat gas cost regardless of the access list, and that access list transactions using
+// the result are accepted by the mempool and built into blocks.
+func TestCreateAccessListPrecompile(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1], nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	// Enabling an address reads the role of the caller and writes the role of the address
+	input, err := precompile.PackModifyAllowList(testEthAddrs[1], precompile.AllowListEnabled)
+	require.NoError(t, err)
+	data := hexutil.Bytes(input)
+	result, err := ethapi.NewBlockChainAPI(vm.eth.APIBackend).CreateAccessList(context.Background(), ethapi.TransactionArgs{
+		From: &testEthAddrs[0],
+		To:   &precompile.TxAllowListAddress,
+		Data: &data,
+	}, nil)
+	require.NoError(t, err)
+	require.Empty(t, result.Error)
+	require.Empty(t, *result.Accesslist)
+
+	tx, err := types.SignTx(types.NewTx(&types.AccessListTx{
+		ChainID:    vm.chainConfig.ChainID,
+		Nonce:      0,
+		GasPrice:   big.NewInt(testMinGasPrice),
+		Gas:        uint64(result.GasUsed),
+		To:         &precompile.TxAllowListAddress,
+		Data:       input,
+		AccessList: *result.Accesslist,
+	}), types.LatestSignerForChainID(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(t, err)
+	require.NoError(t, vm.txPool.AddRemotesSync([]*types.Transaction{tx})[0])
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(t, block.Transactions(), 1)
+	require.Equal(t, types.AccessListTxType, int(block.Transactions()[0].Type()))
+	receipts := vm.blockChain.GetReceiptsByHash(block.Hash())
+	require.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Equal(t, precompile.AllowListEnabled, precompile.GetTxAllowListStatus(state, testEthAddrs[1]))
+}
@@ -5,10 +5,16 @@ package evm
 
 import (
 	"fmt"
+	"math/big"
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/api"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/profiler"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -65,6 +71,100 @@ func (p *Admin) SetLogLevel(_ *http.Request, args *SetLogLevelArgs, reply *api.E
 	return nil
 }
 
+type LocalAccountsArgs struct {
+	Addresses []common.Address `json:"addresses"`
+}
+
+type LocalAccountsReply struct {
+	Addresses []common.Address `json:"addresses"`
+}
+
+// AddLocalAccounts marks [args.Addresses] as local in the tx pool, so that their transactions are exempt
+// from price-based eviction and prioritized by the miner. This does not persist across restarts.
+func (p *Admin) AddLocalAccounts(_ *http.Request, args *LocalAccountsArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: AddLocalAccounts called", "addresses", args.Addresses)
+
+	for _, addr := range args.Addresses {
+		p.vm.txPool.AddLocalAccount(addr)
+	}
+	return nil
+}
+
+// RemoveLocalAccounts stops treating [args.Addresses] as local in the tx pool. This does not persist
+// across restarts.
+func (p *Admin) RemoveLocalAccounts(_ *http.Request, args *LocalAccountsArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: RemoveLocalAccounts called", "addresses", args.Addresses)
+
+	for _, addr := range args.Addresses {
+		p.vm.txPool.RemoveLocalAccount(addr)
+	}
+	return nil
+}
+
+// GetLocalAccounts returns the addresses currently treated as local in the tx pool.
+func (p *Admin) GetLocalAccounts(_ *http.Request, _ *struct{}, reply *LocalAccountsReply) error {
+	reply.Addresses = p.vm.txPool.Locals()
+	return nil
+}
+
+type EvictTransactionArgs struct {
+	Hash common.Hash `json:"hash"`
+}
+
+type EvictTransactionsFromArgs struct {
+	Address common.Address `json:"address"`
+}
+
+type EvictTransactionsReply struct {
+	Evicted int `json:"evicted"`
+}
+
+// EvictTransaction removes the transaction with [args.Hash] from the tx pool. Later transactions of the
+// same sender are moved back to the queue, since they are no longer executable.
+func (p *Admin) EvictTransaction(_ *http.Request, args *EvictTransactionArgs, reply *EvictTransactionsReply) error {
+	log.Info("Admin: EvictTransaction called", "hash", args.Hash)
+
+	if !p.vm.txPool.EvictTx(args.Hash) {
+		return fmt.Errorf("%w: %s", errTxNotInPool, args.Hash)
+	}
+	reply.Evicted = 1
+	return nil
+}
+
+// EvictTransactionsFrom removes all the pending and queued transactions of [args.Address] from the tx pool.
+func (p *Admin) EvictTransactionsFrom(_ *http.Request, args *EvictTransactionsFromArgs, reply *EvictTransactionsReply) error {
+	log.Info("Admin: EvictTransactionsFrom called", "address", args.Address)
+
+	reply.Evicted = p.vm.txPool.EvictTxsFrom(args.Address)
+	return nil
+}
+
+type MinGasPriceArgs struct {
+	MinGasPrice *big.Int `json:"minGasPrice"`
+}
+
+type MinGasPriceReply struct {
+	MinGasPrice *big.Int `json:"minGasPrice"`
+}
+
+// SetMinGasPrice sets the minimum gas fee cap of remote transactions admitted to the tx pool, and drops the
+// remote transactions below it. This does not persist across restarts.
+func (p *Admin) SetMinGasPrice(_ *http.Request, args *MinGasPriceArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: SetMinGasPrice called", "minGasPrice", args.MinGasPrice)
+
+	if args.MinGasPrice == nil || args.MinGasPrice.Sign() < 0 {
+		return errInvalidMinGasPrice
+	}
+	p.vm.txPool.SetMinGasPrice(args.MinGasPrice)
+	return nil
+}
+
+// GetMinGasPrice returns the minimum gas fee cap of remote transactions admitted to the tx pool.
+func (p *Admin) GetMinGasPrice(_ *http.Request, _ *struct{}, reply *MinGasPriceReply) error {
+	reply.MinGasPrice = p.vm.txPool.MinGasPrice()
+	return nil
+}
+
 type ConfigReply struct {
 	Config *Config `json:"config"`
 }
@@ -73,3 +173,152 @@ func (p *Admin) GetVMConfig(_ *http.Request, _ *struct{}, reply *ConfigReply) er
 	reply.Config = &p.vm.config
 	return nil
 }
+
+type UpdateUpgradeConfigArgs struct {
+	UpgradeConfig params.UpgradeConfig `json:"upgradeConfig"`
+}
+
+// UpdateUpgradeConfig applies the precompile upgrades of [args.UpgradeConfig], which has the format of the
+// upgrade bytes, without restarting the node. The applied upgrades are kept after a restart until the upgrade
+// bytes of the node are updated, which then replace them.
+func (p *Admin) UpdateUpgradeConfig(_ *http.Request, args *UpdateUpgradeConfigArgs, _ *api.EmptyReply) error {
+	log.Info("Admin: UpdateUpgradeConfig called")
+
+	return p.vm.updateUpgradeConfig(args.UpgradeConfig)
+}
+
+type ValidateUpgradeConfigReply struct {
+	Errors []*params.UpgradeConfigError `json:"errors"`
+}
+
+// ValidateUpgradeConfig checks whether [args.UpgradeConfig], which has the format of the upgrade bytes, is valid
+// and compatible with the upgrades already activated on the chain. The errors found are returned in [reply], which
+// has no errors if the upgrade config can be distributed to the validators.
+func (p *Admin) ValidateUpgradeConfig(_ *http.Request, args *UpdateUpgradeConfigArgs, reply *ValidateUpgradeConfigReply) error {
+	log.Info("Admin: ValidateUpgradeConfig called")
+
+	reply.Errors = p.vm.validateUpgradeConfig(&args.UpgradeConfig)
+	return nil
+}
+
+type DryRunUpgradeConfigArgs struct {
+	UpgradeConfig params.UpgradeConfig `json:"upgradeConfig"`
+	Blocks        uint64               `json:"blocks"`
+}
+
+type DryRunUpgradeConfigReply struct {
+	Blocks []*core.BlockReplayResult `json:"blocks"`
+}
+
+// DryRunUpgradeConfig replays the last [args.Blocks] accepted blocks against the chain config with
+// [args.UpgradeConfig] in an isolated copy of the state, and reports in [reply] how each replayed block
+// diverges from the accepted chain. The state of the chain is not modified.
+func (p *Admin) DryRunUpgradeConfig(_ *http.Request, args *DryRunUpgradeConfigArgs, reply *DryRunUpgradeConfigReply) error {
+	log.Info("Admin: DryRunUpgradeConfig called", "blocks", args.Blocks)
+
+	results, err := p.vm.dryRunUpgradeConfig(args.UpgradeConfig, args.Blocks)
+	if err != nil {
+		return err
+	}
+	reply.Blocks = results
+	return nil
+}
+
+type ExportStateArgs struct {
+	Number uint64 `json:"number"`
+	File   string `json:"file"`
+}
+
+type ExportStateReply struct {
+	Stats *core.StateExportStats `json:"stats"`
+}
+
+// ExportState exports the state of the accepted block [args.Number] to [args.File], which can be used with
+// the state-import-file config to initialize a new node. The state must still be available on this node.
+func (p *Admin) ExportState(_ *http.Request, args *ExportStateArgs, reply *ExportStateReply) error {
+	log.Info("Admin: ExportState called", "number", args.Number, "file", args.File)
+
+	if len(args.File) == 0 {
+		return errMissingExportFile
+	}
+	stats, err := p.vm.blockChain.ExportStateToFile(args.File, args.Number)
+	if err != nil {
+		return err
+	}
+	reply.Stats = stats
+	return nil
+}
+
+type RebuildIndexesArgs struct {
+	Bloom    bool   `json:"bloom"`
+	TxLookup bool   `json:"txLookup"`
+	From     uint64 `json:"from"`
+}
+
+type RebuildIndexesReply struct {
+	BloomSection uint64 `json:"bloomSection"`
+}
+
+// RebuildIndexes rebuilds the bloom index from the section containing block [args.From] if [args.Bloom]
+// is set, and the transaction lookup index of the blocks within the tx-lookup-limit if [args.TxLookup] is
+// set. The indexes are rebuilt in the background, and [reply.BloomSection] is the first bloom section
+// being rebuilt.
+func (p *Admin) RebuildIndexes(_ *http.Request, args *RebuildIndexesArgs, reply *RebuildIndexesReply) error {
+	log.Info("Admin: RebuildIndexes called", "bloom", args.Bloom, "txLookup", args.TxLookup, "from", args.From)
+
+	if args.Bloom {
+		reply.BloomSection = p.vm.eth.BloomIndexer().Rebuild(args.From / params.BloomBitsBlocks)
+	}
+	if args.TxLookup {
+		p.vm.blockChain.RebuildTxIndex()
+	}
+	return nil
+}
+
+// CompactIndexes compacts the key ranges of the bloom and transaction lookup indexes, reclaiming the disk
+// space of the entries deleted after lowering the tx-lookup-limit.
+func (p *Admin) CompactIndexes(_ *http.Request, _ *struct{}, _ *api.EmptyReply) error {
+	log.Info("Admin: CompactIndexes called")
+
+	return rawdb.CompactIndexes(p.vm.chaindb)
+}
+
+type TxGossipStatsReply struct {
+	Peers      map[ids.NodeID]*PeerTxGossipStats `json:"peers"`
+	Rejections map[string]uint64                 `json:"rejections"`
+}
+
+// GetTxGossipStats returns the stats of the txs gossiped by each peer since the node started, and the number
+// of gossiped txs rejected by the tx pool for each reason. Peers gossiping many invalid, dropped or late txs
+// are likely misbehaving, and many rejections for being underpriced suggest mismatched fee configs.
+func (p *Admin) GetTxGossipStats(_ *http.Request, _ *struct{}, reply *TxGossipStatsReply) error {
+	reply.Peers, reply.Rejections = p.vm.txGossipTracker.snapshot()
+	return nil
+}
+
+type ExportChainArgs struct {
+	First uint64 `json:"first"`
+	Last  uint64 `json:"last"`
+	File  string `json:"file"`
+}
+
+type ExportChainReply struct {
+	Stats *core.ChainExportStats `json:"stats"`
+}
+
+// ExportChain exports the accepted blocks from [args.First] to [args.Last] and their receipts to [args.File], which
+// can be used with the chain-import-file config to replay them on another node, or to move them to another database
+// along with a state export of [args.Last]. The blocks and receipts must not have been pruned on this node.
+func (p *Admin) ExportChain(_ *http.Request, args *ExportChainArgs, reply *ExportChainReply) error {
+	log.Info("Admin: ExportChain called", "first", args.First, "last", args.Last, "file", args.File)
+
+	if len(args.File) == 0 {
+		return errMissingChainExportFile
+	}
+	stats, err := p.vm.blockChain.ExportChainToFile(args.File, args.First, args.Last)
+	if err != nil {
+		return err
+	}
+	reply.Stats = stats
+	return nil
+}
@@ -0,0 +1,169 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package alert delivers alerts on the anomalies that are critical to the consensus
+// of a subnet, such as deep reorgs or fee config changes, to pluggable hooks.
+package alert
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// queueSize is the number of alerts waiting to be delivered to the hooks, after which
+// new alerts are dropped so that firing an alert never blocks the caller.
+const queueSize = 256
+
+var (
+	firedCounter   = metrics.NewRegisteredCounter("alerts/fired", nil)
+	droppedCounter = metrics.NewRegisteredCounter("alerts/dropped", nil)
+	failedCounter  = metrics.NewRegisteredCounter("alerts/failed", nil)
+)
+
+// Kind is the kind of anomaly an alert reports.
+type Kind string
+
+const (
+	// Reorg is fired when the preferred chain drops a number of blocks exceeding the threshold.
+	Reorg Kind = "reorg"
+	// FeeConfigChange is fired when an accepted block changes the fee config.
+	FeeConfigChange Kind = "feeConfigChange"
+	// AllowListRoleChange is fired when an accepted block changes a role of an allow list.
+	AllowListRoleChange Kind = "allowListRoleChange"
+	// BlockVerificationFailure is fired when a block received from consensus fails verification.
+	BlockVerificationFailure Kind = "blockVerificationFailure"
+	// UpgradeActivation is fired when an accepted block activates a network or precompile upgrade.
+	UpgradeActivation Kind = "upgradeActivation"
+)
+
+// Severity is the urgency of an alert.
+type Severity string
+
+const (
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Alert is an anomaly reported to the hooks.
+type Alert struct {
+	Kind        Kind                   `json:"kind"`
+	Severity    Severity               `json:"severity"`
+	ChainID     string                 `json:"chainID"`
+	Time        time.Time              `json:"time"`
+	BlockNumber uint64                 `json:"blockNumber"`
+	BlockHash   common.Hash            `json:"blockHash"`
+	Message     string                 `json:"message"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// Hook is a sink of alerts, such as a log or a webhook. Hooks are called from a single
+// goroutine, one alert at a time.
+type Hook interface {
+	Fire(Alert) error
+}
+
+// Dispatcher delivers the alerts fired by the VM to its hooks in the background.
+type Dispatcher struct {
+	chainID string
+
+	lock  sync.RWMutex
+	hooks []Hook
+
+	queue chan Alert
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher delivering the alerts of [chainID] to [hooks].
+// The dispatcher must be closed to stop delivering alerts.
+func NewDispatcher(chainID string, hooks ...Hook) *Dispatcher {
+	d := &Dispatcher{
+		chainID: chainID,
+		hooks:   hooks,
+		queue:   make(chan Alert, queueSize),
+		done:    make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.loop()
+	return d
+}
+
+// AddHook adds [hook] to the hooks the alerts are delivered to.
+func (d *Dispatcher) AddHook(hook Hook) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.hooks = append(d.hooks, hook)
+}
+
+// Fire queues [alert] to be delivered to the hooks, setting its chain ID and time. The
+// alert is dropped if the queue is full or the dispatcher is closed or nil.
+func (d *Dispatcher) Fire(alert Alert) {
+	if d == nil {
+		return
+	}
+	alert.ChainID = d.chainID
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+	select {
+	case d.queue <- alert:
+		firedCounter.Inc(1)
+	default:
+		droppedCounter.Inc(1)
+		log.Warn("Dropping alert as the queue is full", "kind", alert.Kind, "msg", alert.Message)
+	}
+}
+
+// Close delivers the queued alerts and stops the dispatcher. Closing a nil dispatcher
+// is a no-op.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	close(d.done)
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) loop() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case alert := <-d.queue:
+			d.deliver(alert)
+		case <-d.done:
+			for {
+				select {
+				case alert := <-d.queue:
+					d.deliver(alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver calls the hooks with [alert], logging the hooks that fail.
+func (d *Dispatcher) deliver(alert Alert) {
+	d.lock.RLock()
+	hooks := d.hooks
+	d.lock.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook.Fire(alert); err != nil {
+			failedCounter.Inc(1)
+			log.Warn("Failed to deliver alert", "kind", alert.Kind, "err", err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHook struct {
+	lock   sync.Mutex
+	alerts []Alert
+	err    error
+}
+
+func (h *recordingHook) Fire(alert Alert) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.alerts = append(h.alerts, alert)
+	return h.err
+}
+
+func (h *recordingHook) fired() []Alert {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.alerts
+}
+
+func TestDispatcher(t *testing.T) {
+	first := &recordingHook{err: errors.New("unreachable")}
+	d := NewDispatcher("chain", first)
+	second := &recordingHook{}
+	d.AddHook(second)
+
+	d.Fire(Alert{Kind: Reorg, Severity: Critical, BlockNumber: 5, Message: "reorg"})
+	d.Fire(Alert{Kind: FeeConfigChange, Severity: Warning, BlockNumber: 6, Message: "fee config"})
+	d.Close()
+
+	// A failing hook does not prevent the alerts from reaching the other hooks
+	for _, hook := range []*recordingHook{first, second} {
+		alerts := hook.fired()
+		require.Len(t, alerts, 2)
+		require.Equal(t, Reorg, alerts[0].Kind)
+		require.Equal(t, FeeConfigChange, alerts[1].Kind)
+		for _, alert := range alerts {
+			require.Equal(t, "chain", alert.ChainID)
+			require.False(t, alert.Time.IsZero())
+		}
+	}
+
+	// Alerts fired after the dispatcher is closed are dropped
+	d.Fire(Alert{Kind: Reorg})
+	require.Len(t, second.fired(), 2)
+
+	// A nil dispatcher drops the alerts
+	var nilDispatcher *Dispatcher
+	nilDispatcher.Fire(Alert{Kind: Reorg})
+	nilDispatcher.Close()
+}
+
+func TestWebhookHook(t *testing.T) {
+	var (
+		received Alert
+		header   string
+		status   = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	hook := NewWebhookHook(server.URL, map[string]string{"Authorization": "Token secret"}, time.Second)
+	alert := Alert{
+		Kind:        AllowListRoleChange,
+		Severity:    Critical,
+		ChainID:     "chain",
+		Time:        time.Unix(10, 0).UTC(),
+		BlockNumber: 3,
+		BlockHash:   common.HexToHash("0x01"),
+		Message:     "role changed",
+		Details:     map[string]interface{}{"role": "admin"},
+	}
+	require.NoError(t, hook.Fire(alert))
+	require.Equal(t, "Token secret", header)
+	require.Equal(t, alert, received)
+
+	status = http.StatusInternalServerError
+	require.ErrorContains(t, hook.Fire(alert), "500")
+}
@@ -0,0 +1,75 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	_ Hook = LogHook{}
+	_ Hook = (*WebhookHook)(nil)
+)
+
+// LogHook logs the alerts, at the error level for critical alerts and at the warn
+// level otherwise.
+type LogHook struct{}
+
+// Fire implements Hook
+func (LogHook) Fire(alert Alert) error {
+	logFn := log.Warn
+	if alert.Severity == Critical {
+		logFn = log.Error
+	}
+	logFn("Alert: "+alert.Message, "kind", alert.Kind, "number", alert.BlockNumber, "hash", alert.BlockHash)
+	return nil
+}
+
+// WebhookHook posts the alerts as JSON to a URL, such as the webhook of a paging service.
+type WebhookHook struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookHook returns a hook posting the alerts to [url] with [headers], failing the
+// requests that take longer than [timeout] (no timeout if 0).
+func NewWebhookHook(url string, headers map[string]string, timeout time.Duration) *WebhookHook {
+	return &WebhookHook{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Fire implements Hook
+func (w *WebhookHook) Fire(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
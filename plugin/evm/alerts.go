@@ -0,0 +1,253 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/plugin/evm/alert"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// roleSetEventID is the topic of the RoleSet event emitted by the allow lists.
+var roleSetEventID = precompile.PrecompileEventsABI.Events["RoleSet"].ID
+
+// alertMonitor fires the alerts on the anomalies of the chain: deep reorgs, and the fee
+// config changes, allow list role changes and upgrade activations of the accepted blocks.
+type alertMonitor struct {
+	chain        *core.BlockChain
+	chainConfig  *params.ChainConfig
+	alerts       *alert.Dispatcher
+	reorgDepth   int
+	shutdownChan <-chan struct{}
+
+	wg *sync.WaitGroup
+}
+
+// AddAlertHook adds [hook] to the hooks the alerts of the VM are delivered to, in addition
+// to the log and webhook hooks enabled in the config.
+func (vm *VM) AddAlertHook(hook alert.Hook) {
+	if vm.alerts == nil {
+		vm.alertHooks = append(vm.alertHooks, hook)
+		return
+	}
+	vm.alerts.AddHook(hook)
+}
+
+// initializeAlerts creates the dispatcher of the alerts, delivering them to the hooks
+// enabled in the config and those added with AddAlertHook.
+func (vm *VM) initializeAlerts() {
+	hooks := vm.alertHooks
+	if vm.config.AlertLogEnabled {
+		hooks = append(hooks, alert.LogHook{})
+	}
+	if len(vm.config.AlertWebhookURL) > 0 {
+		hooks = append(hooks, alert.NewWebhookHook(vm.config.AlertWebhookURL, vm.config.AlertWebhookHeaders, vm.config.AlertWebhookTimeout.Duration))
+	}
+	vm.alerts = alert.NewDispatcher(vm.ctx.ChainID.String(), hooks...)
+}
+
+// handleAlerts starts the goroutine monitoring the chain for the anomalies to alert on.
+func (vm *VM) handleAlerts() {
+	monitor := &alertMonitor{
+		chain:        vm.blockChain,
+		chainConfig:  vm.chainConfig,
+		alerts:       vm.alerts,
+		reorgDepth:   vm.config.AlertReorgDepth,
+		shutdownChan: vm.shutdownChan,
+		wg:           &vm.shutdownWg,
+	}
+	monitor.start()
+}
+
+func (m *alertMonitor) start() {
+	var (
+		reorgs      = make(chan core.ChainReorgEvent, 16)
+		accepted    = make(chan core.ChainEvent, 64)
+		reorgSub    = m.chain.SubscribeChainReorgEvent(reorgs)
+		acceptedSub = m.chain.SubscribeChainAcceptedEvent(accepted)
+	)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer reorgSub.Unsubscribe()
+		defer acceptedSub.Unsubscribe()
+
+		for {
+			select {
+			case event := <-reorgs:
+				m.checkReorg(event)
+			case event := <-accepted:
+				m.checkAccepted(event.Block, event.Logs)
+			case <-reorgSub.Err():
+				return
+			case <-acceptedSub.Err():
+				return
+			case <-m.shutdownChan:
+				return
+			}
+		}
+	}()
+}
+
+// checkReorg fires an alert if [event] dropped at least [reorgDepth] blocks.
+func (m *alertMonitor) checkReorg(event core.ChainReorgEvent) {
+	if m.reorgDepth <= 0 || event.Depth < m.reorgDepth {
+		return
+	}
+	m.alerts.Fire(alert.Alert{
+		Kind:        alert.Reorg,
+		Severity:    alert.Critical,
+		BlockNumber: event.NewHead.NumberU64(),
+		BlockHash:   event.NewHead.Hash(),
+		Message:     fmt.Sprintf("preferred chain dropped %d blocks above block %d", event.Depth, event.CommonBlock.NumberU64()),
+		Details: map[string]interface{}{
+			"depth":       event.Depth,
+			"commonBlock": event.CommonBlock.Hash(),
+			"oldHead":     event.OldHead.Hash(),
+		},
+	})
+}
+
+// checkAccepted fires the alerts on the fee config changes, allow list role changes and
+// upgrade activations of the accepted [block], which emitted [logs].
+func (m *alertMonitor) checkAccepted(block *types.Block, logs []*types.Log) {
+	var (
+		number    = block.Number()
+		timestamp = new(big.Int).SetUint64(block.Time())
+	)
+	if m.chainConfig.IsFeeConfigManager(number, timestamp) {
+		feeConfig, lastChangedAt, err := m.chain.GetFeeConfigAt(block.Header())
+		if err != nil {
+			log.Debug("failed to get fee config of accepted block", "number", number, "err", err)
+		} else if lastChangedAt.Cmp(number) == 0 {
+			m.alerts.Fire(alert.Alert{
+				Kind:        alert.FeeConfigChange,
+				Severity:    alert.Warning,
+				BlockNumber: block.NumberU64(),
+				BlockHash:   block.Hash(),
+				Message:     fmt.Sprintf("fee config changed at block %d", block.NumberU64()),
+				Details:     map[string]interface{}{"feeConfig": feeConfig},
+			})
+		}
+	}
+
+	for _, txLog := range logs {
+		if len(txLog.Topics) == 0 || txLog.Topics[0] != roleSetEventID || !m.chainConfig.IsPrecompileEnabled(txLog.Address, number, timestamp) {
+			continue
+		}
+		_, args, err := precompile.UnpackPrecompileEvent(txLog.Topics, txLog.Data)
+		if err != nil {
+			continue
+		}
+		role := precompile.AllowListRole(common.BigToHash(args["role"].(*big.Int)))
+		severity, roleName := alert.Warning, "none"
+		switch {
+		case role.IsAdmin():
+			severity, roleName = alert.Critical, "admin"
+		case !role.IsNoRole():
+			roleName = "enabled"
+		}
+		account, sender := args["account"].(common.Address), args["sender"].(common.Address)
+		m.alerts.Fire(alert.Alert{
+			Kind:        alert.AllowListRoleChange,
+			Severity:    severity,
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			Message:     fmt.Sprintf("role of %s in the allow list of %s set to %s by %s", account, txLog.Address, roleName, sender),
+			Details: map[string]interface{}{
+				"precompile": txLog.Address,
+				"account":    account,
+				"role":       roleName,
+				"sender":     sender,
+				"txHash":     txLog.TxHash,
+			},
+		})
+	}
+
+	if details := m.activatedUpgrades(block); len(details) > 0 {
+		m.alerts.Fire(alert.Alert{
+			Kind:        alert.UpgradeActivation,
+			Severity:    alert.Warning,
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			Message:     fmt.Sprintf("upgrades activated at block %d", block.NumberU64()),
+			Details:     details,
+		})
+	}
+}
+
+// activatedUpgrades returns the network upgrades, parameter upgrades and precompile
+// changes activated by [block], or nil if [block] does not activate any.
+func (m *alertMonitor) activatedUpgrades(block *types.Block) map[string]interface{} {
+	parent := m.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil
+	}
+	var (
+		parentTimestamp = new(big.Int).SetUint64(parent.Time)
+		timestamp       = new(big.Int).SetUint64(block.Time())
+		details         = make(map[string]interface{})
+	)
+	if !m.chainConfig.IsSubnetEVM(parentTimestamp) && m.chainConfig.IsSubnetEVM(timestamp) {
+		details["networkUpgrades"] = []string{"subnetEVM"}
+	}
+	var parameterUpgrades []uint64
+	for _, upgrade := range m.chainConfig.Upgrades().ParameterUpgrades {
+		if utils.IsForked(upgrade.Timestamp(), timestamp) && !utils.IsForked(upgrade.Timestamp(), parentTimestamp) {
+			parameterUpgrades = append(parameterUpgrades, upgrade.Timestamp().Uint64())
+		}
+	}
+	if len(parameterUpgrades) > 0 {
+		details["parameterUpgrades"] = parameterUpgrades
+	}
+
+	parentHash := m.chainConfig.PrecompileConfigHashAt(parent.Number, parentTimestamp)
+	hash := m.chainConfig.PrecompileConfigHashAt(block.Number(), timestamp)
+	if parentHash != hash {
+		var (
+			parentRules = m.chainConfig.AvalancheRules(parent.Number, parentTimestamp)
+			rules       = m.chainConfig.AvalancheRules(block.Number(), timestamp)
+			enabled     []common.Address
+			disabled    []common.Address
+		)
+		for addr := range rules.Precompiles {
+			if _, ok := parentRules.Precompiles[addr]; !ok {
+				enabled = append(enabled, addr)
+			}
+		}
+		for addr := range parentRules.Precompiles {
+			if _, ok := rules.Precompiles[addr]; !ok {
+				disabled = append(disabled, addr)
+			}
+		}
+		sortAddresses(enabled)
+		sortAddresses(disabled)
+		details["precompileConfigHash"] = hash
+		if len(enabled) > 0 {
+			details["enabledPrecompiles"] = enabled
+		}
+		if len(disabled) > 0 {
+			details["disabledPrecompiles"] = disabled
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
+func sortAddresses(addrs []common.Address) {
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+}
@@ -0,0 +1,107 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/plugin/evm/alert"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type alertHookFunc func(alert.Alert) error
+
+func (f alertHookFunc) Fire(a alert.Alert) error { return f(a) }
+
+func TestAcceptedBlockAlerts(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	activation := big.NewInt(time.Now().Unix())
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewContractDeployerAllowListConfig(activation, testEthAddrs[:1], nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	// Enable the events of the allow list along with its activation
+	precompileEvents := true
+	upgradeJSON, err := json.Marshal(&params.UpgradeConfig{
+		ParameterUpgrades: []params.ParameterUpgrade{{BlockTimestamp: activation, PrecompileEvents: &precompileEvents}},
+	})
+	require.NoError(t, err)
+
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", string(upgradeJSON))
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	alerts := make(chan alert.Alert, 8)
+	vm.AddAlertHook(alertHookFunc(func(a alert.Alert) error {
+		alerts <- a
+		return nil
+	}))
+
+	// The admin enabled by the activation of the allow list enables another address in the same block
+	input, err := precompile.PackModifyAllowList(testEthAddrs[1], precompile.AllowListEnabled)
+	require.NoError(t, err)
+	tx := types.NewTransaction(uint64(0), precompile.ContractDeployerAllowListAddress, big.NewInt(0), 100_000, big.NewInt(testMinGasPrice*3), input)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(t, err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(t, err)
+	}
+	blk := issueAndAccept(t, issuer, vm)
+
+	fired := make(map[alert.Kind]alert.Alert)
+	for len(fired) < 2 {
+		select {
+		case a := <-alerts:
+			fired[a.Kind] = a
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for alerts, got %v", fired)
+		}
+	}
+
+	roleChange := fired[alert.AllowListRoleChange]
+	require.Equal(t, alert.Warning, roleChange.Severity)
+	require.Equal(t, common.Hash(blk.ID()), roleChange.BlockHash)
+	require.Equal(t, "enabled", roleChange.Details["role"])
+	require.Equal(t, testEthAddrs[1], roleChange.Details["account"])
+	require.Equal(t, testEthAddrs[0], roleChange.Details["sender"])
+
+	upgrade := fired[alert.UpgradeActivation]
+	require.EqualValues(t, 1, upgrade.BlockNumber)
+	require.Equal(t, []common.Address{precompile.ContractDeployerAllowListAddress}, upgrade.Details["enabledPrecompiles"])
+	require.Equal(t, []uint64{activation.Uint64()}, upgrade.Details["parameterUpgrades"])
+}
+
+func TestReorgAlert(t *testing.T) {
+	var fired []alert.Alert
+	dispatcher := alert.NewDispatcher("chain", alertHookFunc(func(a alert.Alert) error {
+		fired = append(fired, a)
+		return nil
+	}))
+	monitor := &alertMonitor{alerts: dispatcher, reorgDepth: 2}
+
+	var (
+		ancestor = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		oldHead  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Extra: []byte{1}})
+		newHead  = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Extra: []byte{2}})
+	)
+	monitor.checkReorg(core.ChainReorgEvent{OldHead: oldHead, NewHead: newHead, CommonBlock: ancestor, Depth: 1})
+	monitor.checkReorg(core.ChainReorgEvent{OldHead: oldHead, NewHead: newHead, CommonBlock: ancestor, Depth: 2})
+	dispatcher.Close()
+
+	require.Len(t, fired, 1)
+	require.Equal(t, alert.Reorg, fired[0].Kind)
+	require.Equal(t, alert.Critical, fired[0].Severity)
+	require.Equal(t, newHead.Hash(), fired[0].BlockHash)
+	require.Equal(t, 2, fired[0].Details["depth"])
+}
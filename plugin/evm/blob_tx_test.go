@@ -0,0 +1,72 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that blob transactions carrying their sidecar are rejected, while those without it are
+// built into blocks and expose their versioned hashes through BLOBHASH.
+func TestBlobTxRejectDataMode(t *testing.T) {
+	// PUSH1 0 BLOBHASH PUSH1 0 SSTORE STOP
+	contract := common.HexToAddress("0x0400000000000000000000000000000000000000")
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.BlobTxTimestamp = big.NewInt(0)
+	genesis.Alloc[contract] = core.GenesisAccount{Code: common.FromHex("0x60004960005500"), Balance: common.Big0}
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       []types.Blob{{0x01}},
+		Commitments: []types.KZGCommitment{{0x01}},
+		Proofs:      []types.KZGProof{{0x01}},
+	}
+	blobHashes := sidecar.BlobHashes()
+	tx, err := types.SignNewTx(testKeys[0], types.LatestSigner(vm.chainConfig), &types.BlobTx{
+		ChainID:    vm.chainConfig.ChainID,
+		Nonce:      0,
+		GasTipCap:  big.NewInt(0),
+		GasFeeCap:  big.NewInt(testMinGasPrice),
+		Gas:        100_000,
+		To:         contract,
+		Value:      common.Big0,
+		BlobFeeCap: big.NewInt(testMinGasPrice),
+		BlobHashes: blobHashes,
+		Sidecar:    sidecar,
+	})
+	require.NoError(t, err)
+
+	// The sidecar cannot be retained since its KZG proofs are not verified
+	err = vm.txPool.AddRemotesSync([]*types.Transaction{tx})[0]
+	require.ErrorIs(t, err, core.ErrBlobDataRejected)
+	tx = tx.WithoutBlobTxSidecar()
+	require.NoError(t, vm.txPool.AddRemotesSync([]*types.Transaction{tx})[0])
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(t, block.Transactions(), 1)
+	included := block.Transactions()[0]
+	require.Equal(t, tx.Hash(), included.Hash())
+	require.Nil(t, included.BlobTxSidecar())
+	receipts := vm.blockChain.GetReceiptsByHash(block.Hash())
+	require.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Equal(t, blobHashes[0], state.GetState(contract, common.Hash{}))
+}
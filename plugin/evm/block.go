@@ -12,10 +12,16 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/plugin/evm/alert"
+	"github.com/ava-labs/subnet-evm/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 )
 
 // Block implements the snowman.Block interface
@@ -39,7 +45,10 @@ func (vm *VM) newBlock(ethBlock *types.Block) *Block {
 func (b *Block) ID() ids.ID { return b.id }
 
 // Accept implements the snowman.Block interface
-func (b *Block) Accept(context.Context) error {
+func (b *Block) Accept(ctx context.Context) error {
+	_, span := tracing.Start(ctx, "Block.Accept", b.traceAttributes()...)
+	defer span.End()
+
 	vm := b.vm
 
 	// Although returning an error from Accept is considered fatal, it is good
@@ -54,14 +63,21 @@ func (b *Block) Accept(context.Context) error {
 	if err := vm.acceptedBlockDB.Put(lastAcceptedKey, b.id[:]); err != nil {
 		return fmt.Errorf("failed to put %s as the last accepted block: %w", b.ID(), err)
 	}
+	vm.finality.blockAccepted(b.ethBlock.Hash())
+	delete(vm.processing, b.ethBlock.Hash())
 
 	return vm.db.Commit()
 }
 
 // Reject implements the snowman.Block interface
-func (b *Block) Reject(context.Context) error {
+func (b *Block) Reject(ctx context.Context) error {
+	_, span := tracing.Start(ctx, "Block.Reject", b.traceAttributes()...)
+	defer span.End()
+
 	b.status = choices.Rejected
 	log.Debug(fmt.Sprintf("Rejecting block %s (%s) at height %d", b.ID().Hex(), b.ID(), b.Height()))
+	b.vm.finality.blockRejected(b.ethBlock.Hash())
+	delete(b.vm.processing, b.ethBlock.Hash())
 	return b.vm.blockChain.Reject(b.ethBlock)
 }
 
@@ -101,16 +117,66 @@ func (b *Block) syntacticVerify() error {
 }
 
 // Verify implements the snowman.Block interface
-func (b *Block) Verify(context.Context) error {
-	return b.verify(true)
+func (b *Block) Verify(ctx context.Context) error {
+	return b.verify(ctx, true)
+}
+
+// ShouldVerifyWithContext implements the block.WithVerifyContext interface
+// Blocks record the P-chain height used to snapshot validator sets once the
+// validator info precompile is enabled, so they must be verified with the
+// P-chain height provided by the proposervm.
+func (b *Block) ShouldVerifyWithContext(context.Context) (bool, error) {
+	return b.vm.chainConfig.IsValidatorInfo(b.ethBlock.Number(), new(big.Int).SetUint64(b.ethBlock.Time())), nil
+}
+
+// VerifyWithContext implements the block.WithVerifyContext interface
+func (b *Block) VerifyWithContext(ctx context.Context, blockCtx *block.Context) error {
+	if pChainHeight, ok := dummy.GetPChainHeight(b.vm.chainConfig, b.ethBlock.Header()); ok && pChainHeight > blockCtx.PChainHeight {
+		return fmt.Errorf("%w: recorded %d, proposervm %d", errPChainHeightTooHigh, pChainHeight, blockCtx.PChainHeight)
+	}
+	return b.verify(ctx, true)
 }
 
-func (b *Block) verify(writes bool) error {
+func (b *Block) verify(ctx context.Context, writes bool) error {
+	ctx, span := tracing.Start(ctx, "Block.Verify", b.traceAttributes()...)
+	defer span.End()
+
 	if err := b.syntacticVerify(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("syntactic block verification failed: %w", err)
 	}
 
-	return b.vm.blockChain.InsertBlockManual(b.ethBlock, writes)
+	if err := b.vm.blockChain.InsertBlockManual(ctx, b.ethBlock, writes); err != nil {
+		// Blocks verified with [writes] come from consensus, so a failure may
+		// indicate that this node disagrees with its peers on the state.
+		if writes {
+			b.vm.alerts.Fire(alert.Alert{
+				Kind:        alert.BlockVerificationFailure,
+				Severity:    alert.Warning,
+				BlockNumber: b.Height(),
+				BlockHash:   b.ethBlock.Hash(),
+				Message:     fmt.Sprintf("block %d failed verification: %s", b.Height(), err),
+			})
+		}
+		return err
+	}
+	if writes {
+		b.vm.processing[b.ethBlock.Hash()] = b.ethBlock.Header()
+	}
+	// The time to acceptance is measured from the verification of the blocks
+	// by consensus, once the node follows the tip of the chain.
+	if writes && b.vm.bootstrapped.GetValue() {
+		b.vm.finality.blockVerified(b.ethBlock.Hash())
+	}
+	return nil
+}
+
+// traceAttributes returns the attributes identifying the block in its spans.
+func (b *Block) traceAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("height", int64(b.Height())),
+		attribute.Stringer("id", b.id),
+	}
 }
 
 // Bytes implements the snowman.Block interface
@@ -58,7 +58,7 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 	}
 
 	if rules.IsSubnetEVM {
-		expectedExtraDataSize := params.ExtraDataSize
+		expectedExtraDataSize := b.vm.chainConfig.GetExtraDataSize(ethHeader.Number, new(big.Int).SetUint64(ethHeader.Time))
 		if headerExtraDataSize := len(ethHeader.Extra); headerExtraDataSize != expectedExtraDataSize {
 			return fmt.Errorf(
 				"expected header ExtraData to be %d but got %d",
@@ -114,6 +114,13 @@ func (v blockValidator) SyntacticVerify(b *Block, rules params.Rules) error {
 		}
 	}
 
+	// Make sure that the custom transactions of the block have activated
+	for _, tx := range txs {
+		if types.IsCustomTxType(tx.Type()) && !b.vm.chainConfig.IsCustomTxType(tx.Type(), new(big.Int).SetUint64(ethHeader.Time)) {
+			return fmt.Errorf("block contains tx %s with custom type %#x that is not enabled", tx.Hash(), tx.Type())
+		}
+	}
+
 	// Make sure the block isn't too far in the future
 	blockTimestamp := b.ethBlock.Time()
 	if maxBlockTime := uint64(b.vm.clock.Time().Add(maxFutureBlockTime).Unix()); blockTimestamp > maxBlockTime {
@@ -0,0 +1,47 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/vms/platformvm/teleporter"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	errTeleporterSignerUnavailable = errors.New("teleporter signer is not available")
+	errNativeTokenMessageNotSent   = errors.New("native token transfer message was not sent in an accepted block")
+)
+
+// BridgeAPI serves the signatures of the validator over the native token transfers sent by the
+// NativeTokenSource precompile, which relayers aggregate to deliver the transfers to the
+// NativeTokenDestination precompile of the destination chain.
+type BridgeAPI struct{ vm *VM }
+
+// GetTransferSignature returns the BLS signature of the validator over the unsigned Teleporter
+// [message] of a native token transfer, which must have been sent in an accepted block.
+func (api *BridgeAPI) GetTransferSignature(_ context.Context, message hexutil.Bytes) (hexutil.Bytes, error) {
+	msg, err := teleporter.ParseUnsignedMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", precompile.ErrInvalidTransferMessage, err)
+	}
+	if msg.SourceChainID != api.vm.ctx.ChainID {
+		return nil, fmt.Errorf("%w: source chain %s", precompile.ErrInvalidTransferMessage, msg.SourceChainID)
+	}
+	state, err := api.vm.blockChain.StateAt(api.vm.blockChain.LastAcceptedBlock().Root())
+	if err != nil {
+		return nil, err
+	}
+	if !precompile.IsNativeTokenMessageSent(state, precompile.NativeTokenMessageID(msg)) {
+		return nil, errNativeTokenMessageNotSent
+	}
+	if api.vm.ctx.TeleporterSigner == nil {
+		return nil, errTeleporterSignerUnavailable
+	}
+	return api.vm.ctx.TeleporterSigner.Sign(msg)
+}
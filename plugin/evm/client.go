@@ -6,9 +6,13 @@ package evm
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -23,6 +27,16 @@ type Client interface {
 	LockProfile(ctx context.Context) error
 	SetLogLevel(ctx context.Context, level log.Lvl) error
 	GetVMConfig(ctx context.Context) (*Config, error)
+	AddLocalAccounts(ctx context.Context, addresses []common.Address) error
+	RemoveLocalAccounts(ctx context.Context, addresses []common.Address) error
+	GetLocalAccounts(ctx context.Context) ([]common.Address, error)
+	EvictTransaction(ctx context.Context, hash common.Hash) error
+	EvictTransactionsFrom(ctx context.Context, address common.Address) (int, error)
+	SetMinGasPrice(ctx context.Context, minGasPrice *big.Int) error
+	GetMinGasPrice(ctx context.Context) (*big.Int, error)
+	UpdateUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig) error
+	ValidateUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig) ([]*params.UpgradeConfigError, error)
+	DryRunUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig, blocks uint64) ([]*core.BlockReplayResult, error)
 }
 
 // Client implementation for interacting with EVM [chain]
@@ -72,3 +86,82 @@ func (c *client) GetVMConfig(ctx context.Context) (*Config, error) {
 	err := c.requester.SendRequest(ctx, "admin.getVMConfig", struct{}{}, res)
 	return res.Config, err
 }
+
+// AddLocalAccounts marks [addresses] as local in the tx pool
+func (c *client) AddLocalAccounts(ctx context.Context, addresses []common.Address) error {
+	return c.requester.SendRequest(ctx, "admin.addLocalAccounts", &LocalAccountsArgs{
+		Addresses: addresses,
+	}, &api.EmptyReply{})
+}
+
+// RemoveLocalAccounts stops treating [addresses] as local in the tx pool
+func (c *client) RemoveLocalAccounts(ctx context.Context, addresses []common.Address) error {
+	return c.requester.SendRequest(ctx, "admin.removeLocalAccounts", &LocalAccountsArgs{
+		Addresses: addresses,
+	}, &api.EmptyReply{})
+}
+
+// GetLocalAccounts returns the addresses treated as local in the tx pool
+func (c *client) GetLocalAccounts(ctx context.Context) ([]common.Address, error) {
+	res := &LocalAccountsReply{}
+	err := c.requester.SendRequest(ctx, "admin.getLocalAccounts", struct{}{}, res)
+	return res.Addresses, err
+}
+
+// EvictTransaction removes the transaction with [hash] from the tx pool
+func (c *client) EvictTransaction(ctx context.Context, hash common.Hash) error {
+	return c.requester.SendRequest(ctx, "admin.evictTransaction", &EvictTransactionArgs{
+		Hash: hash,
+	}, &EvictTransactionsReply{})
+}
+
+// EvictTransactionsFrom removes all the transactions of [address] from the tx pool
+// and returns the number of transactions removed
+func (c *client) EvictTransactionsFrom(ctx context.Context, address common.Address) (int, error) {
+	res := &EvictTransactionsReply{}
+	err := c.requester.SendRequest(ctx, "admin.evictTransactionsFrom", &EvictTransactionsFromArgs{
+		Address: address,
+	}, res)
+	return res.Evicted, err
+}
+
+// UpdateUpgradeConfig applies the precompile upgrades of [upgradeConfig] without restarting the node
+func (c *client) UpdateUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig) error {
+	return c.requester.SendRequest(ctx, "admin.updateUpgradeConfig", &UpdateUpgradeConfigArgs{
+		UpgradeConfig: *upgradeConfig,
+	}, &api.EmptyReply{})
+}
+
+// ValidateUpgradeConfig returns the errors that prevent [upgradeConfig] from being applied to the chain
+func (c *client) ValidateUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig) ([]*params.UpgradeConfigError, error) {
+	res := &ValidateUpgradeConfigReply{}
+	err := c.requester.SendRequest(ctx, "admin.validateUpgradeConfig", &UpdateUpgradeConfigArgs{
+		UpgradeConfig: *upgradeConfig,
+	}, res)
+	return res.Errors, err
+}
+
+// DryRunUpgradeConfig replays the last [blocks] accepted blocks against [upgradeConfig] and returns how each
+// replayed block diverges from the accepted chain
+func (c *client) DryRunUpgradeConfig(ctx context.Context, upgradeConfig *params.UpgradeConfig, blocks uint64) ([]*core.BlockReplayResult, error) {
+	res := &DryRunUpgradeConfigReply{}
+	err := c.requester.SendRequest(ctx, "admin.dryRunUpgradeConfig", &DryRunUpgradeConfigArgs{
+		UpgradeConfig: *upgradeConfig,
+		Blocks:        blocks,
+	}, res)
+	return res.Blocks, err
+}
+
+// SetMinGasPrice sets the minimum gas fee cap of remote transactions admitted to the tx pool
+func (c *client) SetMinGasPrice(ctx context.Context, minGasPrice *big.Int) error {
+	return c.requester.SendRequest(ctx, "admin.setMinGasPrice", &MinGasPriceArgs{
+		MinGasPrice: minGasPrice,
+	}, &api.EmptyReply{})
+}
+
+// GetMinGasPrice returns the minimum gas fee cap of remote transactions admitted to the tx pool
+func (c *client) GetMinGasPrice(ctx context.Context) (*big.Int, error) {
+	res := &MinGasPriceReply{}
+	err := c.requester.SendRequest(ctx, "admin.getMinGasPrice", struct{}{}, res)
+	return res.MinGasPrice, err
+}
@@ -0,0 +1,78 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/internal/ethapi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that conditional transactions are rejected on submission when their preconditions
+// cannot be satisfied, and are otherwise included in the next block.
+func TestSendRawTransactionConditional(t *testing.T) {
+	contract := common.HexToAddress("0x0400000000000000000000000000000000000000")
+	slot := common.HexToHash("0x01")
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Alloc[contract] = core.GenesisAccount{
+		Code:    common.FromHex("0x00"),
+		Storage: map[common.Hash]common.Hash{slot: common.HexToHash("0x07")},
+		Balance: common.Big0,
+	}
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	tx, err := types.SignNewTx(testKeys[0], types.LatestSigner(vm.chainConfig), &types.DynamicFeeTx{
+		ChainID:   vm.chainConfig.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(testMinGasPrice),
+		Gas:       100_000,
+		To:        &contract,
+		Value:     common.Big0,
+	})
+	require.NoError(t, err)
+	input, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	api := ethapi.NewTransactionAPI(vm.eth.APIBackend, nil)
+
+	_, err = api.SendRawTransactionConditional(context.Background(), input, types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{slot: common.HexToHash("0x08")}},
+		},
+	})
+	require.ErrorContains(t, err, "conditional not satisfied")
+	_, err = api.SendRawTransactionConditional(context.Background(), input, types.TransactionConditional{
+		BlockNumberMax: common.Big0,
+	})
+	require.ErrorContains(t, err, "cannot be satisfied by the next block")
+	require.Nil(t, vm.txPool.Get(tx.Hash()))
+
+	hash, err := api.SendRawTransactionConditional(context.Background(), input, types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			contract: {StorageSlots: map[common.Hash]common.Hash{slot: common.HexToHash("0x07")}},
+		},
+		BlockNumberMin: common.Big1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash(), hash)
+	require.NotNil(t, vm.txPool.Get(tx.Hash()).Conditional())
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(t, block.Transactions(), 1)
+	require.Equal(t, tx.Hash(), block.Transactions()[0].Hash())
+}
@@ -6,11 +6,20 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/url"
 	"time"
 
+	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	corevm "github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth"
+	"github.com/ava-labs/subnet-evm/miner"
+	"github.com/ava-labs/subnet-evm/plugin/evm/faucet"
+	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/spf13/cast"
 )
 
@@ -22,8 +31,11 @@ const (
 	defaultTrieDirtyCache                         = 256
 	defaultTrieDirtyCommitTarget                  = 20
 	defaultSnapshotCache                          = 256
+	defaultTriePrefetcherParallelism              = 16
 	defaultSyncableCommitInterval                 = defaultCommitInterval * 4
 	defaultSnapshotAsync                          = true
+	defaultBlockPrefetchEnabled                   = true
+	defaultBlockBuildTxOrdering                   = miner.PriceTxOrdering
 	defaultRpcGasCap                              = 50_000_000 // Default to 50M Gas Limit
 	defaultRpcTxFeeCap                            = 100        // 100 AVAX
 	defaultMetricsExpensiveEnabled                = true
@@ -40,12 +52,31 @@ const (
 	defaultPriorityRegossipMaxTxs                 = 32
 	defaultPriorityRegossipTxsPerAddress          = 16
 	defaultOfflinePruningBloomFilterSize   uint64 = 512 // Default size (MB) for the offline pruner to use
+	defaultOnlinePruningBloomFilterSize    uint64 = 512 // Default size (MB) for the online pruner to use
+	defaultOnlinePruningRetention                 = defaultCommitInterval * 4
+	defaultAncientStoreThreshold           uint64 = 90_000 // Default number of recent blocks kept out of the ancient store
+	defaultPebbleCacheSize                        = 512    // MB
+	defaultPebbleHandles                          = 1024
+	defaultStateScheme                            = rawdb.HashScheme
 	defaultLogLevel                               = "info"
 	defaultLogJSONFormat                          = false
 	defaultMaxOutboundActiveRequests              = 16
 	defaultPopulateMissingTriesParallelism        = 1024
 	defaultStateSyncServerTrieCache               = 64 // MB
 	defaultAcceptedCacheSize                      = 32 // blocks
+	defaultTracingExporterType                    = "grpc"
+	defaultTracingEndpoint                        = "localhost:4317"
+	defaultTracingSampleRate                      = 0.1
+	defaultAlertLogEnabled                        = true
+	defaultAlertWebhookTimeout                    = 5 * time.Second
+	defaultAlertReorgDepth                        = 2
+	defaultFaucetMode                             = string(faucet.Transfer)
+	defaultFaucetAmount                           = 1_000_000_000_000_000_000 // 1 token of 18 decimals
+	defaultFaucetRateLimit                        = 24 * time.Hour
+	defaultFaucetMaxFundsPerIP                    = 5
+	defaultFaucetCaptchaTimeout                   = 5 * time.Second
+	defaultRPCDefaultMethodCost                   = 1_000
+	defaultRPCRateLimitBurstWindow                = 10 * time.Second
 
 	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
 	// should be ahead of local last accepted to perform state sync.
@@ -61,15 +92,30 @@ var (
 	defaultEnabledAPIs = []string{
 		"eth",
 		"eth-filter",
+		"subnetevm-filter",
+		"subnetevm-pending",
 		"net",
 		"web3",
 		"internal-eth",
 		"internal-blockchain",
 		"internal-transaction",
+		"internal-tx-pool",
+		"internal-subnetevm",
 	}
 	defaultAllowUnprotectedTxHashes = []common.Hash{
 		common.HexToHash("0xfefb2da535e927b85fe68eb81cb2e4a5827c905f78381a01ef2322aa9b0aee8e"), // EIP-1820: https://eips.ethereum.org/EIPS/eip-1820
 	}
+	// defaultRPCMethodCosts are the costs, in gas-equivalent units, of the RPC methods that
+	// are expensive to serve. The other methods cost [defaultRPCDefaultMethodCost].
+	defaultRPCMethodCosts = map[string]uint64{
+		"eth_call":             50_000,
+		"eth_estimateGas":      100_000,
+		"eth_createAccessList": 100_000,
+		"eth_getLogs":          100_000,
+		"eth_getProof":         50_000,
+		"eth_feeHistory":       10_000,
+		"debug_*":              1_000_000,
+	}
 )
 
 type Duration struct {
@@ -81,15 +127,46 @@ type Config struct {
 	// Airdrop
 	AirdropFile string `json:"airdrop"`
 
+	// PrecompilePlugins is a list of paths to Go plugins providing additional stateful precompiles.
+	// See precompile.LoadPlugins for the requirements on the plugins.
+	PrecompilePlugins []string `json:"precompile-plugins"`
+
 	// Subnet EVM APIs
 	SnowmanAPIEnabled bool   `json:"snowman-api-enabled"`
 	AdminAPIEnabled   bool   `json:"admin-api-enabled"`
 	AdminAPIDir       string `json:"admin-api-dir"`
 
+	// HealthAPIEnabled serves the health status of the node in the "health" RPC namespace
+	// and on the /health endpoint, which answers 503 while the node is unhealthy.
+	HealthAPIEnabled bool `json:"health-api-enabled"`
+	// HealthMaxBlockAgeFactor marks the node unhealthy once the last accepted block is older
+	// than this multiple of the TargetBlockRate of the fee config (0 = disabled).
+	HealthMaxBlockAgeFactor float64 `json:"health-max-block-age-factor"`
+	// HealthMaxCompactionDebt marks the node unhealthy once the estimated number of bytes the
+	// pebble database needs to compact exceeds this value (0 = disabled).
+	HealthMaxCompactionDebt uint64 `json:"health-max-compaction-debt"`
+
+	// FinalityAPIEnabled serves the accepted and preferred heights, the reorgs and the time
+	// to acceptance of the blocks in the "finality" RPC namespace, with a subscription to them.
+	FinalityAPIEnabled bool `json:"finality-api-enabled"`
+
+	// BridgeAPIEnabled serves the signatures of the validator over the native token transfers
+	// sent by the NativeTokenSource precompile in the "bridge" RPC namespace, for relayers.
+	BridgeAPIEnabled bool `json:"bridge-api-enabled"`
+
+	// SubnetAPIEnabled serves the validator set of the subnet, with the weights and the
+	// connection status and uptime of the validators as seen by this node, in the "subnet"
+	// RPC namespace.
+	SubnetAPIEnabled bool `json:"subnet-api-enabled"`
+
 	// EnabledEthAPIs is a list of Ethereum services that should be enabled
 	// If none is specified, then we use the default list [defaultEnabledAPIs]
 	EnabledEthAPIs []string `json:"eth-apis"`
 
+	// DisabledPrecompileAPIs is a list of config keys of precompiles (e.g. "feeManagerConfig") whose
+	// RPC helpers are not served by this node. This only affects the APIs of the node, not consensus.
+	DisabledPrecompileAPIs []string `json:"disabled-precompile-apis"`
+
 	// Continuous Profiler
 	ContinuousProfilerDir       string   `json:"continuous-profiler-dir"`       // If set to non-empty string creates a continuous profiler
 	ContinuousProfilerFrequency Duration `json:"continuous-profiler-frequency"` // Frequency to run continuous profiler if enabled
@@ -107,10 +184,18 @@ type Config struct {
 	TrieDirtyCommitTarget int      `json:"trie-dirty-commit-target"` // Memory limit to target in the dirty cache before performing a commit (MB)
 	SnapshotCache         int      `json:"snapshot-cache"`           // Size of the snapshot disk layer clean cache (MB)
 
+	TriePrefetcherParallelism int    `json:"trie-prefetcher-parallelism"` // Maximum number of tries loaded concurrently by the trie prefetcher
+	CacheProfile              string `json:"cache-profile"`               // Preset of the cache settings for the kind of node ("low-memory" or "rpc"), overridden by the cache settings set explicitly
+
 	// Eth Settings
 	Preimages      bool `json:"preimages-enabled"`
 	SnapshotAsync  bool `json:"snapshot-async"`
 	SnapshotVerify bool `json:"snapshot-verification-enabled"`
+	BlockPrefetch  bool `json:"block-prefetch-enabled"` // If enabled, blocks are executed on a throwaway state in the background to warm up the caches before they are processed
+
+	// Number of results of the expensive pure precompiles (e.g. ecrecover and pairing checks) cached across
+	// block verification and eth_call simulations (0 = disabled)
+	PrecompileResultCacheSize int `json:"precompile-result-cache-size"`
 
 	// Pruning Settings
 	Pruning                         bool    `json:"pruning-enabled"`                    // If enabled, trie roots are only persisted every 4096 blocks
@@ -119,10 +204,38 @@ type Config struct {
 	AllowMissingTries               bool    `json:"allow-missing-tries"`                // If enabled, warnings preventing an incomplete trie index are suppressed
 	PopulateMissingTries            *uint64 `json:"populate-missing-tries,omitempty"`   // Sets the starting point for re-populating missing tries. Disables re-generation if nil.
 	PopulateMissingTriesParallelism int     `json:"populate-missing-tries-parallelism"` // Number of concurrent readers to use when re-populating missing tries on startup.
+	StateScheme                     string  `json:"state-scheme"`                       // Scheme used to store state trie nodes on disk, either "hash" or "path". Cannot be changed once the chain is initialized.
 
 	// Metric Settings
 	MetricsExpensiveEnabled bool `json:"metrics-expensive-enabled"` // Debug-level metrics that might impact runtime performance
 
+	// Tracing Settings
+	TracingEnabled      bool              `json:"tracing-enabled"`       // If enabled, spans of the block lifecycle, RPC calls and stateful precompile calls are exported with OpenTelemetry
+	TracingExporterType string            `json:"tracing-exporter-type"` // Protocol of the OTLP exporter, either "grpc" or "http"
+	TracingEndpoint     string            `json:"tracing-endpoint"`      // Endpoint of the OTLP collector the spans are exported to
+	TracingInsecure     bool              `json:"tracing-insecure"`      // If enabled, the spans are exported without TLS
+	TracingHeaders      map[string]string `json:"tracing-headers"`       // Headers sent with the exported spans
+	TracingSampleRate   float64           `json:"tracing-sample-rate"`   // Fraction of the traces sampled, between 0 and 1
+
+	// Alerting Settings
+	AlertLogEnabled     bool              `json:"alert-log-enabled"`     // If enabled, alerts on consensus-critical anomalies are logged
+	AlertWebhookURL     string            `json:"alert-webhook-url"`     // URL the alerts are posted to as JSON, disabled if empty
+	AlertWebhookHeaders map[string]string `json:"alert-webhook-headers"` // Headers sent with the alerts posted to the webhook
+	AlertWebhookTimeout Duration          `json:"alert-webhook-timeout"` // Timeout of the requests posting the alerts to the webhook
+	AlertReorgDepth     int               `json:"alert-reorg-depth"`     // Number of blocks a reorg must drop to fire an alert, disabled if 0
+
+	// Faucet Settings
+	FaucetEnabled          bool                  `json:"faucet-enabled"`            // If enabled, the faucet endpoint funds the addresses requesting test tokens
+	FaucetKeyFile          string                `json:"faucet-key-file"`           // File holding the hex private key signing the transactions of the faucet
+	FaucetMode             string                `json:"faucet-mode"`               // Either "transfer" from the balance of the faucet key, or "mint" through the NativeMinter precompile
+	FaucetAmount           *math.HexOrDecimal256 `json:"faucet-amount"`             // Amount of wei given to each request
+	FaucetRateLimit        Duration              `json:"faucet-rate-limit"`         // Time after which an address can be funded again, no limit if 0
+	FaucetMaxFundsPerIP    uint64                `json:"faucet-max-funds-per-ip"`   // Number of requests from the same IP funded within the rate limit, no limit if 0
+	FaucetAllowlist        []common.Address      `json:"faucet-allowlist"`          // If set, only these addresses can be funded
+	FaucetCaptchaVerifyURL string                `json:"faucet-captcha-verify-url"` // Siteverify URL of the reCAPTCHA or hCaptcha provider, no captcha required if empty
+	FaucetCaptchaSecret    string                `json:"faucet-captcha-secret"`     // Secret of the site verifying the captchas
+	FaucetCaptchaTimeout   Duration              `json:"faucet-captcha-timeout"`    // Timeout of the captcha verifications
+
 	// API Settings
 	LocalTxsEnabled bool `json:"local-txs-enabled"`
 
@@ -134,6 +247,20 @@ type Config struct {
 	TxPoolGlobalSlots  uint64   `json:"tx-pool-global-slots"`
 	TxPoolAccountQueue uint64   `json:"tx-pool-account-queue"`
 	TxPoolGlobalQueue  uint64   `json:"tx-pool-global-queue"`
+	TxPoolLifetime     Duration `json:"tx-pool-lifetime"`
+	// TxPoolMaxTxAge is the maximum amount of time a remote transaction is kept in the tx pool since it
+	// was first seen, whether executable or not. Zero means no limit.
+	TxPoolMaxTxAge Duration `json:"tx-pool-max-tx-age"`
+	// TxPoolMaxNonceGap is the maximum distance of the nonce of a remote transaction from the next nonce of
+	// its sender. Zero means no limit.
+	TxPoolMaxNonceGap uint64 `json:"tx-pool-max-nonce-gap"`
+	// TxPoolMinGasPrice is the minimum gas fee cap, in wei, of remote transactions admitted to the tx pool
+	// and gossiped by this node, independent of the base fee. Zero means no floor.
+	TxPoolMinGasPrice uint64 `json:"tx-pool-min-gas-price"`
+
+	// TxPoolLocals are treated as local in addition to [PriorityRegossipAddresses]: their transactions are
+	// exempt from price-based eviction and prioritized by the miner.
+	TxPoolLocals []common.Address `json:"tx-pool-locals"`
 
 	APIMaxDuration           Duration      `json:"api-max-duration"`
 	WSCPURefillRate          Duration      `json:"ws-cpu-refill-rate"`
@@ -143,10 +270,45 @@ type Config struct {
 	AllowUnprotectedTxs      bool          `json:"allow-unprotected-txs"`
 	AllowUnprotectedTxHashes []common.Hash `json:"allow-unprotected-tx-hashes"`
 
+	// BatchRequestLimit is the maximum number of requests in an RPC batch, and
+	// BatchResponseMaxSize the maximum size in bytes of the results of a batch (0 for no limit).
+	BatchRequestLimit    int `json:"batch-request-limit"`
+	BatchResponseMaxSize int `json:"batch-response-max-size"`
+	// HTTPBodyLimit is the maximum size in bytes of the body of an RPC request over HTTP, which
+	// bounds the size of a batch (0 for the default of 5 MiB).
+	HTTPBodyLimit int `json:"http-body-limit"`
+
+	// RPCCompression are the encodings ("gzip" or "deflate") responses to RPC requests over HTTP
+	// are compressed with, in order of preference, if the client accepts them.
+	RPCCompression []string `json:"rpc-compression"`
+	// RPCHTTP2Enabled serves the eth RPC API over cleartext HTTP/2 (h2c) to the clients upgrading
+	// their HTTP/1.1 connection. HTTP/2 over TLS is negotiated by the node serving the API.
+	RPCHTTP2Enabled bool `json:"rpc-http2-enabled"`
+
+	// RPC Rate Limiting Settings
+	RPCMethodCosts          map[string]uint64 `json:"rpc-method-costs"`            // Costs of the RPC methods by name or namespace ("debug_*"), overriding the defaults
+	RPCDefaultMethodCost    uint64            `json:"rpc-default-method-cost"`     // Cost of the RPC methods without a configured cost
+	RPCRateLimitBudget      uint64            `json:"rpc-rate-limit-budget"`       // Cost refilled each second to the budget of each client IP, disabled if 0
+	RPCRateLimitBurstWindow Duration          `json:"rpc-rate-limit-burst-window"` // Duration of budget a client can accumulate while idle
+	RPCRateLimitKeyHeader   string            `json:"rpc-rate-limit-key-header"`   // Header holding the API key identifying clients
+	RPCRateLimitKeyBudgets  map[string]uint64 `json:"rpc-rate-limit-key-budgets"`  // Budgets of the clients by API key, replacing the budget of their IP
+
+	// RPCSlowCallThreshold is the duration after which RPC calls are logged as slow, along
+	// with a summary of their parameters and the number of state reads (disabled if 0).
+	RPCSlowCallThreshold Duration `json:"rpc-slow-call-threshold"`
+
 	// Keystore Settings
 	KeystoreDirectory             string `json:"keystore-directory"` // both absolute and relative supported
 	KeystoreExternalSigner        string `json:"keystore-external-signer"`
 	KeystoreInsecureUnlockAllowed bool   `json:"keystore-insecure-unlock-allowed"`
+	KeystoreUseLightweightKDF     bool   `json:"keystore-use-lightweight-kdf"` // lowers the cost of the scrypt KDF of new keys
+	// KeystoreUnlockAccounts are the keystore accounts unlocked on startup with the passwords read from
+	// [KeystorePasswordFile] (one per line, in the order of the accounts, the last one being used for the
+	// remaining accounts), so that automation can sign transactions with them through the RPC without
+	// handling the keys. Requires [KeystoreInsecureUnlockAllowed], since the RPC of the node can then
+	// send transactions from these accounts.
+	KeystoreUnlockAccounts []common.Address `json:"keystore-unlock-accounts"`
+	KeystorePasswordFile   string           `json:"keystore-password-file"`
 
 	// Gossip Settings
 	RemoteGossipOnlyEnabled       bool             `json:"remote-gossip-only-enabled"`
@@ -165,11 +327,60 @@ type Config struct {
 	// Address for Tx Fees (must be empty if not supported by blockchain)
 	FeeRecipient string `json:"feeRecipient"`
 
+	// Gas of each built block reserved for transactions from admins of governance precompiles, such as
+	// the fee config manager, so that they cannot be crowded out by spam (0 = disabled)
+	GovernanceGasReservation uint64 `json:"governance-gas-reservation"`
+
+	// Block Building Settings
+	BlockBuildMaxDuration Duration `json:"block-build-max-duration"` // Duration after which no more transactions are committed to the block being built (0 = unbounded)
+	BlockBuildTxOrdering  string   `json:"block-build-tx-ordering"`  // Ordering of the transactions committed to the blocks built ("price", "fifo", "round-robin" or a custom registered ordering)
+
 	// Offline Pruning Settings
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
 	OfflinePruningBloomFilterSize uint64 `json:"offline-pruning-bloom-filter-size"`
 	OfflinePruningDataDirectory   string `json:"offline-pruning-data-directory"`
 
+	// Online Pruning Settings
+	OnlinePruning                bool   `json:"online-pruning-enabled"`           // If enabled, historical state is pruned in the background
+	OnlinePruningRetention       uint64 `json:"online-pruning-retention"`         // Number of recent blocks whose committed state is kept by the online pruner
+	OnlinePruningBloomFilterSize uint64 `json:"online-pruning-bloom-filter-size"` // Size (MB) of the bloom filter used by each online pruning pass
+
+	// Ancient Store Settings
+	AncientStore          bool   `json:"ancient-store-enabled"`   // If enabled, old blocks are moved out of the key-value store into an append-only ancient store
+	AncientStoreDirectory string `json:"ancient-store-directory"` // Directory holding the ancient store
+	AncientStoreThreshold uint64 `json:"ancient-store-threshold"` // Number of recent accepted blocks kept in the key-value store
+
+	// State Export Settings
+	StateExportInterval  uint64 `json:"state-export-interval"`  // Number of accepted blocks between the background exports of the state (disabled if 0)
+	StateExportDirectory string `json:"state-export-directory"` // Directory the background state exports are written to
+	StateImportFile      string `json:"state-import-file"`      // State export used to initialize the node if it has not accepted any block yet
+
+	// Chain Import Settings
+	ChainImportFile string `json:"chain-import-file"` // Chain export replayed on top of the last accepted block, or written as the history of the imported state
+
+	// State Diff Archive Settings
+	StateDiffArchive   bool   `json:"state-diff-archive-enabled"` // If enabled, the accounts and storage slots modified by each block are stored and served over RPC
+	StateDiffRetention uint64 `json:"state-diff-retention"`       // Number of recent accepted blocks whose state diff is kept (all if 0)
+
+	// Index Maintenance Settings
+	TxLookupLimit  uint64 `json:"tx-lookup-limit"` // Number of recent accepted blocks whose transactions are indexed (all if 0)
+	RebuildIndexes bool   `json:"rebuild-indexes"` // If enabled, the bloom and transaction lookup indexes are rebuilt in the background on startup
+	CompactIndexes bool   `json:"compact-indexes"` // If enabled, the key ranges of the bloom and transaction lookup indexes are compacted on startup
+
+	// History Pruning Settings
+	HistoryRetention uint64 `json:"history-retention"` // Number of recent accepted blocks whose bodies and receipts are kept (all if 0), headers are always kept
+
+	// Database Settings
+	DatabaseType                   string `json:"database-type"`                     // Key-value database backend of the chain data, either the avalanchego database ("") or "pebbledb"
+	DatabaseDirectory              string `json:"database-directory"`                // Directory of the pebble database, defaults to a directory in the chain data directory
+	PebbleCacheSize                int    `json:"pebble-cache-size"`                 // Size (MB) of the pebble block cache and memory tables
+	PebbleHandles                  int    `json:"pebble-handles"`                    // Maximum number of files held open by pebble
+	PebbleMaxConcurrentCompactions int    `json:"pebble-max-concurrent-compactions"` // Maximum number of concurrent pebble compactions (0 = number of CPUs)
+	PebbleWALDisabled              bool   `json:"pebble-wal-disabled"`               // If enabled, writes not yet flushed by pebble are lost on a crash
+	PebbleWALDirectory             string `json:"pebble-wal-directory"`              // Directory of the pebble write ahead log, defaults to the database directory
+	PebbleWALBytesPerSync          int    `json:"pebble-wal-bytes-per-sync"`         // Bytes written to the pebble write ahead log between background syncs (0 = disabled)
+	PebbleSyncWrites               bool   `json:"pebble-sync-writes"`                // If enabled, the pebble write ahead log is synced to disk on every write
+
 	// VM2VM network
 	MaxOutboundActiveRequests int64 `json:"max-outbound-active-requests"`
 
@@ -204,11 +415,44 @@ func (c Config) EthBackendSettings() eth.Settings {
 	return eth.Settings{MaxBlocksPerRequest: c.MaxBlocksPerRequest}
 }
 
+// RPCCostLimits returns the cost accounting and rate limiting of the RPC calls, with
+// the configured method costs overriding the default ones.
+func (c Config) RPCCostLimits() rpc.CostLimits {
+	costs := make(map[string]uint64, len(defaultRPCMethodCosts)+len(c.RPCMethodCosts))
+	for method, cost := range defaultRPCMethodCosts {
+		costs[method] = cost
+	}
+	for method, cost := range c.RPCMethodCosts {
+		costs[method] = cost
+	}
+	return rpc.CostLimits{
+		Costs:       costs,
+		DefaultCost: c.RPCDefaultMethodCost,
+		Budget:      c.RPCRateLimitBudget,
+		KeyBudgets:  c.RPCRateLimitKeyBudgets,
+		KeyHeader:   c.RPCRateLimitKeyHeader,
+		BurstWindow: c.RPCRateLimitBurstWindow.Duration,
+	}
+}
+
 func (c *Config) SetDefaults() {
 	c.EnabledEthAPIs = defaultEnabledAPIs
 	c.RPCGasCap = defaultRpcGasCap
 	c.RPCTxFeeCap = defaultRpcTxFeeCap
 	c.MetricsExpensiveEnabled = defaultMetricsExpensiveEnabled
+	c.TracingExporterType = defaultTracingExporterType
+	c.TracingEndpoint = defaultTracingEndpoint
+	c.TracingSampleRate = defaultTracingSampleRate
+	c.AlertLogEnabled = defaultAlertLogEnabled
+	c.AlertWebhookTimeout = Duration{defaultAlertWebhookTimeout}
+	c.AlertReorgDepth = defaultAlertReorgDepth
+	c.FaucetMode = defaultFaucetMode
+	c.FaucetAmount = (*math.HexOrDecimal256)(new(big.Int).SetUint64(defaultFaucetAmount))
+	c.FaucetRateLimit = Duration{defaultFaucetRateLimit}
+	c.FaucetMaxFundsPerIP = defaultFaucetMaxFundsPerIP
+	c.FaucetCaptchaTimeout = Duration{defaultFaucetCaptchaTimeout}
+	c.RPCDefaultMethodCost = defaultRPCDefaultMethodCost
+	c.RPCRateLimitBurstWindow = Duration{defaultRPCRateLimitBurstWindow}
 
 	c.TxPoolJournal = core.DefaultTxPoolConfig.Journal
 	c.TxPoolRejournal = Duration{core.DefaultTxPoolConfig.Rejournal}
@@ -218,6 +462,7 @@ func (c *Config) SetDefaults() {
 	c.TxPoolGlobalSlots = core.DefaultTxPoolConfig.GlobalSlots
 	c.TxPoolAccountQueue = core.DefaultTxPoolConfig.AccountQueue
 	c.TxPoolGlobalQueue = core.DefaultTxPoolConfig.GlobalQueue
+	c.TxPoolLifetime = Duration{core.DefaultTxPoolConfig.Lifetime}
 
 	c.APIMaxDuration.Duration = defaultApiMaxDuration
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
@@ -230,9 +475,13 @@ func (c *Config) SetDefaults() {
 	c.TrieDirtyCache = defaultTrieDirtyCache
 	c.TrieDirtyCommitTarget = defaultTrieDirtyCommitTarget
 	c.SnapshotCache = defaultSnapshotCache
+	c.TriePrefetcherParallelism = defaultTriePrefetcherParallelism
 	c.AcceptorQueueLimit = defaultAcceptorQueueLimit
 	c.CommitInterval = defaultCommitInterval
 	c.SnapshotAsync = defaultSnapshotAsync
+	c.BlockPrefetch = defaultBlockPrefetchEnabled
+	c.PrecompileResultCacheSize = corevm.DefaultPrecompileResultCacheSize
+	c.BlockBuildTxOrdering = defaultBlockBuildTxOrdering
 	c.RegossipFrequency.Duration = defaultRegossipFrequency
 	c.RegossipMaxTxs = defaultRegossipMaxTxs
 	c.RegossipTxsPerAddress = defaultRegossipTxsPerAddress
@@ -240,6 +489,12 @@ func (c *Config) SetDefaults() {
 	c.PriorityRegossipMaxTxs = defaultPriorityRegossipMaxTxs
 	c.PriorityRegossipTxsPerAddress = defaultPriorityRegossipTxsPerAddress
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
+	c.OnlinePruningRetention = defaultOnlinePruningRetention
+	c.OnlinePruningBloomFilterSize = defaultOnlinePruningBloomFilterSize
+	c.AncientStoreThreshold = defaultAncientStoreThreshold
+	c.PebbleCacheSize = defaultPebbleCacheSize
+	c.PebbleHandles = defaultPebbleHandles
+	c.StateScheme = defaultStateScheme
 	c.LogLevel = defaultLogLevel
 	c.LogJSONFormat = defaultLogJSONFormat
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
@@ -251,6 +506,56 @@ func (c *Config) SetDefaults() {
 	c.AcceptedCacheSize = defaultAcceptedCacheSize
 }
 
+const (
+	// lowMemoryCacheProfile sizes the caches for validators with a few GB of memory.
+	lowMemoryCacheProfile = "low-memory"
+	// rpcCacheProfile sizes the caches for RPC nodes on large machines serving
+	// heavy read traffic.
+	rpcCacheProfile = "rpc"
+)
+
+// cacheProfile is a preset of the cache settings, selected with the cache-profile option.
+type cacheProfile struct {
+	TrieCleanCache            int
+	TrieDirtyCache            int
+	SnapshotCache             int
+	TriePrefetcherParallelism int
+}
+
+var cacheProfiles = map[string]cacheProfile{
+	lowMemoryCacheProfile: {
+		TrieCleanCache:            128,
+		TrieDirtyCache:            64,
+		SnapshotCache:             64,
+		TriePrefetcherParallelism: 4,
+	},
+	rpcCacheProfile: {
+		TrieCleanCache:            4096,
+		TrieDirtyCache:            1024,
+		SnapshotCache:             4096,
+		TriePrefetcherParallelism: 64,
+	},
+}
+
+// unmarshal sets the fields of [c] set in [configBytes]. If a cache profile is
+// selected, its cache settings replace the defaults before the config is unmarshalled
+// again, so that the cache settings set explicitly take precedence over the profile.
+func (c *Config) unmarshal(configBytes []byte) error {
+	if err := json.Unmarshal(configBytes, c); err != nil {
+		return err
+	}
+	profile, ok := cacheProfiles[c.CacheProfile]
+	if !ok {
+		// An unknown profile is reported by Validate
+		return nil
+	}
+	c.TrieCleanCache = profile.TrieCleanCache
+	c.TrieDirtyCache = profile.TrieDirtyCache
+	c.SnapshotCache = profile.SnapshotCache
+	c.TriePrefetcherParallelism = profile.TriePrefetcherParallelism
+	return json.Unmarshal(configBytes, c)
+}
+
 func (d *Duration) UnmarshalJSON(data []byte) (err error) {
 	var v interface{}
 	if err := json.Unmarshal(data, &v); err != nil {
@@ -282,10 +587,179 @@ func (c *Config) Validate() error {
 	if !c.Pruning && c.OfflinePruning {
 		return fmt.Errorf("cannot run offline pruning while pruning is disabled")
 	}
+	if _, ok := cacheProfiles[c.CacheProfile]; c.CacheProfile != "" && !ok {
+		return fmt.Errorf("unknown cache profile %q, expected %q or %q", c.CacheProfile, lowMemoryCacheProfile, rpcCacheProfile)
+	}
+	if c.TriePrefetcherParallelism < 1 {
+		return fmt.Errorf("trie prefetcher parallelism (%d) must be at least 1", c.TriePrefetcherParallelism)
+	}
+	if c.TracingEnabled {
+		if _, err := trace.ExporterTypeFromString(c.TracingExporterType); err != nil {
+			return fmt.Errorf("invalid tracing exporter type: %w", err)
+		}
+	}
+	if c.TracingSampleRate < 0 || c.TracingSampleRate > 1 {
+		return fmt.Errorf("tracing sample rate (%f) must be between 0 and 1", c.TracingSampleRate)
+	}
+	if len(c.AlertWebhookURL) > 0 {
+		if _, err := url.ParseRequestURI(c.AlertWebhookURL); err != nil {
+			return fmt.Errorf("invalid alert webhook url: %w", err)
+		}
+	}
+	if c.AlertWebhookTimeout.Duration < 0 {
+		return fmt.Errorf("alert webhook timeout (%s) cannot be negative", c.AlertWebhookTimeout)
+	}
+	if c.AlertReorgDepth < 0 {
+		return fmt.Errorf("alert reorg depth (%d) cannot be negative", c.AlertReorgDepth)
+	}
+	if c.FaucetEnabled {
+		if err := c.validateFaucet(); err != nil {
+			return err
+		}
+	}
+	if c.HealthMaxBlockAgeFactor < 0 {
+		return fmt.Errorf("health max block age factor (%f) cannot be negative", c.HealthMaxBlockAgeFactor)
+	}
+	if c.RPCRateLimitBudget > 0 && c.RPCRateLimitBurstWindow.Duration <= 0 {
+		return fmt.Errorf("rpc rate limit burst window (%s) must be positive", c.RPCRateLimitBurstWindow)
+	}
+	if len(c.RPCRateLimitKeyBudgets) > 0 && len(c.RPCRateLimitKeyHeader) == 0 {
+		return fmt.Errorf("rpc rate limit key budgets require the rpc rate limit key header")
+	}
+	if c.RPCSlowCallThreshold.Duration < 0 {
+		return fmt.Errorf("rpc slow call threshold (%s) cannot be negative", c.RPCSlowCallThreshold)
+	}
+	if len(c.KeystoreUnlockAccounts) > 0 {
+		switch {
+		case len(c.KeystoreExternalSigner) > 0:
+			return fmt.Errorf("cannot unlock the accounts of the external signer, which manages their keys")
+		case len(c.KeystorePasswordFile) == 0:
+			return fmt.Errorf("unlocking keystore accounts requires a keystore password file")
+		case !c.KeystoreInsecureUnlockAllowed:
+			return fmt.Errorf("unlocking keystore accounts requires keystore-insecure-unlock-allowed, since the RPC can then send transactions from them")
+		}
+	}
+	if c.BatchRequestLimit < 0 {
+		return fmt.Errorf("batch request limit (%d) cannot be negative", c.BatchRequestLimit)
+	}
+	if c.BatchResponseMaxSize < 0 {
+		return fmt.Errorf("batch response max size (%d) cannot be negative", c.BatchResponseMaxSize)
+	}
+	if c.HTTPBodyLimit < 0 {
+		return fmt.Errorf("http body limit (%d) cannot be negative", c.HTTPBodyLimit)
+	}
+	if err := rpc.ValidateHTTPCompression(c.RPCCompression); err != nil {
+		return err
+	}
+	if c.BlockBuildMaxDuration.Duration < 0 {
+		return fmt.Errorf("block build max duration (%s) cannot be negative", c.BlockBuildMaxDuration)
+	}
+	if _, ok := miner.GetTxOrderer(c.BlockBuildTxOrdering); !ok {
+		return fmt.Errorf("unknown block build tx ordering %q", c.BlockBuildTxOrdering)
+	}
 
 	// If pruning is enabled, the commit interval must be non-zero so the node commits state tries every CommitInterval blocks.
 	if c.Pruning && c.CommitInterval == 0 {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
+
+	if !c.Pruning && c.OnlinePruning {
+		return fmt.Errorf("cannot run online pruning while pruning is disabled")
+	}
+	// The online pruner keeps the committed state within the retention window, so the window must
+	// cover at least one commit interval for a committed state other than the last to be kept.
+	if c.OnlinePruning && c.OnlinePruningRetention < c.CommitInterval {
+		return fmt.Errorf("online pruning retention (%d) must be at least the commit interval (%d)", c.OnlinePruningRetention, c.CommitInterval)
+	}
+	if c.AncientStore && len(c.AncientStoreDirectory) == 0 {
+		return fmt.Errorf("ancient store directory must be specified when the ancient store is enabled")
+	}
+	if c.DatabaseType != "" && c.DatabaseType != pebbleDatabaseType {
+		return fmt.Errorf("unknown database type %q, expected %q or none", c.DatabaseType, pebbleDatabaseType)
+	}
+	if err := c.validateStateScheme(); err != nil {
+		return err
+	}
+	if c.StateExportInterval != 0 && len(c.StateExportDirectory) == 0 {
+		return fmt.Errorf("state export directory must be specified when the state export interval is set")
+	}
+	if len(c.StateImportFile) != 0 && c.StateSyncEnabled {
+		return fmt.Errorf("cannot enable state sync while importing state from %s", c.StateImportFile)
+	}
+	if len(c.StateImportFile) != 0 && c.StateScheme == rawdb.PathScheme {
+		return fmt.Errorf("cannot import state with the %s state scheme", c.StateScheme)
+	}
+	if len(c.ChainImportFile) != 0 && c.StateSyncEnabled {
+		return fmt.Errorf("cannot enable state sync while importing the chain from %s", c.ChainImportFile)
+	}
+	if c.StateSyncCommitInterval == 0 {
+		return fmt.Errorf("cannot use state sync commit interval of 0")
+	}
+	// If pruning is enabled, only the state of blocks at multiples of the commit interval is persisted, so
+	// the state sync commit interval must be a multiple of the commit interval to serve the summary state.
+	if c.Pruning && c.StateSyncCommitInterval%c.CommitInterval != 0 {
+		return fmt.Errorf("state sync commit interval (%d) must be a multiple of the commit interval (%d) with pruning enabled", c.StateSyncCommitInterval, c.CommitInterval)
+	}
+	if c.HistoryRetention != 0 {
+		if c.AncientStore {
+			return fmt.Errorf("cannot prune the block history with the ancient store enabled")
+		}
+		// The latest state sync summary is at most [StateSyncCommitInterval] blocks old, and syncing nodes
+		// fetch [parentsToGet] parents of its block, so these must be retained for the summary to be servable.
+		// This also retains the blocks reprocessed on startup since the last committed state.
+		if minRetention := c.StateSyncCommitInterval + parentsToGet; c.HistoryRetention < minRetention {
+			return fmt.Errorf("history retention (%d) must be at least the state sync commit interval plus %d blocks (%d)", c.HistoryRetention, parentsToGet, minRetention)
+		}
+	}
+	return nil
+}
+
+// validateStateScheme returns an error if the state scheme is unknown or does
+// not support the other enabled features. The path scheme only keeps a single
+// version of the state on disk, so it requires pruning, does not need offline
+// or online pruning, and cannot be populated by state sync, which writes trie
+// nodes keyed by hash.
+func (c *Config) validateStateScheme() error {
+	switch c.StateScheme {
+	case rawdb.HashScheme, "":
+		return nil
+	case rawdb.PathScheme:
+	default:
+		return fmt.Errorf("unknown state scheme %q, expected %q or %q", c.StateScheme, rawdb.HashScheme, rawdb.PathScheme)
+	}
+	if !c.Pruning {
+		return fmt.Errorf("cannot use the %s state scheme while pruning is disabled", c.StateScheme)
+	}
+	if c.OfflinePruning || c.OnlinePruning {
+		return fmt.Errorf("cannot run offline pruning (enabled: %t)/online pruning (enabled: %t) with the %s state scheme", c.OfflinePruning, c.OnlinePruning, c.StateScheme)
+	}
+	if c.StateSyncEnabled {
+		return fmt.Errorf("cannot enable state sync with the %s state scheme", c.StateScheme)
+	}
+	return nil
+}
+
+// validateFaucet returns an error if the faucet cannot be started with the config.
+func (c *Config) validateFaucet() error {
+	if len(c.FaucetKeyFile) == 0 {
+		return fmt.Errorf("the faucet requires a faucet key file")
+	}
+	if mode := faucet.Mode(c.FaucetMode); mode != faucet.Transfer && mode != faucet.Mint {
+		return fmt.Errorf("unknown faucet mode %q, expected %q or %q", c.FaucetMode, faucet.Transfer, faucet.Mint)
+	}
+	if c.FaucetAmount == nil || (*big.Int)(c.FaucetAmount).Sign() <= 0 {
+		return fmt.Errorf("faucet amount (%v) must be positive", (*big.Int)(c.FaucetAmount))
+	}
+	if c.FaucetRateLimit.Duration < 0 {
+		return fmt.Errorf("faucet rate limit (%s) cannot be negative", c.FaucetRateLimit)
+	}
+	if len(c.FaucetCaptchaVerifyURL) > 0 {
+		if _, err := url.ParseRequestURI(c.FaucetCaptchaVerifyURL); err != nil {
+			return fmt.Errorf("invalid faucet captcha verify url: %w", err)
+		}
+		if len(c.FaucetCaptchaSecret) == 0 {
+			return fmt.Errorf("the faucet captcha requires a faucet captcha secret")
+		}
+	}
 	return nil
 }
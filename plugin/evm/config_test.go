@@ -9,7 +9,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/miner"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,7 +50,7 @@ func TestUnmarshalConfig(t *testing.T) {
 
 		{
 			"tx pool configurations",
-			[]byte(`{"tx-pool-journal": "hello", "tx-pool-price-limit": 1, "tx-pool-price-bump": 2, "tx-pool-account-slots": 3, "tx-pool-global-slots": 4, "tx-pool-account-queue": 5, "tx-pool-global-queue": 6}`),
+			[]byte(`{"tx-pool-journal": "hello", "tx-pool-price-limit": 1, "tx-pool-price-bump": 2, "tx-pool-account-slots": 3, "tx-pool-global-slots": 4, "tx-pool-account-queue": 5, "tx-pool-global-queue": 6, "tx-pool-lifetime": "10m", "tx-pool-max-tx-age": "1h", "tx-pool-max-nonce-gap": 7, "tx-pool-min-gas-price": 8}`),
 			Config{
 				TxPoolJournal:      "hello",
 				TxPoolPriceLimit:   1,
@@ -56,10 +59,20 @@ func TestUnmarshalConfig(t *testing.T) {
 				TxPoolGlobalSlots:  4,
 				TxPoolAccountQueue: 5,
 				TxPoolGlobalQueue:  6,
+				TxPoolLifetime:     Duration{10 * time.Minute},
+				TxPoolMaxTxAge:     Duration{time.Hour},
+				TxPoolMaxNonceGap:  7,
+				TxPoolMinGasPrice:  8,
 			},
 			false,
 		},
 
+		{
+			"tx pool locals",
+			[]byte(`{"tx-pool-locals": ["0x0000000000000000000000000000000000000001"]}`),
+			Config{TxPoolLocals: []common.Address{common.HexToAddress("0x1")}},
+			false,
+		},
 		{
 			"state sync enabled",
 			[]byte(`{"state-sync-enabled":true}`),
@@ -93,3 +106,798 @@ func TestUnmarshalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStateSyncCommitInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(*Config) {},
+			false,
+		},
+		{
+			"zero interval",
+			func(c *Config) { c.StateSyncCommitInterval = 0 },
+			true,
+		},
+		{
+			"multiple of commit interval",
+			func(c *Config) { c.StateSyncCommitInterval = 2 * c.CommitInterval },
+			false,
+		},
+		{
+			"not a multiple of commit interval",
+			func(c *Config) { c.StateSyncCommitInterval = c.CommitInterval + 1 },
+			true,
+		},
+		{
+			"not a multiple of commit interval without pruning",
+			func(c *Config) {
+				c.Pruning = false
+				c.StateSyncCommitInterval = c.CommitInterval + 1
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateOnlinePruning(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) { c.OnlinePruning = true },
+			false,
+		},
+		{
+			"pruning disabled",
+			func(c *Config) {
+				c.Pruning = false
+				c.OnlinePruning = true
+			},
+			true,
+		},
+		{
+			"retention of one commit interval",
+			func(c *Config) {
+				c.OnlinePruning = true
+				c.OnlinePruningRetention = c.CommitInterval
+			},
+			false,
+		},
+		{
+			"retention shorter than commit interval",
+			func(c *Config) {
+				c.OnlinePruning = true
+				c.OnlinePruningRetention = c.CommitInterval - 1
+			},
+			true,
+		},
+		{
+			"retention ignored when disabled",
+			func(c *Config) { c.OnlinePruningRetention = 0 },
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAncientStore(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"enabled with directory",
+			func(c *Config) {
+				c.AncientStore = true
+				c.AncientStoreDirectory = "ancient"
+			},
+			false,
+		},
+		{
+			"enabled without directory",
+			func(c *Config) { c.AncientStore = true },
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDatabaseType(t *testing.T) {
+	tests := []struct {
+		databaseType string
+		expectedErr  bool
+	}{
+		{"", false},
+		{pebbleDatabaseType, false},
+		{"leveldb", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.databaseType, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			c.DatabaseType = tt.databaseType
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStateScheme(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"path scheme",
+			func(c *Config) { c.StateScheme = rawdb.PathScheme },
+			false,
+		},
+		{
+			"unknown scheme",
+			func(c *Config) { c.StateScheme = "tree" },
+			true,
+		},
+		{
+			"path scheme with pruning disabled",
+			func(c *Config) {
+				c.StateScheme = rawdb.PathScheme
+				c.Pruning = false
+			},
+			true,
+		},
+		{
+			"path scheme with online pruning",
+			func(c *Config) {
+				c.StateScheme = rawdb.PathScheme
+				c.OnlinePruning = true
+			},
+			true,
+		},
+		{
+			"path scheme with state sync",
+			func(c *Config) {
+				c.StateScheme = rawdb.PathScheme
+				c.StateSyncEnabled = true
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStateExport(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"export with directory",
+			func(c *Config) {
+				c.StateExportInterval = 4096
+				c.StateExportDirectory = "exports"
+			},
+			false,
+		},
+		{
+			"export without directory",
+			func(c *Config) { c.StateExportInterval = 4096 },
+			true,
+		},
+		{
+			"import",
+			func(c *Config) { c.StateImportFile = "state.rlp.gz" },
+			false,
+		},
+		{
+			"import with state sync",
+			func(c *Config) {
+				c.StateImportFile = "state.rlp.gz"
+				c.StateSyncEnabled = true
+			},
+			true,
+		},
+		{
+			"import with path scheme",
+			func(c *Config) {
+				c.StateImportFile = "state.rlp.gz"
+				c.StateScheme = rawdb.PathScheme
+			},
+			true,
+		},
+		{
+			"chain import",
+			func(c *Config) { c.ChainImportFile = "chain.rlp.gz" },
+			false,
+		},
+		{
+			"chain import with state sync",
+			func(c *Config) {
+				c.ChainImportFile = "chain.rlp.gz"
+				c.StateSyncEnabled = true
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHistoryRetention(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"retention covering state sync summaries",
+			func(c *Config) { c.HistoryRetention = c.StateSyncCommitInterval + parentsToGet },
+			false,
+		},
+		{
+			"retention shorter than state sync summaries",
+			func(c *Config) { c.HistoryRetention = c.StateSyncCommitInterval },
+			true,
+		},
+		{
+			"retention with ancient store",
+			func(c *Config) {
+				c.HistoryRetention = c.StateSyncCommitInterval + parentsToGet
+				c.AncientStore = true
+				c.AncientStoreDirectory = "ancient"
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateKeystoreUnlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"unlock accounts",
+			func(c *Config) {
+				c.KeystoreUnlockAccounts = []common.Address{{1}}
+				c.KeystorePasswordFile = "passwords"
+				c.KeystoreInsecureUnlockAllowed = true
+			},
+			false,
+		},
+		{
+			"unlock accounts without password file",
+			func(c *Config) {
+				c.KeystoreUnlockAccounts = []common.Address{{1}}
+				c.KeystoreInsecureUnlockAllowed = true
+			},
+			true,
+		},
+		{
+			"unlock accounts without insecure unlock",
+			func(c *Config) {
+				c.KeystoreUnlockAccounts = []common.Address{{1}}
+				c.KeystorePasswordFile = "passwords"
+			},
+			true,
+		},
+		{
+			"unlock accounts of external signer",
+			func(c *Config) {
+				c.KeystoreUnlockAccounts = []common.Address{{1}}
+				c.KeystorePasswordFile = "passwords"
+				c.KeystoreInsecureUnlockAllowed = true
+				c.KeystoreExternalSigner = "http://localhost:8550"
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateFaucet(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {
+				c.FaucetEnabled = true
+				c.FaucetKeyFile = "faucet.key"
+			},
+			false,
+		},
+		{
+			"mint with captcha",
+			func(c *Config) {
+				c.FaucetEnabled = true
+				c.FaucetKeyFile = "faucet.key"
+				c.FaucetMode = "mint"
+				c.FaucetCaptchaVerifyURL = "https://hcaptcha.com/siteverify"
+				c.FaucetCaptchaSecret = "secret"
+			},
+			false,
+		},
+		{
+			"missing key file",
+			func(c *Config) {
+				c.FaucetEnabled = true
+			},
+			true,
+		},
+		{
+			"unknown mode",
+			func(c *Config) {
+				c.FaucetEnabled = true
+				c.FaucetKeyFile = "faucet.key"
+				c.FaucetMode = "airdrop"
+			},
+			true,
+		},
+		{
+			"zero amount",
+			func(c *Config) {
+				c.FaucetEnabled = true
+				c.FaucetKeyFile = "faucet.key"
+				c.FaucetAmount = new(math.HexOrDecimal256)
+			},
+			true,
+		},
+		{
+			"captcha without secret",
+			func(c *Config) {
+				c.FaucetEnabled = true
+				c.FaucetKeyFile = "faucet.key"
+				c.FaucetCaptchaVerifyURL = "https://hcaptcha.com/siteverify"
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCacheProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		givenJSON   []byte
+		expected    cacheProfile
+		expectedErr bool
+	}{
+		{
+			"no profile",
+			[]byte(`{"trie-dirty-cache": 512}`),
+			cacheProfile{TrieCleanCache: defaultTrieCleanCache, TrieDirtyCache: 512, SnapshotCache: defaultSnapshotCache, TriePrefetcherParallelism: defaultTriePrefetcherParallelism},
+			false,
+		},
+		{
+			"low memory profile",
+			[]byte(`{"cache-profile": "low-memory"}`),
+			cacheProfiles[lowMemoryCacheProfile],
+			false,
+		},
+		{
+			"rpc profile with explicit settings",
+			[]byte(`{"trie-clean-cache": 1024, "cache-profile": "rpc", "trie-prefetcher-parallelism": 32}`),
+			cacheProfile{TrieCleanCache: 1024, TrieDirtyCache: 1024, SnapshotCache: 4096, TriePrefetcherParallelism: 32},
+			false,
+		},
+		{
+			"unknown profile",
+			[]byte(`{"cache-profile": "archive"}`),
+			cacheProfile{TrieCleanCache: defaultTrieCleanCache, TrieDirtyCache: defaultTrieDirtyCache, SnapshotCache: defaultSnapshotCache, TriePrefetcherParallelism: defaultTriePrefetcherParallelism},
+			true,
+		},
+		{
+			"no trie prefetcher parallelism",
+			[]byte(`{"trie-prefetcher-parallelism": 0}`),
+			cacheProfile{TrieCleanCache: defaultTrieCleanCache, TrieDirtyCache: defaultTrieDirtyCache, SnapshotCache: defaultSnapshotCache},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			assert.NoError(t, c.unmarshal(tt.givenJSON))
+			assert.Equal(t, tt.expected, cacheProfile{
+				TrieCleanCache:            c.TrieCleanCache,
+				TrieDirtyCache:            c.TrieDirtyCache,
+				SnapshotCache:             c.SnapshotCache,
+				TriePrefetcherParallelism: c.TriePrefetcherParallelism,
+			})
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateBlockBuilding(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"max duration and fifo ordering",
+			func(c *Config) {
+				c.BlockBuildMaxDuration = Duration{500 * time.Millisecond}
+				c.BlockBuildTxOrdering = miner.FIFOTxOrdering
+			},
+			false,
+		},
+		{
+			"round robin ordering",
+			func(c *Config) { c.BlockBuildTxOrdering = miner.RoundRobinTxOrdering },
+			false,
+		},
+		{
+			"negative max duration",
+			func(c *Config) { c.BlockBuildMaxDuration = Duration{-time.Second} },
+			true,
+		},
+		{
+			"unknown ordering",
+			func(c *Config) { c.BlockBuildTxOrdering = "random" },
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateBatchLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"limits",
+			func(c *Config) {
+				c.BatchRequestLimit = 1000
+				c.BatchResponseMaxSize = 25 * 1000 * 1000
+			},
+			false,
+		},
+		{
+			"negative request limit",
+			func(c *Config) { c.BatchRequestLimit = -1 },
+			true,
+		},
+		{
+			"negative response max size",
+			func(c *Config) { c.BatchResponseMaxSize = -1 },
+			true,
+		},
+		{
+			"transport options",
+			func(c *Config) {
+				c.HTTPBodyLimit = 64 * 1024 * 1024
+				c.RPCCompression = []string{"gzip", "deflate"}
+				c.RPCHTTP2Enabled = true
+			},
+			false,
+		},
+		{
+			"negative http body limit",
+			func(c *Config) { c.HTTPBodyLimit = -1 },
+			true,
+		},
+		{
+			"unsupported compression",
+			func(c *Config) { c.RPCCompression = []string{"br"} },
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAlerting(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"webhook",
+			func(c *Config) {
+				c.AlertWebhookURL = "https://events.example.com/v2/enqueue"
+				c.AlertWebhookHeaders = map[string]string{"Authorization": "Token secret"}
+			},
+			false,
+		},
+		{
+			"invalid webhook url",
+			func(c *Config) { c.AlertWebhookURL = "events.example.com" },
+			true,
+		},
+		{
+			"negative webhook timeout",
+			func(c *Config) { c.AlertWebhookTimeout = Duration{-time.Second} },
+			true,
+		},
+		{
+			"reorg alerts disabled",
+			func(c *Config) { c.AlertReorgDepth = 0 },
+			false,
+		},
+		{
+			"negative reorg depth",
+			func(c *Config) { c.AlertReorgDepth = -1 },
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateRPCRateLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr bool
+	}{
+		{
+			"default",
+			func(c *Config) {},
+			false,
+		},
+		{
+			"budgets",
+			func(c *Config) {
+				c.RPCRateLimitBudget = 1_000_000
+				c.RPCRateLimitKeyHeader = "X-Api-Key"
+				c.RPCRateLimitKeyBudgets = map[string]uint64{"key": 10_000_000}
+			},
+			false,
+		},
+		{
+			"no burst window",
+			func(c *Config) {
+				c.RPCRateLimitBudget = 1_000_000
+				c.RPCRateLimitBurstWindow = Duration{}
+			},
+			true,
+		},
+		{
+			"key budgets without header",
+			func(c *Config) { c.RPCRateLimitKeyBudgets = map[string]uint64{"key": 10_000_000} },
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			c.SetDefaults()
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRPCCostLimits(t *testing.T) {
+	var c Config
+	c.SetDefaults()
+	assert.NoError(t, c.unmarshal([]byte(`{"rpc-method-costs": {"eth_getLogs": 5000, "eth_chainId": 1}, "rpc-rate-limit-budget": 2000000}`)))
+
+	limits := c.RPCCostLimits()
+	assert.EqualValues(t, 5000, limits.Costs["eth_getLogs"])
+	assert.EqualValues(t, 1, limits.Costs["eth_chainId"])
+	assert.Equal(t, defaultRPCMethodCosts["debug_*"], limits.Costs["debug_*"])
+	assert.EqualValues(t, defaultRPCDefaultMethodCost, limits.DefaultCost)
+	assert.EqualValues(t, 2_000_000, limits.Budget)
+	assert.Equal(t, defaultRPCRateLimitBurstWindow, limits.BurstWindow)
+	// The defaults are not modified
+	assert.EqualValues(t, 100_000, defaultRPCMethodCosts["eth_getLogs"])
+}
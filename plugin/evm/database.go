@@ -4,27 +4,44 @@
 package evm
 
 import (
+	"errors"
+	"time"
+
 	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ava-labs/avalanchego/database"
 )
 
-var _ ethdb.Database = &Database{}
+// pebbleDatabaseType is the database type of a chain database backed by pebble.
+const pebbleDatabaseType = "pebbledb"
+
+var (
+	_ ethdb.KeyValueStore = &Database{}
+
+	// pebbleMigrationKey marks that the chain data has been migrated out of the
+	// avalanchego database into a pebble database. It is written to both of the
+	// databases once all the chain data has been copied.
+	pebbleMigrationKey = []byte("PebbleDBMigration")
 
-// Database implements ethdb.Database
+	errChainDataMigrated = errors.New("chain data has been migrated to a pebble database, database-type must be set to " + pebbleDatabaseType)
+)
+
+// Database implements ethdb.KeyValueStore
 type Database struct{ database.Database }
 
-// Stat implements ethdb.Database
+// Stat implements ethdb.KeyValueStore
 func (db Database) Stat(string) (string, error) { return "", database.ErrNotFound }
 
-// NewBatch implements ethdb.Database
+// NewBatch implements ethdb.KeyValueStore
 func (db Database) NewBatch() ethdb.Batch { return Batch{db.Database.NewBatch()} }
 
-// NewBatchWithSize implements ethdb.Database
+// NewBatchWithSize implements ethdb.KeyValueStore
 // TODO: propagate size through avalanchego Database interface
 func (db Database) NewBatchWithSize(size int) ethdb.Batch { return Batch{db.Database.NewBatch()} }
 
-// NewIterator implements ethdb.Database
+// NewIterator implements ethdb.KeyValueStore
 //
 // Note: This method assumes that the prefix is NOT part of the start, so there's
 // no need for the caller to prepend the prefix to the start.
@@ -40,7 +57,7 @@ func (db Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
 	return db.Database.NewIteratorWithStartAndPrefix(start, prefix)
 }
 
-// NewIteratorWithStart implements ethdb.Database
+// NewIteratorWithStart implements ethdb.KeyValueStore
 func (db Database) NewIteratorWithStart(start []byte) ethdb.Iterator {
 	return db.Database.NewIteratorWithStart(start)
 }
@@ -53,3 +70,105 @@ func (batch Batch) ValueSize() int { return batch.Batch.Size() }
 
 // Replay implements ethdb.Batch
 func (batch Batch) Replay(w ethdb.KeyValueWriter) error { return batch.Batch.Replay(w) }
+
+// migrateChainDatabase moves the chain data held in [src] into the pebble
+// database [dst]. The chain data is first copied into [dst], which is restarted
+// from scratch if the node stops before the copy completes. Once the copy is
+// marked as complete in both of the databases, the chain data is deleted from
+// [src], so that the node refuses to start on [src] again.
+func migrateChainDatabase(src, dst ethdb.KeyValueStore) error {
+	migrated, err := dst.Has(pebbleMigrationKey)
+	if err != nil {
+		return err
+	}
+	if !migrated {
+		if err := copyChainDatabase(src, dst); err != nil {
+			return err
+		}
+		if err := dst.Put(pebbleMigrationKey, []byte{}); err != nil {
+			return err
+		}
+	}
+	if err := src.Put(pebbleMigrationKey, []byte{}); err != nil {
+		return err
+	}
+	return clearChainDatabase(src)
+}
+
+// copyChainDatabase copies all the keys of [src] into [dst].
+func copyChainDatabase(src, dst ethdb.KeyValueStore) error {
+	var (
+		it     = src.NewIterator(nil, nil)
+		batch  = dst.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		copied uint64
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if string(it.Key()) == string(pebbleMigrationKey) {
+			continue
+		}
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+		copied++
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying chain data into pebble database", "keys", copied, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if copied > 0 {
+		log.Info("Copied chain data into pebble database", "keys", copied, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// clearChainDatabase deletes all the keys of [db] but [pebbleMigrationKey].
+func clearChainDatabase(db ethdb.KeyValueStore) error {
+	var (
+		it      = db.NewIterator(nil, nil)
+		batch   = db.NewBatch()
+		deleted uint64
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if string(it.Key()) == string(pebbleMigrationKey) {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		deleted++
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Info("Deleted migrated chain data from avalanchego database", "keys", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateChainDatabase(t *testing.T) {
+	assert := assert.New(t)
+
+	src := Database{memdb.New()}
+	dst := memorydb.New()
+	for i := 0; i < 1000; i++ {
+		assert.NoError(src.Put([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	assert.NoError(migrateChainDatabase(src, dst))
+
+	for i := 0; i < 1000; i++ {
+		value, err := dst.Get([]byte(fmt.Sprintf("key-%d", i)))
+		assert.NoError(err)
+		assert.Equal([]byte(fmt.Sprintf("value-%d", i)), value)
+
+		has, err := src.Has([]byte(fmt.Sprintf("key-%d", i)))
+		assert.NoError(err)
+		assert.False(has)
+	}
+	// Both databases are marked as migrated
+	for _, db := range []interface{ Has([]byte) (bool, error) }{src, dst} {
+		migrated, err := db.Has(pebbleMigrationKey)
+		assert.NoError(err)
+		assert.True(migrated)
+	}
+
+	// Migrating again does not copy the keys written to the source afterwards,
+	// but deletes them
+	assert.NoError(src.Put([]byte("stale"), []byte("value")))
+	assert.NoError(migrateChainDatabase(src, dst))
+	has, err := dst.Has([]byte("stale"))
+	assert.NoError(err)
+	assert.False(has)
+	has, err = src.Has([]byte("stale"))
+	assert.NoError(err)
+	assert.False(has)
+}
@@ -0,0 +1,46 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/plugin/evm/faucet"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AddFaucetHook adds [hook] to the hooks checking the requests of the faucet, in addition
+// to the captcha and allow list hooks enabled in the config.
+func (vm *VM) AddFaucetHook(hook faucet.Hook) {
+	if vm.faucet == nil {
+		vm.faucetHooks = append(vm.faucetHooks, hook)
+		return
+	}
+	vm.faucet.AddHook(hook)
+}
+
+// initializeFaucet creates the faucet enabled in the config, checking the requests with the
+// hooks enabled in the config and those added with AddFaucetHook.
+func (vm *VM) initializeFaucet() error {
+	key, err := crypto.LoadECDSA(vm.config.FaucetKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load faucet key: %w", err)
+	}
+	hooks := vm.faucetHooks
+	if len(vm.config.FaucetAllowlist) > 0 {
+		hooks = append(hooks, faucet.NewAllowlistHook(vm.config.FaucetAllowlist))
+	}
+	if len(vm.config.FaucetCaptchaVerifyURL) > 0 {
+		hooks = append(hooks, faucet.NewCaptchaHook(vm.config.FaucetCaptchaVerifyURL, vm.config.FaucetCaptchaSecret, vm.config.FaucetCaptchaTimeout.Duration))
+	}
+	vm.faucet, err = faucet.New(vm.eth.APIBackend, faucet.Config{
+		Key:           key,
+		Mode:          faucet.Mode(vm.config.FaucetMode),
+		Amount:        (*big.Int)(vm.config.FaucetAmount),
+		RateLimit:     vm.config.FaucetRateLimit.Duration,
+		MaxFundsPerIP: vm.config.FaucetMaxFundsPerIP,
+	}, hooks...)
+	return err
+}
@@ -0,0 +1,240 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package faucet funds the addresses requesting test tokens over HTTP, either by transferring
+// from a key or by minting through the NativeMinter precompile, subject to pluggable hooks
+// (such as a captcha or an allow list) and to rate limits per address and per IP.
+package faucet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Mode is the way the faucet funds the requested addresses.
+type Mode string
+
+const (
+	// Transfer sends the funds from the balance of the faucet key.
+	Transfer Mode = "transfer"
+	// Mint mints the funds through the NativeMinter precompile, on which the faucet key must be enabled.
+	Mint Mode = "mint"
+)
+
+// mintGas is the gas limit of the mint transactions, covering the intrinsic gas, the calldata
+// and the allow list read of the NativeMinter precompile.
+const mintGas = 100_000
+
+var (
+	// ErrRateLimited is returned when the address was funded less than the rate limit ago.
+	ErrRateLimited = errors.New("address was funded too recently")
+	// ErrIPRateLimited is returned when the IP of the request had as many requests funded as allowed
+	// within the rate limit.
+	ErrIPRateLimited = errors.New("too many addresses funded from this IP")
+	// ErrRejected is returned when a hook rejects the request.
+	ErrRejected = errors.New("request rejected")
+
+	fundedCounter   = metrics.NewRegisteredCounter("faucet/funded", nil)
+	rejectedCounter = metrics.NewRegisteredCounter("faucet/rejected", nil)
+)
+
+// Backend is the chain the faucet sends its transactions to.
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
+	EstimateBaseFee(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTx(ctx context.Context, tx *types.Transaction) error
+}
+
+// Config is the configuration of a faucet.
+type Config struct {
+	// Key signs the transactions of the faucet.
+	Key  *ecdsa.PrivateKey
+	Mode Mode
+	// Amount is the amount of wei given to each request.
+	Amount *big.Int
+	// RateLimit is the time after which an address can be funded again (no limit if 0).
+	RateLimit time.Duration
+	// MaxFundsPerIP is the number of requests from the same IP that can be funded within RateLimit,
+	// so that a requester cannot drain the faucet by requesting funds for many addresses (no limit if 0).
+	MaxFundsPerIP uint64
+}
+
+// Request is a request for funds.
+type Request struct {
+	Address common.Address `json:"address"`
+	// Captcha is the response of the captcha solved by the requester, if the faucet requires one.
+	Captcha string `json:"captcha,omitempty"`
+	// RemoteIP is the IP the request was received from.
+	RemoteIP string `json:"-"`
+}
+
+// Hook checks the requests before they are funded, rejecting them with an error.
+type Hook interface {
+	Check(ctx context.Context, req *Request) error
+}
+
+// Faucet funds the requested addresses.
+type Faucet struct {
+	backend Backend
+	config  Config
+	address common.Address
+	signer  types.Signer
+	clock   mockable.Clock
+
+	lock       sync.Mutex
+	hooks      []Hook
+	lastFunded map[common.Address]time.Time
+	// fundedByIP contains the times the requests from each IP were funded within the rate limit, in order.
+	fundedByIP map[string][]time.Time
+}
+
+// New returns a faucet sending its transactions to [backend], checking the requests with [hooks].
+func New(backend Backend, config Config, hooks ...Hook) (*Faucet, error) {
+	if config.Key == nil {
+		return nil, errors.New("missing faucet key")
+	}
+	if config.Mode != Transfer && config.Mode != Mint {
+		return nil, fmt.Errorf("unknown faucet mode %q", config.Mode)
+	}
+	if config.Amount == nil || config.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("faucet amount (%v) must be positive", config.Amount)
+	}
+	return &Faucet{
+		backend:    backend,
+		config:     config,
+		address:    crypto.PubkeyToAddress(config.Key.PublicKey),
+		signer:     types.LatestSignerForChainID(backend.ChainConfig().ChainID),
+		hooks:      hooks,
+		lastFunded: make(map[common.Address]time.Time),
+		fundedByIP: make(map[string][]time.Time),
+	}, nil
+}
+
+// Address returns the address of the faucet key.
+func (f *Faucet) Address() common.Address {
+	return f.address
+}
+
+// AddHook adds [hook] to the hooks checking the requests.
+func (f *Faucet) AddHook(hook Hook) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.hooks = append(f.hooks, hook)
+}
+
+// Fund checks [req] with the hooks and the rate limits, then sends the transaction funding its
+// address and returns its hash. The transaction is issued to the mempool, not waited on.
+func (f *Faucet) Fund(ctx context.Context, req *Request) (common.Hash, error) {
+	f.lock.Lock()
+	hooks := f.hooks
+	f.lock.Unlock()
+	for _, hook := range hooks {
+		if err := hook.Check(ctx, req); err != nil {
+			rejectedCounter.Inc(1)
+			return common.Hash{}, fmt.Errorf("%w: %s", ErrRejected, err)
+		}
+	}
+
+	// The lock is held until the transaction is issued, so that the nonces are assigned in order
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.clock.Time()
+	if f.config.RateLimit > 0 {
+		for address, funded := range f.lastFunded {
+			if now.Sub(funded) >= f.config.RateLimit {
+				delete(f.lastFunded, address)
+			}
+		}
+		for ip, funded := range f.fundedByIP {
+			n := 0
+			for n < len(funded) && now.Sub(funded[n]) >= f.config.RateLimit {
+				n++
+			}
+			if n == len(funded) {
+				delete(f.fundedByIP, ip)
+			} else {
+				f.fundedByIP[ip] = funded[n:]
+			}
+		}
+		if funded, ok := f.lastFunded[req.Address]; ok {
+			return common.Hash{}, fmt.Errorf("%w, retry in %s", ErrRateLimited, f.config.RateLimit-now.Sub(funded))
+		}
+		if funded := f.fundedByIP[req.RemoteIP]; f.config.MaxFundsPerIP > 0 && uint64(len(funded)) >= f.config.MaxFundsPerIP {
+			return common.Hash{}, fmt.Errorf("%w, retry in %s", ErrIPRateLimited, f.config.RateLimit-now.Sub(funded[0]))
+		}
+	}
+
+	tx, err := f.newTx(ctx, req.Address)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := f.backend.SendTx(ctx, tx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to issue faucet transaction: %w", err)
+	}
+	if f.config.RateLimit > 0 {
+		f.lastFunded[req.Address] = now
+		if f.config.MaxFundsPerIP > 0 && len(req.RemoteIP) > 0 {
+			f.fundedByIP[req.RemoteIP] = append(f.fundedByIP[req.RemoteIP], now)
+		}
+	}
+	fundedCounter.Inc(1)
+	log.Info("Faucet funded address", "address", req.Address, "amount", f.config.Amount, "mode", f.config.Mode, "tx", tx.Hash())
+	return tx.Hash(), nil
+}
+
+// newTx returns the signed transaction funding [to].
+func (f *Faucet) newTx(ctx context.Context, to common.Address) (*types.Transaction, error) {
+	nonce, err := f.backend.GetPoolNonce(ctx, f.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get faucet nonce: %w", err)
+	}
+	baseFee, err := f.backend.EstimateBaseFee(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate base fee: %w", err)
+	}
+	tip, err := f.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	txData := &types.DynamicFeeTx{
+		ChainID:   f.backend.ChainConfig().ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		// Leave room for the base fee to double before the transaction is included
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(baseFee, common.Big2), tip),
+	}
+	switch f.config.Mode {
+	case Transfer:
+		txData.To = &to
+		txData.Value = f.config.Amount
+		txData.Gas = params.TxGas
+	case Mint:
+		input, err := precompile.PackMintInput(to, f.config.Amount)
+		if err != nil {
+			return nil, err
+		}
+		minter := precompile.ContractNativeMinterAddress
+		txData.To = &minter
+		txData.Data = input
+		txData.Gas = mintGas
+	}
+	return types.SignNewTx(f.config.Key, f.signer, txData)
+}
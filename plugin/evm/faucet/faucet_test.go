@@ -0,0 +1,232 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package faucet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// testBackend records the transactions sent by the faucet.
+type testBackend struct {
+	txs     []*types.Transaction
+	sendErr error
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+
+func (b *testBackend) GetPoolNonce(context.Context, common.Address) (uint64, error) {
+	return uint64(len(b.txs)), nil
+}
+
+func (b *testBackend) EstimateBaseFee(context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei * 25), nil
+}
+
+func (b *testBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei), nil
+}
+
+func (b *testBackend) SendTx(_ context.Context, tx *types.Transaction) error {
+	if b.sendErr != nil {
+		return b.sendErr
+	}
+	b.txs = append(b.txs, tx)
+	return nil
+}
+
+type hookFunc func(context.Context, *Request) error
+
+func (f hookFunc) Check(ctx context.Context, req *Request) error { return f(ctx, req) }
+
+func newTestFaucet(t *testing.T, mode Mode, rateLimit time.Duration, hooks ...Hook) (*Faucet, *testBackend) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	backend := &testBackend{}
+	f, err := New(backend, Config{Key: key, Mode: mode, Amount: big.NewInt(params.Ether), RateLimit: rateLimit}, hooks...)
+	require.NoError(t, err)
+	return f, backend
+}
+
+func TestFundTransfer(t *testing.T) {
+	f, backend := newTestFaucet(t, Transfer, 0)
+	to := common.Address{0xaa}
+
+	txHash, err := f.Fund(context.Background(), &Request{Address: to})
+	require.NoError(t, err)
+	require.Len(t, backend.txs, 1)
+	tx := backend.txs[0]
+	require.Equal(t, txHash, tx.Hash())
+	require.Equal(t, to, *tx.To())
+	require.Equal(t, big.NewInt(params.Ether), tx.Value())
+	require.Equal(t, big.NewInt(params.GWei*51), tx.GasFeeCap())
+	sender, err := types.Sender(types.LatestSignerForChainID(params.TestChainConfig.ChainID), tx)
+	require.NoError(t, err)
+	require.Equal(t, f.Address(), sender)
+
+	// Without a rate limit, the address can be funded again right away
+	_, err = f.Fund(context.Background(), &Request{Address: to})
+	require.NoError(t, err)
+	require.Len(t, backend.txs, 2)
+	require.Equal(t, uint64(1), backend.txs[1].Nonce())
+}
+
+func TestFundMint(t *testing.T) {
+	f, backend := newTestFaucet(t, Mint, 0)
+	to := common.Address{0xaa}
+
+	_, err := f.Fund(context.Background(), &Request{Address: to})
+	require.NoError(t, err)
+	require.Len(t, backend.txs, 1)
+	tx := backend.txs[0]
+	require.Equal(t, precompile.ContractNativeMinterAddress, *tx.To())
+	require.Zero(t, tx.Value().Sign())
+	input, err := precompile.PackMintInput(to, big.NewInt(params.Ether))
+	require.NoError(t, err)
+	require.Equal(t, input, tx.Data())
+}
+
+func TestFundRateLimit(t *testing.T) {
+	f, backend := newTestFaucet(t, Transfer, time.Hour)
+	now := time.Unix(1_000_000, 0)
+	f.clock.Set(now)
+
+	_, err := f.Fund(context.Background(), &Request{Address: common.Address{0xaa}})
+	require.NoError(t, err)
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xaa}})
+	require.ErrorIs(t, err, ErrRateLimited)
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xbb}})
+	require.NoError(t, err)
+
+	// The address can be funded again once the rate limit elapsed
+	f.clock.Set(now.Add(time.Hour))
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xaa}})
+	require.NoError(t, err)
+	require.Len(t, backend.txs, 3)
+
+	// An address is not rate limited by a request that failed to be issued
+	backend.sendErr = errors.New("mempool full")
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xcc}})
+	require.ErrorContains(t, err, "mempool full")
+	backend.sendErr = nil
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xcc}})
+	require.NoError(t, err)
+}
+
+func TestFundIPRateLimit(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	backend := &testBackend{}
+	f, err := New(backend, Config{Key: key, Mode: Transfer, Amount: big.NewInt(params.Ether), RateLimit: time.Hour, MaxFundsPerIP: 3})
+	require.NoError(t, err)
+	now := time.Unix(1_000_000, 0)
+	f.clock.Set(now)
+
+	// Many addresses requested from the same IP are throttled once the IP had MaxFundsPerIP requests funded
+	for i := byte(0); i < 3; i++ {
+		f.clock.Set(now.Add(time.Duration(i) * time.Minute))
+		_, err := f.Fund(context.Background(), &Request{Address: common.Address{i}, RemoteIP: "10.0.0.1"})
+		require.NoError(t, err)
+	}
+	for i := byte(3); i < 10; i++ {
+		_, err := f.Fund(context.Background(), &Request{Address: common.Address{i}, RemoteIP: "10.0.0.1"})
+		require.ErrorIs(t, err, ErrIPRateLimited)
+	}
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{3}, RemoteIP: "10.0.0.2"})
+	require.NoError(t, err)
+	require.Len(t, backend.txs, 4)
+
+	// The IP can have another request funded once its oldest request is out of the rate limit
+	f.clock.Set(now.Add(time.Hour))
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{4}, RemoteIP: "10.0.0.1"})
+	require.NoError(t, err)
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{5}, RemoteIP: "10.0.0.1"})
+	require.ErrorIs(t, err, ErrIPRateLimited)
+}
+
+func TestFundHooks(t *testing.T) {
+	f, backend := newTestFaucet(t, Transfer, 0, NewAllowlistHook([]common.Address{{0xaa}, {0xbb}}))
+
+	_, err := f.Fund(context.Background(), &Request{Address: common.Address{0xaa}})
+	require.NoError(t, err)
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xcc}})
+	require.ErrorIs(t, err, ErrRejected)
+
+	f.AddHook(hookFunc(func(_ context.Context, req *Request) error {
+		if req.Address == (common.Address{0xbb}) {
+			return errors.New("blocked")
+		}
+		return nil
+	}))
+	_, err = f.Fund(context.Background(), &Request{Address: common.Address{0xbb}})
+	require.ErrorIs(t, err, ErrRejected)
+	require.ErrorContains(t, err, "blocked")
+	require.Len(t, backend.txs, 1)
+}
+
+func TestCaptchaHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "secret", r.PostForm.Get("secret"))
+		require.Equal(t, "127.0.0.1", r.PostForm.Get("remoteip"))
+		_ = json.NewEncoder(w).Encode(map[string]bool{"success": r.PostForm.Get("response") == "solved"})
+	}))
+	defer server.Close()
+	hook := NewCaptchaHook(server.URL, "secret", time.Second)
+
+	require.NoError(t, hook.Check(context.Background(), &Request{Captcha: "solved", RemoteIP: "127.0.0.1"}))
+	require.ErrorIs(t, hook.Check(context.Background(), &Request{Captcha: "wrong", RemoteIP: "127.0.0.1"}), errInvalidCaptcha)
+	require.ErrorIs(t, hook.Check(context.Background(), &Request{RemoteIP: "127.0.0.1"}), errMissingCaptcha)
+}
+
+func TestHandler(t *testing.T) {
+	f, backend := newTestFaucet(t, Transfer, time.Hour)
+	server := httptest.NewServer(f.Handler())
+	defer server.Close()
+
+	post := func(body string) (int, *Response) {
+		resp, err := http.Post(server.URL, "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		response := new(Response)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+		return resp.StatusCode, response
+	}
+
+	status, response := post(`{"address": "0xaa00000000000000000000000000000000000000"}`)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, backend.txs, 1)
+	require.Equal(t, backend.txs[0].Hash(), *response.TxHash)
+
+	status, response = post(`{"address": "0xaa00000000000000000000000000000000000000"}`)
+	require.Equal(t, http.StatusTooManyRequests, status)
+	require.Contains(t, response.Error, ErrRateLimited.Error())
+
+	status, _ = post(`{"address": 1}`)
+	require.Equal(t, http.StatusBadRequest, status)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	info := new(Info)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(info))
+	require.Equal(t, f.Address(), info.Address)
+	require.Equal(t, Transfer, info.Mode)
+	require.Equal(t, uint64(time.Hour.Seconds()), info.RateLimit)
+}
@@ -0,0 +1,86 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package faucet
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxRequestSize is the maximum size of the body of a request for funds.
+const maxRequestSize = 4 * 1024
+
+// Info describes the faucet, as answered to the GET requests.
+type Info struct {
+	Address common.Address        `json:"address"`
+	Mode    Mode                  `json:"mode"`
+	Amount  *math.HexOrDecimal256 `json:"amount"`
+	// RateLimit is the number of seconds after which an address can be funded again.
+	RateLimit uint64 `json:"rateLimit"`
+	// MaxFundsPerIP is the number of requests from the same IP funded within the rate limit, if limited.
+	MaxFundsPerIP uint64 `json:"maxFundsPerIP,omitempty"`
+}
+
+// Response is the answer to a request for funds.
+type Response struct {
+	TxHash *common.Hash `json:"txHash,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// Handler returns the HTTP handler of the faucet. GET requests are answered with the [Info] of
+// the faucet, and POST requests with a JSON [Request] in their body are funded, answering with
+// the hash of the funding transaction or with the status code 403 if a hook rejects the request
+// and 429 if the address was funded too recently.
+func (f *Faucet) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, &Info{
+				Address:       f.address,
+				Mode:          f.config.Mode,
+				Amount:        (*math.HexOrDecimal256)(f.config.Amount),
+				RateLimit:     uint64(f.config.RateLimit.Seconds()),
+				MaxFundsPerIP: f.config.MaxFundsPerIP,
+			})
+		case http.MethodPost:
+			req := new(Request)
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(req); err != nil {
+				writeJSON(w, http.StatusBadRequest, &Response{Error: "invalid request: " + err.Error()})
+				return
+			}
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				req.RemoteIP = host
+			}
+			txHash, err := f.Fund(r.Context(), req)
+			switch {
+			case errors.Is(err, ErrRejected):
+				writeJSON(w, http.StatusForbidden, &Response{Error: err.Error()})
+			case errors.Is(err, ErrRateLimited), errors.Is(err, ErrIPRateLimited):
+				writeJSON(w, http.StatusTooManyRequests, &Response{Error: err.Error()})
+			case err != nil:
+				log.Warn("Faucet failed to fund address", "address", req.Address, "err", err)
+				writeJSON(w, http.StatusInternalServerError, &Response{Error: err.Error()})
+			default:
+				writeJSON(w, http.StatusOK, &Response{TxHash: &txHash})
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, &Response{Error: "method not allowed"})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debug("failed to write faucet response", "err", err)
+	}
+}
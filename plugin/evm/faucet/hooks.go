@@ -0,0 +1,100 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	_ Hook = (*AllowlistHook)(nil)
+	_ Hook = (*CaptchaHook)(nil)
+
+	errNotAllowed     = errors.New("address is not allowed")
+	errMissingCaptcha = errors.New("missing captcha")
+	errInvalidCaptcha = errors.New("invalid captcha")
+)
+
+// AllowlistHook only accepts the requests funding one of its addresses.
+type AllowlistHook struct {
+	addresses map[common.Address]struct{}
+}
+
+// NewAllowlistHook returns a hook accepting the requests funding one of [addresses].
+func NewAllowlistHook(addresses []common.Address) *AllowlistHook {
+	h := &AllowlistHook{addresses: make(map[common.Address]struct{}, len(addresses))}
+	for _, address := range addresses {
+		h.addresses[address] = struct{}{}
+	}
+	return h
+}
+
+// Check implements Hook
+func (h *AllowlistHook) Check(_ context.Context, req *Request) error {
+	if _, ok := h.addresses[req.Address]; !ok {
+		return errNotAllowed
+	}
+	return nil
+}
+
+// CaptchaHook only accepts the requests with a captcha response verified by the siteverify
+// endpoint of the captcha provider, following the protocol shared by reCAPTCHA and hCaptcha.
+type CaptchaHook struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// NewCaptchaHook returns a hook verifying the captcha responses at [verifyURL] with [secret],
+// failing the verifications that take longer than [timeout] (no timeout if 0).
+func NewCaptchaHook(verifyURL string, secret string, timeout time.Duration) *CaptchaHook {
+	return &CaptchaHook{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+// Check implements Hook
+func (h *CaptchaHook) Check(ctx context.Context, req *Request) error {
+	if len(req.Captcha) == 0 {
+		return errMissingCaptcha
+	}
+	form := url.Values{"secret": {h.secret}, "response": {req.Captcha}}
+	if len(req.RemoteIP) > 0 {
+		form.Set("remoteip", req.RemoteIP)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to verify captcha: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("captcha verification responded with status %s", resp.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode captcha verification: %w", err)
+	}
+	if !result.Success {
+		return errInvalidCaptcha
+	}
+	return nil
+}
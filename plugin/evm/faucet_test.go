@@ -0,0 +1,55 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/plugin/evm/faucet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaucetEndpoint(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "faucet.key")
+	require.NoError(t, crypto.SaveECDSA(keyFile, testKeys[0]))
+	configJSON := fmt.Sprintf(`{"faucet-enabled": true, "faucet-key-file": %q, "faucet-allowlist": [%q]}`, keyFile, testEthAddrs[1])
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, configJSON, "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	handlers, err := vm.CreateHandlers(context.Background())
+	require.NoError(t, err)
+	handler := handlers[faucetEndpoint].Handler
+	require.Equal(t, testEthAddrs[0], vm.faucet.Address())
+
+	post := func(address common.Address) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"address": %q}`, address)
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, faucetEndpoint, strings.NewReader(body)))
+		return rec
+	}
+
+	// The funding transaction of an allowed address is issued to the mempool
+	rec := post(testEthAddrs[1])
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response faucet.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	tx := vm.txPool.Get(*response.TxHash)
+	require.NotNil(t, tx)
+	require.Equal(t, testEthAddrs[1], *tx.To())
+
+	// Other addresses are rejected by the allow list
+	rec = post(common.Address{0xaa})
+	require.Equal(t, http.StatusForbidden, rec.Code)
+}
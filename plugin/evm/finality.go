@@ -0,0 +1,351 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+const (
+	// maxRecentReorgs is the number of the last reorgs reported by the finality API.
+	maxRecentReorgs = 64
+	// acceptanceSampleSize is the number of the last accepted blocks the time to
+	// acceptance distribution is computed over.
+	acceptanceSampleSize = 1024
+
+	preferredFinalityEvent = "preferred"
+	acceptedFinalityEvent  = "accepted"
+	reorgFinalityEvent     = "reorg"
+)
+
+// ReorgInfo describes a change of the preferred chain to a block that is not a descendant
+// of the previous preference.
+type ReorgInfo struct {
+	Time         time.Time      `json:"time"`
+	Depth        int            `json:"depth"`
+	CommonBlock  common.Hash    `json:"commonBlock"`
+	CommonHeight hexutil.Uint64 `json:"commonHeight"`
+	OldHead      common.Hash    `json:"oldHead"`
+	NewHead      common.Hash    `json:"newHead"`
+	// Dropped are the hashes of the blocks dropped from the preferred chain, from the old head down.
+	Dropped []common.Hash `json:"dropped"`
+}
+
+// AcceptanceLatency is the distribution of the time between the verification of the last
+// accepted blocks and their acceptance, in milliseconds.
+type AcceptanceLatency struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	P99     float64 `json:"p99"`
+	Max     float64 `json:"max"`
+}
+
+// FinalityStatus is the height of the preferred chain relative to the accepted chain, and
+// the history of the reorgs and the acceptance of the blocks.
+type FinalityStatus struct {
+	AcceptedHeight  hexutil.Uint64 `json:"acceptedHeight"`
+	AcceptedHash    common.Hash    `json:"acceptedHash"`
+	PreferredHeight hexutil.Uint64 `json:"preferredHeight"`
+	PreferredHash   common.Hash    `json:"preferredHash"`
+	// Divergence is the number of preferred blocks that are not accepted yet.
+	Divergence       hexutil.Uint64    `json:"divergence"`
+	Reorgs           uint64            `json:"reorgs"`
+	TimeToAcceptance AcceptanceLatency `json:"timeToAcceptance"`
+}
+
+// FinalityEvent is notified to the subscribers of the finality API when the preferred
+// block changes ("preferred"), a block is accepted ("accepted") or the preferred chain
+// reorgs ("reorg").
+type FinalityEvent struct {
+	Type            string         `json:"type"`
+	Number          hexutil.Uint64 `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	AcceptedHeight  hexutil.Uint64 `json:"acceptedHeight"`
+	PreferredHeight hexutil.Uint64 `json:"preferredHeight"`
+	// TimeToAcceptance is set on the "accepted" events of the blocks verified by this node
+	// after bootstrapping, in milliseconds.
+	TimeToAcceptance *float64   `json:"timeToAcceptance,omitempty"`
+	Reorg            *ReorgInfo `json:"reorg,omitempty"`
+}
+
+// finalityTracker observes how the preferred chain diverges from the accepted chain: the
+// reorgs of the preferred chain, and the time the blocks take to be accepted once verified.
+type finalityTracker struct {
+	lock sync.Mutex
+	// [verified] holds the verification time of the blocks being decided, and
+	// [acceptance] the time to acceptance of the blocks accepted by consensus but not
+	// yet processed by the chain.
+	verified   map[common.Hash]time.Time
+	acceptance map[common.Hash]time.Duration
+	latencies  []time.Duration // the last [acceptanceSampleSize] times to acceptance
+	next       int
+	reorgs     []*ReorgInfo
+	reorgCount uint64
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+
+	acceptanceTimer metrics.Timer
+	divergence      metrics.Gauge
+}
+
+func newFinalityTracker() *finalityTracker {
+	return &finalityTracker{
+		verified:        make(map[common.Hash]time.Time),
+		acceptance:      make(map[common.Hash]time.Duration),
+		acceptanceTimer: metrics.GetOrRegisterTimer("chain/finality/acceptance", nil),
+		divergence:      metrics.GetOrRegisterGauge("chain/finality/divergence", nil),
+	}
+}
+
+// blockVerified records the verification of the block [hash] by consensus.
+func (t *finalityTracker) blockVerified(hash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.verified[hash]; !ok {
+		t.verified[hash] = time.Now()
+	}
+}
+
+// blockAccepted records the acceptance of the block [hash] by consensus.
+func (t *finalityTracker) blockAccepted(hash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	verified, ok := t.verified[hash]
+	if !ok {
+		return
+	}
+	delete(t.verified, hash)
+	latency := time.Since(verified)
+	t.acceptanceTimer.Update(latency)
+	t.acceptance[hash] = latency
+	if len(t.latencies) < acceptanceSampleSize {
+		t.latencies = append(t.latencies, latency)
+	} else {
+		t.latencies[t.next] = latency
+	}
+	t.next = (t.next + 1) % acceptanceSampleSize
+}
+
+// blockRejected records the rejection of the block [hash] by consensus.
+func (t *finalityTracker) blockRejected(hash common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.verified, hash)
+}
+
+// start starts the goroutine notifying the subscribers of the preferred and accepted
+// blocks and of the reorgs of [chain].
+func (t *finalityTracker) start(chain *core.BlockChain, shutdownChan <-chan struct{}, wg *sync.WaitGroup) {
+	var (
+		heads       = make(chan core.ChainHeadEvent, 16)
+		accepted    = make(chan core.ChainEvent, 64)
+		reorgs      = make(chan core.ChainReorgEvent, 16)
+		headSub     = chain.SubscribeChainHeadEvent(heads)
+		acceptedSub = chain.SubscribeChainAcceptedEvent(accepted)
+		reorgSub    = chain.SubscribeChainReorgEvent(reorgs)
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer headSub.Unsubscribe()
+		defer acceptedSub.Unsubscribe()
+		defer reorgSub.Unsubscribe()
+		defer t.scope.Close()
+
+		for {
+			select {
+			case ev := <-heads:
+				t.notify(chain, FinalityEvent{Type: preferredFinalityEvent, Number: hexutil.Uint64(ev.Block.NumberU64()), Hash: ev.Block.Hash()})
+			case ev := <-accepted:
+				t.notify(chain, FinalityEvent{Type: acceptedFinalityEvent, Number: hexutil.Uint64(ev.Block.NumberU64()), Hash: ev.Hash, TimeToAcceptance: t.popAcceptance(ev.Hash)})
+			case ev := <-reorgs:
+				reorg := t.recordReorg(ev)
+				t.notify(chain, FinalityEvent{Type: reorgFinalityEvent, Number: hexutil.Uint64(ev.NewHead.NumberU64()), Hash: ev.NewHead.Hash(), Reorg: reorg})
+			case <-headSub.Err():
+				return
+			case <-acceptedSub.Err():
+				return
+			case <-reorgSub.Err():
+				return
+			case <-shutdownChan:
+				return
+			}
+		}
+	}()
+}
+
+// notify sends [ev] to the subscribers, along with the current heights of [chain].
+func (t *finalityTracker) notify(chain *core.BlockChain, ev FinalityEvent) {
+	accepted, preferred := chain.LastConsensusAcceptedBlock().NumberU64(), chain.CurrentBlock().NumberU64()
+	ev.AcceptedHeight, ev.PreferredHeight = hexutil.Uint64(accepted), hexutil.Uint64(preferred)
+	if preferred > accepted {
+		t.divergence.Update(int64(preferred - accepted))
+	} else {
+		t.divergence.Update(0)
+	}
+	t.feed.Send(ev)
+}
+
+// popAcceptance returns the time to acceptance of the block [hash] in milliseconds, or nil
+// if it is not known.
+func (t *finalityTracker) popAcceptance(hash common.Hash) *float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	latency, ok := t.acceptance[hash]
+	if !ok {
+		return nil
+	}
+	delete(t.acceptance, hash)
+	ms := milliseconds(latency)
+	return &ms
+}
+
+func (t *finalityTracker) recordReorg(ev core.ChainReorgEvent) *ReorgInfo {
+	reorg := &ReorgInfo{
+		Time:         time.Now(),
+		Depth:        ev.Depth,
+		CommonBlock:  ev.CommonBlock.Hash(),
+		CommonHeight: hexutil.Uint64(ev.CommonBlock.NumberU64()),
+		OldHead:      ev.OldHead.Hash(),
+		NewHead:      ev.NewHead.Hash(),
+		Dropped:      ev.Dropped,
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.reorgCount++
+	t.reorgs = append(t.reorgs, reorg)
+	if len(t.reorgs) > maxRecentReorgs {
+		t.reorgs = t.reorgs[len(t.reorgs)-maxRecentReorgs:]
+	}
+	return reorg
+}
+
+// subscribe registers [ch] to receive the finality events.
+func (t *finalityTracker) subscribe(ch chan<- FinalityEvent) event.Subscription {
+	return t.scope.Track(t.feed.Subscribe(ch))
+}
+
+// recentReorgs returns the last reorgs, from the oldest.
+func (t *finalityTracker) recentReorgs() []*ReorgInfo {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return append([]*ReorgInfo{}, t.reorgs...)
+}
+
+// status returns the finality status of [chain].
+func (t *finalityTracker) status(chain *core.BlockChain) *FinalityStatus {
+	accepted, preferred := chain.LastConsensusAcceptedBlock(), chain.CurrentBlock()
+	status := &FinalityStatus{
+		AcceptedHeight:  hexutil.Uint64(accepted.NumberU64()),
+		AcceptedHash:    accepted.Hash(),
+		PreferredHeight: hexutil.Uint64(preferred.NumberU64()),
+		PreferredHash:   preferred.Hash(),
+	}
+	if preferred.NumberU64() > accepted.NumberU64() {
+		status.Divergence = hexutil.Uint64(preferred.NumberU64() - accepted.NumberU64())
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	status.Reorgs = t.reorgCount
+	status.TimeToAcceptance = latencyDistribution(t.latencies)
+	return status
+}
+
+// latencyDistribution returns the distribution of [latencies].
+func latencyDistribution(latencies []time.Duration) AcceptanceLatency {
+	if len(latencies) == 0 {
+		return AcceptanceLatency{}
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, latency := range sorted {
+		sum += latency
+	}
+	percentile := func(p float64) float64 {
+		return milliseconds(sorted[int(p*float64(len(sorted)-1))])
+	}
+	return AcceptanceLatency{
+		Samples: len(sorted),
+		Mean:    milliseconds(sum / time.Duration(len(sorted))),
+		P50:     percentile(0.5),
+		P90:     percentile(0.9),
+		P99:     percentile(0.99),
+		Max:     milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// FinalityAPI reports how the preferred chain of the node diverges from the accepted
+// chain, so that integrations can confirm when their transactions are final.
+type FinalityAPI struct{ vm *VM }
+
+// Status returns the accepted and preferred heights, the number of reorgs and the time
+// to acceptance distribution of the last accepted blocks.
+func (api *FinalityAPI) Status(context.Context) (*FinalityStatus, error) {
+	return api.vm.finality.status(api.vm.blockChain), nil
+}
+
+// Reorgs returns the last reorgs of the preferred chain, from the oldest.
+func (api *FinalityAPI) Reorgs(context.Context) ([]*ReorgInfo, error) {
+	return api.vm.finality.recentReorgs(), nil
+}
+
+// Events creates a subscription that fires when the preferred block changes, a block is
+// accepted or the preferred chain reorgs.
+func (api *FinalityAPI) Events(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	var (
+		rpcSub    = notifier.CreateSubscription()
+		events    = make(chan FinalityEvent, 64)
+		eventsSub = api.vm.finality.subscribe(events)
+	)
+	go func() {
+		defer eventsSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case <-eventsSub.Err(): // the VM is shutting down
+				return
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,134 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyDistribution(t *testing.T) {
+	require.Equal(t, AcceptanceLatency{}, latencyDistribution(nil))
+
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, AcceptanceLatency{
+		Samples: 100,
+		Mean:    50.5,
+		P50:     50,
+		P90:     90,
+		P99:     99,
+		Max:     100,
+	}, latencyDistribution(latencies))
+}
+
+func TestFinalityTrackerAcceptance(t *testing.T) {
+	tracker := newFinalityTracker()
+	accepted, rejected, unverified := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	tracker.blockVerified(accepted)
+	tracker.blockVerified(rejected)
+	tracker.blockAccepted(accepted)
+	tracker.blockRejected(rejected)
+	tracker.blockAccepted(unverified)
+
+	require.Empty(t, tracker.verified)
+	require.Len(t, tracker.latencies, 1)
+	require.NotNil(t, tracker.popAcceptance(accepted))
+	require.Nil(t, tracker.popAcceptance(accepted))
+	require.Nil(t, tracker.popAcceptance(unverified))
+
+	// The distribution is computed over the last accepted blocks
+	for i := 0; i < acceptanceSampleSize; i++ {
+		hash := common.BigToHash(big.NewInt(int64(i + 10)))
+		tracker.blockVerified(hash)
+		tracker.blockAccepted(hash)
+		tracker.popAcceptance(hash)
+	}
+	require.Len(t, tracker.latencies, acceptanceSampleSize)
+}
+
+func TestFinalityTrackerReorgs(t *testing.T) {
+	tracker := newFinalityTracker()
+	block := func(number int64) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number)})
+	}
+	ancestor, oldHead, newHead := block(1), block(3), block(2)
+	for i := 0; i < maxRecentReorgs+1; i++ {
+		tracker.recordReorg(core.ChainReorgEvent{
+			OldHead:     oldHead,
+			NewHead:     newHead,
+			CommonBlock: ancestor,
+			Depth:       i + 1,
+			Dropped:     []common.Hash{oldHead.Hash(), oldHead.ParentHash()},
+		})
+	}
+
+	reorgs := tracker.recentReorgs()
+	require.Len(t, reorgs, maxRecentReorgs)
+	require.Equal(t, 2, reorgs[0].Depth)
+	last := reorgs[len(reorgs)-1]
+	require.Equal(t, maxRecentReorgs+1, last.Depth)
+	require.Equal(t, ancestor.Hash(), last.CommonBlock)
+	require.EqualValues(t, 1, last.CommonHeight)
+	require.Equal(t, newHead.Hash(), last.NewHead)
+	require.Equal(t, []common.Hash{oldHead.Hash(), oldHead.ParentHash()}, last.Dropped)
+}
+
+func TestFinalityAPI(t *testing.T) {
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, `{"finality-api-enabled":true}`, "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	events := make(chan FinalityEvent, 8)
+	sub := vm.finality.subscribe(events)
+	defer sub.Unsubscribe()
+
+	tx := types.NewTransaction(uint64(0), testEthAddrs[1], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(t, err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(t, err)
+	}
+	blk := issueAndAccept(t, issuer, vm)
+
+	received := make(map[string]FinalityEvent)
+	for len(received) < 2 {
+		select {
+		case ev := <-events:
+			require.Equal(t, common.Hash(blk.ID()), ev.Hash)
+			received[ev.Type] = ev
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for finality events, got %v", received)
+		}
+	}
+	require.EqualValues(t, 1, received[preferredFinalityEvent].PreferredHeight)
+	accepted := received[acceptedFinalityEvent]
+	require.EqualValues(t, 1, accepted.Number)
+	require.EqualValues(t, 1, accepted.AcceptedHeight)
+	require.NotNil(t, accepted.TimeToAcceptance)
+
+	api := &FinalityAPI{vm}
+	status, err := api.Status(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, status.AcceptedHeight)
+	require.Equal(t, common.Hash(blk.ID()), status.AcceptedHash)
+	require.EqualValues(t, 1, status.PreferredHeight)
+	require.Zero(t, status.Divergence)
+	require.Zero(t, status.Reorgs)
+	require.Equal(t, 1, status.TimeToAcceptance.Samples)
+
+	reorgs, err := api.Reorgs(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, reorgs)
+}
@@ -45,6 +45,14 @@ func (gpu *gasPriceUpdater) start() {
 	minBaseFee := gpu.chainConfig.FeeConfig.MinBaseFee
 	// Updates to the minimum gas price as of Subnet EVM if it's already in effect or starts a goroutine to enable it at the correct time
 	gpu.handleUpdate(gpu.setter.SetMinFee, gpu.chainConfig.SubnetEVMTimestamp, minBaseFee)
+
+	// Updates to the minimum gas price scheduled by parameter upgrades, applied in order of activation
+	for _, upgrade := range gpu.chainConfig.Upgrades().ParameterUpgrades {
+		if upgrade.FeeConfig == nil {
+			continue
+		}
+		gpu.handleUpdate(gpu.setter.SetMinFee, upgrade.BlockTimestamp, upgrade.FeeConfig.MinBaseFee)
+	}
 }
 
 // handleUpdate handles calling update(price) at the appropriate time based on
@@ -0,0 +1,224 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/precompile"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	// maxTrackedGossipPeers is the number of peers whose tx gossip stats are tracked, after
+	// which the stats of the least recently seen peer are dropped to track a new peer.
+	maxTrackedGossipPeers = 1024
+	// txFirstSeenCacheSize is the number of gossiped txs whose first arrival is remembered
+	// to measure how late the peers gossiping them again are.
+	txFirstSeenCacheSize = 16_384
+
+	// otherRejectionReason is the reason of the rejections not matching [txRejectionReasons].
+	otherRejectionReason = "other"
+)
+
+// txRejectionReasons are the reasons the tx pool rejects gossiped txs for, reported in
+// the metrics and the admin API.
+var txRejectionReasons = []struct {
+	err    error
+	reason string
+}{
+	{core.ErrUnderpriced, "underpriced"},
+	{core.ErrReplaceUnderpriced, "replaceUnderpriced"},
+	{core.ErrFeeCapTooLow, "feeCapTooLow"},
+	{core.ErrTipAboveFeeCap, "tipAboveFeeCap"},
+	{core.ErrFeeCapVeryHigh, "feeCapVeryHigh"},
+	{core.ErrTipVeryHigh, "tipVeryHigh"},
+	{core.ErrNonceTooLow, "nonceTooLow"},
+	{core.ErrNonceGapTooLarge, "nonceGapTooLarge"},
+	{core.ErrInsufficientFunds, "insufficientFunds"},
+	{core.ErrIntrinsicGas, "intrinsicGas"},
+	{core.ErrGasLimit, "gasLimit"},
+	{core.ErrTxPoolOverflow, "txPoolOverflow"},
+	{core.ErrInvalidSender, "invalidSender"},
+	{core.ErrOversizedData, "oversizedData"},
+	{core.ErrNegativeValue, "negativeValue"},
+	{core.ErrTxTypeNotSupported, "txTypeNotSupported"},
+	{precompile.ErrSenderAddressNotAllowListed, "notAllowListed"},
+}
+
+// txRejectionReason returns the reason of the rejection of a tx by the tx pool with [err].
+func txRejectionReason(err error) string {
+	for _, rejection := range txRejectionReasons {
+		if errors.Is(err, rejection.err) {
+			return rejection.reason
+		}
+	}
+	return otherRejectionReason
+}
+
+// PeerTxGossipStats are the stats of the txs gossiped by a peer.
+type PeerTxGossipStats struct {
+	Messages        uint64 `json:"messages"`
+	InvalidMessages uint64 `json:"invalidMessages"`
+	Txs             uint64 `json:"txs"`
+	// New txs were added to the tx pool, and the First of them were gossiped by this peer
+	// before any other peer.
+	New   uint64 `json:"new"`
+	First uint64 `json:"first"`
+	// Duplicates were already known to the tx pool, and Dropped were rejected by the tx
+	// pool for the Rejections reasons.
+	Duplicates uint64            `json:"duplicates"`
+	Dropped    uint64            `json:"dropped"`
+	Rejections map[string]uint64 `json:"rejections,omitempty"`
+	// AvgDuplicateDelay estimates the propagation latency of the peer: the average time
+	// between the first arrival of a tx and its arrival from this peer, in milliseconds.
+	AvgDuplicateDelay float64   `json:"avgDuplicateDelay"`
+	LastSeen          time.Time `json:"lastSeen"`
+
+	duplicateDelaySamples uint64
+	duplicateDelaySum     time.Duration
+}
+
+func (s *PeerTxGossipStats) copy() *PeerTxGossipStats {
+	c := *s
+	c.Rejections = make(map[string]uint64, len(s.Rejections))
+	for reason, count := range s.Rejections {
+		c.Rejections[reason] = count
+	}
+	if s.duplicateDelaySamples > 0 {
+		c.AvgDuplicateDelay = float64(s.duplicateDelaySum.Microseconds()) / float64(s.duplicateDelaySamples) / 1000
+	}
+	return &c
+}
+
+// txGossipTracker tracks the txs gossiped by each peer, to identify the peers gossiping
+// invalid or late txs and the reasons the txs of the peers are dropped for.
+type txGossipTracker struct {
+	lock       sync.Mutex
+	peers      map[ids.NodeID]*PeerTxGossipStats
+	rejections map[string]uint64
+	firstSeen  *lru.Cache // tx hash -> time of the first arrival
+
+	invalidMessages metrics.Counter
+	dropped         metrics.Counter
+	duplicateDelay  metrics.Timer
+}
+
+func newTxGossipTracker() *txGossipTracker {
+	firstSeen, _ := lru.New(txFirstSeenCacheSize)
+	return &txGossipTracker{
+		peers:           make(map[ids.NodeID]*PeerTxGossipStats),
+		rejections:      make(map[string]uint64),
+		firstSeen:       firstSeen,
+		invalidMessages: metrics.GetOrRegisterCounter("gossip_eth_txs_received_invalid", nil),
+		dropped:         metrics.GetOrRegisterCounter("gossip_eth_txs_received_dropped", nil),
+		duplicateDelay:  metrics.GetOrRegisterTimer("gossip_eth_txs_duplicate_delay", nil),
+	}
+}
+
+// peer returns the stats of [nodeID], tracking it if needed. Assumes the lock is held.
+func (t *txGossipTracker) peer(nodeID ids.NodeID, now time.Time) *PeerTxGossipStats {
+	stats, ok := t.peers[nodeID]
+	if !ok {
+		if len(t.peers) >= maxTrackedGossipPeers {
+			t.evictLeastRecentPeer()
+		}
+		stats = &PeerTxGossipStats{Rejections: make(map[string]uint64)}
+		t.peers[nodeID] = stats
+	}
+	stats.LastSeen = now
+	return stats
+}
+
+func (t *txGossipTracker) evictLeastRecentPeer() {
+	var (
+		oldest   ids.NodeID
+		lastSeen time.Time
+	)
+	for nodeID, stats := range t.peers {
+		if lastSeen.IsZero() || stats.LastSeen.Before(lastSeen) {
+			oldest, lastSeen = nodeID, stats.LastSeen
+		}
+	}
+	delete(t.peers, oldest)
+}
+
+// recordInvalid records a tx gossip message from [nodeID] that could not be decoded.
+func (t *txGossipTracker) recordInvalid(nodeID ids.NodeID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.invalidMessages.Inc(1)
+	stats := t.peer(nodeID, time.Now())
+	stats.Messages++
+	stats.InvalidMessages++
+}
+
+// recordTxs records the [txs] gossiped by [nodeID] in a message, and the [errs] the tx
+// pool returned when adding them.
+func (t *txGossipTracker) recordTxs(nodeID ids.NodeID, txs []*types.Transaction, errs []error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	stats := t.peer(nodeID, now)
+	stats.Messages++
+	stats.Txs += uint64(len(txs))
+	for i, tx := range txs {
+		hash := tx.Hash()
+		firstSeen, seen := t.firstSeen.Get(hash)
+		if !seen {
+			t.firstSeen.Add(hash, now)
+		}
+		switch err := errs[i]; {
+		case err == nil:
+			stats.New++
+			if !seen {
+				stats.First++
+			}
+		case errors.Is(err, core.ErrAlreadyKnown):
+			stats.Duplicates++
+			if seen {
+				delay := now.Sub(firstSeen.(time.Time))
+				t.duplicateDelay.Update(delay)
+				stats.duplicateDelaySamples++
+				stats.duplicateDelaySum += delay
+			}
+		default:
+			reason := txRejectionReason(err)
+			t.dropped.Inc(1)
+			metrics.GetOrRegisterCounter("gossip_eth_txs_rejected_"+reason, nil).Inc(1)
+			stats.Dropped++
+			stats.Rejections[reason]++
+			t.rejections[reason]++
+		}
+	}
+}
+
+// snapshot returns a copy of the stats of the tracked peers and of the rejection counts
+// by reason. It is safe to call snapshot on a nil tracker, in which case it returns
+// empty stats.
+func (t *txGossipTracker) snapshot() (map[ids.NodeID]*PeerTxGossipStats, map[string]uint64) {
+	peers := make(map[ids.NodeID]*PeerTxGossipStats)
+	rejections := make(map[string]uint64)
+	if t == nil {
+		return peers, rejections
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for nodeID, stats := range t.peers {
+		peers[nodeID] = stats.copy()
+	}
+	for reason, count := range t.rejections {
+		rejections[reason] = count
+	}
+	return peers, rejections
+}
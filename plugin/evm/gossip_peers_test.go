@@ -0,0 +1,115 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRejectionReason(t *testing.T) {
+	require.Equal(t, "underpriced", txRejectionReason(fmt.Errorf("%w: address 0x01", core.ErrUnderpriced)))
+	require.Equal(t, "nonceTooLow", txRejectionReason(core.ErrNonceTooLow))
+	require.Equal(t, "notAllowListed", txRejectionReason(fmt.Errorf("%w: 0x01", precompile.ErrSenderAddressNotAllowListed)))
+	require.Equal(t, otherRejectionReason, txRejectionReason(fmt.Errorf("unexpected")))
+}
+
+func TestTxGossipTracker(t *testing.T) {
+	tracker := newTxGossipTracker()
+	txs := []*types.Transaction{
+		types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		types.NewTransaction(1, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+		types.NewTransaction(2, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil),
+	}
+	first, second := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+
+	tracker.recordTxs(first, txs, []error{nil, nil, core.ErrUnderpriced})
+	tracker.recordTxs(second, txs[:2], []error{core.ErrAlreadyKnown, core.ErrAlreadyKnown})
+	tracker.recordInvalid(second)
+
+	peers, rejections := tracker.snapshot()
+	require.Len(t, peers, 2)
+	require.Equal(t, map[string]uint64{"underpriced": 1}, rejections)
+
+	firstStats := peers[first]
+	require.EqualValues(t, 1, firstStats.Messages)
+	require.EqualValues(t, 3, firstStats.Txs)
+	require.EqualValues(t, 2, firstStats.New)
+	require.EqualValues(t, 2, firstStats.First)
+	require.EqualValues(t, 1, firstStats.Dropped)
+	require.Equal(t, map[string]uint64{"underpriced": 1}, firstStats.Rejections)
+	require.Zero(t, firstStats.AvgDuplicateDelay)
+
+	secondStats := peers[second]
+	require.EqualValues(t, 2, secondStats.Messages)
+	require.EqualValues(t, 1, secondStats.InvalidMessages)
+	require.EqualValues(t, 2, secondStats.Duplicates)
+	require.EqualValues(t, 2, secondStats.duplicateDelaySamples)
+	require.Zero(t, secondStats.First)
+
+	// The snapshot is not modified by later gossip
+	tracker.recordTxs(first, txs[2:], []error{core.ErrNonceTooLow})
+	require.Equal(t, map[string]uint64{"underpriced": 1}, firstStats.Rejections)
+
+	// A nil tracker returns empty stats
+	var nilTracker *txGossipTracker
+	peers, rejections = nilTracker.snapshot()
+	require.Empty(t, peers)
+	require.Empty(t, rejections)
+}
+
+func TestTxGossipTrackerEviction(t *testing.T) {
+	tracker := newTxGossipTracker()
+	firstPeer := ids.GenerateTestNodeID()
+	tracker.recordInvalid(firstPeer)
+	for i := 1; i < maxTrackedGossipPeers; i++ {
+		tracker.recordInvalid(ids.GenerateTestNodeID())
+	}
+	// The least recently seen peer is evicted to track a new peer
+	tracker.recordInvalid(ids.GenerateTestNodeID())
+	peers, _ := tracker.snapshot()
+	require.Len(t, peers, maxTrackedGossipPeers)
+	require.NotContains(t, peers, firstPeer)
+}
+
+func TestGetTxGossipStats(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	// The first tx is valid, and the second does not pay the minimum base fee
+	valid, err := types.SignTx(types.NewTransaction(0, testEthAddrs[1], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil), types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(t, err)
+	underpriced, err := types.SignTx(types.NewTransaction(0, testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(1), nil), types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[1])
+	require.NoError(t, err)
+	txBytes, err := rlp.EncodeToBytes([]*types.Transaction{valid, underpriced})
+	require.NoError(t, err)
+
+	handler := NewGossipHandler(vm, NewGossipStats())
+	nodeID := ids.GenerateTestNodeID()
+	require.NoError(t, handler.HandleTxs(nodeID, message.TxsGossip{Txs: txBytes}))
+	require.NoError(t, handler.HandleTxs(nodeID, message.TxsGossip{Txs: []byte{0x01}}))
+
+	var reply TxGossipStatsReply
+	require.NoError(t, NewAdminService(vm, "").GetTxGossipStats(nil, nil, &reply))
+	require.Equal(t, map[string]uint64{"underpriced": 1}, reply.Rejections)
+	stats := reply.Peers[nodeID]
+	require.NotNil(t, stats)
+	require.EqualValues(t, 2, stats.Messages)
+	require.EqualValues(t, 1, stats.InvalidMessages)
+	require.EqualValues(t, 1, stats.New)
+	require.EqualValues(t, 1, stats.Dropped)
+}
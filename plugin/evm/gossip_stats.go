@@ -20,6 +20,10 @@ type GossipReceivedStats interface {
 	// new vs. known txs received
 	IncEthTxsGossipReceivedKnown()
 	IncEthTxsGossipReceivedNew()
+
+	// chain config hashes received and mismatching the hash of this node
+	IncChainConfigHashGossipReceived()
+	IncChainConfigHashMismatch()
 }
 
 // GossipSentStats groups functions for outgoing gossip stats.
@@ -30,6 +34,8 @@ type GossipSentStats interface {
 	IncEthTxsRegossipQueued()
 	IncEthTxsRegossipQueuedLocal(count int)
 	IncEthTxsRegossipQueuedRemote(count int)
+
+	IncChainConfigHashGossipSent()
 }
 
 // gossipStats implements stats for incoming and outgoing gossip stats.
@@ -46,6 +52,11 @@ type gossipStats struct {
 	// new vs. known txs received
 	ethTxsGossipReceivedKnown metrics.Counter
 	ethTxsGossipReceivedNew   metrics.Counter
+
+	// chain config hashes
+	chainConfigHashGossipSent     metrics.Counter
+	chainConfigHashGossipReceived metrics.Counter
+	chainConfigHashMismatch       metrics.Counter
 }
 
 func NewGossipStats() GossipStats {
@@ -59,6 +70,10 @@ func NewGossipStats() GossipStats {
 
 		ethTxsGossipReceivedKnown: metrics.GetOrRegisterCounter("gossip_eth_txs_received_known", nil),
 		ethTxsGossipReceivedNew:   metrics.GetOrRegisterCounter("gossip_eth_txs_received_new", nil),
+
+		chainConfigHashGossipSent:     metrics.GetOrRegisterCounter("gossip_chain_config_hash_sent", nil),
+		chainConfigHashGossipReceived: metrics.GetOrRegisterCounter("gossip_chain_config_hash_received", nil),
+		chainConfigHashMismatch:       metrics.GetOrRegisterCounter("gossip_chain_config_hash_mismatch", nil),
 	}
 }
 
@@ -80,3 +95,8 @@ func (g *gossipStats) IncEthTxsRegossipQueuedLocal(count int) {
 func (g *gossipStats) IncEthTxsRegossipQueuedRemote(count int) {
 	g.ethTxsRegossipQueuedRemote.Inc(int64(count))
 }
+
+// chain config hashes
+func (g *gossipStats) IncChainConfigHashGossipSent()     { g.chainConfigHashGossipSent.Inc(1) }
+func (g *gossipStats) IncChainConfigHashGossipReceived() { g.chainConfigHashGossipReceived.Inc(1) }
+func (g *gossipStats) IncChainConfigHashMismatch()       { g.chainConfigHashMismatch.Inc(1) }
@@ -34,6 +34,10 @@ const (
 	// [txsGossipInterval] is how often we attempt to gossip newly seen
 	// transactions to other nodes.
 	txsGossipInterval = 500 * time.Millisecond
+
+	// [chainConfigHashGossipInterval] is how often we advertise the hash of
+	// our chain config to other nodes.
+	chainConfigHashGossipInterval = time.Minute
 )
 
 // Gossiper handles outgoing gossip of transactions
@@ -341,6 +345,12 @@ func (n *pushGossiper) gossipTxs(force bool) (int, error) {
 			continue
 		}
 
+		// Conditional transactions are not gossiped, since other nodes would include
+		// them without checking their preconditions
+		if tx.Conditional() != nil {
+			continue
+		}
+
 		// We check [force] outside of the if statement to avoid an unnecessary
 		// cache lookup.
 		if !force {
@@ -401,16 +411,18 @@ func (n *pushGossiper) GossipTxs(txs []*types.Transaction) error {
 
 // GossipHandler handles incoming gossip messages
 type GossipHandler struct {
-	vm     *VM
-	txPool *core.TxPool
-	stats  GossipReceivedStats
+	vm      *VM
+	txPool  *core.TxPool
+	stats   GossipReceivedStats
+	tracker *txGossipTracker
 }
 
 func NewGossipHandler(vm *VM, stats GossipReceivedStats) *GossipHandler {
 	return &GossipHandler{
-		vm:     vm,
-		txPool: vm.txPool,
-		stats:  stats,
+		vm:      vm,
+		txPool:  vm.txPool,
+		stats:   stats,
+		tracker: vm.txGossipTracker,
 	}
 }
 
@@ -437,10 +449,12 @@ func (h *GossipHandler) HandleTxs(nodeID ids.NodeID, msg message.TxsGossip) erro
 			"peerID", nodeID,
 			"err", err,
 		)
+		h.tracker.recordInvalid(nodeID)
 		return nil
 	}
 	h.stats.IncEthTxsGossipReceived()
 	errs := h.txPool.AddRemotes(txs)
+	h.tracker.recordTxs(nodeID, txs, errs)
 	for i, err := range errs {
 		if err != nil {
 			log.Trace(
@@ -458,6 +472,64 @@ func (h *GossipHandler) HandleTxs(nodeID ids.NodeID, msg message.TxsGossip) erro
 	return nil
 }
 
+// HandleChainConfigHash compares the chain config hash advertised by [nodeID] with the hash of the chain config
+// of this node, and warns if they differ. Validators with different chain configs (typically different upgrade
+// bytes) will fail to agree on blocks once the upgrades they disagree on activate.
+func (h *GossipHandler) HandleChainConfigHash(nodeID ids.NodeID, msg message.ChainConfigHashGossip) error {
+	log.Trace(
+		"AppGossip called with ChainConfigHashGossip",
+		"peerID", nodeID,
+		"hash", msg.Hash,
+	)
+	h.stats.IncChainConfigHashGossipReceived()
+
+	if hash := h.vm.chainConfig.Hash(); msg.Hash != hash {
+		h.stats.IncChainConfigHashMismatch()
+		log.Warn(
+			"peer advertised a different chain config hash, check that the upgrade configs of the validators match",
+			"peerID", nodeID,
+			"peerHash", msg.Hash,
+			"hash", hash,
+		)
+	}
+	return nil
+}
+
+// awaitChainConfigHashGossip advertises the hash of the chain config of this node to other nodes once
+// when it starts and then every [chainConfigHashGossipInterval], so that they can detect mismatched
+// chain configs.
+func (vm *VM) awaitChainConfigHashGossip(stats GossipSentStats) {
+	vm.shutdownWg.Add(1)
+	go vm.ctx.Log.RecoverAndPanic(func() {
+		defer vm.shutdownWg.Done()
+
+		ticker := time.NewTicker(chainConfigHashGossipInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := vm.gossipChainConfigHash(stats); err != nil {
+				log.Warn("failed to gossip chain config hash", "err", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-vm.shutdownChan:
+				return
+			}
+		}
+	})
+}
+
+func (vm *VM) gossipChainConfigHash(stats GossipSentStats) error {
+	msgBytes, err := message.BuildGossipMessage(vm.networkCodec, message.ChainConfigHashGossip{
+		Hash: vm.chainConfig.Hash(),
+	})
+	if err != nil {
+		return err
+	}
+	stats.IncChainConfigHashGossipSent()
+	return vm.client.Gossip(msgBytes)
+}
+
 // noopGossiper should be used when gossip communication is not supported
 type noopGossiper struct{}
 
@@ -3,12 +3,128 @@
 
 package evm
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
 
-// Health returns nil if this chain is healthy.
-// Also returns details, which should be one of:
-// string, []byte, map[string]string
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errNotBootstrapped = errors.New("not bootstrapped")
+
+// compactionBacklogReporter is implemented by the databases reporting their compaction
+// backlog, such as the pebble database.
+type compactionBacklogReporter interface {
+	// CompactionBacklog returns the estimated number of bytes left to compact and
+	// the number of compactions in progress.
+	CompactionBacklog() (uint64, int64)
+}
+
+// HealthStatus is the health of the node, as reported by the health API.
+type HealthStatus struct {
+	Healthy      bool            `json:"healthy"`
+	Bootstrapped bool            `json:"bootstrapped"`
+	StateSync    StateSyncStatus `json:"stateSync"`
+
+	LastAcceptedHeight    uint64 `json:"lastAcceptedHeight"`
+	LastAcceptedTimestamp uint64 `json:"lastAcceptedTimestamp"`
+	// LastAcceptedAge is the number of seconds since the timestamp of the last accepted block
+	// and TargetBlockRate the number of seconds between blocks targeted by the fee config.
+	LastAcceptedAge uint64 `json:"lastAcceptedAge"`
+	TargetBlockRate uint64 `json:"targetBlockRate"`
+
+	// CompactionDebt and CompactionsInProgress are only reported by the pebble database.
+	CompactionDebt        *uint64 `json:"compactionDebt,omitempty"`
+	CompactionsInProgress *int64  `json:"compactionsInProgress,omitempty"`
+
+	// PrecompileConfigHash is the hash of the precompile configs active at the last accepted
+	// block, which differs between nodes that disagree on the precompiles of the chain.
+	PrecompileConfigHash common.Hash `json:"precompileConfigHash"`
+
+	// Errors are the reasons the node is unhealthy.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// healthStatus returns the current health of the node. The node is unhealthy while it is not
+// bootstrapped and, if the thresholds are set in the config, while the last accepted block is
+// too old or the database has too much compaction debt.
+func (vm *VM) healthStatus() *HealthStatus {
+	status := &HealthStatus{
+		Bootstrapped: vm.bootstrapped.GetValue(),
+		StateSync:    vm.StateSyncClient.Status(),
+	}
+	if !status.Bootstrapped {
+		status.Errors = append(status.Errors, errNotBootstrapped.Error())
+	}
+
+	lastAccepted := vm.blockChain.LastAcceptedBlock()
+	status.LastAcceptedHeight = lastAccepted.NumberU64()
+	status.LastAcceptedTimestamp = lastAccepted.Time()
+	if now := vm.clock.Unix(); now > lastAccepted.Time() {
+		status.LastAcceptedAge = now - lastAccepted.Time()
+	}
+	status.PrecompileConfigHash = vm.chainConfig.PrecompileConfigHashAt(lastAccepted.Number(), new(big.Int).SetUint64(lastAccepted.Time()))
+
+	feeConfig, _, err := vm.blockChain.GetFeeConfigAt(lastAccepted.Header())
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to get fee config: %s", err))
+	} else {
+		status.TargetBlockRate = feeConfig.TargetBlockRate
+		maxAge := vm.config.HealthMaxBlockAgeFactor * float64(feeConfig.TargetBlockRate)
+		if status.Bootstrapped && maxAge > 0 && float64(status.LastAcceptedAge) > maxAge {
+			status.Errors = append(status.Errors, fmt.Sprintf("last accepted block is %ds old, exceeding %.0fs", status.LastAcceptedAge, maxAge))
+		}
+	}
+
+	if reporter, ok := vm.chainKVStore.(compactionBacklogReporter); ok {
+		debt, inProgress := reporter.CompactionBacklog()
+		status.CompactionDebt, status.CompactionsInProgress = &debt, &inProgress
+		if maxDebt := vm.config.HealthMaxCompactionDebt; maxDebt > 0 && debt > maxDebt {
+			status.Errors = append(status.Errors, fmt.Sprintf("compaction debt of %d bytes exceeds %d", debt, maxDebt))
+		}
+	}
+
+	status.Healthy = len(status.Errors) == 0
+	return status
+}
+
+// HealthCheck implements the health.Checker interface, returning the health status
+// of the node as details along with an error if it is unhealthy.
 func (vm *VM) HealthCheck(context.Context) (interface{}, error) {
-	// TODO perform actual health check
-	return nil, nil
+	status := vm.healthStatus()
+	if !status.Healthy {
+		return status, fmt.Errorf("unhealthy: %s", strings.Join(status.Errors, ", "))
+	}
+	return status, nil
+}
+
+// healthHandler returns the handler of the health endpoint, which answers with the health status
+// of the node and the status code 200 if it is healthy or 503 if not, for load balancers.
+func (vm *VM) healthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := vm.healthStatus()
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Debug("failed to write health status", "err", err)
+		}
+	})
+}
+
+// HealthAPI offers the health status of the node
+type HealthAPI struct {
+	vm *VM
+}
+
+// Status returns the health status of the node
+func (api *HealthAPI) Status(context.Context) (*HealthStatus, error) {
+	return api.vm.healthStatus(), nil
 }
@@ -0,0 +1,69 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheck(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, `{"health-api-enabled": true, "health-max-block-age-factor": 2}`, "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+	// The genesis block has timestamp 0 and a target block rate of 2s
+	vm.clock.Set(time.Unix(1, 0))
+
+	// The node is unhealthy until it is bootstrapped
+	details, err := vm.HealthCheck(context.Background())
+	require.ErrorContains(t, err, errNotBootstrapped.Error())
+	status := details.(*HealthStatus)
+	require.False(t, status.Healthy)
+	require.False(t, status.Bootstrapped)
+	require.False(t, status.StateSync.Syncing)
+
+	require.NoError(t, vm.SetState(context.Background(), snow.Bootstrapping))
+	require.NoError(t, vm.SetState(context.Background(), snow.NormalOp))
+	details, err = vm.HealthCheck(context.Background())
+	require.NoError(t, err)
+	status = details.(*HealthStatus)
+	require.True(t, status.Healthy)
+	require.Empty(t, status.Errors)
+	require.Zero(t, status.LastAcceptedHeight)
+	require.EqualValues(t, 1, status.LastAcceptedAge)
+	require.EqualValues(t, 2, status.TargetBlockRate)
+	require.Nil(t, status.CompactionDebt)
+	require.Equal(t, vm.chainConfig.PrecompileConfigHashAt(big.NewInt(0), big.NewInt(0)), status.PrecompileConfigHash)
+
+	// The health endpoint answers 200 while the node is healthy
+	handler := vm.healthHandler()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, healthEndpoint, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var served HealthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Equal(t, *status, served)
+
+	// The node is unhealthy once the last accepted block is older than twice the target block rate
+	vm.clock.Set(time.Unix(5, 0))
+	_, err = vm.HealthCheck(context.Background())
+	require.ErrorContains(t, err, "last accepted block is 5s old")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, healthEndpoint, nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	status, err = (&HealthAPI{vm}).Status(context.Background())
+	require.NoError(t, err)
+	require.False(t, status.Healthy)
+	require.Len(t, status.Errors, 1)
+}
@@ -0,0 +1,50 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts"
+	"github.com/ava-labs/subnet-evm/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// unlockAccounts unlocks each of [addresses] in the keystore of [am] until the node stops, with the
+// passwords read from [passwordFile]: the password of the ith account is on the ith line of the file,
+// and the last line is used for the accounts without a line of their own.
+func unlockAccounts(am *accounts.Manager, addresses []common.Address, passwordFile string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	backends := am.Backends(keystore.KeyStoreType)
+	if len(backends) == 0 {
+		return fmt.Errorf("cannot unlock accounts without a keystore")
+	}
+	ks := backends[0].(*keystore.KeyStore)
+
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return fmt.Errorf("failed to read keystore password file: %w", err)
+	}
+	passwords := strings.Split(strings.TrimRight(string(data), "\r\n"), "\n")
+	for i, address := range addresses {
+		password := passwords[len(passwords)-1]
+		if i < len(passwords) {
+			password = passwords[i]
+		}
+		account, err := ks.Find(accounts.Account{Address: address})
+		if err != nil {
+			return fmt.Errorf("failed to find keystore account %s: %w", address, err)
+		}
+		if err := ks.Unlock(account, strings.TrimRight(password, "\r")); err != nil {
+			return fmt.Errorf("failed to unlock keystore account %s: %w", address, err)
+		}
+		log.Info("Unlocked keystore account", "address", address)
+	}
+	return nil
+}
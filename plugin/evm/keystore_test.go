@@ -0,0 +1,49 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/accounts"
+	"github.com/ava-labs/subnet-evm/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockAccounts(t *testing.T) {
+	dir := t.TempDir()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	am := accounts.NewManager(&accounts.Config{}, ks)
+	defer am.Close()
+
+	first, err := ks.NewAccount("first")
+	require.NoError(t, err)
+	second, err := ks.NewAccount("second")
+	require.NoError(t, err)
+	third, err := ks.NewAccount("second")
+	require.NoError(t, err)
+
+	passwordFile := filepath.Join(dir, "passwords")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("first\nsecond\n"), 0o600))
+
+	// The accounts can sign without their password once unlocked
+	_, err = ks.SignHash(first, common.Hash{}.Bytes())
+	require.ErrorIs(t, err, keystore.ErrLocked)
+	require.NoError(t, unlockAccounts(am, []common.Address{first.Address, second.Address, third.Address}, passwordFile))
+	for _, account := range []accounts.Account{first, second, third} {
+		_, err = ks.SignHash(account, common.Hash{}.Bytes())
+		require.NoError(t, err)
+	}
+
+	// The passwords are matched to the accounts by their order
+	err = unlockAccounts(am, []common.Address{second.Address, first.Address}, passwordFile)
+	require.ErrorContains(t, err, "failed to unlock keystore account "+second.Address.Hex())
+	err = unlockAccounts(am, []common.Address{{1}}, passwordFile)
+	require.ErrorContains(t, err, "failed to find keystore account")
+	err = unlockAccounts(am, []common.Address{first.Address}, filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "failed to read keystore password file")
+}
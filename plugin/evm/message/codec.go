@@ -37,6 +37,13 @@ func init() {
 		c.RegisterType(CodeRequest{}),
 		c.RegisterType(CodeResponse{}),
 
+		// Gossip types registered after the state sync types to keep the IDs of the existing types
+		c.RegisterType(ChainConfigHashGossip{}),
+
+		// Healing types registered last to keep the IDs of the existing types
+		c.RegisterType(TrieNodesRequest{}),
+		c.RegisterType(TrieNodesResponse{}),
+
 		Codec.RegisterCodec(Version, c),
 	)
 
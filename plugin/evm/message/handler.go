@@ -19,6 +19,7 @@ var (
 // GossipHandler handles incoming gossip messages
 type GossipHandler interface {
 	HandleTxs(nodeID ids.NodeID, msg TxsGossip) error
+	HandleChainConfigHash(nodeID ids.NodeID, msg ChainConfigHashGossip) error
 }
 
 type NoopMempoolGossipHandler struct{}
@@ -28,6 +29,11 @@ func (NoopMempoolGossipHandler) HandleTxs(nodeID ids.NodeID, _ TxsGossip) error
 	return nil
 }
 
+func (NoopMempoolGossipHandler) HandleChainConfigHash(nodeID ids.NodeID, _ ChainConfigHashGossip) error {
+	log.Debug("dropping unexpected ChainConfigHash message", "peerID", nodeID)
+	return nil
+}
+
 // RequestHandler interface handles incoming requests from peers
 // Must have methods in format of handleType(context.Context, ids.NodeID, uint32, request Type) error
 // so that the Request object of relevant Type can invoke its respective handle method
@@ -37,6 +43,7 @@ type RequestHandler interface {
 	HandleTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error)
 	HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockRequest) ([]byte, error)
 	HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest CodeRequest) ([]byte, error)
+	HandleTrieNodesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, trieNodesRequest TrieNodesRequest) ([]byte, error)
 }
 
 // ResponseHandler handles response for a sent request
@@ -61,3 +68,7 @@ func (NoopRequestHandler) HandleBlockRequest(ctx context.Context, nodeID ids.Nod
 func (NoopRequestHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest CodeRequest) ([]byte, error) {
 	return nil, nil
 }
+
+func (NoopRequestHandler) HandleTrieNodesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, trieNodesRequest TrieNodesRequest) ([]byte, error) {
+	return nil, nil
+}
@@ -12,7 +12,8 @@ import (
 )
 
 type CounterHandler struct {
-	Txs int
+	Txs               int
+	ChainConfigHashes int
 }
 
 func (h *CounterHandler) HandleTxs(ids.NodeID, TxsGossip) error {
@@ -20,6 +21,11 @@ func (h *CounterHandler) HandleTxs(ids.NodeID, TxsGossip) error {
 	return nil
 }
 
+func (h *CounterHandler) HandleChainConfigHash(ids.NodeID, ChainConfigHashGossip) error {
+	h.ChainConfigHashes++
+	return nil
+}
+
 func TestHandleTxs(t *testing.T) {
 	assert := assert.New(t)
 
@@ -31,6 +37,17 @@ func TestHandleTxs(t *testing.T) {
 	assert.Equal(1, handler.Txs)
 }
 
+func TestHandleChainConfigHash(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := CounterHandler{}
+	msg := ChainConfigHashGossip{}
+
+	err := msg.Handle(&handler, ids.EmptyNodeID)
+	assert.NoError(err)
+	assert.Equal(1, handler.ChainConfigHashes)
+}
+
 func TestNoopHandler(t *testing.T) {
 	assert := assert.New(t)
 
@@ -38,4 +55,7 @@ func TestNoopHandler(t *testing.T) {
 
 	err := handler.HandleTxs(ids.EmptyNodeID, TxsGossip{})
 	assert.NoError(err)
+
+	err = handler.HandleChainConfigHash(ids.EmptyNodeID, ChainConfigHashGossip{})
+	assert.NoError(err)
 }
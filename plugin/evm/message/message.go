@@ -25,6 +25,7 @@ const (
 
 var (
 	_ GossipMessage = TxsGossip{}
+	_ GossipMessage = ChainConfigHashGossip{}
 
 	errUnexpectedCodecVersion = errors.New("unexpected codec version")
 )
@@ -49,6 +50,20 @@ func (msg TxsGossip) String() string {
 	return fmt.Sprintf("TxsGossip(Len=%d)", len(msg.Txs))
 }
 
+// ChainConfigHashGossip advertises the hash of the chain config of the sender, including its
+// scheduled upgrades, so that peers can detect mismatched upgrade configs.
+type ChainConfigHashGossip struct {
+	Hash common.Hash `serialize:"true"`
+}
+
+func (msg ChainConfigHashGossip) Handle(handler GossipHandler, nodeID ids.NodeID) error {
+	return handler.HandleChainConfigHash(nodeID, msg)
+}
+
+func (msg ChainConfigHashGossip) String() string {
+	return fmt.Sprintf("ChainConfigHashGossip(Hash=%s)", msg.Hash)
+}
+
 func ParseGossipMessage(codec codec.Manager, bytes []byte) (GossipMessage, error) {
 	var msg GossipMessage
 	version, err := codec.Unmarshal(bytes, &msg)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +37,29 @@ func TestMarshalTxs(t *testing.T) {
 	assert.Equal(msg, parsedMsg.Txs)
 }
 
+// TestMarshalChainConfigHash asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalChainConfigHash(t *testing.T) {
+	assert := assert.New(t)
+
+	base64ChainConfigHashGossip := "AAAAAAAIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE="
+	hash := common.HexToHash("0x01")
+	builtMsg := ChainConfigHashGossip{
+		Hash: hash,
+	}
+	builtMsgBytes, err := BuildGossipMessage(Codec, builtMsg)
+	assert.NoError(err)
+	assert.Equal(base64ChainConfigHashGossip, base64.StdEncoding.EncodeToString(builtMsgBytes))
+
+	parsedMsgIntf, err := ParseGossipMessage(Codec, builtMsgBytes)
+	assert.NoError(err)
+
+	parsedMsg, ok := parsedMsgIntf.(ChainConfigHashGossip)
+	assert.True(ok)
+
+	assert.Equal(hash, parsedMsg.Hash)
+}
+
 func TestTxsTooLarge(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,51 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxTrieNodesPerRequest is the maximum number of trie nodes that can be requested in a single TrieNodesRequest
+const MaxTrieNodesPerRequest = 128
+
+var _ Request = TrieNodesRequest{}
+
+// TrieNodesRequest is a request to retrieve trie nodes by their hashes, used to heal
+// the state trie after syncing leafs from multiple roots
+type TrieNodesRequest struct {
+	// Hashes is a list of trie node hashes
+	Hashes []common.Hash `serialize:"true"`
+}
+
+func (t TrieNodesRequest) String() string {
+	hashStrs := make([]string, len(t.Hashes))
+	for i, hash := range t.Hashes {
+		hashStrs[i] = hash.String()
+	}
+	return fmt.Sprintf("TrieNodesRequest(Hashes=%s)", strings.Join(hashStrs, ", "))
+}
+
+func (t TrieNodesRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleTrieNodesRequest(ctx, nodeID, requestID, t)
+}
+
+func NewTrieNodesRequest(hashes []common.Hash) TrieNodesRequest {
+	return TrieNodesRequest{
+		Hashes: hashes,
+	}
+}
+
+// TrieNodesResponse is a response to a TrieNodesRequest
+// crypto.Keccak256Hash of each element in Data is expected to equal
+// the corresponding element in TrieNodesRequest.Hashes
+// handler: handlers.TrieNodesRequestHandler
+type TrieNodesResponse struct {
+	Data [][]byte `serialize:"true"`
+}
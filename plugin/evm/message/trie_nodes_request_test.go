@@ -0,0 +1,50 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshalTrieNodesRequest asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalTrieNodesRequest(t *testing.T) {
+	trieNodesRequest := TrieNodesRequest{
+		Hashes: []common.Hash{common.BytesToHash([]byte("some nodes pls"))},
+	}
+
+	base64TrieNodesRequest := "AAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAc29tZSBub2RlcyBwbHM="
+
+	trieNodesRequestBytes, err := Codec.Marshal(Version, trieNodesRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, base64TrieNodesRequest, base64.StdEncoding.EncodeToString(trieNodesRequestBytes))
+
+	var r TrieNodesRequest
+	_, err = Codec.Unmarshal(trieNodesRequestBytes, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, trieNodesRequest.Hashes, r.Hashes)
+}
+
+// TestMarshalTrieNodesResponse asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalTrieNodesResponse(t *testing.T) {
+	trieNodesResponse := TrieNodesResponse{
+		Data: [][]byte{{0xc2, 0x80, 0x80}, {0xc3, 0x82, 0x01, 0x02}},
+	}
+
+	base64TrieNodesResponse := "AAAAAAACAAAAA8KAgAAAAATDggEC"
+
+	trieNodesResponseBytes, err := Codec.Marshal(Version, trieNodesResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, base64TrieNodesResponse, base64.StdEncoding.EncodeToString(trieNodesResponseBytes))
+
+	var r TrieNodesResponse
+	_, err = Codec.Unmarshal(trieNodesResponseBytes, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, trieNodesResponse.Data, r.Data)
+}
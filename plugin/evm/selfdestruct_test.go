@@ -0,0 +1,80 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that once EIP-6780 is active, SELFDESTRUCT only sends the balance of pre-existing
+// contracts to the beneficiary, while contracts created in the same transaction are deleted.
+func TestSelfdestructEIP6780(t *testing.T) {
+	// PUSH1 0 SELFDESTRUCT
+	code := common.FromHex("0x6000ff")
+	contract := common.HexToAddress("0x0400000000000000000000000000000000000000")
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.EIP6780Timestamp = big.NewInt(0)
+	genesis.Alloc[contract] = core.GenesisAccount{
+		Code:    code,
+		Storage: map[common.Hash]common.Hash{{1}: {1}},
+		Balance: common.Big1,
+	}
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	signer := types.LatestSigner(vm.chainConfig)
+	call, err := types.SignNewTx(testKeys[0], signer, &types.DynamicFeeTx{
+		ChainID:   vm.chainConfig.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(testMinGasPrice),
+		Gas:       100_000,
+		To:        &contract,
+		Value:     common.Big0,
+	})
+	require.NoError(t, err)
+	create, err := types.SignNewTx(testKeys[0], signer, &types.DynamicFeeTx{
+		ChainID:   vm.chainConfig.ChainID,
+		Nonce:     1,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(testMinGasPrice),
+		Gas:       100_000,
+		Value:     common.Big1,
+		Data:      code,
+	})
+	require.NoError(t, err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{call, create}) {
+		require.NoError(t, err)
+	}
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(t, block.Transactions(), 2)
+	for _, receipt := range vm.blockChain.GetReceiptsByHash(block.Hash()) {
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	}
+
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Equal(t, code, state.GetCode(contract))
+	require.Equal(t, common.Hash{1}, state.GetState(contract, common.Hash{1}))
+	require.Zero(t, state.GetBalance(contract).Sign())
+
+	created := crypto.CreateAddress(testEthAddrs[0], 1)
+	require.False(t, state.Exist(created))
+}
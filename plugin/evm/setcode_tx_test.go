@@ -0,0 +1,155 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/vms/components/chain"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/internal/ethapi"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that set code transactions delegate the code of their authorities, which then execute
+// the code of the delegation target in their own context, and that the mempool limits the
+// transactions of delegated accounts.
+func TestSetCodeTxDelegation(t *testing.T) {
+	// PUSH1 0x2a PUSH1 0 SSTORE STOP
+	contract := common.HexToAddress("0x0400000000000000000000000000000000000000")
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.BlobTxTimestamp = big.NewInt(0)
+	genesis.Config.SetCodeTxTimestamp = big.NewInt(0)
+	genesis.Alloc[contract] = core.GenesisAccount{Code: common.FromHex("0x602a60005500"), Balance: common.Big0}
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	auth, err := types.SignSetCode(testKeys[1], types.SetCodeAuthorization{
+		ChainID: vm.chainConfig.ChainID,
+		Address: contract,
+		Nonce:   0,
+	})
+	require.NoError(t, err)
+	signer := types.LatestSigner(vm.chainConfig)
+	tx, err := types.SignNewTx(testKeys[0], signer, &types.SetCodeTx{
+		ChainID:   vm.chainConfig.ChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(testMinGasPrice),
+		Gas:       100_000,
+		To:        testEthAddrs[1],
+		Value:     common.Big0,
+		AuthList:  []types.SetCodeAuthorization{auth},
+	})
+	require.NoError(t, err)
+	require.NoError(t, vm.txPool.AddRemotesSync([]*types.Transaction{tx})[0])
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Equal(t, block.Hash(), (<-newTxPoolHeadChan).Head.Hash())
+	require.Len(t, block.Transactions(), 1)
+	receipts := vm.blockChain.GetReceiptsByHash(block.Hash())
+	require.Equal(t, types.ReceiptStatusSuccessful, receipts[0].Status)
+
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Equal(t, types.AddressToDelegation(contract), state.GetCode(testEthAddrs[1]))
+	require.Equal(t, uint64(1), state.GetNonce(testEthAddrs[1]))
+	require.Equal(t, common.BigToHash(big.NewInt(0x2a)), state.GetState(testEthAddrs[1], common.Hash{}))
+
+	rpcTx, err := ethapi.NewTransactionAPI(vm.eth.APIBackend, nil).GetTransactionByHash(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, []types.SetCodeAuthorization{auth}, rpcTx.AuthorizationList)
+
+	// The delegated account may only have a single transaction in the mempool
+	txs := make([]*types.Transaction, 2)
+	for i := range txs {
+		txs[i], err = types.SignNewTx(testKeys[1], signer, &types.DynamicFeeTx{
+			ChainID:   vm.chainConfig.ChainID,
+			Nonce:     uint64(i + 1),
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(testMinGasPrice),
+			Gas:       100_000,
+			To:        &testEthAddrs[0],
+			Value:     common.Big1,
+		})
+		require.NoError(t, err)
+	}
+	errs := vm.txPool.AddRemotesSync(txs)
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], core.ErrInflightTxLimitReached)
+}
+
+// Test that the authorizations of existing authorities are charged the reduced cost of EIP-7702,
+// while those of new authorities are charged the new account cost.
+func TestSetCodeTxAuthorizationGas(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.BlobTxTimestamp = big.NewInt(0)
+	genesis.Config.SetCodeTxTimestamp = big.NewInt(0)
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	newKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSigner(vm.chainConfig)
+	tests := []struct {
+		name        string
+		authority   *ecdsa.PrivateKey
+		expectedGas uint64
+	}{
+		{"existing authority", testKeys[1], params.TxGas + params.TxAuthTupleGas},
+		{"new authority", newKey, params.TxGas + params.CallNewAccountGas},
+	}
+	txs := make([]*types.Transaction, len(tests))
+	for i, test := range tests {
+		auth, err := types.SignSetCode(test.authority, types.SetCodeAuthorization{
+			ChainID: vm.chainConfig.ChainID,
+			Address: common.HexToAddress("0x0400000000000000000000000000000000000000"),
+			Nonce:   0,
+		})
+		require.NoError(t, err)
+		txs[i], err = types.SignNewTx(testKeys[0], signer, &types.SetCodeTx{
+			ChainID:   vm.chainConfig.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(testMinGasPrice),
+			Gas:       100_000,
+			To:        testEthAddrs[0],
+			Value:     common.Big0,
+			AuthList:  []types.SetCodeAuthorization{auth},
+		})
+		require.NoError(t, err)
+	}
+	for _, err := range vm.txPool.AddRemotesSync(txs) {
+		require.NoError(t, err)
+	}
+
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	receipts := vm.blockChain.GetReceiptsByHash(block.Hash())
+	require.Len(t, receipts, len(tests))
+	for i, test := range tests {
+		require.Equal(t, types.ReceiptStatusSuccessful, receipts[i].Status, test.name)
+		require.Equal(t, test.expectedGas, receipts[i].GasUsed, test.name)
+	}
+}
@@ -0,0 +1,211 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var errNotValidator = errors.New("node is not a validator of the subnet")
+
+// peerUptime is the connection history of a peer since the VM started.
+type peerUptime struct {
+	connectedSince time.Time     // zero if the peer is disconnected
+	connectedFor   time.Duration // total duration of the previous connections
+	version        string
+}
+
+// uptimeTracker tracks the time the peers of the node have been connected to it since the
+// VM started, to report the uptime of the validators as seen by this node.
+type uptimeTracker struct {
+	lock      sync.Mutex
+	clock     *mockable.Clock
+	startTime time.Time
+	peers     map[ids.NodeID]*peerUptime
+}
+
+func newUptimeTracker(clock *mockable.Clock) *uptimeTracker {
+	return &uptimeTracker{
+		clock:     clock,
+		startTime: clock.Time(),
+		peers:     make(map[ids.NodeID]*peerUptime),
+	}
+}
+
+// connected records that [nodeID] running [nodeVersion] connected to this node.
+func (u *uptimeTracker) connected(nodeID ids.NodeID, nodeVersion *version.Application) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	peer, ok := u.peers[nodeID]
+	if !ok {
+		peer = &peerUptime{}
+		u.peers[nodeID] = peer
+	}
+	if peer.connectedSince.IsZero() {
+		peer.connectedSince = u.clock.Time()
+	}
+	if nodeVersion != nil {
+		peer.version = nodeVersion.String()
+	}
+}
+
+// disconnected records that [nodeID] disconnected from this node.
+func (u *uptimeTracker) disconnected(nodeID ids.NodeID) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	peer, ok := u.peers[nodeID]
+	if !ok || peer.connectedSince.IsZero() {
+		return
+	}
+	peer.connectedFor += u.clock.Time().Sub(peer.connectedSince)
+	peer.connectedSince = time.Time{}
+}
+
+// uptime returns whether [nodeID] is connected, since when, the percentage of the time since the VM
+// started it was connected and its version.
+func (u *uptimeTracker) uptime(nodeID ids.NodeID) (bool, time.Time, float64, string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	peer, ok := u.peers[nodeID]
+	if !ok {
+		return false, time.Time{}, 0, ""
+	}
+	now := u.clock.Time()
+	connectedFor := peer.connectedFor
+	if !peer.connectedSince.IsZero() {
+		connectedFor += now.Sub(peer.connectedSince)
+	}
+	elapsed := now.Sub(u.startTime)
+	if elapsed <= 0 {
+		return !peer.connectedSince.IsZero(), peer.connectedSince, 100, peer.version
+	}
+	return !peer.connectedSince.IsZero(), peer.connectedSince, 100 * float64(connectedFor) / float64(elapsed), peer.version
+}
+
+// Connected records the connection of [nodeID] for the uptime reported by the subnet API
+// before adding it to the peers of the network.
+func (vm *VM) Connected(ctx context.Context, nodeID ids.NodeID, nodeVersion *version.Application) error {
+	vm.uptime.connected(nodeID, nodeVersion)
+	return vm.Network.Connected(ctx, nodeID, nodeVersion)
+}
+
+// Disconnected records the disconnection of [nodeID] for the uptime reported by the subnet API
+// before removing it from the peers of the network.
+func (vm *VM) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
+	vm.uptime.disconnected(nodeID)
+	return vm.Network.Disconnected(ctx, nodeID)
+}
+
+// SubnetValidator is a validator of the subnet and its status as seen by this node.
+type SubnetValidator struct {
+	NodeID    ids.NodeID    `json:"nodeID"`
+	Weight    uint64        `json:"weight"`
+	PublicKey hexutil.Bytes `json:"publicKey,omitempty"`
+	// Self is true if the validator is this node, which is always connected.
+	Self      bool   `json:"self"`
+	Connected bool   `json:"connected"`
+	Version   string `json:"version,omitempty"`
+	// ConnectedSince is the time the validator connected to this node, if it is connected.
+	ConnectedSince *time.Time `json:"connectedSince,omitempty"`
+	// Uptime is the percentage of the time since this node started that the validator was
+	// connected to it. It is not the uptime measured by the P-chain for rewards.
+	Uptime float64 `json:"uptime"`
+}
+
+// SubnetValidators is the validator set of the subnet at a P-chain height.
+type SubnetValidators struct {
+	SubnetID     ids.ID             `json:"subnetID"`
+	PChainHeight hexutil.Uint64     `json:"pChainHeight"`
+	TotalWeight  uint64             `json:"totalWeight"`
+	Validators   []*SubnetValidator `json:"validators"`
+}
+
+// SubnetAPI reports the validator set of the subnet validating the chain, with the weights
+// and the connection status of the validators as seen by this node.
+type SubnetAPI struct{ vm *VM }
+
+// Validators returns the current validator set of the subnet, sorted by decreasing weight.
+func (api *SubnetAPI) Validators(ctx context.Context) (*SubnetValidators, error) {
+	validatorState := api.vm.ctx.ValidatorState
+	if validatorState == nil {
+		return nil, precompile.ErrValidatorStateNotFound
+	}
+	height, err := validatorState.GetCurrentHeight(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current P-chain height: %w", err)
+	}
+	validatorSet, err := validatorState.GetValidatorSet(ctx, height, api.vm.ctx.SubnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the validator set at P-chain height %d: %w", height, err)
+	}
+
+	result := &SubnetValidators{
+		SubnetID:     api.vm.ctx.SubnetID,
+		PChainHeight: hexutil.Uint64(height),
+		Validators:   make([]*SubnetValidator, 0, len(validatorSet)),
+	}
+	for nodeID, validator := range validatorSet {
+		result.TotalWeight += validator.Weight
+		result.Validators = append(result.Validators, api.validator(nodeID, validator.Weight, validator.PublicKey))
+	}
+	sort.Slice(result.Validators, func(i, j int) bool {
+		if result.Validators[i].Weight != result.Validators[j].Weight {
+			return result.Validators[i].Weight > result.Validators[j].Weight
+		}
+		return result.Validators[i].NodeID.String() < result.Validators[j].NodeID.String()
+	})
+	return result, nil
+}
+
+// Validator returns the validator of the subnet with [nodeID].
+func (api *SubnetAPI) Validator(ctx context.Context, nodeID ids.NodeID) (*SubnetValidator, error) {
+	validators, err := api.Validators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, validator := range validators.Validators {
+		if validator.NodeID == nodeID {
+			return validator, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", errNotValidator, nodeID)
+}
+
+// validator returns the status of the validator with [nodeID] as seen by this node.
+func (api *SubnetAPI) validator(nodeID ids.NodeID, weight uint64, publicKey *bls.PublicKey) *SubnetValidator {
+	validator := &SubnetValidator{NodeID: nodeID, Weight: weight}
+	if publicKey != nil {
+		validator.PublicKey = bls.PublicKeyToBytes(publicKey)
+	}
+	if nodeID == api.vm.ctx.NodeID {
+		validator.Self = true
+		validator.Connected = true
+		validator.Version = Version
+		validator.Uptime = 100
+		return validator
+	}
+	connected, since, uptime, nodeVersion := api.vm.uptime.uptime(nodeID)
+	validator.Connected = connected
+	validator.Version = nodeVersion
+	validator.Uptime = uptime
+	if connected {
+		validator.ConnectedSince = &since
+	}
+	return validator
+}
@@ -0,0 +1,105 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUptimeTracker(t *testing.T) {
+	var clock mockable.Clock
+	clock.Set(time.Unix(1000, 0))
+	tracker := newUptimeTracker(&clock)
+	nodeID := ids.GenerateTestNodeID()
+	nodeVersion := &version.Application{Major: 1, Minor: 9, Patch: 6}
+
+	connected, _, uptime, _ := tracker.uptime(nodeID)
+	require.False(t, connected)
+	require.Zero(t, uptime)
+
+	// Connected for 10s out of 40s
+	clock.Set(time.Unix(1010, 0))
+	tracker.connected(nodeID, nodeVersion)
+	clock.Set(time.Unix(1020, 0))
+	tracker.disconnected(nodeID)
+	tracker.disconnected(nodeID)
+	clock.Set(time.Unix(1040, 0))
+	connected, _, uptime, peerVersion := tracker.uptime(nodeID)
+	require.False(t, connected)
+	require.Equal(t, 25.0, uptime)
+	require.Equal(t, nodeVersion.String(), peerVersion)
+
+	// Connected for another 20s out of 60s
+	tracker.connected(nodeID, nodeVersion)
+	clock.Set(time.Unix(1060, 0))
+	connected, since, uptime, _ := tracker.uptime(nodeID)
+	require.True(t, connected)
+	require.Equal(t, time.Unix(1040, 0), since)
+	require.Equal(t, 50.0, uptime)
+}
+
+func TestSubnetAPI(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, `{"subnet-api-enabled": true}`, "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	peerID := ids.GenerateTestNodeID()
+	disconnectedID := ids.GenerateTestNodeID()
+	vm.ctx.SubnetID = ids.GenerateTestID()
+	vm.ctx.ValidatorState = &validators.TestState{
+		GetCurrentHeightF: func(context.Context) (uint64, error) { return 42, nil },
+		GetValidatorSetF: func(_ context.Context, height uint64, subnetID ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+			require.Equal(t, uint64(42), height)
+			require.Equal(t, vm.ctx.SubnetID, subnetID)
+			return map[ids.NodeID]*validators.GetValidatorOutput{
+				vm.ctx.NodeID:  {NodeID: vm.ctx.NodeID, Weight: 20},
+				peerID:         {NodeID: peerID, Weight: 30, PublicKey: bls.PublicFromSecretKey(sk)},
+				disconnectedID: {NodeID: disconnectedID, Weight: 10},
+			}, nil
+		},
+	}
+	require.NoError(t, vm.Connected(context.Background(), peerID, &version.Application{Major: 1, Minor: 9, Patch: 6}))
+
+	api := &SubnetAPI{vm}
+	validatorSet, err := api.Validators(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, vm.ctx.SubnetID, validatorSet.SubnetID)
+	require.EqualValues(t, 42, validatorSet.PChainHeight)
+	require.EqualValues(t, 60, validatorSet.TotalWeight)
+	require.Len(t, validatorSet.Validators, 3)
+
+	peer := validatorSet.Validators[0]
+	require.Equal(t, peerID, peer.NodeID)
+	require.Equal(t, bls.PublicKeyToBytes(bls.PublicFromSecretKey(sk)), []byte(peer.PublicKey))
+	require.True(t, peer.Connected)
+	require.NotNil(t, peer.ConnectedSince)
+	require.Equal(t, "avalanche/1.9.6", peer.Version)
+
+	self := validatorSet.Validators[1]
+	require.Equal(t, vm.ctx.NodeID, self.NodeID)
+	require.True(t, self.Self)
+	require.True(t, self.Connected)
+	require.Equal(t, 100.0, self.Uptime)
+
+	disconnected, err := api.Validator(context.Background(), disconnectedID)
+	require.NoError(t, err)
+	require.False(t, disconnected.Connected)
+	require.Nil(t, disconnected.ConnectedSince)
+	require.Zero(t, disconnected.Uptime)
+
+	_, err = api.Validator(context.Background(), ids.GenerateTestNodeID())
+	require.ErrorIs(t, err, errNotValidator)
+}
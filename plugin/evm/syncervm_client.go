@@ -69,6 +69,20 @@ type stateSyncerClient struct {
 	// State Sync results
 	syncSummary  message.SyncSummary
 	stateSyncErr error
+
+	// [statusLock] protects the status of the ongoing sync reported by Status
+	statusLock  sync.RWMutex
+	syncing     bool
+	stateSyncer interface{ Progress() statesync.Progress }
+}
+
+// StateSyncStatus is the status of the state sync of the VM. The summary and progress are only
+// set while the VM is syncing.
+type StateSyncStatus struct {
+	Syncing bool        `json:"syncing"`
+	Height  uint64      `json:"height"` // height of the summary being synced to
+	Root    common.Hash `json:"root"`   // state root of the summary being synced to
+	statesync.Progress
 }
 
 func NewStateSyncClient(config *stateSyncClientConfig) StateSyncClient {
@@ -87,6 +101,7 @@ type StateSyncClient interface {
 	StateSyncClearOngoingSummary() error
 	Shutdown() error
 	Error() error
+	Status() StateSyncStatus
 }
 
 // Syncer represents a step in state sync,
@@ -175,17 +190,31 @@ func (client *stateSyncerClient) acceptSyncSummary(proposedSummary message.SyncS
 			return block.StateSyncSkipped, nil
 		}
 
-		// Wipe the snapshot completely if we are not resuming from an existing sync, so that we do not
-		// use a corrupted snapshot.
-		// Note: this assumes that when the node is started with state sync disabled, the in-progress state
-		// sync marker will be wiped, so we do not accidentally resume progress from an incorrect version
-		// of the snapshot. (if switching between versions that come before this change and back this could
-		// lead to the snapshot not being cleaned up correctly)
-		<-snapshot.WipeSnapshot(client.chaindb, true)
-		// Reset the snapshot generator here so that when state sync completes, snapshots will not attempt to read an
-		// invalid generator.
-		// Note: this must be called after WipeSnapshot is called so that we do not invalidate a partially generated snapshot.
-		snapshot.ResetSnapshotGeneration(client.chaindb)
+		if client.resumableSummary.BlockHash != (common.Hash{}) {
+			// The sync target moved to a newer summary: keep the progress of the interrupted sync.
+			// The leafs synced from the previous summary are kept in the snapshot and the state
+			// trie is healed once the sync completes.
+			log.Info("resuming interrupted state sync to newer summary", "previous", client.resumableSummary, "summary", proposedSummary)
+		} else {
+			// Wipe the snapshot completely if we are not resuming from an existing sync, so that we do not
+			// use a corrupted snapshot.
+			// Note: this assumes that when the node is started with state sync disabled, the in-progress state
+			// sync marker will be wiped, so we do not accidentally resume progress from an incorrect version
+			// of the snapshot. (if switching between versions that come before this change and back this could
+			// lead to the snapshot not being cleaned up correctly)
+			<-snapshot.WipeSnapshot(client.chaindb, true)
+			// Reset the snapshot generator here so that when state sync completes, snapshots will not attempt to read an
+			// invalid generator.
+			// Note: this must be called after WipeSnapshot is called so that we do not invalidate a partially generated snapshot.
+			snapshot.ResetSnapshotGeneration(client.chaindb)
+			// Clear the progress markers of any previous sync, since the leafs it synced were wiped with the snapshot.
+			if err := rawdb.ClearAllSyncStorageTries(client.chaindb); err != nil {
+				return block.StateSyncSkipped, fmt.Errorf("failed to clear storage tries to sync: %w", err)
+			}
+			if err := rawdb.ClearAllSyncSegments(client.chaindb); err != nil {
+				return block.StateSyncSkipped, fmt.Errorf("failed to clear sync segments: %w", err)
+			}
+		}
 	}
 	client.syncSummary = proposedSummary
 
@@ -205,9 +234,11 @@ func (client *stateSyncerClient) acceptSyncSummary(proposedSummary message.SyncS
 	ctx, cancel := context.WithCancel(context.Background())
 	client.cancel = cancel
 	client.wg.Add(1) // track the state sync goroutine so we can wait for it on shutdown
+	client.setSyncing(true)
 	go func() {
 		defer client.wg.Done()
 		defer cancel()
+		defer client.setSyncing(false)
 
 		if err := client.stateSync(ctx); err != nil {
 			client.stateSyncErr = err
@@ -287,6 +318,9 @@ func (client *stateSyncerClient) syncStateTrie(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	client.statusLock.Lock()
+	client.stateSyncer = evmSyncer
+	client.statusLock.Unlock()
 	if err := evmSyncer.Start(ctx); err != nil {
 		return err
 	}
@@ -371,3 +405,33 @@ func (client *stateSyncerClient) updateVMMarkers() error {
 
 // Error returns a non-nil error if one occurred during the sync.
 func (client *stateSyncerClient) Error() error { return client.stateSyncErr }
+
+// Status returns the status of the state sync, including the progress of the sync of the state
+// trie once it has started.
+func (client *stateSyncerClient) Status() StateSyncStatus {
+	client.statusLock.RLock()
+	defer client.statusLock.RUnlock()
+
+	if !client.syncing {
+		return StateSyncStatus{}
+	}
+	status := StateSyncStatus{
+		Syncing: true,
+		Height:  client.syncSummary.BlockNumber,
+		Root:    client.syncSummary.BlockRoot,
+	}
+	if client.stateSyncer != nil {
+		status.Progress = client.stateSyncer.Progress()
+	}
+	return status
+}
+
+func (client *stateSyncerClient) setSyncing(syncing bool) {
+	client.statusLock.Lock()
+	defer client.statusLock.Unlock()
+
+	client.syncing = syncing
+	if !syncing {
+		client.stateSyncer = nil
+	}
+}
@@ -444,7 +444,7 @@ func testSyncerVM(t *testing.T, vmSetup *syncVMSetup, test syncTest) {
 
 	// check we can transition to [NormalOp] state and continue to process blocks.
 	assert.NoError(t, syncerVM.SetState(context.Background(), snow.NormalOp))
-	assert.True(t, syncerVM.bootstrapped)
+	assert.True(t, syncerVM.bootstrapped.GetValue())
 
 	// Generate blocks after we have entered normal consensus as well
 	generateAndAcceptBlocks(t, syncerVM, blocksToBuild, func(_ int, gen *core.BlockGen) {
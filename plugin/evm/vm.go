@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,21 +24,29 @@ import (
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
+	corevm "github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth"
 	"github.com/ava-labs/subnet-evm/eth/ethconfig"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/ethdb/pebble"
 	"github.com/ava-labs/subnet-evm/metrics"
 	subnetEVMPrometheus "github.com/ava-labs/subnet-evm/metrics/prometheus"
 	"github.com/ava-labs/subnet-evm/miner"
 	"github.com/ava-labs/subnet-evm/node"
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/peer"
+	"github.com/ava-labs/subnet-evm/plugin/evm/alert"
+	"github.com/ava-labs/subnet-evm/plugin/evm/faucet"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/rpc"
 	statesyncclient "github.com/ava-labs/subnet-evm/sync/client"
 	"github.com/ava-labs/subnet-evm/sync/client/stats"
 	"github.com/ava-labs/subnet-evm/sync/handlers"
 	handlerstats "github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ava-labs/subnet-evm/utils"
 
 	// Force-load tracer engine to trigger registration
 	//
@@ -47,8 +56,12 @@ import (
 	_ "github.com/ava-labs/subnet-evm/eth/tracers/native"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	avalancheRPC "github.com/gorilla/rpc/v2"
 
@@ -62,6 +75,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/trace"
 	cjson "github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/perms"
 	"github.com/ava-labs/avalanchego/utils/profiler"
@@ -70,6 +84,7 @@ import (
 
 	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
 
+	avalancheUtils "github.com/ava-labs/avalanchego/utils"
 	avalancheJSON "github.com/ava-labs/avalanchego/utils/json"
 )
 
@@ -87,6 +102,9 @@ const (
 	missingCacheSize    = 50
 	unverifiedCacheSize = 50
 
+	// Max number of blocks replayed by a dry run of an upgrade config
+	maxDryRunBlocks = 1024
+
 	// Prefixes for metrics gatherers
 	ethMetricsPrefix        = "eth"
 	chainStateMetricsPrefix = "chain_state"
@@ -97,6 +115,8 @@ const (
 	adminEndpoint  = "/admin"
 	ethRPCEndpoint = "/rpc"
 	ethWSEndpoint  = "/ws"
+	healthEndpoint = "/health"
+	faucetEndpoint = "/faucet"
 )
 
 var (
@@ -105,6 +125,9 @@ var (
 	acceptedPrefix  = []byte("snowman_accepted")
 	metadataPrefix  = []byte("metadata")
 	ethDBPrefix     = []byte("ethdb")
+
+	// Key of the upgrade config applied by updateUpgradeConfig in the metadata database.
+	appliedUpgradeConfigKey = []byte("applied_upgrade_config")
 )
 
 var (
@@ -115,6 +138,13 @@ var (
 	errUnclesUnsupported        = errors.New("uncles unsupported")
 	errNilBaseFeeSubnetEVM      = errors.New("nil base fee is invalid after subnetEVM")
 	errNilBlockGasCostSubnetEVM = errors.New("nil blockGasCost is invalid after subnetEVM")
+	errPChainHeightTooHigh      = errors.New("P-chain height is greater than the proposervm P-chain height")
+	errNetworkUpgradesChanged   = errors.New("network upgrades cannot be changed without restarting the node")
+	errNoBlocksToReplay         = errors.New("no accepted blocks to replay")
+	errTxNotInPool              = errors.New("transaction not in tx pool")
+	errInvalidMinGasPrice       = errors.New("min gas price must be non-negative")
+	errMissingExportFile        = errors.New("missing state export file")
+	errMissingChainExportFile   = errors.New("missing chain export file")
 )
 
 var originalStderr *os.File
@@ -172,7 +202,9 @@ type VM struct {
 	metadataDB database.Database
 
 	// [chaindb] is the database supplied to the Ethereum backend
-	chaindb Database
+	chaindb ethdb.Database
+	// [chainKVStore] is the key-value store backing [chaindb]
+	chainKVStore ethdb.KeyValueStore
 
 	// [acceptedBlockDB] is the database to store the last accepted
 	// block.
@@ -185,6 +217,8 @@ type VM struct {
 	builder *blockBuilder
 
 	gossiper Gossiper
+	// [txGossipTracker] tracks the txs gossiped by each peer
+	txGossipTracker *txGossipTracker
 
 	clock mockable.Clock
 
@@ -201,7 +235,30 @@ type VM struct {
 	// Metrics
 	multiGatherer avalanchegoMetrics.MultiGatherer
 
-	bootstrapped bool
+	// [tracer] exports the spans recorded by the tracing package
+	tracer trace.Tracer
+
+	// [alerts] delivers the alerts on consensus-critical anomalies to the hooks,
+	// including the [alertHooks] added before the VM is initialized
+	alerts     *alert.Dispatcher
+	alertHooks []alert.Hook
+
+	// [faucet] funds the addresses requesting test tokens if enabled in the config,
+	// checking the requests with the [faucetHooks] added before it is initialized
+	faucet      *faucet.Faucet
+	faucetHooks []faucet.Hook
+
+	// [finality] tracks the divergence of the preferred chain from the accepted chain
+	finality *finalityTracker
+	// [uptime] tracks the connection of the peers for the validator uptime reported by the subnet API
+	uptime *uptimeTracker
+	// [processing] holds the headers of the blocks verified by consensus that have not been decided yet,
+	// against which updateUpgradeConfig checks the upgrades. Accessed under the ctx lock.
+	processing map[common.Hash]*types.Header
+	// [upgradeBytesHash] is the hash of the upgrade bytes the VM was initialized with
+	upgradeBytesHash common.Hash
+
+	bootstrapped avalancheUtils.AtomicBool
 
 	logger SubnetEVMLogger
 	// State sync server and client
@@ -234,7 +291,7 @@ func (vm *VM) Initialize(
 ) error {
 	vm.config.SetDefaults()
 	if len(configBytes) > 0 {
-		if err := json.Unmarshal(configBytes, &vm.config); err != nil {
+		if err := vm.config.unmarshal(configBytes); err != nil {
 			return fmt.Errorf("failed to unmarshal config %s: %w", string(configBytes), err)
 		}
 	}
@@ -265,16 +322,40 @@ func (vm *VM) Initialize(
 
 	// Enable debug-level metrics that might impact runtime performance
 	metrics.EnabledExpensive = vm.config.MetricsExpensiveEnabled
+	// Size the cache of precompile results shared by all EVMs
+	corevm.SetPrecompileResultCacheSize(vm.config.PrecompileResultCacheSize)
+	if err := vm.initializeTracer(); err != nil {
+		return err
+	}
+	vm.initializeAlerts()
 
 	vm.toEngine = toEngine
 	vm.shutdownChan = make(chan struct{}, 1)
+	vm.txGossipTracker = newTxGossipTracker()
+	vm.finality = newFinalityTracker()
+	vm.uptime = newUptimeTracker(&vm.clock)
+	vm.processing = make(map[common.Hash]*types.Header)
 	baseDB := dbManager.Current().Database
 	// Use NewNested rather than New so that the structure of the database
 	// remains the same regardless of the provided baseDB type.
-	vm.chaindb = Database{prefixdb.NewNested(ethDBPrefix, baseDB)}
+	if err := vm.initializeChainDB(Database{prefixdb.NewNested(ethDBPrefix, baseDB)}); err != nil {
+		return err
+	}
 	vm.db = versiondb.New(baseDB)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
 	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
+
+	// Register the precompiles provided by plugins before parsing the genesis,
+	// so that their configs are recognized in the chain config.
+	if err := precompile.LoadPlugins(vm.config.PrecompilePlugins); err != nil {
+		return err
+	}
+	for _, key := range vm.config.DisabledPrecompileAPIs {
+		if _, ok := precompile.GetRegisteredModule(key); !ok {
+			return fmt.Errorf("cannot disable APIs of unknown precompile %q", key)
+		}
+	}
+
 	g := new(core.Genesis)
 	if err := json.Unmarshal(genesisBytes, g); err != nil {
 		return err
@@ -312,7 +393,9 @@ func (vm *VM) Initialize(
 	vm.ethConfig.RPCEVMTimeout = vm.config.APIMaxDuration.Duration
 	vm.ethConfig.RPCTxFeeCap = vm.config.RPCTxFeeCap
 
-	vm.ethConfig.TxPool.Locals = vm.config.PriorityRegossipAddresses
+	vm.ethConfig.TxPool.Locals = make([]common.Address, 0, len(vm.config.PriorityRegossipAddresses)+len(vm.config.TxPoolLocals))
+	vm.ethConfig.TxPool.Locals = append(vm.ethConfig.TxPool.Locals, vm.config.PriorityRegossipAddresses...)
+	vm.ethConfig.TxPool.Locals = append(vm.ethConfig.TxPool.Locals, vm.config.TxPoolLocals...)
 	vm.ethConfig.TxPool.NoLocals = !vm.config.LocalTxsEnabled
 	vm.ethConfig.TxPool.Journal = vm.config.TxPoolJournal
 	vm.ethConfig.TxPool.Rejournal = vm.config.TxPoolRejournal.Duration
@@ -322,8 +405,13 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TxPool.GlobalSlots = vm.config.TxPoolGlobalSlots
 	vm.ethConfig.TxPool.AccountQueue = vm.config.TxPoolAccountQueue
 	vm.ethConfig.TxPool.GlobalQueue = vm.config.TxPoolGlobalQueue
+	vm.ethConfig.TxPool.Lifetime = vm.config.TxPoolLifetime.Duration
+	vm.ethConfig.TxPool.MaxTxAge = vm.config.TxPoolMaxTxAge.Duration
+	vm.ethConfig.TxPool.MaxNonceGap = vm.config.TxPoolMaxNonceGap
+	vm.ethConfig.TxPool.MinGasPrice = vm.config.TxPoolMinGasPrice
 
 	vm.ethConfig.AllowUnfinalizedQueries = vm.config.AllowUnfinalizedQueries
+	vm.ethConfig.DisabledPrecompileAPIs = vm.config.DisabledPrecompileAPIs
 	vm.ethConfig.AllowUnprotectedTxs = vm.config.AllowUnprotectedTxs
 	vm.ethConfig.AllowUnprotectedTxHashes = vm.config.AllowUnprotectedTxHashes
 	vm.ethConfig.Preimages = vm.config.Preimages
@@ -334,16 +422,29 @@ func (vm *VM) Initialize(
 	vm.ethConfig.TrieDirtyCache = vm.config.TrieDirtyCache
 	vm.ethConfig.TrieDirtyCommitTarget = vm.config.TrieDirtyCommitTarget
 	vm.ethConfig.SnapshotCache = vm.config.SnapshotCache
+	vm.ethConfig.TriePrefetcherParallelism = vm.config.TriePrefetcherParallelism
 	vm.ethConfig.AcceptorQueueLimit = vm.config.AcceptorQueueLimit
 	vm.ethConfig.PopulateMissingTries = vm.config.PopulateMissingTries
 	vm.ethConfig.PopulateMissingTriesParallelism = vm.config.PopulateMissingTriesParallelism
 	vm.ethConfig.AllowMissingTries = vm.config.AllowMissingTries
 	vm.ethConfig.SnapshotDelayInit = vm.config.StateSyncEnabled
 	vm.ethConfig.SnapshotAsync = vm.config.SnapshotAsync
+	vm.ethConfig.BlockPrefetch = vm.config.BlockPrefetch
 	vm.ethConfig.SnapshotVerify = vm.config.SnapshotVerify
 	vm.ethConfig.OfflinePruning = vm.config.OfflinePruning
 	vm.ethConfig.OfflinePruningBloomFilterSize = vm.config.OfflinePruningBloomFilterSize
 	vm.ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
+	vm.ethConfig.OnlinePruning = vm.config.OnlinePruning
+	vm.ethConfig.OnlinePruningRetention = vm.config.OnlinePruningRetention
+	vm.ethConfig.OnlinePruningBloomFilterSize = vm.config.OnlinePruningBloomFilterSize
+	vm.ethConfig.AncientStoreThreshold = vm.config.AncientStoreThreshold
+	vm.ethConfig.StateScheme = vm.config.StateScheme
+	vm.ethConfig.StateExportInterval = vm.config.StateExportInterval
+	vm.ethConfig.StateExportDirectory = vm.config.StateExportDirectory
+	vm.ethConfig.StateDiffArchive = vm.config.StateDiffArchive
+	vm.ethConfig.StateDiffRetention = vm.config.StateDiffRetention
+	vm.ethConfig.TxLookupLimit = vm.config.TxLookupLimit
+	vm.ethConfig.HistoryRetention = vm.config.HistoryRetention
 	vm.ethConfig.CommitInterval = vm.config.CommitInterval
 	vm.ethConfig.SkipUpgradeCheck = vm.config.SkipUpgradeCheck
 	vm.ethConfig.AcceptedCacheSize = vm.config.AcceptedCacheSize
@@ -356,6 +457,14 @@ func (vm *VM) Initialize(
 		}
 	}
 
+	// Create directory for state exports
+	if len(vm.ethConfig.StateExportDirectory) != 0 {
+		if err := os.MkdirAll(vm.ethConfig.StateExportDirectory, perms.ReadWriteExecute); err != nil {
+			log.Error("failed to create state export directory", "error", err)
+			return err
+		}
+	}
+
 	// Handle custom fee recipient
 	if common.IsHexAddress(vm.config.FeeRecipient) {
 		address := common.HexToAddress(vm.config.FeeRecipient)
@@ -365,6 +474,9 @@ func (vm *VM) Initialize(
 		log.Warn("Config has not specified any coinbase address. Defaulting to the blackhole address.")
 		vm.ethConfig.Miner.Etherbase = constants.BlackholeAddr
 	}
+	vm.ethConfig.Miner.GovernanceGasReservation = vm.config.GovernanceGasReservation
+	vm.ethConfig.Miner.MaxBuildDuration = vm.config.BlockBuildMaxDuration.Duration
+	vm.ethConfig.Miner.TxOrderer, _ = miner.GetTxOrderer(vm.config.BlockBuildTxOrdering)
 
 	vm.chainConfig = g.Config
 	vm.networkID = vm.ethConfig.NetworkId
@@ -374,10 +486,15 @@ func (vm *VM) Initialize(
 	if len(upgradeBytes) > 0 {
 		var upgradeConfig params.UpgradeConfig
 		if err := json.Unmarshal(upgradeBytes, &upgradeConfig); err != nil {
-			return fmt.Errorf("failed to parse upgrade bytes: %w", err)
+			return fmt.Errorf("failed to parse upgrade bytes: %w", utils.QualifyJSONError(upgradeBytes, &upgradeConfig, err))
 		}
 		vm.chainConfig.UpgradeConfig = upgradeConfig
 	}
+	vm.upgradeBytesHash = crypto.Keccak256Hash(upgradeBytes)
+	if err := vm.loadAppliedUpgradeConfig(); err != nil {
+		return err
+	}
+	vm.chainConfig.SetUpgradeConfig(vm.chainConfig.UpgradeConfig)
 
 	// create genesisHash after applying upgradeBytes in case
 	// upgradeBytes modifies genesis.
@@ -389,6 +506,29 @@ func (vm *VM) Initialize(
 	}
 	log.Info("reading accepted block db", "lastAcceptedHash", lastAcceptedHash)
 
+	var importedBlock, importedHistory *types.Block
+	if len(vm.config.StateImportFile) != 0 && lastAcceptedHeight == 0 {
+		// The history of the imported state is written first, so that its last block is
+		// replaced by the block of the state export, which must be the same.
+		if len(vm.config.ChainImportFile) != 0 {
+			importedHistory, err = vm.importChainHistory(vm.config.ChainImportFile)
+			if err != nil {
+				return fmt.Errorf("failed to import chain history from %s: %w", vm.config.ChainImportFile, err)
+			}
+		}
+		importedBlock, err = vm.importState(vm.config.StateImportFile)
+		if err != nil {
+			return fmt.Errorf("failed to import state from %s: %w", vm.config.StateImportFile, err)
+		}
+		if importedHistory != nil && importedHistory.Hash() != importedBlock.Hash() {
+			return fmt.Errorf("chain history ends at block %d (%s), but the state is of block %d (%s)",
+				importedHistory.NumberU64(), importedHistory.Hash(), importedBlock.NumberU64(), importedBlock.Hash())
+		}
+		lastAcceptedHash, lastAcceptedHeight = importedBlock.Hash(), importedBlock.NumberU64()
+	} else if len(vm.config.StateImportFile) != 0 {
+		log.Info("Skipping state import since blocks have been accepted", "lastAcceptedHeight", lastAcceptedHeight)
+	}
+
 	if err := vm.initializeMetrics(); err != nil {
 		return err
 	}
@@ -401,6 +541,20 @@ func (vm *VM) Initialize(
 	if err := vm.initializeChain(lastAcceptedHash, vm.ethConfig); err != nil {
 		return err
 	}
+	if importedBlock != nil && importedHistory == nil {
+		// The blocks before the imported block are not available to the
+		// BloomIndexer, as after state sync.
+		vm.eth.BloomIndexer().AddCheckpoint((importedBlock.NumberU64()-1)/params.BloomBitsBlocks, importedBlock.ParentHash())
+	}
+	if vm.config.RebuildIndexes {
+		vm.eth.BloomIndexer().Rebuild(0)
+		vm.blockChain.RebuildTxIndex()
+	}
+	if vm.config.CompactIndexes {
+		if err := rawdb.CompactIndexes(vm.chaindb); err != nil {
+			return fmt.Errorf("failed to compact indexes: %w", err)
+		}
+	}
 
 	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
 
@@ -408,6 +562,62 @@ func (vm *VM) Initialize(
 	return vm.initializeStateSyncClient(lastAcceptedHeight)
 }
 
+// initializeChainDB sets [vm.chaindb] to the chain database selected by the
+// config. [avalancheDB] holds the chain data in the avalanchego database, which
+// is migrated into the pebble database the first time it is used.
+func (vm *VM) initializeChainDB(avalancheDB ethdb.KeyValueStore) error {
+	kvStore := avalancheDB
+	if vm.config.DatabaseType == pebbleDatabaseType {
+		dir := vm.config.DatabaseDirectory
+		if len(dir) == 0 {
+			dir = filepath.Join(vm.ctx.ChainDataDir, pebbleDatabaseType)
+		}
+		if err := os.MkdirAll(dir, perms.ReadWriteExecute); err != nil {
+			return fmt.Errorf("failed to create pebble database directory: %w", err)
+		}
+		pebbleDB, err := pebble.New(dir, pebble.Config{
+			Cache:                    vm.config.PebbleCacheSize,
+			Handles:                  vm.config.PebbleHandles,
+			MaxConcurrentCompactions: vm.config.PebbleMaxConcurrentCompactions,
+			DisableWAL:               vm.config.PebbleWALDisabled,
+			WALDir:                   vm.config.PebbleWALDirectory,
+			WALBytesPerSync:          vm.config.PebbleWALBytesPerSync,
+			SyncWrites:               vm.config.PebbleSyncWrites,
+		}, "eth/db/chaindata/")
+		if err != nil {
+			return fmt.Errorf("failed to open pebble database: %w", err)
+		}
+		if err := migrateChainDatabase(avalancheDB, pebbleDB); err != nil {
+			pebbleDB.Close()
+			return fmt.Errorf("failed to migrate chain data to pebble database: %w", err)
+		}
+		kvStore = pebbleDB
+	} else {
+		migrated, err := avalancheDB.Has(pebbleMigrationKey)
+		if err != nil {
+			return err
+		}
+		if migrated {
+			return errChainDataMigrated
+		}
+	}
+
+	vm.chainKVStore = kvStore
+	if !vm.config.AncientStore {
+		vm.chaindb = rawdb.NewDatabase(kvStore)
+		return nil
+	}
+	if err := os.MkdirAll(vm.config.AncientStoreDirectory, perms.ReadWriteExecute); err != nil {
+		return fmt.Errorf("failed to create ancient store directory: %w", err)
+	}
+	var err error
+	vm.chaindb, err = rawdb.NewDatabaseWithFreezer(kvStore, vm.config.AncientStoreDirectory, false)
+	if err != nil {
+		return fmt.Errorf("failed to open ancient store: %w", err)
+	}
+	return nil
+}
+
 func (vm *VM) initializeMetrics() error {
 	vm.multiGatherer = avalanchegoMetrics.NewMultiGatherer()
 	// If metrics are enabled, register the default metrics regitry
@@ -430,11 +640,15 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash, ethConfig ethconfig.
 		KeyStoreDir:           vm.config.KeystoreDirectory,
 		ExternalSigner:        vm.config.KeystoreExternalSigner,
 		InsecureUnlockAllowed: vm.config.KeystoreInsecureUnlockAllowed,
+		UseLightweightKDF:     vm.config.KeystoreUseLightweightKDF,
 	}
 	node, err := node.New(nodecfg)
 	if err != nil {
 		return err
 	}
+	if err := unlockAccounts(node.AccountManager(), vm.config.KeystoreUnlockAccounts, vm.config.KeystorePasswordFile); err != nil {
+		return err
+	}
 	vm.eth, err = eth.New(
 		node,
 		&vm.ethConfig,
@@ -453,6 +667,17 @@ func (vm *VM) initializeChain(lastAcceptedHash common.Hash, ethConfig ethconfig.
 
 	// start goroutines to update the tx pool gas minimum gas price when upgrades go into effect
 	vm.handleGasPriceUpdates()
+	// start the goroutine monitoring the chain for the anomalies to alert on
+	vm.handleAlerts()
+	// start the goroutine notifying the finality events to the subscribers of the finality API
+	vm.finality.start(vm.blockChain, vm.shutdownChan, &vm.shutdownWg)
+
+	// Replay the imported chain before the last accepted block is passed to the consensus engine
+	if len(vm.config.ChainImportFile) != 0 && len(vm.config.StateImportFile) == 0 {
+		if err := vm.importChain(vm.config.ChainImportFile); err != nil {
+			return fmt.Errorf("failed to import chain from %s: %w", vm.config.ChainImportFile, err)
+		}
+	}
 
 	vm.eth.Start()
 	return vm.initChainState(vm.blockChain.LastAcceptedBlock())
@@ -523,14 +748,15 @@ func (vm *VM) initChainState(lastAcceptedBlock *types.Block) error {
 	block.status = choices.Accepted
 
 	config := &chain.Config{
-		DecidedCacheSize:    decidedCacheSize,
-		MissingCacheSize:    missingCacheSize,
-		UnverifiedCacheSize: unverifiedCacheSize,
-		GetBlockIDAtHeight:  vm.GetBlockIDAtHeight,
-		GetBlock:            vm.getBlock,
-		UnmarshalBlock:      vm.parseBlock,
-		BuildBlock:          vm.buildBlock,
-		LastAcceptedBlock:   block,
+		DecidedCacheSize:      decidedCacheSize,
+		MissingCacheSize:      missingCacheSize,
+		UnverifiedCacheSize:   unverifiedCacheSize,
+		GetBlockIDAtHeight:    vm.GetBlockIDAtHeight,
+		GetBlock:              vm.getBlock,
+		UnmarshalBlock:        vm.parseBlock,
+		BuildBlock:            vm.buildBlock,
+		BuildBlockWithContext: vm.buildBlockWithContext,
+		LastAcceptedBlock:     block,
 	}
 
 	// Register chain state metrics
@@ -547,10 +773,10 @@ func (vm *VM) initChainState(lastAcceptedBlock *types.Block) error {
 func (vm *VM) SetState(_ context.Context, state snow.State) error {
 	switch state {
 	case snow.StateSyncing:
-		vm.bootstrapped = false
+		vm.bootstrapped.SetValue(false)
 		return nil
 	case snow.Bootstrapping:
-		vm.bootstrapped = false
+		vm.bootstrapped.SetValue(false)
 		if err := vm.StateSyncClient.Error(); err != nil {
 			return err
 		}
@@ -558,7 +784,7 @@ func (vm *VM) SetState(_ context.Context, state snow.State) error {
 	case snow.NormalOp:
 		// Initialize gossip handling once we enter normal operation as there is no need to handle mempool gossip before this point.
 		vm.initBlockBuilding()
-		vm.bootstrapped = true
+		vm.bootstrapped.SetValue(true)
 		return nil
 	default:
 		return snow.ErrUnknownState
@@ -573,6 +799,7 @@ func (vm *VM) initBlockBuilding() {
 	vm.builder = vm.NewBlockBuilder(vm.toEngine)
 	vm.builder.awaitSubmittedTxs()
 	vm.Network.SetGossipHandler(NewGossipHandler(vm, gossipStats))
+	vm.awaitChainConfigHashGossip(gossipStats)
 }
 
 // setAppRequestHandlers sets the request handlers for the VM to serve state sync
@@ -584,7 +811,8 @@ func (vm *VM) setAppRequestHandlers() {
 	evmTrieDB := trie.NewDatabaseWithConfig(
 		vm.chaindb,
 		&trie.Config{
-			Cache: vm.config.StateSyncServerTrieCache,
+			Cache:  vm.config.StateSyncServerTrieCache,
+			Scheme: rawdb.ReadStateScheme(vm.chaindb),
 		},
 	)
 	syncRequestHandler := handlers.NewSyncHandler(
@@ -608,14 +836,74 @@ func (vm *VM) Shutdown(context.Context) error {
 	close(vm.shutdownChan)
 	vm.eth.Stop()
 	vm.shutdownWg.Wait()
+	vm.alerts.Close()
+	if vm.config.AncientStore || vm.config.DatabaseType == pebbleDatabaseType {
+		if err := vm.chaindb.Close(); err != nil {
+			log.Error("error closing chain database", "err", err)
+		}
+	}
+	if vm.tracer != nil {
+		tracing.SetTracer(nil)
+		if err := vm.tracer.Close(); err != nil {
+			log.Error("error closing tracer", "err", err)
+		}
+	}
+	return nil
+}
+
+// initializeTracer sets the tracer recording the spans of the block lifecycle, RPC calls
+// and stateful precompile calls, if tracing is enabled in the config.
+func (vm *VM) initializeTracer() error {
+	if !vm.config.TracingEnabled {
+		return nil
+	}
+	exporterType, err := trace.ExporterTypeFromString(vm.config.TracingExporterType)
+	if err != nil {
+		return err
+	}
+	vm.tracer, err = trace.New(trace.Config{
+		ExporterConfig: trace.ExporterConfig{
+			Type:     exporterType,
+			Endpoint: vm.config.TracingEndpoint,
+			Headers:  vm.config.TracingHeaders,
+			Insecure: vm.config.TracingInsecure,
+		},
+		Enabled:         true,
+		TraceSampleRate: vm.config.TracingSampleRate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tracer: %w", err)
+	}
+	tracing.SetTracer(vm.tracer)
 	return nil
 }
 
 // buildBlock builds a block to be wrapped by ChainState
-func (vm *VM) buildBlock(context.Context) (snowman.Block, error) {
-	block, err := vm.miner.GenerateBlock()
+func (vm *VM) buildBlock(ctx context.Context) (snowman.Block, error) {
+	return vm.buildBlockWithPChainHeight(ctx, nil)
+}
+
+// buildBlockWithContext builds a block recording the P-chain height provided by the proposervm.
+func (vm *VM) buildBlockWithContext(ctx context.Context, blockCtx *block.Context) (snowman.Block, error) {
+	return vm.buildBlockWithPChainHeight(ctx, &blockCtx.PChainHeight)
+}
+
+func (vm *VM) buildBlockWithPChainHeight(ctx context.Context, pChainHeight *uint64) (snowman.Block, error) {
+	ctx, span := tracing.Start(ctx, "VM.BuildBlock")
+	defer span.End()
+
+	var (
+		block *types.Block
+		err   error
+	)
+	if pChainHeight != nil {
+		block, err = vm.miner.GenerateBlockWithPChainHeight(ctx, *pChainHeight)
+	} else {
+		block, err = vm.miner.GenerateBlock(ctx)
+	}
 	vm.builder.handleGenerateBlock()
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
@@ -634,7 +922,7 @@ func (vm *VM) buildBlock(context.Context) (snowman.Block, error) {
 	// We call verify without writes here to avoid generating a reference
 	// to the blk state root in the triedb when we are going to call verify
 	// again from the consensus engine with writes enabled.
-	if err := blk.verify(false /*=writes*/); err != nil {
+	if err := blk.verify(ctx, false /*=writes*/); err != nil {
 		return nil, fmt.Errorf("block failed verification due to: %w", err)
 	}
 
@@ -748,6 +1036,13 @@ func newHandler(name string, service interface{}, lockOption ...commonEng.LockOp
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers(context.Context) (map[string]*commonEng.HTTPHandler, error) {
 	handler := rpc.NewServer(vm.config.APIMaxDuration.Duration)
+	handler.SetBatchLimits(vm.config.BatchRequestLimit, vm.config.BatchResponseMaxSize)
+	handler.SetCostLimits(vm.config.RPCCostLimits())
+	handler.SetSlowCallThreshold(vm.config.RPCSlowCallThreshold.Duration)
+	handler.SetHTTPBodyLimit(vm.config.HTTPBodyLimit)
+	if err := handler.SetHTTPCompression(vm.config.RPCCompression...); err != nil {
+		return nil, err
+	}
 	enabledAPIs := vm.config.EthAPIs()
 	if err := attachEthService(handler, vm.eth.APIs(), enabledAPIs); err != nil {
 		return nil, err
@@ -774,10 +1069,58 @@ func (vm *VM) CreateHandlers(context.Context) (map[string]*commonEng.HTTPHandler
 		enabledAPIs = append(enabledAPIs, "snowman")
 	}
 
+	if vm.config.HealthAPIEnabled {
+		if err := handler.RegisterName("health", &HealthAPI{vm}); err != nil {
+			return nil, err
+		}
+		apis[healthEndpoint] = &commonEng.HTTPHandler{
+			LockOptions: commonEng.NoLock,
+			Handler:     vm.healthHandler(),
+		}
+		enabledAPIs = append(enabledAPIs, "health")
+	}
+
+	if vm.config.FaucetEnabled {
+		if err := vm.initializeFaucet(); err != nil {
+			return nil, fmt.Errorf("failed to initialize faucet: %w", err)
+		}
+		apis[faucetEndpoint] = &commonEng.HTTPHandler{
+			LockOptions: commonEng.NoLock,
+			Handler:     vm.faucet.Handler(),
+		}
+		enabledAPIs = append(enabledAPIs, "faucet")
+		log.Info("Faucet enabled", "address", vm.faucet.Address(), "mode", vm.config.FaucetMode)
+	}
+
+	if vm.config.FinalityAPIEnabled {
+		if err := handler.RegisterName("finality", &FinalityAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "finality")
+	}
+
+	if vm.config.BridgeAPIEnabled {
+		if err := handler.RegisterName("bridge", &BridgeAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "bridge")
+	}
+
+	if vm.config.SubnetAPIEnabled {
+		if err := handler.RegisterName("subnet", &SubnetAPI{vm}); err != nil {
+			return nil, err
+		}
+		enabledAPIs = append(enabledAPIs, "subnet")
+	}
+
 	log.Info(fmt.Sprintf("Enabled APIs: %s", strings.Join(enabledAPIs, ", ")))
+	var rpcHandler http.Handler = handler
+	if vm.config.RPCHTTP2Enabled {
+		rpcHandler = h2c.NewHandler(handler, &http2.Server{})
+	}
 	apis[ethRPCEndpoint] = &commonEng.HTTPHandler{
 		LockOptions: commonEng.NoLock,
-		Handler:     handler,
+		Handler:     rpcHandler,
 	}
 	apis[ethWSEndpoint] = &commonEng.HTTPHandler{
 		LockOptions: commonEng.NoLock,
@@ -831,6 +1174,130 @@ func (vm *VM) currentRules() params.Rules {
 	return vm.chainConfig.AvalancheRules(header.Number, big.NewInt(int64(header.Time)))
 }
 
+// appliedUpgradeConfig is the upgrade config applied by updateUpgradeConfig, persisted with the hash of
+// the upgrade bytes the VM was initialized with when it was applied.
+type appliedUpgradeConfig struct {
+	UpgradeBytesHash common.Hash          `json:"upgradeBytesHash"`
+	UpgradeConfig    params.UpgradeConfig `json:"upgradeConfig"`
+}
+
+// loadAppliedUpgradeConfig replaces the upgrade config of the chain config with the one applied by
+// updateUpgradeConfig before the VM restarted, if the upgrade bytes are unchanged since then. Otherwise,
+// the upgrade bytes were updated by the operator and take precedence over the applied upgrade config.
+func (vm *VM) loadAppliedUpgradeConfig() error {
+	data, err := vm.metadataDB.Get(appliedUpgradeConfigKey)
+	if err == database.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the applied upgrade config: %w", err)
+	}
+	var applied appliedUpgradeConfig
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return fmt.Errorf("failed to parse the applied upgrade config: %w", err)
+	}
+	if applied.UpgradeBytesHash != vm.upgradeBytesHash {
+		log.Info("Upgrade bytes changed since the upgrade config was applied, discarding it")
+		if err := vm.metadataDB.Delete(appliedUpgradeConfigKey); err != nil {
+			return fmt.Errorf("failed to delete the applied upgrade config: %w", err)
+		}
+		return vm.db.Commit()
+	}
+	log.Info("Using the upgrade config applied before the restart", "upgradeConfig", applied.UpgradeConfig)
+	vm.chainConfig.UpgradeConfig = applied.UpgradeConfig
+	return nil
+}
+
+// updateUpgradeConfig replaces the precompile upgrades of the chain config with those of [upgradeConfig]
+// without restarting the VM, so that newly added upgrades are scheduled as soon as validators apply them.
+// [upgradeConfig] must be valid and compatible with the upgrades activated by the preferred and processing
+// blocks, and it cannot change the network upgrades, which are scheduled when the VM is initialized.
+// The updated config is persisted, so that it is used on the next restart unless the upgrade bytes changed.
+// Assumes that the ctx lock is held, so that no block is being verified or built concurrently.
+func (vm *VM) updateUpgradeConfig(upgradeConfig params.UpgradeConfig) error {
+	if !networkUpgradesEqual(vm.chainConfig.Upgrades().NetworkUpgrades, upgradeConfig.NetworkUpgrades) {
+		return errNetworkUpgradesChanged
+	}
+	if errs := vm.validateUpgradeConfig(&upgradeConfig); len(errs) > 0 {
+		return fmt.Errorf("cannot apply upgrade config: %w", errs[0])
+	}
+
+	data, err := json.Marshal(&appliedUpgradeConfig{
+		UpgradeBytesHash: vm.upgradeBytesHash,
+		UpgradeConfig:    upgradeConfig,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode the upgrade config: %w", err)
+	}
+	if err := vm.metadataDB.Put(appliedUpgradeConfigKey, data); err != nil {
+		return fmt.Errorf("failed to store the upgrade config: %w", err)
+	}
+	if err := vm.db.Commit(); err != nil {
+		return fmt.Errorf("failed to store the upgrade config: %w", err)
+	}
+	vm.chainConfig.SetUpgradeConfig(upgradeConfig)
+	rawdb.WriteChainConfig(vm.chaindb, vm.genesisHash, vm.chainConfig)
+	log.Info("Updated precompile upgrades", "upgradeConfig", upgradeConfig)
+	return nil
+}
+
+// validateUpgradeConfig returns the errors found in [upgradeConfig] by [params.ValidateUpgradeConfig]
+// against the tip of the preferred and processing blocks, or nil if it can replace the upgrade config
+// of the chain.
+func (vm *VM) validateUpgradeConfig(upgradeConfig *params.UpgradeConfig) []*params.UpgradeConfigError {
+	height, timestamp := vm.processingTip()
+	return params.ValidateUpgradeConfig(vm.chainConfig, upgradeConfig, height, timestamp)
+}
+
+// processingTip returns the greatest height and timestamp of the preferred block and the blocks being
+// processed by consensus, which may be on other branches than the preferred block.
+// Assumes that the ctx lock is held.
+func (vm *VM) processingTip() (uint64, uint64) {
+	currentBlock := vm.blockChain.CurrentBlock()
+	height, timestamp := currentBlock.NumberU64(), currentBlock.Time()
+	for _, header := range vm.processing {
+		if header.Number.Uint64() > height {
+			height = header.Number.Uint64()
+		}
+		if header.Time > timestamp {
+			timestamp = header.Time
+		}
+	}
+	return height, timestamp
+}
+
+// dryRunUpgradeConfig replays the last [blocks] accepted blocks against the chain config with [upgradeConfig] in
+// an isolated copy of the state, and reports how each block diverges from the accepted chain. Unlike
+// updateUpgradeConfig, the upgrades of [upgradeConfig] may activate in the past, so that they affect the
+// replayed blocks.
+func (vm *VM) dryRunUpgradeConfig(upgradeConfig params.UpgradeConfig, blocks uint64) ([]*core.BlockReplayResult, error) {
+	if blocks == 0 || blocks > maxDryRunBlocks {
+		return nil, fmt.Errorf("number of blocks to replay must be in [1, %d], got %d", maxDryRunBlocks, blocks)
+	}
+	config := vm.chainConfig.WithUpgradeConfig(upgradeConfig)
+	if err := config.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid upgrade config: %w", err)
+	}
+
+	lastAccepted := vm.blockChain.LastAcceptedBlock().NumberU64()
+	if lastAccepted == 0 {
+		return nil, errNoBlocksToReplay
+	}
+	from := uint64(1)
+	if lastAccepted > blocks {
+		from = lastAccepted - blocks + 1
+	}
+	return vm.blockChain.DryRunChainConfig(config, from, lastAccepted)
+}
+
+// networkUpgradesEqual returns true if [a] and [b] schedule the same network upgrades.
+func networkUpgradesEqual(a, b *params.NetworkUpgrades) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return utils.BigNumEqual(a.SubnetEVMTimestamp, b.SubnetEVMTimestamp)
+}
+
 func (vm *VM) startContinuousProfiler() {
 	// If the profiler directory is empty, return immediately
 	// without creating or starting a continuous profiler.
@@ -883,6 +1350,61 @@ func (vm *VM) readLastAccepted() (common.Hash, uint64, error) {
 	}
 }
 
+// importState initializes the chain database with the block and the state of
+// the state export at [path], and marks the block as the last accepted block.
+func (vm *VM) importState(path string) (*types.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	block, _, err := core.ImportState(vm.chaindb, f, vm.genesisHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := vm.acceptedBlockDB.Put(lastAcceptedKey, block.Hash().Bytes()); err != nil {
+		return nil, err
+	}
+	if err := vm.db.Commit(); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// importChainHistory writes the blocks and the receipts of the chain export at
+// [path] to the chain database without processing them, and returns the last
+// block. Its state must be imported separately.
+func (vm *VM) importChainHistory(path string) (*types.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	block, _, err := core.ImportChainHistory(vm.chaindb, f, vm.genesisHash, vm.chainConfig)
+	return block, err
+}
+
+// importChain replays the blocks of the chain export at [path] on top of the
+// last accepted block, and marks the last of them as the last accepted block.
+func (vm *VM) importChain(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := vm.blockChain.ImportChain(f); err != nil {
+		return err
+	}
+	lastAccepted := vm.blockChain.LastAcceptedBlock()
+	if err := vm.acceptedBlockDB.Put(lastAcceptedKey, lastAccepted.Hash().Bytes()); err != nil {
+		return err
+	}
+	return vm.db.Commit()
+}
+
 // attachEthService registers the backend RPC services provided by Ethereum
 // to the provided handler under their assigned namespaces.
 func attachEthService(handler *rpc.Server, apis []rpc.API, names []string) error {
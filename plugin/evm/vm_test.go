@@ -18,13 +18,17 @@ import (
 	"time"
 
 	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ava-labs/subnet-evm/internal/ethapi"
 	"github.com/ava-labs/subnet-evm/metrics"
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/stretchr/testify/require"
 
@@ -250,6 +254,35 @@ func TestVMConfigDefaults(t *testing.T) {
 	require.NoError(t, vm.Shutdown(context.Background()))
 }
 
+func TestVMDisabledPrecompileAPIs(t *testing.T) {
+	configJSON := fmt.Sprintf("{\"disabled-precompile-apis\": [%q]}", precompile.FeeConfigManagerConfigKey)
+	_, vm, _, _ := GenesisVM(t, false, "", configJSON, "")
+	require.False(t, vm.eth.APIBackend.PrecompileAPIEnabled(precompile.FeeConfigManagerConfigKey))
+	require.True(t, vm.eth.APIBackend.PrecompileAPIEnabled(precompile.TxAllowListConfigKey))
+
+	_, err := ethapi.NewBlockChainAPI(vm.eth.APIBackend).FeeConfig(context.Background(), nil)
+	require.ErrorContains(t, err, "APIs are disabled on this node")
+	_, err = ethapi.NewSubnetEVMAPI(vm.eth.APIBackend).GetFeeConfig(context.Background())
+	require.ErrorContains(t, err, "APIs are disabled on this node")
+	require.NoError(t, vm.Shutdown(context.Background()))
+
+	// Disabling the APIs of an unknown precompile is rejected.
+	ctx, dbManager, genesisBytes, issuer := setupGenesis(t, "")
+	vm = &VM{}
+	err = vm.Initialize(
+		context.Background(),
+		ctx,
+		dbManager,
+		genesisBytes,
+		[]byte(""),
+		[]byte(`{"disabled-precompile-apis": ["unknownConfig"]}`),
+		issuer,
+		[]*engCommon.Fx{},
+		nil,
+	)
+	require.ErrorContains(t, err, "cannot disable APIs of unknown precompile")
+}
+
 func TestVMNilConfig(t *testing.T) {
 	_, vm, _, _ := GenesisVM(t, false, "", "", "")
 
@@ -2116,7 +2149,7 @@ func TestBuildAllowListActivationBlock(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(time.Now().Unix()), testEthAddrs, nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewContractDeployerAllowListConfig(big.NewInt(time.Now().Unix()), testEthAddrs, nil))
 
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
@@ -2180,7 +2213,7 @@ func TestTxAllowListSuccessfulTx(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1], nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1], nil))
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
 		t.Fatal(err)
@@ -2256,7 +2289,7 @@ func TestTxAllowListDisablePrecompile(t *testing.T) {
 		t.Fatal(err)
 	}
 	enableAllowListTimestamp := time.Unix(0, 0) // enable at genesis
-	genesis.Config.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil))
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
 		t.Fatal(err)
@@ -2368,7 +2401,7 @@ func TestFeeManagerChangeFee(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.FeeManagerConfig = precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil))
 
 	// set a lower fee config now
 	testLowFeeConfig := commontype.FeeConfig{
@@ -2462,6 +2495,40 @@ func TestFeeManagerChangeFee(t *testing.T) {
 	require.EqualValues(t, testHighFeeConfig, feeConfig)
 	require.EqualValues(t, vm.blockChain.CurrentBlock().Number(), lastChangedAt)
 
+	// the subnetevm API returns the decoded fee config at the head and at a past block
+	subnetEVMAPI := ethapi.NewSubnetEVMAPI(vm.eth.APIBackend)
+	feeConfigResult, err := subnetEVMAPI.GetFeeConfig(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, testHighFeeConfig, feeConfigResult.FeeConfig)
+	require.EqualValues(t, block.Number(), feeConfigResult.LastChangedAt)
+	feeConfigResult, err = subnetEVMAPI.GetFeeConfigAt(context.Background(), rpc.BlockNumberOrHashWithNumber(0))
+	require.NoError(t, err)
+	require.EqualValues(t, testLowFeeConfig, feeConfigResult.FeeConfig)
+
+	// the fee manager state is proven by eth_getProof for the slots resolved by the subnetevm API
+	slots, err := subnetEVMAPI.GetPrecompileStorageSlots(precompile.FeeConfigManagerAddress, []string{
+		"feeConfig.minBaseFee",
+		"feeConfigLastChangedAt",
+		fmt.Sprintf("allowList[%s]", testEthAddrs[0]),
+	})
+	require.NoError(t, err)
+	storageKeys := make([]string, len(slots))
+	for i, slot := range slots {
+		storageKeys[i] = slot.Hex()
+	}
+	proof, err := ethapi.NewBlockChainAPI(vm.eth.APIBackend).GetProof(context.Background(), precompile.FeeConfigManagerAddress, storageKeys, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	require.NoError(t, err)
+	var account types.StateAccount
+	require.NoError(t, rlp.DecodeBytes(verifyTestProof(t, block.Root(), precompile.FeeConfigManagerAddress.Bytes(), proof.AccountProof), &account))
+	require.Equal(t, proof.StorageHash, account.Root)
+	expectedValues := []*big.Int{testHighFeeConfig.MinBaseFee, block.Number(), common.Hash(precompile.AllowListAdmin).Big()}
+	for i, storageProof := range proof.StorageProof {
+		require.Equal(t, expectedValues[i], storageProof.Value.ToInt())
+		var value []byte
+		require.NoError(t, rlp.DecodeBytes(verifyTestProof(t, proof.StorageHash, slots[i].Bytes(), storageProof.Proof), &value))
+		require.Equal(t, expectedValues[i], new(big.Int).SetBytes(value))
+	}
+
 	// should fail, with same params since fee is higher now
 	tx2 := types.NewTx(&types.DynamicFeeTx{
 		ChainID:   genesis.Config.ChainID,
@@ -2481,6 +2548,350 @@ func TestFeeManagerChangeFee(t *testing.T) {
 
 	err = vm.txPool.AddRemote(signedTx2)
 	require.ErrorIs(t, err, core.ErrUnderpriced)
+
+	// issue a block under the new fee config
+	tx3 := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   genesis.Config.ChainID,
+		Nonce:     uint64(1),
+		To:        &testEthAddrs[1],
+		Gas:       params.TxGas,
+		Value:     common.Big0,
+		GasFeeCap: new(big.Int).Mul(testHighFeeConfig.MinBaseFee, big.NewInt(202)),
+		GasTipCap: new(big.Int).Mul(testHighFeeConfig.MinBaseFee, big.NewInt(200)), // cover the block gas cost
+	})
+	signedTx3, err := types.SignTx(tx3, types.LatestSigner(genesis.Config), testKeys[0])
+	require.NoError(t, err)
+	errs = vm.txPool.AddRemotesSync([]*types.Transaction{signedTx3})
+	require.NoError(t, errs[0])
+	blk = issueAndAccept(t, issuer, vm)
+	<-newTxPoolHeadChan
+
+	// the fee history reports the change of fee config in the middle of the range
+	history, err := ethapi.NewEthereumAPI(vm.eth.APIBackend).SubnetFeeHistory(context.Background(), 3, rpc.LatestBlockNumber, nil)
+	require.NoError(t, err)
+	require.Len(t, history.BlockGasCost, 3)
+	require.Len(t, history.FeeConfigs, 2)
+	require.EqualValues(t, 0, history.FeeConfigs[0].FromBlock.ToInt().Uint64())
+	require.EqualValues(t, 1, history.FeeConfigs[0].ToBlock.ToInt().Uint64())
+	require.EqualValues(t, testLowFeeConfig, history.FeeConfigs[0].FeeConfig)
+	require.EqualValues(t, blk.Height(), history.FeeConfigs[1].FromBlock.ToInt().Uint64())
+	require.EqualValues(t, testHighFeeConfig, history.FeeConfigs[1].FeeConfig)
+}
+
+// Test that a governance transaction whose gas limit exceeds the gas reserved for governance transactions
+// is still included in a block with the other transactions.
+func TestGovernanceTxAboveGasReservation(t *testing.T) {
+	genesis := &core.Genesis{}
+	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
+		t.Fatal(err)
+	}
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), `{"governance-gas-reservation": 100000}`, "")
+
+	defer func() {
+		if err := vm.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.txPool.SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	feeConfig := vm.chainConfig.FeeConfig
+	feeConfig.MinBaseFee = new(big.Int).Mul(feeConfig.MinBaseFee, common.Big2)
+	data, err := precompile.PackSetFeeConfig(feeConfig)
+	require.NoError(t, err)
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   genesis.Config.ChainID,
+		Nonce:     0,
+		To:        &precompile.FeeConfigManagerAddress,
+		Gas:       200_000,
+		Value:     common.Big0,
+		GasFeeCap: big.NewInt(testMinGasPrice),
+		GasTipCap: common.Big0,
+		Data:      data,
+	})
+	signedTx, err := types.SignTx(tx, types.LatestSigner(genesis.Config), testKeys[0])
+	require.NoError(t, err)
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{signedTx})
+	require.NoError(t, errs[0])
+
+	blk := issueAndAccept(t, issuer, vm)
+	newHead := <-newTxPoolHeadChan
+	require.Equal(t, common.Hash(blk.ID()), newHead.Head.Hash())
+
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	require.Len(t, block.Transactions(), 1)
+	require.Equal(t, signedTx.Hash(), block.Transactions()[0].Hash())
+}
+
+func TestCallMany(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	_, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	var (
+		recipient    = common.HexToAddress("0x0123")
+		transferred  = big.NewInt(params.Ether)
+		forwarded    = big.NewInt(params.Ether / 2)
+		newFeeConfig = params.DefaultFeeConfig
+	)
+	newFeeConfig.MinBaseFee = big.NewInt(50_000_000_000)
+	setFeeConfigData, err := precompile.PackSetFeeConfig(newFeeConfig)
+	require.NoError(t, err)
+	setFeeConfigInput := hexutil.Bytes(setFeeConfigData)
+	gas := hexutil.Uint64(1_000_000)
+
+	// The second transaction spends the funds received in the first one, the third one fails
+	// since its sender is not a fee manager admin and the last one changes the fee config.
+	txs := []ethapi.TransactionArgs{
+		{From: &testEthAddrs[0], To: &recipient, Value: (*hexutil.Big)(transferred)},
+		{From: &recipient, To: &testEthAddrs[1], Value: (*hexutil.Big)(forwarded)},
+		{From: &testEthAddrs[1], To: &precompile.FeeConfigManagerAddress, Gas: &gas, Input: &setFeeConfigInput},
+		{From: &testEthAddrs[0], To: &precompile.FeeConfigManagerAddress, Gas: &gas, Input: &setFeeConfigInput},
+	}
+	blockNumber := big.NewInt(100)
+	results, err := ethapi.NewBlockChainAPI(vm.eth.APIBackend).CallMany(context.Background(), txs, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, &ethapi.BlockOverrides{Number: (*hexutil.Big)(blockNumber)})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	require.Empty(t, results[0].Error)
+	require.EqualValues(t, params.TxGas, results[0].UsedGas)
+	require.Equal(t, transferred, results[0].StateDiff[recipient].Balance.To.ToInt())
+
+	require.Empty(t, results[1].Error)
+	recipientDiff := results[1].StateDiff[recipient]
+	require.Equal(t, transferred, recipientDiff.Balance.From.ToInt())
+	require.Equal(t, new(big.Int).Sub(transferred, forwarded), recipientDiff.Balance.To.ToInt())
+	require.EqualValues(t, 0, recipientDiff.Nonce.From)
+	require.EqualValues(t, 1, recipientDiff.Nonce.To)
+
+	require.Contains(t, results[2].Error, precompile.ErrCannotChangeFee.Error())
+	require.NotContains(t, results[2].StateDiff, precompile.FeeConfigManagerAddress)
+
+	require.Empty(t, results[3].Error)
+	minBaseFeeSlot, err := precompile.ResolveStorageSlot(precompile.FeeConfigManagerAddress, "feeConfig.minBaseFee")
+	require.NoError(t, err)
+	lastChangedAtSlot, err := precompile.ResolveStorageSlot(precompile.FeeConfigManagerAddress, "feeConfigLastChangedAt")
+	require.NoError(t, err)
+	storageDiff := results[3].StateDiff[precompile.FeeConfigManagerAddress].Storage
+	require.Equal(t, common.BigToHash(newFeeConfig.MinBaseFee), storageDiff[minBaseFeeSlot].To)
+	require.Equal(t, common.BigToHash(blockNumber), storageDiff[lastChangedAtSlot].To)
+
+	// The bundle does not change the state of the chain.
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Zero(t, state.GetBalance(recipient).Sign())
+}
+
+func TestSimulateV1(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil))
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1], nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	upgradeJSON := `{"parameterUpgrades":[{"blockTimestamp":0,"precompileEvents":true}]}`
+	_, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", upgradeJSON)
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	var (
+		api          = ethapi.NewBlockChainAPI(vm.eth.APIBackend)
+		recipient    = common.HexToAddress("0x0123")
+		transferred  = (*hexutil.Big)(big.NewInt(params.Ether))
+		newFeeConfig = params.DefaultFeeConfig
+	)
+	newFeeConfig.MinBaseFee = big.NewInt(50_000_000_000)
+	setFeeConfigData, err := precompile.PackSetFeeConfig(newFeeConfig)
+	require.NoError(t, err)
+	setFeeConfigInput := hexutil.Bytes(setFeeConfigData)
+
+	// The fee config changed in the first block applies to the base fee of the second one.
+	results, err := api.SimulateV1(context.Background(), ethapi.SimulateOpts{
+		BlockStateCalls: []ethapi.SimulateBlock{
+			{Calls: []ethapi.TransactionArgs{{From: &testEthAddrs[0], To: &precompile.FeeConfigManagerAddress, Input: &setFeeConfigInput}}},
+			{Calls: []ethapi.TransactionArgs{{From: &testEthAddrs[0], To: &recipient, Value: transferred}}},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.EqualValues(t, 1, results[0]["number"].(*hexutil.Big).ToInt().Int64())
+	require.EqualValues(t, 2, results[1]["number"].(*hexutil.Big).ToInt().Int64())
+	require.Equal(t, results[0]["hash"], results[1]["parentHash"])
+
+	feeCalls := results[0]["calls"].([]ethapi.SimulateCallResult)
+	require.Len(t, feeCalls, 1)
+	require.Nil(t, feeCalls[0].Error)
+	require.EqualValues(t, types.ReceiptStatusSuccessful, feeCalls[0].Status)
+	require.Len(t, feeCalls[0].Logs, 1)
+	require.Equal(t, precompile.FeeConfigManagerAddress, feeCalls[0].Logs[0].Address)
+	require.Equal(t, results[0]["hash"], feeCalls[0].Logs[0].BlockHash)
+	require.EqualValues(t, 1, feeCalls[0].Logs[0].BlockNumber)
+
+	require.Less(t, results[0]["baseFeePerGas"].(*hexutil.Big).ToInt().Cmp(newFeeConfig.MinBaseFee), 0)
+	require.GreaterOrEqual(t, results[1]["baseFeePerGas"].(*hexutil.Big).ToInt().Cmp(newFeeConfig.MinBaseFee), 0)
+	transferCalls := results[1]["calls"].([]ethapi.SimulateCallResult)
+	require.Len(t, transferCalls, 1)
+	require.EqualValues(t, types.ReceiptStatusSuccessful, transferCalls[0].Status)
+	require.EqualValues(t, params.TxGas, transferCalls[0].GasUsed)
+
+	// Senders must be on the tx allow list, which can be granted by overriding the state of the precompile.
+	notAllowed := ethapi.SimulateBlock{Calls: []ethapi.TransactionArgs{{From: &testEthAddrs[1], To: &recipient}}}
+	_, err = api.SimulateV1(context.Background(), ethapi.SimulateOpts{BlockStateCalls: []ethapi.SimulateBlock{notAllowed}}, nil)
+	require.ErrorIs(t, err, precompile.ErrSenderAddressNotAllowListed)
+
+	allowListState := map[common.Hash]common.Hash{testEthAddrs[1].Hash(): common.Hash(precompile.AllowListEnabled)}
+	allowed := notAllowed
+	allowed.StateOverrides = &ethapi.StateOverride{precompile.TxAllowListAddress: {StateDiff: &allowListState}}
+	results, err = api.SimulateV1(context.Background(), ethapi.SimulateOpts{BlockStateCalls: []ethapi.SimulateBlock{allowed}}, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, types.ReceiptStatusSuccessful, results[0]["calls"].([]ethapi.SimulateCallResult)[0].Status)
+
+	// The simulation does not change the state of the chain.
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Zero(t, state.GetBalance(recipient).Sign())
+	require.Equal(t, params.DefaultFeeConfig.MinBaseFee, precompile.GetStoredFeeConfig(state).MinBaseFee)
+}
+
+func TestCallPrecompileOverrides(t *testing.T) {
+	genesis := &core.Genesis{}
+	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewContractDeployerAllowListConfig(big.NewInt(0), testEthAddrs[0:1], nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	require.NoError(t, err)
+	_, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	var (
+		api          = ethapi.NewBlockChainAPI(vm.eth.APIBackend)
+		code         = hexutil.Bytes{0x00}
+		deploy       = ethapi.TransactionArgs{From: &testEthAddrs[1], Input: &code}
+		genesisBlock = rpc.BlockNumberOrHashWithNumber(0)
+		override     = func(config string) *ethapi.StateOverride {
+			return &ethapi.StateOverride{precompile.ContractDeployerAllowListAddress: {PrecompileConfig: json.RawMessage(config)}}
+		}
+	)
+	_, err = api.Call(context.Background(), deploy, genesisBlock, nil)
+	require.ErrorContains(t, err, "is not authorized to deploy a contract")
+
+	// The deployment succeeds as if the allow list were disabled, or if the sender were an admin.
+	_, err = api.Call(context.Background(), deploy, genesisBlock, override(`{"disable": true}`))
+	require.NoError(t, err)
+	_, err = api.Call(context.Background(), deploy, genesisBlock, override(fmt.Sprintf(`{"adminAddresses": ["%s"]}`, testEthAddrs[1])))
+	require.NoError(t, err)
+	gas, err := api.EstimateGas(context.Background(), deploy, &genesisBlock, override(`{"disable": true}`))
+	require.NoError(t, err)
+	require.Greater(t, uint64(gas), params.TxGasContractCreation)
+	_, err = api.EstimateGas(context.Background(), deploy, &genesisBlock, nil)
+	require.ErrorContains(t, err, "is not authorized to deploy a contract")
+
+	// Only the configs of stateful precompiles can be overridden.
+	_, err = api.Call(context.Background(), deploy, genesisBlock, &ethapi.StateOverride{testEthAddrs[0]: {PrecompileConfig: json.RawMessage(`{"disable": true}`)}})
+	require.ErrorContains(t, err, "is not a stateful precompile")
+
+	// The overrides do not change the state of the chain.
+	state, err := vm.blockChain.State()
+	require.NoError(t, err)
+	require.Equal(t, precompile.AllowListNoRole, precompile.GetContractDeployerAllowListStatus(state, testEthAddrs[1]))
+}
+
+func TestPendingBlockEvent(t *testing.T) {
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	pendingBlocks := make(chan core.PendingBlockEvent, 1)
+	sub := vm.miner.SubscribePendingBlock(pendingBlocks)
+	defer sub.Unsubscribe()
+
+	tx := types.NewTransaction(uint64(0), testEthAddrs[1], firstTxAmount, 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	require.NoError(t, err)
+	for _, err := range vm.txPool.AddRemotesSync([]*types.Transaction{signedTx}) {
+		require.NoError(t, err)
+	}
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+
+	// The block is posted as soon as it is built, before it is accepted.
+	ev := <-pendingBlocks
+	require.Equal(t, blk.ID(), ids.ID(ev.Block.Hash()))
+	require.Len(t, ev.Block.Transactions(), 1)
+	require.Equal(t, signedTx.Hash(), ev.Block.Transactions()[0].Hash())
+	require.Len(t, ev.Receipts, 1)
+	require.Equal(t, types.ReceiptStatusSuccessful, ev.Receipts[0].Status)
+	require.Equal(t, uint64(0), vm.blockChain.LastAcceptedBlock().NumberU64())
+}
+
+func TestGetBlockReceipts(t *testing.T) {
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		require.NoError(t, vm.Shutdown(context.Background()))
+	}()
+
+	txs := make([]*types.Transaction, 2)
+	for i := range txs {
+		tx := types.NewTransaction(uint64(i), testEthAddrs[1], firstTxAmount, 21000, big.NewInt(testMinGasPrice), nil)
+		signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+		require.NoError(t, err)
+		txs[i] = signedTx
+	}
+	for _, err := range vm.txPool.AddRemotesSync(txs) {
+		require.NoError(t, err)
+	}
+	<-issuer
+
+	blk, err := vm.BuildBlock(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, blk.Verify(context.Background()))
+	require.NoError(t, vm.SetPreference(context.Background(), blk.ID()))
+	require.NoError(t, blk.Accept(context.Background()))
+	vm.blockChain.DrainAcceptorQueue()
+
+	var (
+		api      = ethapi.NewBlockChainAPI(vm.eth.APIBackend)
+		txAPI    = ethapi.NewTransactionAPI(vm.eth.APIBackend, new(ethapi.AddrLocker))
+		debugAPI = ethapi.NewDebugAPI(vm.eth.APIBackend)
+		blockNr  = rpc.BlockNumberOrHashWithNumber(1)
+	)
+	receipts, err := api.GetBlockReceipts(context.Background(), blockNr)
+	require.NoError(t, err)
+	require.Len(t, receipts, len(txs))
+	for i, tx := range txs {
+		receipt, err := txAPI.GetTransactionReceipt(context.Background(), tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, receipt, receipts[i])
+	}
+
+	rawReceipts, err := debugAPI.GetRawReceipts(context.Background(), blockNr)
+	require.NoError(t, err)
+	require.Len(t, rawReceipts, len(txs))
+	for i, raw := range rawReceipts {
+		receipt := new(types.Receipt)
+		require.NoError(t, receipt.UnmarshalBinary(raw))
+		require.Equal(t, receipts[i]["cumulativeGasUsed"], hexutil.Uint64(receipt.CumulativeGasUsed))
+	}
+
+	_, err = api.GetBlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(common.Hash{1}, false))
+	require.Error(t, err)
 }
 
 // Test Allow Fee Recipients is disabled and, etherbase must be blackhole address
@@ -2608,7 +3019,7 @@ func TestRewardManagerPrecompileSetRewardAddress(t *testing.T) {
 	genesis := &core.Genesis{}
 	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
 
-	genesis.Config.RewardManagerConfig = precompile.NewRewardManagerConfig(common.Big0, testEthAddrs[0:1], nil, nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewRewardManagerConfig(common.Big0, testEthAddrs[0:1], nil, nil))
 	genesis.Config.AllowFeeRecipients = true // enable this in genesis to test if this is recognized by the reward manager
 	genesisJSON, err := genesis.MarshalJSON()
 	require.NoError(t, err)
@@ -2748,7 +3159,7 @@ func TestRewardManagerPrecompileAllowFeeRecipients(t *testing.T) {
 	genesis := &core.Genesis{}
 	require.NoError(t, genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)))
 
-	genesis.Config.RewardManagerConfig = precompile.NewRewardManagerConfig(common.Big0, testEthAddrs[0:1], nil, nil)
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewRewardManagerConfig(common.Big0, testEthAddrs[0:1], nil, nil))
 	genesis.Config.AllowFeeRecipients = false // disable this in genesis
 	genesisJSON, err := genesis.MarshalJSON()
 	require.NoError(t, err)
@@ -2935,3 +3346,15 @@ func TestSkipChainConfigCheckCompatible(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, reinitVM.Shutdown(context.Background()))
 }
+
+// verifyTestProof verifies the Merkle [proof] of [key] against [root] and returns the proven value.
+func verifyTestProof(t *testing.T, root common.Hash, key []byte, proof []string) []byte {
+	proofDB := memorydb.New()
+	for _, node := range proof {
+		nodeBytes := common.FromHex(node)
+		require.NoError(t, proofDB.Put(crypto.Keccak256(nodeBytes), nodeBytes))
+	}
+	value, err := trie.VerifyProof(root, crypto.Keccak256(key), proofDB)
+	require.NoError(t, err)
+	return value
+}
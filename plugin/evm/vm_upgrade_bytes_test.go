@@ -11,10 +11,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/vms/components/chain"
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/metrics"
 	"github.com/ava-labs/subnet-evm/params"
@@ -27,9 +29,7 @@ func TestVMUpgradeBytesPrecompile(t *testing.T) {
 	enableAllowListTimestamp := time.Unix(0, 0) // enable at genesis
 	upgradeConfig := &params.UpgradeConfig{
 		PrecompileUpgrades: []params.PrecompileUpgrade{
-			{
-				TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil),
-			},
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil)),
 		},
 	}
 	upgradeBytesJSON, err := json.Marshal(upgradeConfig)
@@ -70,9 +70,7 @@ func TestVMUpgradeBytesPrecompile(t *testing.T) {
 	disableAllowListTimestamp := enableAllowListTimestamp.Add(10 * time.Hour) // arbitrary choice
 	upgradeConfig.PrecompileUpgrades = append(
 		upgradeConfig.PrecompileUpgrades,
-		params.PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(disableAllowListTimestamp.Unix())),
-		},
+		params.NewPrecompileUpgrade(precompile.NewDisableTxAllowListConfig(big.NewInt(disableAllowListTimestamp.Unix()))),
 	)
 	upgradeBytesJSON, err = json.Marshal(upgradeConfig)
 	if err != nil {
@@ -267,3 +265,206 @@ func TestVMUpgradeBytesNetworkUpgradesWithGenesis(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestVMUpdateUpgradeConfig(t *testing.T) {
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		if err := vm.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// schedule the TxAllowList after the current block without restarting the VM
+	enableAllowListTimestamp := time.Unix(10, 0)
+	upgradeConfig := params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil)),
+		},
+	}
+	assert.NoError(t, vm.updateUpgradeConfig(upgradeConfig))
+	assert.True(t, vm.chainConfig.IsTxAllowList(big.NewInt(1), big.NewInt(enableAllowListTimestamp.Unix())))
+	storedConfig := rawdb.ReadChainConfig(vm.chaindb, vm.genesisHash)
+	assert.Len(t, storedConfig.PrecompileUpgrades, 1)
+
+	// the network upgrades cannot be changed
+	invalidConfig := upgradeConfig
+	invalidConfig.NetworkUpgrades = &params.NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0)}
+	assert.ErrorIs(t, vm.updateUpgradeConfig(invalidConfig), errNetworkUpgradesChanged)
+
+	// the upgrades must be valid
+	invalidConfig = params.UpgradeConfig{
+		PrecompileUpgrades: append(upgradeConfig.PrecompileUpgrades, upgradeConfig.PrecompileUpgrades[0]),
+	}
+	var upgradeErr *params.UpgradeConfigError
+	assert.ErrorAs(t, vm.updateUpgradeConfig(invalidConfig), &upgradeErr)
+	assert.Equal(t, params.InvalidUpgradeSchedule, upgradeErr.Kind)
+
+	// build a block after the upgrade, the allow list is configured when the block is processed
+	vm.clock.Set(enableAllowListTimestamp)
+	tx := types.NewTransaction(uint64(0), testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	assert.NoError(t, err)
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{signedTx})
+	assert.NoError(t, errs[0])
+	issueAndAccept(t, issuer, vm)
+
+	state, err := vm.blockChain.State()
+	assert.NoError(t, err)
+	assert.Equal(t, precompile.AllowListAdmin, precompile.GetTxAllowListStatus(state, testEthAddrs[0]))
+
+	// upgrades which have already activated cannot be removed
+	assert.ErrorAs(t, vm.updateUpgradeConfig(params.UpgradeConfig{}), &upgradeErr)
+	assert.Equal(t, params.IncompatibleUpgrade, upgradeErr.Kind)
+}
+
+func TestVMUpdateUpgradeConfigProcessingBlocks(t *testing.T) {
+	issuer, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		if err := vm.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	enableAllowListTimestamp := time.Unix(10, 0)
+	upgradeConfig := params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1], nil)),
+		},
+	}
+	assert.NoError(t, vm.updateUpgradeConfig(upgradeConfig))
+
+	// verify a block after the upgrade without accepting it, and keep the genesis block preferred
+	vm.clock.Set(enableAllowListTimestamp)
+	tx := types.NewTransaction(uint64(0), testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	assert.NoError(t, err)
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{signedTx})
+	assert.NoError(t, errs[0])
+	<-issuer
+	blk, err := vm.BuildBlock(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, blk.Verify(context.Background()))
+	assert.NoError(t, vm.SetPreference(context.Background(), ids.ID(vm.genesisHash)))
+
+	// the upgrade activated in a processing block, so it cannot be removed
+	var upgradeErr *params.UpgradeConfigError
+	assert.ErrorAs(t, vm.updateUpgradeConfig(params.UpgradeConfig{}), &upgradeErr)
+	assert.Equal(t, params.IncompatibleUpgrade, upgradeErr.Kind)
+
+	// once the block is rejected, the upgrade has not activated on the chain anymore
+	assert.NoError(t, blk.Reject(context.Background()))
+	assert.NoError(t, vm.updateUpgradeConfig(params.UpgradeConfig{}))
+	assert.False(t, vm.chainConfig.IsTxAllowList(big.NewInt(1), big.NewInt(enableAllowListTimestamp.Unix())))
+}
+
+func TestVMUpdateUpgradeConfigRestart(t *testing.T) {
+	issuer, vm, dbManager, appSender := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	genesisBytes := buildGenesisTest(t, genesisJSONSubnetEVM)
+
+	enableAllowListTimestamp := big.NewInt(10)
+	upgradeConfig := params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewTxAllowListConfig(enableAllowListTimestamp, testEthAddrs[0:1], nil)),
+		},
+	}
+	assert.NoError(t, vm.updateUpgradeConfig(upgradeConfig))
+	if err := vm.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the applied upgrade config is used after a restart with the same upgrade bytes
+	restartedVM := &VM{}
+	if err := restartedVM.Initialize(
+		context.Background(), NewContext(), dbManager, genesisBytes, []byte(""), []byte(""), issuer, []*common.Fx{}, appSender,
+	); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, restartedVM.chainConfig.IsTxAllowList(big.NewInt(1), enableAllowListTimestamp))
+	if err := restartedVM.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// updated upgrade bytes take precedence over the applied upgrade config
+	enableDeployerAllowListTimestamp := big.NewInt(20)
+	upgradeBytesJSON, err := json.Marshal(&params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{
+			params.NewPrecompileUpgrade(precompile.NewContractDeployerAllowListConfig(enableDeployerAllowListTimestamp, testEthAddrs[0:1], nil)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not marshal upgradeConfig to json: %s", err)
+	}
+	restartedVM = &VM{}
+	if err := restartedVM.Initialize(
+		context.Background(), NewContext(), dbManager, genesisBytes, upgradeBytesJSON, []byte(""), issuer, []*common.Fx{}, appSender,
+	); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := restartedVM.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	assert.False(t, restartedVM.chainConfig.IsTxAllowList(big.NewInt(1), enableAllowListTimestamp))
+	assert.True(t, restartedVM.chainConfig.IsContractDeployerAllowList(big.NewInt(1), enableDeployerAllowListTimestamp))
+}
+
+func TestVMUpgradeBytesFeeManagerMigration(t *testing.T) {
+	genesis := &core.Genesis{}
+	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
+		t.Fatal(err)
+	}
+	genesis.Config.PrecompileUpgrade.SetConfig(precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1], nil, nil))
+	genesisJSON, err := genesis.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// migrate the storage of the fee config manager to version 1 with the same parameters
+	migrateTimestamp := time.Unix(10, 0)
+	migrateConfig := precompile.NewFeeManagerConfig(big.NewInt(migrateTimestamp.Unix()), testEthAddrs[0:1], nil, nil)
+	migrateConfig.StorageVersion = 1
+	upgradeConfig := params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{params.NewPrecompileUpgrade(migrateConfig)},
+	}
+	upgradeBytesJSON, err := json.Marshal(&upgradeConfig)
+	if err != nil {
+		t.Fatalf("could not marshal upgradeConfig to json: %s", err)
+	}
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", string(upgradeBytesJSON))
+	defer func() {
+		if err := vm.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	genesisState, err := vm.blockChain.StateAt(vm.blockChain.Genesis().Root())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), precompile.GetPrecompileStorageVersion(genesisState, precompile.FeeConfigManagerAddress))
+	assert.Zero(t, precompile.GetFeeConfigHash(genesisState))
+
+	// a migration cannot change the parameters of the precompile, since they would not be applied
+	invalidConfig := precompile.NewFeeManagerConfig(big.NewInt(migrateTimestamp.Unix()), testEthAddrs[1:2], nil, nil)
+	invalidConfig.StorageVersion = 1
+	var upgradeErr *params.UpgradeConfigError
+	assert.ErrorAs(t, vm.updateUpgradeConfig(params.UpgradeConfig{
+		PrecompileUpgrades: []params.PrecompileUpgrade{params.NewPrecompileUpgrade(invalidConfig)},
+	}), &upgradeErr)
+	assert.Equal(t, params.InvalidUpgradeSchedule, upgradeErr.Kind)
+
+	// the storage is migrated when the first block after the upgrade is processed
+	vm.clock.Set(migrateTimestamp)
+	tx := types.NewTransaction(uint64(0), testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	assert.NoError(t, err)
+	errs := vm.txPool.AddRemotesSync([]*types.Transaction{signedTx})
+	assert.NoError(t, errs[0])
+	issueAndAccept(t, issuer, vm)
+
+	state, err := vm.blockChain.State()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), precompile.GetPrecompileStorageVersion(state, precompile.FeeConfigManagerAddress))
+	assert.Equal(t, precompile.FeeConfigHash(vm.chainConfig.FeeConfig), precompile.GetFeeConfigHash(state))
+	assert.Equal(t, precompile.AllowListAdmin, precompile.GetFeeConfigManagerStatus(state, testEthAddrs[0]))
+	assert.Equal(t, migrateConfig, vm.chainConfig.GetFeeConfigManagerConfig(big.NewInt(1), big.NewInt(migrateTimestamp.Unix())))
+}
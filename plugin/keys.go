@@ -4,5 +4,11 @@
 package main
 
 const (
-	versionKey = "version"
+	versionKey        = "version"
+	precompilesKey    = "precompiles"
+	devKey            = "dev"
+	devAddressKey     = "dev-address"
+	devGenesisKey     = "dev-genesis"
+	devPrecompilesKey = "dev-precompiles"
+	devLogLevelKey    = "dev-log-level"
 )
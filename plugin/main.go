@@ -4,6 +4,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
 
 	"github.com/ava-labs/subnet-evm/plugin/evm"
+	"github.com/ava-labs/subnet-evm/precompile"
 )
 
 func main() {
@@ -24,6 +26,37 @@ func main() {
 		fmt.Println(evm.Version)
 		os.Exit(0)
 	}
+	precompiles, err := PrintPrecompiles()
+	if err != nil {
+		fmt.Printf("couldn't get config: %s", err)
+		os.Exit(1)
+	}
+	if precompiles {
+		exports, err := precompile.ExportModules()
+		if err != nil {
+			fmt.Printf("couldn't export precompiles: %s", err)
+			os.Exit(1)
+		}
+		exportsJSON, err := json.MarshalIndent(exports, "", "  ")
+		if err != nil {
+			fmt.Printf("couldn't encode precompiles: %s", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(exportsJSON))
+		os.Exit(0)
+	}
+	runDevChain, err := RunDev()
+	if err != nil {
+		fmt.Printf("couldn't get config: %s", err)
+		os.Exit(1)
+	}
+	if runDevChain {
+		if err := runDev(); err != nil {
+			fmt.Printf("failed to run development chain: %s", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	if err := ulimit.Set(ulimit.DefaultFDLimit, logging.NoLog{}); err != nil {
 		fmt.Printf("failed to set fd limit correctly due to: %s", err)
 		os.Exit(1)
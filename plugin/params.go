@@ -5,15 +5,28 @@ package main
 
 import (
 	"flag"
+	"sync"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+var (
+	parsedViper *viper.Viper
+	viperErr    error
+	viperOnce   sync.Once
+)
+
 func subnetEVMFlagSet() *flag.FlagSet {
 	fs := flag.NewFlagSet("subnet-evm", flag.ContinueOnError)
 
 	fs.Bool(versionKey, false, "If true, print version and quit")
+	fs.Bool(precompilesKey, false, "If true, print the addresses, ABIs, selectors and gas costs of the stateful precompiles as JSON and quit")
+	fs.Bool(devKey, false, "If true, run a single node development chain without AvalancheGo, accepting a block as soon as transactions are pending")
+	fs.String(devAddressKey, "127.0.0.1:9650", "Address the APIs of the development chain are served at")
+	fs.String(devGenesisKey, "", "Path to the genesis of the development chain (default = a genesis funding the development accounts)")
+	fs.String(devPrecompilesKey, "", "Comma separated config keys of the precompiles enabled by the default development genesis, with the development accounts as admins, or \"all\"")
+	fs.String(devLogLevelKey, "info", "Log level of the development chain")
 
 	return fs
 }
@@ -33,13 +46,30 @@ func getViper() (*viper.Viper, error) {
 }
 
 func PrintVersion() (bool, error) {
-	v, err := getViper()
+	return getFlag(versionKey)
+}
+
+func PrintPrecompiles() (bool, error) {
+	return getFlag(precompilesKey)
+}
+
+func RunDev() (bool, error) {
+	return getFlag(devKey)
+}
+
+// getFlag returns the value of the boolean flag [key].
+func getFlag(key string) (bool, error) {
+	v, err := parsedFlags()
 	if err != nil {
 		return false, err
 	}
+	return v.GetBool(key), nil
+}
 
-	if v.GetBool(versionKey) {
-		return true, nil
-	}
-	return false, nil
+// parsedFlags returns the viper environment of the flags, which are parsed once.
+func parsedFlags() (*viper.Viper, error) {
+	viperOnce.Do(func() {
+		parsedViper, viperErr = getViper()
+	})
+	return parsedViper, viperErr
 }
@@ -0,0 +1,493 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// addressBlocklistLogGasCost covers a log with two topics (LogGas + 2 * LogTopicGas).
+	addressBlocklistLogGasCost uint64 = 375 + 2*375
+
+	BlockAddressGasCost        uint64 = 3*writeGasCostPerSlot + ReadAllowListGasCost + addressBlocklistLogGasCost // write membership, entry and count + read allow list + log
+	UnblockAddressGasCost      uint64 = 5*writeGasCostPerSlot + ReadAllowListGasCost + addressBlocklistLogGasCost // swap and pop the entry + read allow list + log
+	IsBlockedGasCost           uint64 = readGasCostPerSlot
+	BlockedAddressCountGasCost uint64 = readGasCostPerSlot
+	BlockedAddressAtGasCost    uint64 = 2 * readGasCostPerSlot // read count + read entry
+
+	// AddressBlocklistRawABI contains the raw ABI of AddressBlocklist contract.
+	AddressBlocklistRawABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"AddressBlocked\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"AddressUnblocked\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"blockAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"index\",\"type\":\"uint256\"}],\"name\":\"blockedAddressAt\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"blockedAddressCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"count\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"isBlocked\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"blocked\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"unblockAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &AddressBlocklistConfig{}
+
+	ErrCannotBlockAddress        = errors.New("non-enabled cannot call blockAddress")
+	ErrCannotUnblockAddress      = errors.New("non-enabled cannot call unblockAddress")
+	ErrAddressAlreadyBlocked     = errors.New("address is already blocked")
+	ErrAddressNotBlocked         = errors.New("address is not blocked")
+	ErrBlockedAddressOutOfBounds = errors.New("blocked address index out of bounds")
+
+	// ErrSenderAddressBlocked is returned when a blocked address attempts to issue a transaction.
+	ErrSenderAddressBlocked = errors.New("cannot issue transaction from blocked address")
+	// ErrRecipientAddressBlocked is returned when a call attempts to transfer value to a blocked address.
+	ErrRecipientAddressBlocked = errors.New("cannot transfer value to blocked address")
+
+	AddressBlocklistABI        abi.ABI                     // will be initialized by init function
+	AddressBlocklistPrecompile StatefulPrecompiledContract // will be initialized by init function
+
+	// Storage layout of the blocklist. The allow list uses [address.Hash()] as its keys, so the blocklist
+	// keys are derived by hashing a prefix with the address or index to avoid any overlap.
+	blockedAddressCountStorageKey = common.Hash{'b', 'a', 'c', 's', 'k'}
+	blockedAddressIndexPrefix     = []byte("blockedAddressIndex")
+	blockedAddressEntryPrefix     = []byte("blockedAddressEntry")
+)
+
+// AddressBlocklistConfig implements the StatefulPrecompileConfig interface while adding in the
+// AddressBlocklist specific precompile config. Addresses on the blocklist are rejected as
+// transaction senders and as recipients of value transfers.
+// The allow list controls which addresses are permitted to modify the blocklist.
+type AddressBlocklistConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+	BlockedAddresses []common.Address `json:"blockedAddresses,omitempty"` // initial blocked addresses
+}
+
+// AddressBlocklistConfigKey is the JSON key of the AddressBlocklist config in the chain config and precompile upgrades.
+const AddressBlocklistConfigKey = "addressBlocklistConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(AddressBlocklistRawABI))
+	if err != nil {
+		panic(err)
+	}
+	AddressBlocklistABI = parsed
+	AddressBlocklistPrecompile = createAddressBlocklistPrecompile(AddressBlocklistAddress)
+
+	RegisterModule(Module{
+		ConfigKey: AddressBlocklistConfigKey,
+		Address:   AddressBlocklistAddress,
+		Contract:  AddressBlocklistPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(AddressBlocklistConfig) },
+		ABI:       &AddressBlocklistABI,
+		RawABI:    AddressBlocklistRawABI,
+		GasCosts: allowListGasCosts(map[string]uint64{
+			"blockAddress":        BlockAddressGasCost,
+			"unblockAddress":      UnblockAddressGasCost,
+			"isBlocked":           IsBlockedGasCost,
+			"blockedAddressCount": BlockedAddressCountGasCost,
+			"blockedAddressAt":    BlockedAddressAtGasCost,
+		}),
+		StorageSlot: addressBlocklistStorageSlot,
+		StorageSlotName: func(key common.Hash) (string, bool) {
+			return "blockedAddressCount", key == blockedAddressCountStorageKey
+		},
+	})
+}
+
+// NewAddressBlocklistConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// AddressBlocklist with the given [admins] and [enableds] as members of the allowlist and [blocked]
+// as the initial members of the blocklist.
+func NewAddressBlocklistConfig(blockTimestamp *big.Int, admins []common.Address, enableds []common.Address, blocked []common.Address) *AddressBlocklistConfig {
+	return &AddressBlocklistConfig{
+		AllowListConfig: AllowListConfig{
+			AllowListAdmins:  admins,
+			EnabledAddresses: enableds,
+		},
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+		BlockedAddresses:  blocked,
+	}
+}
+
+// NewDisableAddressBlocklistConfig returns config for a network upgrade at [blockTimestamp]
+// that disables AddressBlocklist.
+func NewDisableAddressBlocklistConfig(blockTimestamp *big.Int) *AddressBlocklistConfig {
+	return &AddressBlocklistConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the address blocklist.
+func (c *AddressBlocklistConfig) Address() common.Address {
+	return AddressBlocklistAddress
+}
+
+// Configure configures [state] with the desired admins and initial blocked addresses based on [c].
+func (c *AddressBlocklistConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	c.AllowListConfig.Configure(state, AddressBlocklistAddress)
+	for _, blockedAddr := range c.BlockedAddresses {
+		BlockAddress(state, blockedAddr)
+	}
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the address blocklist.
+func (c *AddressBlocklistConfig) Contract() StatefulPrecompiledContract {
+	return AddressBlocklistPrecompile
+}
+
+// Verify returns an error if the allow list is invalid or if [BlockedAddresses] contains a duplicate.
+func (c *AddressBlocklistConfig) Verify() error {
+	if err := c.AllowListConfig.Verify(); err != nil {
+		return err
+	}
+	blocked := make(map[common.Address]struct{}, len(c.BlockedAddresses))
+	for _, blockedAddr := range c.BlockedAddresses {
+		if _, ok := blocked[blockedAddr]; ok {
+			return fmt.Errorf("duplicate address %s in blocked addresses", blockedAddr)
+		}
+		blocked[blockedAddr] = struct{}{}
+	}
+	return nil
+}
+
+// Equal returns true if [s] is a [*AddressBlocklistConfig] and it has been configured identical to [c].
+func (c *AddressBlocklistConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*AddressBlocklistConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) &&
+		c.AllowListConfig.Equal(&other.AllowListConfig) &&
+		areEqualAddressLists(c.BlockedAddresses, other.BlockedAddresses)
+}
+
+// String returns a string representation of the AddressBlocklistConfig.
+func (c *AddressBlocklistConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// GetAddressBlocklistAllowListStatus returns the role of [address] for the AddressBlocklist allow list.
+func GetAddressBlocklistAllowListStatus(stateDB StateDB, address common.Address) AllowListRole {
+	return getAllowListStatus(stateDB, AddressBlocklistAddress, address)
+}
+
+// SetAddressBlocklistAllowListStatus sets the permissions of [address] to [role] for the
+// AddressBlocklist allow list. Assumes [role] has already been verified as valid.
+func SetAddressBlocklistAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, AddressBlocklistAddress, address, role)
+}
+
+// addressBlocklistStorageSlot resolves the "blockedAddressCount", "blockedAddressIndex[<address>]"
+// and "blockedAddressEntry[<index>]" storage slots.
+func addressBlocklistStorageSlot(name string) (common.Hash, bool) {
+	if name == "blockedAddressCount" {
+		return blockedAddressCountStorageKey, true
+	}
+	field, key, ok := parseIndexedStorageSlot(name)
+	if !ok {
+		return common.Hash{}, false
+	}
+	switch field {
+	case "blockedAddressIndex":
+		if address, ok := parseStorageSlotAddress(key); ok {
+			return blockedAddressIndexKey(address), true
+		}
+	case "blockedAddressEntry":
+		if index, ok := parseStorageSlotIndex(key); ok {
+			return blockedAddressEntryKey(index), true
+		}
+	}
+	return common.Hash{}, false
+}
+
+// blockedAddressIndexKey returns the storage key holding the 1-based position of [address] in the blocklist.
+func blockedAddressIndexKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(blockedAddressIndexPrefix, address.Bytes())
+}
+
+// blockedAddressEntryKey returns the storage key holding the blocked address at [index].
+func blockedAddressEntryKey(index uint64) common.Hash {
+	return crypto.Keccak256Hash(blockedAddressEntryPrefix, common.BigToHash(new(big.Int).SetUint64(index)).Bytes())
+}
+
+// IsAddressBlocked returns true if [address] is on the blocklist.
+func IsAddressBlocked(stateDB StateDB, address common.Address) bool {
+	return stateDB.GetState(AddressBlocklistAddress, blockedAddressIndexKey(address)) != (common.Hash{})
+}
+
+// GetBlockedAddressCount returns the number of addresses on the blocklist.
+func GetBlockedAddressCount(stateDB StateDB) uint64 {
+	return stateDB.GetState(AddressBlocklistAddress, blockedAddressCountStorageKey).Big().Uint64()
+}
+
+// GetBlockedAddressAt returns the blocked address at [index] and false if [index] is out of bounds.
+func GetBlockedAddressAt(stateDB StateDB, index uint64) (common.Address, bool) {
+	if index >= GetBlockedAddressCount(stateDB) {
+		return common.Address{}, false
+	}
+	val := stateDB.GetState(AddressBlocklistAddress, blockedAddressEntryKey(index))
+	return common.BytesToAddress(val.Bytes()), true
+}
+
+// BlockAddress adds [address] to the blocklist.
+// Returns false if [address] was already blocked.
+func BlockAddress(stateDB StateDB, address common.Address) bool {
+	if IsAddressBlocked(stateDB, address) {
+		return false
+	}
+	count := GetBlockedAddressCount(stateDB)
+	stateDB.SetState(AddressBlocklistAddress, blockedAddressEntryKey(count), address.Hash())
+	stateDB.SetState(AddressBlocklistAddress, blockedAddressIndexKey(address), common.BigToHash(new(big.Int).SetUint64(count+1)))
+	stateDB.SetState(AddressBlocklistAddress, blockedAddressCountStorageKey, common.BigToHash(new(big.Int).SetUint64(count+1)))
+	return true
+}
+
+// UnblockAddress removes [address] from the blocklist by moving the last entry into its position.
+// Returns false if [address] was not blocked.
+func UnblockAddress(stateDB StateDB, address common.Address) bool {
+	indexKey := blockedAddressIndexKey(address)
+	position := stateDB.GetState(AddressBlocklistAddress, indexKey).Big().Uint64()
+	if position == 0 {
+		return false
+	}
+	var (
+		index     = position - 1
+		lastIndex = GetBlockedAddressCount(stateDB) - 1
+	)
+	if index != lastIndex {
+		lastAddr, _ := GetBlockedAddressAt(stateDB, lastIndex)
+		stateDB.SetState(AddressBlocklistAddress, blockedAddressEntryKey(index), lastAddr.Hash())
+		stateDB.SetState(AddressBlocklistAddress, blockedAddressIndexKey(lastAddr), common.BigToHash(new(big.Int).SetUint64(position)))
+	}
+	stateDB.SetState(AddressBlocklistAddress, blockedAddressEntryKey(lastIndex), common.Hash{})
+	stateDB.SetState(AddressBlocklistAddress, indexKey, common.Hash{})
+	stateDB.SetState(AddressBlocklistAddress, blockedAddressCountStorageKey, common.BigToHash(new(big.Int).SetUint64(lastIndex)))
+	return true
+}
+
+// PackBlockAddress packs [account] of type common.Address into the appropriate arguments for blockAddress.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackBlockAddress(account common.Address) ([]byte, error) {
+	return AddressBlocklistABI.Pack("blockAddress", account)
+}
+
+// PackUnblockAddress packs [account] of type common.Address into the appropriate arguments for unblockAddress.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackUnblockAddress(account common.Address) ([]byte, error) {
+	return AddressBlocklistABI.Pack("unblockAddress", account)
+}
+
+// PackIsBlocked packs [account] of type common.Address into the appropriate arguments for isBlocked.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackIsBlocked(account common.Address) ([]byte, error) {
+	return AddressBlocklistABI.Pack("isBlocked", account)
+}
+
+// PackIsBlockedOutput attempts to pack given blocked of type bool
+// to conform the ABI outputs.
+func PackIsBlockedOutput(blocked bool) ([]byte, error) {
+	return AddressBlocklistABI.PackOutput("isBlocked", blocked)
+}
+
+// PackBlockedAddressCount packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackBlockedAddressCount() ([]byte, error) {
+	return AddressBlocklistABI.Pack("blockedAddressCount")
+}
+
+// PackBlockedAddressCountOutput attempts to pack given count of type *big.Int
+// to conform the ABI outputs.
+func PackBlockedAddressCountOutput(count *big.Int) ([]byte, error) {
+	return AddressBlocklistABI.PackOutput("blockedAddressCount", count)
+}
+
+// PackBlockedAddressAt packs [index] of type *big.Int into the appropriate arguments for blockedAddressAt.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackBlockedAddressAt(index *big.Int) ([]byte, error) {
+	return AddressBlocklistABI.Pack("blockedAddressAt", index)
+}
+
+// PackBlockedAddressAtOutput attempts to pack given account of type common.Address
+// to conform the ABI outputs.
+func PackBlockedAddressAtOutput(account common.Address) ([]byte, error) {
+	return AddressBlocklistABI.PackOutput("blockedAddressAt", account)
+}
+
+// UnpackAddressBlocklistAddressInput attempts to unpack [input] into the common.Address argument
+// of the function [name]. Assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackAddressBlocklistAddressInput(name string, input []byte) (common.Address, error) {
+	res, err := AddressBlocklistABI.UnpackInput(name, input)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	return unpacked, nil
+}
+
+// UnpackBlockedAddressAtInput attempts to unpack [input] into the *big.Int type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackBlockedAddressAtInput(input []byte) (*big.Int, error) {
+	res, err := AddressBlocklistABI.UnpackInput("blockedAddressAt", input)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// emitAddressBlocklistEvent adds a log for the event [name] with [account] as its indexed topic.
+func emitAddressBlocklistEvent(accessibleState PrecompileAccessibleState, name string, account common.Address) error {
+	topics, data, err := AddressBlocklistABI.PackEvent(name, account)
+	if err != nil {
+		return err
+	}
+	accessibleState.GetStateDB().AddLog(AddressBlocklistAddress, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+	return nil
+}
+
+func blockAddress(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, BlockAddressGasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := UnpackAddressBlocklistAddressInput("blockAddress", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to modify the blocklist
+	callerStatus := getAllowListStatus(stateDB, AddressBlocklistAddress, caller)
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotBlockAddress, caller)
+	}
+
+	if !BlockAddress(stateDB, account) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrAddressAlreadyBlocked, account)
+	}
+	if err := emitAddressBlocklistEvent(accessibleState, "AddressBlocked", account); err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+func unblockAddress(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, UnblockAddressGasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := UnpackAddressBlocklistAddressInput("unblockAddress", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to modify the blocklist
+	callerStatus := getAllowListStatus(stateDB, AddressBlocklistAddress, caller)
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotUnblockAddress, caller)
+	}
+
+	if !UnblockAddress(stateDB, account) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrAddressNotBlocked, account)
+	}
+	if err := emitAddressBlocklistEvent(accessibleState, "AddressUnblocked", account); err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+func isBlocked(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, IsBlockedGasCost); err != nil {
+		return nil, 0, err
+	}
+	account, err := UnpackAddressBlocklistAddressInput("isBlocked", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := PackIsBlockedOutput(IsAddressBlocked(accessibleState.GetStateDB(), account))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func blockedAddressCount(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, BlockedAddressCountGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	count := GetBlockedAddressCount(accessibleState.GetStateDB())
+	packedOutput, err := PackBlockedAddressCountOutput(new(big.Int).SetUint64(count))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func blockedAddressAt(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, BlockedAddressAtGasCost); err != nil {
+		return nil, 0, err
+	}
+	index, err := UnpackBlockedAddressAtInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if !index.IsUint64() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrBlockedAddressOutOfBounds, index)
+	}
+
+	account, ok := GetBlockedAddressAt(accessibleState.GetStateDB(), index.Uint64())
+	if !ok {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrBlockedAddressOutOfBounds, index)
+	}
+	packedOutput, err := PackBlockedAddressAtOutput(account)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// createAddressBlocklistPrecompile returns a StatefulPrecompiledContract with R/W control of the blocklist.
+// Access to the setters is controlled by an allow list for [precompileAddr].
+func createAddressBlocklistPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	var functions []*statefulPrecompileFunction
+	functions = append(functions, createAllowListFunctions(precompileAddr)...)
+
+	abiFunctionMap := map[string]RunStatefulPrecompileFunc{
+		"blockAddress":        blockAddress,
+		"unblockAddress":      unblockAddress,
+		"isBlocked":           isBlocked,
+		"blockedAddressCount": blockedAddressCount,
+		"blockedAddressAt":    blockedAddressAt,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := AddressBlocklistABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, newStatefulPrecompileFunction(method.Name, method.ID, method.IsConstant(), function))
+	}
+
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
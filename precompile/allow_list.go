@@ -7,8 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
-	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -32,12 +33,25 @@ var (
 	setEnabledSignature    = CalculateFunctionSelector("setEnabled(address)")
 	setNoneSignature       = CalculateFunctionSelector("setNone(address)")
 	readAllowListSignature = CalculateFunctionSelector("readAllowList(address)")
+
+	// AllowListRawABI contains the raw ABI of the allow list functions shared by allow list precompiles.
+	AllowListRawABI         = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"RoleSet\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+	AllowListABI    abi.ABI // will be initialized by init function
+
 	// Error returned when an invalid write is attempted
 	ErrCannotModifyAllowList = errors.New("non-admin cannot modify allow list")
 
 	allowListInputLen = common.HashLength
 )
 
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(AllowListRawABI))
+	if err != nil {
+		panic(err)
+	}
+	AllowListABI = parsed
+}
+
 // AllowListConfig specifies the initial set of allow list admins.
 type AllowListConfig struct {
 	AllowListAdmins  []common.Address `json:"adminAddresses"`
@@ -111,12 +125,26 @@ func (c *AllowListConfig) Verify() error {
 	return nil
 }
 
+// allowListCacheKey is the key under which the role of an address is cached for an
+// allow list precompile, if the StateDB implements [ReadCache].
+type allowListCacheKey common.Address
+
 // getAllowListStatus returns the allow list role of [address] for the precompile
 // at [precompileAddr]
 func getAllowListStatus(state StateDB, precompileAddr common.Address, address common.Address) AllowListRole {
+	cache, ok := state.(ReadCache)
+	if ok {
+		if role, cached := cache.GetCachedRead(precompileAddr, allowListCacheKey(address)); cached {
+			return role.(AllowListRole)
+		}
+	}
 	// Generate the state key for [address]
 	addressKey := address.Hash()
-	return AllowListRole(state.GetState(precompileAddr, addressKey))
+	role := AllowListRole(state.GetState(precompileAddr, addressKey))
+	if ok {
+		cache.CacheRead(precompileAddr, allowListCacheKey(address), role)
+	}
+	return role
 }
 
 // setAllowListRole sets the permissions of [address] to [role] for the precompile
@@ -133,6 +161,18 @@ func setAllowListRole(stateDB StateDB, precompileAddr, address common.Address, r
 	stateDB.SetState(precompileAddr, addressKey, common.Hash(role))
 }
 
+// GetAllowListRole returns the allow list role of [address] for the precompile at [precompileAddr].
+func GetAllowListRole(stateDB StateDB, precompileAddr common.Address, address common.Address) AllowListRole {
+	return getAllowListStatus(stateDB, precompileAddr, address)
+}
+
+// SetAllowListRole sets the allow list role of [address] to [role] for the precompile at [precompileAddr],
+// without checking the permissions of any caller (e.g. to set up the state of a test).
+// Assumes [role] has already been verified as valid.
+func SetAllowListRole(stateDB StateDB, precompileAddr common.Address, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, precompileAddr, address, role)
+}
+
 // PackModifyAllowList packs [address] and [role] into the appropriate arguments for modifying the allow list.
 // Note: [role] is not packed in the input value returned, but is instead used as a selector for the function
 // selector that should be encoded in the input.
@@ -163,33 +203,54 @@ func PackReadAllowList(address common.Address) []byte {
 	return input
 }
 
+// CallModifyAllowList sets the role of [address] to [role] in the allow list of the precompile at [precompileAddr],
+// on behalf of [caller] which must be an admin of the allow list. At most [gas] is forwarded to the precompile and
+// the remaining gas is returned.
+// This allows a precompile to manage the allow list of another precompile without accessing its storage directly.
+func CallModifyAllowList(accessibleState PrecompileAccessibleState, caller common.Address, precompileAddr common.Address, address common.Address, role AllowListRole, gas uint64, readOnly bool) (remainingGas uint64, err error) {
+	input, err := PackModifyAllowList(address, role)
+	if err != nil {
+		return gas, err
+	}
+	_, remainingGas, err = accessibleState.CallPrecompile(caller, precompileAddr, input, gas, readOnly)
+	return remainingGas, err
+}
+
+// CallReadAllowList returns the role of [address] in the allow list of the precompile at [precompileAddr], on behalf of [caller].
+// At most [gas] is forwarded to the precompile and the remaining gas is returned.
+func CallReadAllowList(accessibleState PrecompileAccessibleState, caller common.Address, precompileAddr common.Address, address common.Address, gas uint64) (role AllowListRole, remainingGas uint64, err error) {
+	ret, remainingGas, err := accessibleState.CallPrecompile(caller, precompileAddr, PackReadAllowList(address), gas, true)
+	if err != nil {
+		return AllowListNoRole, remainingGas, err
+	}
+	if len(ret) != common.HashLength {
+		return AllowListNoRole, remainingGas, fmt.Errorf("invalid output length for read allow list: %d", len(ret))
+	}
+	return AllowListRole(common.BytesToHash(ret)), remainingGas, nil
+}
+
 // createAllowListRoleSetter returns an execution function for setting the allow list status of the input address argument to [role].
 // This execution function is speciifc to [precompileAddr].
 func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole) RunStatefulPrecompileFunc {
 	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-		if remainingGas, err = deductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
-			return nil, 0, err
-		}
-
 		if len(input) != allowListInputLen {
-			return nil, remainingGas, fmt.Errorf("invalid input length for modifying allow list: %d", len(input))
+			return nil, suppliedGas, fmt.Errorf("invalid input length for modifying allow list: %d", len(input))
 		}
 
 		modifyAddress := common.BytesToAddress(input)
 
-		if readOnly {
-			return nil, remainingGas, vmerrs.ErrWriteProtection
-		}
-
 		stateDB := evm.GetStateDB()
 
 		// Verify that the caller is in the allow list and therefore has the right to modify it
 		callerStatus := getAllowListStatus(stateDB, precompileAddr, callerAddr)
 		if !callerStatus.IsAdmin() {
-			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
+			return nil, suppliedGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
 		}
 
 		setAllowListRole(stateDB, precompileAddr, modifyAddress, role)
+		if remainingGas, err = emitPrecompileEvent(evm, precompileAddr, suppliedGas, "RoleSet", common.Hash(role).Big(), modifyAddress, callerAddr); err != nil {
+			return nil, remainingGas, err
+		}
 		// Return an empty output and the remaining gas
 		return []byte{}, remainingGas, nil
 	}
@@ -200,18 +261,14 @@ func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole
 // designated role of that address
 func createReadAllowList(precompileAddr common.Address) RunStatefulPrecompileFunc {
 	return func(evm PrecompileAccessibleState, callerAddr common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-		if remainingGas, err = deductGas(suppliedGas, ReadAllowListGasCost); err != nil {
-			return nil, 0, err
-		}
-
 		if len(input) != allowListInputLen {
-			return nil, remainingGas, fmt.Errorf("invalid input length for read allow list: %d", len(input))
+			return nil, suppliedGas, fmt.Errorf("invalid input length for read allow list: %d", len(input))
 		}
 
 		readAddress := common.BytesToAddress(input)
 		role := getAllowListStatus(evm.GetStateDB(), precompileAddr, readAddress)
 		roleBytes := common.Hash(role).Bytes()
-		return roleBytes, remainingGas, nil
+		return roleBytes, suppliedGas, nil
 	}
 }
 
@@ -219,15 +276,32 @@ func createReadAllowList(precompileAddr common.Address) RunStatefulPrecompileFun
 func createAllowListPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
 	// Construct the contract with no fallback function.
 	allowListFuncs := createAllowListFunctions(precompileAddr)
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, allowListFuncs)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, allowListFuncs)
 	return contract
 }
 
+// createAllowListFunctions returns the allow list functions for [precompileAddr]. Their fixed gas costs are
+// deducted before they are executed.
 func createAllowListFunctions(precompileAddr common.Address) []*statefulPrecompileFunction {
-	setAdmin := newStatefulPrecompileFunction(setAdminSignature, createAllowListRoleSetter(precompileAddr, AllowListAdmin))
-	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
-	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
-	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	setAdmin := newStatefulPrecompileFunctionWithGas("setAdmin", setAdminSignature, false, fixedGas(ModifyAllowListGasCost), createAllowListRoleSetter(precompileAddr, AllowListAdmin))
+	setEnabled := newStatefulPrecompileFunctionWithGas("setEnabled", setEnabledSignature, false, fixedGas(ModifyAllowListGasCost), createAllowListRoleSetter(precompileAddr, AllowListEnabled))
+	setNone := newStatefulPrecompileFunctionWithGas("setNone", setNoneSignature, false, fixedGas(ModifyAllowListGasCost), createAllowListRoleSetter(precompileAddr, AllowListNoRole))
+	read := newStatefulPrecompileFunctionWithGas("readAllowList", readAllowListSignature, true, fixedGas(ReadAllowListGasCost), createReadAllowList(precompileAddr))
 
 	return []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read}
 }
+
+// allowListGasCosts returns [gasCosts] with the gas costs of the functions created by [createAllowListFunctions],
+// for the [Module.GasCosts] of precompiles with an allow list.
+func allowListGasCosts(gasCosts map[string]uint64) map[string]uint64 {
+	allowListCosts := map[string]uint64{
+		"setAdmin":      ModifyAllowListGasCost,
+		"setEnabled":    ModifyAllowListGasCost,
+		"setNone":       ModifyAllowListGasCost,
+		"readAllowList": ReadAllowListGasCost,
+	}
+	for name, gasCost := range gasCosts {
+		allowListCosts[name] = gasCost
+	}
+	return allowListCosts
+}
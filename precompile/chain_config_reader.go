@@ -0,0 +1,376 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Values returned by feeConfigSource
+	FeeConfigSourceGenesis    uint8 = 0 // fee config is read from the genesis chain config
+	FeeConfigSourceFeeManager uint8 = 1 // fee config is read from the FeeConfigManager precompile state
+
+	// Note: the chain config is held in memory, so reads are priced as a single storage read.
+	ReadChainConfigGasCost uint64 = readGasCostPerSlot
+
+	// ChainConfigReaderRawABI contains the raw ABI of ChainConfigReader contract.
+	ChainConfigReaderRawABI = "[{\"inputs\":[],\"name\":\"allowFeeRecipients\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"allowed\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"chainId\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"chainId\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"configHash\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"hash\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"enabledPrecompiles\",\"outputs\":[{\"internalType\":\"address[]\",\"name\":\"precompiles\",\"type\":\"address[]\"},{\"internalType\":\"uint256[]\",\"name\":\"timestamps\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"feeConfigSource\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"source\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"precompileAddr\",\"type\":\"address\"}],\"name\":\"precompileActivation\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"enabled\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"subnetEVMTimestamp\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"activated\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &ChainConfigReaderConfig{}
+
+	ChainConfigReaderABI        abi.ABI                     // will be initialized by init function
+	ChainConfigReaderPrecompile StatefulPrecompiledContract // will be initialized by init function
+)
+
+// ChainConfigReaderConfig implements the StatefulPrecompileConfig interface for a read-only
+// precompile that exposes the chain configuration to contracts.
+type ChainConfigReaderConfig struct {
+	UpgradeableConfig
+}
+
+// ChainConfigReaderConfigKey is the JSON key of the ChainConfigReader config in the chain config and precompile upgrades.
+const ChainConfigReaderConfigKey = "chainConfigReaderConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(ChainConfigReaderRawABI))
+	if err != nil {
+		panic(err)
+	}
+	ChainConfigReaderABI = parsed
+	ChainConfigReaderPrecompile = createChainConfigReaderPrecompile()
+
+	RegisterModule(Module{
+		ConfigKey: ChainConfigReaderConfigKey,
+		Address:   ChainConfigReaderAddress,
+		Contract:  ChainConfigReaderPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(ChainConfigReaderConfig) },
+		ABI:       &ChainConfigReaderABI,
+		RawABI:    ChainConfigReaderRawABI,
+		GasCosts: map[string]uint64{
+			"allowFeeRecipients":   ReadChainConfigGasCost,
+			"chainId":              ReadChainConfigGasCost,
+			"configHash":           ReadChainConfigGasCost,
+			"enabledPrecompiles":   ReadChainConfigGasCost,
+			"feeConfigSource":      ReadChainConfigGasCost,
+			"precompileActivation": ReadChainConfigGasCost,
+			"subnetEVMTimestamp":   ReadChainConfigGasCost,
+		},
+	})
+}
+
+// NewChainConfigReaderConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// ChainConfigReader.
+func NewChainConfigReaderConfig(blockTimestamp *big.Int) *ChainConfigReaderConfig {
+	return &ChainConfigReaderConfig{
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableChainConfigReaderConfig returns config for a network upgrade at [blockTimestamp]
+// that disables ChainConfigReader.
+func NewDisableChainConfigReaderConfig(blockTimestamp *big.Int) *ChainConfigReaderConfig {
+	return &ChainConfigReaderConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the chain config reader.
+func (c *ChainConfigReaderConfig) Address() common.Address {
+	return ChainConfigReaderAddress
+}
+
+// Configure is a no-op since the chain config reader does not keep any state.
+func (c *ChainConfigReaderConfig) Configure(_ ChainConfig, _ StateDB, _ BlockContext) {}
+
+// Contract returns the singleton stateful precompiled contract to be used for the chain config reader.
+func (c *ChainConfigReaderConfig) Contract() StatefulPrecompiledContract {
+	return ChainConfigReaderPrecompile
+}
+
+// Verify always returns nil since the chain config reader has no parameters.
+func (c *ChainConfigReaderConfig) Verify() error { return nil }
+
+// Equal returns true if [s] is a [*ChainConfigReaderConfig] and it has been configured identical to [c].
+func (c *ChainConfigReaderConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*ChainConfigReaderConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig)
+}
+
+// String returns a string representation of the ChainConfigReaderConfig.
+func (c *ChainConfigReaderConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// activePrecompileConfigs returns the configs of the stateful precompiles that are enabled in the
+// current block.
+func activePrecompileConfigs(accessibleState PrecompileAccessibleState) []StatefulPrecompileConfig {
+	blockContext := accessibleState.GetBlockContext()
+	configs := accessibleState.GetChainConfig().EnabledStatefulPrecompiles(blockContext.Number(), blockContext.Timestamp())
+	active := make([]StatefulPrecompileConfig, 0, len(configs))
+	for _, config := range configs {
+		if !config.IsDisabled() {
+			active = append(active, config)
+		}
+	}
+	return active
+}
+
+// activationTimestamp returns the timestamp at which [config] was activated, or 0 if it was
+// activated by block number.
+func activationTimestamp(config StatefulPrecompileConfig) *big.Int {
+	if timestamp := config.Timestamp(); timestamp != nil {
+		return new(big.Int).Set(timestamp)
+	}
+	return new(big.Int)
+}
+
+// GetFeeConfigSource returns FeeConfigSourceFeeManager if the FeeConfigManager precompile is
+// enabled in [configs] and FeeConfigSourceGenesis otherwise.
+func GetFeeConfigSource(configs []StatefulPrecompileConfig) uint8 {
+	for _, config := range configs {
+		if config.Address() == FeeConfigManagerAddress {
+			return FeeConfigSourceFeeManager
+		}
+	}
+	return FeeConfigSourceGenesis
+}
+
+// PackChainId packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackChainId() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("chainId")
+}
+
+func chainId(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	chainID := accessibleState.GetChainConfig().GetChainID()
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	packedOutput, err := ChainConfigReaderABI.PackOutput("chainId", chainID)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackConfigHash packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackConfigHash() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("configHash")
+}
+
+func configHash(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	blockContext := accessibleState.GetBlockContext()
+	hash := accessibleState.GetChainConfig().HashAt(blockContext.Number(), blockContext.Timestamp())
+	packedOutput, err := ChainConfigReaderABI.PackOutput("configHash", hash)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackSubnetEVMTimestamp packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackSubnetEVMTimestamp() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("subnetEVMTimestamp")
+}
+
+func subnetEVMTimestamp(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	var (
+		activated bool
+		timestamp = new(big.Int)
+	)
+	if upgradeTimestamp := accessibleState.GetChainConfig().GetSubnetEVMTimestamp(); upgradeTimestamp != nil {
+		activated = upgradeTimestamp.Cmp(accessibleState.GetBlockContext().Timestamp()) <= 0
+		timestamp.Set(upgradeTimestamp)
+	}
+	packedOutput, err := ChainConfigReaderABI.PackOutput("subnetEVMTimestamp", activated, timestamp)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackFeeConfigSource packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackFeeConfigSource() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("feeConfigSource")
+}
+
+func feeConfigSource(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	source := GetFeeConfigSource(activePrecompileConfigs(accessibleState))
+	packedOutput, err := ChainConfigReaderABI.PackOutput("feeConfigSource", source)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackChainConfigAllowFeeRecipients packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackChainConfigAllowFeeRecipients() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("allowFeeRecipients")
+}
+
+func chainConfigAllowFeeRecipients(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	packedOutput, err := ChainConfigReaderABI.PackOutput("allowFeeRecipients", accessibleState.GetChainConfig().AllowedFeeRecipients())
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackEnabledPrecompiles packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackEnabledPrecompiles() ([]byte, error) {
+	return ChainConfigReaderABI.Pack("enabledPrecompiles")
+}
+
+func enabledPrecompiles(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	configs := activePrecompileConfigs(accessibleState)
+	var (
+		addresses  = make([]common.Address, 0, len(configs))
+		timestamps = make([]*big.Int, 0, len(configs))
+	)
+	for _, config := range configs {
+		addresses = append(addresses, config.Address())
+		timestamps = append(timestamps, activationTimestamp(config))
+	}
+	packedOutput, err := ChainConfigReaderABI.PackOutput("enabledPrecompiles", addresses, timestamps)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// PackPrecompileActivation packs [precompileAddr] of type common.Address into the appropriate arguments for precompileActivation.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackPrecompileActivation(precompileAddr common.Address) ([]byte, error) {
+	return ChainConfigReaderABI.Pack("precompileActivation", precompileAddr)
+}
+
+// UnpackPrecompileActivationInput attempts to unpack [input] into the common.Address type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackPrecompileActivationInput(input []byte) (common.Address, error) {
+	res, err := ChainConfigReaderABI.UnpackInput("precompileActivation", input)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	return unpacked, nil
+}
+
+func precompileActivation(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ReadChainConfigGasCost); err != nil {
+		return nil, 0, err
+	}
+	precompileAddr, err := UnpackPrecompileActivationInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	var (
+		enabled   bool
+		timestamp = new(big.Int)
+	)
+	for _, config := range activePrecompileConfigs(accessibleState) {
+		if config.Address() == precompileAddr {
+			enabled = true
+			timestamp = activationTimestamp(config)
+			break
+		}
+	}
+	packedOutput, err := ChainConfigReaderABI.PackOutput("precompileActivation", enabled, timestamp)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// createChainConfigReaderPrecompile returns a StatefulPrecompiledContract with read-only access to the chain config.
+func createChainConfigReaderPrecompile() StatefulPrecompiledContract {
+	var functions []*statefulPrecompileFunction
+
+	abiFunctionMap := map[string]RunStatefulPrecompileFunc{
+		"allowFeeRecipients":   chainConfigAllowFeeRecipients,
+		"chainId":              chainId,
+		"configHash":           configHash,
+		"enabledPrecompiles":   enabledPrecompiles,
+		"feeConfigSource":      feeConfigSource,
+		"precompileActivation": precompileActivation,
+		"subnetEVMTimestamp":   subnetEVMTimestamp,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := ChainConfigReaderABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, newStatefulPrecompileFunction(method.Name, method.ID, method.IsConstant(), function))
+	}
+
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
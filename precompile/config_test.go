@@ -5,8 +5,10 @@ package precompile
 
 import (
 	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -118,6 +120,94 @@ func TestVerifyPrecompileUpgrades(t *testing.T) {
 			}),
 			expectedError: ErrCannotEnableBothRewards.Error(),
 		},
+		{
+			name:          "invalid allow list config in address blocklist",
+			config:        NewAddressBlocklistConfig(big.NewInt(3), admins, admins, nil),
+			expectedError: "cannot set address",
+		},
+		{
+			name:          "duplicate blocked addresses in address blocklist",
+			config:        NewAddressBlocklistConfig(big.NewInt(3), admins, enableds, []common.Address{{3}, {3}}),
+			expectedError: "duplicate address",
+		},
+		{
+			name: "nil amount in native asset call config",
+			config: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				common.HexToAddress("0x01"): {common.Hash{1}: math.NewHexOrDecimal256(123)},
+				common.HexToAddress("0x02"): {common.Hash{1}: nil},
+			}),
+			expectedError: "initial asset balances cannot contain nil",
+		},
+		{
+			name: "zero amount in native asset call config",
+			config: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				common.HexToAddress("0x01"): {common.Hash{1}: math.NewHexOrDecimal256(0)},
+			}),
+			expectedError: "initial asset balances cannot contain invalid amount",
+		},
+		{
+			name:          "invalid allow list config in gas sponsor",
+			config:        NewGasSponsorConfig(big.NewInt(3), admins, admins),
+			expectedError: "cannot set address",
+		},
+		{
+			name:          "invalid allow list config in gas token",
+			config:        NewGasTokenConfig(big.NewInt(3), admins, admins, "Gas Token", "GAS", 18, nil),
+			expectedError: "cannot set address",
+		},
+		{
+			name:          "too long name in gas token",
+			config:        NewGasTokenConfig(big.NewInt(3), admins, enableds, strings.Repeat("a", 32), "GAS", 18, nil),
+			expectedError: ErrGasTokenStringTooLong.Error(),
+		},
+		{
+			name: "zero initial balance in gas token",
+			config: NewGasTokenConfig(big.NewInt(3), admins, enableds, "Gas Token", "GAS", 18,
+				map[common.Address]*math.HexOrDecimal256{
+					common.HexToAddress("0x01"): math.NewHexOrDecimal256(0),
+				}),
+			expectedError: "initial balances cannot contain invalid amount",
+		},
+		{
+			name:          "zero epoch duration in validator info",
+			config:        NewValidatorInfoConfig(big.NewInt(3), 0),
+			expectedError: ErrInvalidEpochDuration.Error(),
+		},
+		{
+			name:          "disable validator info without epoch duration",
+			config:        NewDisableValidatorInfoConfig(big.NewInt(3)),
+			expectedError: "",
+		},
+		{
+			name:          "missing destination chain in native token source",
+			config:        NewNativeTokenSourceConfig(big.NewInt(3), ids.Empty),
+			expectedError: ErrMissingBridgeChainID.Error(),
+		},
+		{
+			name:          "missing source chain in native token destination",
+			config:        NewNativeTokenDestinationConfig(big.NewInt(3), ids.Empty, nil, 0),
+			expectedError: ErrMissingBridgeChainID.Error(),
+		},
+		{
+			name:          "rate limit amount without period in native token destination",
+			config:        NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 0),
+			expectedError: ErrInvalidTransferRateLimit.Error(),
+		},
+		{
+			name:          "zero rate limit amount in native token destination",
+			config:        NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(0), 60),
+			expectedError: ErrInvalidTransferRateLimit.Error(),
+		},
+		{
+			name:          "disable native token source",
+			config:        &NativeTokenSourceConfig{UpgradeableConfig: UpgradeableConfig{BlockTimestamp: big.NewInt(3), Disable: true}},
+			expectedError: ErrNativeTokenBridgeDisabled.Error(),
+		},
+		{
+			name:          "disable native token destination",
+			config:        &NativeTokenDestinationConfig{UpgradeableConfig: UpgradeableConfig{BlockTimestamp: big.NewInt(3), Disable: true}},
+			expectedError: ErrNativeTokenBridgeDisabled.Error(),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -460,3 +550,434 @@ func TestEqualRewardManagerConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestEqualAddressBlocklistConfig(t *testing.T) {
+	admins := []common.Address{{1}}
+	blocked := []common.Address{{3}}
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			other:    NewTxAllowListConfig(big.NewInt(3), admins, nil),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			other:    NewAddressBlocklistConfig(big.NewInt(4), admins, nil, blocked),
+			expected: false,
+		},
+		{
+			name:     "different blocked addresses",
+			config:   NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			other:    NewAddressBlocklistConfig(big.NewInt(3), admins, nil, []common.Address{{4}}),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			other:    NewAddressBlocklistConfig(big.NewInt(3), admins, nil, blocked),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualChainConfigReaderConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewChainConfigReaderConfig(big.NewInt(3)),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewChainConfigReaderConfig(big.NewInt(3)),
+			other:    NewTxAllowListConfig(big.NewInt(3), nil, nil),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewChainConfigReaderConfig(big.NewInt(3)),
+			other:    NewChainConfigReaderConfig(big.NewInt(4)),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewChainConfigReaderConfig(big.NewInt(3)),
+			other:    NewChainConfigReaderConfig(big.NewInt(3)),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualNativeAssetCallConfig(t *testing.T) {
+	holder := common.HexToAddress("0x01")
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewNativeAssetCallConfig(big.NewInt(3), nil),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewNativeAssetCallConfig(big.NewInt(3), nil),
+			other:    NewNativeAssetBalanceConfig(big.NewInt(3)),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewNativeAssetCallConfig(big.NewInt(3), nil),
+			other:    NewNativeAssetCallConfig(big.NewInt(4), nil),
+			expected: false,
+		},
+		{
+			name: "different initial asset balances",
+			config: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holder: {common.Hash{1}: math.NewHexOrDecimal256(1)},
+			}),
+			other: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holder: {common.Hash{2}: math.NewHexOrDecimal256(1)},
+			}),
+			expected: false,
+		},
+		{
+			name: "same config",
+			config: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holder: {common.Hash{1}: math.NewHexOrDecimal256(1)},
+			}),
+			other: NewNativeAssetCallConfig(big.NewInt(3), map[common.Address]map[common.Hash]*math.HexOrDecimal256{
+				holder: {common.Hash{1}: math.NewHexOrDecimal256(1)},
+			}),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualGasSponsorConfig(t *testing.T) {
+	admins := []common.Address{{1}}
+	enableds := []common.Address{{2}}
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			other:    NewTxAllowListConfig(big.NewInt(3), admins, enableds),
+			expected: false,
+		},
+		{
+			name:     "different admin",
+			config:   NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			other:    NewGasSponsorConfig(big.NewInt(3), []common.Address{{3}}, enableds),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			other:    NewGasSponsorConfig(big.NewInt(4), admins, enableds),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			other:    NewGasSponsorConfig(big.NewInt(3), admins, enableds),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualValidatorInfoConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewValidatorInfoConfig(big.NewInt(3), 60),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewValidatorInfoConfig(big.NewInt(3), 60),
+			other:    NewChainConfigReaderConfig(big.NewInt(3)),
+			expected: false,
+		},
+		{
+			name:     "different epoch duration",
+			config:   NewValidatorInfoConfig(big.NewInt(3), 60),
+			other:    NewValidatorInfoConfig(big.NewInt(3), 120),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewValidatorInfoConfig(big.NewInt(3), 60),
+			other:    NewValidatorInfoConfig(big.NewInt(4), 60),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewValidatorInfoConfig(big.NewInt(3), 60),
+			other:    NewValidatorInfoConfig(big.NewInt(3), 60),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualGasTokenConfig(t *testing.T) {
+	admins := []common.Address{{1}}
+	balances := map[common.Address]*math.HexOrDecimal256{
+		common.HexToAddress("0x01"): math.NewHexOrDecimal256(1),
+	}
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other:    NewGasSponsorConfig(big.NewInt(3), admins, nil),
+			expected: false,
+		},
+		{
+			name:     "different symbol",
+			config:   NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other:    NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GT", 18, balances),
+			expected: false,
+		},
+		{
+			name:     "different decimals",
+			config:   NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other:    NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 6, balances),
+			expected: false,
+		},
+		{
+			name:   "different initial balances",
+			config: NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other: NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18,
+				map[common.Address]*math.HexOrDecimal256{
+					common.HexToAddress("0x01"): math.NewHexOrDecimal256(2),
+				}),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			other:    NewGasTokenConfig(big.NewInt(3), admins, nil, "Gas Token", "GAS", 18, balances),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualGovernanceRelayerConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewGovernanceRelayerConfig(big.NewInt(3)),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewGovernanceRelayerConfig(big.NewInt(3)),
+			other:    NewChainConfigReaderConfig(big.NewInt(3)),
+			expected: false,
+		},
+		{
+			name:     "different timestamp",
+			config:   NewGovernanceRelayerConfig(big.NewInt(3)),
+			other:    NewGovernanceRelayerConfig(big.NewInt(4)),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewGovernanceRelayerConfig(big.NewInt(3)),
+			other:    NewGovernanceRelayerConfig(big.NewInt(3)),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualNativeTokenSourceConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{1}),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different type",
+			config:   NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{1}),
+			other:    NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, nil, 0),
+			expected: false,
+		},
+		{
+			name:     "different destination chain",
+			config:   NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{1}),
+			other:    NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{2}),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{1}),
+			other:    NewNativeTokenSourceConfig(big.NewInt(3), ids.ID{1}),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
+
+func TestEqualNativeTokenDestinationConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   StatefulPrecompileConfig
+		other    StatefulPrecompileConfig
+		expected bool
+	}{
+		{
+			name:     "non-nil config and nil other",
+			config:   NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			other:    nil,
+			expected: false,
+		},
+		{
+			name:     "different source chain",
+			config:   NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			other:    NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{2}, big.NewInt(100), 60),
+			expected: false,
+		},
+		{
+			name:     "different rate limit amount",
+			config:   NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			other:    NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, nil, 60),
+			expected: false,
+		},
+		{
+			name:     "different rate limit period",
+			config:   NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			other:    NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 120),
+			expected: false,
+		},
+		{
+			name:     "same config",
+			config:   NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			other:    NewNativeTokenDestinationConfig(big.NewInt(3), ids.ID{1}, big.NewInt(100), 60),
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tt.expected, tt.config.Equal(tt.other))
+		})
+	}
+}
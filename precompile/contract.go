@@ -4,11 +4,14 @@
 package precompile
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -16,32 +19,73 @@ const (
 	selectorLen = 4
 )
 
+// ErrPrecompileNotEnabled is returned when a stateful precompile calls an address where no stateful precompile is enabled.
+var ErrPrecompileNotEnabled = errors.New("no stateful precompile enabled at address")
+
 type RunStatefulPrecompileFunc func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 
+// RequiredGasFunc returns the gas required to execute a stateful precompile function on [input] (excluding the
+// function selector). The cost may depend on the length of [input], e.g. to price batch functions per element,
+// or on the state the function will access.
+type RequiredGasFunc func(accessibleState PrecompileAccessibleState, caller common.Address, input []byte) uint64
+
+// fixedGas returns a RequiredGasFunc for functions that cost [gasCost] regardless of their input.
+func fixedGas(gasCost uint64) RequiredGasFunc {
+	return func(PrecompileAccessibleState, common.Address, []byte) uint64 { return gasCost }
+}
+
 // PrecompileAccessibleState defines the interface exposed to stateful precompile contracts
 type PrecompileAccessibleState interface {
 	GetStateDB() StateDB
 	GetBlockContext() BlockContext
 	GetSnowContext() *snow.Context
+	GetChainConfig() ChainConfig
 	CallFromPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error)
+	// CallPrecompile executes the stateful precompile enabled at [addr] with [input] on behalf of [caller], which
+	// is typically the address of the calling precompile, so that one precompile can use the functions of
+	// another one instead of accessing its storage directly.
+	// At most [gas] is forwarded to the callee and the unused gas is returned. The call is read-only if [readOnly]
+	// is set or if the calling context is read-only. State changes of the callee are reverted if it returns an error.
+	CallPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
 // BlockContext defines an interface that provides information to a stateful precompile
-// about the block that activates the upgrade. The precompile can access this information
-// to initialize its state.
+// about the block that activates the upgrade or the block being executed. The precompile
+// can access this information to initialize its state or to make decisions based on the
+// header of the current block.
 type BlockContext interface {
 	Number() *big.Int
 	Timestamp() *big.Int
+	// BaseFee returns the base fee of the block, or nil if the block precedes the SubnetEVM upgrade.
+	BaseFee() *big.Int
+	Coinbase() common.Address
+	ParentHash() common.Hash
+	GasLimit() uint64
 }
 
 // ChainContext defines an interface that provides information to a stateful precompile
 // about the chain configuration. The precompile can access this information to initialize
 // its state.
 type ChainConfig interface {
-	// GetFeeConfig returns the original FeeConfig that was set in the genesis.
-	GetFeeConfig() commontype.FeeConfig
+	// FeeConfigAt returns the FeeConfig set in the genesis, as changed by the parameter upgrades
+	// activated at or before [blockTimestamp].
+	FeeConfigAt(blockTimestamp *big.Int) commontype.FeeConfig
 	// AllowedFeeRecipients returns true if fee recipients are allowed in the genesis.
 	AllowedFeeRecipients() bool
+	// GetChainID returns the chainID used for replay protection.
+	GetChainID() *big.Int
+	// GetSubnetEVMTimestamp returns the timestamp of the SubnetEVM network upgrade or nil if it is not scheduled.
+	GetSubnetEVMTimestamp() *big.Int
+	// EnabledStatefulPrecompiles returns the most recent config for each stateful precompile
+	// that has been configured at or before the block with [blockNumber] and [blockTimestamp],
+	// including disabling configs.
+	EnabledStatefulPrecompiles(blockNumber *big.Int, blockTimestamp *big.Int) []StatefulPrecompileConfig
+	// HashAt returns the canonical hash of the chain config and of the upgrades activated at or before
+	// the block with [blockNumber] and [blockTimestamp].
+	HashAt(blockNumber *big.Int, blockTimestamp *big.Int) common.Hash
+	// PrecompileEventsAt returns true if the precompiles that predate events emit them at [blockTimestamp],
+	// as enabled by the parameter upgrades.
+	PrecompileEventsAt(blockTimestamp *big.Int) bool
 }
 
 // StateDB is the interface for accessing EVM state
@@ -67,42 +111,113 @@ type StateDB interface {
 	Finalise(deleteEmptyObjects bool)
 }
 
+// ReadCache is implemented by StateDBs that can cache values derived from the storage of an account until
+// the storage is modified, so that precompiles consulted many times within a block (e.g. the tx allow list,
+// checked for every transaction) do not repeat the same storage reads.
+type ReadCache interface {
+	// GetCachedRead returns the value cached under [key] for [addr], if any.
+	GetCachedRead(addr common.Address, key interface{}) (interface{}, bool)
+	// CacheRead caches [value] under [key] for [addr], until the storage of [addr] is modified.
+	CacheRead(addr common.Address, key interface{}, value interface{})
+}
+
 // StatefulPrecompiledContract is the interface for executing a precompiled contract
 type StatefulPrecompiledContract interface {
 	// Run executes the precompiled contract.
 	Run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error)
 }
 
+// FunctionNamer is implemented by stateful precompiles that can name the function executed for a given input,
+// so that tracers can report meaningful call frames for precompile invocations.
+type FunctionNamer interface {
+	// FunctionName returns the name of the function executed for [input], or an empty string if [input]
+	// does not select any function.
+	FunctionName(input []byte) string
+}
+
+// FunctionMetadata describes a function implemented by a stateful precompile.
+type FunctionMetadata struct {
+	Name     string
+	Selector []byte
+	// IsView is true if the function does not modify the state, so that it can be called in a read-only context.
+	IsView bool
+}
+
+// FunctionDescriber is implemented by stateful precompiles that can describe the functions they implement,
+// so that tooling can classify them (e.g. to determine which functions can be called with eth_call).
+type FunctionDescriber interface {
+	// Functions returns the functions of the precompile sorted by name.
+	Functions() []FunctionMetadata
+}
+
 // statefulPrecompileFunction defines a function implemented by a stateful precompile
 type statefulPrecompileFunction struct {
+	// name is the name of this function, reported to tracers
+	name string
 	// selector is the 4 byte function selector for this function
 	// This should be calculated from the function signature using CalculateFunctionSelector
 	selector []byte
+	// isView is true if this function does not modify the state. Functions that are not views
+	// are rejected with ErrWriteProtection in a read-only context before they are executed.
+	isView bool
+	// requiredGas is the optional gas cost of this function, deducted before [execute] is performed.
+	// If nil, [execute] must deduct its own gas cost from the supplied gas.
+	requiredGas RequiredGasFunc
 	// execute is performed when this function is selected
 	execute RunStatefulPrecompileFunc
 }
 
 // newStatefulPrecompileFunction creates a stateful precompile function with the given arguments
-func newStatefulPrecompileFunction(selector []byte, execute RunStatefulPrecompileFunc) *statefulPrecompileFunction {
+func newStatefulPrecompileFunction(name string, selector []byte, isView bool, execute RunStatefulPrecompileFunc) *statefulPrecompileFunction {
 	return &statefulPrecompileFunction{
+		name:     name,
 		selector: selector,
+		isView:   isView,
 		execute:  execute,
 	}
 }
 
+// newStatefulPrecompileFunctionWithGas creates a stateful precompile function that costs [requiredGas].
+// The gas is deducted before [execute] is performed, which is supplied the remaining gas.
+func newStatefulPrecompileFunctionWithGas(name string, selector []byte, isView bool, requiredGas RequiredGasFunc, execute RunStatefulPrecompileFunc) *statefulPrecompileFunction {
+	function := newStatefulPrecompileFunction(name, selector, isView, execute)
+	function.requiredGas = requiredGas
+	return function
+}
+
+// run deducts the required gas of the function, if any, then executes it on [input].
+// Returns ErrWriteProtection without executing the function if it is not a view and [readOnly] is true, consuming
+// all of the supplied gas as the EVM does for write protection errors.
+func (f *statefulPrecompileFunction) run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if f.requiredGas != nil {
+		if suppliedGas, err = deductGas(suppliedGas, f.requiredGas(accessibleState, caller, input)); err != nil {
+			return nil, 0, err
+		}
+	}
+	if readOnly && !f.isView {
+		return nil, 0, vmerrs.ErrWriteProtection
+	}
+	return f.execute(accessibleState, caller, addr, input, suppliedGas, readOnly)
+}
+
 // statefulPrecompileWithFunctionSelectors implements StatefulPrecompiledContract by using 4 byte function selectors to pass
 // off responsibilities to internal execution functions.
 // Note: because we only ever read from [functions] there no lock is required to make it thread-safe.
 type statefulPrecompileWithFunctionSelectors struct {
+	receive   RunStatefulPrecompileFunc
 	fallback  RunStatefulPrecompileFunc
 	functions map[string]*statefulPrecompileFunction
 }
 
-// newStatefulPrecompileWithFunctionSelectors generates new StatefulPrecompile using [functions] as the available functions and [fallback]
-// as an optional fallback if there is no input data. Note: the selector of [fallback] will be ignored, so it is required to be left empty.
-func newStatefulPrecompileWithFunctionSelectors(fallback RunStatefulPrecompileFunc, functions []*statefulPrecompileFunction) StatefulPrecompiledContract {
+// newStatefulPrecompileWithFunctionSelectors generates new StatefulPrecompile using [functions] as the available functions.
+// The optional [receive] and [fallback] follow the semantics of Solidity:
+// - [receive] is called if there is no input data.
+// - [fallback] is called with the raw input data if it does not match any of [functions], including when there is no
+// input data and [receive] is nil.
+func newStatefulPrecompileWithFunctionSelectors(receive RunStatefulPrecompileFunc, fallback RunStatefulPrecompileFunc, functions []*statefulPrecompileFunction) StatefulPrecompiledContract {
 	// Construct the contract and populate [functions].
 	contract := &statefulPrecompileWithFunctionSelectors{
+		receive:   receive,
 		fallback:  fallback,
 		functions: make(map[string]*statefulPrecompileFunction),
 	}
@@ -118,15 +233,18 @@ func newStatefulPrecompileWithFunctionSelectors(fallback RunStatefulPrecompileFu
 }
 
 // Run selects the function using the 4 byte function selector at the start of the input and executes the underlying function on the
-// given arguments.
+// given arguments. If the input does not select a function, the receive or fallback function is executed instead if present.
 func (s *statefulPrecompileWithFunctionSelectors) Run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	// If there is no input data present, call the fallback function if present.
-	if len(input) == 0 && s.fallback != nil {
-		return s.fallback(accessibleState, caller, addr, nil, suppliedGas, readOnly)
+	// If there is no input data present, call the receive function if present.
+	if len(input) == 0 && s.receive != nil {
+		return s.receive(accessibleState, caller, addr, nil, suppliedGas, readOnly)
 	}
 
-	// Otherwise, an unexpected input size will result in an error.
+	// Otherwise, an unexpected input size will result in an error unless there is a fallback function.
 	if len(input) < selectorLen {
+		if s.fallback != nil {
+			return s.fallback(accessibleState, caller, addr, input, suppliedGas, readOnly)
+		}
 		return nil, suppliedGas, fmt.Errorf("missing function selector to precompile - input length (%d)", len(input))
 	}
 
@@ -135,8 +253,43 @@ func (s *statefulPrecompileWithFunctionSelectors) Run(accessibleState Precompile
 	functionInput := input[selectorLen:]
 	function, ok := s.functions[string(selector)]
 	if !ok {
+		if s.fallback != nil {
+			return s.fallback(accessibleState, caller, addr, input, suppliedGas, readOnly)
+		}
 		return nil, suppliedGas, fmt.Errorf("invalid function selector %#x", selector)
 	}
 
-	return function.execute(accessibleState, caller, addr, functionInput, suppliedGas, readOnly)
+	return function.run(accessibleState, caller, addr, functionInput, suppliedGas, readOnly)
+}
+
+// FunctionName returns the name of the function selected by [input], or "receive" or "fallback" if [input]
+// is handled by the receive or fallback function. Implements [FunctionNamer].
+func (s *statefulPrecompileWithFunctionSelectors) FunctionName(input []byte) string {
+	if len(input) == 0 && s.receive != nil {
+		return "receive"
+	}
+	if len(input) >= selectorLen {
+		if function, ok := s.functions[string(input[:selectorLen])]; ok {
+			return function.name
+		}
+	}
+	if s.fallback != nil {
+		return "fallback"
+	}
+	return ""
+}
+
+// Functions returns the functions selected by their 4 byte selector, excluding the receive and fallback functions,
+// sorted by name. Implements [FunctionDescriber].
+func (s *statefulPrecompileWithFunctionSelectors) Functions() []FunctionMetadata {
+	functions := make([]FunctionMetadata, 0, len(s.functions))
+	for _, function := range s.functions {
+		functions = append(functions, FunctionMetadata{
+			Name:     function.name,
+			Selector: common.CopyBytes(function.selector),
+			IsView:   function.isView,
+		})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
 }
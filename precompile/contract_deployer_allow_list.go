@@ -5,6 +5,7 @@ package precompile
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,10 +13,28 @@ import (
 
 var (
 	_ StatefulPrecompileConfig = &ContractDeployerAllowListConfig{}
+
+	ErrSenderAddressNotAllowedToDeploy = errors.New("cannot deploy contract from non-allow listed address")
+
 	// Singleton StatefulPrecompiledContract for W/R access to the contract deployer allow list.
 	ContractDeployerAllowListPrecompile StatefulPrecompiledContract = createAllowListPrecompile(ContractDeployerAllowListAddress)
 )
 
+// ContractDeployerAllowListConfigKey is the JSON key of the ContractDeployerAllowList config in the chain config and precompile upgrades.
+const ContractDeployerAllowListConfigKey = "contractDeployerAllowListConfig"
+
+func init() {
+	RegisterModule(Module{
+		ConfigKey: ContractDeployerAllowListConfigKey,
+		Address:   ContractDeployerAllowListAddress,
+		Contract:  ContractDeployerAllowListPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(ContractDeployerAllowListConfig) },
+		ABI:       &AllowListABI,
+		RawABI:    AllowListRawABI,
+		GasCosts:  allowListGasCosts(nil),
+	})
+}
+
 // ContractDeployerAllowListConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the contract deployer specific precompile address.
 type ContractDeployerAllowListConfig struct {
@@ -8,9 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
+	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ava-labs/subnet-evm/utils"
-	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 )
@@ -31,8 +32,33 @@ var (
 
 	mintSignature = CalculateFunctionSelector("mintNativeCoin(address,uint256)") // address, amount
 	ErrCannotMint = errors.New("non-enabled cannot mint")
+
+	// ContractNativeMinterRawABI contains the raw ABI of ContractNativeMinter contract.
+	ContractNativeMinterRawABI         = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"NativeCoinMinted\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"RoleSet\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"mintNativeCoin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+	ContractNativeMinterABI    abi.ABI // will be initialized by init function
 )
 
+// ContractNativeMinterConfigKey is the JSON key of the ContractNativeMinter config in the chain config and precompile upgrades.
+const ContractNativeMinterConfigKey = "contractNativeMinterConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(ContractNativeMinterRawABI))
+	if err != nil {
+		panic(err)
+	}
+	ContractNativeMinterABI = parsed
+
+	RegisterModule(Module{
+		ConfigKey: ContractNativeMinterConfigKey,
+		Address:   ContractNativeMinterAddress,
+		Contract:  ContractNativeMinterPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(ContractNativeMinterConfig) },
+		ABI:       &ContractNativeMinterABI,
+		RawABI:    ContractNativeMinterRawABI,
+		GasCosts:  allowListGasCosts(map[string]uint64{"mintNativeCoin": MintGasCost}),
+	})
+}
+
 // ContractNativeMinterConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the ContractNativeMinter specific precompile address.
 type ContractNativeMinterConfig struct {
@@ -183,10 +209,6 @@ func mintNativeCoin(accessibleState PrecompileAccessibleState, caller common.Add
 		return nil, 0, err
 	}
 
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
-
 	to, amount, err := UnpackMintInput(input)
 	if err != nil {
 		return nil, remainingGas, err
@@ -205,6 +227,9 @@ func mintNativeCoin(accessibleState PrecompileAccessibleState, caller common.Add
 	}
 
 	stateDB.AddBalance(to, amount)
+	if remainingGas, err = emitPrecompileEvent(accessibleState, ContractNativeMinterAddress, remainingGas, "NativeCoinMinted", caller, to, amount); err != nil {
+		return nil, remainingGas, err
+	}
 	// Return an empty output and the remaining gas
 	return []byte{}, remainingGas, nil
 }
@@ -213,10 +238,10 @@ func mintNativeCoin(accessibleState PrecompileAccessibleState, caller common.Add
 func createNativeMinterPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
 	enabledFuncs := createAllowListFunctions(precompileAddr)
 
-	mintFunc := newStatefulPrecompileFunction(mintSignature, mintNativeCoin)
+	mintFunc := newStatefulPrecompileFunction("mintNativeCoin", mintSignature, false, mintNativeCoin)
 
 	enabledFuncs = append(enabledFuncs, mintFunc)
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, enabledFuncs)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, enabledFuncs)
 	return contract
 }
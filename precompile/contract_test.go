@@ -0,0 +1,175 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatefulPrecompileWithFunctionSelectorsRun(t *testing.T) {
+	// returnName returns a function that outputs [name] followed by its input.
+	returnName := func(name string) RunStatefulPrecompileFunc {
+		return func(_ PrecompileAccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+			return append([]byte(name), input...), suppliedGas, nil
+		}
+	}
+	selector := []byte{1, 2, 3, 4}
+	functions := []*statefulPrecompileFunction{newStatefulPrecompileFunction("function", selector, false, returnName("function"))}
+
+	type test struct {
+		contract     StatefulPrecompiledContract
+		input        []byte
+		expectedRes  []byte
+		expectedName string
+		expectedErr  string
+	}
+	for name, test := range map[string]test{
+		"function": {
+			contract:     newStatefulPrecompileWithFunctionSelectors(returnName("receive"), returnName("fallback"), functions),
+			input:        []byte{1, 2, 3, 4, 5},
+			expectedRes:  []byte("function\x05"),
+			expectedName: "function",
+		},
+		"receive without input": {
+			contract:     newStatefulPrecompileWithFunctionSelectors(returnName("receive"), returnName("fallback"), functions),
+			expectedRes:  []byte("receive"),
+			expectedName: "receive",
+		},
+		"fallback without input": {
+			contract:     newStatefulPrecompileWithFunctionSelectors(nil, returnName("fallback"), functions),
+			expectedRes:  []byte("fallback"),
+			expectedName: "fallback",
+		},
+		"fallback with short input": {
+			contract:     newStatefulPrecompileWithFunctionSelectors(returnName("receive"), returnName("fallback"), functions),
+			input:        []byte{1, 2},
+			expectedRes:  []byte("fallback\x01\x02"),
+			expectedName: "fallback",
+		},
+		"fallback with unknown selector": {
+			contract:     newStatefulPrecompileWithFunctionSelectors(returnName("receive"), returnName("fallback"), functions),
+			input:        []byte{4, 3, 2, 1, 0},
+			expectedRes:  []byte("fallback\x04\x03\x02\x01\x00"),
+			expectedName: "fallback",
+		},
+		"receive with unknown selector": {
+			contract:    newStatefulPrecompileWithFunctionSelectors(returnName("receive"), nil, functions),
+			input:       []byte{4, 3, 2, 1},
+			expectedErr: "invalid function selector 0x04030201",
+		},
+		"no receive or fallback without input": {
+			contract:    newStatefulPrecompileWithFunctionSelectors(nil, nil, functions),
+			expectedErr: "missing function selector to precompile - input length (0)",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expectedName, test.contract.(FunctionNamer).FunctionName(test.input))
+			res, remainingGas, err := test.contract.Run(nil, common.Address{}, common.Address{}, test.input, 100, false)
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedRes, res)
+			require.Equal(t, uint64(100), remainingGas)
+		})
+	}
+}
+
+func TestStatefulPrecompileFunctionRequiredGas(t *testing.T) {
+	// countElements returns the number of 32 byte elements in its input, priced per element.
+	const gasPerElement = 10
+	selector := []byte{1, 2, 3, 4}
+	countElements := newStatefulPrecompileFunctionWithGas(
+		"countElements",
+		selector,
+		true,
+		func(_ PrecompileAccessibleState, _ common.Address, input []byte) uint64 {
+			return uint64(len(input)/common.HashLength) * gasPerElement
+		},
+		func(_ PrecompileAccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, _ bool) ([]byte, uint64, error) {
+			return []byte{byte(len(input) / common.HashLength)}, suppliedGas, nil
+		},
+	)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, []*statefulPrecompileFunction{countElements})
+
+	for name, test := range map[string]struct {
+		elements    int
+		suppliedGas uint64
+		expectedGas uint64
+		expectedRes []byte
+		expectedErr error
+	}{
+		"no elements": {
+			suppliedGas: 5,
+			expectedGas: 5,
+			expectedRes: []byte{0},
+		},
+		"priced per element": {
+			elements:    3,
+			suppliedGas: 35,
+			expectedGas: 5,
+			expectedRes: []byte{3},
+		},
+		"out of gas": {
+			elements:    3,
+			suppliedGas: 29,
+			expectedErr: vmerrs.ErrOutOfGas,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := append(common.CopyBytes(selector), make([]byte, test.elements*common.HashLength)...)
+			res, remainingGas, err := contract.Run(nil, common.Address{}, common.Address{}, input, test.suppliedGas, false)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expectedRes, res)
+			require.Equal(t, test.expectedGas, remainingGas)
+		})
+	}
+}
+
+func TestStatefulPrecompileFunctionIsView(t *testing.T) {
+	// execute returns its input, or fails if it is executed in a read-only context.
+	execute := func(_ PrecompileAccessibleState, _ common.Address, _ common.Address, input []byte, suppliedGas uint64, readOnly bool) ([]byte, uint64, error) {
+		if readOnly {
+			return nil, suppliedGas, errors.New("executed in a read-only context")
+		}
+		return input, suppliedGas, nil
+	}
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, []*statefulPrecompileFunction{
+		newStatefulPrecompileFunction("write", []byte{1, 1, 1, 1}, false, execute),
+		newStatefulPrecompileFunctionWithGas("read", []byte{2, 2, 2, 2}, true, fixedGas(10), execute),
+	})
+
+	// functions that are not views are rejected in a read-only context without being executed
+	_, remainingGas, err := contract.Run(nil, common.Address{}, common.Address{}, []byte{1, 1, 1, 1}, 100, true)
+	require.ErrorIs(t, err, vmerrs.ErrWriteProtection)
+	require.Zero(t, remainingGas)
+	res, _, err := contract.Run(nil, common.Address{}, common.Address{}, []byte{1, 1, 1, 1, 5}, 100, false)
+	require.NoError(t, err)
+	require.Equal(t, []byte{5}, res)
+
+	// views are executed in a read-only context after their gas is deducted
+	_, remainingGas, err = contract.Run(nil, common.Address{}, common.Address{}, []byte{2, 2, 2, 2}, 100, true)
+	require.ErrorContains(t, err, "executed in a read-only context")
+	require.Equal(t, uint64(90), remainingGas)
+
+	require.Equal(t, []FunctionMetadata{
+		{Name: "read", Selector: []byte{2, 2, 2, 2}, IsView: true},
+		{Name: "write", Selector: []byte{1, 1, 1, 1}, IsView: false},
+	}, contract.(FunctionDescriber).Functions())
+}
+
+func TestAllowListFunctions(t *testing.T) {
+	require.Equal(t, []FunctionMetadata{
+		{Name: "readAllowList", Selector: readAllowListSignature, IsView: true},
+		{Name: "setAdmin", Selector: setAdminSignature, IsView: false},
+		{Name: "setEnabled", Selector: setEnabledSignature, IsView: false},
+		{Name: "setNone", Selector: setNoneSignature, IsView: false},
+	}, TxAllowListPrecompile.(FunctionDescriber).Functions())
+}
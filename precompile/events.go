@@ -0,0 +1,107 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Gas costs of a log, as charged by the LOG opcodes.
+	logGasCost      uint64 = 375
+	logTopicGasCost uint64 = 375
+	logDataGasCost  uint64 = 8
+
+	// PrecompileEventsRawABI contains the events emitted by the allow list, fee config manager and native minter
+	// precompiles when their state changes.
+	PrecompileEventsRawABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"name\":\"FeeConfigChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"NativeCoinMinted\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"RoleSet\",\"type\":\"event\"}]"
+)
+
+// PrecompileEventsABI will be initialized by init function
+var PrecompileEventsABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(PrecompileEventsRawABI))
+	if err != nil {
+		panic(err)
+	}
+	PrecompileEventsABI = parsed
+}
+
+// emitPrecompileEvent adds a log at [addr] for the event [name] of [PrecompileEventsABI] with [args] packed as its
+// topics and data, and returns the remaining gas after paying for the log.
+// Since logs are part of the receipts, precompiles that predate events only emit them once enabled by a
+// ParameterUpgrade, so that the blocks accepted before are still valid. No gas is charged otherwise.
+func emitPrecompileEvent(accessibleState PrecompileAccessibleState, addr common.Address, suppliedGas uint64, name string, args ...interface{}) (remainingGas uint64, err error) {
+	blockContext := accessibleState.GetBlockContext()
+	if !accessibleState.GetChainConfig().PrecompileEventsAt(blockContext.Timestamp()) {
+		return suppliedGas, nil
+	}
+	topics, data, err := PrecompileEventsABI.PackEvent(name, args...)
+	if err != nil {
+		return suppliedGas, err
+	}
+	gasCost := logGasCost + uint64(len(topics))*logTopicGasCost + uint64(len(data))*logDataGasCost
+	if remainingGas, err = deductGas(suppliedGas, gasCost); err != nil {
+		return 0, err
+	}
+	accessibleState.GetStateDB().AddLog(addr, topics, data, blockContext.Number().Uint64())
+	return remainingGas, nil
+}
+
+// emitFeeConfigChangedEvent emits the FeeConfigChanged event for [feeConfig] set by [sender].
+func emitFeeConfigChangedEvent(accessibleState PrecompileAccessibleState, suppliedGas uint64, sender common.Address, feeConfig commontype.FeeConfig) (uint64, error) {
+	return emitPrecompileEvent(accessibleState, FeeConfigManagerAddress, suppliedGas, "FeeConfigChanged",
+		sender,
+		feeConfig.GasLimit,
+		new(big.Int).SetUint64(feeConfig.TargetBlockRate),
+		feeConfig.MinBaseFee,
+		feeConfig.TargetGas,
+		feeConfig.BaseFeeChangeDenominator,
+		feeConfig.MinBlockGasCost,
+		feeConfig.MaxBlockGasCost,
+		feeConfig.BlockGasCostStep,
+	)
+}
+
+// eventABIs are the ABIs declaring the events emitted by the precompiles.
+func eventABIs() []abi.ABI {
+	return []abi.ABI{PrecompileEventsABI, AddressBlocklistABI, GasSponsorABI, GasTokenABI, GovernanceRelayerABI, NativeTokenSourceABI, NativeTokenDestinationABI}
+}
+
+// UnpackPrecompileEvent decodes a log emitted by a precompile with [topics] and [data], and returns the name
+// of the event with its arguments keyed by name.
+func UnpackPrecompileEvent(topics []common.Hash, data []byte) (string, map[string]interface{}, error) {
+	if len(topics) == 0 {
+		return "", nil, errors.New("cannot unpack anonymous event")
+	}
+	for _, eventABI := range eventABIs() {
+		event, err := eventABI.EventByID(topics[0])
+		if err != nil {
+			continue
+		}
+		args := make(map[string]interface{})
+		if err := event.Inputs.UnpackIntoMap(args, data); err != nil {
+			return "", nil, fmt.Errorf("failed to unpack data of event %s: %w", event.Name, err)
+		}
+		var indexed abi.Arguments
+		for _, input := range event.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, input)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(args, indexed, topics[1:]); err != nil {
+			return "", nil, fmt.Errorf("failed to unpack topics of event %s: %w", event.Name, err)
+		}
+		return event.Name, args, nil
+	}
+	return "", nil, fmt.Errorf("unknown event %s", topics[0])
+}
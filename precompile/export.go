@@ -0,0 +1,123 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ModuleExport describes a registered precompile to the SDKs and explorers generating its clients.
+type ModuleExport struct {
+	ConfigKey      string         `json:"configKey"`
+	Address        common.Address `json:"address"`
+	StorageVersion uint64         `json:"storageVersion"`
+	// ABI is the JSON ABI of the precompile, if it declares one.
+	ABI       json.RawMessage  `json:"abi,omitempty"`
+	Functions []FunctionExport `json:"functions"`
+	Events    []EventExport    `json:"events,omitempty"`
+}
+
+// FunctionExport describes a function of a precompile.
+type FunctionExport struct {
+	Name string `json:"name"`
+	// Signature and Selector are empty for the fallback function of a precompile called with raw input.
+	Signature string        `json:"signature,omitempty"`
+	Selector  hexutil.Bytes `json:"selector,omitempty"`
+	View      bool          `json:"view"`
+	// GasCost is the gas charged by the function before any event it emits, if known.
+	GasCost *uint64 `json:"gasCost,omitempty"`
+}
+
+// EventExport describes an event emitted by a precompile.
+type EventExport struct {
+	Name      string      `json:"name"`
+	Signature string      `json:"signature"`
+	Topic     common.Hash `json:"topic"`
+}
+
+// ExportModules returns the description of the registered modules sorted by address, as built into the
+// running binary.
+func ExportModules() ([]ModuleExport, error) {
+	modules := RegisteredModules()
+	exports := make([]ModuleExport, 0, len(modules))
+	for _, module := range modules {
+		export, err := ExportModule(module)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, nil
+}
+
+// ExportModule returns the description of [module]. The functions are those described by its contract if
+// it implements [FunctionDescriber], or else those of its ABI, sorted by name.
+func ExportModule(module Module) (ModuleExport, error) {
+	export := ModuleExport{
+		ConfigKey:      module.ConfigKey,
+		Address:        module.Address,
+		StorageVersion: module.StorageVersion,
+		Functions:      make([]FunctionExport, 0),
+	}
+	if module.RawABI != "" {
+		if !json.Valid([]byte(module.RawABI)) {
+			return ModuleExport{}, fmt.Errorf("precompile %s has an invalid raw ABI", module.ConfigKey)
+		}
+		export.ABI = json.RawMessage(module.RawABI)
+	}
+
+	gasCost := func(name string) *uint64 {
+		if gasCost, ok := module.GasCosts[name]; ok {
+			return &gasCost
+		}
+		return nil
+	}
+	if describer, ok := module.Contract.(FunctionDescriber); ok {
+		for _, function := range describer.Functions() {
+			functionExport := FunctionExport{
+				Name:     function.Name,
+				Selector: function.Selector,
+				View:     function.IsView,
+				GasCost:  gasCost(function.Name),
+			}
+			if module.ABI != nil {
+				if method, ok := module.ABI.Methods[function.Name]; ok {
+					functionExport.Signature = method.Sig
+				}
+			}
+			export.Functions = append(export.Functions, functionExport)
+		}
+	} else if module.ABI != nil {
+		for _, method := range module.ABI.Methods {
+			export.Functions = append(export.Functions, FunctionExport{
+				Name:      method.Name,
+				Signature: method.Sig,
+				Selector:  common.CopyBytes(method.ID),
+				View:      method.IsConstant(),
+				GasCost:   gasCost(method.Name),
+			})
+		}
+		sort.Slice(export.Functions, func(i, j int) bool { return export.Functions[i].Name < export.Functions[j].Name })
+	}
+	if fallbackGasCost := gasCost("fallback"); fallbackGasCost != nil {
+		export.Functions = append(export.Functions, FunctionExport{Name: "fallback", GasCost: fallbackGasCost})
+	}
+
+	if module.ABI != nil {
+		for _, event := range module.ABI.Events {
+			export.Events = append(export.Events, EventExport{
+				Name:      event.Name,
+				Signature: event.Sig,
+				Topic:     event.ID,
+			})
+		}
+		sort.Slice(export.Events, func(i, j int) bool { return export.Events[i].Name < export.Events[j].Name })
+	}
+	return export, nil
+}
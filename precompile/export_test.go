@@ -0,0 +1,77 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportModules(t *testing.T) {
+	exports, err := ExportModules()
+	require.NoError(t, err)
+	modules := RegisteredModules()
+	require.Len(t, exports, len(modules))
+
+	for i, export := range exports {
+		module := modules[i]
+		require.Equal(t, module.ConfigKey, export.ConfigKey)
+		require.Equal(t, module.Address, export.Address)
+
+		// The exported ABI is the one the precompile is called with.
+		if module.ABI != nil {
+			require.NotEmpty(t, export.ABI, module.ConfigKey)
+			parsed, err := abi.JSON(strings.NewReader(string(export.ABI)))
+			require.NoError(t, err, module.ConfigKey)
+			require.Len(t, parsed.Methods, len(module.ABI.Methods), module.ConfigKey)
+			require.Len(t, export.Events, len(module.ABI.Events), module.ConfigKey)
+		}
+
+		// Every function has a gas cost, and every gas cost is of a function.
+		require.NotEmpty(t, export.Functions, module.ConfigKey)
+		require.Len(t, export.Functions, len(module.GasCosts), module.ConfigKey)
+		for _, function := range export.Functions {
+			require.NotNil(t, function.GasCost, "%s: %s", module.ConfigKey, function.Name)
+			require.Equal(t, module.GasCosts[function.Name], *function.GasCost)
+			if function.Name == "fallback" {
+				continue
+			}
+			method, err := module.ABI.MethodById(function.Selector)
+			require.NoError(t, err, "%s: %s", module.ConfigKey, function.Name)
+			require.Equal(t, method.Sig, function.Signature)
+		}
+	}
+}
+
+func TestExportModule(t *testing.T) {
+	module, ok := GetRegisteredModule(ContractNativeMinterConfigKey)
+	require.True(t, ok)
+	export, err := ExportModule(module)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(export.Functions[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"mintNativeCoin","signature":"mintNativeCoin(address,uint256)","selector":"0x4f5aaaba","view":false,"gasCost":30000}`, string(data))
+	require.Equal(t, "readAllowList", export.Functions[1].Name)
+	require.True(t, export.Functions[1].View)
+	require.Equal(t, "NativeCoinMinted", export.Events[0].Name)
+
+	// Precompiles called with raw input only have a fallback function.
+	module, ok = GetRegisteredModule(NativeAssetCallConfigKey)
+	require.True(t, ok)
+	export, err = ExportModule(module)
+	require.NoError(t, err)
+	require.Empty(t, export.ABI)
+	require.Len(t, export.Functions, 1)
+	require.Equal(t, "fallback", export.Functions[0].Name)
+	require.Equal(t, NativeAssetCallGasCost, *export.Functions[0].GasCost)
+
+	module.RawABI = "{"
+	_, err = ExportModule(module)
+	require.ErrorContains(t, err, "invalid raw ABI")
+}
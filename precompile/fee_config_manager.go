@@ -8,10 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
+	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ava-labs/subnet-evm/commontype"
-	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 const (
@@ -43,15 +46,96 @@ var (
 	// Singleton StatefulPrecompiledContract for setting fee configs by permissioned callers.
 	FeeConfigManagerPrecompile StatefulPrecompiledContract = createFeeConfigManagerPrecompile(FeeConfigManagerAddress)
 
+	// FeeConfigManagerRawABI contains the raw ABI of FeeConfigManager contract.
+	FeeConfigManagerRawABI         = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"name\":\"FeeConfigChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"RoleSet\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"getFeeConfig\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"getFeeConfigLastChangedAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"blockNumber\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetBlockRate\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBaseFee\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"targetGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"baseFeeChangeDenominator\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"minBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"maxBlockGasCost\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"blockGasCostStep\",\"type\":\"uint256\"}],\"name\":\"setFeeConfig\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+	FeeConfigManagerABI    abi.ABI // will be initialized by init function
+
 	setFeeConfigSignature              = CalculateFunctionSelector("setFeeConfig(uint256,uint256,uint256,uint256,uint256,uint256,uint256,uint256)")
 	getFeeConfigSignature              = CalculateFunctionSelector("getFeeConfig()")
 	getFeeConfigLastChangedAtSignature = CalculateFunctionSelector("getFeeConfigLastChangedAt()")
 
 	feeConfigLastChangedAtKey = common.Hash{'l', 'c', 'a'}
+	feeConfigHashKey          = common.Hash{'f', 'c', 'h'}
 
 	ErrCannotChangeFee = errors.New("non-enabled cannot change fee config")
 )
 
+// FeeConfigManagerConfigKey is the JSON key of the FeeConfigManager config in the chain config and precompile upgrades.
+const FeeConfigManagerConfigKey = "feeManagerConfig"
+
+// feeConfigHashStorageVersion is the storage version from which the fee config manager also stores the hash
+// of the fee config, so that light clients can prove the whole fee config with a single storage proof.
+const feeConfigHashStorageVersion = 1
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(FeeConfigManagerRawABI))
+	if err != nil {
+		panic(err)
+	}
+	FeeConfigManagerABI = parsed
+
+	RegisterModule(Module{
+		ConfigKey: FeeConfigManagerConfigKey,
+		Address:   FeeConfigManagerAddress,
+		Contract:  FeeConfigManagerPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(FeeConfigManagerConfig) },
+		ABI:       &FeeConfigManagerABI,
+		RawABI:    FeeConfigManagerRawABI,
+		GasCosts: allowListGasCosts(map[string]uint64{
+			"setFeeConfig":              SetFeeConfigGasCost,
+			"getFeeConfig":              GetFeeConfigGasCost,
+			"getFeeConfigLastChangedAt": GetLastChangedAtGasCost,
+		}),
+		StorageSlot:     feeConfigManagerStorageSlot,
+		StorageSlotName: feeConfigManagerStorageSlotName,
+		StorageVersion:  feeConfigHashStorageVersion,
+	})
+}
+
+// feeConfigStorageKeys maps the names of the fee config fields to their storage keys.
+var feeConfigStorageKeys = map[string]common.Hash{
+	"gasLimit":                 {gasLimitKey},
+	"targetBlockRate":          {targetBlockRateKey},
+	"minBaseFee":               {minBaseFeeKey},
+	"targetGas":                {targetGasKey},
+	"baseFeeChangeDenominator": {baseFeeChangeDenominatorKey},
+	"minBlockGasCost":          {minBlockGasCostKey},
+	"maxBlockGasCost":          {maxBlockGasCostKey},
+	"blockGasCostStep":         {blockGasCostStepKey},
+}
+
+// feeConfigManagerStorageSlot resolves the "feeConfig.<field>", "feeConfigLastChangedAt" and "feeConfigHash"
+// storage slots. The "feeConfigHash" slot is only set from storage version 1 on.
+func feeConfigManagerStorageSlot(name string) (common.Hash, bool) {
+	switch name {
+	case "feeConfigLastChangedAt":
+		return feeConfigLastChangedAtKey, true
+	case "feeConfigHash":
+		return feeConfigHashKey, true
+	}
+	if field := strings.TrimPrefix(name, "feeConfig."); field != name {
+		key, ok := feeConfigStorageKeys[field]
+		return key, ok
+	}
+	return common.Hash{}, false
+}
+
+// feeConfigManagerStorageSlotName names the storage slots resolved by [feeConfigManagerStorageSlot].
+func feeConfigManagerStorageSlotName(key common.Hash) (string, bool) {
+	switch key {
+	case feeConfigLastChangedAtKey:
+		return "feeConfigLastChangedAt", true
+	case feeConfigHashKey:
+		return "feeConfigHash", true
+	}
+	for field, fieldKey := range feeConfigStorageKeys {
+		if key == fieldKey {
+			return "feeConfig." + field, true
+		}
+	}
+	return "", false
+}
+
 // FeeConfigManagerConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the FeeConfigManager specific precompile address.
 type FeeConfigManagerConfig struct {
@@ -117,7 +201,7 @@ func (c *FeeConfigManagerConfig) Configure(chainConfig ChainConfig, state StateD
 			panic(fmt.Sprintf("invalid feeConfig provided: %s", err))
 		}
 	} else {
-		if err := StoreFeeConfig(state, chainConfig.GetFeeConfig(), blockContext); err != nil {
+		if err := StoreFeeConfig(state, chainConfig.FeeConfigAt(blockContext.Timestamp()), blockContext); err != nil {
 			// This should not happen since we already checked the chain config in the genesis creation.
 			panic(fmt.Sprintf("fee config should have been verified in genesis: %s", err))
 		}
@@ -125,6 +209,14 @@ func (c *FeeConfigManagerConfig) Configure(chainConfig ChainConfig, state StateD
 	c.AllowListConfig.Configure(state, FeeConfigManagerAddress)
 }
 
+// Migrate converts the storage of the fee config manager from [fromVersion] to [toVersion].
+// Storage version 1 adds the hash of the fee config, computed from the stored fee config.
+func (c *FeeConfigManagerConfig) Migrate(state StateDB, fromVersion uint64, toVersion uint64) {
+	if fromVersion < feeConfigHashStorageVersion && toVersion >= feeConfigHashStorageVersion {
+		storeFeeConfigHash(state, GetStoredFeeConfig(state))
+	}
+}
+
 // Contract returns the singleton stateful precompiled contract to be used for the fee manager.
 func (c *FeeConfigManagerConfig) Contract() StatefulPrecompiledContract {
 	return FeeConfigManagerPrecompile
@@ -138,7 +230,7 @@ func (c *FeeConfigManagerConfig) Verify() error {
 		return nil
 	}
 
-	return c.InitialFeeConfig.Verify()
+	return utils.WithFieldPath("initialFeeConfig", c.InitialFeeConfig.Verify())
 }
 
 // String returns a string representation of the FeeConfigManagerConfig.
@@ -239,9 +331,10 @@ func UnpackFeeConfigInput(input []byte) (commontype.FeeConfig, error) {
 
 // GetStoredFeeConfig returns fee config from contract storage in given state
 func GetStoredFeeConfig(stateDB StateDB) commontype.FeeConfig {
+	vals := getStoredFeeConfigValues(stateDB)
 	feeConfig := commontype.FeeConfig{}
 	for i := minFeeConfigFieldKey; i <= numFeeConfigField; i++ {
-		val := stateDB.GetState(FeeConfigManagerAddress, common.Hash{byte(i)})
+		val := vals[i-minFeeConfigFieldKey]
 		switch i {
 		case gasLimitKey:
 			feeConfig.GasLimit = new(big.Int).Set(val.Big())
@@ -266,11 +359,52 @@ func GetStoredFeeConfig(stateDB StateDB) commontype.FeeConfig {
 	return feeConfig
 }
 
+// feeConfigCacheKey is the key under which the stored fee config values are cached,
+// if the StateDB implements [ReadCache].
+type feeConfigCacheKey struct{}
+
+// getStoredFeeConfigValues returns the raw storage values of the fee config fields,
+// reading them from the [ReadCache] of [stateDB] if they were already read since the
+// fee config was last changed.
+func getStoredFeeConfigValues(stateDB StateDB) [numFeeConfigField]common.Hash {
+	cache, ok := stateDB.(ReadCache)
+	if ok {
+		if vals, cached := cache.GetCachedRead(FeeConfigManagerAddress, feeConfigCacheKey{}); cached {
+			return vals.([numFeeConfigField]common.Hash)
+		}
+	}
+	var vals [numFeeConfigField]common.Hash
+	for i := minFeeConfigFieldKey; i <= numFeeConfigField; i++ {
+		vals[i-minFeeConfigFieldKey] = stateDB.GetState(FeeConfigManagerAddress, common.Hash{byte(i)})
+	}
+	if ok {
+		cache.CacheRead(FeeConfigManagerAddress, feeConfigCacheKey{}, vals)
+	}
+	return vals
+}
+
 func GetFeeConfigLastChangedAt(stateDB StateDB) *big.Int {
 	val := stateDB.GetState(FeeConfigManagerAddress, feeConfigLastChangedAtKey)
 	return val.Big()
 }
 
+// GetFeeConfigHash returns the hash of the fee config stored in [stateDB], or the empty hash if the storage
+// version of the fee config manager is lower than 1.
+func GetFeeConfigHash(stateDB StateDB) common.Hash {
+	return stateDB.GetState(FeeConfigManagerAddress, feeConfigHashKey)
+}
+
+// FeeConfigHash returns the hash of [feeConfig] stored by the fee config manager, which is the keccak256
+// hash of the ABI encoding of [feeConfig] returned by getFeeConfig.
+func FeeConfigHash(feeConfig commontype.FeeConfig) common.Hash {
+	return crypto.Keccak256Hash(packFeeConfigHelper(feeConfig, false))
+}
+
+// storeFeeConfigHash stores the hash of [feeConfig] to [stateDB].
+func storeFeeConfigHash(stateDB StateDB, feeConfig commontype.FeeConfig) {
+	stateDB.SetState(FeeConfigManagerAddress, feeConfigHashKey, FeeConfigHash(feeConfig))
+}
+
 // StoreFeeConfig stores given [feeConfig] and block number in the [blockContext] to the [stateDB].
 // A validation on [feeConfig] is done before storing.
 func StoreFeeConfig(stateDB StateDB, feeConfig commontype.FeeConfig, blockContext BlockContext) error {
@@ -308,6 +442,9 @@ func StoreFeeConfig(stateDB StateDB, feeConfig commontype.FeeConfig, blockContex
 		return fmt.Errorf("blockNumber cannot be nil")
 	}
 	stateDB.SetState(FeeConfigManagerAddress, feeConfigLastChangedAtKey, common.BigToHash(blockNumber))
+	if GetPrecompileStorageVersion(stateDB, FeeConfigManagerAddress) >= feeConfigHashStorageVersion {
+		storeFeeConfigHash(stateDB, feeConfig)
+	}
 
 	return nil
 }
@@ -315,27 +452,22 @@ func StoreFeeConfig(stateDB StateDB, feeConfig commontype.FeeConfig, blockContex
 // setFeeConfig checks if the caller has permissions to set the fee config.
 // The execution function parses [input] into FeeConfig structure and sets contract storage accordingly.
 func setFeeConfig(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, SetFeeConfigGasCost); err != nil {
-		return nil, 0, err
-	}
-
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
-
 	feeConfig, err := UnpackFeeConfigInput(input)
 	if err != nil {
-		return nil, remainingGas, err
+		return nil, suppliedGas, err
 	}
 
 	stateDB := accessibleState.GetStateDB()
 	// Verify that the caller is in the allow list and therefore has the right to modify it
 	callerStatus := getAllowListStatus(stateDB, FeeConfigManagerAddress, caller)
 	if !callerStatus.IsEnabled() {
-		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotChangeFee, caller)
+		return nil, suppliedGas, fmt.Errorf("%w: %s", ErrCannotChangeFee, caller)
 	}
 
 	if err := StoreFeeConfig(stateDB, feeConfig, accessibleState.GetBlockContext()); err != nil {
+		return nil, suppliedGas, err
+	}
+	if remainingGas, err = emitFeeConfigChangedEvent(accessibleState, suppliedGas, caller, feeConfig); err != nil {
 		return nil, remainingGas, err
 	}
 
@@ -346,32 +478,24 @@ func setFeeConfig(accessibleState PrecompileAccessibleState, caller common.Addre
 // getFeeConfig returns the stored fee config as an output.
 // The execution function reads the contract state for the stored fee config and returns the output.
 func getFeeConfig(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, GetFeeConfigGasCost); err != nil {
-		return nil, 0, err
-	}
-
 	feeConfig := GetStoredFeeConfig(accessibleState.GetStateDB())
 
 	output, err := PackFeeConfig(feeConfig)
 	if err != nil {
-		return nil, remainingGas, err
+		return nil, suppliedGas, err
 	}
 
 	// Return the fee config as output and the remaining gas
-	return output, remainingGas, err
+	return output, suppliedGas, err
 }
 
 // getFeeConfigLastChangedAt returns the block number that fee config was last changed in.
 // The execution function reads the contract state for the stored block number and returns the output.
 func getFeeConfigLastChangedAt(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, GetLastChangedAtGasCost); err != nil {
-		return nil, 0, err
-	}
-
 	lastChangedAt := GetFeeConfigLastChangedAt(accessibleState.GetStateDB())
 
 	// Return an empty output and the remaining gas
-	return common.BigToHash(lastChangedAt).Bytes(), remainingGas, err
+	return common.BigToHash(lastChangedAt).Bytes(), suppliedGas, err
 }
 
 // createFeeConfigManagerPrecompile returns a StatefulPrecompiledContract
@@ -380,12 +504,12 @@ func getFeeConfigLastChangedAt(accessibleState PrecompileAccessibleState, caller
 func createFeeConfigManagerPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
 	feeConfigManagerFunctions := createAllowListFunctions(precompileAddr)
 
-	setFeeConfigFunc := newStatefulPrecompileFunction(setFeeConfigSignature, setFeeConfig)
-	getFeeConfigFunc := newStatefulPrecompileFunction(getFeeConfigSignature, getFeeConfig)
-	getFeeConfigLastChangedAtFunc := newStatefulPrecompileFunction(getFeeConfigLastChangedAtSignature, getFeeConfigLastChangedAt)
+	setFeeConfigFunc := newStatefulPrecompileFunctionWithGas("setFeeConfig", setFeeConfigSignature, false, fixedGas(SetFeeConfigGasCost), setFeeConfig)
+	getFeeConfigFunc := newStatefulPrecompileFunctionWithGas("getFeeConfig", getFeeConfigSignature, true, fixedGas(GetFeeConfigGasCost), getFeeConfig)
+	getFeeConfigLastChangedAtFunc := newStatefulPrecompileFunctionWithGas("getFeeConfigLastChangedAt", getFeeConfigLastChangedAtSignature, true, fixedGas(GetLastChangedAtGasCost), getFeeConfigLastChangedAt)
 
 	feeConfigManagerFunctions = append(feeConfigManagerFunctions, setFeeConfigFunc, getFeeConfigFunc, getFeeConfigLastChangedAtFunc)
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, feeConfigManagerFunctions)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, feeConfigManagerFunctions)
 	return contract
 }
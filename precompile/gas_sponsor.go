@@ -0,0 +1,527 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// gasSponsorLogGasCost covers a log with up to two topics and a single word of data
+	// (LogGas + 2 * LogTopicGas + 32 * LogDataGas).
+	gasSponsorLogGasCost uint64 = 375 + 2*375 + 32*8
+
+	DepositGasCost          uint64 = 3*writeGasCostPerSlot + ReadAllowListGasCost + gasSponsorLogGasCost // write deposit, sender and precompile balances + read allow list + log
+	WithdrawGasCost         uint64 = 3*writeGasCostPerSlot + gasSponsorLogGasCost                        // write deposit, sender and precompile balances + log
+	DepositOfGasCost        uint64 = readGasCostPerSlot
+	SponsorAccountGasCost   uint64 = writeGasCostPerSlot + readGasCostPerSlot + ReadAllowListGasCost + gasSponsorLogGasCost // read and write sponsor + read allow list + log
+	UnsponsorAccountGasCost uint64 = writeGasCostPerSlot + readGasCostPerSlot + gasSponsorLogGasCost                        // read and write sponsor + log
+	SponsorOfGasCost        uint64 = 2*readGasCostPerSlot + ReadAllowListGasCost                                            // read target and sender sponsors + read allow list
+
+	// GasSponsorRawABI contains the raw ABI of GasSponsor contract.
+	GasSponsorRawABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"Deposited\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"SenderSponsored\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"SenderUnsponsored\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"TargetSponsored\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"TargetUnsponsored\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"Withdrawn\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"deposit\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"}],\"name\":\"depositOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"sponsorOf\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"sponsor\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"sponsorSender\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"sponsorTarget\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"}],\"name\":\"unsponsorSender\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"}],\"name\":\"unsponsorTarget\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"withdraw\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &GasSponsorConfig{}
+
+	ErrCannotDeposit        = errors.New("non-enabled cannot call deposit")
+	ErrCannotSponsor        = errors.New("non-enabled cannot sponsor accounts")
+	ErrInsufficientDeposit  = errors.New("insufficient sponsor deposit")
+	ErrAlreadySponsored     = errors.New("account is already sponsored")
+	ErrNotSponsoredByCaller = errors.New("account is not sponsored by caller")
+
+	GasSponsorABI        abi.ABI                     // will be initialized by init function
+	GasSponsorPrecompile StatefulPrecompiledContract // will be initialized by init function
+
+	// Storage layout of the sponsorships. The allow list uses [address.Hash()] as its keys, so the
+	// sponsorship keys are derived by hashing a prefix with the address to avoid any overlap.
+	sponsorDepositPrefix  = []byte("sponsorDeposit")
+	sponsoredSenderPrefix = []byte("sponsoredSender")
+	sponsoredTargetPrefix = []byte("sponsoredTarget")
+)
+
+// GasSponsorConfig implements the StatefulPrecompileConfig interface while adding in the
+// GasSponsor specific precompile config. Sponsors deposit native coin into the precompile and
+// authorize it to pay for the gas of transactions issued by a sender or sent to a target.
+// The allow list controls which addresses are permitted to act as sponsors.
+type GasSponsorConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+}
+
+// GasSponsorConfigKey is the JSON key of the GasSponsor config in the chain config and precompile upgrades.
+const GasSponsorConfigKey = "gasSponsorConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(GasSponsorRawABI))
+	if err != nil {
+		panic(err)
+	}
+	GasSponsorABI = parsed
+	GasSponsorPrecompile = createGasSponsorPrecompile(GasSponsorAddress)
+
+	RegisterModule(Module{
+		ConfigKey: GasSponsorConfigKey,
+		Address:   GasSponsorAddress,
+		Contract:  GasSponsorPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(GasSponsorConfig) },
+		ABI:       &GasSponsorABI,
+		RawABI:    GasSponsorRawABI,
+		GasCosts: allowListGasCosts(map[string]uint64{
+			"deposit":         DepositGasCost,
+			"withdraw":        WithdrawGasCost,
+			"depositOf":       DepositOfGasCost,
+			"sponsorSender":   SponsorAccountGasCost,
+			"sponsorTarget":   SponsorAccountGasCost,
+			"unsponsorSender": UnsponsorAccountGasCost,
+			"unsponsorTarget": UnsponsorAccountGasCost,
+			"sponsorOf":       SponsorOfGasCost,
+		}),
+	})
+}
+
+// NewGasSponsorConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// GasSponsor with the given [admins] and [enableds] as members of the allowlist.
+func NewGasSponsorConfig(blockTimestamp *big.Int, admins []common.Address, enableds []common.Address) *GasSponsorConfig {
+	return &GasSponsorConfig{
+		AllowListConfig: AllowListConfig{
+			AllowListAdmins:  admins,
+			EnabledAddresses: enableds,
+		},
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableGasSponsorConfig returns config for a network upgrade at [blockTimestamp]
+// that disables GasSponsor.
+// Note: disabling the precompile resets its state, which clears all sponsorships and burns
+// any outstanding deposits.
+func NewDisableGasSponsorConfig(blockTimestamp *big.Int) *GasSponsorConfig {
+	return &GasSponsorConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the gas sponsor precompile.
+func (c *GasSponsorConfig) Address() common.Address {
+	return GasSponsorAddress
+}
+
+// Configure configures [state] with the desired admins based on [c].
+func (c *GasSponsorConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	c.AllowListConfig.Configure(state, GasSponsorAddress)
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the gas sponsor.
+func (c *GasSponsorConfig) Contract() StatefulPrecompiledContract {
+	return GasSponsorPrecompile
+}
+
+// Verify returns an error if the allow list is invalid.
+func (c *GasSponsorConfig) Verify() error { return c.AllowListConfig.Verify() }
+
+// Equal returns true if [s] is a [*GasSponsorConfig] and it has been configured identical to [c].
+func (c *GasSponsorConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*GasSponsorConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.AllowListConfig.Equal(&other.AllowListConfig)
+}
+
+// String returns a string representation of the GasSponsorConfig.
+func (c *GasSponsorConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// GetGasSponsorAllowListStatus returns the role of [address] for the GasSponsor allow list.
+func GetGasSponsorAllowListStatus(stateDB StateDB, address common.Address) AllowListRole {
+	return getAllowListStatus(stateDB, GasSponsorAddress, address)
+}
+
+// SetGasSponsorAllowListStatus sets the permissions of [address] to [role] for the
+// GasSponsor allow list. Assumes [role] has already been verified as valid.
+func SetGasSponsorAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, GasSponsorAddress, address, role)
+}
+
+// sponsorDepositKey returns the storage key holding the deposit of [sponsor].
+func sponsorDepositKey(sponsor common.Address) common.Hash {
+	return crypto.Keccak256Hash(sponsorDepositPrefix, sponsor.Bytes())
+}
+
+// sponsoredSenderKey returns the storage key holding the sponsor of transactions issued by [sender].
+func sponsoredSenderKey(sender common.Address) common.Hash {
+	return crypto.Keccak256Hash(sponsoredSenderPrefix, sender.Bytes())
+}
+
+// sponsoredTargetKey returns the storage key holding the sponsor of transactions sent to [target].
+func sponsoredTargetKey(target common.Address) common.Hash {
+	return crypto.Keccak256Hash(sponsoredTargetPrefix, target.Bytes())
+}
+
+// GetSponsorDeposit returns the amount deposited by [sponsor] that is available to pay for gas.
+func GetSponsorDeposit(stateDB StateDB, sponsor common.Address) *big.Int {
+	return stateDB.GetState(GasSponsorAddress, sponsorDepositKey(sponsor)).Big()
+}
+
+// setSponsorDeposit sets the deposit of [sponsor] to [amount].
+func setSponsorDeposit(stateDB StateDB, sponsor common.Address, amount *big.Int) {
+	stateDB.SetState(GasSponsorAddress, sponsorDepositKey(sponsor), common.BigToHash(amount))
+}
+
+// GetSenderSponsor returns the sponsor of transactions issued by [sender] and false if there is none.
+func GetSenderSponsor(stateDB StateDB, sender common.Address) (common.Address, bool) {
+	val := stateDB.GetState(GasSponsorAddress, sponsoredSenderKey(sender))
+	return common.BytesToAddress(val.Bytes()), val != (common.Hash{})
+}
+
+// GetTargetSponsor returns the sponsor of transactions sent to [target] and false if there is none.
+func GetTargetSponsor(stateDB StateDB, target common.Address) (common.Address, bool) {
+	val := stateDB.GetState(GasSponsorAddress, sponsoredTargetKey(target))
+	return common.BytesToAddress(val.Bytes()), val != (common.Hash{})
+}
+
+// GetGasSponsor returns the sponsor that pays for the gas of a transaction from [sender] to [target].
+// A sponsorship of [target] takes precedence over a sponsorship of [sender]. Sponsorships are only
+// honoured while the sponsor is enabled in the allow list. Returns false if there is no valid sponsor.
+func GetGasSponsor(stateDB StateDB, sender common.Address, target *common.Address) (common.Address, bool) {
+	if target != nil {
+		if sponsor, ok := GetTargetSponsor(stateDB, *target); ok && GetGasSponsorAllowListStatus(stateDB, sponsor).IsEnabled() {
+			return sponsor, true
+		}
+	}
+	if sponsor, ok := GetSenderSponsor(stateDB, sender); ok && GetGasSponsorAllowListStatus(stateDB, sponsor).IsEnabled() {
+		return sponsor, true
+	}
+	return common.Address{}, false
+}
+
+// ChargeSponsor deducts [amount] from the deposit of [sponsor].
+// Returns false without modifying [stateDB] if the deposit of [sponsor] is less than [amount].
+func ChargeSponsor(stateDB StateDB, sponsor common.Address, amount *big.Int) bool {
+	deposit := GetSponsorDeposit(stateDB, sponsor)
+	if deposit.Cmp(amount) < 0 {
+		return false
+	}
+	setSponsorDeposit(stateDB, sponsor, deposit.Sub(deposit, amount))
+	stateDB.SubBalance(GasSponsorAddress, amount)
+	return true
+}
+
+// RefundSponsor returns [amount] to the deposit of [sponsor].
+func RefundSponsor(stateDB StateDB, sponsor common.Address, amount *big.Int) {
+	deposit := GetSponsorDeposit(stateDB, sponsor)
+	setSponsorDeposit(stateDB, sponsor, deposit.Add(deposit, amount))
+	stateDB.AddBalance(GasSponsorAddress, amount)
+}
+
+// PackDeposit packs [amount] of type *big.Int into the appropriate arguments for deposit.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackDeposit(amount *big.Int) ([]byte, error) {
+	return GasSponsorABI.Pack("deposit", amount)
+}
+
+// PackWithdraw packs [amount] of type *big.Int into the appropriate arguments for withdraw.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackWithdraw(amount *big.Int) ([]byte, error) {
+	return GasSponsorABI.Pack("withdraw", amount)
+}
+
+// PackDepositOf packs [sponsor] of type common.Address into the appropriate arguments for depositOf.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackDepositOf(sponsor common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("depositOf", sponsor)
+}
+
+// PackDepositOfOutput attempts to pack given amount of type *big.Int
+// to conform the ABI outputs.
+func PackDepositOfOutput(amount *big.Int) ([]byte, error) {
+	return GasSponsorABI.PackOutput("depositOf", amount)
+}
+
+// PackSponsorSender packs [sender] of type common.Address into the appropriate arguments for sponsorSender.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackSponsorSender(sender common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("sponsorSender", sender)
+}
+
+// PackUnsponsorSender packs [sender] of type common.Address into the appropriate arguments for unsponsorSender.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackUnsponsorSender(sender common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("unsponsorSender", sender)
+}
+
+// PackSponsorTarget packs [target] of type common.Address into the appropriate arguments for sponsorTarget.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackSponsorTarget(target common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("sponsorTarget", target)
+}
+
+// PackUnsponsorTarget packs [target] of type common.Address into the appropriate arguments for unsponsorTarget.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackUnsponsorTarget(target common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("unsponsorTarget", target)
+}
+
+// PackSponsorOf packs [sender] and [target] of type common.Address into the appropriate arguments for sponsorOf.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackSponsorOf(sender common.Address, target common.Address) ([]byte, error) {
+	return GasSponsorABI.Pack("sponsorOf", sender, target)
+}
+
+// PackSponsorOfOutput attempts to pack given sponsor of type common.Address
+// to conform the ABI outputs.
+func PackSponsorOfOutput(sponsor common.Address) ([]byte, error) {
+	return GasSponsorABI.PackOutput("sponsorOf", sponsor)
+}
+
+// UnpackGasSponsorAmountInput attempts to unpack [input] into the *big.Int argument
+// of the function [name]. Assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackGasSponsorAmountInput(name string, input []byte) (*big.Int, error) {
+	res, err := GasSponsorABI.UnpackInput(name, input)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// UnpackGasSponsorAddressInput attempts to unpack [input] into the common.Address argument
+// of the function [name]. Assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackGasSponsorAddressInput(name string, input []byte) (common.Address, error) {
+	res, err := GasSponsorABI.UnpackInput(name, input)
+	if err != nil {
+		return common.Address{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	return unpacked, nil
+}
+
+// UnpackSponsorOfInput attempts to unpack [input] into the sender and target arguments of sponsorOf.
+// Assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackSponsorOfInput(input []byte) (common.Address, common.Address, error) {
+	res, err := GasSponsorABI.UnpackInput("sponsorOf", input)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	sender := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	target := *abi.ConvertType(res[1], new(common.Address)).(*common.Address)
+	return sender, target, nil
+}
+
+// emitGasSponsorEvent adds a log for the event [name] with [args] packed as its topics and data.
+func emitGasSponsorEvent(accessibleState PrecompileAccessibleState, name string, args ...interface{}) error {
+	topics, data, err := GasSponsorABI.PackEvent(name, args...)
+	if err != nil {
+		return err
+	}
+	accessibleState.GetStateDB().AddLog(GasSponsorAddress, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+	return nil
+}
+
+// deposit moves the given amount of native coin from the caller's balance into the caller's deposit.
+func deposit(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, DepositGasCost); err != nil {
+		return nil, 0, err
+	}
+	amount, err := UnpackGasSponsorAmountInput("deposit", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to act as a sponsor
+	callerStatus := getAllowListStatus(stateDB, GasSponsorAddress, caller)
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotDeposit, caller)
+	}
+	if stateDB.GetBalance(caller).Cmp(amount) < 0 {
+		return nil, remainingGas, vmerrs.ErrInsufficientBalance
+	}
+
+	stateDB.SubBalance(caller, amount)
+	RefundSponsor(stateDB, caller, amount)
+	if err := emitGasSponsorEvent(accessibleState, "Deposited", caller, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// withdraw returns the given amount of the caller's deposit to the caller's balance.
+// Withdrawals are permitted regardless of the caller's role so that funds are never locked.
+func withdraw(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, WithdrawGasCost); err != nil {
+		return nil, 0, err
+	}
+	amount, err := UnpackGasSponsorAmountInput("withdraw", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	if !ChargeSponsor(stateDB, caller, amount) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInsufficientDeposit, caller)
+	}
+	stateDB.AddBalance(caller, amount)
+	if err := emitGasSponsorEvent(accessibleState, "Withdrawn", caller, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+func depositOf(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, DepositOfGasCost); err != nil {
+		return nil, 0, err
+	}
+	sponsor, err := UnpackGasSponsorAddressInput("depositOf", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := PackDepositOfOutput(GetSponsorDeposit(accessibleState.GetStateDB(), sponsor))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// createSponsorAccount returns a function that registers the caller as the sponsor of the account
+// given as input, using [keyFn] to select between sender and target sponsorships.
+func createSponsorAccount(name string, event string, keyFn func(common.Address) common.Hash) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, SponsorAccountGasCost); err != nil {
+			return nil, 0, err
+		}
+		account, err := UnpackGasSponsorAddressInput(name, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		// Verify that the caller is in the allow list and therefore has the right to act as a sponsor
+		callerStatus := getAllowListStatus(stateDB, GasSponsorAddress, caller)
+		if !callerStatus.IsEnabled() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotSponsor, caller)
+		}
+		key := keyFn(account)
+		if stateDB.GetState(GasSponsorAddress, key) != (common.Hash{}) {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrAlreadySponsored, account)
+		}
+
+		stateDB.SetState(GasSponsorAddress, key, caller.Hash())
+		if err := emitGasSponsorEvent(accessibleState, event, caller, account); err != nil {
+			return nil, remainingGas, err
+		}
+
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// createUnsponsorAccount returns a function that removes the caller's sponsorship of the account
+// given as input, using [keyFn] to select between sender and target sponsorships.
+// Sponsorships can be removed regardless of the caller's role.
+func createUnsponsorAccount(name string, event string, keyFn func(common.Address) common.Hash) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, UnsponsorAccountGasCost); err != nil {
+			return nil, 0, err
+		}
+		account, err := UnpackGasSponsorAddressInput(name, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		key := keyFn(account)
+		if stateDB.GetState(GasSponsorAddress, key) != caller.Hash() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrNotSponsoredByCaller, account)
+		}
+
+		stateDB.SetState(GasSponsorAddress, key, common.Hash{})
+		if err := emitGasSponsorEvent(accessibleState, event, caller, account); err != nil {
+			return nil, remainingGas, err
+		}
+
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+func sponsorOf(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsorOfGasCost); err != nil {
+		return nil, 0, err
+	}
+	sender, target, err := UnpackSponsorOfInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// The zero address is returned if there is no valid sponsor.
+	sponsor, _ := GetGasSponsor(accessibleState.GetStateDB(), sender, &target)
+	packedOutput, err := PackSponsorOfOutput(sponsor)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// createGasSponsorPrecompile returns a StatefulPrecompiledContract with R/W control of sponsor deposits
+// and sponsorships. Access to deposits and new sponsorships is controlled by an allow list for [precompileAddr].
+func createGasSponsorPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	var functions []*statefulPrecompileFunction
+	functions = append(functions, createAllowListFunctions(precompileAddr)...)
+
+	abiFunctionMap := map[string]RunStatefulPrecompileFunc{
+		"deposit":         deposit,
+		"withdraw":        withdraw,
+		"depositOf":       depositOf,
+		"sponsorSender":   createSponsorAccount("sponsorSender", "SenderSponsored", sponsoredSenderKey),
+		"unsponsorSender": createUnsponsorAccount("unsponsorSender", "SenderUnsponsored", sponsoredSenderKey),
+		"sponsorTarget":   createSponsorAccount("sponsorTarget", "TargetSponsored", sponsoredTargetKey),
+		"unsponsorTarget": createUnsponsorAccount("unsponsorTarget", "TargetUnsponsored", sponsoredTargetKey),
+		"sponsorOf":       sponsorOf,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := GasSponsorABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, newStatefulPrecompileFunction(method.Name, method.ID, method.IsConstant(), function))
+	}
+
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
@@ -0,0 +1,585 @@
+// (c) 2023 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// maxGasTokenStringLen is the maximum length of the name and symbol of the gas token,
+	// which are each stored in a single storage slot along with their length.
+	maxGasTokenStringLen = common.HashLength - 1
+
+	// gasTokenLogGasCost covers a log with three topics and a single word of data
+	// (LogGas + 3 * LogTopicGas + 32 * LogDataGas).
+	gasTokenLogGasCost uint64 = 375 + 3*375 + 32*8
+
+	GasTokenReadGasCost         uint64 = readGasCostPerSlot                                                // read a single slot
+	GasTokenTransferGasCost     uint64 = 2*writeGasCostPerSlot + gasTokenLogGasCost                        // write sender and recipient balances + log
+	GasTokenApproveGasCost      uint64 = writeGasCostPerSlot + gasTokenLogGasCost                          // write allowance + log
+	GasTokenTransferFromGasCost uint64 = 3*writeGasCostPerSlot + gasTokenLogGasCost                        // write allowance, sender and recipient balances + log
+	GasTokenMintGasCost         uint64 = 2*writeGasCostPerSlot + ReadAllowListGasCost + gasTokenLogGasCost // write recipient balance and total supply + read allow list + log
+
+	// GasTokenRawABI contains the raw ABI of GasToken contract.
+	GasTokenRawABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Approval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"Transfer\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"}],\"name\":\"allowance\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"spender\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"approve\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"balanceOf\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"balance\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"decimals\",\"outputs\":[{\"internalType\":\"uint8\",\"name\":\"decimals\",\"type\":\"uint8\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"mint\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"name\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"name\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"readAllowList\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"role\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setEnabled\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"addr\",\"type\":\"address\"}],\"name\":\"setNone\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"symbol\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"symbol\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalSupply\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"supply\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transfer\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"transferFrom\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &GasTokenConfig{}
+
+	ErrCannotMintGasToken            = errors.New("non-enabled cannot mint gas token")
+	ErrInsufficientGasTokenBalance   = errors.New("insufficient gas token balance")
+	ErrInsufficientGasTokenAllowance = errors.New("insufficient gas token allowance")
+	ErrGasTokenStringTooLong         = fmt.Errorf("gas token name and symbol cannot be longer than %d bytes", maxGasTokenStringLen)
+
+	GasTokenABI        abi.ABI                     // will be initialized by init function
+	GasTokenPrecompile StatefulPrecompiledContract // will be initialized by init function
+
+	// Storage layout of the token. The allow list uses [address.Hash()] as its keys, so the
+	// balance and allowance keys are derived by hashing a prefix with the addresses to avoid
+	// any overlap.
+	gasTokenNameStorageKey        = common.Hash{'g', 't', 'n', 's', 'k'}
+	gasTokenSymbolStorageKey      = common.Hash{'g', 't', 's', 's', 'k'}
+	gasTokenDecimalsStorageKey    = common.Hash{'g', 't', 'd', 's', 'k'}
+	gasTokenTotalSupplyStorageKey = common.Hash{'g', 't', 't', 's', 's', 'k'}
+	gasTokenBalancePrefix         = []byte("gasTokenBalance")
+	gasTokenAllowancePrefix       = []byte("gasTokenAllowance")
+)
+
+// GasTokenConfig implements the StatefulPrecompileConfig interface while adding in the
+// GasToken specific precompile config. While the precompile is enabled, the gas of every
+// transaction is paid in the ERC-20 token it implements rather than in the native coin, and
+// the fees are paid to the coinbase in the token as well.
+// The allow list controls which addresses are permitted to mint the token.
+type GasTokenConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+	Name            string                                   `json:"name,omitempty"`
+	Symbol          string                                   `json:"symbol,omitempty"`
+	Decimals        uint8                                    `json:"decimals,omitempty"`
+	InitialBalances map[common.Address]*math.HexOrDecimal256 `json:"initialBalances,omitempty"` // initial balances to be immediately minted
+}
+
+// GasTokenConfigKey is the JSON key of the GasToken config in the chain config and precompile upgrades.
+const GasTokenConfigKey = "gasTokenConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(GasTokenRawABI))
+	if err != nil {
+		panic(err)
+	}
+	GasTokenABI = parsed
+	GasTokenPrecompile = createGasTokenPrecompile(GasTokenAddress)
+
+	RegisterModule(Module{
+		ConfigKey: GasTokenConfigKey,
+		Address:   GasTokenAddress,
+		Contract:  GasTokenPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(GasTokenConfig) },
+		ABI:       &GasTokenABI,
+		RawABI:    GasTokenRawABI,
+		GasCosts: allowListGasCosts(map[string]uint64{
+			"name":         GasTokenReadGasCost,
+			"symbol":       GasTokenReadGasCost,
+			"decimals":     GasTokenReadGasCost,
+			"totalSupply":  GasTokenReadGasCost,
+			"balanceOf":    GasTokenReadGasCost,
+			"allowance":    GasTokenReadGasCost,
+			"transfer":     GasTokenTransferGasCost,
+			"approve":      GasTokenApproveGasCost,
+			"transferFrom": GasTokenTransferFromGasCost,
+			"mint":         GasTokenMintGasCost,
+		}),
+	})
+}
+
+// NewGasTokenConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// GasToken with the given [admins] and [enableds] as members of the allowlist. Also mints
+// balances according to [initialBalances] when the upgrade activates.
+func NewGasTokenConfig(blockTimestamp *big.Int, admins []common.Address, enableds []common.Address, name string, symbol string, decimals uint8, initialBalances map[common.Address]*math.HexOrDecimal256) *GasTokenConfig {
+	return &GasTokenConfig{
+		AllowListConfig: AllowListConfig{
+			AllowListAdmins:  admins,
+			EnabledAddresses: enableds,
+		},
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+		Name:              name,
+		Symbol:            symbol,
+		Decimals:          decimals,
+		InitialBalances:   initialBalances,
+	}
+}
+
+// NewDisableGasTokenConfig returns config for a network upgrade at [blockTimestamp]
+// that disables GasToken, after which gas is paid in the native coin again.
+// Note: disabling the precompile resets its state, which clears all balances.
+func NewDisableGasTokenConfig(blockTimestamp *big.Int) *GasTokenConfig {
+	return &GasTokenConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the gas token precompile.
+func (c *GasTokenConfig) Address() common.Address {
+	return GasTokenAddress
+}
+
+// Configure configures [state] with the token metadata, initial balances and desired admins
+// based on [c].
+func (c *GasTokenConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	state.SetState(GasTokenAddress, gasTokenNameStorageKey, packGasTokenString(c.Name))
+	state.SetState(GasTokenAddress, gasTokenSymbolStorageKey, packGasTokenString(c.Symbol))
+	state.SetState(GasTokenAddress, gasTokenDecimalsStorageKey, common.BigToHash(new(big.Int).SetUint64(uint64(c.Decimals))))
+	for to, amount := range c.InitialBalances {
+		if amount != nil {
+			mintGasToken(state, to, (*big.Int)(amount))
+		}
+	}
+
+	c.AllowListConfig.Configure(state, GasTokenAddress)
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the gas token.
+func (c *GasTokenConfig) Contract() StatefulPrecompiledContract {
+	return GasTokenPrecompile
+}
+
+// Verify returns an error if the allow list, token metadata or initial balances are invalid.
+func (c *GasTokenConfig) Verify() error {
+	if err := c.AllowListConfig.Verify(); err != nil {
+		return err
+	}
+	if len(c.Name) > maxGasTokenStringLen || len(c.Symbol) > maxGasTokenStringLen {
+		return ErrGasTokenStringTooLong
+	}
+	// ensure that all of the initial balances in the map are non-nil positive values
+	for addr, amount := range c.InitialBalances {
+		if amount == nil {
+			return fmt.Errorf("initial balances cannot contain nil amount for address %s", addr)
+		}
+		bigIntAmount := (*big.Int)(amount)
+		if bigIntAmount.Sign() < 1 {
+			return fmt.Errorf("initial balances cannot contain invalid amount %v for address %s", bigIntAmount, addr)
+		}
+	}
+	return nil
+}
+
+// Equal returns true if [s] is a [*GasTokenConfig] and it has been configured identical to [c].
+func (c *GasTokenConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*GasTokenConfig)
+	if !ok {
+		return false
+	}
+	eq := c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	if !eq || c.Name != other.Name || c.Symbol != other.Symbol || c.Decimals != other.Decimals {
+		return false
+	}
+
+	if len(c.InitialBalances) != len(other.InitialBalances) {
+		return false
+	}
+	for address, amount := range c.InitialBalances {
+		val, ok := other.InitialBalances[address]
+		if !ok {
+			return false
+		}
+		if !utils.BigNumEqual((*big.Int)(amount), (*big.Int)(val)) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the GasTokenConfig.
+func (c *GasTokenConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// GetGasTokenAllowListStatus returns the role of [address] for the GasToken allow list.
+func GetGasTokenAllowListStatus(stateDB StateDB, address common.Address) AllowListRole {
+	return getAllowListStatus(stateDB, GasTokenAddress, address)
+}
+
+// SetGasTokenAllowListStatus sets the permissions of [address] to [role] for the
+// GasToken allow list. Assumes [role] has already been verified as valid.
+func SetGasTokenAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, GasTokenAddress, address, role)
+}
+
+// packGasTokenString packs [s] into a single storage slot, left aligned with its length in
+// the last byte. Assumes [s] is at most [maxGasTokenStringLen] bytes long.
+func packGasTokenString(s string) common.Hash {
+	var packed common.Hash
+	copy(packed[:], s)
+	packed[common.HashLength-1] = byte(len(s))
+	return packed
+}
+
+// unpackGasTokenString returns the string packed into [packed] by [packGasTokenString].
+func unpackGasTokenString(packed common.Hash) string {
+	length := int(packed[common.HashLength-1])
+	if length > maxGasTokenStringLen {
+		length = maxGasTokenStringLen
+	}
+	return string(packed[:length])
+}
+
+// gasTokenBalanceKey returns the storage key holding the balance of [account].
+func gasTokenBalanceKey(account common.Address) common.Hash {
+	return crypto.Keccak256Hash(gasTokenBalancePrefix, account.Bytes())
+}
+
+// gasTokenAllowanceKey returns the storage key holding the amount [spender] may transfer
+// on behalf of [owner].
+func gasTokenAllowanceKey(owner common.Address, spender common.Address) common.Hash {
+	return crypto.Keccak256Hash(gasTokenAllowancePrefix, owner.Bytes(), spender.Bytes())
+}
+
+// GetGasTokenBalance returns the gas token balance of [account].
+func GetGasTokenBalance(stateDB StateDB, account common.Address) *big.Int {
+	return stateDB.GetState(GasTokenAddress, gasTokenBalanceKey(account)).Big()
+}
+
+// setGasTokenBalance sets the gas token balance of [account] to [amount].
+func setGasTokenBalance(stateDB StateDB, account common.Address, amount *big.Int) {
+	stateDB.SetState(GasTokenAddress, gasTokenBalanceKey(account), common.BigToHash(amount))
+}
+
+// GetGasTokenTotalSupply returns the total supply of the gas token.
+func GetGasTokenTotalSupply(stateDB StateDB) *big.Int {
+	return stateDB.GetState(GasTokenAddress, gasTokenTotalSupplyStorageKey).Big()
+}
+
+// GetGasTokenAllowance returns the amount of gas token [spender] may transfer on behalf of [owner].
+func GetGasTokenAllowance(stateDB StateDB, owner common.Address, spender common.Address) *big.Int {
+	return stateDB.GetState(GasTokenAddress, gasTokenAllowanceKey(owner, spender)).Big()
+}
+
+// setGasTokenAllowance sets the amount of gas token [spender] may transfer on behalf of [owner].
+func setGasTokenAllowance(stateDB StateDB, owner common.Address, spender common.Address, amount *big.Int) {
+	stateDB.SetState(GasTokenAddress, gasTokenAllowanceKey(owner, spender), common.BigToHash(amount))
+}
+
+// mintGasToken adds [amount] to the balance of [to] and to the total supply.
+func mintGasToken(stateDB StateDB, to common.Address, amount *big.Int) {
+	setGasTokenBalance(stateDB, to, new(big.Int).Add(GetGasTokenBalance(stateDB, to), amount))
+	supply := GetGasTokenTotalSupply(stateDB)
+	stateDB.SetState(GasTokenAddress, gasTokenTotalSupplyStorageKey, common.BigToHash(supply.Add(supply, amount)))
+}
+
+// transferGasToken moves [amount] of gas token from [from] to [to].
+// Returns false without modifying [stateDB] if the balance of [from] is less than [amount].
+func transferGasToken(stateDB StateDB, from common.Address, to common.Address, amount *big.Int) bool {
+	balance := GetGasTokenBalance(stateDB, from)
+	if balance.Cmp(amount) < 0 {
+		return false
+	}
+	setGasTokenBalance(stateDB, from, balance.Sub(balance, amount))
+	setGasTokenBalance(stateDB, to, new(big.Int).Add(GetGasTokenBalance(stateDB, to), amount))
+	return true
+}
+
+// ChargeGasToken moves [amount] of gas token from [account] to the balance held by the precompile
+// itself, where it stays until the gas is settled with [ReleaseGasToken].
+// Returns false without modifying [stateDB] if the balance of [account] is less than [amount].
+func ChargeGasToken(stateDB StateDB, account common.Address, amount *big.Int) bool {
+	return transferGasToken(stateDB, account, GasTokenAddress, amount)
+}
+
+// ReleaseGasToken moves [amount] of gas token held by the precompile to [account]. This is used
+// to refund the unused gas to the sender and to pay the fees to the coinbase.
+// Assumes that [amount] was previously charged with [ChargeGasToken].
+func ReleaseGasToken(stateDB StateDB, account common.Address, amount *big.Int) {
+	transferGasToken(stateDB, GasTokenAddress, account, amount)
+}
+
+// PackGasTokenBalanceOf packs [account] of type common.Address into the appropriate arguments for balanceOf.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenBalanceOf(account common.Address) ([]byte, error) {
+	return GasTokenABI.Pack("balanceOf", account)
+}
+
+// PackGasTokenTransfer packs [to] and [amount] into the appropriate arguments for transfer.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenTransfer(to common.Address, amount *big.Int) ([]byte, error) {
+	return GasTokenABI.Pack("transfer", to, amount)
+}
+
+// PackGasTokenApprove packs [spender] and [amount] into the appropriate arguments for approve.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenApprove(spender common.Address, amount *big.Int) ([]byte, error) {
+	return GasTokenABI.Pack("approve", spender, amount)
+}
+
+// PackGasTokenAllowance packs [owner] and [spender] into the appropriate arguments for allowance.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenAllowance(owner common.Address, spender common.Address) ([]byte, error) {
+	return GasTokenABI.Pack("allowance", owner, spender)
+}
+
+// PackGasTokenTransferFrom packs [from], [to] and [amount] into the appropriate arguments for transferFrom.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenTransferFrom(from common.Address, to common.Address, amount *big.Int) ([]byte, error) {
+	return GasTokenABI.Pack("transferFrom", from, to, amount)
+}
+
+// PackGasTokenMint packs [to] and [amount] into the appropriate arguments for mint.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGasTokenMint(to common.Address, amount *big.Int) ([]byte, error) {
+	return GasTokenABI.Pack("mint", to, amount)
+}
+
+// unpackGasTokenAddressAmountInput attempts to unpack [input] into the address and amount arguments
+// of the function [name]. Assumes that [input] does not include selector (omits first 4 func signature bytes)
+func unpackGasTokenAddressAmountInput(name string, input []byte) (common.Address, *big.Int, error) {
+	res, err := GasTokenABI.UnpackInput(name, input)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	addr := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	amount := *abi.ConvertType(res[1], new(*big.Int)).(**big.Int)
+	return addr, amount, nil
+}
+
+// emitGasTokenEvent adds a log for the event [name] with [args] packed as its topics and data.
+func emitGasTokenEvent(accessibleState PrecompileAccessibleState, name string, args ...interface{}) error {
+	topics, data, err := GasTokenABI.PackEvent(name, args...)
+	if err != nil {
+		return err
+	}
+	accessibleState.GetStateDB().AddLog(GasTokenAddress, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+	return nil
+}
+
+// createGasTokenGetter returns a view function without arguments that returns the value
+// computed by [get] from the state.
+func createGasTokenGetter(name string, get func(stateDB StateDB) interface{}) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, GasTokenReadGasCost); err != nil {
+			return nil, 0, err
+		}
+		packedOutput, err := GasTokenABI.PackOutput(name, get(accessibleState.GetStateDB()))
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		// Return the packed output and the remaining gas
+		return packedOutput, remainingGas, nil
+	}
+}
+
+func gasTokenBalanceOf(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenReadGasCost); err != nil {
+		return nil, 0, err
+	}
+	res, err := GasTokenABI.UnpackInput("balanceOf", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	account := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+
+	packedOutput, err := GasTokenABI.PackOutput("balanceOf", GetGasTokenBalance(accessibleState.GetStateDB(), account))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func gasTokenAllowance(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenReadGasCost); err != nil {
+		return nil, 0, err
+	}
+	res, err := GasTokenABI.UnpackInput("allowance", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	owner := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	spender := *abi.ConvertType(res[1], new(common.Address)).(*common.Address)
+
+	packedOutput, err := GasTokenABI.PackOutput("allowance", GetGasTokenAllowance(accessibleState.GetStateDB(), owner, spender))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// gasTokenTransfer moves the given amount of gas token from the caller's balance to the recipient.
+func gasTokenTransfer(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenTransferGasCost); err != nil {
+		return nil, 0, err
+	}
+	to, amount, err := unpackGasTokenAddressAmountInput("transfer", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	if !transferGasToken(accessibleState.GetStateDB(), caller, to, amount) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInsufficientGasTokenBalance, caller)
+	}
+	if err := emitGasTokenEvent(accessibleState, "Transfer", caller, to, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := GasTokenABI.PackOutput("transfer", true)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// gasTokenApprove sets the amount of gas token the spender may transfer on behalf of the caller.
+func gasTokenApprove(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenApproveGasCost); err != nil {
+		return nil, 0, err
+	}
+	spender, amount, err := unpackGasTokenAddressAmountInput("approve", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	setGasTokenAllowance(accessibleState.GetStateDB(), caller, spender, amount)
+	if err := emitGasTokenEvent(accessibleState, "Approval", caller, spender, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := GasTokenABI.PackOutput("approve", true)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// gasTokenTransferFrom moves the given amount of gas token from the owner's balance to the
+// recipient, spending the allowance of the caller. An allowance of the maximum uint256 value
+// is never spent.
+func gasTokenTransferFrom(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenTransferFromGasCost); err != nil {
+		return nil, 0, err
+	}
+	res, err := GasTokenABI.UnpackInput("transferFrom", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	from := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	to := *abi.ConvertType(res[1], new(common.Address)).(*common.Address)
+	amount := *abi.ConvertType(res[2], new(*big.Int)).(**big.Int)
+
+	stateDB := accessibleState.GetStateDB()
+	allowance := GetGasTokenAllowance(stateDB, from, caller)
+	if allowance.Cmp(amount) < 0 {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInsufficientGasTokenAllowance, caller)
+	}
+	if !transferGasToken(stateDB, from, to, amount) {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrInsufficientGasTokenBalance, from)
+	}
+	if allowance.Cmp(math.MaxBig256) != 0 {
+		setGasTokenAllowance(stateDB, from, caller, allowance.Sub(allowance, amount))
+	}
+	if err := emitGasTokenEvent(accessibleState, "Transfer", from, to, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	packedOutput, err := GasTokenABI.PackOutput("transferFrom", true)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// gasTokenMint mints the given amount of gas token to the recipient.
+func gasTokenMint(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GasTokenMintGasCost); err != nil {
+		return nil, 0, err
+	}
+	to, amount, err := unpackGasTokenAddressAmountInput("mint", input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to mint
+	callerStatus := getAllowListStatus(stateDB, GasTokenAddress, caller)
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotMintGasToken, caller)
+	}
+
+	mintGasToken(stateDB, to, amount)
+	if err := emitGasTokenEvent(accessibleState, "Transfer", common.Address{}, to, amount); err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// createGasTokenPrecompile returns a StatefulPrecompiledContract implementing an ERC-20 token that
+// is used to pay for gas. Minting is controlled by an allow list for [precompileAddr].
+func createGasTokenPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	var functions []*statefulPrecompileFunction
+	functions = append(functions, createAllowListFunctions(precompileAddr)...)
+
+	abiFunctionMap := map[string]RunStatefulPrecompileFunc{
+		"name": createGasTokenGetter("name", func(stateDB StateDB) interface{} {
+			return unpackGasTokenString(stateDB.GetState(GasTokenAddress, gasTokenNameStorageKey))
+		}),
+		"symbol": createGasTokenGetter("symbol", func(stateDB StateDB) interface{} {
+			return unpackGasTokenString(stateDB.GetState(GasTokenAddress, gasTokenSymbolStorageKey))
+		}),
+		"decimals": createGasTokenGetter("decimals", func(stateDB StateDB) interface{} {
+			return uint8(stateDB.GetState(GasTokenAddress, gasTokenDecimalsStorageKey).Big().Uint64())
+		}),
+		"totalSupply": createGasTokenGetter("totalSupply", func(stateDB StateDB) interface{} {
+			return GetGasTokenTotalSupply(stateDB)
+		}),
+		"balanceOf":    gasTokenBalanceOf,
+		"allowance":    gasTokenAllowance,
+		"transfer":     gasTokenTransfer,
+		"approve":      gasTokenApprove,
+		"transferFrom": gasTokenTransferFrom,
+		"mint":         gasTokenMint,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := GasTokenABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, newStatefulPrecompileFunction(method.Name, method.ID, method.IsConstant(), function))
+	}
+
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
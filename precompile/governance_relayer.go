@@ -0,0 +1,339 @@
+// (c) 2023 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// GovernanceCallType is the EIP-712 type of the governance calls signed by admins.
+	GovernanceCallType = "GovernanceCall(address precompileAddr,bytes input,uint256 nonce,uint256 deadline)"
+
+	// GovernanceRelayerDomainName and GovernanceRelayerDomainVersion identify the EIP-712 domain of
+	// governance calls, along with the chain ID and the address of the relayer.
+	GovernanceRelayerDomainName    = "Subnet-EVM Governance Relayer"
+	GovernanceRelayerDomainVersion = "1"
+
+	// governanceRelayerLogGasCost covers a log with four topics and a single word of data
+	// (LogGas + 4 * LogTopicGas + 32 * LogDataGas).
+	governanceRelayerLogGasCost uint64 = 375 + 4*375 + 32*8
+	// ecrecoverGasCost is the gas cost of the ecrecover precompile.
+	ecrecoverGasCost uint64 = 3_000
+	// governanceCallWordGasCost is the cost of hashing each word of the relayed input (Keccak256WordGas).
+	governanceCallWordGasCost uint64 = 6
+
+	GovernanceRelayerReadGasCost uint64 = readGasCostPerSlot                                                   // read a single slot
+	RelayGovernanceCallGasCost   uint64 = writeGasCostPerSlot + ecrecoverGasCost + governanceRelayerLogGasCost // write nonce + recover signer + log, plus the gas of the relayed call
+
+	// GovernanceRelayerRawABI contains the raw ABI of GovernanceRelayer contract.
+	GovernanceRelayerRawABI = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"signer\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"precompileAddr\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"relayer\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"nonce\",\"type\":\"uint256\"}],\"name\":\"GovernanceCallRelayed\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"domainSeparator\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"separator\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"signer\",\"type\":\"address\"}],\"name\":\"nonces\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"nonce\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"signer\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"precompileAddr\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"input\",\"type\":\"bytes\"},{\"internalType\":\"uint256\",\"name\":\"deadline\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"signature\",\"type\":\"bytes\"}],\"name\":\"relay\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"output\",\"type\":\"bytes\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &GovernanceRelayerConfig{}
+
+	ErrGovernanceCallExpired          = errors.New("governance call expired")
+	ErrInvalidGovernanceCallSignature = errors.New("invalid governance call signature")
+	ErrGovernanceCallNotAllowed       = errors.New("call is not a governance operation that can be relayed")
+
+	GovernanceRelayerABI        abi.ABI                     // will be initialized by init function
+	GovernanceRelayerPrecompile StatefulPrecompiledContract // will be initialized by init function
+
+	// governanceRelayerNoncePrefix is hashed with the address of a signer to derive the storage key of its nonce.
+	governanceRelayerNoncePrefix = []byte("governanceRelayerNonce")
+
+	// allowListGovernanceSelectors are the role changes of the precompiles with an allow list.
+	allowListGovernanceSelectors = [][]byte{setAdminSignature, setEnabledSignature, setNoneSignature}
+
+	// governanceSelectors lists the admin operations that can be relayed, keyed by the address of their
+	// precompile. Any other call is refused, since the signer's permissions go beyond administering
+	// precompiles (e.g. minting native coins or moving assets with NativeAssetCall).
+	governanceSelectors = map[common.Address][][]byte{
+		ContractDeployerAllowListAddress: allowListGovernanceSelectors,
+		ContractNativeMinterAddress:      allowListGovernanceSelectors,
+		TxAllowListAddress:               allowListGovernanceSelectors,
+		FeeConfigManagerAddress:          append([][]byte{setFeeConfigSignature}, allowListGovernanceSelectors...),
+		RewardManagerAddress: append([][]byte{
+			CalculateFunctionSelector("allowFeeRecipients()"),
+			CalculateFunctionSelector("disableRewards()"),
+			CalculateFunctionSelector("setRewardAddress(address)"),
+		}, allowListGovernanceSelectors...),
+		AddressBlocklistAddress: allowListGovernanceSelectors,
+		GasSponsorAddress:       allowListGovernanceSelectors,
+		GasTokenAddress:         allowListGovernanceSelectors,
+	}
+)
+
+// isGovernanceCall returns true if [input] calls one of the admin operations of the precompile at
+// [precompileAddr] that can be relayed.
+func isGovernanceCall(precompileAddr common.Address, input []byte) bool {
+	if len(input) < selectorLen {
+		return false
+	}
+	for _, selector := range governanceSelectors[precompileAddr] {
+		if bytes.Equal(selector, input[:selectorLen]) {
+			return true
+		}
+	}
+	return false
+}
+
+// GovernanceRelayerConfig implements the StatefulPrecompileConfig interface for a precompile that
+// executes the admin operations of other precompiles (e.g. setFeeConfig or role changes) on behalf
+// of admins that authorized them with EIP-712 signatures. Anyone can relay a signed call and pay for
+// its gas, so that admins whose keys are kept in cold storage never broadcast transactions.
+type GovernanceRelayerConfig struct {
+	UpgradeableConfig
+}
+
+// GovernanceRelayerConfigKey is the JSON key of the GovernanceRelayer config in the chain config and precompile upgrades.
+const GovernanceRelayerConfigKey = "governanceRelayerConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(GovernanceRelayerRawABI))
+	if err != nil {
+		panic(err)
+	}
+	GovernanceRelayerABI = parsed
+	GovernanceRelayerPrecompile = createGovernanceRelayerPrecompile()
+
+	RegisterModule(Module{
+		ConfigKey: GovernanceRelayerConfigKey,
+		Address:   GovernanceRelayerAddress,
+		Contract:  GovernanceRelayerPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(GovernanceRelayerConfig) },
+		ABI:       &GovernanceRelayerABI,
+		RawABI:    GovernanceRelayerRawABI,
+		GasCosts: map[string]uint64{
+			"domainSeparator": GovernanceRelayerReadGasCost,
+			"nonces":          GovernanceRelayerReadGasCost,
+			"relay":           RelayGovernanceCallGasCost,
+		},
+		StorageSlot: governanceRelayerStorageSlot,
+	})
+}
+
+// NewGovernanceRelayerConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// GovernanceRelayer.
+func NewGovernanceRelayerConfig(blockTimestamp *big.Int) *GovernanceRelayerConfig {
+	return &GovernanceRelayerConfig{
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableGovernanceRelayerConfig returns config for a network upgrade at [blockTimestamp]
+// that disables GovernanceRelayer.
+func NewDisableGovernanceRelayerConfig(blockTimestamp *big.Int) *GovernanceRelayerConfig {
+	return &GovernanceRelayerConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the governance relayer.
+func (c *GovernanceRelayerConfig) Address() common.Address {
+	return GovernanceRelayerAddress
+}
+
+// Configure is a no-op since the nonces of the signers start at zero.
+func (c *GovernanceRelayerConfig) Configure(_ ChainConfig, _ StateDB, _ BlockContext) {}
+
+// Contract returns the singleton stateful precompiled contract to be used for the governance relayer.
+func (c *GovernanceRelayerConfig) Contract() StatefulPrecompiledContract {
+	return GovernanceRelayerPrecompile
+}
+
+// Verify always returns nil since the governance relayer has no parameters.
+func (c *GovernanceRelayerConfig) Verify() error { return nil }
+
+// Equal returns true if [s] is a [*GovernanceRelayerConfig] and it has been configured identical to [c].
+func (c *GovernanceRelayerConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*GovernanceRelayerConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig)
+}
+
+// String returns a string representation of the GovernanceRelayerConfig.
+func (c *GovernanceRelayerConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// governanceRelayerStorageSlot resolves the "nonces[<address>]" storage slots.
+func governanceRelayerStorageSlot(name string) (common.Hash, bool) {
+	field, key, ok := parseIndexedStorageSlot(name)
+	if !ok || field != "nonces" {
+		return common.Hash{}, false
+	}
+	if address, ok := parseStorageSlotAddress(key); ok {
+		return governanceRelayerNonceKey(address), true
+	}
+	return common.Hash{}, false
+}
+
+// governanceRelayerNonceKey returns the storage key holding the nonce of [signer].
+func governanceRelayerNonceKey(signer common.Address) common.Hash {
+	return crypto.Keccak256Hash(governanceRelayerNoncePrefix, signer.Bytes())
+}
+
+// GetGovernanceRelayerNonce returns the nonce of the next governance call signed by [signer].
+func GetGovernanceRelayerNonce(stateDB StateDB, signer common.Address) *big.Int {
+	return stateDB.GetState(GovernanceRelayerAddress, governanceRelayerNonceKey(signer)).Big()
+}
+
+// GovernanceRelayerDomain returns the EIP-712 domain of the governance calls of the chain with [chainID].
+func GovernanceRelayerDomain(chainID *big.Int) abi.TypedDataDomain {
+	return abi.TypedDataDomain{
+		Name:              GovernanceRelayerDomainName,
+		Version:           GovernanceRelayerDomainVersion,
+		ChainID:           chainID,
+		VerifyingContract: GovernanceRelayerAddress,
+	}
+}
+
+// HashGovernanceCall returns the EIP-712 struct hash of the call of the precompile at [precompileAddr] with
+// [input], which is the [nonce]th call signed by its signer and is valid until the block timestamp [deadline].
+func HashGovernanceCall(precompileAddr common.Address, input []byte, nonce *big.Int, deadline *big.Int) (common.Hash, error) {
+	return abi.HashTypedDataStruct(GovernanceCallType, precompileAddr, input, nonce, deadline)
+}
+
+// SignGovernanceCall signs the governance call described by [HashGovernanceCall] for the chain with [chainID]
+// with [key], which must be allowed to call the precompile at [precompileAddr] with [input] directly.
+// The signature can be relayed by anyone with [PackRelayGovernanceCall].
+func SignGovernanceCall(key *ecdsa.PrivateKey, chainID *big.Int, precompileAddr common.Address, input []byte, nonce *big.Int, deadline *big.Int) ([]byte, error) {
+	structHash, err := HashGovernanceCall(precompileAddr, input, nonce, deadline)
+	if err != nil {
+		return nil, err
+	}
+	return abi.SignTypedData(key, GovernanceRelayerDomain(chainID), structHash)
+}
+
+// PackRelayGovernanceCall packs [signer], [precompileAddr], [input], [deadline] and [signature] into the
+// appropriate arguments for relay.
+// the packed bytes include selector (first 4 func signature bytes).
+func PackRelayGovernanceCall(signer common.Address, precompileAddr common.Address, input []byte, deadline *big.Int, signature []byte) ([]byte, error) {
+	return GovernanceRelayerABI.Pack("relay", signer, precompileAddr, input, deadline, signature)
+}
+
+// PackGovernanceRelayerNonces packs [signer] into the appropriate arguments for nonces.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGovernanceRelayerNonces(signer common.Address) ([]byte, error) {
+	return GovernanceRelayerABI.Pack("nonces", signer)
+}
+
+// relayGovernanceCallGas returns the gas required to relay [input], which is charged on top of the gas of the
+// relayed call.
+func relayGovernanceCallGas(_ PrecompileAccessibleState, _ common.Address, input []byte) uint64 {
+	words := (uint64(len(input)) + common.HashLength - 1) / common.HashLength
+	return RelayGovernanceCallGasCost + words*governanceCallWordGasCost
+}
+
+// relayGovernanceCall verifies that the governance call is an admin operation that can be relayed and that it
+// was signed by the signer before its deadline with its current nonce, then executes the call on behalf of the
+// signer, whose permissions are checked by the called precompile. The nonce of the signer is only incremented if the call succeeds, so that a failed call
+// can be relayed again until its deadline.
+func relayGovernanceCall(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	res, err := GovernanceRelayerABI.UnpackInput("relay", input)
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	signer := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	precompileAddr := *abi.ConvertType(res[1], new(common.Address)).(*common.Address)
+	callInput := *abi.ConvertType(res[2], new([]byte)).(*[]byte)
+	deadline := *abi.ConvertType(res[3], new(*big.Int)).(**big.Int)
+	signature := *abi.ConvertType(res[4], new([]byte)).(*[]byte)
+
+	if !isGovernanceCall(precompileAddr, callInput) {
+		return nil, suppliedGas, fmt.Errorf("%w: %s", ErrGovernanceCallNotAllowed, precompileAddr)
+	}
+	if timestamp := accessibleState.GetBlockContext().Timestamp(); timestamp.Cmp(deadline) > 0 {
+		return nil, suppliedGas, fmt.Errorf("%w: deadline %d < block timestamp %d", ErrGovernanceCallExpired, deadline, timestamp)
+	}
+	stateDB := accessibleState.GetStateDB()
+	nonce := GetGovernanceRelayerNonce(stateDB, signer)
+	structHash, err := HashGovernanceCall(precompileAddr, callInput, nonce, deadline)
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	recovered, err := abi.RecoverTypedDataSigner(GovernanceRelayerDomain(accessibleState.GetChainConfig().GetChainID()), structHash, signature)
+	if err != nil {
+		return nil, suppliedGas, fmt.Errorf("%w: %v", ErrInvalidGovernanceCallSignature, err)
+	}
+	if recovered != signer {
+		return nil, suppliedGas, fmt.Errorf("%w: signed by %s, not %s with nonce %d", ErrInvalidGovernanceCallSignature, recovered, signer, nonce)
+	}
+	stateDB.SetState(GovernanceRelayerAddress, governanceRelayerNonceKey(signer), common.BigToHash(new(big.Int).Add(nonce, common.Big1)))
+
+	output, remainingGas, err := accessibleState.CallPrecompile(signer, precompileAddr, callInput, suppliedGas, readOnly)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	topics, data, err := GovernanceRelayerABI.PackEvent("GovernanceCallRelayed", signer, precompileAddr, caller, nonce)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	stateDB.AddLog(GovernanceRelayerAddress, topics, data, accessibleState.GetBlockContext().Number().Uint64())
+
+	packedOutput, err := GovernanceRelayerABI.PackOutput("relay", output)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// governanceRelayerNonces returns the nonce of the next governance call signed by the given signer.
+func governanceRelayerNonces(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	res, err := GovernanceRelayerABI.UnpackInput("nonces", input)
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	signer := *abi.ConvertType(res[0], new(common.Address)).(*common.Address)
+	packedOutput, err := GovernanceRelayerABI.PackOutput("nonces", GetGovernanceRelayerNonce(accessibleState.GetStateDB(), signer))
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	// Return the packed output and the remaining gas
+	return packedOutput, suppliedGas, nil
+}
+
+// governanceRelayerDomainSeparator returns the EIP-712 domain separator of the governance calls.
+func governanceRelayerDomainSeparator(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	separator := GovernanceRelayerDomain(accessibleState.GetChainConfig().GetChainID()).Separator()
+	packedOutput, err := GovernanceRelayerABI.PackOutput("domainSeparator", separator)
+	if err != nil {
+		return nil, suppliedGas, err
+	}
+	// Return the packed output and the remaining gas
+	return packedOutput, suppliedGas, nil
+}
+
+// createGovernanceRelayerPrecompile returns a StatefulPrecompiledContract that relays the governance
+// calls signed by admins.
+func createGovernanceRelayerPrecompile() StatefulPrecompiledContract {
+	functions := []*statefulPrecompileFunction{
+		newStatefulPrecompileFunctionWithGas("relay", GovernanceRelayerABI.Methods["relay"].ID, false, relayGovernanceCallGas, relayGovernanceCall),
+		newStatefulPrecompileFunctionWithGas("nonces", GovernanceRelayerABI.Methods["nonces"].ID, true, fixedGas(GovernanceRelayerReadGasCost), governanceRelayerNonces),
+		newStatefulPrecompileFunctionWithGas("domainSeparator", GovernanceRelayerABI.Methods["domainSeparator"].ID, true, fixedGas(GovernanceRelayerReadGasCost), governanceRelayerDomainSeparator),
+	}
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
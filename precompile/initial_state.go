@@ -0,0 +1,82 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxEventTopics is the maximum number of topics of an event, as for the LOG0-LOG4 opcodes.
+const maxEventTopics = 4
+
+var (
+	ErrInitialStateOnDisable     = errors.New("cannot seed initial state when disabling a precompile")
+	ErrReservedInitialStorage    = errors.New("initial storage cannot set the storage version slot")
+	ErrTooManyInitialEventTopics = fmt.Errorf("initial event cannot have more than %d topics", maxEventTopics)
+)
+
+// InitialEvent is an event emitted by a precompile at its address when it is configured.
+type InitialEvent struct {
+	Topics []common.Hash `json:"topics,omitempty"`
+	Data   hexutil.Bytes `json:"data,omitempty"`
+}
+
+// Equal returns true if [other] has the same topics and data.
+func (e *InitialEvent) Equal(other *InitialEvent) bool {
+	if len(e.Topics) != len(other.Topics) {
+		return false
+	}
+	for i, topic := range e.Topics {
+		if topic != other.Topics[i] {
+			return false
+		}
+	}
+	return bytes.Equal(e.Data, other.Data)
+}
+
+// VerifyInitialState returns an error if the initial storage or events of [config] cannot be applied
+// when the precompile is configured.
+func VerifyInitialState(config StatefulPrecompileConfig) error {
+	storage, events := config.GetInitialStorage(), config.GetInitialEvents()
+	if config.IsDisabled() && (len(storage) != 0 || len(events) != 0) {
+		return ErrInitialStateOnDisable
+	}
+	if _, ok := storage[storageVersionKey]; ok {
+		return ErrReservedInitialStorage
+	}
+	for i, event := range events {
+		if len(event.Topics) > maxEventTopics {
+			return fmt.Errorf("initial event %d: %w", i, ErrTooManyInitialEventTopics)
+		}
+	}
+	return nil
+}
+
+// applyInitialState writes the initial storage of [precompileConfig] to the state of its address, in order of
+// storage key, then emits its initial events in the order they are listed, so that every validator applies
+// the same state modifications.
+// Note: since precompiles are configured outside of any transaction, the initial events are not included
+// in the receipts of the block.
+func applyInitialState(precompileConfig StatefulPrecompileConfig, state StateDB, blockContext BlockContext) {
+	address := precompileConfig.Address()
+	storage := precompileConfig.GetInitialStorage()
+	keys := make([]common.Hash, 0, len(storage))
+	for key := range storage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	for _, key := range keys {
+		state.SetState(address, key, storage[key])
+	}
+
+	for _, event := range precompileConfig.GetInitialEvents() {
+		state.AddLog(address, event.Topics, event.Data, blockContext.Number().Uint64())
+	}
+}
@@ -0,0 +1,346 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The native asset precompiles are ported from coreth and keep the same addresses, packed input
+// encoding and gas costs so that contracts written against the C-Chain can be reused on subnets.
+// Since subnet-evm accounts do not carry multi-coin balances, balances of native assets are kept
+// in a ledger within the address space of the native asset call precompile.
+const (
+	nativeAssetBalanceInputLen = common.AddressLength + common.HashLength
+	nativeAssetCallMinInputLen = common.AddressLength + common.HashLength + common.HashLength
+
+	NativeAssetBalanceGasCost uint64 = 2_474                         // matches AssetBalanceApricot in coreth
+	NativeAssetCallGasCost    uint64 = 9_000 + 2*writeGasCostPerSlot // matches AssetCallApricot in coreth + write sender and recipient balance
+	NativeAssetCallNewAccount uint64 = 25_000                        // matches CallNewAccountGas
+)
+
+var (
+	_ StatefulPrecompileConfig = &NativeAssetBalanceConfig{}
+	_ StatefulPrecompileConfig = &NativeAssetCallConfig{}
+
+	// Singleton StatefulPrecompiledContracts for reading and transferring native assets.
+	NativeAssetBalancePrecompile StatefulPrecompiledContract = &nativeAssetBalance{}
+	NativeAssetCallPrecompile    StatefulPrecompiledContract = &nativeAssetCall{}
+)
+
+// JSON keys of the native asset configs in the chain config and precompile upgrades.
+const (
+	NativeAssetBalanceConfigKey = "nativeAssetBalanceConfig"
+	NativeAssetCallConfigKey    = "nativeAssetCallConfig"
+)
+
+func init() {
+	RegisterModule(Module{
+		ConfigKey: NativeAssetBalanceConfigKey,
+		Address:   NativeAssetBalanceAddress,
+		Contract:  NativeAssetBalancePrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(NativeAssetBalanceConfig) },
+		GasCosts:  map[string]uint64{"fallback": NativeAssetBalanceGasCost},
+	})
+	RegisterModule(Module{
+		ConfigKey: NativeAssetCallConfigKey,
+		Address:   NativeAssetCallAddress,
+		Contract:  NativeAssetCallPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(NativeAssetCallConfig) },
+		GasCosts:  map[string]uint64{"fallback": NativeAssetCallGasCost},
+	})
+}
+
+// NativeAssetBalanceConfig implements the StatefulPrecompileConfig interface for the
+// nativeAssetBalance precompile.
+type NativeAssetBalanceConfig struct {
+	UpgradeableConfig
+}
+
+// NewNativeAssetBalanceConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// NativeAssetBalance.
+func NewNativeAssetBalanceConfig(blockTimestamp *big.Int) *NativeAssetBalanceConfig {
+	return &NativeAssetBalanceConfig{
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableNativeAssetBalanceConfig returns config for a network upgrade at [blockTimestamp]
+// that disables NativeAssetBalance.
+func NewDisableNativeAssetBalanceConfig(blockTimestamp *big.Int) *NativeAssetBalanceConfig {
+	return &NativeAssetBalanceConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the native asset balance precompile.
+func (c *NativeAssetBalanceConfig) Address() common.Address {
+	return NativeAssetBalanceAddress
+}
+
+// Configure is a no-op since balances are kept by the native asset call precompile.
+func (c *NativeAssetBalanceConfig) Configure(_ ChainConfig, _ StateDB, _ BlockContext) {}
+
+// Contract returns the singleton stateful precompiled contract to be used for the native asset balance.
+func (c *NativeAssetBalanceConfig) Contract() StatefulPrecompiledContract {
+	return NativeAssetBalancePrecompile
+}
+
+// Verify always returns nil since NativeAssetBalance has no parameters.
+func (c *NativeAssetBalanceConfig) Verify() error { return nil }
+
+// Equal returns true if [s] is a [*NativeAssetBalanceConfig] and it has been configured identical to [c].
+func (c *NativeAssetBalanceConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*NativeAssetBalanceConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig)
+}
+
+// String returns a string representation of the NativeAssetBalanceConfig.
+func (c *NativeAssetBalanceConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// NativeAssetCallConfig implements the StatefulPrecompileConfig interface for the nativeAssetCall
+// precompile. [InitialAssetBalances] is credited to the ledger when the upgrade activates.
+type NativeAssetCallConfig struct {
+	UpgradeableConfig
+	InitialAssetBalances map[common.Address]map[common.Hash]*math.HexOrDecimal256 `json:"initialAssetBalances,omitempty"` // holder => assetID => amount
+}
+
+// NewNativeAssetCallConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// NativeAssetCall and credits [initialAssetBalances] when the upgrade activates.
+func NewNativeAssetCallConfig(blockTimestamp *big.Int, initialAssetBalances map[common.Address]map[common.Hash]*math.HexOrDecimal256) *NativeAssetCallConfig {
+	return &NativeAssetCallConfig{
+		UpgradeableConfig:    UpgradeableConfig{BlockTimestamp: blockTimestamp},
+		InitialAssetBalances: initialAssetBalances,
+	}
+}
+
+// NewDisableNativeAssetCallConfig returns config for a network upgrade at [blockTimestamp]
+// that disables NativeAssetCall.
+// Note: disabling the precompile resets its storage, which clears all native asset balances.
+func NewDisableNativeAssetCallConfig(blockTimestamp *big.Int) *NativeAssetCallConfig {
+	return &NativeAssetCallConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the native asset call precompile.
+func (c *NativeAssetCallConfig) Address() common.Address {
+	return NativeAssetCallAddress
+}
+
+// Configure credits the initial asset balances in [c].
+func (c *NativeAssetCallConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	for holder, balances := range c.InitialAssetBalances {
+		for assetID, amount := range balances {
+			if amount != nil {
+				AddBalanceMultiCoin(state, holder, assetID, (*big.Int)(amount))
+			}
+		}
+	}
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the native asset call.
+func (c *NativeAssetCallConfig) Contract() StatefulPrecompiledContract {
+	return NativeAssetCallPrecompile
+}
+
+// Verify ensures that all of the initial asset balances are non-nil positive values.
+func (c *NativeAssetCallConfig) Verify() error {
+	for holder, balances := range c.InitialAssetBalances {
+		for assetID, amount := range balances {
+			if amount == nil {
+				return fmt.Errorf("initial asset balances cannot contain nil amount for address %s and asset %s", holder, assetID)
+			}
+			if bigIntAmount := (*big.Int)(amount); bigIntAmount.Sign() < 1 {
+				return fmt.Errorf("initial asset balances cannot contain invalid amount %v for address %s and asset %s", bigIntAmount, holder, assetID)
+			}
+		}
+	}
+	return nil
+}
+
+// Equal returns true if [s] is a [*NativeAssetCallConfig] and it has been configured identical to [c].
+func (c *NativeAssetCallConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*NativeAssetCallConfig)
+	if !ok {
+		return false
+	}
+	if !c.UpgradeableConfig.Equal(&other.UpgradeableConfig) {
+		return false
+	}
+	if len(c.InitialAssetBalances) != len(other.InitialAssetBalances) {
+		return false
+	}
+	for holder, balances := range c.InitialAssetBalances {
+		otherBalances, ok := other.InitialAssetBalances[holder]
+		if !ok || len(balances) != len(otherBalances) {
+			return false
+		}
+		for assetID, amount := range balances {
+			otherAmount, ok := otherBalances[assetID]
+			if !ok || !utils.BigNumEqual((*big.Int)(amount), (*big.Int)(otherAmount)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns a string representation of the NativeAssetCallConfig.
+func (c *NativeAssetCallConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// multiCoinBalanceKey returns the ledger key holding the balance of [assetID] for [address].
+func multiCoinBalanceKey(address common.Address, assetID common.Hash) common.Hash {
+	return crypto.Keccak256Hash(address.Bytes(), assetID.Bytes())
+}
+
+// GetBalanceMultiCoin returns the balance of [assetID] held by [address].
+func GetBalanceMultiCoin(stateDB StateDB, address common.Address, assetID common.Hash) *big.Int {
+	return stateDB.GetState(NativeAssetCallAddress, multiCoinBalanceKey(address, assetID)).Big()
+}
+
+// AddBalanceMultiCoin adds [amount] of [assetID] to the balance of [address].
+// Assumes the resulting balance fits in 32 bytes.
+func AddBalanceMultiCoin(stateDB StateDB, address common.Address, assetID common.Hash, amount *big.Int) {
+	balance := new(big.Int).Add(GetBalanceMultiCoin(stateDB, address, assetID), amount)
+	stateDB.SetState(NativeAssetCallAddress, multiCoinBalanceKey(address, assetID), common.BigToHash(balance))
+}
+
+// SubBalanceMultiCoin subtracts [amount] of [assetID] from the balance of [address].
+// Assumes the balance of [address] is at least [amount].
+func SubBalanceMultiCoin(stateDB StateDB, address common.Address, assetID common.Hash, amount *big.Int) {
+	balance := new(big.Int).Sub(GetBalanceMultiCoin(stateDB, address, assetID), amount)
+	stateDB.SetState(NativeAssetCallAddress, multiCoinBalanceKey(address, assetID), common.BigToHash(balance))
+}
+
+// PackNativeAssetBalanceInput packs the arguments into the required input data for a transaction to be passed into
+// the native asset balance precompile.
+func PackNativeAssetBalanceInput(address common.Address, assetID common.Hash) []byte {
+	input := make([]byte, nativeAssetBalanceInputLen)
+	copy(input, address.Bytes())
+	copy(input[common.AddressLength:], assetID.Bytes())
+	return input
+}
+
+// UnpackNativeAssetBalanceInput attempts to unpack [input] into the arguments to the native asset balance precompile
+func UnpackNativeAssetBalanceInput(input []byte) (common.Address, common.Hash, error) {
+	if len(input) != nativeAssetBalanceInputLen {
+		return common.Address{}, common.Hash{}, fmt.Errorf("native asset balance input had unexpected length %d", len(input))
+	}
+	address := common.BytesToAddress(input[:common.AddressLength])
+	assetID := common.BytesToHash(input[common.AddressLength:])
+	return address, assetID, nil
+}
+
+// PackNativeAssetCallInput packs the arguments into the required input data for a transaction to be passed into
+// the native asset call precompile.
+// Assumes that [assetAmount] is non-nil.
+func PackNativeAssetCallInput(address common.Address, assetID common.Hash, assetAmount *big.Int, callData []byte) []byte {
+	input := make([]byte, nativeAssetCallMinInputLen+len(callData))
+	copy(input[0:20], address.Bytes())
+	copy(input[20:52], assetID.Bytes())
+	assetAmount.FillBytes(input[52:84])
+	copy(input[84:], callData)
+	return input
+}
+
+// UnpackNativeAssetCallInput attempts to unpack [input] into the arguments to the native asset call precompile
+func UnpackNativeAssetCallInput(input []byte) (common.Address, common.Hash, *big.Int, []byte, error) {
+	if len(input) < nativeAssetCallMinInputLen {
+		return common.Address{}, common.Hash{}, nil, nil, fmt.Errorf("native asset call input had unexpected length %d", len(input))
+	}
+	to := common.BytesToAddress(input[:20])
+	assetID := common.BytesToHash(input[20:52])
+	assetAmount := new(big.Int).SetBytes(input[52:84])
+	callData := input[84:]
+	return to, assetID, assetAmount, callData, nil
+}
+
+// nativeAssetBalance is a precompiled contract used to retrieve the native asset balance
+type nativeAssetBalance struct{}
+
+// Run implements StatefulPrecompiledContract
+func (b *nativeAssetBalance) Run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	// input: encodePacked(address 20 bytes, assetID 32 bytes)
+	if remainingGas, err = deductGas(suppliedGas, NativeAssetBalanceGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	address, assetID, err := UnpackNativeAssetBalanceInput(input)
+	if err != nil {
+		return nil, remainingGas, vmerrs.ErrExecutionReverted
+	}
+
+	balance := GetBalanceMultiCoin(accessibleState.GetStateDB(), address, assetID)
+	return common.BigToHash(balance).Bytes(), remainingGas, nil
+}
+
+// nativeAssetCall atomically transfers a native asset to a recipient address as well as calling that
+// address
+type nativeAssetCall struct{}
+
+// Run implements StatefulPrecompiledContract
+func (c *nativeAssetCall) Run(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	// input: encodePacked(address to 20 bytes, assetID 32 bytes, assetAmount 32 bytes, callData variable length bytes)
+	if remainingGas, err = deductGas(suppliedGas, NativeAssetCallGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	to, assetID, assetAmount, callData, err := UnpackNativeAssetCallInput(input)
+	if err != nil {
+		log.Debug("unpacking native asset call input failed", "err", err)
+		return nil, remainingGas, vmerrs.ErrExecutionReverted
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// Note: it is not possible for a negative assetAmount to be passed in here due to the fact that decoding a
+	// byte slice into a *big.Int type will always return a positive value.
+	if assetAmount.Sign() != 0 && GetBalanceMultiCoin(stateDB, caller, assetID).Cmp(assetAmount) < 0 {
+		return nil, remainingGas, vmerrs.ErrInsufficientBalance
+	}
+
+	if !stateDB.Exist(to) {
+		if remainingGas, err = deductGas(remainingGas, NativeAssetCallNewAccount); err != nil {
+			return nil, 0, err
+		}
+		stateDB.CreateAccount(to)
+	}
+
+	// Send [assetAmount] of [assetID] to [to] address.
+	// Note: if the call below fails, the state changes made here are reverted along
+	// with the call into this precompile.
+	SubBalanceMultiCoin(stateDB, caller, assetID, assetAmount)
+	AddBalanceMultiCoin(stateDB, to, assetID, assetAmount)
+	return accessibleState.CallFromPrecompile(caller, to, callData, remainingGas, new(big.Int))
+}
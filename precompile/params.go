@@ -4,8 +4,6 @@
 package precompile
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -27,24 +25,25 @@ const (
 // For forks of subnet-evm, users should start at 0x0300000000000000000000000000000000000000 to ensure
 // that their own modifications do not conflict with stateful precompiles that may be added to subnet-evm
 // in the future.
+// The addresses of new precompiles can be declared next to their implementation, since [RegisterModule]
+// verifies that every registered address is in a reserved range and unique.
 var (
+	NativeAssetBalanceAddress        = common.HexToAddress("0x0100000000000000000000000000000000000001")
+	NativeAssetCallAddress           = common.HexToAddress("0x0100000000000000000000000000000000000002")
 	ContractDeployerAllowListAddress = common.HexToAddress("0x0200000000000000000000000000000000000000")
 	ContractNativeMinterAddress      = common.HexToAddress("0x0200000000000000000000000000000000000001")
 	TxAllowListAddress               = common.HexToAddress("0x0200000000000000000000000000000000000002")
 	FeeConfigManagerAddress          = common.HexToAddress("0x0200000000000000000000000000000000000003")
 	RewardManagerAddress             = common.HexToAddress("0x0200000000000000000000000000000000000004")
-	// ADD YOUR PRECOMPILE HERE
-	// {YourPrecompile}Address       = common.HexToAddress("0x03000000000000000000000000000000000000??")
+	AddressBlocklistAddress          = common.HexToAddress("0x0200000000000000000000000000000000000005")
+	ChainConfigReaderAddress         = common.HexToAddress("0x0200000000000000000000000000000000000006")
+	GasSponsorAddress                = common.HexToAddress("0x0200000000000000000000000000000000000007")
+	ValidatorInfoAddress             = common.HexToAddress("0x0200000000000000000000000000000000000008")
+	GasTokenAddress                  = common.HexToAddress("0x0200000000000000000000000000000000000009")
+	GovernanceRelayerAddress         = common.HexToAddress("0x020000000000000000000000000000000000000a")
+	NativeTokenSourceAddress         = common.HexToAddress("0x020000000000000000000000000000000000000b")
+	NativeTokenDestinationAddress    = common.HexToAddress("0x020000000000000000000000000000000000000c")
 
-	UsedAddresses = []common.Address{
-		ContractDeployerAllowListAddress,
-		ContractNativeMinterAddress,
-		TxAllowListAddress,
-		FeeConfigManagerAddress,
-		RewardManagerAddress,
-		// ADD YOUR PRECOMPILE HERE
-		// YourPrecompileAddress
-	}
 	reservedRanges = []AddressRange{
 		{
 			common.HexToAddress("0x0100000000000000000000000000000000000000"),
@@ -61,7 +60,7 @@ var (
 	}
 )
 
-// UsedAddress returns true if [addr] is in a reserved range for custom precompiles
+// ReservedAddress returns true if [addr] is in a reserved range for custom precompiles
 func ReservedAddress(addr common.Address) bool {
 	for _, reservedRange := range reservedRanges {
 		if reservedRange.Contains(addr) {
@@ -71,12 +70,3 @@ func ReservedAddress(addr common.Address) bool {
 
 	return false
 }
-
-func init() {
-	// Ensure that every address used by a precompile is in a reserved range.
-	for _, addr := range UsedAddresses {
-		if !ReservedAddress(addr) {
-			panic(fmt.Errorf("address %s used for stateful precompile but not specified in any reserved range", addr))
-		}
-	}
-}
@@ -0,0 +1,72 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// PluginSymbol is the name of the function a Go plugin must export to provide precompiles.
+// The function must have the signature func() []precompile.Module, e.g.:
+//
+//	func PrecompileModules() []precompile.Module {
+//		return []precompile.Module{{ConfigKey: "myPrecompileConfig", Address: MyPrecompileAddress, ...}}
+//	}
+//
+// The plugin must be built with the same Go version and the same version of subnet-evm as the
+// VM loading it, using "go build -buildmode=plugin".
+const PluginSymbol = "PrecompileModules"
+
+var (
+	// loadedPlugins contains the absolute paths of the plugins whose modules have been registered,
+	// so that a plugin shared by multiple chains is registered only once.
+	loadedPlugins     = make(map[string]struct{})
+	loadedPluginsLock sync.Mutex
+)
+
+// LoadPlugins opens the Go plugins at [paths] and registers the modules they provide.
+// Plugins that have already been loaded are skipped.
+// The precompiles of a plugin are activated like any other precompile, by setting their config
+// at the module config key in the genesis or in a precompile upgrade. Every node of a network
+// activating such a precompile must load the same plugin.
+func LoadPlugins(paths []string) error {
+	loadedPluginsLock.Lock()
+	defer loadedPluginsLock.Unlock()
+
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("invalid precompile plugin path %q: %w", path, err)
+		}
+		if _, ok := loadedPlugins[absPath]; ok {
+			continue
+		}
+		symbol, err := lookupPluginSymbol(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to load precompile plugin %q: %w", path, err)
+		}
+		if err := registerPluginModules(symbol); err != nil {
+			return fmt.Errorf("failed to register precompile plugin %q: %w", path, err)
+		}
+		loadedPlugins[absPath] = struct{}{}
+	}
+	return nil
+}
+
+// registerPluginModules registers the modules returned by [symbol], which must be the
+// [PluginSymbol] function of a plugin.
+func registerPluginModules(symbol interface{}) error {
+	modulesFn, ok := symbol.(func() []Module)
+	if !ok {
+		return fmt.Errorf("symbol %s has type %T, expected func() []precompile.Module", PluginSymbol, symbol)
+	}
+	for _, module := range modulesFn() {
+		if err := registerModule(module); err != nil {
+			return err
+		}
+	}
+	return nil
+}
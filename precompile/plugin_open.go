@@ -0,0 +1,19 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build cgo && (linux || darwin || freebsd)
+// +build cgo
+// +build linux darwin freebsd
+
+package precompile
+
+import "plugin"
+
+// lookupPluginSymbol opens the Go plugin at [path] and returns its [PluginSymbol].
+func lookupPluginSymbol(path string) (interface{}, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Lookup(PluginSymbol)
+}
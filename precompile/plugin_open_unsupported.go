@@ -0,0 +1,16 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !cgo || !(linux || darwin || freebsd)
+// +build !cgo !linux,!darwin,!freebsd
+
+package precompile
+
+import "errors"
+
+var errPluginsUnsupported = errors.New("go plugins are not supported by this build, precompiles must be registered by the embedding binary instead")
+
+// lookupPluginSymbol returns an error since Go plugins require cgo on linux, darwin or freebsd.
+func lookupPluginSymbol(string) (interface{}, error) {
+	return nil, errPluginsUnsupported
+}
@@ -0,0 +1,37 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPluginsMissing(t *testing.T) {
+	require.NoError(t, LoadPlugins(nil))
+
+	path := filepath.Join(t.TempDir(), "missing.so")
+	err := LoadPlugins([]string{path})
+	require.ErrorContains(t, err, "failed to load precompile plugin")
+	require.NotContains(t, loadedPlugins, path)
+}
+
+func TestRegisterPluginModules(t *testing.T) {
+	err := registerPluginModules(func() Module { return Module{} })
+	require.ErrorContains(t, err, "expected func() []precompile.Module")
+
+	err = registerPluginModules(func() []Module {
+		return []Module{{
+			ConfigKey: "pluginTxAllowListConfig",
+			Address:   TxAllowListAddress,
+			Contract:  TxAllowListPrecompile,
+			NewConfig: func() StatefulPrecompileConfig { return new(TxAllowListConfig) },
+		}}
+	})
+	require.ErrorContains(t, err, "already used by "+TxAllowListConfigKey)
+
+	require.NoError(t, registerPluginModules(func() []Module { return nil }))
+}
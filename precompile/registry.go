@@ -0,0 +1,163 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Module is a stateful precompile that can be activated as a network upgrade.
+// Each precompile registers its module with [RegisterModule] from an init function. Precompiles maintained
+// outside of this repository can be registered by a binary embedding the VM in the same way, or loaded
+// from Go plugins with [LoadPlugins]. The chain config,
+// the JSON encoding of precompile upgrades and the EVM activation checks iterate the registered modules,
+// so that adding a precompile does not require changes outside of its own file.
+type Module struct {
+	// ConfigKey is the JSON key of the precompile config in the chain config and precompile upgrades.
+	ConfigKey string
+	// Address is the address where the precompile is accessible.
+	Address common.Address
+	// Contract is the thread-safe singleton enabled at [Address] while the precompile is active.
+	Contract StatefulPrecompiledContract
+	// NewConfig returns an empty config of the precompile, to decode a JSON config into.
+	NewConfig func() StatefulPrecompileConfig
+	// StorageVersion is the latest version of the storage layout supported by [Contract].
+	// Configs of the precompile cannot specify a greater storage version.
+	StorageVersion uint64
+	// ABI optionally declares the functions of [Contract], so that calls to the precompile can be
+	// decoded (e.g. by tracers). Points to the ABI parsed by the precompile's init function.
+	ABI *abi.ABI
+	// RawABI optionally holds the JSON that [ABI] is parsed from, so that clients of the precompile can be
+	// generated from the running binary. See [ExportModules].
+	RawABI string
+	// GasCosts optionally holds the gas charged by each function of [Contract] keyed by name, or by "fallback"
+	// for a contract that is called with raw input. Functions may charge more than their cost, e.g. for the
+	// events they emit once precompile events are enabled.
+	GasCosts map[string]uint64
+	// StorageSlot optionally resolves the friendly [name] of a storage slot of [Contract] (e.g. "feeConfig.gasLimit")
+	// to its storage key. Returns false if [name] is unknown. See [ResolveStorageSlot].
+	StorageSlot func(name string) (common.Hash, bool)
+	// StorageSlotName optionally names the storage [key] of [Contract], the inverse of [StorageSlot] for the
+	// slots whose key is not a hash. Returns false if [key] is unknown. See [StorageSlotName].
+	StorageSlotName func(key common.Hash) (string, bool)
+}
+
+// Describe returns the schema of the JSON encoding of the configs of the precompile, so that tooling can
+// generate forms and validation for them. Configs can customize their schema by implementing [utils.Describer].
+func (m Module) Describe() *utils.Schema {
+	return utils.Describe(m.NewConfig())
+}
+
+var (
+	// registeredModules contains the registered modules sorted by address, so that iterating them
+	// (e.g. to configure the precompiles activated by a block) is deterministic.
+	// The slice is replaced rather than modified on registration, so it can be read without
+	// holding [registeredModulesLock] once it has been retrieved.
+	registeredModules     []Module
+	registeredModulesLock sync.RWMutex
+)
+
+// RegisterModule registers [module] so that it can be activated as a network upgrade.
+// Panics if [module] is invalid or conflicts with an already registered module, since
+// modules are typically registered during init.
+func RegisterModule(module Module) {
+	if err := registerModule(module); err != nil {
+		panic(err)
+	}
+}
+
+func registerModule(module Module) error {
+	switch {
+	case module.ConfigKey == "":
+		return fmt.Errorf("precompile at %s has an empty config key", module.Address)
+	case module.Contract == nil:
+		return fmt.Errorf("precompile %s has a nil contract", module.ConfigKey)
+	case module.NewConfig == nil:
+		return fmt.Errorf("precompile %s has a nil config constructor", module.ConfigKey)
+	case !ReservedAddress(module.Address):
+		return fmt.Errorf("address %s used for stateful precompile %s but not specified in any reserved range", module.Address, module.ConfigKey)
+	}
+	if config := module.NewConfig(); config.Address() != module.Address {
+		return fmt.Errorf("config of precompile %s has address %s, expected %s", module.ConfigKey, config.Address(), module.Address)
+	}
+
+	registeredModulesLock.Lock()
+	defer registeredModulesLock.Unlock()
+
+	for _, registered := range registeredModules {
+		if registered.ConfigKey == module.ConfigKey {
+			return fmt.Errorf("precompile config key %s is already registered", module.ConfigKey)
+		}
+		if registered.Address == module.Address {
+			return fmt.Errorf("address %s of precompile %s is already used by %s", module.Address, module.ConfigKey, registered.ConfigKey)
+		}
+	}
+
+	modules := make([]Module, 0, len(registeredModules)+1)
+	modules = append(modules, registeredModules...)
+	modules = append(modules, module)
+	sort.SliceStable(modules, func(i, j int) bool {
+		return bytes.Compare(modules[i].Address[:], modules[j].Address[:]) < 0
+	})
+	registeredModules = modules
+	return nil
+}
+
+// RegisteredModules returns the registered modules sorted by address.
+// The returned slice must not be modified.
+func RegisteredModules() []Module {
+	registeredModulesLock.RLock()
+	defer registeredModulesLock.RUnlock()
+
+	return registeredModules
+}
+
+// GetRegisteredModule returns the module registered with [configKey].
+func GetRegisteredModule(configKey string) (Module, bool) {
+	for _, module := range RegisteredModules() {
+		if module.ConfigKey == configKey {
+			return module, true
+		}
+	}
+	return Module{}, false
+}
+
+// GetRegisteredModuleByAddress returns the module registered at [address].
+func GetRegisteredModuleByAddress(address common.Address) (Module, bool) {
+	for _, module := range RegisteredModules() {
+		if module.Address == address {
+			return module, true
+		}
+	}
+	return Module{}, false
+}
+
+// UnpackFunctionInput decodes [input] of a call to the precompile registered at [address] into the
+// name of the called function and its arguments keyed by name.
+// Returns an error if the precompile does not declare an ABI or [input] does not match it.
+func UnpackFunctionInput(address common.Address, input []byte) (string, map[string]interface{}, error) {
+	module, ok := GetRegisteredModuleByAddress(address)
+	if !ok || module.ABI == nil {
+		return "", nil, fmt.Errorf("no ABI registered for precompile at %s", address)
+	}
+	if len(input) < selectorLen {
+		return "", nil, fmt.Errorf("input of length %d is shorter than a function selector", len(input))
+	}
+	method, err := module.ABI.MethodById(input[:selectorLen])
+	if err != nil {
+		return "", nil, err
+	}
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, input[selectorLen:]); err != nil {
+		return "", nil, err
+	}
+	return method.Name, args, nil
+}
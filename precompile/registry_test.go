@@ -0,0 +1,217 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredModules(t *testing.T) {
+	modules := RegisteredModules()
+	require.NotEmpty(t, modules)
+	for i, module := range modules {
+		if i > 0 {
+			require.Negative(t, bytes.Compare(modules[i-1].Address.Bytes(), module.Address.Bytes()), "modules must be sorted by address")
+		}
+		byKey, ok := GetRegisteredModule(module.ConfigKey)
+		require.True(t, ok)
+		require.Equal(t, module.Address, byKey.Address)
+
+		byAddress, ok := GetRegisteredModuleByAddress(module.Address)
+		require.True(t, ok)
+		require.Equal(t, module.ConfigKey, byAddress.ConfigKey)
+	}
+
+	_, ok := GetRegisteredModule("unknownConfig")
+	require.False(t, ok)
+	_, ok = GetRegisteredModuleByAddress(common.Address{1})
+	require.False(t, ok)
+}
+
+func TestRegisterModuleInvalid(t *testing.T) {
+	newConfig := func() StatefulPrecompileConfig { return new(TxAllowListConfig) }
+	tests := map[string]struct {
+		module      Module
+		expectedErr string
+	}{
+		"empty config key": {
+			module:      Module{Address: TxAllowListAddress, Contract: TxAllowListPrecompile, NewConfig: newConfig},
+			expectedErr: "empty config key",
+		},
+		"nil contract": {
+			module:      Module{ConfigKey: "testConfig", Address: TxAllowListAddress, NewConfig: newConfig},
+			expectedErr: "nil contract",
+		},
+		"nil config constructor": {
+			module:      Module{ConfigKey: "testConfig", Address: TxAllowListAddress, Contract: TxAllowListPrecompile},
+			expectedErr: "nil config constructor",
+		},
+		"address not reserved": {
+			module:      Module{ConfigKey: "testConfig", Address: common.HexToAddress("0xff00000000000000000000000000000000000000"), Contract: TxAllowListPrecompile, NewConfig: newConfig},
+			expectedErr: "not specified in any reserved range",
+		},
+		"config address mismatch": {
+			module:      Module{ConfigKey: "testConfig", Address: common.HexToAddress("0x0300000000000000000000000000000000000090"), Contract: TxAllowListPrecompile, NewConfig: newConfig},
+			expectedErr: "expected 0x0300000000000000000000000000000000000090",
+		},
+		"duplicate config key": {
+			module:      Module{ConfigKey: TxAllowListConfigKey, Address: TxAllowListAddress, Contract: TxAllowListPrecompile, NewConfig: newConfig},
+			expectedErr: "already registered",
+		},
+		"duplicate address": {
+			module:      Module{ConfigKey: "testConfig", Address: TxAllowListAddress, Contract: TxAllowListPrecompile, NewConfig: newConfig},
+			expectedErr: "already used by " + TxAllowListConfigKey,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := registerModule(test.module)
+			require.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+	require.Panics(t, func() { RegisterModule(tests["duplicate address"].module) })
+}
+
+func TestRegisteredModuleABIs(t *testing.T) {
+	for _, module := range RegisteredModules() {
+		if module.ABI == nil {
+			continue
+		}
+		describer, ok := module.Contract.(FunctionDescriber)
+		if !ok {
+			continue
+		}
+		// Every function implemented by the contract must be declared by its ABI.
+		for _, function := range describer.Functions() {
+			method, err := module.ABI.MethodById(function.Selector)
+			require.NoError(t, err, "%s: %s", module.ConfigKey, function.Name)
+			require.Equal(t, function.Name, method.Name, module.ConfigKey)
+			require.True(t, bytes.Equal(function.Selector, method.ID), module.ConfigKey)
+		}
+	}
+}
+
+func TestUnpackFunctionInput(t *testing.T) {
+	addr := common.HexToAddress("0x0123")
+	feeConfig := commontype.FeeConfig{
+		GasLimit:                 big.NewInt(8_000_000),
+		TargetBlockRate:          2,
+		MinBaseFee:               big.NewInt(25_000_000_000),
+		TargetGas:                big.NewInt(15_000_000),
+		BaseFeeChangeDenominator: big.NewInt(36),
+		MinBlockGasCost:          big.NewInt(0),
+		MaxBlockGasCost:          big.NewInt(1_000_000),
+		BlockGasCostStep:         big.NewInt(200_000),
+	}
+
+	setFeeConfigInput, err := PackSetFeeConfig(feeConfig)
+	require.NoError(t, err)
+	name, args, err := UnpackFunctionInput(FeeConfigManagerAddress, setFeeConfigInput)
+	require.NoError(t, err)
+	require.Equal(t, "setFeeConfig", name)
+	require.Len(t, args, 8)
+	require.Equal(t, feeConfig.GasLimit, args["gasLimit"])
+	require.Equal(t, new(big.Int).SetUint64(feeConfig.TargetBlockRate), args["targetBlockRate"])
+	require.Equal(t, feeConfig.BlockGasCostStep, args["blockGasCostStep"])
+
+	mintInput, err := PackMintInput(addr, big.NewInt(100))
+	require.NoError(t, err)
+	name, args, err = UnpackFunctionInput(ContractNativeMinterAddress, mintInput)
+	require.NoError(t, err)
+	require.Equal(t, "mintNativeCoin", name)
+	require.Equal(t, map[string]interface{}{"addr": addr, "amount": big.NewInt(100)}, args)
+
+	setAdminInput, err := PackModifyAllowList(addr, AllowListAdmin)
+	require.NoError(t, err)
+	name, args, err = UnpackFunctionInput(TxAllowListAddress, setAdminInput)
+	require.NoError(t, err)
+	require.Equal(t, "setAdmin", name)
+	require.Equal(t, map[string]interface{}{"addr": addr}, args)
+
+	name, args, err = UnpackFunctionInput(FeeConfigManagerAddress, PackGetFeeConfigInput())
+	require.NoError(t, err)
+	require.Equal(t, "getFeeConfig", name)
+	require.Empty(t, args)
+
+	// Inputs that do not match the ABI and precompiles without an ABI cannot be decoded.
+	_, _, err = UnpackFunctionInput(FeeConfigManagerAddress, []byte{1, 2})
+	require.Error(t, err)
+	_, _, err = UnpackFunctionInput(FeeConfigManagerAddress, mintInput)
+	require.Error(t, err)
+	_, _, err = UnpackFunctionInput(NativeAssetCallAddress, mintInput)
+	require.Error(t, err)
+	_, _, err = UnpackFunctionInput(addr, mintInput)
+	require.Error(t, err)
+}
+
+func TestResolveStorageSlot(t *testing.T) {
+	addr := common.HexToAddress("0x0123")
+	tests := []struct {
+		address      common.Address
+		name         string
+		expectedSlot common.Hash
+		expectedErr  bool
+	}{
+		{address: FeeConfigManagerAddress, name: "feeConfig.gasLimit", expectedSlot: common.Hash{gasLimitKey}},
+		{address: FeeConfigManagerAddress, name: "feeConfig.blockGasCostStep", expectedSlot: common.Hash{blockGasCostStepKey}},
+		{address: FeeConfigManagerAddress, name: "feeConfigLastChangedAt", expectedSlot: feeConfigLastChangedAtKey},
+		{address: FeeConfigManagerAddress, name: "allowList[" + addr.Hex() + "]", expectedSlot: addr.Hash()},
+		{address: TxAllowListAddress, name: "allowList[" + addr.Hex() + "]", expectedSlot: addr.Hash()},
+		{address: RewardManagerAddress, name: "rewardAddress", expectedSlot: rewardAddressStorageKey},
+		{address: AddressBlocklistAddress, name: "blockedAddressCount", expectedSlot: blockedAddressCountStorageKey},
+		{address: AddressBlocklistAddress, name: "blockedAddressIndex[" + addr.Hex() + "]", expectedSlot: blockedAddressIndexKey(addr)},
+		{address: AddressBlocklistAddress, name: "blockedAddressEntry[3]", expectedSlot: blockedAddressEntryKey(3)},
+		{address: GovernanceRelayerAddress, name: "nonces[" + addr.Hex() + "]", expectedSlot: governanceRelayerNonceKey(addr)},
+		{address: FeeConfigManagerAddress, name: "feeConfig.unknown", expectedErr: true},
+		{address: FeeConfigManagerAddress, name: "allowList[0x01]", expectedErr: true},
+		{address: FeeConfigManagerAddress, name: "rewardAddress", expectedErr: true},
+		{address: AddressBlocklistAddress, name: "blockedAddressEntry[abc]", expectedErr: true},
+		{address: ChainConfigReaderAddress, name: "allowList[" + addr.Hex() + "]", expectedErr: true},
+		{address: addr, name: "feeConfig.gasLimit", expectedErr: true},
+	}
+	for _, test := range tests {
+		slot, err := ResolveStorageSlot(test.address, test.name)
+		if test.expectedErr {
+			require.Error(t, err, test.name)
+			continue
+		}
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.expectedSlot, slot, test.name)
+	}
+}
+
+func TestStorageSlotName(t *testing.T) {
+	addr := common.HexToAddress("0x0123")
+	tests := []struct {
+		address      common.Address
+		key          common.Hash
+		expectedName string
+		expectedOk   bool
+	}{
+		{address: FeeConfigManagerAddress, key: common.Hash{gasLimitKey}, expectedName: "feeConfig.gasLimit", expectedOk: true},
+		{address: FeeConfigManagerAddress, key: feeConfigLastChangedAtKey, expectedName: "feeConfigLastChangedAt", expectedOk: true},
+		{address: FeeConfigManagerAddress, key: addr.Hash(), expectedName: "allowList[" + addr.Hex() + "]", expectedOk: true},
+		{address: RewardManagerAddress, key: rewardAddressStorageKey, expectedName: "rewardAddress", expectedOk: true},
+		{address: AddressBlocklistAddress, key: blockedAddressCountStorageKey, expectedName: "blockedAddressCount", expectedOk: true},
+		{address: AddressBlocklistAddress, key: blockedAddressIndexKey(addr)},
+		{address: ChainConfigReaderAddress, key: addr.Hash()},
+		{address: addr, key: common.Hash{gasLimitKey}},
+	}
+	for _, test := range tests {
+		name, ok := StorageSlotName(test.address, test.key)
+		require.Equal(t, test.expectedOk, ok, test.expectedName)
+		require.Equal(t, test.expectedName, name)
+		if ok {
+			slot, err := ResolveStorageSlot(test.address, name)
+			require.NoError(t, err)
+			require.Equal(t, test.key, slot)
+		}
+	}
+}
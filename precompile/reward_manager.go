@@ -15,7 +15,6 @@ import (
 
 	"github.com/ava-labs/subnet-evm/accounts/abi"
 	"github.com/ava-labs/subnet-evm/constants"
-	"github.com/ava-labs/subnet-evm/vmerrs"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -97,6 +96,9 @@ type RewardManagerConfig struct {
 	InitialRewardConfig *InitialRewardConfig `json:"initialRewardConfig,omitempty"`
 }
 
+// RewardManagerConfigKey is the JSON key of the RewardManager config in the chain config and precompile upgrades.
+const RewardManagerConfigKey = "rewardManagerConfig"
+
 func init() {
 	parsed, err := abi.JSON(strings.NewReader(RewardManagerRawABI))
 	if err != nil {
@@ -104,6 +106,28 @@ func init() {
 	}
 	RewardManagerABI = parsed
 	RewardManagerPrecompile = createRewardManagerPrecompile(RewardManagerAddress)
+
+	RegisterModule(Module{
+		ConfigKey: RewardManagerConfigKey,
+		Address:   RewardManagerAddress,
+		Contract:  RewardManagerPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(RewardManagerConfig) },
+		ABI:       &RewardManagerABI,
+		RawABI:    RewardManagerRawABI,
+		GasCosts: allowListGasCosts(map[string]uint64{
+			"allowFeeRecipients":      AllowFeeRecipientsGasCost,
+			"areFeeRecipientsAllowed": AreFeeRecipientsAllowedGasCost,
+			"currentRewardAddress":    CurrentRewardAddressGasCost,
+			"disableRewards":          DisableRewardsGasCost,
+			"setRewardAddress":        SetRewardAddressGasCost,
+		}),
+		StorageSlot: func(name string) (common.Hash, bool) {
+			return rewardAddressStorageKey, name == "rewardAddress"
+		},
+		StorageSlotName: func(key common.Hash) (string, bool) {
+			return "rewardAddress", key == rewardAddressStorageKey
+		},
+	})
 }
 
 // NewRewardManagerConfig returns a config for a network upgrade at [blockTimestamp] that enables
@@ -226,9 +250,6 @@ func allowFeeRecipients(accessibleState PrecompileAccessibleState, caller common
 	if remainingGas, err = deductGas(suppliedGas, AllowFeeRecipientsGasCost); err != nil {
 		return nil, 0, err
 	}
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
 	// no input provided for this function
 
 	// Allow list is enabled and AllowFeeRecipients is a state-changer function.
@@ -332,9 +353,6 @@ func setRewardAddress(accessibleState PrecompileAccessibleState, caller common.A
 	if remainingGas, err = deductGas(suppliedGas, SetRewardAddressGasCost); err != nil {
 		return nil, 0, err
 	}
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
 	// attempts to unpack [input] into the arguments to the SetRewardAddressInput.
 	// Assumes that [input] does not include selector
 	// You can use unpacked [inputStruct] variable in your code
@@ -391,9 +409,6 @@ func disableRewards(accessibleState PrecompileAccessibleState, caller common.Add
 	if remainingGas, err = deductGas(suppliedGas, DisableRewardsGasCost); err != nil {
 		return nil, 0, err
 	}
-	if readOnly {
-		return nil, remainingGas, vmerrs.ErrWriteProtection
-	}
 	// no input provided for this function
 
 	// Allow list is enabled and DisableRewards is a state-changer function.
@@ -424,33 +439,33 @@ func createRewardManagerPrecompile(precompileAddr common.Address) StatefulPrecom
 	if !ok {
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(methodAllowFeeRecipients.ID, allowFeeRecipients))
+	functions = append(functions, newStatefulPrecompileFunction(methodAllowFeeRecipients.Name, methodAllowFeeRecipients.ID, methodAllowFeeRecipients.IsConstant(), allowFeeRecipients))
 
 	methodAreFeeRecipientsAllowed, ok := RewardManagerABI.Methods["areFeeRecipientsAllowed"]
 	if !ok {
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(methodAreFeeRecipientsAllowed.ID, areFeeRecipientsAllowed))
+	functions = append(functions, newStatefulPrecompileFunction(methodAreFeeRecipientsAllowed.Name, methodAreFeeRecipientsAllowed.ID, methodAreFeeRecipientsAllowed.IsConstant(), areFeeRecipientsAllowed))
 
 	methodCurrentRewardAddress, ok := RewardManagerABI.Methods["currentRewardAddress"]
 	if !ok {
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(methodCurrentRewardAddress.ID, currentRewardAddress))
+	functions = append(functions, newStatefulPrecompileFunction(methodCurrentRewardAddress.Name, methodCurrentRewardAddress.ID, methodCurrentRewardAddress.IsConstant(), currentRewardAddress))
 
 	methodDisableRewards, ok := RewardManagerABI.Methods["disableRewards"]
 	if !ok {
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(methodDisableRewards.ID, disableRewards))
+	functions = append(functions, newStatefulPrecompileFunction(methodDisableRewards.Name, methodDisableRewards.ID, methodDisableRewards.IsConstant(), disableRewards))
 
 	methodSetRewardAddress, ok := RewardManagerABI.Methods["setRewardAddress"]
 	if !ok {
 		panic("given method does not exist in the ABI")
 	}
-	functions = append(functions, newStatefulPrecompileFunction(methodSetRewardAddress.ID, setRewardAddress))
+	functions = append(functions, newStatefulPrecompileFunction(methodSetRewardAddress.Name, methodSetRewardAddress.ID, methodSetRewardAddress.IsConstant(), setRewardAddress))
 
 	// Construct the contract with no fallback function.
-	contract := newStatefulPrecompileWithFunctionSelectors(nil, functions)
+	contract := newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
 	return contract
 }
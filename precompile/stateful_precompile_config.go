@@ -4,12 +4,19 @@
 package precompile
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// storageVersionKey is the storage slot of a precompile address holding the version of its storage layout.
+// Precompile implementations must use a different key than [storageVersionKey] for their storage.
+var storageVersionKey = crypto.Keccak256Hash([]byte("subnet-evm.precompile.storageVersion"))
+
 // StatefulPrecompileConfig defines the interface for a stateful precompile to
 type StatefulPrecompileConfig interface {
 	// Address returns the address where the stateful precompile is accessible.
@@ -17,8 +24,14 @@ type StatefulPrecompileConfig interface {
 	// Timestamp returns the timestamp at which this stateful precompile should be enabled.
 	// 1) 0 indicates that the precompile should be enabled from genesis.
 	// 2) n indicates that the precompile should be enabled in the first block with timestamp >= [n].
-	// 3) nil indicates that the precompile is never enabled.
+	// 3) nil indicates that the precompile is not enabled by timestamp.
 	Timestamp() *big.Int
+	// Number returns the block number at which this stateful precompile should be enabled, as an alternative
+	// to [Timestamp] for chains that coordinate upgrades by block height rather than by time.
+	// 1) n indicates that the precompile should be enabled in the first block with number >= [n].
+	// 2) nil indicates that the precompile is not enabled by block number.
+	// If both [Timestamp] and [Number] are nil, the precompile is never enabled.
+	Number() *big.Int
 	// IsDisabled returns true if this network upgrade should disable the precompile.
 	IsDisabled() bool
 	// Equal returns true if the provided argument configures the same precompile with the same parameters.
@@ -38,12 +51,29 @@ type StatefulPrecompileConfig interface {
 	Contract() StatefulPrecompiledContract
 	// Verify is called on startup and an error is treated as fatal. Configure can assume the Config has passed verification.
 	Verify() error
+	// GetStorageVersion returns the version of the storage layout of the precompile from this upgrade on.
+	// Enabling an active precompile with a greater storage version migrates its storage with Migrate
+	// instead of configuring it.
+	GetStorageVersion() uint64
+	// Migrate is called on the block where the storage of the active precompile is upgraded from [fromVersion]
+	// to [toVersion]. It must convert the storage of the precompile to the layout of [toVersion] and, like
+	// Configure, must be deterministic and should only modify the state within its own address space.
+	Migrate(state StateDB, fromVersion uint64, toVersion uint64)
+	// Deconfigure is called on the block where the stateful precompile is disabled, before the state at its
+	// address is wiped. This allows the precompile to clean up or mark inert any state it maintains outside
+	// of its own address space. Like Configure, it must be deterministic.
+	Deconfigure(StateDB)
+	// GetInitialStorage returns the storage slots set at the address of the precompile after Configure,
+	// allowing the precompile state to be seeded from the config.
+	GetInitialStorage() map[common.Hash]common.Hash
+	// GetInitialEvents returns the events emitted at the address of the precompile after Configure.
+	GetInitialEvents() []InitialEvent
 
 	fmt.Stringer
 }
 
 // Configure sets the nonce and code to non-empty values then calls Configure on [precompileConfig] to make the necessary
-// state update to enable the StatefulPrecompile.
+// state update to enable the StatefulPrecompile, and finally seeds the initial state of [precompileConfig].
 // Assumes that [precompileConfig] is non-nil.
 func Configure(chainConfig ChainConfig, blockContext BlockContext, precompileConfig StatefulPrecompileConfig, state StateDB) {
 	// Set the nonce of the precompile's address (as is done when a contract is created) to ensure
@@ -53,5 +83,84 @@ func Configure(chainConfig ChainConfig, blockContext BlockContext, precompileCon
 	// can be called from within Solidity contracts. Solidity adds a check before invoking a contract to ensure
 	// that it does not attempt to invoke a non-existent contract.
 	state.SetCode(precompileConfig.Address(), []byte{0x1})
+	// Set the storage version first, so that Configure stores the state with the layout of that version.
+	if version := precompileConfig.GetStorageVersion(); version != 0 {
+		SetPrecompileStorageVersion(state, precompileConfig.Address(), version)
+	}
 	precompileConfig.Configure(chainConfig, state, blockContext)
+	applyInitialState(precompileConfig, state, blockContext)
+}
+
+// Migrate calls Migrate on [precompileConfig] to convert the storage of the active precompile from its
+// stored version to the storage version of [precompileConfig], and stores the new version.
+// Assumes that [precompileConfig] is non-nil.
+func Migrate(precompileConfig StatefulPrecompileConfig, state StateDB) {
+	address := precompileConfig.Address()
+	toVersion := precompileConfig.GetStorageVersion()
+	precompileConfig.Migrate(state, GetPrecompileStorageVersion(state, address), toVersion)
+	SetPrecompileStorageVersion(state, address, toVersion)
+}
+
+// upgradeFields are the JSON keys of the fields of [UpgradeableConfig] that do not configure the precompile
+// itself, and may differ between an upgrade migrating the storage of a precompile and its active config.
+var upgradeFields = []string{"blockTimestamp", "blockNumber", "storageVersion", "initialStorage", "initialEvents"}
+
+// SameSettings returns true if [a] and [b] configure the precompile with the same parameters, ignoring
+// their activation, storage version and initial state. An upgrade migrating the storage of an active
+// precompile must not change its parameters, since they are not applied when the storage is migrated.
+func SameSettings(a, b StatefulPrecompileConfig) bool {
+	aSettings, err := settings(a)
+	if err != nil {
+		return false
+	}
+	bSettings, err := settings(b)
+	if err != nil || len(aSettings) != len(bSettings) {
+		return false
+	}
+	for key, value := range aSettings {
+		if other, ok := bSettings[key]; !ok || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// settings returns the JSON encoding of the fields of [config], except for its [upgradeFields].
+func settings(config StatefulPrecompileConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range upgradeFields {
+		delete(fields, key)
+	}
+	return fields, nil
+}
+
+// Deconfigure calls Deconfigure on [precompileConfig] then wipes the state of the precompile's address,
+// to make the necessary state update to disable the StatefulPrecompile.
+// Assumes that [precompileConfig] is non-nil.
+func Deconfigure(precompileConfig StatefulPrecompileConfig, state StateDB) {
+	precompileConfig.Deconfigure(state)
+	state.Suicide(precompileConfig.Address())
+	// Calling Finalise here effectively commits Suicide call and wipes the contract state.
+	// This enables re-configuration of the same contract state in the same block.
+	// Without an immediate Finalise call after the Suicide, a reconfigured precompiled state can be wiped out
+	// since Suicide will be committed after the reconfiguration.
+	state.Finalise(true)
+}
+
+// GetPrecompileStorageVersion returns the version of the storage layout of the precompile at [address].
+// Precompiles that were never migrated have version 0.
+func GetPrecompileStorageVersion(state StateDB, address common.Address) uint64 {
+	return state.GetState(address, storageVersionKey).Big().Uint64()
+}
+
+// SetPrecompileStorageVersion sets the version of the storage layout of the precompile at [address] to [version].
+func SetPrecompileStorageVersion(state StateDB, address common.Address, version uint64) {
+	state.SetState(address, storageVersionKey, common.BigToHash(new(big.Int).SetUint64(version)))
 }
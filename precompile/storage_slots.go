@@ -0,0 +1,97 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// allowListStorageSlot is the name of the storage slots holding the allow list roles,
+// indexed by the address of the member (e.g. "allowList[0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC]").
+const allowListStorageSlot = "allowList"
+
+// ResolveStorageSlot returns the storage key of the precompile at [address] referred to by the friendly
+// [name] (e.g. "feeConfig.gasLimit"), so that light clients can request Merkle proofs of the state of
+// the precompile with eth_getProof. Slots indexed by a key are named "<name>[<key>]".
+// The allow list slots are resolved for every precompile declaring readAllowList in its ABI,
+// other slots are resolved by the [Module.StorageSlot] of the precompile.
+func ResolveStorageSlot(address common.Address, name string) (common.Hash, error) {
+	module, ok := GetRegisteredModuleByAddress(address)
+	if !ok {
+		return common.Hash{}, fmt.Errorf("no precompile registered at %s", address)
+	}
+	if field, key, ok := parseIndexedStorageSlot(name); ok && field == allowListStorageSlot && module.ABI != nil {
+		if _, ok := module.ABI.Methods[ReadAllowListFuncKey]; ok {
+			if addr, ok := parseStorageSlotAddress(key); ok {
+				return addr.Hash(), nil
+			}
+		}
+	}
+	if module.StorageSlot != nil {
+		if slot, ok := module.StorageSlot(name); ok {
+			return slot, nil
+		}
+	}
+	return common.Hash{}, fmt.Errorf("unknown storage slot %q of precompile %s", name, module.ConfigKey)
+}
+
+// StorageSlotName returns the friendly name of the storage [key] of the precompile at [address], the inverse
+// of [ResolveStorageSlot], so that tracers can annotate changes to the state of the precompile.
+// Returns false if [key] cannot be named, as is the case for slots keyed by a hash (e.g. "blockedAddressIndex[<address>]").
+func StorageSlotName(address common.Address, key common.Hash) (string, bool) {
+	module, ok := GetRegisteredModuleByAddress(address)
+	if !ok {
+		return "", false
+	}
+	if module.StorageSlotName != nil {
+		if name, ok := module.StorageSlotName(key); ok {
+			return name, true
+		}
+	}
+	if module.ABI != nil && isAddressStorageKey(key) {
+		if _, ok := module.ABI.Methods[ReadAllowListFuncKey]; ok {
+			return fmt.Sprintf("%s[%s]", allowListStorageSlot, common.BytesToAddress(key.Bytes()).Hex()), true
+		}
+	}
+	return "", false
+}
+
+// isAddressStorageKey returns true if [key] is an address left-padded with zeroes, as are the keys
+// of the allow list slots.
+func isAddressStorageKey(key common.Hash) bool {
+	for _, b := range key[:common.HashLength-common.AddressLength] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseIndexedStorageSlot splits the storage slot [name] of the form "<field>[<key>]" into its
+// field and key. Returns false if [name] is not indexed.
+func parseIndexedStorageSlot(name string) (string, string, bool) {
+	open := strings.IndexByte(name, '[')
+	if open <= 0 || !strings.HasSuffix(name, "]") {
+		return "", "", false
+	}
+	return name[:open], name[open+1 : len(name)-1], true
+}
+
+// parseStorageSlotAddress parses the address [key] of an indexed storage slot.
+func parseStorageSlotAddress(key string) (common.Address, bool) {
+	if !common.IsHexAddress(key) {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(key), true
+}
+
+// parseStorageSlotIndex parses the numeric [key] of an indexed storage slot.
+func parseStorageSlotIndex(key string) (uint64, bool) {
+	index, err := strconv.ParseUint(key, 0, 64)
+	return index, err == nil
+}
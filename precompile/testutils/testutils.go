@@ -0,0 +1,144 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package testutils provides an in-memory environment to unit test stateful precompiles
+// without running them in the EVM.
+package testutils
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ precompile.BlockContext              = &BlockContext{}
+	_ precompile.PrecompileAccessibleState = &AccessibleState{}
+
+	// ErrCallNotSupported is returned by [AccessibleState.CallFromPrecompile] if no
+	// [AccessibleState.CallFromPrecompileFunc] is set.
+	ErrCallNotSupported = errors.New("calls from precompiles to contracts are not supported by the test harness")
+)
+
+// Addresses with each allow list role once [AccessibleState.SetUpAllowList] is called.
+var (
+	AdminAddr   = common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	EnabledAddr = common.HexToAddress("0xB2B1B5A6B4A1d8D1F1c7B8c7c1E0d5e6a1f1A2B3")
+	NoRoleAddr  = common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+)
+
+// BlockContext is a [precompile.BlockContext] with fixed header fields.
+type BlockContext struct {
+	BlockNumber   *big.Int
+	Time          uint64
+	BlockBaseFee  *big.Int
+	BlockCoinbase common.Address
+	BlockParent   common.Hash
+	BlockGasLimit uint64
+}
+
+func (b *BlockContext) Number() *big.Int         { return b.BlockNumber }
+func (b *BlockContext) Timestamp() *big.Int      { return new(big.Int).SetUint64(b.Time) }
+func (b *BlockContext) BaseFee() *big.Int        { return b.BlockBaseFee }
+func (b *BlockContext) Coinbase() common.Address { return b.BlockCoinbase }
+func (b *BlockContext) ParentHash() common.Hash  { return b.BlockParent }
+func (b *BlockContext) GasLimit() uint64         { return b.BlockGasLimit }
+
+// AccessibleState is a [precompile.PrecompileAccessibleState] backed by an in-memory [state.StateDB].
+// Its fields can be modified to set up the environment of the precompile under test.
+type AccessibleState struct {
+	StateDB      *state.StateDB
+	BlockContext *BlockContext
+	SnowContext  *snow.Context
+	ChainConfig  precompile.ChainConfig
+	// CallFromPrecompileFunc is called by CallFromPrecompile if set, to mock calls from the precompile to contracts.
+	CallFromPrecompileFunc func(caller common.Address, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error)
+}
+
+// NewAccessibleState returns an AccessibleState with an empty state at block 0 of [params.TestChainConfig].
+func NewAccessibleState(t testing.TB) *AccessibleState {
+	t.Helper()
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	return &AccessibleState{
+		StateDB:      statedb,
+		BlockContext: &BlockContext{BlockNumber: common.Big0},
+		SnowContext:  snow.DefaultContextTest(),
+		ChainConfig:  params.TestChainConfig,
+	}
+}
+
+func (s *AccessibleState) GetStateDB() precompile.StateDB           { return s.StateDB }
+func (s *AccessibleState) GetBlockContext() precompile.BlockContext { return s.BlockContext }
+func (s *AccessibleState) GetSnowContext() *snow.Context            { return s.SnowContext }
+func (s *AccessibleState) GetChainConfig() precompile.ChainConfig   { return s.ChainConfig }
+
+// CallFromPrecompile calls [CallFromPrecompileFunc], or returns [ErrCallNotSupported] if it is not set.
+func (s *AccessibleState) CallFromPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, leftOverGas uint64, err error) {
+	if s.CallFromPrecompileFunc == nil {
+		return nil, gas, ErrCallNotSupported
+	}
+	return s.CallFromPrecompileFunc(caller, addr, input, gas, value)
+}
+
+// CallPrecompile runs the precompile registered at [addr], regardless of whether it is enabled by [ChainConfig].
+// As in the EVM, state changes are reverted and the remaining gas is consumed if the callee returns an error,
+// unless the error is vmerrs.ErrExecutionReverted.
+func (s *AccessibleState) CallPrecompile(caller common.Address, addr common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	module, ok := precompile.GetRegisteredModuleByAddress(addr)
+	if !ok {
+		return nil, gas, precompile.ErrPrecompileNotEnabled
+	}
+	return s.run(module.Contract, caller, addr, input, gas, readOnly)
+}
+
+// Run runs [contract], which must be registered, as if it was called by [caller] with [input] and [gas].
+// Like [CallPrecompile], state changes are reverted if [contract] returns an error.
+func (s *AccessibleState) Run(t testing.TB, contract precompile.StatefulPrecompiledContract, caller common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	t.Helper()
+
+	for _, module := range precompile.RegisteredModules() {
+		if module.Contract == contract {
+			return s.run(contract, caller, module.Address, input, gas, readOnly)
+		}
+	}
+	require.FailNow(t, "contract is not registered by any precompile module")
+	return nil, 0, nil
+}
+
+func (s *AccessibleState) run(contract precompile.StatefulPrecompiledContract, caller common.Address, addr common.Address, input []byte, gas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	snapshot := s.StateDB.Snapshot()
+	ret, remainingGas, err = contract.Run(s, caller, addr, input, gas, readOnly)
+	if err != nil {
+		s.StateDB.RevertToSnapshot(snapshot)
+		if err != vmerrs.ErrExecutionReverted {
+			remainingGas = 0
+		}
+	}
+	return ret, remainingGas, err
+}
+
+// SetUpAllowList gives [AdminAddr] the admin role and [EnabledAddr] the enabled role in the allow list
+// of the precompile at [precompileAddr]. [NoRoleAddr] has no role.
+func (s *AccessibleState) SetUpAllowList(precompileAddr common.Address) {
+	precompile.SetAllowListRole(s.StateDB, precompileAddr, AdminAddr, precompile.AllowListAdmin)
+	precompile.SetAllowListRole(s.StateDB, precompileAddr, EnabledAddr, precompile.AllowListEnabled)
+	precompile.SetAllowListRole(s.StateDB, precompileAddr, NoRoleAddr, precompile.AllowListNoRole)
+}
+
+// RequireAllowListRole requires [address] to have [role] in the allow list of the precompile at [precompileAddr].
+func (s *AccessibleState) RequireAllowListRole(t testing.TB, precompileAddr common.Address, address common.Address, role precompile.AllowListRole) {
+	t.Helper()
+
+	require.Equal(t, role, precompile.GetAllowListRole(s.StateDB, precompileAddr, address), "allow list role of %s", address)
+}
@@ -0,0 +1,53 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testutils
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessibleStateRun(t *testing.T) {
+	state := NewAccessibleState(t)
+	state.SetUpAllowList(precompile.TxAllowListAddress)
+	state.RequireAllowListRole(t, precompile.TxAllowListAddress, AdminAddr, precompile.AllowListAdmin)
+	state.RequireAllowListRole(t, precompile.TxAllowListAddress, EnabledAddr, precompile.AllowListEnabled)
+	state.RequireAllowListRole(t, precompile.TxAllowListAddress, NoRoleAddr, precompile.AllowListNoRole)
+
+	input, err := precompile.PackModifyAllowList(NoRoleAddr, precompile.AllowListEnabled)
+	require.NoError(t, err)
+
+	// the caller must be an admin
+	_, remainingGas, err := state.Run(t, precompile.TxAllowListPrecompile, EnabledAddr, input, precompile.ModifyAllowListGasCost+1, false)
+	require.ErrorIs(t, err, precompile.ErrCannotModifyAllowList)
+	require.Zero(t, remainingGas)
+	state.RequireAllowListRole(t, precompile.TxAllowListAddress, NoRoleAddr, precompile.AllowListNoRole)
+
+	_, _, err = state.Run(t, precompile.TxAllowListPrecompile, AdminAddr, input, precompile.ModifyAllowListGasCost, true)
+	require.ErrorIs(t, err, vmerrs.ErrWriteProtection)
+
+	_, remainingGas, err = state.Run(t, precompile.TxAllowListPrecompile, AdminAddr, input, precompile.ModifyAllowListGasCost+1, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), remainingGas)
+	state.RequireAllowListRole(t, precompile.TxAllowListAddress, NoRoleAddr, precompile.AllowListEnabled)
+}
+
+func TestAccessibleStateCallPrecompile(t *testing.T) {
+	state := NewAccessibleState(t)
+	state.SetUpAllowList(precompile.ContractNativeMinterAddress)
+
+	role, _, err := precompile.CallReadAllowList(state, common.Address{}, precompile.ContractNativeMinterAddress, EnabledAddr, precompile.ReadAllowListGasCost)
+	require.NoError(t, err)
+	require.Equal(t, precompile.AllowListEnabled, role)
+
+	_, _, err = state.CallPrecompile(AdminAddr, common.HexToAddress("0x0300000000000000000000000000000000000100"), nil, 100, false)
+	require.ErrorIs(t, err, precompile.ErrPrecompileNotEnabled)
+
+	_, _, err = state.CallFromPrecompile(AdminAddr, EnabledAddr, nil, 100, common.Big0)
+	require.ErrorIs(t, err, ErrCallNotSupported)
+}
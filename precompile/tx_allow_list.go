@@ -19,6 +19,21 @@ var (
 	ErrSenderAddressNotAllowListed = errors.New("cannot issue transaction from non-allow listed address")
 )
 
+// TxAllowListConfigKey is the JSON key of the TxAllowList config in the chain config and precompile upgrades.
+const TxAllowListConfigKey = "txAllowListConfig"
+
+func init() {
+	RegisterModule(Module{
+		ConfigKey: TxAllowListConfigKey,
+		Address:   TxAllowListAddress,
+		Contract:  TxAllowListPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(TxAllowListConfig) },
+		ABI:       &AllowListABI,
+		RawABI:    AllowListRawABI,
+		GasCosts:  allowListGasCosts(nil),
+	})
+}
+
 // TxAllowListConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the TxAllowList specific precompile address.
 type TxAllowListConfig struct {
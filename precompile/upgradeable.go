@@ -7,14 +7,26 @@ import (
 	"math/big"
 
 	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-// UpgradeableConfig contains the timestamp for the upgrade along with
-// a boolean [Disable]. If [Disable] is set, the upgrade deactivates
+// UpgradeableConfig contains the timestamp or the block number for the upgrade
+// along with a boolean [Disable]. If [Disable] is set, the upgrade deactivates
 // the precompile and resets its storage.
+// Exactly one of [BlockTimestamp] and [BlockNumber] must be set for the upgrade
+// to be valid.
+// [StorageVersion] is the version of the storage layout of the precompile.
+// An upgrade with a greater [StorageVersion] than the active config of the
+// precompile migrates its storage.
+// [InitialStorage] and [InitialEvents] seed the state of the precompile when it
+// is configured, in addition to the state set by the precompile itself.
 type UpgradeableConfig struct {
-	BlockTimestamp *big.Int `json:"blockTimestamp"`
-	Disable        bool     `json:"disable,omitempty"`
+	BlockTimestamp *big.Int                    `json:"blockTimestamp,omitempty"`
+	BlockNumber    *big.Int                    `json:"blockNumber,omitempty"`
+	Disable        bool                        `json:"disable,omitempty"`
+	StorageVersion uint64                      `json:"storageVersion,omitempty"`
+	InitialStorage map[common.Hash]common.Hash `json:"initialStorage,omitempty"`
+	InitialEvents  []InitialEvent              `json:"initialEvents,omitempty"`
 }
 
 // Timestamp returns the timestamp this network upgrade goes into effect.
@@ -22,16 +34,62 @@ func (c *UpgradeableConfig) Timestamp() *big.Int {
 	return c.BlockTimestamp
 }
 
+// Number returns the block number this network upgrade goes into effect.
+func (c *UpgradeableConfig) Number() *big.Int {
+	return c.BlockNumber
+}
+
 // IsDisabled returns true if the network upgrade deactivates the precompile.
 func (c *UpgradeableConfig) IsDisabled() bool {
 	return c.Disable
 }
 
-// Equal returns true iff [other] has the same blockTimestamp and has the
-// same on value for the Disable flag.
+// GetStorageVersion returns the version of the storage layout of the precompile from this upgrade on.
+func (c *UpgradeableConfig) GetStorageVersion() uint64 {
+	return c.StorageVersion
+}
+
+// GetInitialStorage returns the storage slots set at the address of the precompile when it is configured.
+func (c *UpgradeableConfig) GetInitialStorage() map[common.Hash]common.Hash {
+	return c.InitialStorage
+}
+
+// GetInitialEvents returns the events emitted by the precompile when it is configured.
+func (c *UpgradeableConfig) GetInitialEvents() []InitialEvent {
+	return c.InitialEvents
+}
+
+// Migrate does nothing, since the storage of a precompile is unversioned by default.
+// Precompiles that support multiple storage versions must override it.
+func (c *UpgradeableConfig) Migrate(StateDB, uint64, uint64) {}
+
+// Deconfigure does nothing, since by default a precompile only maintains state within its
+// own address space, which is wiped when it is disabled.
+func (c *UpgradeableConfig) Deconfigure(StateDB) {}
+
+// Equal returns true iff [other] has the same blockTimestamp and blockNumber, has
+// the same on value for the Disable flag and the storage version and seeds the same
+// initial state.
 func (c *UpgradeableConfig) Equal(other *UpgradeableConfig) bool {
 	if other == nil {
 		return false
 	}
-	return c.Disable == other.Disable && utils.BigNumEqual(c.BlockTimestamp, other.BlockTimestamp)
+	if c.Disable != other.Disable || c.StorageVersion != other.StorageVersion || !utils.BigNumEqual(c.BlockTimestamp, other.BlockTimestamp) ||
+		!utils.BigNumEqual(c.BlockNumber, other.BlockNumber) {
+		return false
+	}
+	if len(c.InitialStorage) != len(other.InitialStorage) || len(c.InitialEvents) != len(other.InitialEvents) {
+		return false
+	}
+	for key, value := range c.InitialStorage {
+		if otherValue, ok := other.InitialStorage[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	for i := range c.InitialEvents {
+		if !c.InitialEvents[i].Equal(&other.InitialEvents[i]) {
+			return false
+		}
+	}
+	return true
 }
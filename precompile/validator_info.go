@@ -0,0 +1,499 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/subnet-evm/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	nodeIDLen = len(ids.EmptyNodeID)
+
+	// validatorEntrySlots is the number of storage slots used by each validator in the snapshot:
+	// one slot for the nodeID, weight and public key flag and two slots for the BLS public key.
+	validatorEntrySlots = 3
+
+	CurrentEpochGasCost   uint64 = 2 * readGasCostPerSlot                         // read epoch and P-chain height
+	ValidatorCountGasCost uint64 = readGasCostPerSlot                             // read count
+	TotalWeightGasCost    uint64 = readGasCostPerSlot                             // read total weight
+	ValidatorAtGasCost    uint64 = (1 + validatorEntrySlots) * readGasCostPerSlot // read count + read entry
+	GetValidatorGasCost   uint64 = (1 + validatorEntrySlots) * readGasCostPerSlot // read index + read entry
+
+	// ValidatorInfoRawABI contains the raw ABI of ValidatorInfo contract.
+	ValidatorInfoRawABI = "[{\"inputs\":[],\"name\":\"currentEpoch\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"epoch\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"pChainHeight\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes20\",\"name\":\"nodeID\",\"type\":\"bytes20\"}],\"name\":\"getValidator\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"isValidator\",\"type\":\"bool\"},{\"internalType\":\"uint64\",\"name\":\"weight\",\"type\":\"uint64\"},{\"internalType\":\"bytes\",\"name\":\"blsPublicKey\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"totalWeight\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"weight\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"index\",\"type\":\"uint256\"}],\"name\":\"validatorAt\",\"outputs\":[{\"internalType\":\"bytes20\",\"name\":\"nodeID\",\"type\":\"bytes20\"},{\"internalType\":\"uint64\",\"name\":\"weight\",\"type\":\"uint64\"},{\"internalType\":\"bytes\",\"name\":\"blsPublicKey\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"validatorCount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"count\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+)
+
+var (
+	_ StatefulPrecompileConfig = &ValidatorInfoConfig{}
+
+	ErrInvalidEpochDuration    = errors.New("epoch duration must be greater than 0")
+	ErrEpochsRequireTimestamp  = errors.New("validator info must be enabled by timestamp since its epochs are measured in seconds")
+	ErrValidatorOutOfBounds    = errors.New("validator index out of bounds")
+	ErrValidatorStateNotFound  = errors.New("validator state is not available")
+	ErrMissingPChainHeight     = errors.New("header does not contain a P-chain height")
+	ErrInvalidBLSPublicKeySize = errors.New("invalid BLS public key size")
+
+	ValidatorInfoABI        abi.ABI                     // will be initialized by init function
+	ValidatorInfoPrecompile StatefulPrecompiledContract // will be initialized by init function
+
+	// Storage layout of the validator snapshot. The validator entries are keyed by index and
+	// the positions of the validators are keyed by nodeID so that both enumeration and lookup
+	// are possible.
+	validatorSnapshotEpochStorageKey  = common.Hash{'v', 's', 'e', 's', 'k'}
+	validatorSnapshotHeightStorageKey = common.Hash{'v', 's', 'h', 's', 'k'}
+	validatorCountStorageKey          = common.Hash{'v', 'c', 's', 'k'}
+	validatorTotalWeightStorageKey    = common.Hash{'v', 't', 'w', 's', 'k'}
+	validatorIndexPrefix              = []byte("validatorIndex")
+	validatorEntryPrefix              = []byte("validatorEntry")
+)
+
+// ValidatorInfoConfig implements the StatefulPrecompileConfig interface while adding in the
+// ValidatorInfo specific precompile config. The validator set of the subnet is snapshotted from
+// the P-chain at the first block of every epoch of [EpochDuration] seconds, starting at the
+// activation timestamp of the precompile.
+type ValidatorInfoConfig struct {
+	UpgradeableConfig
+	EpochDuration uint64 `json:"epochDuration,omitempty"` // length of an epoch in seconds
+}
+
+// Validator is a single entry of a validator snapshot.
+type Validator struct {
+	NodeID       ids.NodeID
+	Weight       uint64
+	BLSPublicKey []byte // compressed BLS public key, or nil if the validator has not registered one
+}
+
+// ValidatorInfoConfigKey is the JSON key of the ValidatorInfo config in the chain config and precompile upgrades.
+const ValidatorInfoConfigKey = "validatorInfoConfig"
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(ValidatorInfoRawABI))
+	if err != nil {
+		panic(err)
+	}
+	ValidatorInfoABI = parsed
+	ValidatorInfoPrecompile = createValidatorInfoPrecompile()
+
+	RegisterModule(Module{
+		ConfigKey: ValidatorInfoConfigKey,
+		Address:   ValidatorInfoAddress,
+		Contract:  ValidatorInfoPrecompile,
+		NewConfig: func() StatefulPrecompileConfig { return new(ValidatorInfoConfig) },
+		ABI:       &ValidatorInfoABI,
+		RawABI:    ValidatorInfoRawABI,
+		GasCosts: map[string]uint64{
+			"currentEpoch":   CurrentEpochGasCost,
+			"validatorCount": ValidatorCountGasCost,
+			"totalWeight":    TotalWeightGasCost,
+			"validatorAt":    ValidatorAtGasCost,
+			"getValidator":   GetValidatorGasCost,
+		},
+	})
+}
+
+// NewValidatorInfoConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// ValidatorInfo with epochs of [epochDuration] seconds.
+func NewValidatorInfoConfig(blockTimestamp *big.Int, epochDuration uint64) *ValidatorInfoConfig {
+	return &ValidatorInfoConfig{
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+		EpochDuration:     epochDuration,
+	}
+}
+
+// NewDisableValidatorInfoConfig returns config for a network upgrade at [blockTimestamp]
+// that disables ValidatorInfo.
+func NewDisableValidatorInfoConfig(blockTimestamp *big.Int) *ValidatorInfoConfig {
+	return &ValidatorInfoConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the validator info precompile.
+func (c *ValidatorInfoConfig) Address() common.Address {
+	return ValidatorInfoAddress
+}
+
+// Configure is a no-op since the first snapshot is written when the block activating
+// the precompile is processed.
+func (c *ValidatorInfoConfig) Configure(_ ChainConfig, _ StateDB, _ BlockContext) {}
+
+// Contract returns the singleton stateful precompiled contract to be used for the validator info.
+func (c *ValidatorInfoConfig) Contract() StatefulPrecompiledContract {
+	return ValidatorInfoPrecompile
+}
+
+// Verify returns an error if [EpochDuration] is zero for a config that enables the precompile.
+func (c *ValidatorInfoConfig) Verify() error {
+	if c.Disable {
+		return nil
+	}
+	if c.EpochDuration == 0 {
+		return ErrInvalidEpochDuration
+	}
+	if c.BlockTimestamp == nil && c.BlockNumber != nil {
+		return ErrEpochsRequireTimestamp
+	}
+	return nil
+}
+
+// Equal returns true if [s] is a [*ValidatorInfoConfig] and it has been configured identical to [c].
+func (c *ValidatorInfoConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*ValidatorInfoConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.EpochDuration == other.EpochDuration
+}
+
+// String returns a string representation of the ValidatorInfoConfig.
+func (c *ValidatorInfoConfig) String() string {
+	bytes, _ := json.Marshal(c)
+	return string(bytes)
+}
+
+// EpochAt returns the epoch that [blockTimestamp] falls in.
+// Assumes that [blockTimestamp] is not before the activation timestamp of [c].
+func (c *ValidatorInfoConfig) EpochAt(blockTimestamp *big.Int) uint64 {
+	elapsed := new(big.Int).Sub(blockTimestamp, c.Timestamp())
+	return elapsed.Uint64() / c.EpochDuration
+}
+
+// NewValidatorSnapshot converts the validator set returned by the P-chain into a list of
+// validators sorted by nodeID, so that every node writes the snapshot in the same order.
+func NewValidatorSnapshot(validatorSet map[ids.NodeID]*validators.GetValidatorOutput) []Validator {
+	snapshot := make([]Validator, 0, len(validatorSet))
+	for nodeID, vdr := range validatorSet {
+		validator := Validator{
+			NodeID: nodeID,
+			Weight: vdr.Weight,
+		}
+		if vdr.PublicKey != nil {
+			validator.BLSPublicKey = bls.PublicKeyToBytes(vdr.PublicKey)
+		}
+		snapshot = append(snapshot, validator)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		return bytes.Compare(snapshot[i].NodeID[:], snapshot[j].NodeID[:]) < 0
+	})
+	return snapshot
+}
+
+// validatorIndexKey returns the storage key holding the 1-based position of [nodeID] in the snapshot.
+func validatorIndexKey(nodeID ids.NodeID) common.Hash {
+	return crypto.Keccak256Hash(validatorIndexPrefix, nodeID[:])
+}
+
+// validatorEntryKey returns the storage key of [slot] of the validator at [index].
+func validatorEntryKey(index uint64, slot byte) common.Hash {
+	return crypto.Keccak256Hash(validatorEntryPrefix, common.BigToHash(new(big.Int).SetUint64(index)).Bytes(), []byte{slot})
+}
+
+// GetValidatorSnapshotEpoch returns the epoch and P-chain height of the current snapshot and
+// false if no snapshot has been written.
+func GetValidatorSnapshotEpoch(stateDB StateDB) (uint64, uint64, bool) {
+	// The epoch is stored incremented by one so that the first epoch can be distinguished
+	// from the absence of a snapshot.
+	storedEpoch := stateDB.GetState(ValidatorInfoAddress, validatorSnapshotEpochStorageKey).Big().Uint64()
+	if storedEpoch == 0 {
+		return 0, 0, false
+	}
+	pChainHeight := stateDB.GetState(ValidatorInfoAddress, validatorSnapshotHeightStorageKey).Big().Uint64()
+	return storedEpoch - 1, pChainHeight, true
+}
+
+// GetValidatorCount returns the number of validators in the current snapshot.
+func GetValidatorCount(stateDB StateDB) uint64 {
+	return stateDB.GetState(ValidatorInfoAddress, validatorCountStorageKey).Big().Uint64()
+}
+
+// GetValidatorTotalWeight returns the sum of the weights of the validators in the current snapshot.
+func GetValidatorTotalWeight(stateDB StateDB) *big.Int {
+	return stateDB.GetState(ValidatorInfoAddress, validatorTotalWeightStorageKey).Big()
+}
+
+// GetValidatorAt returns the validator at [index] of the current snapshot and false if [index] is out of bounds.
+func GetValidatorAt(stateDB StateDB, index uint64) (Validator, bool) {
+	if index >= GetValidatorCount(stateDB) {
+		return Validator{}, false
+	}
+	return getValidatorEntry(stateDB, index), true
+}
+
+// GetValidator returns the validator with [nodeID] in the current snapshot and false if it is not a validator.
+func GetValidator(stateDB StateDB, nodeID ids.NodeID) (Validator, bool) {
+	position := stateDB.GetState(ValidatorInfoAddress, validatorIndexKey(nodeID)).Big().Uint64()
+	if position == 0 {
+		return Validator{}, false
+	}
+	return getValidatorEntry(stateDB, position-1), true
+}
+
+// getValidatorEntry reads the validator at [index] from [stateDB].
+// The first slot holds the nodeID in bytes [0, 20), the weight in bytes [20, 28) and a flag
+// indicating the presence of a BLS public key in byte 31. The remaining slots hold the key.
+func getValidatorEntry(stateDB StateDB, index uint64) Validator {
+	header := stateDB.GetState(ValidatorInfoAddress, validatorEntryKey(index, 0))
+	validator := Validator{
+		Weight: binary.BigEndian.Uint64(header[nodeIDLen : nodeIDLen+8]),
+	}
+	copy(validator.NodeID[:], header[:nodeIDLen])
+	if header[common.HashLength-1] != 0 {
+		key := make([]byte, 0, 2*common.HashLength)
+		for slot := byte(1); slot < validatorEntrySlots; slot++ {
+			val := stateDB.GetState(ValidatorInfoAddress, validatorEntryKey(index, slot))
+			key = append(key, val[:]...)
+		}
+		validator.BLSPublicKey = key[:bls.PublicKeyLen]
+	}
+	return validator
+}
+
+// setValidatorEntry writes [validator] at [index] to [stateDB].
+func setValidatorEntry(stateDB StateDB, index uint64, validator Validator) {
+	var header common.Hash
+	copy(header[:nodeIDLen], validator.NodeID[:])
+	binary.BigEndian.PutUint64(header[nodeIDLen:nodeIDLen+8], validator.Weight)
+	var key [2 * common.HashLength]byte
+	if validator.BLSPublicKey != nil {
+		header[common.HashLength-1] = 1
+		copy(key[:], validator.BLSPublicKey)
+	}
+	stateDB.SetState(ValidatorInfoAddress, validatorEntryKey(index, 0), header)
+	stateDB.SetState(ValidatorInfoAddress, validatorEntryKey(index, 1), common.BytesToHash(key[:common.HashLength]))
+	stateDB.SetState(ValidatorInfoAddress, validatorEntryKey(index, 2), common.BytesToHash(key[common.HashLength:]))
+}
+
+// SetValidatorSnapshot replaces the current snapshot with [snapshot], taken at [pChainHeight] for [epoch].
+// Returns an error if a BLS public key in [snapshot] has an invalid size.
+func SetValidatorSnapshot(stateDB StateDB, epoch uint64, pChainHeight uint64, snapshot []Validator) error {
+	for _, validator := range snapshot {
+		if validator.BLSPublicKey != nil && len(validator.BLSPublicKey) != bls.PublicKeyLen {
+			return fmt.Errorf("%w: %d for %s", ErrInvalidBLSPublicKeySize, len(validator.BLSPublicKey), validator.NodeID)
+		}
+	}
+
+	// Clear the positions of the previous validators and any entries beyond the new snapshot.
+	prevCount := GetValidatorCount(stateDB)
+	for index := uint64(0); index < prevCount; index++ {
+		prev := getValidatorEntry(stateDB, index)
+		stateDB.SetState(ValidatorInfoAddress, validatorIndexKey(prev.NodeID), common.Hash{})
+		if index >= uint64(len(snapshot)) {
+			for slot := byte(0); slot < validatorEntrySlots; slot++ {
+				stateDB.SetState(ValidatorInfoAddress, validatorEntryKey(index, slot), common.Hash{})
+			}
+		}
+	}
+
+	totalWeight := new(big.Int)
+	for index, validator := range snapshot {
+		setValidatorEntry(stateDB, uint64(index), validator)
+		stateDB.SetState(ValidatorInfoAddress, validatorIndexKey(validator.NodeID), common.BigToHash(new(big.Int).SetUint64(uint64(index)+1)))
+		totalWeight.Add(totalWeight, new(big.Int).SetUint64(validator.Weight))
+	}
+	stateDB.SetState(ValidatorInfoAddress, validatorCountStorageKey, common.BigToHash(new(big.Int).SetUint64(uint64(len(snapshot)))))
+	stateDB.SetState(ValidatorInfoAddress, validatorTotalWeightStorageKey, common.BigToHash(totalWeight))
+	stateDB.SetState(ValidatorInfoAddress, validatorSnapshotEpochStorageKey, common.BigToHash(new(big.Int).SetUint64(epoch+1)))
+	stateDB.SetState(ValidatorInfoAddress, validatorSnapshotHeightStorageKey, common.BigToHash(new(big.Int).SetUint64(pChainHeight)))
+	return nil
+}
+
+// PackCurrentEpoch packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackCurrentEpoch() ([]byte, error) {
+	return ValidatorInfoABI.Pack("currentEpoch")
+}
+
+// PackValidatorCount packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackValidatorCount() ([]byte, error) {
+	return ValidatorInfoABI.Pack("validatorCount")
+}
+
+// PackTotalWeight packs the include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackTotalWeight() ([]byte, error) {
+	return ValidatorInfoABI.Pack("totalWeight")
+}
+
+// PackValidatorAt packs [index] of type *big.Int into the appropriate arguments for validatorAt.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackValidatorAt(index *big.Int) ([]byte, error) {
+	return ValidatorInfoABI.Pack("validatorAt", index)
+}
+
+// PackGetValidator packs [nodeID] of type ids.NodeID into the appropriate arguments for getValidator.
+// the packed bytes include selector (first 4 func signature bytes).
+// This function is mostly used for tests.
+func PackGetValidator(nodeID ids.NodeID) ([]byte, error) {
+	return ValidatorInfoABI.Pack("getValidator", [nodeIDLen]byte(nodeID))
+}
+
+// PackValidatorOutput attempts to pack [validator] to conform the ABI outputs of the function [name],
+// which is either validatorAt or getValidator. [isValidator] is only included for getValidator.
+func PackValidatorOutput(name string, isValidator bool, validator Validator) ([]byte, error) {
+	blsPublicKey := validator.BLSPublicKey
+	if blsPublicKey == nil {
+		blsPublicKey = []byte{}
+	}
+	if name == "getValidator" {
+		return ValidatorInfoABI.PackOutput(name, isValidator, validator.Weight, blsPublicKey)
+	}
+	return ValidatorInfoABI.PackOutput(name, [nodeIDLen]byte(validator.NodeID), validator.Weight, blsPublicKey)
+}
+
+// UnpackValidatorAtInput attempts to unpack [input] into the *big.Int type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackValidatorAtInput(input []byte) (*big.Int, error) {
+	res, err := ValidatorInfoABI.UnpackInput("validatorAt", input)
+	if err != nil {
+		return nil, err
+	}
+	unpacked := *abi.ConvertType(res[0], new(*big.Int)).(**big.Int)
+	return unpacked, nil
+}
+
+// UnpackGetValidatorInput attempts to unpack [input] into the ids.NodeID type argument
+// assumes that [input] does not include selector (omits first 4 func signature bytes)
+func UnpackGetValidatorInput(input []byte) (ids.NodeID, error) {
+	res, err := ValidatorInfoABI.UnpackInput("getValidator", input)
+	if err != nil {
+		return ids.NodeID{}, err
+	}
+	unpacked := *abi.ConvertType(res[0], new([nodeIDLen]byte)).(*[nodeIDLen]byte)
+	return ids.NodeID(unpacked), nil
+}
+
+func currentEpoch(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CurrentEpochGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	epoch, pChainHeight, _ := GetValidatorSnapshotEpoch(accessibleState.GetStateDB())
+	packedOutput, err := ValidatorInfoABI.PackOutput("currentEpoch", new(big.Int).SetUint64(epoch), new(big.Int).SetUint64(pChainHeight))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func validatorCount(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ValidatorCountGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	count := GetValidatorCount(accessibleState.GetStateDB())
+	packedOutput, err := ValidatorInfoABI.PackOutput("validatorCount", new(big.Int).SetUint64(count))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func totalWeight(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, TotalWeightGasCost); err != nil {
+		return nil, 0, err
+	}
+	// no input provided for this function
+
+	packedOutput, err := ValidatorInfoABI.PackOutput("totalWeight", GetValidatorTotalWeight(accessibleState.GetStateDB()))
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func validatorAt(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, ValidatorAtGasCost); err != nil {
+		return nil, 0, err
+	}
+	index, err := UnpackValidatorAtInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+	if !index.IsUint64() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrValidatorOutOfBounds, index)
+	}
+
+	validator, ok := GetValidatorAt(accessibleState.GetStateDB(), index.Uint64())
+	if !ok {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrValidatorOutOfBounds, index)
+	}
+	packedOutput, err := PackValidatorOutput("validatorAt", true, validator)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+func getValidator(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, GetValidatorGasCost); err != nil {
+		return nil, 0, err
+	}
+	nodeID, err := UnpackGetValidatorInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	validator, ok := GetValidator(accessibleState.GetStateDB(), nodeID)
+	packedOutput, err := PackValidatorOutput("getValidator", ok, validator)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	// Return the packed output and the remaining gas
+	return packedOutput, remainingGas, nil
+}
+
+// createValidatorInfoPrecompile returns a StatefulPrecompiledContract with read-only access to the
+// validator snapshot of the current epoch.
+func createValidatorInfoPrecompile() StatefulPrecompiledContract {
+	var functions []*statefulPrecompileFunction
+
+	abiFunctionMap := map[string]RunStatefulPrecompileFunc{
+		"currentEpoch":   currentEpoch,
+		"validatorCount": validatorCount,
+		"totalWeight":    totalWeight,
+		"validatorAt":    validatorAt,
+		"getValidator":   getValidator,
+	}
+	for name, function := range abiFunctionMap {
+		method, ok := ValidatorInfoABI.Methods[name]
+		if !ok {
+			panic(fmt.Errorf("given method (%s) does not exist in the ABI", name))
+		}
+		functions = append(functions, newStatefulPrecompileFunction(method.Name, method.ID, method.IsConstant(), function))
+	}
+
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, nil, functions)
+}
@@ -0,0 +1,60 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+)
+
+// batchLimits are the limits applied to the batches of requests served by a handler.
+type batchLimits struct {
+	itemLimit       int // maximum number of requests in a batch (unlimited if 0)
+	responseMaxSize int // maximum size in bytes of the results of a batch (unlimited if 0)
+}
+
+// BatchCache holds values shared by the calls of a batch of requests, such as the state
+// of the blocks the calls are served on, so that they are computed once per batch
+// instead of once per call.
+type BatchCache struct {
+	lock   sync.Mutex
+	values map[interface{}]interface{}
+}
+
+type batchCacheContextKey struct{}
+
+// withBatchCache returns a copy of [ctx] carrying a new, empty batch cache.
+func withBatchCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchCacheContextKey{}, &BatchCache{values: make(map[interface{}]interface{})})
+}
+
+// BatchCacheFromContext returns the cache of the batch of requests being served.
+// Use this with the context passed to RPC method handler functions.
+//
+// nil is returned if the call is not part of a batch.
+func BatchCacheFromContext(ctx context.Context) *BatchCache {
+	cache, _ := ctx.Value(batchCacheContextKey{}).(*BatchCache)
+	return cache
+}
+
+// GetOrCreate returns the value cached under [key], calling [create] to compute it if
+// it is not cached yet. Errors returned by [create] are not cached. It is safe to call
+// GetOrCreate on a nil cache, in which case [create] is always called.
+func (c *BatchCache) GetOrCreate(key interface{}, create func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return create()
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if value, ok := c.values[key]; ok {
+		return value, nil
+	}
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+	c.values[key] = value
+	return value, nil
+}
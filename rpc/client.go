@@ -88,6 +88,13 @@ type Client struct {
 	isHTTP   bool      // isHTTP specifies if the client uses an HTTP connection
 	services *serviceRegistry
 
+	// batchLimits are applied to the batches of requests served on the connection, the
+	// calls served on the connection are charged to [costLimiter], and those taking
+	// longer than [slowCallThreshold] are logged.
+	batchLimits       batchLimits
+	costLimiter       *costLimiter
+	slowCallThreshold time.Duration
+
 	idCounter uint32
 
 	// This function, if non-nil, is called when the connection is lost.
@@ -129,6 +136,9 @@ func (c *Client) newClientConn(conn ServerCodec, apiMaxDuration, refillRate, max
 	// all client invocations of this function), it is ignored.
 	handler.deadlineContext = apiMaxDuration
 	handler.addLimiter(refillRate, maxStored)
+	handler.batchLimits = c.batchLimits
+	handler.costLimiter = c.costLimiter
+	handler.slowCallThreshold = c.slowCallThreshold
 	return &clientConn{conn, handler}
 }
 
@@ -214,27 +224,30 @@ func newClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), 0, 0, 0)
+	c := initClient(conn, randomIDGenerator(), new(serviceRegistry), 0, 0, 0, batchLimits{}, nil, 0)
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, apiMaxDuration, refillRate, maxStored time.Duration) *Client {
+func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry, apiMaxDuration, refillRate, maxStored time.Duration, limits batchLimits, costLimiter *costLimiter, slowCallThreshold time.Duration) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
-		idgen:       idgen,
-		isHTTP:      isHTTP,
-		services:    services,
-		writeConn:   conn,
-		close:       make(chan struct{}),
-		closing:     make(chan struct{}),
-		didClose:    make(chan struct{}),
-		reconnected: make(chan ServerCodec),
-		readOp:      make(chan readOp),
-		readErr:     make(chan error),
-		reqInit:     make(chan *requestOp),
-		reqSent:     make(chan error, 1),
-		reqTimeout:  make(chan *requestOp),
+		idgen:             idgen,
+		isHTTP:            isHTTP,
+		services:          services,
+		batchLimits:       limits,
+		costLimiter:       costLimiter,
+		slowCallThreshold: slowCallThreshold,
+		writeConn:         conn,
+		close:             make(chan struct{}),
+		closing:           make(chan struct{}),
+		didClose:          make(chan struct{}),
+		reconnected:       make(chan ServerCodec),
+		readOp:            make(chan readOp),
+		readErr:           make(chan error),
+		reqInit:           make(chan *requestOp),
+		reqSent:           make(chan error, 1),
+		reqTimeout:        make(chan *requestOp),
 	}
 	if !c.isHTTP {
 		go c.dispatch(conn, apiMaxDuration, refillRate, maxStored)
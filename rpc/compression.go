@@ -0,0 +1,84 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressor is a compressing writer that can be reused for several responses.
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressorPools contains the pools of compressors of the supported HTTP content encodings.
+// "deflate" is the zlib format, as specified by RFC 9110.
+var compressorPools = map[string]*sync.Pool{
+	"gzip":    {New: func() interface{} { return gzip.NewWriter(nil) }},
+	"deflate": {New: func() interface{} { return zlib.NewWriter(nil) }},
+}
+
+// ValidateHTTPCompression returns an error if one of [encodings] is not a supported
+// HTTP response compression ("gzip" or "deflate").
+func ValidateHTTPCompression(encodings []string) error {
+	for _, encoding := range encodings {
+		if _, ok := compressorPools[encoding]; !ok {
+			return fmt.Errorf("unsupported HTTP compression %q", encoding)
+		}
+	}
+	return nil
+}
+
+// negotiateEncoding returns the first of [encodings] accepted by the Accept-Encoding
+// header of [r], or "" if the response must not be compressed.
+func negotiateEncoding(r *http.Request, encodings []string) string {
+	if len(encodings) == 0 {
+		return ""
+	}
+	accepted := make(map[string]bool)
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			accepted[name] = true
+			if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+				if weight, err := strconv.ParseFloat(params[2:], 64); err == nil && weight == 0 {
+					accepted[name] = false
+				}
+			}
+		}
+	}
+	for _, encoding := range encodings {
+		if ok, listed := accepted[encoding]; ok || (!listed && accepted["*"]) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressResponse returns a writer compressing the response written to [w] with
+// [encoding], and a function closing it once the response is written.
+func compressResponse(w http.ResponseWriter, encoding string) (io.Writer, func()) {
+	header := w.Header()
+	header.Set("Content-Encoding", encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+
+	pool := compressorPools[encoding]
+	cw := pool.Get().(compressor)
+	cw.Reset(w)
+	return cw, func() {
+		cw.Close()
+		cw.Reset(nil)
+		pool.Put(cw)
+	}
+}
@@ -29,6 +29,7 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -36,7 +37,9 @@ import (
 	"time"
 
 	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/tracing"
 	"github.com/ethereum/go-ethereum/log"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/time/rate"
 )
 
@@ -79,6 +82,10 @@ type handler struct {
 
 	deadlineContext time.Duration // limits execution after some time.Duration
 	limiter         *rate.Limiter
+	batchLimits     batchLimits  // limits the size of batches and their responses
+	costLimiter     *costLimiter // charges the calls to the budgets of their clients
+
+	slowCallThreshold time.Duration // calls taking longer are logged (disabled if 0)
 }
 
 type callProc struct {
@@ -141,13 +148,45 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	// Reject batches with too many calls before processing any of them:
+	if limit := h.batchLimits.itemLimit; limit > 0 && len(calls) > limit {
+		h.startCallProc(func(cp *callProc) {
+			err := &invalidRequestError{fmt.Sprintf("batch too large: %d requests exceed the limit of %d", len(calls), limit)}
+			h.conn.writeJSONSkipDeadline(cp.ctx, errorMessage(err), h.deadlineContext > 0)
+		})
+		return
+	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		// The calls of the batch share a cache, so that values such as the state of
+		// a block are only computed once per batch.
+		cp.ctx = withBatchCache(cp.ctx)
+		var (
+			answers       = make([]*jsonrpcMessage, 0, len(msgs))
+			responseBytes int
+			tooLarge      bool
+		)
 		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+			// Once the results exceed the maximum response size, the remaining
+			// calls are answered with an error without being processed.
+			if tooLarge {
+				if !msg.isNotification() {
+					answers = append(answers, msg.errorResponse(&responseTooLargeError{}))
+				}
+				continue
+			}
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
+			}
+			if limit := h.batchLimits.responseMaxSize; limit > 0 {
+				responseBytes += len(answer.Result)
+				if responseBytes > limit {
+					tooLarge = true
+					answer = msg.errorResponse(&responseTooLargeError{})
+				}
 			}
+			answers = append(answers, answer)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -415,6 +454,9 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if err := h.costLimiter.charge(PeerInfoFromContext(cp.ctx).clientKey, msg.Method); err != nil {
+		return msg.errorResponse(err)
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -432,7 +474,18 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, span := tracing.Start(cp.ctx, "rpc."+msg.Method)
+	var stats *CallStats
+	if h.slowCallThreshold > 0 {
+		stats = new(CallStats)
+		ctx = context.WithValue(ctx, callStatsContextKey{}, stats)
+	}
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error != nil {
+		span.SetStatus(codes.Error, answer.Error.Message)
+	}
+	span.End()
+	h.logSlowCall(msg, stats, start)
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
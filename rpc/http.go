@@ -241,8 +241,8 @@ type httpServerConn struct {
 	r *http.Request
 }
 
-func newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
-	body := io.LimitReader(r.Body, maxRequestContentLength)
+func newHTTPServerConn(r *http.Request, w io.Writer, bodyLimit int) ServerCodec {
+	body := io.LimitReader(r.Body, int64(bodyLimit))
 	conn := &httpServerConn{Reader: body, Writer: w, r: r}
 	return NewCodec(conn)
 }
@@ -265,7 +265,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		return
 	}
-	if code, err := validateRequest(r); err != nil {
+	bodyLimit := s.httpBodyLimit
+	if bodyLimit == 0 {
+		bodyLimit = maxRequestContentLength
+	}
+	if code, err := validateRequest(r, bodyLimit); err != nil {
 		http.Error(w, err.Error(), code)
 		return
 	}
@@ -276,6 +280,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	connInfo.clientKey = s.costLimiter.clientKey(r)
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 	// All checks passed, create a codec that reads directly from the request body
@@ -283,19 +288,25 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// single request.
 
 	w.Header().Set("content-type", contentType)
-	codec := newHTTPServerConn(r, w)
+	var out io.Writer = w
+	if encoding := negotiateEncoding(r, s.httpCompression); encoding != "" {
+		cw, closeWriter := compressResponse(w, encoding)
+		defer closeWriter()
+		out = cw
+	}
+	codec := newHTTPServerConn(r, out, bodyLimit)
 	defer codec.close()
 	s.serveSingleRequest(ctx, codec)
 }
 
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
-func validateRequest(r *http.Request) (int, error) {
+func validateRequest(r *http.Request, bodyLimit int) (int, error) {
 	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
 		return http.StatusMethodNotAllowed, errors.New("method not allowed")
 	}
-	if r.ContentLength > maxRequestContentLength {
-		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxRequestContentLength)
+	if r.ContentLength > int64(bodyLimit) {
+		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, bodyLimit)
 		return http.StatusRequestEntityTooLarge, err
 	}
 	// Allow OPTIONS (regardless of content-type)
@@ -27,10 +27,15 @@
 package rpc
 
 import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func confirmStatusCode(t *testing.T, got, want int) {
@@ -52,7 +57,7 @@ func confirmRequestValidationCode(t *testing.T, method, contentType, body string
 	if len(contentType) > 0 {
 		request.Header.Set("Content-Type", contentType)
 	}
-	code, err := validateRequest(request)
+	code, err := validateRequest(request, maxRequestContentLength)
 	if code == 0 {
 		if err != nil {
 			t.Errorf("validation: got error %v, expected nil", err)
@@ -208,3 +213,164 @@ func TestHTTPPeerInfo(t *testing.T) {
 		t.Errorf("wrong HTTP.Origin %q", info.HTTP.UserAgent)
 	}
 }
+
+func TestHTTPCostLimits(t *testing.T) {
+	s := newTestServer()
+	// Clients identified by IP get a budget of 100, and the "premium" API key 10000.
+	s.SetCostLimits(CostLimits{
+		Costs:       map[string]uint64{"test_*": 60, "test_noArgsRets": 1},
+		DefaultCost: 1,
+		Budget:      1,
+		KeyBudgets:  map[string]uint64{"premium": 100},
+		KeyHeader:   "X-Api-Key",
+		BurstWindow: 100 * time.Second,
+	})
+	defer s.Stop()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	dial := func(apiKey string) *Client {
+		c, err := Dial(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(apiKey) > 0 {
+			c.SetHeader("X-Api-Key", apiKey)
+		}
+		return c
+	}
+	call := func(c *Client, method string) error {
+		var result interface{}
+		return c.Call(&result, method, "x", 1)
+	}
+	expectRateLimited := func(err error) {
+		t.Helper()
+		if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32005 {
+			t.Fatalf("expected rate limited error, got %v", err)
+		}
+	}
+
+	// The second call exceeds the budget of the IP of the client
+	ipClient := dial("")
+	if err := call(ipClient, "test_echo"); err != nil {
+		t.Fatal(err)
+	}
+	expectRateLimited(call(ipClient, "test_echo"))
+	// Cheaper calls fit in the remaining budget
+	if err := ipClient.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown API keys share the budget of their IP
+	expectRateLimited(call(dial("unknown"), "test_echo"))
+
+	// Known API keys have their own budget
+	keyClient := dial("premium")
+	for i := 0; i < 10; i++ {
+		if err := call(keyClient, "test_echo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHTTPCompression(t *testing.T) {
+	const respLength = 100000
+
+	s := NewServer(0)
+	defer s.Stop()
+	s.RegisterName("test", largeRespService{respLength})
+	if err := s.SetHTTPCompression("brotli"); err == nil {
+		t.Fatal("expected unsupported compression to be rejected")
+	}
+	if err := s.SetHTTPCompression("gzip", "deflate"); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		acceptEncoding string
+		encoding       string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip;q=0.5", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"*", "gzip"},
+		{"br", ""},
+	} {
+		req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_largeResp"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		if len(tt.acceptEncoding) > 0 {
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		} else {
+			// Prevent the transport from requesting gzip on its own.
+			req.Header.Set("Accept-Encoding", "identity")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body io.Reader = resp.Body
+		switch encoding := resp.Header.Get("Content-Encoding"); {
+		case encoding != tt.encoding:
+			t.Fatalf("Accept-Encoding %q: got encoding %q, want %q", tt.acceptEncoding, encoding, tt.encoding)
+		case encoding == "gzip":
+			if body, err = gzip.NewReader(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+		case encoding == "deflate":
+			if body, err = zlib.NewReader(resp.Body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var msg jsonrpcMessage
+		err = json.NewDecoder(body).Decode(&msg)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Accept-Encoding %q: %v", tt.acceptEncoding, err)
+		}
+		if len(msg.Result) != respLength+2 {
+			t.Fatalf("Accept-Encoding %q: response has wrong length %d, want %d", tt.acceptEncoding, len(msg.Result), respLength+2)
+		}
+	}
+
+	// The client transparently decompresses responses.
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var r string
+	if err := c.Call(&r, "test_largeResp"); err != nil {
+		t.Fatal(err)
+	}
+	if len(r) != respLength {
+		t.Fatalf("response has wrong length %d, want %d", len(r), respLength)
+	}
+}
+
+func TestHTTPBodyLimit(t *testing.T) {
+	s := newTestServer()
+	defer s.Stop()
+	s.SetHTTPBodyLimit(64)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := DialHTTP(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var r echoResult
+	if err := c.Call(&r, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	err = c.Call(&r, "test_echo", strings.Repeat("x", 64), 1)
+	if httpErr, ok := err.(HTTPError); !ok || httpErr.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected request entity too large error, got %v", err)
+	}
+}
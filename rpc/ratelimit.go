@@ -0,0 +1,173 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRateLimitedClients is the number of clients whose budget is tracked, after which
+	// the least recently seen clients are forgotten (and start over with a full budget).
+	maxRateLimitedClients = 10_000
+
+	apiKeyClientPrefix = "key:"
+	ipClientPrefix     = "ip:"
+)
+
+var (
+	rpcCostCounter        = metrics.NewRegisteredCounter("rpc/cost/all", nil)
+	rpcRateLimitedCounter = metrics.NewRegisteredCounter("rpc/ratelimit/rejected", nil)
+	rpcRateLimitedClients = metrics.NewRegisteredGauge("rpc/ratelimit/clients", nil)
+
+	// rpcCostCounterName and rpcRateLimitedCounterName are the prefixes of the per-method
+	// cost and rejection counters.
+	rpcCostCounterName        = "rpc/cost"
+	rpcRateLimitedCounterName = "rpc/ratelimit/rejected"
+)
+
+// CostLimits configures the cost accounting and rate limiting of the calls served by
+// a Server. Each call is charged the cost of its method, in gas-equivalent units, against
+// the budget of the client making it: the API key sent in [KeyHeader] if it is listed in
+// [KeyBudgets], or the IP address of the client otherwise.
+type CostLimits struct {
+	// Costs are the costs of the methods, by name ("eth_getLogs") or by namespace
+	// ("debug_*"). Methods that are not listed cost [DefaultCost].
+	Costs       map[string]uint64
+	DefaultCost uint64
+
+	// Budget is the cost refilled each second to the budget of each client identified
+	// by its IP address, and KeyBudgets the budgets of the clients identified by their
+	// API key. Calls are not rate limited if Budget is 0, and the calls of an API key
+	// with a budget of 0 are never rate limited.
+	Budget     uint64
+	KeyBudgets map[string]uint64
+	KeyHeader  string
+
+	// BurstWindow is the duration of budget a client can accumulate while idle. A call
+	// costing more than the accumulated budget of a client is rejected, except for the
+	// methods costing more than the whole window, which require a full budget instead.
+	BurstWindow time.Duration
+}
+
+// costLimiter charges the calls of a Server to the budgets of their clients.
+type costLimiter struct {
+	costs       map[string]uint64
+	defaultCost uint64
+
+	budget      uint64
+	keyBudgets  map[string]uint64
+	keyHeader   string
+	burstWindow time.Duration
+
+	lock    sync.Mutex
+	clients *lru.Cache // client key -> *rate.Limiter
+}
+
+func newCostLimiter(limits CostLimits) *costLimiter {
+	clients, _ := lru.NewWithEvict(maxRateLimitedClients, func(interface{}, interface{}) {
+		rpcRateLimitedClients.Dec(1)
+	})
+	return &costLimiter{
+		costs:       limits.Costs,
+		defaultCost: limits.DefaultCost,
+		budget:      limits.Budget,
+		keyBudgets:  limits.KeyBudgets,
+		keyHeader:   limits.KeyHeader,
+		burstWindow: limits.BurstWindow,
+		clients:     clients,
+	}
+}
+
+// clientKey returns the key identifying the client of [r]: its API key if it is
+// listed in [keyBudgets], or its IP address otherwise.
+func (l *costLimiter) clientKey(r *http.Request) string {
+	if l == nil {
+		return ""
+	}
+	if len(l.keyHeader) > 0 {
+		if key := r.Header.Get(l.keyHeader); len(key) > 0 {
+			if _, ok := l.keyBudgets[key]; ok {
+				return apiKeyClientPrefix + key
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return ipClientPrefix + host
+}
+
+// cost returns the cost of a call to [method].
+func (l *costLimiter) cost(method string) uint64 {
+	if cost, ok := l.costs[method]; ok {
+		return cost
+	}
+	if i := strings.IndexByte(method, serviceMethodSeparator[0]); i >= 0 {
+		if cost, ok := l.costs[method[:i+1]+"*"]; ok {
+			return cost
+		}
+	}
+	return l.defaultCost
+}
+
+// charge charges the cost of a call to [method] to the budget of [clientKey], returning
+// an error if the budget is exhausted. Calls from clients without a key, such as
+// in-process calls, are accounted for but never rejected.
+func (l *costLimiter) charge(clientKey, method string) error {
+	if l == nil {
+		return nil
+	}
+	cost := l.cost(method)
+	rpcCostCounter.Inc(int64(cost))
+	if metrics.EnabledExpensive {
+		metrics.GetOrRegisterCounter(rpcCostCounterName+"/"+method, nil).Inc(int64(cost))
+	}
+	if l.budget == 0 || len(clientKey) == 0 {
+		return nil
+	}
+	limiter := l.limiter(clientKey)
+	if burst := uint64(limiter.Burst()); cost > burst {
+		cost = burst
+	}
+	if !limiter.AllowN(time.Now(), int(cost)) {
+		rpcRateLimitedCounter.Inc(1)
+		if metrics.EnabledExpensive {
+			metrics.GetOrRegisterCounter(rpcRateLimitedCounterName+"/"+method, nil).Inc(1)
+		}
+		return &rateLimitedError{method: method}
+	}
+	return nil
+}
+
+// limiter returns the rate limiter of [clientKey], creating it if it is not tracked.
+func (l *costLimiter) limiter(clientKey string) *rate.Limiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if limiter, ok := l.clients.Get(clientKey); ok {
+		return limiter.(*rate.Limiter)
+	}
+	budget := l.budget
+	if strings.HasPrefix(clientKey, apiKeyClientPrefix) {
+		budget = l.keyBudgets[clientKey[len(apiKeyClientPrefix):]]
+	}
+	burst := uint64(float64(budget) * l.burstWindow.Seconds())
+	if burst < budget {
+		burst = budget
+	}
+	limiter := rate.NewLimiter(rate.Limit(budget), int(burst))
+	l.clients.Add(clientKey, limiter)
+	rpcRateLimitedClients.Inc(1)
+	return limiter
+}
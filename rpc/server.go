@@ -58,6 +58,12 @@ type Server struct {
 	run             int32
 	codecs          mapset.Set
 	maximumDuration time.Duration
+	batchLimits     batchLimits
+	costLimiter     *costLimiter
+
+	slowCallThreshold time.Duration
+	httpBodyLimit     int
+	httpCompression   []string
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -79,6 +85,61 @@ func NewServer(maximumDuration time.Duration) *Server {
 	return server
 }
 
+// SetBatchLimits sets the limits applied to batches of requests. Batches with more than
+// [itemLimit] requests are rejected, and once the results of a batch exceed
+// [maxResponseSize] bytes, its remaining requests are answered with an error. A limit
+// of 0 disables the corresponding check.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
+	s.batchLimits = batchLimits{itemLimit: itemLimit, responseMaxSize: maxResponseSize}
+}
+
+// SetCostLimits sets the cost accounting and rate limiting of the calls received over
+// HTTP and WebSocket, as configured by [limits].
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCostLimits(limits CostLimits) {
+	s.costLimiter = newCostLimiter(limits)
+}
+
+// SetSlowCallThreshold sets the duration after which calls are logged as slow, along
+// with a summary of their parameters and the state they read. Slow calls are not
+// logged if [threshold] is 0.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	s.slowCallThreshold = threshold
+}
+
+// SetHTTPBodyLimit sets the maximum size in bytes of the body of the requests received
+// over HTTP, which bounds the size of a batch. The default of 5 MiB is kept if [limit] is 0.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetHTTPBodyLimit(limit int) {
+	if limit > 0 {
+		s.httpBodyLimit = limit
+	}
+}
+
+// SetHTTPCompression sets the content encodings ("gzip" or "deflate") the responses
+// to HTTP requests are compressed with, in order of preference, if the client accepts
+// them. Responses are not compressed if [encodings] is empty.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetHTTPCompression(encodings ...string) error {
+	if err := ValidateHTTPCompression(encodings); err != nil {
+		return err
+	}
+	s.httpCompression = encodings
+	return nil
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -104,7 +165,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption, apiMaxDurati
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services, apiMaxDuration, refillRate, maxStored)
+	c := initClient(codec, s.idgen, &s.services, apiMaxDuration, refillRate, maxStored, s.batchLimits, s.costLimiter, s.slowCallThreshold)
 	<-codec.closed()
 	c.Close()
 }
@@ -120,6 +181,9 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services)
 	h.deadlineContext = s.maximumDuration
+	h.batchLimits = s.batchLimits
+	h.costLimiter = s.costLimiter
+	h.slowCallThreshold = s.slowCallThreshold
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -190,6 +254,9 @@ type PeerInfo struct {
 		Origin    string
 		Host      string
 	}
+
+	// clientKey identifies the client in the budgets of the cost limiter.
+	clientKey string
 }
 
 type peerInfoContextKey struct{}
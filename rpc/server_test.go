@@ -54,7 +54,7 @@ func TestServerRegisterName(t *testing.T) {
 		t.Fatalf("Expected service calc to be registered")
 	}
 
-	wantCallbacks := 10
+	wantCallbacks := 11
 	if len(svc.callbacks) != wantCallbacks {
 		t.Errorf("Expected %d callbacks for service 'service', got %d", wantCallbacks, len(svc.callbacks))
 	}
@@ -120,6 +120,60 @@ func runTestScript(t *testing.T, file string) {
 	}
 }
 
+func TestServerBatch(t *testing.T) {
+	server := newTestServer()
+	server.SetBatchLimits(3, 40)
+	defer server.Stop()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0, 0, 0, 0)
+	readbuf := bufio.NewReader(clientConn)
+
+	tests := []struct {
+		name, request, response string
+	}{
+		{
+			"single call",
+			`{"jsonrpc":"2.0","id":1,"method":"test_batchCalls"}`,
+			`{"jsonrpc":"2.0","id":1,"result":0}`,
+		},
+		{
+			// The calls of a batch share the batch cache
+			"shared cache",
+			`[{"jsonrpc":"2.0","id":1,"method":"test_batchCalls"},{"jsonrpc":"2.0","id":2,"method":"test_batchCalls"},{"jsonrpc":"2.0","id":3,"method":"test_batchCalls"}]`,
+			`[{"jsonrpc":"2.0","id":1,"result":1},{"jsonrpc":"2.0","id":2,"result":2},{"jsonrpc":"2.0","id":3,"result":3}]`,
+		},
+		{
+			"too many requests",
+			`[{"jsonrpc":"2.0","id":1,"method":"test_batchCalls"},{"jsonrpc":"2.0","id":2,"method":"test_batchCalls"},{"jsonrpc":"2.0","id":3,"method":"test_batchCalls"},{"jsonrpc":"2.0","id":4,"method":"test_batchCalls"}]`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch too large: 4 requests exceed the limit of 3"}}`,
+		},
+		{
+			// The results of the first call fit in the response, but not those of the second
+			"response too large",
+			`[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",2]},{"jsonrpc":"2.0","id":3,"method":"test_batchCalls"}]`,
+			`[{"jsonrpc":"2.0","id":1,"result":{"String":"x","Int":1,"Args":null}},{"jsonrpc":"2.0","id":2,"error":{"code":-32003,"message":"response too large"}},{"jsonrpc":"2.0","id":3,"error":{"code":-32003,"message":"response too large"}}]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientConn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if _, err := io.WriteString(clientConn, test.request+"\n"); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+			clientConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			sent, err := readbuf.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read error: %v", err)
+			}
+			if sent = strings.TrimRight(sent, "\r\n"); sent != test.response {
+				t.Errorf("wrong response from server\ngot:  %s\nwant: %s", sent, test.response)
+			}
+		})
+	}
+}
+
 // // This test checks that responses are delivered for very short-lived connections that
 // // only carry a single request.
 // func TestServerShortLivedConn(t *testing.T) {
@@ -0,0 +1,121 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/metrics"
+)
+
+const (
+	// maxSummarizedParams is the number of parameters included in the summary of a slow
+	// call, and maxSummarizedParamLength the length each of them is truncated to.
+	maxSummarizedParams      = 8
+	maxSummarizedParamLength = 128
+)
+
+var slowCallCounter = metrics.NewRegisteredCounter("rpc/slow", nil)
+
+// StateReader is implemented by the states read by RPC calls, such as *state.StateDB.
+type StateReader interface {
+	// StateReads returns the number of accounts and storage slots loaded from disk.
+	StateReads() (accounts int, storage int)
+}
+
+// CallStats collects the statistics of a call that are reported in the slow call log.
+type CallStats struct {
+	lock   sync.Mutex
+	states []StateReader
+}
+
+type callStatsContextKey struct{}
+
+// CallStatsFromContext returns the statistics of the call being served. Use this with
+// the context passed to RPC method handler functions.
+//
+// nil is returned if slow calls are not logged.
+func CallStatsFromContext(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(callStatsContextKey{}).(*CallStats)
+	return stats
+}
+
+// TrackState adds the reads of [state] to the state reads of the call, unless it is
+// already tracked. It is safe to call TrackState on nil stats, in which case it does
+// nothing.
+func (c *CallStats) TrackState(state StateReader) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, tracked := range c.states {
+		if tracked == state {
+			return
+		}
+	}
+	c.states = append(c.states, state)
+}
+
+// stateReads returns the number of accounts and storage slots loaded by the states
+// tracked by the call.
+func (c *CallStats) stateReads() (accounts int, storage int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, state := range c.states {
+		a, s := state.StateReads()
+		accounts += a
+		storage += s
+	}
+	return accounts, storage
+}
+
+// logSlowCall logs [msg] if it took at least the slow call threshold of the handler
+// since [start], along with the statistics collected in [stats].
+func (h *handler) logSlowCall(msg *jsonrpcMessage, stats *CallStats, start time.Time) {
+	elapsed := time.Since(start)
+	if stats == nil || elapsed < h.slowCallThreshold {
+		return
+	}
+	slowCallCounter.Inc(1)
+	accounts, storage := stats.stateReads()
+	h.log.Warn("Slow RPC call", "method", msg.Method, "params", summarizeParams(msg.Params), "duration", elapsed, "accountReads", accounts, "storageReads", storage)
+}
+
+// summarizeParams returns the first parameters of [params], each truncated so that the
+// summary of the large parameters (e.g. call data) stays readable.
+func summarizeParams(params json.RawMessage) string {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil {
+		return truncateParam(string(params))
+	}
+	summary := make([]string, 0, len(args))
+	for i, arg := range args {
+		if i == maxSummarizedParams {
+			summary = append(summary, "...")
+			break
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, arg); err != nil {
+			summary = append(summary, truncateParam(string(arg)))
+			continue
+		}
+		summary = append(summary, truncateParam(compact.String()))
+	}
+	return "[" + strings.Join(summary, ",") + "]"
+}
+
+func truncateParam(param string) string {
+	if len(param) <= maxSummarizedParamLength {
+		return param
+	}
+	return param[:maxSummarizedParamLength] + "..."
+}
@@ -0,0 +1,88 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeStateReader struct{ accounts, storage int }
+
+func (f *fakeStateReader) StateReads() (int, int) { return f.accounts, f.storage }
+
+type slowCallService struct {
+	stats *CallStats
+}
+
+func (s *slowCallService) Read(ctx context.Context, delay time.Duration) bool {
+	stats := CallStatsFromContext(ctx)
+	state := &fakeStateReader{accounts: 2, storage: 3}
+	stats.TrackState(state)
+	stats.TrackState(state)
+	time.Sleep(delay)
+	s.stats = stats
+	return stats != nil
+}
+
+func TestSlowCallLog(t *testing.T) {
+	service := new(slowCallService)
+	server := NewServer(0)
+	if err := server.RegisterName("slow", service); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Calls are not instrumented when slow calls are not logged
+	var tracked bool
+	if err := client.Call(&tracked, "slow_read", 0); err != nil {
+		t.Fatal(err)
+	}
+	if tracked {
+		t.Fatal("expected no call stats without a slow call threshold")
+	}
+
+	server.SetSlowCallThreshold(10 * time.Millisecond)
+	client = DialInProc(server)
+	defer client.Close()
+	for _, delay := range []time.Duration{0, 20 * time.Millisecond} {
+		before := slowCallCounter.Count()
+		if err := client.Call(&tracked, "slow_read", delay); err != nil {
+			t.Fatal(err)
+		}
+		if !tracked {
+			t.Fatal("expected call stats with a slow call threshold")
+		}
+		// The state tracked twice is counted once
+		if accounts, storage := service.stats.stateReads(); accounts != 2 || storage != 3 {
+			t.Fatalf("wrong state reads: got %d accounts and %d storage slots, want 2 and 3", accounts, storage)
+		}
+		if logged, slow := slowCallCounter.Count()-before, delay > 0; (logged == 1) != slow {
+			t.Fatalf("call with delay %s logged %d times", delay, logged)
+		}
+	}
+}
+
+func TestSummarizeParams(t *testing.T) {
+	long := strings.Repeat("ab", maxSummarizedParamLength)
+	tests := []struct {
+		params, summary string
+	}{
+		{`[]`, `[]`},
+		{`["0x1", {"to": "0x2",  "data": "0x"}, true]`, `["0x1",{"to":"0x2","data":"0x"},true]`},
+		{`["` + long + `"]`, `["` + long[:maxSummarizedParamLength-1] + `...]`},
+		{`[1,2,3,4,5,6,7,8,9,10]`, `[1,2,3,4,5,6,7,8,...]`},
+		{`{"not": "positional"}`, `{"not": "positional"}`},
+	}
+	for _, test := range tests {
+		if summary := summarizeParams(json.RawMessage(test.params)); summary != test.summary {
+			t.Errorf("wrong summary of %s\ngot:  %s\nwant: %s", test.params, summary, test.summary)
+		}
+	}
+}
@@ -94,6 +94,21 @@ func (s *testService) PeerInfo(ctx context.Context) PeerInfo {
 	return PeerInfoFromContext(ctx)
 }
 
+// BatchCalls returns the number of calls to BatchCalls made so far in the batch of the
+// call, or 0 if the call is not part of a batch.
+func (s *testService) BatchCalls(ctx context.Context) (int, error) {
+	cache := BatchCacheFromContext(ctx)
+	if cache == nil {
+		return 0, nil
+	}
+	calls, err := cache.GetOrCreate("calls", func() (interface{}, error) { return new(int), nil })
+	if err != nil {
+		return 0, err
+	}
+	*calls.(*int)++
+	return *calls.(*int), nil
+}
+
 func (s *testService) Sleep(ctx context.Context, duration time.Duration) {
 	time.Sleep(duration)
 }
@@ -74,7 +74,8 @@ func (s *Server) WebsocketHandlerWithDuration(allowedOrigins []string, apiMaxDur
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn, r.Host, r.Header)
+		codec := newWebsocketCodec(conn, r.Host, r.Header).(*websocketCodec)
+		codec.info.clientKey = s.costLimiter.clientKey(r)
 		s.ServeCodec(codec, 0, apiMaxDuration, refillRate, maxStored)
 	})
 }
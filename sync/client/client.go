@@ -51,6 +51,7 @@ var (
 	errUnmarshalResponse      = errors.New("failed to unmarshal response")
 	errInvalidCodeResponseLen = errors.New("number of code bytes in response does not match requested hashes")
 	errMaxCodeSizeExceeded    = errors.New("max code size exceeded")
+	errInvalidTrieNodesLen    = errors.New("number of trie nodes in response does not match requested hashes")
 )
 var _ Client = &client{}
 
@@ -67,6 +68,9 @@ type Client interface {
 
 	// GetCode synchronously retrieves code associated with the given hashes
 	GetCode(ctx context.Context, hashes []common.Hash) ([][]byte, error)
+
+	// GetTrieNodes synchronously retrieves the trie nodes with the given hashes
+	GetTrieNodes(ctx context.Context, hashes []common.Hash) ([][]byte, error)
 }
 
 // parseResponseFn parses given response bytes in context of specified request
@@ -147,7 +151,7 @@ func parseLeafsResponse(codec codec.Manager, reqIntf message.Request, data []byt
 		return nil, 0, fmt.Errorf("empty key response must include merkle proof")
 	}
 
-	var proof ethdb.Database
+	var proof ethdb.KeyValueStore
 	// Populate proof when ProofVals are present in the response. Its ok to pass it as nil to the trie.VerifyRangeProof
 	// function as it will assert that all the leaves belonging to the specified root are present.
 	if len(leafsResponse.ProofVals) > 0 {
@@ -285,6 +289,41 @@ func parseCode(codec codec.Manager, req message.Request, data []byte) (interface
 	return response.Data, totalBytes, nil
 }
 
+func (c *client) GetTrieNodes(ctx context.Context, hashes []common.Hash) ([][]byte, error) {
+	req := message.NewTrieNodesRequest(hashes)
+
+	data, err := c.get(ctx, req, parseTrieNodes)
+	if err != nil {
+		return nil, fmt.Errorf("could not get trie nodes (%s): %w", req, err)
+	}
+
+	return data.([][]byte), nil
+}
+
+// parseTrieNodes validates given object as a list of trie nodes
+// assumes req is of type message.TrieNodesRequest
+// returns a non-nil error if the request should be retried
+func parseTrieNodes(codec codec.Manager, req message.Request, data []byte) (interface{}, int, error) {
+	var response message.TrieNodesResponse
+	if _, err := codec.Unmarshal(data, &response); err != nil {
+		return nil, 0, err
+	}
+
+	trieNodesRequest := req.(message.TrieNodesRequest)
+	if len(response.Data) != len(trieNodesRequest.Hashes) {
+		return nil, 0, fmt.Errorf("%w (got %d) (requested %d)", errInvalidTrieNodesLen, len(response.Data), len(trieNodesRequest.Hashes))
+	}
+
+	for i, node := range response.Data {
+		hash := crypto.Keccak256Hash(node)
+		if hash != trieNodesRequest.Hashes[i] {
+			return nil, 0, fmt.Errorf("%w for trie node at index %d: (got %v) (expected %v)", errHashMismatch, i, hash, trieNodesRequest.Hashes[i])
+		}
+	}
+
+	return response.Data, len(response.Data), nil
+}
+
 // get submits given request and blockingly returns with either a parsed response object or an error
 // if [ctx] expires before the client can successfully retrieve a valid response.
 // Retries if there is a network error or if the [parseResponseFn] returns an error indicating an invalid response.
@@ -17,6 +17,7 @@ import (
 
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
 	"github.com/ava-labs/subnet-evm/params"
@@ -134,6 +135,80 @@ func TestGetCode(t *testing.T) {
 	}
 }
 
+func TestGetTrieNodes(t *testing.T) {
+	mockNetClient := &mockNetwork{}
+
+	node := []byte{0xc2, 0x80, 0x80}
+	nodeHash := crypto.Keccak256Hash(node)
+	tests := map[string]struct {
+		requestHashes []common.Hash
+		mockResponse  message.TrieNodesResponse
+		expectedErr   error
+	}{
+		"normal": {
+			requestHashes: []common.Hash{nodeHash},
+			mockResponse:  message.TrieNodesResponse{Data: [][]byte{node}},
+		},
+		"unexpected node bytes": {
+			requestHashes: []common.Hash{{1}},
+			mockResponse:  message.TrieNodesResponse{Data: [][]byte{node}},
+			expectedErr:   errHashMismatch,
+		},
+		"too many nodes returned": {
+			requestHashes: []common.Hash{nodeHash},
+			mockResponse:  message.TrieNodesResponse{Data: [][]byte{node, node}},
+			expectedErr:   errInvalidTrieNodesLen,
+		},
+		"too few nodes returned": {
+			requestHashes: []common.Hash{nodeHash},
+			mockResponse:  message.TrieNodesResponse{Data: [][]byte{}},
+			expectedErr:   errInvalidTrieNodesLen,
+		},
+	}
+
+	stateSyncClient := NewClient(&ClientConfig{
+		NetworkClient:    mockNetClient,
+		Codec:            message.Codec,
+		Stats:            clientstats.NewNoOpStats(),
+		StateSyncNodeIDs: nil,
+		BlockParser:      mockBlockParser,
+	})
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			responseBytes, err := message.Codec.Marshal(message.Version, test.mockResponse)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The client re-requests if it encounters an error, so cancel before the second attempt.
+			attempted := false
+			if test.expectedErr == nil {
+				mockNetClient.mockResponse(1, nil, responseBytes)
+			} else {
+				mockNetClient.mockResponse(2, func() {
+					if attempted {
+						cancel()
+					}
+					attempted = true
+				}, responseBytes)
+			}
+
+			nodes, err := stateSyncClient.GetTrieNodes(ctx, test.requestHashes)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				assert.EqualValues(t, 2, mockNetClient.numCalls)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.mockResponse.Data, nodes)
+			assert.Equal(t, uint(1), mockNetClient.numCalls)
+		})
+	}
+}
+
 func TestGetBlocks(t *testing.T) {
 	// set random seed for deterministic tests
 	rand.Seed(1)
@@ -141,7 +216,7 @@ func TestGetBlocks(t *testing.T) {
 	var gspec = &core.Genesis{
 		Config: params.TestChainConfig,
 	}
-	memdb := memorydb.New()
+	memdb := rawdb.NewMemoryDatabase()
 	genesis := gspec.MustCommit(memdb)
 	engine := dummy.NewETHFaker()
 	numBlocks := 110
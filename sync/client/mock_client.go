@@ -31,6 +31,8 @@ type MockClient struct {
 	codeReceived   int32
 	blocksHandler  *handlers.BlockRequestHandler
 	blocksReceived int32
+	nodesHandler   *handlers.TrieNodesRequestHandler
+	nodesReceived  int32
 	// GetLeafsIntercept is called on every GetLeafs request if set to a non-nil callback.
 	// The returned response will be returned by MockClient to the caller.
 	GetLeafsIntercept func(req message.LeafsRequest, res message.LeafsResponse) (message.LeafsResponse, error)
@@ -40,6 +42,9 @@ type MockClient struct {
 	// GetBlocksIntercept is called on every GetBlocks request if set to a non-nil callback.
 	// The returned response will be returned by MockClient to the caller.
 	GetBlocksIntercept func(blockReq message.BlockRequest, blocks types.Blocks) (types.Blocks, error)
+	// GetTrieNodesIntercept is called on every GetTrieNodes request if set to a non-nil callback.
+	// The returned response will be returned by MockClient to the caller.
+	GetTrieNodesIntercept func(hashes []common.Hash, nodes [][]byte) ([][]byte, error)
 }
 
 func NewMockClient(
@@ -47,12 +52,14 @@ func NewMockClient(
 	leafHandler *handlers.LeafsRequestHandler,
 	codesHandler *handlers.CodeRequestHandler,
 	blocksHandler *handlers.BlockRequestHandler,
+	nodesHandler *handlers.TrieNodesRequestHandler,
 ) *MockClient {
 	return &MockClient{
 		codec:         codec,
 		leafsHandler:  leafHandler,
 		codesHandler:  codesHandler,
 		blocksHandler: blocksHandler,
+		nodesHandler:  nodesHandler,
 	}
 }
 
@@ -138,6 +145,34 @@ func (ml *MockClient) BlocksReceived() int32 {
 	return atomic.LoadInt32(&ml.blocksReceived)
 }
 
+func (ml *MockClient) GetTrieNodes(ctx context.Context, hashes []common.Hash) ([][]byte, error) {
+	if ml.nodesHandler == nil {
+		panic("no trie nodes handler for mock client")
+	}
+	request := message.NewTrieNodesRequest(hashes)
+	response, err := ml.nodesHandler.OnTrieNodesRequest(ctx, ids.GenerateTestNodeID(), 1, request)
+	if err != nil {
+		return nil, err
+	}
+
+	nodesIntf, numNodes, err := parseTrieNodes(ml.codec, request, response)
+	if err != nil {
+		return nil, err
+	}
+	nodes := nodesIntf.([][]byte)
+	if ml.GetTrieNodesIntercept != nil {
+		nodes, err = ml.GetTrieNodesIntercept(hashes, nodes)
+	}
+	if err == nil {
+		atomic.AddInt32(&ml.nodesReceived, int32(numNodes))
+	}
+	return nodes, err
+}
+
+func (ml *MockClient) TrieNodesReceived() int32 {
+	return atomic.LoadInt32(&ml.nodesReceived)
+}
+
 type testBlockParser struct{}
 
 func (t *testBlockParser) ParseEthBlock(b []byte) (*types.Block, error) {
@@ -78,6 +78,7 @@ type clientSyncerStats struct {
 	atomicTrieLeavesMetric,
 	stateTrieLeavesMetric,
 	codeRequestMetric,
+	trieNodesRequestMetric,
 	blockRequestMetric MessageMetric
 }
 
@@ -87,6 +88,7 @@ func NewClientSyncerStats() ClientSyncerStats {
 		atomicTrieLeavesMetric: NewMessageMetric("sync_atomic_trie_leaves"),
 		stateTrieLeavesMetric:  NewMessageMetric("sync_state_trie_leaves"),
 		codeRequestMetric:      NewMessageMetric("sync_code"),
+		trieNodesRequestMetric: NewMessageMetric("sync_trie_nodes"),
 		blockRequestMetric:     NewMessageMetric("sync_blocks"),
 	}
 }
@@ -98,6 +100,8 @@ func (c *clientSyncerStats) GetMetric(msgIntf message.Request) (MessageMetric, e
 		return c.blockRequestMetric, nil
 	case message.CodeRequest:
 		return c.codeRequestMetric, nil
+	case message.TrieNodesRequest:
+		return c.trieNodesRequestMetric, nil
 	case message.LeafsRequest:
 		return c.stateTrieLeavesMetric, nil
 	default:
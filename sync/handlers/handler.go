@@ -35,6 +35,7 @@ type syncHandler struct {
 	stateTrieLeafsRequestHandler *LeafsRequestHandler
 	blockRequestHandler          *BlockRequestHandler
 	codeRequestHandler           *CodeRequestHandler
+	trieNodesRequestHandler      *TrieNodesRequestHandler
 }
 
 // NewSyncHandler constructs the handler for serving state sync.
@@ -48,6 +49,7 @@ func NewSyncHandler(
 		stateTrieLeafsRequestHandler: NewLeafsRequestHandler(evmTrieDB, provider, networkCodec, stats),
 		blockRequestHandler:          NewBlockRequestHandler(provider, networkCodec, stats),
 		codeRequestHandler:           NewCodeRequestHandler(evmTrieDB.DiskDB(), networkCodec, stats),
+		trieNodesRequestHandler:      NewTrieNodesRequestHandler(evmTrieDB, networkCodec, stats),
 	}
 }
 
@@ -62,3 +64,7 @@ func (s *syncHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID,
 func (s *syncHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
 	return s.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
 }
+
+func (s *syncHandler) HandleTrieNodesRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, trieNodesRequest message.TrieNodesRequest) ([]byte, error) {
+	return s.trieNodesRequestHandler.OnTrieNodesRequest(ctx, nodeID, requestID, trieNodesRequest)
+}
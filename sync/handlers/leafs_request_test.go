@@ -678,7 +678,7 @@ func assertRangeProofIsValid(t *testing.T, request *message.LeafsRequest, respon
 		end = response.Keys[len(response.Vals)-1]
 	}
 
-	var proof ethdb.Database
+	var proof ethdb.KeyValueStore
 	if len(response.ProofVals) > 0 {
 		proof = memorydb.New()
 		defer proof.Close()
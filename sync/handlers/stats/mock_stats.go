@@ -26,6 +26,12 @@ type MockHandlerStats struct {
 	CodeBytesReturnedSum uint32
 	CodeReadTimeSum time.Duration
 
+	TrieNodesRequestCount,
+	InvalidTrieNodesRequestCount,
+	MissingTrieNodeCount,
+	TrieNodesBytesReturnedSum uint32
+	TrieNodesReadTimeSum time.Duration
+
 	LeafsRequestCount,
 	InvalidLeafsRequestCount,
 	LeafsReturnedSum,
@@ -57,6 +63,11 @@ func (m *MockHandlerStats) Reset() {
 	m.DuplicateHashesRequested = 0
 	m.CodeBytesReturnedSum = 0
 	m.CodeReadTimeSum = 0
+	m.TrieNodesRequestCount = 0
+	m.InvalidTrieNodesRequestCount = 0
+	m.MissingTrieNodeCount = 0
+	m.TrieNodesBytesReturnedSum = 0
+	m.TrieNodesReadTimeSum = 0
 	m.LeafsRequestCount = 0
 	m.InvalidLeafsRequestCount = 0
 	m.LeafsReturnedSum = 0
@@ -135,6 +146,36 @@ func (m *MockHandlerStats) UpdateCodeBytesReturned(bytes uint32) {
 	m.CodeBytesReturnedSum += bytes
 }
 
+func (m *MockHandlerStats) IncTrieNodesRequest() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.TrieNodesRequestCount++
+}
+
+func (m *MockHandlerStats) IncInvalidTrieNodesRequest() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.InvalidTrieNodesRequestCount++
+}
+
+func (m *MockHandlerStats) IncMissingTrieNode() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.MissingTrieNodeCount++
+}
+
+func (m *MockHandlerStats) UpdateTrieNodesReadTime(duration time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.TrieNodesReadTimeSum += duration
+}
+
+func (m *MockHandlerStats) UpdateTrieNodesBytesReturned(bytes uint32) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.TrieNodesBytesReturnedSum += bytes
+}
+
 func (m *MockHandlerStats) IncLeafsRequest() {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -14,6 +14,7 @@ type HandlerStats interface {
 	BlockRequestHandlerStats
 	CodeRequestHandlerStats
 	LeafsRequestHandlerStats
+	TrieNodesRequestHandlerStats
 }
 
 type BlockRequestHandlerStats interface {
@@ -32,6 +33,14 @@ type CodeRequestHandlerStats interface {
 	UpdateCodeBytesReturned(bytes uint32)
 }
 
+type TrieNodesRequestHandlerStats interface {
+	IncTrieNodesRequest()
+	IncInvalidTrieNodesRequest()
+	IncMissingTrieNode()
+	UpdateTrieNodesReadTime(duration time.Duration)
+	UpdateTrieNodesBytesReturned(bytes uint32)
+}
+
 type LeafsRequestHandlerStats interface {
 	IncLeafsRequest()
 	IncInvalidLeafsRequest()
@@ -66,6 +75,13 @@ type handlerStats struct {
 	codeBytesReturned        metrics.Histogram
 	codeReadDuration         metrics.Timer
 
+	// TrieNodesRequestHandler stats
+	trieNodesRequest        metrics.Counter
+	invalidTrieNodesRequest metrics.Counter
+	missingTrieNode         metrics.Counter
+	trieNodesBytesReturned  metrics.Histogram
+	trieNodesReadDuration   metrics.Timer
+
 	// LeafsRequestHandler stats
 	leafsRequest               metrics.Counter
 	invalidLeafsRequest        metrics.Counter
@@ -125,6 +141,26 @@ func (h *handlerStats) UpdateCodeBytesReturned(bytesLen uint32) {
 	h.codeBytesReturned.Update(int64(bytesLen))
 }
 
+func (h *handlerStats) IncTrieNodesRequest() {
+	h.trieNodesRequest.Inc(1)
+}
+
+func (h *handlerStats) IncInvalidTrieNodesRequest() {
+	h.invalidTrieNodesRequest.Inc(1)
+}
+
+func (h *handlerStats) IncMissingTrieNode() {
+	h.missingTrieNode.Inc(1)
+}
+
+func (h *handlerStats) UpdateTrieNodesReadTime(duration time.Duration) {
+	h.trieNodesReadDuration.Update(duration)
+}
+
+func (h *handlerStats) UpdateTrieNodesBytesReturned(bytesLen uint32) {
+	h.trieNodesBytesReturned.Update(int64(bytesLen))
+}
+
 func (h *handlerStats) IncLeafsRequest() {
 	h.leafsRequest.Inc(1)
 }
@@ -185,6 +221,13 @@ func NewHandlerStats(enabled bool) HandlerStats {
 		codeReadDuration:         metrics.GetOrRegisterTimer("code_request_read_time", nil),
 		codeBytesReturned:        metrics.GetOrRegisterHistogram("code_request_bytes_returned", nil, metrics.NewExpDecaySample(1028, 0.015)),
 
+		// initialize trie nodes request stats
+		trieNodesRequest:        metrics.GetOrRegisterCounter("trie_nodes_request_count", nil),
+		invalidTrieNodesRequest: metrics.GetOrRegisterCounter("trie_nodes_request_invalid", nil),
+		missingTrieNode:         metrics.GetOrRegisterCounter("trie_nodes_request_missing_node", nil),
+		trieNodesReadDuration:   metrics.GetOrRegisterTimer("trie_nodes_request_read_time", nil),
+		trieNodesBytesReturned:  metrics.GetOrRegisterHistogram("trie_nodes_request_bytes_returned", nil, metrics.NewExpDecaySample(1028, 0.015)),
+
 		// initialize leafs request stats
 		leafsRequest:               metrics.GetOrRegisterCounter("leafs_request_count", nil),
 		invalidLeafsRequest:        metrics.GetOrRegisterCounter("leafs_request_invalid", nil),
@@ -223,6 +266,11 @@ func (n *noopHandlerStats) IncTooManyHashesRequested()
 func (n *noopHandlerStats) IncDuplicateHashesRequested()                        {}
 func (n *noopHandlerStats) UpdateCodeReadTime(time.Duration)                    {}
 func (n *noopHandlerStats) UpdateCodeBytesReturned(uint32)                      {}
+func (n *noopHandlerStats) IncTrieNodesRequest()                                {}
+func (n *noopHandlerStats) IncInvalidTrieNodesRequest()                         {}
+func (n *noopHandlerStats) IncMissingTrieNode()                                 {}
+func (n *noopHandlerStats) UpdateTrieNodesReadTime(time.Duration)               {}
+func (n *noopHandlerStats) UpdateTrieNodesBytesReturned(uint32)                 {}
 func (n *noopHandlerStats) IncLeafsRequest()                                    {}
 func (n *noopHandlerStats) IncInvalidLeafsRequest()                             {}
 func (n *noopHandlerStats) UpdateLeafsRequestProcessingTime(time.Duration)      {}
@@ -0,0 +1,81 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// TrieNodesRequestHandler is a peer.RequestHandler for message.TrieNodesRequest
+// serving requested trie nodes by their hashes
+type TrieNodesRequestHandler struct {
+	trieDB *trie.Database
+	codec  codec.Manager
+	stats  stats.TrieNodesRequestHandlerStats
+}
+
+func NewTrieNodesRequestHandler(trieDB *trie.Database, codec codec.Manager, stats stats.TrieNodesRequestHandlerStats) *TrieNodesRequestHandler {
+	return &TrieNodesRequestHandler{
+		trieDB: trieDB,
+		codec:  codec,
+		stats:  stats,
+	}
+}
+
+// OnTrieNodesRequest handles request to retrieve trie nodes by their hashes in message.TrieNodesRequest
+// Never returns error
+// Returns nothing if any of the nodes is not found
+// Expects returned errors to be treated as FATAL
+// Assumes ctx is active
+func (t *TrieNodesRequestHandler) OnTrieNodesRequest(_ context.Context, nodeID ids.NodeID, requestID uint32, trieNodesRequest message.TrieNodesRequest) ([]byte, error) {
+	startTime := time.Now()
+	t.stats.IncTrieNodesRequest()
+
+	// always report trie nodes read time metric
+	defer func() {
+		t.stats.UpdateTrieNodesReadTime(time.Since(startTime))
+	}()
+
+	if len(trieNodesRequest.Hashes) == 0 || len(trieNodesRequest.Hashes) > message.MaxTrieNodesPerRequest {
+		t.stats.IncInvalidTrieNodesRequest()
+		log.Debug("invalid number of trie nodes requested, dropping request", "nodeID", nodeID, "requestID", requestID, "numHashes", len(trieNodesRequest.Hashes))
+		return nil, nil
+	}
+	if !isUnique(trieNodesRequest.Hashes) {
+		t.stats.IncInvalidTrieNodesRequest()
+		log.Debug("duplicate trie nodes requested, dropping request", "nodeID", nodeID, "requestID", requestID)
+		return nil, nil
+	}
+
+	nodes := make([][]byte, len(trieNodesRequest.Hashes))
+	totalBytes := 0
+	for i, hash := range trieNodesRequest.Hashes {
+		node, err := t.trieDB.RawNode(hash)
+		if err != nil || len(node) == 0 {
+			t.stats.IncMissingTrieNode()
+			log.Debug("requested trie node not found, dropping request", "nodeID", nodeID, "requestID", requestID, "hash", hash)
+			return nil, nil
+		}
+		nodes[i] = node
+		totalBytes += len(node)
+	}
+
+	trieNodesResponse := message.TrieNodesResponse{Data: nodes}
+	responseBytes, err := t.codec.Marshal(message.Version, trieNodesResponse)
+	if err != nil {
+		log.Warn("could not marshal TrieNodesResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", trieNodesRequest, "err", err)
+		return nil, nil
+	}
+	t.stats.UpdateTrieNodesBytesReturned(uint32(totalBytes))
+	return responseBytes, nil
+}
@@ -0,0 +1,92 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/sync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrieNodesRequestHandler(t *testing.T) {
+	trieDB := trie.NewDatabase(memorydb.New())
+	root, _, _ := trie.GenerateTrie(t, trieDB, 100, common.HashLength)
+	rootNode, err := trieDB.RawNode(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockHandlerStats := &stats.MockHandlerStats{}
+	trieNodesRequestHandler := NewTrieNodesRequestHandler(trieDB, message.Codec, mockHandlerStats)
+
+	tooManyHashes := make([]common.Hash, message.MaxTrieNodesPerRequest+1)
+	for i := range tooManyHashes {
+		tooManyHashes[i] = common.Hash{byte(i)}
+	}
+
+	tests := map[string]struct {
+		request          message.TrieNodesRequest
+		expectedResponse [][]byte
+		verifyStats      func(t *testing.T, stats *stats.MockHandlerStats)
+	}{
+		"normal": {
+			request:          message.NewTrieNodesRequest([]common.Hash{root}),
+			expectedResponse: [][]byte{rootNode},
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.TrieNodesRequestCount)
+				assert.EqualValues(t, len(rootNode), stats.TrieNodesBytesReturnedSum)
+			},
+		},
+		"missing node": {
+			request: message.NewTrieNodesRequest([]common.Hash{root, {1}}),
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.MissingTrieNodeCount)
+			},
+		},
+		"duplicate hashes": {
+			request: message.NewTrieNodesRequest([]common.Hash{root, root}),
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.InvalidTrieNodesRequestCount)
+			},
+		},
+		"too many hashes": {
+			request: message.NewTrieNodesRequest(tooManyHashes),
+			verifyStats: func(t *testing.T, stats *stats.MockHandlerStats) {
+				assert.EqualValues(t, 1, stats.InvalidTrieNodesRequestCount)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		// Reset stats before each test
+		mockHandlerStats.Reset()
+
+		t.Run(name, func(t *testing.T) {
+			responseBytes, err := trieNodesRequestHandler.OnTrieNodesRequest(context.Background(), ids.GenerateTestNodeID(), 1, test.request)
+			assert.NoError(t, err)
+			test.verifyStats(t, mockHandlerStats)
+
+			if len(test.expectedResponse) == 0 {
+				assert.Len(t, responseBytes, 0, "expected response to be empty")
+				return
+			}
+			var response message.TrieNodesResponse
+			if _, err = message.Codec.Unmarshal(responseBytes, &response); err != nil {
+				t.Fatal("error unmarshalling TrieNodesResponse", err)
+			}
+			assert.Equal(t, test.expectedResponse, response.Data)
+			for i, node := range response.Data {
+				assert.Equal(t, test.request.Hashes[i], crypto.Keccak256Hash(node))
+			}
+		})
+	}
+}
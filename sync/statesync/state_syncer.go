@@ -91,7 +91,7 @@ func NewStateSyncer(config *StateSyncerConfig) (*stateSync, error) {
 	})
 
 	ss.trieQueue = NewTrieQueue(config.DB)
-	if err := ss.trieQueue.clearIfRootDoesNotMatch(ss.root); err != nil {
+	if err := ss.trieQueue.updateRoot(ss.root); err != nil {
 		return nil, err
 	}
 
@@ -137,8 +137,22 @@ func (t *stateSync) onMainTrieFinished() error {
 // all storage tries have completed syncing. We persist
 // [mainTrie]'s batch last to avoid persisting the state
 // root before all storage tries are done syncing.
-func (t *stateSync) onSyncComplete() error {
-	return t.mainTrie.batch.Write()
+// If the account trie was synced from leafs of a previous
+// root, it is healed and the snapshot is scheduled to be
+// regenerated, since it holds the leafs of the previous root.
+func (t *stateSync) onSyncComplete(ctx context.Context) error {
+	if err := t.mainTrie.batch.Write(); err != nil {
+		return err
+	}
+	if !t.mainTrie.requiresHeal {
+		return nil
+	}
+	healer := newTrieHealer(t.db, t.client, t.root, t.batchSize, defaultNumThreads)
+	if err := healer.heal(ctx); err != nil {
+		return err
+	}
+	snapshot.ScheduleSnapshotRegeneration(t.db)
+	return nil
 }
 
 // storageTrieProducer waits for the main trie to finish
@@ -204,7 +218,7 @@ func (t *stateSync) Start(ctx context.Context) error {
 		if err := <-t.syncer.Done(); err != nil {
 			return err
 		}
-		return t.onSyncComplete()
+		return t.onSyncComplete(egCtx)
 	})
 	eg.Go(func() error {
 		err := <-t.codeSyncer.Done()
@@ -224,6 +238,9 @@ func (t *stateSync) Start(ctx context.Context) error {
 
 func (t *stateSync) Done() <-chan error { return t.done }
 
+// Progress returns the progress of the sync. It is safe to call concurrently with the sync.
+func (t *stateSync) Progress() Progress { return t.stats.progress() }
+
 // addTrieInProgress tracks the root as being currently synced.
 func (t *stateSync) addTrieInProgress(root common.Hash, trie *trieToSync) {
 	t.lock.Lock()
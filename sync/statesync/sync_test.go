@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"math/big"
 	"math/rand"
 	"runtime/pprof"
 	"sync/atomic"
@@ -39,6 +40,13 @@ type syncTest struct {
 	expectedError     error
 	GetLeafsIntercept func(message.LeafsRequest, message.LeafsResponse) (message.LeafsResponse, error)
 	GetCodeIntercept  func([]common.Hash, [][]byte) ([][]byte, error)
+
+	GetTrieNodesIntercept func([]common.Hash, [][]byte) ([][]byte, error)
+
+	// expectHeal is set if the synced trie is expected to be healed, in which case
+	// the snapshot is expected to be scheduled for regeneration instead of being
+	// consistent with the trie.
+	expectHeal bool
 }
 
 func testSync(t *testing.T, test syncTest) {
@@ -50,10 +58,12 @@ func testSync(t *testing.T, test syncTest) {
 	clientDB, serverTrieDB, root := test.prepareForTest(t)
 	leafsRequestHandler := handlers.NewLeafsRequestHandler(serverTrieDB, nil, message.Codec, handlerstats.NewNoopHandlerStats())
 	codeRequestHandler := handlers.NewCodeRequestHandler(serverTrieDB.DiskDB(), message.Codec, handlerstats.NewNoopHandlerStats())
-	mockClient := statesyncclient.NewMockClient(message.Codec, leafsRequestHandler, codeRequestHandler, nil)
+	trieNodesRequestHandler := handlers.NewTrieNodesRequestHandler(serverTrieDB, message.Codec, handlerstats.NewNoopHandlerStats())
+	mockClient := statesyncclient.NewMockClient(message.Codec, leafsRequestHandler, codeRequestHandler, nil, trieNodesRequestHandler)
 	// Set intercept functions for the mock client
 	mockClient.GetLeafsIntercept = test.GetLeafsIntercept
 	mockClient.GetCodeIntercept = test.GetCodeIntercept
+	mockClient.GetTrieNodesIntercept = test.GetTrieNodesIntercept
 
 	s, err := NewStateSyncer(&StateSyncerConfig{
 		Client:                   mockClient,
@@ -72,7 +82,14 @@ func testSync(t *testing.T, test syncTest) {
 	if test.expectedError != nil {
 		return
 	}
+	if progress := s.Progress(); progress.TriesSynced == 0 || progress.TriesRemaining != 0 {
+		t.Fatalf("unexpected progress after sync: %+v", progress)
+	}
 
+	if test.expectHeal {
+		assertHealedTrieConsistency(t, root, serverTrieDB, trie.NewDatabase(clientDB))
+		return
+	}
 	assertDBConsistency(t, root, serverTrieDB, trie.NewDatabase(clientDB))
 }
 
@@ -120,7 +137,7 @@ func TestSimpleSyncCases(t *testing.T) {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := trie.FillAccounts(t, serverTrieDB, common.Hash{}, numAccounts, nil)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with code": {
@@ -140,14 +157,14 @@ func TestSimpleSyncCases(t *testing.T) {
 					}
 					return account
 				})
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with code and storage": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root := fillAccountsWithStorage(t, serverTrieDB, common.Hash{}, numAccounts)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with storage": {
@@ -160,21 +177,21 @@ func TestSimpleSyncCases(t *testing.T) {
 
 					return account
 				})
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"accounts with overlapping storage": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, numAccounts, 3)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 		},
 		"failed to fetch leafs": {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root, _ := trie.FillAccounts(t, serverTrieDB, common.Hash{}, numAccountsSmall, nil)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 			GetLeafsIntercept: func(_ message.LeafsRequest, _ message.LeafsResponse) (message.LeafsResponse, error) {
 				return message.LeafsResponse{}, clientErr
@@ -185,7 +202,7 @@ func TestSimpleSyncCases(t *testing.T) {
 			prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
 				serverTrieDB := trie.NewDatabase(memorydb.New())
 				root := fillAccountsWithStorage(t, serverTrieDB, common.Hash{}, numAccountsSmall)
-				return memorydb.New(), serverTrieDB, root
+				return rawdb.NewMemoryDatabase(), serverTrieDB, root
 			},
 			GetCodeIntercept: func(_ []common.Hash, _ [][]byte) ([][]byte, error) {
 				return nil, clientErr
@@ -210,7 +227,7 @@ func TestCancelSync(t *testing.T) {
 	testSync(t, syncTest{
 		ctx: ctx,
 		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
-			return memorydb.New(), serverTrieDB, root
+			return rawdb.NewMemoryDatabase(), serverTrieDB, root
 		},
 		expectedError: context.Canceled,
 		GetLeafsIntercept: func(_ message.LeafsRequest, lr message.LeafsResponse) (message.LeafsResponse, error) {
@@ -244,7 +261,7 @@ func (i *interruptLeafsIntercept) getLeafsIntercept(request message.LeafsRequest
 func TestResumeSyncAccountsTrieInterrupted(t *testing.T) {
 	serverTrieDB := trie.NewDatabase(memorydb.New())
 	root, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 2000, 3)
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	intercept := &interruptLeafsIntercept{
 		root:           root,
 		interruptAfter: 1,
@@ -277,7 +294,7 @@ func TestResumeSyncLargeStorageTrieInterrupted(t *testing.T) {
 		}
 		return account
 	})
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	intercept := &interruptLeafsIntercept{
 		root:           largeStorageRoot,
 		interruptAfter: 1,
@@ -315,7 +332,7 @@ func TestResumeSyncToNewRootAfterLargeStorageTrieInterrupted(t *testing.T) {
 		}
 		return account
 	})
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	intercept := &interruptLeafsIntercept{
 		root:           largeStorageRoot1,
 		interruptAfter: 1,
@@ -348,7 +365,7 @@ func TestResumeSyncLargeStorageTrieWithConsecutiveDuplicatesInterrupted(t *testi
 		}
 		return account
 	})
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	intercept := &interruptLeafsIntercept{
 		root:           largeStorageRoot,
 		interruptAfter: 1,
@@ -378,7 +395,7 @@ func TestResumeSyncLargeStorageTrieWithSpreadOutDuplicatesInterrupted(t *testing
 		}
 		return account
 	})
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	intercept := &interruptLeafsIntercept{
 		root:           largeStorageRoot,
 		interruptAfter: 1,
@@ -472,7 +489,7 @@ func TestResyncNewRootAfterDeletes(t *testing.T) {
 
 func testSyncerSyncsToNewRoot(t *testing.T, deleteBetweenSyncs func(*testing.T, common.Hash, *trie.Database)) {
 	rand.Seed(1)
-	clientDB := memorydb.New()
+	clientDB := rawdb.NewMemoryDatabase()
 	serverTrieDB := trie.NewDatabase(memorydb.New())
 
 	root1, _ := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 1000, 3)
@@ -503,3 +520,121 @@ func testSyncerSyncsToNewRoot(t *testing.T, deleteBetweenSyncs func(*testing.T,
 		deleteBetweenSyncs(t, root1, trie.NewDatabase(clientDB))
 	})
 }
+
+func TestResumeSyncToNewRootHealsTrie(t *testing.T) {
+	rand.Seed(1)
+	serverTrieDB := trie.NewDatabase(memorydb.New())
+	root1, accounts := FillAccountsWithOverlappingStorage(t, serverTrieDB, common.Hash{}, 2000, 3)
+
+	// Modify, delete and add accounts so the leafs synced from [root1] are stale.
+	tr, err := trie.NewStateTrie(common.Hash{}, root1, serverTrieDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := 0
+	for key, acc := range accounts {
+		i++
+		switch i % 4 {
+		case 0:
+			if err := tr.TryDelete(key.Address[:]); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		case 1:
+			acc.Balance = new(big.Int).Add(acc.Balance, common.Big1)
+		case 2:
+			codeBytes := make([]byte, 256)
+			if _, err := rand.Read(codeBytes); err != nil {
+				t.Fatal(err)
+			}
+			codeHash := crypto.Keccak256Hash(codeBytes)
+			rawdb.WriteCode(serverTrieDB.DiskDB(), codeHash, codeBytes)
+			acc.CodeHash = codeHash[:]
+			acc.Root, _, _ = trie.GenerateTrie(t, serverTrieDB, 16, common.HashLength)
+		default:
+			continue
+		}
+		accBytes, err := rlp.EncodeToBytes(acc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tr.TryUpdate(key.Address[:], accBytes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root2, nodes, err := tr.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := serverTrieDB.Update(trie.NewWithNodeSet(nodes)); err != nil {
+		t.Fatal(err)
+	}
+	if err := serverTrieDB.Commit(root2, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	root2 = fillAccountsWithStorage(t, serverTrieDB, root2, 100)
+
+	clientDB := rawdb.NewMemoryDatabase()
+	intercept := &interruptLeafsIntercept{
+		root:           root1,
+		interruptAfter: 1,
+	}
+	testSync(t, syncTest{
+		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
+			return clientDB, serverTrieDB, root1
+		},
+		expectedError:     errInterrupted,
+		GetLeafsIntercept: intercept.getLeafsIntercept,
+	})
+
+	// Sync to [root2] without wiping the snapshot, as when the sync target moves to
+	// a newer summary.
+	var nodesHealed uint32
+	testSync(t, syncTest{
+		prepareForTest: func(t *testing.T) (ethdb.Database, *trie.Database, common.Hash) {
+			return clientDB, serverTrieDB, root2
+		},
+		GetTrieNodesIntercept: func(hashes []common.Hash, nodes [][]byte) ([][]byte, error) {
+			atomic.AddUint32(&nodesHealed, uint32(len(nodes)))
+			return nodes, nil
+		},
+		expectHeal: true,
+	})
+	assert.NotZero(t, atomic.LoadUint32(&nodesHealed))
+}
+
+// assertHealedTrieConsistency asserts the state trie at [root], its storage tries and code
+// are consistent between [serverTrieDB] and [clientTrieDB], and that the snapshot of
+// [clientTrieDB] is scheduled for regeneration.
+func assertHealedTrieConsistency(t testing.TB, root common.Hash, serverTrieDB, clientTrieDB *trie.Database) {
+	clientDB := clientTrieDB.DiskDB()
+	trie.AssertTrieConsistency(t, root, serverTrieDB, clientTrieDB, func(key, val []byte) error {
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(val, &acc); err != nil {
+			return err
+		}
+		if !bytes.Equal(acc.CodeHash, types.EmptyCodeHash[:]) {
+			code := rawdb.ReadCode(clientDB, common.BytesToHash(acc.CodeHash))
+			assert.Equal(t, common.BytesToHash(acc.CodeHash), crypto.Keccak256Hash(code))
+		}
+		if acc.Root != types.EmptyRootHash {
+			trie.AssertTrieConsistency(t, acc.Root, serverTrieDB, clientTrieDB, nil)
+		}
+		return nil
+	})
+
+	var generator struct {
+		Wiping   bool
+		Done     bool
+		Marker   []byte
+		Accounts uint64
+		Slots    uint64
+		Storage  uint64
+	}
+	if err := rlp.DecodeBytes(rawdb.ReadSnapshotGenerator(clientDB), &generator); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, generator.Wiping)
+	assert.False(t, generator.Done)
+	assert.Empty(t, generator.Marker)
+}
@@ -0,0 +1,181 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	syncclient "github.com/ava-labs/subnet-evm/sync/client"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// healTask is a batch of trie nodes or contract code requested by the healer.
+type healTask struct {
+	paths  []string      // paths of the requested trie nodes, nil for code
+	hashes []common.Hash // hashes of the requested trie nodes or code
+	data   [][]byte      // response from the network
+	err    error
+}
+
+func (h *healTask) isCode() bool { return h.paths == nil }
+
+// trieHealer fills in the parts of the state trie at [root] that are missing from [db].
+// The leafs of the account trie may have been synced from an older root when the sync
+// target moves to a newer summary, so the trie hashed from them does not match [root].
+// The healer walks the trie from [root] by hash, skipping subtries that are already on
+// disk, and fetches the missing trie nodes and code from peers with up to [numThreads]
+// requests in flight.
+// Nodes are committed to disk only after all of their children, so an interrupted heal
+// can be resumed by healing again from [root].
+type trieHealer struct {
+	db         ethdb.Database
+	client     syncclient.Client
+	root       common.Hash
+	batchSize  int
+	numThreads int
+	sched      *trie.Sync
+
+	nodesHealed, codeHealed int
+}
+
+func newTrieHealer(db ethdb.Database, client syncclient.Client, root common.Hash, batchSize int, numThreads int) *trieHealer {
+	return &trieHealer{
+		db:         db,
+		client:     client,
+		root:       root,
+		batchSize:  batchSize,
+		numThreads: numThreads,
+		sched:      state.NewStateSync(root, db, nil),
+	}
+}
+
+// heal fetches the missing trie nodes and code until the state trie at [h.root]
+// is complete, or returns an error if one occurred or [ctx] expired.
+func (h *trieHealer) heal(ctx context.Context) error {
+	log.Info("healing state trie", "root", h.root)
+
+	var (
+		tasks   = make(chan *healTask)
+		results = make(chan *healTask, h.numThreads) // at most [numThreads] tasks are in flight
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < h.numThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				if task.isCode() {
+					task.data, task.err = h.client.GetCode(ctx, task.hashes)
+				} else {
+					task.data, task.err = h.client.GetTrieNodes(ctx, task.hashes)
+				}
+				results <- task
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(tasks)
+
+	var (
+		batch    = h.db.NewBatch()
+		queued   []*healTask
+		inFlight int
+	)
+	for {
+		if len(queued) == 0 && inFlight < h.numThreads {
+			queued = h.nextTasks()
+		}
+		if len(queued) == 0 && inFlight == 0 {
+			break
+		}
+
+		// Only attempt to send a task if one is queued.
+		var (
+			send chan *healTask
+			next *healTask
+		)
+		if len(queued) > 0 {
+			send, next = tasks, queued[0]
+		}
+		select {
+		case send <- next:
+			queued = queued[1:]
+			inFlight++
+		case task := <-results:
+			inFlight--
+			if err := h.process(task); err != nil {
+				return err
+			}
+			if err := h.sched.Commit(batch); err != nil {
+				return err
+			}
+			if batch.ValueSize() > h.batchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if pending := h.sched.Pending(); pending != 0 {
+		return fmt.Errorf("state trie heal finished with %d pending requests", pending)
+	}
+	if err := h.sched.Commit(batch); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("healed state trie", "root", h.root, "nodes", h.nodesHealed, "code", h.codeHealed)
+	return nil
+}
+
+// nextTasks returns the tasks for the next batch of missing trie nodes and code.
+func (h *trieHealer) nextTasks() []*healTask {
+	paths, nodeHashes, codeHashes := h.sched.Missing(message.MaxTrieNodesPerRequest)
+
+	var tasks []*healTask
+	if len(nodeHashes) > 0 {
+		tasks = append(tasks, &healTask{paths: paths, hashes: nodeHashes})
+	}
+	for len(codeHashes) > 0 {
+		n := message.MaxCodeHashesPerRequest
+		if n > len(codeHashes) {
+			n = len(codeHashes)
+		}
+		tasks = append(tasks, &healTask{hashes: codeHashes[:n]})
+		codeHashes = codeHashes[n:]
+	}
+	return tasks
+}
+
+// process delivers the response to [task] to the scheduler.
+func (h *trieHealer) process(task *healTask) error {
+	if task.err != nil {
+		return task.err
+	}
+	for i, data := range task.data {
+		if task.isCode() {
+			if err := h.sched.ProcessCode(trie.CodeSyncResult{Hash: task.hashes[i], Data: data}); err != nil {
+				return fmt.Errorf("failed to process code %s: %w", task.hashes[i], err)
+			}
+			h.codeHealed++
+		} else {
+			if err := h.sched.ProcessNode(trie.NodeSyncResult{Path: task.paths[i], Data: data}); err != nil {
+				return fmt.Errorf("failed to process trie node %s: %w", task.hashes[i], err)
+			}
+			h.nodesHealed++
+		}
+	}
+	return nil
+}
@@ -23,19 +23,18 @@ func NewTrieQueue(db ethdb.Database) *trieQueue {
 	}
 }
 
-// clearIfRootDoesNotMatch clears progress and segment markers if
-// the persisted root does not match the root we are syncing to.
-func (t *trieQueue) clearIfRootDoesNotMatch(root common.Hash) error {
+// updateRoot persists [root] as the root we are syncing to. If the persisted
+// root does not match [root], the segment markers of the main trie are moved
+// to [root] so the leafs synced from the persisted root are kept (the trie is
+// healed once all leafs have been synced). Storage tries are keyed by their own
+// roots, so their progress markers remain valid.
+func (t *trieQueue) updateRoot(root common.Hash) error {
 	persistedRoot, err := rawdb.ReadSyncRoot(t.db)
 	if err != nil {
 		return err
 	}
 	if persistedRoot != (common.Hash{}) && persistedRoot != root {
-		// if not resuming, clear all progress markers
-		if err := rawdb.ClearAllSyncStorageTries(t.db); err != nil {
-			return err
-		}
-		if err := rawdb.ClearAllSyncSegments(t.db); err != nil {
+		if err := t.moveSegments(persistedRoot, root); err != nil {
 			return err
 		}
 	}
@@ -43,6 +42,27 @@ func (t *trieQueue) clearIfRootDoesNotMatch(root common.Hash) error {
 	return rawdb.WriteSyncRoot(t.db, root)
 }
 
+// moveSegments moves the segment markers of the trie at [from] to [to].
+func (t *trieQueue) moveSegments(from, to common.Hash) error {
+	it := rawdb.NewSyncSegmentsIterator(t.db, from)
+	defer it.Release()
+
+	batch := t.db.NewBatch()
+	for it.Next() {
+		_, segmentStart := rawdb.UnpackSyncSegmentKey(it.Key())
+		if err := rawdb.WriteSyncSegment(batch, to, segmentStart); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	return rawdb.ClearSyncSegments(t.db, from)
+}
+
 // RegisterStorageTrie is called by the main trie's leaf handling callbacks
 // It adds a key built as [syncProgressPrefix+root+account] to the database.
 // getNextTrie iterates this prefix to find storage tries and accounts
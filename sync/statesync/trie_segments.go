@@ -57,6 +57,10 @@ type trieToSync struct {
 	// tries.
 	task       syncTask
 	isMainTrie bool
+
+	// requiresHeal is set if the main trie hashed from the synced leafs
+	// does not match its root, see [trieHealer].
+	requiresHeal bool
 }
 
 // NewTrieToSync initializes a trieToSync and restores any previously started segments.
@@ -215,7 +219,13 @@ func (t *trieToSync) segmentFinished(idx int) error {
 		return err
 	}
 	if actualRoot != t.root {
-		return fmt.Errorf("unexpected root, expected=%s, actual=%s, account=%s", t.root, actualRoot, t.account)
+		if !t.isMainTrie {
+			return fmt.Errorf("unexpected root, expected=%s, actual=%s, account=%s", t.root, actualRoot, t.account)
+		}
+		// The leafs of the main trie may have been synced from a previous root,
+		// in which case the trie is healed once all leafs have been synced.
+		log.Info("statesync: main trie does not match root, will heal", "expected", t.root, "actual", actualRoot)
+		t.requiresHeal = true
 	}
 	if !t.isMainTrie {
 		// the batch containing the main trie's root will be committed on
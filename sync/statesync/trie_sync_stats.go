@@ -20,6 +20,13 @@ const (
 	epsilon          = 1e-6 // added to avoid division by 0
 )
 
+// Progress is the progress of a state sync.
+type Progress struct {
+	LeafsSynced    uint64 `json:"leafsSynced"`    // number of leafs synced across all tries
+	TriesSynced    int    `json:"triesSynced"`    // number of tries synced, including the account trie
+	TriesRemaining int    `json:"triesRemaining"` // number of storage tries left to sync, known once the account trie is synced
+}
+
 // trieSyncStats keeps track of the total number of leafs and tries
 // completed during a sync.
 type trieSyncStats struct {
@@ -32,6 +39,7 @@ type trieSyncStats struct {
 	triesSynced      int
 	triesStartTime   time.Time
 	leafsSinceUpdate uint64
+	leafsSynced      uint64
 
 	remainingLeafs map[*trieSegment]uint64
 
@@ -67,6 +75,7 @@ func (t *trieSyncStats) incLeafs(segment *trieSegment, count uint64, remaining u
 
 	t.totalLeafs.Inc(int64(count))
 	t.leafsSinceUpdate += count
+	t.leafsSynced += count
 	t.remainingLeafs[segment] = remaining
 
 	now := time.Now()
@@ -147,6 +156,24 @@ func (t *trieSyncStats) setTriesRemaining(triesRemaining int) {
 	t.triesStartTime = time.Now()
 }
 
+// progress takes a lock and returns the progress of the sync.
+func (t *trieSyncStats) progress() Progress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The account trie is marked as done after the storage tries it refers to, which
+	// are the only ones counted in [triesRemaining].
+	triesRemaining := t.triesRemaining
+	if triesRemaining < 0 {
+		triesRemaining = 0
+	}
+	return Progress{
+		LeafsSynced:    t.leafsSynced,
+		TriesSynced:    t.triesSynced,
+		TriesRemaining: triesRemaining,
+	}
+}
+
 // roundETA rounds [d] to a minute and chops off the "0s" suffix
 // returns "<1m" if [d] rounds to 0 minutes.
 func roundETA(d time.Duration) string {
@@ -0,0 +1,54 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package tracing records OpenTelemetry spans of the block lifecycle (building,
+// verification and acceptance), of RPC calls and of the stateful precompile calls
+// they execute, so that the latency can be followed across components.
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "subnet-evm"
+
+var (
+	enabled uint32
+	tracer  atomic.Value // holds a tracerHolder
+)
+
+// tracerHolder wraps the tracer, as an atomic.Value must always hold the same concrete type.
+type tracerHolder struct {
+	trace.Tracer
+}
+
+func init() {
+	SetTracer(nil)
+}
+
+// SetTracer sets the tracer recording the spans. Spans are not recorded if [t] is nil.
+func SetTracer(t trace.Tracer) {
+	if t == nil {
+		atomic.StoreUint32(&enabled, 0)
+		tracer.Store(tracerHolder{trace.NewNoopTracerProvider().Tracer(tracerName)})
+		return
+	}
+	tracer.Store(tracerHolder{t})
+	atomic.StoreUint32(&enabled, 1)
+}
+
+// Enabled returns whether spans are recorded. Callers on hot paths can check it to skip
+// building the attributes of their spans.
+func Enabled() bool {
+	return atomic.LoadUint32(&enabled) == 1
+}
+
+// Start starts a span named [name] with [attrs], as a child of the span of [ctx] if any.
+// The returned context holds the new span, which must be ended by the caller.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Load().(tracerHolder).Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -48,6 +48,10 @@ const (
 	cacheStatsUpdateFrequency = 1000 // update trie cache stats once per 1000 ops
 )
 
+// errPathSchemeUpdate is returned when nodes are inserted into a database using
+// the path scheme without the state transition that wrote them.
+var errPathSchemeUpdate = errors.New("trie nodes must be inserted with UpdateLayer by the path scheme")
+
 var (
 	memcacheCleanHitMeter   = metrics.NewRegisteredMeter("trie/memcache/clean/hit", nil)
 	memcacheCleanMissMeter  = metrics.NewRegisteredMeter("trie/memcache/clean/miss", nil)
@@ -83,10 +87,16 @@ var (
 // the disk database. The aim is to accumulate trie writes in-memory and only
 // periodically flush a couple tries to disk, garbage collecting the remainder.
 //
+// With the path scheme, the trie nodes written by each state transition are
+// instead held in a diff layer until they are committed to disk, where a single
+// version of the state is kept (see layerTree).
+//
 // The trie Database is thread-safe in its mutations and is thread-safe in providing individual,
 // independent node access.
 type Database struct {
 	diskdb ethdb.KeyValueStore // Persistent storage for matured trie nodes
+	scheme string              // Scheme used to store trie nodes on disk
+	layers *layerTree          // Diff layers of the path scheme (nil with the hash scheme)
 
 	cleans  *utils.MeteredCache         // GC friendly memory cache of clean node RLPs
 	dirties map[common.Hash]*cachedNode // Data and references relationships of dirty trie nodes
@@ -105,6 +115,8 @@ type Database struct {
 	childrenSize common.StorageSize // Storage size of the external children tracking
 	preimages    *preimageStore     // The store for caching preimages
 
+	onFlush func(hashes []common.Hash) // Invoked with the hashes of the nodes about to be flushed to disk
+
 	lock sync.RWMutex
 }
 
@@ -287,6 +299,7 @@ type Config struct {
 	Preimages   bool   // Flag whether the preimage of trie key is recorded
 	Journal     string // File location to load trie clean cache from
 	StatsPrefix string // Prefix for cache stats (disabled if empty)
+	Scheme      string // Scheme used to store trie nodes on disk (hash scheme if empty)
 }
 
 // NewDatabase creates a new trie database to store ephemeral trie content before
@@ -308,17 +321,30 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 	if config != nil && config.Preimages {
 		preimage = newPreimageStore(diskdb)
 	}
+	scheme := rawdb.HashScheme
+	if config != nil && config.Scheme != "" {
+		scheme = config.Scheme
+	}
 	db := &Database{
 		diskdb: diskdb,
+		scheme: scheme,
 		cleans: cleans,
 		dirties: map[common.Hash]*cachedNode{{}: {
 			children: make(map[common.Hash]uint16),
 		}},
 		preimages: preimage,
 	}
+	if scheme == rawdb.PathScheme {
+		db.layers = newLayerTree(diskdb)
+	}
 	return db
 }
 
+// Scheme returns the scheme used to store trie nodes on disk.
+func (db *Database) Scheme() string {
+	return db.scheme
+}
+
 // DiskDB retrieves the persistent storage backing the trie database.
 func (db *Database) DiskDB() ethdb.KeyValueStore {
 	return db.diskdb
@@ -359,10 +385,21 @@ func (db *Database) insert(hash common.Hash, size int, node node) {
 // RawNode retrieves an encoded cached trie node from memory. If it cannot be found
 // cached, the method queries the persistent database for the content. This function
 // will not return the metaroot.
+//
+// With the path scheme, nodes on disk cannot be looked up by hash, so only the
+// nodes held in memory are returned.
 func (db *Database) RawNode(h common.Hash) ([]byte, error) {
 	if h == (common.Hash{}) {
 		return nil, errors.New("not found")
 	}
+	if db.layers != nil {
+		db.lock.RLock()
+		defer db.lock.RUnlock()
+		if entry := db.layers.index[h]; entry != nil {
+			return entry.blob, nil
+		}
+		return nil, errors.New("not found")
+	}
 	enc, cn, err := db.node(h)
 	if err != nil {
 		return nil, err
@@ -376,6 +413,12 @@ func (db *Database) RawNode(h common.Hash) ([]byte, error) {
 // EncodedNode returns a formatted [node] when given a node hash. If no node
 // exists, nil is returned. This function will return the metaroot.
 func (db *Database) EncodedNode(h common.Hash) node {
+	if db.layers != nil {
+		if enc, err := db.RawNode(h); err == nil {
+			return mustDecodeNodeUnsafe(h[:], enc)
+		}
+		return nil
+	}
 	enc, cn, err := db.node(h)
 	if err != nil {
 		return nil
@@ -386,6 +429,32 @@ func (db *Database) EncodedNode(h common.Hash) node {
 	return cn.obj(h)
 }
 
+// readNode retrieves the trie node with the provided hash at [path] of the trie
+// owned by [owner], or nil if it is unavailable. The owner and path are only
+// used to look up nodes on disk with the path scheme.
+func (db *Database) readNode(owner common.Hash, path []byte, hash common.Hash) node {
+	if db.layers == nil {
+		return db.EncodedNode(hash)
+	}
+	if enc := db.pathNode(owner, path, hash); len(enc) > 0 {
+		return mustDecodeNodeUnsafe(hash[:], enc)
+	}
+	return nil
+}
+
+// readBlob retrieves the encoded trie node with the provided hash at [path] of
+// the trie owned by [owner]. The owner and path are only used to look up nodes
+// on disk with the path scheme.
+func (db *Database) readBlob(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	if db.layers == nil {
+		return db.RawNode(hash)
+	}
+	if enc := db.pathNode(owner, path, hash); len(enc) > 0 {
+		return enc, nil
+	}
+	return nil, errors.New("not found")
+}
+
 // node retrieves an encoded cached trie node from memory. If it cannot be found
 // cached, the method queries the persistent database for the content.
 //
@@ -443,6 +512,13 @@ func (db *Database) Nodes() []common.Hash {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
+	if db.layers != nil {
+		hashes := make([]common.Hash, 0, len(db.layers.index))
+		for hash := range db.layers.index {
+			hashes = append(hashes, hash)
+		}
+		return hashes
+	}
 	var hashes = make([]common.Hash, 0, len(db.dirties))
 	for hash := range db.dirties {
 		if hash != (common.Hash{}) { // Special case for "root" references/nodes
@@ -456,10 +532,19 @@ func (db *Database) Nodes() []common.Hash {
 // This function is used to add reference between internal trie node
 // and external node(e.g. storage trie root), all internal trie nodes
 // are referenced together by database itself.
+//
+// With the path scheme, only references to state roots (from the metaroot) are
+// tracked.
 func (db *Database) Reference(child common.Hash, parent common.Hash) {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
+	if db.layers != nil {
+		if layer, ok := db.layers.layers[child]; ok && parent == (common.Hash{}) {
+			layer.refs++
+		}
+		return
+	}
 	db.reference(child, parent)
 }
 
@@ -493,6 +578,10 @@ func (db *Database) Dereference(root common.Hash) {
 
 	db.lock.Lock()
 	defer db.lock.Unlock()
+	if db.layers != nil {
+		db.layers.dereference(root)
+		return
+	}
 	nodes, storage, start := len(db.dirties), db.dirtiesSize, time.Now()
 	db.dereference(root, common.Hash{})
 
@@ -569,10 +658,28 @@ type flushItem struct {
 	rlp  []byte
 }
 
+// SetOnFlush registers [onFlush] to be invoked with the hashes of the trie nodes
+// that [Cap] and [Commit] are about to write to disk. The nodes are only written
+// after [onFlush] returns.
+func (db *Database) SetOnFlush(onFlush func(hashes []common.Hash)) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.onFlush = onFlush
+}
+
 // writeFlushItems writes all items in [toFlush] to disk in batches of
-// [ethdb.IdealBatchSize]. This function does not access any variables inside
-// of [Database] and does not need to be synchronized.
-func (db *Database) writeFlushItems(toFlush []*flushItem) error {
+// [ethdb.IdealBatchSize], after passing their hashes to [onFlush] if it is
+// non-nil. This function does not access any variables inside of [Database]
+// and does not need to be synchronized.
+func (db *Database) writeFlushItems(toFlush []*flushItem, onFlush func(hashes []common.Hash)) error {
+	if onFlush != nil && len(toFlush) > 0 {
+		hashes := make([]common.Hash, len(toFlush))
+		for i, item := range toFlush {
+			hashes[i] = item.hash
+		}
+		onFlush(hashes)
+	}
 	batch := db.diskdb.NewBatch()
 	for _, item := range toFlush {
 		rlp := item.node.rlp()
@@ -600,7 +707,16 @@ func (db *Database) writeFlushItems(toFlush []*flushItem) error {
 
 // Cap iteratively flushes old but still referenced trie nodes until the total
 // memory usage goes below the given threshold.
+//
+// With the path scheme, the diff layers can only be written to disk by Commit,
+// so Cap only flushes the preimages.
 func (db *Database) Cap(limit common.StorageSize) error {
+	if db.layers != nil {
+		if db.preimages != nil {
+			return db.preimages.commit(false)
+		}
+		return nil
+	}
 	start := time.Now()
 	// If the preimage cache got large enough, push to disk. If it's still small
 	// leave for later to deduplicate writes.
@@ -645,11 +761,12 @@ func (db *Database) Cap(limit common.StorageSize) error {
 		}
 		oldest = node.flushNext
 	}
+	onFlush := db.onFlush
 	db.lock.RUnlock()
 	lockTime := time.Since(lockStart)
 
 	// Write nodes to disk
-	if err := db.writeFlushItems(toFlush); err != nil {
+	if err := db.writeFlushItems(toFlush, onFlush); err != nil {
 		return err
 	}
 
@@ -686,7 +803,13 @@ func (db *Database) Cap(limit common.StorageSize) error {
 // Commit iterates over all the children of a particular node, writes them out
 // to disk, forcefully tearing down all references in both directions. As a side
 // effect, all pre-images accumulated up to this point are also written.
+//
+// With the path scheme, the state at [node] replaces the state on disk instead
+// (see commitLayers).
 func (db *Database) Commit(node common.Hash, report bool, callback func(common.Hash)) error {
+	if db.layers != nil {
+		return db.commitLayers(node, report, callback)
+	}
 	start := time.Now()
 	if db.preimages != nil {
 		if err := db.preimages.commit(true); err != nil {
@@ -705,11 +828,12 @@ func (db *Database) Commit(node common.Hash, report bool, callback func(common.H
 		log.Error("Failed to commit trie from trie database", "err", err)
 		return err
 	}
+	onFlush := db.onFlush
 	db.lock.RUnlock()
 	lockTime := time.Since(lockStart)
 
 	// Write nodes to disk
-	if err := db.writeFlushItems(toFlush); err != nil {
+	if err := db.writeFlushItems(toFlush, onFlush); err != nil {
 		return err
 	}
 
@@ -820,6 +944,9 @@ func (db *Database) removeFromDirties(hash common.Hash, rlp []byte) {
 // Update inserts the dirty nodes in provided nodeset into database and
 // links the account trie with multiple storage tries if necessary.
 func (db *Database) Update(nodes *MergedNodeSet) error {
+	if db.layers != nil {
+		return errPathSchemeUpdate
+	}
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
@@ -830,6 +957,9 @@ func (db *Database) Update(nodes *MergedNodeSet) error {
 // database and links the account trie with multiple storage tries if necessary,
 // then adds a reference [from] root to the metaroot while holding the db's lock.
 func (db *Database) UpdateAndReferenceRoot(nodes *MergedNodeSet, root common.Hash) error {
+	if db.layers != nil {
+		return errPathSchemeUpdate
+	}
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
@@ -840,6 +970,23 @@ func (db *Database) UpdateAndReferenceRoot(nodes *MergedNodeSet, root common.Has
 	return nil
 }
 
+// UpdateLayer inserts the dirty nodes in provided nodeset, written by the state
+// transition from [parent] to [root], into the database. With the path scheme,
+// the nodes are held in a new diff layer, which is referenced if [reference] is
+// true. With the hash scheme, this is the same as Update or UpdateAndReferenceRoot.
+func (db *Database) UpdateLayer(root common.Hash, parent common.Hash, nodes *MergedNodeSet, reference bool) error {
+	if db.layers == nil {
+		if reference {
+			return db.UpdateAndReferenceRoot(nodes, root)
+		}
+		return db.Update(nodes)
+	}
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.updateLayer(root, parent, nodes, reference)
+}
+
 func (db *Database) update(nodes *MergedNodeSet) error {
 	// Insert dirty nodes into the database. In the same tree, it must be
 	// ensured that children are inserted first, then parent so that children
@@ -864,6 +1011,10 @@ func (db *Database) update(nodes *MergedNodeSet) error {
 			if !ok {
 				return fmt.Errorf("missing node %x %v", owner, path)
 			}
+			// Deleted nodes are only tracked by the path scheme
+			if n.isDeleted() {
+				continue
+			}
 			db.insert(n.hash, int(n.size), n.node)
 		}
 	}
@@ -891,12 +1042,15 @@ func (db *Database) Size() (common.StorageSize, common.StorageSize) {
 	// counted.
 	db.lock.RLock()
 	defer db.lock.RUnlock()
-	var metadataSize = common.StorageSize((len(db.dirties) - 1) * cachedNodeSize)
-	var metarootRefs = common.StorageSize(len(db.dirties[common.Hash{}].children) * (common.HashLength + 2))
 	var preimageSize common.StorageSize
 	if db.preimages != nil {
 		preimageSize = db.preimages.size()
 	}
+	if db.layers != nil {
+		return db.layers.size, preimageSize
+	}
+	var metadataSize = common.StorageSize((len(db.dirties) - 1) * cachedNodeSize)
+	var metarootRefs = common.StorageSize(len(db.dirties[common.Hash{}].children) * (common.HashLength + 2))
 	return db.dirtiesSize + db.childrenSize + metadataSize - metarootRefs, preimageSize
 }
 
@@ -27,6 +27,7 @@
 package trie
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
@@ -41,3 +42,58 @@ func TestDatabaseMetarootFetch(t *testing.T) {
 		t.Fatalf("metaroot retrieval succeeded")
 	}
 }
+
+// Tests that the trie database reports the nodes it flushes before writing them
+// to disk.
+func TestDatabaseOnFlush(t *testing.T) {
+	diskdb := memorydb.New()
+	db := NewDatabase(diskdb)
+	trie := NewEmpty(db)
+	for i := byte(0); i < 16; i++ {
+		trie.Update([]byte{i, i}, bytes.Repeat([]byte{i}, common.HashLength))
+	}
+	root, nodes, err := trie.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+
+	var flushed []common.Hash
+	db.SetOnFlush(func(hashes []common.Hash) {
+		for _, hash := range hashes {
+			if ok, _ := diskdb.Has(hash.Bytes()); ok {
+				t.Fatalf("node %x written before it was reported", hash)
+			}
+		}
+		flushed = append(flushed, hashes...)
+	})
+	if err := db.Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit database: %v", err)
+	}
+	if len(flushed) == 0 || len(flushed) != diskdb.Len() {
+		t.Fatalf("flushed node count mismatch: reported %d, written %d", len(flushed), diskdb.Len())
+	}
+	for _, hash := range flushed {
+		if ok, _ := diskdb.Has(hash.Bytes()); !ok {
+			t.Fatalf("reported node %x missing from disk", hash)
+		}
+	}
+
+	// Nodes are no longer reported once the callback is removed.
+	db.SetOnFlush(nil)
+	trie, _ = New(common.Hash{}, root, db)
+	trie.Update([]byte{0xff}, bytes.Repeat([]byte{0xff}, common.HashLength))
+	root, nodes, _ = trie.Commit(false)
+	if err := db.Update(NewWithNodeSet(nodes)); err != nil {
+		t.Fatalf("failed to update database: %v", err)
+	}
+	reported := len(flushed)
+	if err := db.Commit(root, false, nil); err != nil {
+		t.Fatalf("failed to commit database: %v", err)
+	}
+	if len(flushed) != reported {
+		t.Fatalf("nodes reported after removing callback")
+	}
+}
@@ -40,6 +40,12 @@ type memoryNode struct {
 	node node        // Cached collapsed trie node, or raw rlp data
 }
 
+// isDeleted returns true if the node is a marker of a node deleted from the
+// trie at its path.
+func (n *memoryNode) isDeleted() bool {
+	return n.hash == (common.Hash{})
+}
+
 // NodeSet contains all dirty nodes collected during the commit operation.
 // Each node is keyed by path. It's not thread-safe to use.
 type NodeSet struct {
@@ -61,10 +67,18 @@ func NewNodeSet(owner common.Hash) *NodeSet {
 
 // add caches node with provided path and node object.
 func (set *NodeSet) add(path string, node *memoryNode) {
-	set.paths = append(set.paths, path)
+	if _, present := set.nodes[path]; !present {
+		set.paths = append(set.paths, path)
+	}
 	set.nodes[path] = node
 }
 
+// markDeleted marks the node at the provided path as deleted from the trie,
+// unless a node is added at the path later on.
+func (set *NodeSet) markDeleted(path string) {
+	set.add(path, &memoryNode{})
+}
+
 // addLeaf caches the provided leaf node.
 func (set *NodeSet) addLeaf(node *leaf) {
 	set.leaves = append(set.leaves, node)
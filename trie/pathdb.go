@@ -0,0 +1,375 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trie
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	pathdbLayersGauge      = metrics.NewRegisteredGauge("trie/pathdb/layers", nil)
+	pathdbCommitTimeTimer  = metrics.NewRegisteredResettingTimer("trie/pathdb/commit/time", nil)
+	pathdbCommitNodesMeter = metrics.NewRegisteredMeter("trie/pathdb/commit/nodes", nil)
+	pathdbCommitSizeMeter  = metrics.NewRegisteredMeter("trie/pathdb/commit/size", nil)
+)
+
+// layerNode is a trie node written by a diff layer. A nil [blob] marks the node
+// at its path as deleted.
+type layerNode struct {
+	hash common.Hash
+	blob []byte
+}
+
+// diffLayer holds the trie nodes changed by the state transition from the state
+// at [parent] to the state at [root]. The layer is kept in memory until it is
+// committed to disk or dropped.
+type diffLayer struct {
+	root   common.Hash
+	parent common.Hash
+	nodes  map[common.Hash]map[string]*layerNode // owner -> path -> node
+	size   common.StorageSize
+
+	refs     int // External references to the state at [root]
+	children int // Number of layers on top of this one
+}
+
+// indexedNode is a trie node held by one or more diff layers.
+type indexedNode struct {
+	blob  []byte
+	count int
+}
+
+// layerTree is the in-memory part of the path scheme: a tree of diff layers on
+// top of the single version of the state persisted to disk, whose root is
+// [diskRoot]. As trie nodes are keyed by their path on disk, only the state at
+// [diskRoot] and the states of the diff layers descending from it can be read.
+//
+// The layerTree is protected by the lock of the Database holding it.
+type layerTree struct {
+	diskRoot common.Hash
+	layers   map[common.Hash]*diffLayer
+	index    map[common.Hash]*indexedNode // Trie nodes of all layers, keyed by hash
+	size     common.StorageSize
+}
+
+// newLayerTree creates a layerTree with no diff layers, deriving the root of
+// the state persisted to [db] from the root node of the account trie.
+func newLayerTree(db ethdb.KeyValueReader) *layerTree {
+	diskRoot := emptyRoot
+	if blob := rawdb.ReadAccountTrieNode(db, nil); len(blob) > 0 {
+		diskRoot = crypto.Keccak256Hash(blob)
+	}
+	return &layerTree{
+		diskRoot: diskRoot,
+		layers:   make(map[common.Hash]*diffLayer),
+		index:    make(map[common.Hash]*indexedNode),
+	}
+}
+
+// add inserts [layer] into the tree.
+func (tree *layerTree) add(layer *diffLayer) {
+	for _, subset := range layer.nodes {
+		for _, n := range subset {
+			if n.blob == nil {
+				continue
+			}
+			if entry, ok := tree.index[n.hash]; ok {
+				entry.count++
+			} else {
+				tree.index[n.hash] = &indexedNode{blob: n.blob, count: 1}
+			}
+		}
+	}
+	if parent, ok := tree.layers[layer.parent]; ok {
+		parent.children++
+	}
+	tree.layers[layer.root] = layer
+	tree.size += layer.size
+	pathdbLayersGauge.Update(int64(len(tree.layers)))
+}
+
+// remove drops [layer] from the tree if it has not been dropped already.
+func (tree *layerTree) remove(layer *diffLayer) {
+	if tree.layers[layer.root] != layer {
+		return
+	}
+	for _, subset := range layer.nodes {
+		for _, n := range subset {
+			if n.blob == nil {
+				continue
+			}
+			if entry := tree.index[n.hash]; entry.count > 1 {
+				entry.count--
+			} else {
+				delete(tree.index, n.hash)
+			}
+		}
+	}
+	if parent, ok := tree.layers[layer.parent]; ok {
+		parent.children--
+	}
+	delete(tree.layers, layer.root)
+	tree.size -= layer.size
+	pathdbLayersGauge.Update(int64(len(tree.layers)))
+}
+
+// dereference drops the reference to the state at [root], removing its layer
+// and any ancestors that are no longer needed.
+func (tree *layerTree) dereference(root common.Hash) {
+	layer, ok := tree.layers[root]
+	if !ok {
+		return
+	}
+	if layer.refs > 0 {
+		layer.refs--
+	}
+	for layer.refs == 0 && layer.children == 0 {
+		tree.remove(layer)
+		if layer, ok = tree.layers[layer.parent]; !ok {
+			return
+		}
+	}
+}
+
+// removeStale drops the layers that do not descend from the state persisted to
+// disk, whose trie nodes may have been overwritten.
+func (tree *layerTree) removeStale() {
+	live := map[common.Hash]bool{tree.diskRoot: true}
+	for root := range tree.layers {
+		var (
+			visited []common.Hash
+			hash    = root
+			isLive  bool
+		)
+		for {
+			if known, ok := live[hash]; ok {
+				isLive = known
+				break
+			}
+			layer, ok := tree.layers[hash]
+			if !ok {
+				break
+			}
+			visited = append(visited, hash)
+			hash = layer.parent
+		}
+		for _, hash := range visited {
+			live[hash] = isLive
+		}
+	}
+	for root, layer := range tree.layers {
+		if !live[root] {
+			tree.remove(layer)
+		}
+	}
+}
+
+// updateLayer adds a diff layer holding [nodes] for the state transition from
+// [parent] to [root]. It is assumed the caller holds the [db.lock].
+func (db *Database) updateLayer(root common.Hash, parent common.Hash, nodes *MergedNodeSet, reference bool) error {
+	if parent == (common.Hash{}) {
+		parent = emptyRoot
+	}
+	tree := db.layers
+	if layer, ok := tree.layers[root]; ok {
+		if reference {
+			layer.refs++
+		}
+		return nil
+	}
+	// The state is unchanged or already persisted to disk
+	if root == parent || root == tree.diskRoot {
+		return nil
+	}
+	layer := &diffLayer{
+		root:   root,
+		parent: parent,
+		nodes:  make(map[common.Hash]map[string]*layerNode, len(nodes.sets)),
+	}
+	for owner, set := range nodes.sets {
+		subset := make(map[string]*layerNode, len(set.nodes))
+		for _, path := range set.paths {
+			n, ok := set.nodes[path]
+			if !ok {
+				return fmt.Errorf("missing node %x %v", owner, path)
+			}
+			entry := &layerNode{hash: n.hash}
+			if !n.isDeleted() {
+				entry.blob = nodeToBytes(n.node)
+			}
+			subset[path] = entry
+			layer.size += common.StorageSize(common.HashLength + len(path) + len(entry.blob))
+		}
+		layer.nodes[owner] = subset
+	}
+	if reference {
+		layer.refs = 1
+	}
+	tree.add(layer)
+	memcacheDirtyWriteMeter.Mark(int64(layer.size))
+	return nil
+}
+
+// pathCacheKey returns the key of the trie node at [path] of the trie owned by
+// [owner] in the clean cache of the path scheme.
+func pathCacheKey(owner common.Hash, path []byte) []byte {
+	key := make([]byte, common.HashLength+len(path))
+	copy(key, owner[:])
+	copy(key[common.HashLength:], path)
+	return key
+}
+
+// pathNode retrieves the encoded trie node with the provided hash at [path] of
+// the trie owned by [owner] with the path scheme, or nil if it is unavailable.
+// The node is looked up in the diff layers first, then read from disk, where
+// the node at [path] may belong to another version of the trie.
+func (db *Database) pathNode(owner common.Hash, path []byte, hash common.Hash) []byte {
+	db.lock.RLock()
+	entry := db.layers.index[hash]
+	db.lock.RUnlock()
+
+	if entry != nil {
+		memcacheDirtyHitMeter.Mark(1)
+		memcacheDirtyReadMeter.Mark(int64(len(entry.blob)))
+		return entry.blob
+	}
+	memcacheDirtyMissMeter.Mark(1)
+
+	key := pathCacheKey(owner, path)
+	if db.cleans != nil {
+		if blob := db.cleans.Get(nil, key); len(blob) > 0 && crypto.Keccak256Hash(blob) == hash {
+			memcacheCleanHitMeter.Mark(1)
+			memcacheCleanReadMeter.Mark(int64(len(blob)))
+			return blob
+		}
+	}
+	var blob []byte
+	if owner == (common.Hash{}) {
+		blob = rawdb.ReadAccountTrieNode(db.diskdb, path)
+	} else {
+		blob = rawdb.ReadStorageTrieNode(db.diskdb, owner, path)
+	}
+	if len(blob) == 0 || crypto.Keccak256Hash(blob) != hash {
+		return nil
+	}
+	if db.cleans != nil {
+		db.cleans.Set(key, blob)
+		memcacheCleanMissMeter.Mark(1)
+		memcacheCleanWriteMeter.Mark(int64(len(blob)))
+	}
+	return blob
+}
+
+// commitLayers writes the diff layers from the state persisted to disk up to
+// [root] to disk in a single batch, overwriting the trie nodes at their paths.
+// Afterwards, the state at [root] is the one persisted to disk and the layers
+// not descending from it are dropped.
+func (db *Database) commitLayers(root common.Hash, report bool, callback func(common.Hash)) error {
+	start := time.Now()
+	if db.preimages != nil {
+		if err := db.preimages.commit(true); err != nil {
+			return err
+		}
+	}
+	// Collect the nodes to write, with later layers overriding earlier ones
+	db.lock.RLock()
+	tree := db.layers
+	var chain []*diffLayer
+	for hash := root; hash != tree.diskRoot; {
+		layer, ok := tree.layers[hash]
+		if !ok {
+			db.lock.RUnlock()
+			return fmt.Errorf("state %x is not based on the state %x persisted to disk", root, tree.diskRoot)
+		}
+		chain = append(chain, layer)
+		hash = layer.parent
+	}
+	db.lock.RUnlock()
+	if len(chain) == 0 {
+		return nil
+	}
+	writes := make(map[common.Hash]map[string]*layerNode)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for owner, subset := range chain[i].nodes {
+			merged, ok := writes[owner]
+			if !ok {
+				merged = make(map[string]*layerNode, len(subset))
+				writes[owner] = merged
+			}
+			for path, n := range subset {
+				merged[path] = n
+			}
+		}
+	}
+
+	// Write all nodes in a single batch, so the state on disk is never left
+	// partially overwritten.
+	var (
+		batch = db.diskdb.NewBatch()
+		nodes int
+		size  common.StorageSize
+	)
+	for owner, subset := range writes {
+		for path, n := range subset {
+			switch {
+			case n.blob == nil && owner == (common.Hash{}):
+				rawdb.DeleteAccountTrieNode(batch, []byte(path))
+			case n.blob == nil:
+				rawdb.DeleteStorageTrieNode(batch, owner, []byte(path))
+			case owner == (common.Hash{}):
+				rawdb.WriteAccountTrieNode(batch, []byte(path), n.blob)
+			default:
+				rawdb.WriteStorageTrieNode(batch, owner, []byte(path), n.blob)
+			}
+			if n.blob != nil && callback != nil {
+				callback(n.hash)
+			}
+			nodes++
+			size += common.StorageSize(len(path) + len(n.blob))
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Error("Failed to write trie to disk", "err", err)
+		return err
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	if db.cleans != nil {
+		for owner, subset := range writes {
+			for path, n := range subset {
+				if n.blob == nil {
+					db.cleans.Del(pathCacheKey(owner, []byte(path)))
+				} else {
+					db.cleans.Set(pathCacheKey(owner, []byte(path)), n.blob)
+				}
+			}
+		}
+	}
+	tree.diskRoot = root
+	for _, layer := range chain {
+		tree.remove(layer)
+	}
+	tree.removeStale()
+
+	pathdbCommitTimeTimer.Update(time.Since(start))
+	pathdbCommitNodesMeter.Mark(int64(nodes))
+	pathdbCommitSizeMeter.Mark(int64(size))
+
+	logger := log.Info
+	if !report {
+		logger = log.Debug
+	}
+	logger("Persisted trie from memory database", "root", root, "layers", len(chain), "nodes", nodes, "size", size, "time", time.Since(start),
+		"livelayers", len(tree.layers), "livesize", tree.size)
+	return nil
+}
@@ -0,0 +1,233 @@
+// (c) 2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newPathDatabase(diskdb ethdb.KeyValueStore) *Database {
+	return NewDatabaseWithConfig(diskdb, &Config{Cache: 1, Scheme: rawdb.PathScheme})
+}
+
+// updatePathTrie applies [updates] (deleting keys with an empty value) to the
+// trie at [parent] and inserts the resulting diff layer into [db].
+func updatePathTrie(t *testing.T, db *Database, parent common.Hash, updates map[string]string) common.Hash {
+	t.Helper()
+	trie, err := New(common.Hash{}, parent, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", parent, err)
+	}
+	for key, value := range updates {
+		if err := trie.TryUpdate(crypto.Keccak256([]byte(key)), []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, nodes, err := trie.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes != nil {
+		if err := db.UpdateLayer(root, parent, NewWithNodeSet(nodes), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// checkPathTrie checks the trie at [root] holds exactly [values].
+func checkPathTrie(t *testing.T, db *Database, root common.Hash, values map[string]string) {
+	t.Helper()
+	trie, err := New(common.Hash{}, root, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", root, err)
+	}
+	for key, value := range values {
+		have, err := trie.TryGet(crypto.Keccak256([]byte(key)))
+		if err != nil {
+			t.Fatalf("failed to read %s from trie %x: %v", key, root, err)
+		}
+		if !bytes.Equal(have, []byte(value)) {
+			t.Fatalf("value of %s mismatch in trie %x: have %q, want %q", key, root, have, value)
+		}
+	}
+	var leaves int
+	it := NewIterator(trie.NodeIterator(nil))
+	for it.Next() {
+		leaves++
+	}
+	if it.Err != nil {
+		t.Fatalf("failed to iterate trie %x: %v", root, it.Err)
+	}
+	if leaves != len(values) {
+		t.Fatalf("leaf count mismatch in trie %x: have %d, want %d", root, leaves, len(values))
+	}
+}
+
+// checkPathNodes checks that [diskdb] holds exactly the trie nodes of the trie
+// at [root], so no nodes deleted from the trie are left on disk.
+func checkPathNodes(t *testing.T, db *Database, diskdb ethdb.KeyValueStore, root common.Hash) {
+	t.Helper()
+	trie, err := New(common.Hash{}, root, db)
+	if err != nil {
+		t.Fatalf("failed to open trie %x: %v", root, err)
+	}
+	want := make(map[string]struct{})
+	for it := trie.NodeIterator(nil); it.Next(true); {
+		if it.Hash() != (common.Hash{}) {
+			want[string(it.Path())] = struct{}{}
+		}
+	}
+	it := diskdb.NewIterator(rawdb.TrieNodeAccountPrefix, nil)
+	defer it.Release()
+	var have int
+	for it.Next() {
+		if !rawdb.IsAccountTrieNode(it.Key()) {
+			continue
+		}
+		if _, ok := want[string(it.Key()[len(rawdb.TrieNodeAccountPrefix):])]; !ok {
+			t.Fatalf("unexpected trie node on disk at path %x", it.Key()[len(rawdb.TrieNodeAccountPrefix):])
+		}
+		have++
+	}
+	if have != len(want) {
+		t.Fatalf("trie node count mismatch: have %d, want %d", have, len(want))
+	}
+}
+
+// Tests that diff layers can be read before and after they are committed, and
+// that committing a layer overwrites the state on disk.
+func TestPathDatabaseCommit(t *testing.T) {
+	var (
+		diskdb = memorydb.New()
+		db     = newPathDatabase(diskdb)
+		values = make(map[string]string)
+	)
+	updates := make(map[string]string)
+	for i := byte(0); i < 100; i++ {
+		updates[string([]byte{i})] = string(bytes.Repeat([]byte{i + 1}, 40))
+	}
+	root1 := updatePathTrie(t, db, common.Hash{}, updates)
+	for key, value := range updates {
+		values[key] = value
+	}
+	values1 := copyValues(values)
+
+	// Update some values and delete most of the others on top of [root1]
+	updates = make(map[string]string)
+	for i := byte(0); i < 100; i++ {
+		switch {
+		case i%10 == 0:
+			updates[string([]byte{i})] = "updated"
+			values[string([]byte{i})] = "updated"
+		case i > 5:
+			updates[string([]byte{i})] = ""
+			delete(values, string([]byte{i}))
+		}
+	}
+	root2 := updatePathTrie(t, db, root1, updates)
+	values2 := copyValues(values)
+
+	checkPathTrie(t, db, root1, values1)
+	checkPathTrie(t, db, root2, values2)
+	if nodes, _ := db.Size(); nodes == 0 {
+		t.Fatal("diff layers not held in memory")
+	}
+
+	// Commit the first layer, after which both states remain available
+	var flushed int
+	if err := db.Commit(root1, false, func(common.Hash) { flushed++ }); err != nil {
+		t.Fatalf("failed to commit %x: %v", root1, err)
+	}
+	if flushed == 0 {
+		t.Fatal("no nodes reported as flushed")
+	}
+	checkPathTrie(t, db, root1, values1)
+	checkPathTrie(t, db, root2, values2)
+	checkPathNodes(t, db, diskdb, root1)
+
+	// Commit the second layer, which overwrites the first state on disk
+	if err := db.Commit(root2, false, nil); err != nil {
+		t.Fatalf("failed to commit %x: %v", root2, err)
+	}
+	if nodes, _ := db.Size(); nodes != 0 {
+		t.Fatalf("unexpected diff layers in memory: %v", nodes)
+	}
+	checkPathTrie(t, db, root2, values2)
+	checkPathNodes(t, db, diskdb, root2)
+
+	// The first state is no longer available once overwritten, also when
+	// reopening the database.
+	reopened := newPathDatabase(diskdb)
+	checkPathTrie(t, reopened, root2, values2)
+	for _, db := range []*Database{db, reopened} {
+		trie, err := New(common.Hash{}, root1, db)
+		if err == nil {
+			_, err = trie.TryGet(crypto.Keccak256([]byte{99}))
+		}
+		if _, ok := err.(*MissingNodeError); !ok {
+			t.Fatalf("expected missing node error reading overwritten state, got %v", err)
+		}
+	}
+	if err := reopened.Commit(root1, false, nil); err == nil {
+		t.Fatal("expected error committing unavailable state")
+	}
+}
+
+// Tests that dereferenced layers are dropped unless other layers depend on them,
+// and that committing a layer drops the layers that do not descend from it.
+func TestPathDatabaseDereference(t *testing.T) {
+	db := newPathDatabase(memorydb.New())
+
+	root1 := updatePathTrie(t, db, common.Hash{}, map[string]string{"a": "1", "b": "2"})
+	root2 := updatePathTrie(t, db, root1, map[string]string{"a": "3"})
+	sibling := updatePathTrie(t, db, root1, map[string]string{"b": "4"})
+	child := updatePathTrie(t, db, sibling, map[string]string{"c": "5"})
+
+	// [root1] is kept while the layers on top of it are
+	db.Dereference(root1)
+	if _, ok := db.layers.layers[root1]; !ok {
+		t.Fatal("layer with children dropped")
+	}
+	db.Dereference(sibling)
+	if _, ok := db.layers.layers[sibling]; !ok {
+		t.Fatal("layer with children dropped")
+	}
+	db.Dereference(child)
+	for _, root := range []common.Hash{sibling, child} {
+		if _, ok := db.layers.layers[root]; ok {
+			t.Fatalf("unreferenced layer %x not dropped", root)
+		}
+	}
+	checkPathTrie(t, db, root2, map[string]string{"a": "3", "b": "2"})
+
+	// Layers not descending from the committed state are dropped
+	sibling = updatePathTrie(t, db, root1, map[string]string{"b": "4"})
+	if err := db.Commit(root2, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.layers.layers) != 0 || len(db.layers.index) != 0 || db.layers.size != 0 {
+		t.Fatalf("unexpected layers after commit: %d layers, %d nodes, size %v", len(db.layers.layers), len(db.layers.index), db.layers.size)
+	}
+	if _, err := New(common.Hash{}, sibling, db); err == nil {
+		t.Fatal("expected error opening dropped state")
+	}
+	db.Dereference(sibling)
+	checkPathTrie(t, db, root2, map[string]string{"a": "3", "b": "2"})
+}
+
+func copyValues(values map[string]string) map[string]string {
+	cpy := make(map[string]string, len(values))
+	for key, value := range values {
+		cpy[key] = value
+	}
+	return cpy
+}
@@ -32,13 +32,18 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 var (
 	// emptyRoot is the known root hash of an empty trie.
 	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// emptyState is the known hash of an empty state trie entry.
+	emptyState = crypto.Keccak256Hash(nil)
 )
 
 // LeafCallback is a callback type invoked when a trie operation reaches a leaf
@@ -109,7 +114,11 @@ func New(owner common.Hash, root common.Hash, db *Database) (*Trie, error) {
 	trie := &Trie{
 		owner: owner,
 		db:    db,
-		//tracer: newTracer(),
+	}
+	// The path scheme needs to know which nodes are deleted from the trie to
+	// remove them from disk.
+	if db != nil && db.Scheme() == rawdb.PathScheme {
+		trie.tracer = newTracer()
 	}
 	if root != (common.Hash{}) && root != emptyRoot {
 		rootnode, err := trie.resolveHash(root[:], nil)
@@ -225,7 +234,7 @@ func (t *Trie) tryGetNode(origNode node, path []byte, pos int) (item []byte, new
 		if hash == nil {
 			return nil, origNode, 0, errors.New("non-consensus node")
 		}
-		blob, err := t.db.RawNode(common.BytesToHash(hash))
+		blob, err := t.db.readBlob(t.owner, path, common.BytesToHash(hash))
 		return blob, origNode, 1, err
 	}
 	// Path still needs to be traversed, descend into children
@@ -563,7 +572,7 @@ func (t *Trie) resolve(n node, prefix []byte) (node, error) {
 // node hash and path prefix.
 func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 	hash := common.BytesToHash(n)
-	if node := t.db.EncodedNode(hash); node != nil {
+	if node := t.db.readNode(t.owner, prefix, hash); node != nil {
 		return node, nil
 	}
 	return nil, &MissingNodeError{Owner: t.owner, NodeHash: hash, Path: prefix}
@@ -573,7 +582,7 @@ func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 // with the provided node hash and path prefix.
 func (t *Trie) resolveBlob(n hashNode, prefix []byte) ([]byte, error) {
 	hash := common.BytesToHash(n)
-	blob, _ := t.db.RawNode(hash)
+	blob, _ := t.db.readBlob(t.owner, prefix, hash)
 	if len(blob) != 0 {
 		return blob, nil
 	}
@@ -598,7 +607,17 @@ func (t *Trie) Commit(collectLeaf bool) (common.Hash, *NodeSet, error) {
 	defer t.tracer.reset()
 
 	if t.root == nil {
-		return emptyRoot, nil, nil
+		// Nodes deleted from a trie that became empty still need to be
+		// removed from disk by the path scheme.
+		deleted := t.tracer.deleteList()
+		if len(deleted) == 0 {
+			return emptyRoot, nil, nil
+		}
+		nodes := NewNodeSet(t.owner)
+		for _, path := range deleted {
+			nodes.markDeleted(string(path))
+		}
+		return emptyRoot, nodes, nil
 	}
 	// Derive the hash for all dirty nodes first. We hold the assumption
 	// in the following procedure that all nodes are hashed.
@@ -613,6 +632,9 @@ func (t *Trie) Commit(collectLeaf bool) (common.Hash, *NodeSet, error) {
 		return rootHash, nil, nil
 	}
 	h := newCommitter(t.owner, collectLeaf)
+	for _, path := range t.tracer.deleteList() {
+		h.nodes.markDeleted(string(path))
+	}
 	newRoot, nodes, err := h.Commit(t.root)
 	if err != nil {
 		return common.Hash{}, nil, err
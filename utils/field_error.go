@@ -0,0 +1,194 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError is an error in the value of a config field, qualified by the path of the field
+// from the root of the config, e.g. "feeConfig.gasLimit" or "precompileUpgrades[1].txAllowListConfig".
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+// NewFieldError returns a FieldError for the field at [path] with a message formatted from [format] and [args],
+// which describes the value of the field and follows its path in the error message.
+func NewFieldError(path string, format string, args ...interface{}) *FieldError {
+	return &FieldError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + " " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// WithFieldPath qualifies [err] by the path of the field [path] it was found in. If [err] is a FieldError
+// of a nested field, [path] is prepended to its path. Returns nil if [err] is nil.
+func WithFieldPath(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) && fieldErr == err {
+		return &FieldError{Path: joinFieldPath(path, fieldErr.Path), Err: fieldErr.Err}
+	}
+	return &FieldError{Path: path, Err: fmt.Errorf("is invalid: %w", err)}
+}
+
+func joinFieldPath(parent, child string) string {
+	if parent == "" || strings.HasPrefix(child, "[") {
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// QualifyJSONError returns [err], the error returned by decoding [data] into [v], qualified by the path of
+// the field of [data] that could not be decoded. Returns [err] unchanged if the field cannot be located,
+// for example if [data] is not valid JSON.
+func QualifyJSONError(data []byte, v interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	if located := locateJSONError(data, reflect.TypeOf(v), true); located != nil {
+		return located
+	}
+	return err
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// locateJSONError decodes [data] into a new value of [typ] field by field, and returns the error of the first
+// field that cannot be decoded, qualified by its path. Returns nil if [data] decodes into [typ] or if the
+// error is not caused by a nested field. If [root] is true, the JSON methods of [typ] are ignored, so that
+// the fields of types decoding themselves through a method can be located.
+func locateJSONError(data []byte, typ reflect.Type, root bool) error {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if !root && reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		// The type decodes itself, so its fields are not known. Its error may already be a FieldError.
+		var fieldErr *FieldError
+		if err := json.Unmarshal(data, reflect.New(typ).Interface()); errors.As(err, &fieldErr) {
+			return err
+		}
+		return nil
+	}
+
+	switch {
+	case typ.Kind() == reflect.Struct:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+		for _, field := range jsonFields(typ) {
+			value, ok := lookupJSONField(raw, field.name)
+			if !ok {
+				continue
+			}
+			if err := fieldJSONError(value, field.typ); err != nil {
+				return WithFieldPath(field.name, err)
+			}
+		}
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		var raw []json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+		for i, value := range raw {
+			if err := fieldJSONError(value, typ.Elem()); err != nil {
+				return WithFieldPath(fmt.Sprintf("[%d]", i), err)
+			}
+		}
+	case typ.Kind() == reflect.Map:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil
+		}
+		keys := make([]string, 0, len(raw))
+		for key := range raw {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := fieldJSONError(raw[key], typ.Elem()); err != nil {
+				return WithFieldPath(key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldJSONError returns the error of decoding [data] into a value of [typ], qualified by the path of the
+// nested field causing it if it can be located.
+func fieldJSONError(data []byte, typ reflect.Type) error {
+	err := json.Unmarshal(data, reflect.New(typ).Interface())
+	if err == nil {
+		return nil
+	}
+	if located := locateJSONError(data, typ, false); located != nil {
+		return located
+	}
+	return err
+}
+
+// jsonField is a field of a struct as it is encoded by encoding/json.
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFields returns the fields of the struct type [typ] encoded by encoding/json, including the fields
+// of embedded structs without a JSON name.
+func jsonFields(typ reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: field.Type})
+	}
+	return fields
+}
+
+// lookupJSONField returns the value of [name] in [raw], matching keys case-insensitively as encoding/json does.
+func lookupJSONField(raw map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if value, ok := raw[name]; ok {
+		return value, true
+	}
+	for key, value := range raw {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,134 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package utils
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Schema is a JSON schema describing the JSON encoding of a config, so that tooling can generate
+// forms and validate configs before they are distributed.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *big.Int           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *big.Int           `json:"exclusiveMinimum,omitempty"`
+}
+
+// Describer is implemented by configs that describe their JSON encoding themselves, for example
+// because they implement json.Marshaler or because they constrain the values of their fields.
+type Describer interface {
+	Describe() *Schema
+}
+
+var (
+	describerType     = reflect.TypeOf((*Describer)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+	addressType       = reflect.TypeOf(common.Address{})
+	hashType          = reflect.TypeOf(common.Hash{})
+	bytesType         = reflect.TypeOf(hexutil.Bytes{})
+)
+
+// Describe returns the schema of the JSON encoding of [v], derived from its type. Nested types
+// implementing Describer are described by their Describe method, but not the type of [v] itself,
+// so that Describe methods can start from the schema derived from their own type.
+func Describe(v interface{}) *Schema {
+	return describeType(reflect.TypeOf(v), true)
+}
+
+func describeType(typ reflect.Type, root bool) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if !root && reflect.PtrTo(typ).Implements(describerType) {
+		return reflect.New(typ).Interface().(Describer).Describe()
+	}
+
+	switch typ {
+	case bigIntType:
+		return &Schema{Type: "integer"}
+	case addressType:
+		return &Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{40}$"}
+	case hashType:
+		return &Schema{Type: "string", Pattern: "^0x[0-9a-fA-F]{64}$"}
+	case bytesType:
+		return &Schema{Type: "string", Pattern: "^0x([0-9a-fA-F]{2})*$"}
+	}
+	if reflect.PtrTo(typ).Implements(jsonMarshalerType) || typ.Implements(jsonMarshalerType) {
+		// The encoding of the type is not known from its fields.
+		return &Schema{}
+	}
+	if reflect.PtrTo(typ).Implements(textMarshalerType) || typ.Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: new(big.Int)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: describeType(typ.Elem(), false)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: describeType(typ.Elem(), false)}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		describeFields(schema, typ)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+// describeFields adds the fields of the struct type [typ] encoded by encoding/json to the properties of [schema],
+// including the fields of embedded structs without a JSON name.
+func describeFields(schema *Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				describeFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = describeType(field.Type, false)
+	}
+}